@@ -3,29 +3,36 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
-	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
 )
 
 func main() {
 	// 连接以太坊节点，打印链 ID 和最新区块高度。
-	rpcURL := os.Getenv("ETH_RPC_URL")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	rpcURL := config.Get().RPCURL
 	if rpcURL == "" {
-		log.Fatal("ETH_RPC_URL is not set")
+		log.Fatal(config.ErrNoRPC)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(10*time.Second))
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	client, err := rpcretry.Dial(ctx, rpcURL)
 	if err != nil {
 		log.Fatalf("failed to connect to Ethereum node: %v", err)
 	}
@@ -41,53 +48,45 @@ func main() {
 		log.Fatalf("failed to get latest block header: %v", err)
 	}
 
-	fmt.Println("=== Ethereum Node Info ===")
-	fmt.Printf("RPC URL       : %s\n", rpcURL)
-	fmt.Printf("Chain ID      : %s\n", chainID.String())
-	fmt.Println("\n  注意: 'Latest' 区块是节点当前认为的最新区块，可能尚未被所有节点确认")
-	fmt.Println("   不同RPC节点可能返回不同的 'latest' 区块，导致与浏览器不匹配")
-	fmt.Println("   建议对比 'Safe' 或 'Finalized' 区块（已确认的区块）")
-	fmt.Println()
-	fmt.Printf("Latest Block  : %d\n", header.Number.Uint64())
-	fmt.Printf("Block Hash    : %s\n", header.Hash().Hex())
-	fmt.Printf("Block Time    : %s\n", time.Unix(int64(header.Time), 0).Format(time.RFC3339))
-	fmt.Println("==============================")
+	out.Section("Ethereum Node Info")
+	out.Field("RPC URL", rpcURL)
+	out.Field("Chain ID", chainID)
+	out.Note("\n  注意: 'Latest' 区块是节点当前认为的最新区块，可能尚未被所有节点确认")
+	out.Note("   不同RPC节点可能返回不同的 'latest' 区块，导致与浏览器不匹配")
+	out.Note("   建议对比 'Safe' 或 'Finalized' 区块（已确认的区块）\n")
+	out.Field("Latest Block", header.Number.Uint64())
+	out.Field("Block Hash", header.Hash())
+	out.Field("Block Time", time.Unix(int64(header.Time), 0).Format(time.RFC3339))
 
 	// 示例：也可以获取任意指定高度的区块头
 	if header.Number.Uint64() > 0 {
 		num := new(big.Int).Sub(header.Number, big.NewInt(1))
 		prevHeader, err := client.HeaderByNumber(ctx, num)
 		if err == nil {
-			fmt.Printf("Prev Block    : %d (%s)\n", prevHeader.Number.Uint64(), prevHeader.Hash().Hex())
+			out.Field("Prev Block", prevHeader.Number.Uint64())
+			out.Field("Prev Hash", prevHeader.Hash())
 		}
 	}
 
-	// 获取 'safe' 区块头
-	safeHeader, safeHash, err := getBlockByTag(ctx, client, "safe")
-	if err != nil {
-		log.Fatalf("failed to get 'safe' block header: %v", err)
-	} else {
-		fmt.Println("\n=== Safe Block (推荐对比) ===")
-		fmt.Printf("Block Number  : %d\n", safeHeader.Number.Uint64())
-		fmt.Printf("Block Hash    : %s (RPC提供的hash, 与浏览器一致)\n", safeHash.Hex())
-		fmt.Printf("Calculated    : %s (计算出的hash, 可能不匹配)\n", safeHeader.Hash().Hex())
-		fmt.Printf("Block Time    : %s\n", time.Unix(int64(safeHeader.Time), 0).Format(time.RFC3339))
-		fmt.Printf("Confirmations : %d\n", header.Number.Uint64()-safeHeader.Number.Uint64())
-		fmt.Println("=============================")
-	}
-
-	// 获取 'finalized' 区块头
-	finalizedHeader, finalizedHash, err := getBlockByTag(ctx, client, "finalized")
-	if err != nil {
-		log.Fatalf("failed to get 'finalized' block header: %v", err)
-	} else {
-		fmt.Println("\n=== Finalized Block (最安全的区块) ===")
-		fmt.Printf("Block Number  : %d\n", finalizedHeader.Number.Uint64())
-		fmt.Printf("Block Hash    : %s (RPC提供的hash, 与浏览器一致)\n", finalizedHash.Hex())
-		fmt.Printf("Calculated    : %s (计算出的hash, 可能不匹配)\n", finalizedHeader.Hash().Hex())
-		fmt.Printf("Block Time    : %s\n", time.Unix(int64(finalizedHeader.Time), 0).Format(time.RFC3339))
-		fmt.Printf("Confirmations : %d\n", header.Number.Uint64()-finalizedHeader.Number.Uint64())
-		fmt.Println("=============================")
+	// 获取 'safe'（推荐对比）和 'finalized'（最安全的区块）区块头
+	for _, tag := range []struct{ name, title string }{
+		{"safe", "Safe Block"},
+		{"finalized", "Finalized Block"},
+	} {
+		tagHeader, tagHash, err := getBlockByTag(ctx, client, tag.name)
+		if err != nil {
+			log.Fatalf("failed to get '%s' block header: %v", tag.name, err)
+		}
+		out.Section(tag.title)
+		out.Field("Block Number", tagHeader.Number.Uint64())
+		// RPC 提供的 hash 与浏览器一致；由解析出的字段重新计算的 hash 可能不匹配
+		out.Field("Block Hash", tagHash)
+		out.Field("Calculated", tagHeader.Hash())
+		out.Field("Block Time", time.Unix(int64(tagHeader.Time), 0).Format(time.RFC3339))
+		out.Field("Confirmations", header.Number.Uint64()-tagHeader.Number.Uint64())
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
 	}
 }
 
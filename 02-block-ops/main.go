@@ -2,16 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"math/big"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+	"github.com/yzucdh1/examples/internal/scan"
 )
 
 // 02-block-ops.go
@@ -30,22 +37,36 @@ import (
 //
 //	# 批量查询，自定义请求间隔（毫秒）
 //	go run main.go -range-start 100 -range-end 105 -rate-limit 500
+//
+//	# 长范围查询：定期输出进度，Ctrl-C 后以相同参数重新运行会从中断处继续（见 internal/scan）
+//	go run main.go -range-start 19000000 -range-end 19100000 -state block-range.state.json
 func main() {
 	blockNumberFlag := flag.Uint64("number", 0, "block number to query (0 means skip)")
 	rangeStartFlag := flag.Uint64("range-start", 0, "start block number for range query")
 	rangeEndFlag := flag.Uint64("range-end", 0, "end block number for range query")
 	rateLimitFlag := flag.Int("rate-limit", 200, "rate limit in milliseconds between requests")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	scanOpts := scan.AddFlags(flag.CommandLine, "block-range.state.json")
 	flag.Parse()
 
-	rpcURL := os.Getenv("ETH_RPC_URL")
+	rpcURL := config.Get().RPCURL
 	if rpcURL == "" {
-		log.Fatal("ETH_RPC_URL is not set")
+		log.Fatal(config.ErrNoRPC)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(30*time.Second))
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	// 单次请求超时、失败重试和请求间隔都由 rpcretry 在传输层处理
+	policy := rpcretry.DefaultPolicy()
+	if *rateLimitFlag > 0 {
+		policy.RateLimit = float64(time.Second) / float64(time.Duration(*rateLimitFlag)*time.Millisecond)
+	}
+	policy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		log.Printf("[WARN] request failed, retry %d/%d after %v: %v", attempt, policy.MaxRetries, delay, err)
+	}
+	client, err := rpcretry.DialWith(ctx, rpcURL, policy)
 	if err != nil {
 		log.Fatalf("failed to connect to Ethereum node: %v", err)
 	}
@@ -57,16 +78,16 @@ func main() {
 		log.Fatalf("failed to get latest block: %v", err)
 	}
 
-	printBlockInfo("Latest Block", latestBlock)
+	printBlockInfo(out, "Latest Block", latestBlock)
 
 	// 指定区块
 	if *blockNumberFlag > 0 {
 		num := big.NewInt(0).SetUint64(*blockNumberFlag)
-		block, err := fetchBlockWithRetry(ctx, client, num, 3)
+		block, err := client.BlockByNumber(ctx, num)
 		if err != nil {
 			log.Fatalf("failed to get block %d: %v", *blockNumberFlag, err)
 		}
-		printBlockInfo(fmt.Sprintf("Block %d", *blockNumberFlag), block)
+		printBlockInfo(out, "Block", block)
 	}
 
 	// 批量查询区块范围
@@ -74,126 +95,100 @@ func main() {
 		if *rangeStartFlag > *rangeEndFlag {
 			log.Fatal("range-start must be <= range-end")
 		}
-		rateLimit := time.Duration(*rateLimitFlag) * time.Millisecond
-		fetchBlockRange(ctx, client, *rangeStartFlag, *rangeEndFlag, rateLimit)
-	}
-}
-
-// fetchBlockWithRetry 带重试机制的区块查询
-func fetchBlockWithRetry(ctx context.Context, client *ethclient.Client, blockNumber *big.Int, maxRetries int) (*types.Block, error) {
-	var lastErr error
-	for i := range maxRetries {
-		// 每次重试使用新的超时上下文，避免上下文被取消
-		reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-		block, err := client.BlockByNumber(reqCtx, blockNumber)
-		cancel()
-
-		if err == nil {
-			return block, nil
-		}
-
-		lastErr = err
-		if i < maxRetries-1 {
-			backoff := time.Duration(1<<i) * 500 * time.Millisecond
-			log.Printf("[WARN] failed to fetch block %s, retry %d/%d after %v: %v",
-				blockNumber.String(), i+1, maxRetries, backoff, err)
-			time.Sleep(backoff)
+		// 范围查询可能持续很久，不受上面的整体超时限制；Ctrl-C 时保存进度后正常输出已查询的结果
+		scanCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err := fetchBlockRange(scanCtx, client, out, scanOpts, *rangeStartFlag, *rangeEndFlag)
+		stop()
+		if err != nil && !errors.Is(err, scan.ErrInterrupted) {
+			log.Fatal(err)
 		}
 	}
-	return nil, fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr)
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
 }
 
-// fetchBlockRange 批量查询区块范围，带频率控制
-func fetchBlockRange(ctx context.Context, client *ethclient.Client, start, end uint64, rateLimit time.Duration) {
-	fmt.Printf("\n=== Fetching Block Range [%d, %d] ===\n", start, end)
-	fmt.Printf("Rate Limit: %v per request\n\n", rateLimit)
+// fetchBlockRange 批量查询区块范围；请求间隔由连接的限速控制，进度和续扫由 internal/scan 处理
+func fetchBlockRange(ctx context.Context, client *ethclient.Client, out *output.Printer, opts *scan.Options, start, end uint64) error {
+	out.Note("\n=== Fetching Block Range [%d, %d] ===\n", start, end)
 
-	successCount := 0
 	skipCount := 0
-	ticker := time.NewTicker(rateLimit)
-	defer ticker.Stop()
-
-	for num := start; num <= end; num++ {
-		// 等待速率限制
-		<-ticker.C
-
-		blockNumber := big.NewInt(0).SetUint64(num)
-		block, err := fetchBlockWithRetry(ctx, client, blockNumber, 2)
-
-		if err != nil {
-			log.Printf("[ERROR] Block %d: %v", num, err)
-			skipCount++
-			continue
-		}
-
-		successCount++
-		printBlockInfo(fmt.Sprintf("Block %d", num), block)
-
-		// 检查上下文是否已取消
-		select {
-		case <-ctx.Done():
-			log.Printf("[INFO] Context cancelled, stopping at block %d", num)
-			return
-		default:
-		}
+	res, err := opts.Run(ctx, scan.Scan{Name: "02-block-range", From: start, To: end, Unit: "blocks fetched"},
+		func(ctx context.Context, num, _ uint64) (int, error) {
+			block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(num))
+			if err != nil {
+				// 取消导致的失败交给 scan 处理（该区块下次重新查询），其他错误跳过该区块
+				if ctx.Err() != nil {
+					return 0, ctx.Err()
+				}
+				log.Printf("[ERROR] Block %d: %v", num, err)
+				skipCount++
+				return 0, nil
+			}
+			// 范围内的区块使用同一个段名，结构化输出中合并为数组
+			printBlockInfo(out, "Block", block)
+			return 1, nil
+		})
+
+	out.Section("Summary")
+	if res.Resumed {
+		out.Field("Resumed At", res.Start)
 	}
-
-	fmt.Printf("\n=== Summary ===\n")
-	fmt.Printf("Success: %d blocks\n", successCount)
-	fmt.Printf("Skipped: %d blocks\n", skipCount)
-	fmt.Printf("Total: %d blocks\n", end-start+1)
+	out.Field("Success", res.Items)
+	out.Field("Skipped", skipCount)
+	out.Field("Total", end-start+1)
+	if err != nil {
+		out.Field("Stopped At", res.Next)
+	}
+	return err
 }
 
-// printBlockInfo 打印详细的区块信息
-func printBlockInfo(title string, block *types.Block) {
-	fmt.Println("======================================")
-	fmt.Println(title)
-	fmt.Println("======================================")
-	fmt.Printf("Block: %+v\n", block)
+// printBlockInfo 输出详细的区块信息
+func printBlockInfo(out *output.Printer, title string, block *types.Block) {
+	out.Section(title)
+	out.Note("Block: %+v", block)
 
 	// 基本信息
-	fmt.Printf("Number       : %d\n", block.Number().Uint64())
-	fmt.Printf("Hash         : %s\n", block.Hash().Hex())
-	fmt.Printf("Parent Hash  : %s\n", block.ParentHash().Hex())
+	out.Field("Number", block.Number().Uint64())
+	out.Field("Hash", block.Hash())
+	out.Field("Parent Hash", block.ParentHash())
 
 	// 时间信息
 	blockTime := time.Unix(int64(block.Time()), 0)
-	fmt.Printf("Time         : %s\n", blockTime.Format(time.RFC3339))
-	fmt.Printf("Time (Local) : %s\n", blockTime.Local().Format("2006-01-02 15:04:05 MST"))
+	out.Field("Time", blockTime.Format(time.RFC3339))
+	out.Field("Time (Local)", blockTime.Local().Format("2006-01-02 15:04:05 MST"))
 
 	// Gas 信息
 	gasUsed := block.GasUsed()
 	gasLimit := block.GasLimit()
-	gasUsagePercent := float64(gasUsed) / float64(gasLimit) * 100
-	fmt.Printf("Gas Used     : %d (%.2f%%)\n", gasUsed, gasUsagePercent)
-	fmt.Printf("Gas Limit    : %d\n", gasLimit)
+	out.Field("Gas Used", gasUsed)
+	out.Field("Gas Limit", gasLimit)
+	if gasLimit > 0 {
+		out.Field("Gas Usage", fmt.Sprintf("%.2f%%", float64(gasUsed)/float64(gasLimit)*100))
+	}
 
 	// 交易信息
 	txCount := len(block.Transactions())
-	fmt.Printf("Tx Count     : %d\n", txCount)
+	out.Field("Tx Count", txCount)
 
 	// 区块根信息（Merkle 树根）
-	fmt.Printf("State Root   : %s\n", block.Root().Hex())
-	fmt.Printf("Tx Root      : %s\n", block.TxHash().Hex())
-	fmt.Printf("Receipt Root : %s\n", block.ReceiptHash().Hex())
+	out.Field("State Root", block.Root())
+	out.Field("Tx Root", block.TxHash())
+	out.Field("Receipt Root", block.ReceiptHash())
 
-	// 区块大小估算（简化版，实际大小还包括其他字段）
 	if txCount > 0 {
-		fmt.Printf("\nFirst Tx Hash: %s\n", block.Transactions()[0].Hash().Hex())
+		out.Field("First Tx Hash", block.Transactions()[0].Hash())
 		if txCount > 1 {
-			fmt.Printf("Last Tx Hash : %s\n", block.Transactions()[txCount-1].Hash().Hex())
+			out.Field("Last Tx Hash", block.Transactions()[txCount-1].Hash())
 		}
 	}
 
 	// 难度信息（PoW 相关，PoS 后基本固定）
-	fmt.Printf("Difficulty   : %s\n", block.Difficulty().String())
+	out.Field("Difficulty", block.Difficulty())
 
 	// 区块奖励相关信息
 	coinbase := block.Coinbase()
 	if coinbase != (common.Address{}) {
-		fmt.Printf("Coinbase     : %s\n", coinbase.Hex())
+		out.Field("Coinbase", coinbase)
 	}
-
-	fmt.Println("======================================")
-	fmt.Println()
 }
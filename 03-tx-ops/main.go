@@ -2,59 +2,97 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"flag"
-	"fmt"
 	"log"
 	"math/big"
-	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/24-gas-tracker/gasoracle"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/decimal"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcerr"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+	"github.com/yzucdh1/examples/internal/signer"
+	_ "github.com/yzucdh1/examples/internal/signer/kms" // --signer kms: / gcp-kms: / vault:
+	"github.com/yzucdh1/examples/internal/txbuilder"
+	"github.com/yzucdh1/examples/internal/txwait"
 )
 
 // 03-tx-ops.go
 // 支持两种操作模式：
 // 1. 查询交易：--tx <hash> - 按哈希查询交易与回执，解析关键字段
-// 2. 发送交易：--send --to <address> --amount <eth> - 发起 ETH 转账交易
+// 2. 发送交易：--send --to <address> --amount <eth> [--speed slow|standard|fast] [--type legacy|accesslist|dynamic] [--confirmations n] - 发起 ETH 转账交易，
+//    交易由 internal/txbuilder 构造（默认 EIP-1559），费用由 24-gas-tracker/gasoracle 按档位给出；签名使用 internal/signer，私钥可来自 SENDER_PRIVATE_KEY，
+//    也可以在配置文件的 profile 中改用 keystore、助记词或远程签名服务，或用 --signer kms:alias/... 交给云 KMS / Vault 签名；
+//    --confirmations 大于 0 时由 internal/txwait 等待交易达到指定确认数（可感知重组）
 func main() {
 	// 命令行参数
 	txHashHex := flag.String("tx", "", "transaction hash (for query mode)")
 	sendMode := flag.Bool("send", false, "enable send transaction mode")
 	toAddrHex := flag.String("to", "", "recipient address (required for send mode)")
-	amountEth := flag.Float64("amount", 0, "amount in ETH (required for send mode)")
+	amountEth := flag.String("amount", "", "amount in ETH, e.g. 0.01 (required for send mode)")
+	speedFlag := flag.String("speed", "standard", "fee level for send mode: slow | standard | fast")
+	typeFlag := flag.String("type", "dynamic", "tx type for send mode: legacy | accesslist | dynamic")
+	confirmations := flag.Uint64("confirmations", 0, "confirmations to wait for in send mode (0 = don't wait)")
+	signerFlag := flag.String("signer", "", "signer for send mode as scheme:ref, e.g. kms:alias/deployer (default $SENDER_SIGNER)")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
 	flag.Parse()
 
+	// --signer 优先于 SENDER_SIGNER、SENDER_PRIVATE_KEY 和配置文件
+	if *signerFlag != "" {
+		config.Get().Signer = *signerFlag
+	}
+
 	// 判断操作模式
 	if *sendMode {
 		// 发送交易模式
-		if *toAddrHex == "" || *amountEth <= 0 {
+		if *toAddrHex == "" || *amountEth == "" {
 			log.Fatal("send mode requires --to and --amount flags")
 		}
-		sendTransaction(*toAddrHex, *amountEth)
+		// 金额按十进制精确换算为 wei，不经过 float64（0.1 ETH 不会变成 100000000000000005 wei）
+		valueWei, err := decimal.ParseEther(*amountEth)
+		if err != nil {
+			log.Fatalf("invalid --amount: %v", err)
+		}
+		if valueWei.Sign() == 0 {
+			log.Fatal("--amount must be positive")
+		}
+		speed, err := gasoracle.ParseSpeed(*speedFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		txType, err := txbuilder.ParseType(*typeFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sendTransaction(out, *toAddrHex, valueWei, speed, txType, *confirmations)
 	} else {
 		// 查询交易模式
 		if *txHashHex == "" {
 			log.Fatal("query mode requires --tx flag, or use --send for send mode")
 		}
-		queryTransaction(*txHashHex)
+		queryTransaction(out, *txHashHex)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
 	}
 }
 
 // 查询交易
-func queryTransaction(txHashHex string) {
-	rpcURL := os.Getenv("ETH_RPC_URL")
+func queryTransaction(out *output.Printer, txHashHex string) {
+	rpcURL := config.Get().RPCURL
 	if rpcURL == "" {
-		log.Fatal("ETH_RPC_URL is not set")
+		log.Fatal(config.ErrNoRPC)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(20*time.Second))
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	client, err := rpcretry.Dial(ctx, rpcURL)
 	if err != nil {
 		log.Fatalf("failed to connect to Ethereum node: %v", err)
 	}
@@ -67,8 +105,8 @@ func queryTransaction(txHashHex string) {
 		log.Fatalf("failed to get transaction: %v", err)
 	}
 
-	fmt.Println("=== Transaction ===")
-	printTxBasicInfo(tx, isPending)
+	out.Section("Transaction")
+	printTxBasicInfo(out, tx, isPending)
 
 	// 回执可能尚不可用（pending 交易）
 	receipt, err := client.TransactionReceipt(ctx, txHash)
@@ -77,179 +115,107 @@ func queryTransaction(txHashHex string) {
 		return
 	}
 
-	fmt.Println("=== Receipt ===")
-	printReceiptInfo(receipt)
+	out.Section("Receipt")
+	printReceiptInfo(out, receipt)
 }
 
 // 发送交易
-func sendTransaction(toAddrHex string, amountEth float64) {
-	rpcURL := os.Getenv("ETH_RPC_URL")
+func sendTransaction(out *output.Printer, toAddrHex string, valueWei *big.Int, speed gasoracle.Speed, txType txbuilder.Type, confirmations uint64) {
+	rpcURL := config.Get().RPCURL
 	if rpcURL == "" {
-		log.Fatal("ETH_RPC_URL is not set")
-	}
-
-	privKeyHex := os.Getenv("SENDER_PRIVATE_KEY")
-	if privKeyHex == "" {
-		log.Fatal("SENDER_PRIVATE_KEY is not set (required for send mode)")
+		log.Fatal(config.ErrNoRPC)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(30*time.Second))
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	client, err := rpcretry.Dial(ctx, rpcURL)
 	if err != nil {
 		log.Fatalf("failed to connect to Ethereum node: %v", err)
 	}
 	defer client.Close()
 
-	// 解析私钥
-	privKey, err := crypto.HexToECDSA(trim0x(privKeyHex))
+	// 按配置创建签名器（--signer、SENDER_PRIVATE_KEY、keystore、助记词或远程签名服务，见 internal/signer）
+	sgn, err := signer.FromConfig(ctx, config.Get())
 	if err != nil {
-		log.Fatalf("invalid private key: %v", err)
-	}
-
-	// 获取发送方地址
-	publicKey := privKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("error casting public key to ECDSA")
+		log.Fatalf("failed to load signer: %v", err)
 	}
-	fromAddr := crypto.PubkeyToAddress(*publicKeyECDSA)
+	fromAddr := sgn.Address()
 	toAddr := common.HexToAddress(toAddrHex)
 
-	// 获取链 ID
-	chainID, err := client.ChainID(ctx)
-	if err != nil {
-		log.Fatalf("failed to get chain id: %v", err)
-	}
-
-	// 获取 nonce
-	nonce, err := client.PendingNonceAt(ctx, fromAddr)
-	if err != nil {
-		log.Fatalf("failed to get nonce: %v", err)
-	}
-
-	// 获取建议的 Gas 价格（使用 EIP-1559 动态费用）
-	gasTipCap, err := client.SuggestGasTipCap(ctx)
-	if err != nil {
-		log.Fatalf("failed to get gas tip cap: %v", err)
-	}
-
-	// 获取 base fee，计算 fee cap
-	header, err := client.HeaderByNumber(ctx, nil)
+	// 构造、签名并发送交易：nonce、gas（普通转账固定为 21000）、费用由 txbuilder 补全，
+	// 并检查余额是否覆盖 value + gas 费用上限。
+	// EIP-1559 费用按 --speed 档位取 24-gas-tracker 的 feeHistory 分位数建议，替代 "base fee * 2 + tip" 的简单策略
+	builder := txbuilder.New(client, sgn)
+	builder.Fees = gasoracle.FeeFunc(client, gasoracle.DefaultConfig(), speed)
+	signedTx, err := builder.Send(ctx, txbuilder.Intent{Type: txType, To: &toAddr, Value: valueWei})
 	if err != nil {
-		log.Fatalf("failed to get header: %v", err)
-	}
-
-	baseFee := header.BaseFee
-	if baseFee == nil {
-		// 如果不支持 EIP-1559，使用传统 gas price
-		gasPrice, err := client.SuggestGasPrice(ctx)
-		if err != nil {
-			log.Fatalf("failed to get gas price: %v", err)
+		// nonce 过低、替换费用不足、余额不足、revert 等节点错误附带处理建议
+		if hint := rpcerr.Hint(err); hint != "" {
+			log.Fatalf("%v (hint: %s)", err, hint)
 		}
-		baseFee = gasPrice
+		log.Fatal(err)
 	}
 
-	// fee cap = base fee * 2 + tip cap（简单策略）
-	gasFeeCap := new(big.Int).Add(
-		new(big.Int).Mul(baseFee, big.NewInt(2)),
-		gasTipCap,
-	)
-
-	// 估算 Gas Limit（普通转账固定为 21000）
-	gasLimit := uint64(21000)
-
-	// 转换 ETH 金额为 Wei
-	// amountEth * 1e18
-	amountWei := new(big.Float).Mul(
-		big.NewFloat(amountEth),
-		big.NewFloat(1e18),
-	)
-	valueWei, _ := amountWei.Int(nil)
-
-	// 检查余额是否足够
-	balance, err := client.BalanceAt(ctx, fromAddr, nil)
-	if err != nil {
-		log.Fatalf("failed to get balance: %v", err)
+	// 输出交易信息
+	out.Section("Transaction Sent")
+	out.Field("From", fromAddr)
+	out.Field("To", toAddr)
+	out.Field("Value ETH", decimal.FormatEther(valueWei))
+	out.Field("Value Wei", valueWei)
+	out.Field("Type", signedTx.Type())
+	out.Field("Gas Limit", signedTx.Gas())
+	if signedTx.Type() == types.DynamicFeeTxType {
+		out.Field("Gas Tip Cap", signedTx.GasTipCap())
+		out.Field("Gas Fee Cap", signedTx.GasFeeCap())
+	} else {
+		out.Field("Gas Price", signedTx.GasPrice())
 	}
-
-	// 计算总费用：value + gasFeeCap * gasLimit
-	totalCost := new(big.Int).Add(
-		valueWei,
-		new(big.Int).Mul(gasFeeCap, big.NewInt(int64(gasLimit))),
-	)
-
-	if balance.Cmp(totalCost) < 0 {
-		log.Fatalf("insufficient balance: have %s wei, need %s wei", balance.String(), totalCost.String())
+	out.Field("Nonce", signedTx.Nonce())
+	out.Field("Tx Hash", signedTx.Hash())
+	if confirmations == 0 {
+		out.Note("\nTransaction is pending. Use --tx flag to query status:")
+		out.Note("  go run main.go --tx %s", signedTx.Hash().Hex())
+		return
 	}
 
-	// 构造交易（EIP-1559 动态费用交易）
-	txData := &types.DynamicFeeTx{
-		ChainID:   chainID,
-		Nonce:     nonce,
-		GasTipCap: gasTipCap,
-		GasFeeCap: gasFeeCap,
-		Gas:       gasLimit,
-		To:        &toAddr,
-		Value:     valueWei,
-		Data:      nil,
+	// 等待 confirmations 个确认：不受上面发送用的超时限制，回执所在区块被重组掉时继续等待重新打包
+	out.Note("\nWaiting for %d confirmation(s)...", confirmations)
+	cfg := txwait.DefaultConfig()
+	cfg.OnUpdate = func(u txwait.Update) {
+		if u.Reorged != nil {
+			out.Note("Block %d was reorged out, waiting again...", u.Reorged.BlockNumber.Uint64())
+		} else if u.Receipt != nil {
+			out.Note("Block %d: %d/%d confirmations", u.Receipt.BlockNumber.Uint64(), u.Confirmations, confirmations)
+		}
 	}
-	tx := types.NewTx(txData)
-
-	// 签名交易
-	signer := types.NewLondonSigner(chainID)
-	signedTx, err := types.SignTx(tx, signer, privKey)
+	receipt, err := txwait.Wait(context.WithoutCancel(ctx), client, signedTx.Hash(), confirmations, cfg)
 	if err != nil {
-		log.Fatalf("failed to sign transaction: %v", err)
-	}
-
-	// 发送交易
-	if err := client.SendTransaction(ctx, signedTx); err != nil {
-		log.Fatalf("failed to send transaction: %v", err)
+		log.Fatalf("failed to wait for transaction: %v", err)
 	}
-
-	// 输出交易信息
-	fmt.Println("=== Transaction Sent ===")
-	fmt.Printf("From       : %s\n", fromAddr.Hex())
-	fmt.Printf("To         : %s\n", toAddr.Hex())
-	fmt.Printf("Value      : %s ETH (%s Wei)\n", fmt.Sprintf("%.6f", amountEth), valueWei.String())
-	fmt.Printf("Gas Limit  : %d\n", gasLimit)
-	fmt.Printf("Gas Tip Cap: %s Wei\n", gasTipCap.String())
-	fmt.Printf("Gas Fee Cap: %s Wei\n", gasFeeCap.String())
-	fmt.Printf("Nonce      : %d\n", nonce)
-	fmt.Printf("Tx Hash    : %s\n", signedTx.Hash().Hex())
-	fmt.Println("\nTransaction is pending. Use --tx flag to query status:")
-	fmt.Printf("  go run main.go --tx %s\n", signedTx.Hash().Hex())
+	out.Section("Receipt")
+	printReceiptInfo(out, receipt)
 }
 
-func printTxBasicInfo(tx *types.Transaction, isPending bool) {
-	fmt.Printf("Hash        : %s\n", tx.Hash().Hex())
-	fmt.Printf("Nonce       : %d\n", tx.Nonce())
-	fmt.Printf("Gas         : %d\n", tx.Gas())
-	fmt.Printf("Gas Price   : %s\n", tx.GasPrice().String())
-	fmt.Printf("To          : %v\n", tx.To())
-	fmt.Printf("Value (Wei) : %s\n", tx.Value().String())
-	fmt.Printf("Data Len    : %d bytes\n", len(tx.Data()))
-	fmt.Printf("Pending     : %v\n", isPending)
+func printTxBasicInfo(out *output.Printer, tx *types.Transaction, isPending bool) {
+	out.Field("Hash", tx.Hash())
+	out.Field("Nonce", tx.Nonce())
+	out.Field("Gas", tx.Gas())
+	out.Field("Gas Price", tx.GasPrice())
+	out.Field("To", tx.To())
+	out.Field("Value (Wei)", tx.Value())
+	out.Field("Data Len", len(tx.Data()))
+	out.Field("Pending", isPending)
 }
 
-func printReceiptInfo(r *types.Receipt) {
-	fmt.Printf("Status      : %d\n", r.Status)
-	fmt.Printf("BlockNumber : %d\n", r.BlockNumber.Uint64())
-	fmt.Printf("BlockHash   : %s\n", r.BlockHash.Hex())
-	fmt.Printf("TxIndex     : %d\n", r.TransactionIndex)
-	fmt.Printf("Gas Used    : %d\n", r.GasUsed)
-	fmt.Printf("Logs        : %d\n", len(r.Logs))
+func printReceiptInfo(out *output.Printer, r *types.Receipt) {
+	out.Field("Status", r.Status)
+	out.Field("BlockNumber", r.BlockNumber.Uint64())
+	out.Field("BlockHash", r.BlockHash)
+	out.Field("TxIndex", r.TransactionIndex)
+	out.Field("Gas Used", r.GasUsed)
+	out.Field("Logs", len(r.Logs))
 	if len(r.Logs) > 0 {
-		fmt.Printf("First Log Address : %s\n", r.Logs[0].Address.Hex())
-	}
-}
-
-// trim0x 移除十六进制字符串前缀 "0x"
-func trim0x(s string) string {
-	if len(s) >= 2 && s[:2] == "0x" {
-		return s[2:]
+		out.Field("First Log Address", r.Logs[0].Address)
 	}
-	return s
 }
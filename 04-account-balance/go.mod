@@ -1,9 +1,11 @@
-module github.com/examples/04-account-balance
+module github.com/yzucdh1/examples/04-account-balance
 
 go 1.25.5
 
 require github.com/ethereum/go-ethereum v1.16.8
 
+require gopkg.in/yaml.v3 v3.0.1 // indirect
+
 require (
 	github.com/Microsoft/go-winio v0.6.2 // indirect
 	github.com/ProjectZKM/Ziren/crates/go-runtime/zkvm_runtime v0.0.0-20251001021608-1fe7b43fc4d6 // indirect
@@ -23,7 +25,10 @@ require (
 	github.com/supranational/blst v0.3.16-0.20250831170142-f48500c1fdbe // indirect
 	github.com/tklauser/go-sysconf v0.3.12 // indirect
 	github.com/tklauser/numcpus v0.6.1 // indirect
+	github.com/yzucdh1/examples/internal v0.0.0
 	golang.org/x/crypto v0.36.0 // indirect
 	golang.org/x/sync v0.12.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
 )
+
+replace github.com/yzucdh1/examples/internal => ../internal
@@ -3,15 +3,15 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
 	"log"
-	"math"
 	"math/big"
-	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
 )
 
 // 04-account-balance.go
@@ -19,21 +19,23 @@ import (
 func main() {
 	addrHex := flag.String("address", "", "account address (required)")
 	blockNumber := flag.Int64("block", -1, "block number to query (-1 means latest)")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
 	flag.Parse()
 
 	if *addrHex == "" {
 		log.Fatal("missing --address flag")
 	}
 
-	rpcURL := os.Getenv("ETH_RPC_URL")
+	rpcURL := config.Get().RPCURL
 	if rpcURL == "" {
-		log.Fatal("ETH_RPC_URL is not set")
+		log.Fatal(config.ErrNoRPC)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(15*time.Second))
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	client, err := rpcretry.Dial(ctx, rpcURL)
 	if err != nil {
 		log.Fatalf("failed to connect to Ethereum node: %v", err)
 	}
@@ -51,21 +53,16 @@ func main() {
 		log.Fatalf("failed to get balance: %v", err)
 	}
 
-	fmt.Println("=== Account Balance ===")
-	fmt.Printf("Address     : %s\n", address.Hex())
+	out.Section("Account Balance")
+	out.Field("Address", address)
 	if blockNum == nil {
-		fmt.Printf("Block       : latest\n")
+		out.Field("Block", "latest")
 	} else {
-		fmt.Printf("Block       : %d\n", blockNum.Uint64())
+		out.Field("Block", blockNum.Uint64())
+	}
+	out.Field("Balance Wei", balanceWei)
+	out.Field("Balance ETH", ethutil.FormatEth(balanceWei, 6))
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
 	}
-	fmt.Printf("Balance Wei : %s\n", balanceWei.String())
-
-	balanceEth := weiToEth(balanceWei)
-	fmt.Printf("Balance ETH : %s\n", balanceEth.Text('f', 6))
-}
-
-func weiToEth(wei *big.Int) *big.Float {
-	fWei := new(big.Float).SetInt(wei)
-	ethValue := new(big.Float).Quo(fWei, big.NewFloat(math.Pow10(18)))
-	return ethValue
 }
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -10,27 +10,33 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
 )
 
 // 01-subscribe-blocks.go
 // 通过 SubscribeNewHead 订阅新区块头。
 // 注意：大多数节点要求使用 WebSocket RPC，例如：ws://127.0.0.1:8546 或 wss://...
 func main() {
-	rpcURL := os.Getenv("ETH_WS_URL")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	rpcURL := config.Get().WSURL
 	if rpcURL == "" {
 		// 回退到 ETH_RPC_URL，便于在只配置了 HTTP 的环境中看到错误提示
-		rpcURL = os.Getenv("ETH_RPC_URL")
+		rpcURL = config.Get().RPCURL
 	}
 	if rpcURL == "" {
-		log.Fatal("ETH_WS_URL or ETH_RPC_URL must be set")
+		log.Fatal(config.ErrNoRPC)
 	}
 
 	// 取消功能的上下文,不需要超时时间,长连接
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	client, err := rpcretry.Dial(ctx, rpcURL)
 	if err != nil {
 		log.Fatalf("failed to connect to Ethereum node: %v", err)
 	}
@@ -43,7 +49,7 @@ func main() {
 		log.Fatalf("failed to subscribe new heads: %v", err)
 	}
 
-	fmt.Printf("Subscribed to new blocks via %s\n", rpcURL)
+	out.Note("Subscribed to new blocks via %s", rpcURL)
 
 	// 捕获 Ctrl+C 退出
 	sigCh := make(chan os.Signal, 1)
@@ -55,19 +61,20 @@ func main() {
 			if h == nil {
 				continue
 			}
-			fmt.Printf("[%s] New Block - Number: %d, Hash: %s\n",
-				time.Now().Format(time.RFC3339),
-				h.Number.Uint64(),
-				h.Hash().Hex(),
-			)
+			now := time.Now().Format(time.RFC3339)
+			out.Note("[%s] New Block - Number: %d, Hash: %s", now, h.Number.Uint64(), h.Hash().Hex())
+			// --format json 等结构化格式下每个区块输出一条记录
+			if err := out.Record("block", "time", now, "number", h.Number.Uint64(), "hash", h.Hash()); err != nil {
+				log.Printf("failed to write record: %v", err)
+			}
 		case err := <-sub.Err():
 			log.Printf("subscription error: %v", err)
 			return
 		case sig := <-sigCh:
-			fmt.Printf("received signal %s, shutting down...\n", sig.String())
+			out.Note("received signal %s, shutting down...", sig.String())
 			return
 		case <-ctx.Done():
-			fmt.Println("context cancelled, exiting...")
+			out.Note("context cancelled, exiting...")
 			return
 		}
 	}
@@ -2,13 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"math/big"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
@@ -18,66 +18,51 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/abis"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+	"github.com/yzucdh1/examples/internal/scan"
 )
 
 // 06-subscribe-logs.go
 // 订阅指定合约的日志事件（如 ERC-20 Transfer），并解析事件参数。
 // 本示例展示了如何从 logs 中解析出事件，包括 indexed 参数和普通参数。
-
-// ERC-20 标准 ABI（包含 Transfer 事件定义）
-const erc20ABIJSON = `[
-  {
-    "anonymous": false,
-    "inputs": [
-      {"indexed": true, "name": "from", "type": "address"},
-      {"indexed": true, "name": "to", "type": "address"},
-      {"indexed": false, "name": "value", "type": "uint256"}
-    ],
-    "name": "Transfer",
-    "type": "event"
-  },
-  {
-    "anonymous": false,
-    "inputs": [
-      {"indexed": true, "name": "owner", "type": "address"},
-      {"indexed": true, "name": "spender", "type": "address"},
-      {"indexed": false, "name": "value", "type": "uint256"}
-    ],
-    "name": "Approval",
-    "type": "event"
-  }
-]`
+// 指定 --from-block 时，订阅建立后先按 --chunk 个区块一段回溯从该区块到最新区块的历史日志，
+// 回溯过程定期输出进度，Ctrl-C 后以相同参数重新运行会从中断处继续（见 internal/scan）。
 
 func main() {
 	contractAddr := flag.String("contract", "", "contract address to subscribe logs from (required)")
+	fromBlock := flag.Uint64("from-block", 0, "backfill historical logs from this block before streaming (0 disables)")
+	chunk := flag.Uint64("chunk", 2000, "blocks per eth_getLogs request when backfilling")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	scanOpts := scan.AddFlags(flag.CommandLine, "logs-backfill.state.json")
 	flag.Parse()
 
 	if *contractAddr == "" {
 		log.Fatal("missing --contract flag")
 	}
 
-	rpcURL := os.Getenv("ETH_WS_URL")
+	rpcURL := config.Get().WSURL
 	if rpcURL == "" {
-		rpcURL = os.Getenv("ETH_RPC_URL")
+		rpcURL = config.Get().RPCURL
 	}
 	if rpcURL == "" {
-		log.Fatal("ETH_WS_URL or ETH_RPC_URL must be set")
+		log.Fatal(config.ErrNoRPC)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	client, err := rpcretry.Dial(ctx, rpcURL)
 	if err != nil {
 		log.Fatalf("failed to connect to Ethereum node: %v", err)
 	}
 	defer client.Close()
 
 	// 解析 ABI
-	parsedABI, err := abi.JSON(strings.NewReader(erc20ABIJSON))
-	if err != nil {
-		log.Fatalf("failed to parse ABI: %v", err)
-	}
+	parsedABI := abis.ERC20
 
 	contract := common.HexToAddress(*contractAddr)
 
@@ -91,8 +76,22 @@ func main() {
 		log.Fatalf("failed to subscribe logs: %v", err)
 	}
 
-	fmt.Printf("Subscribed to logs of contract %s via %s\n", contract.Hex(), rpcURL)
-	fmt.Printf("Listening for events...\n\n")
+	out.Note("Subscribed to logs of contract %s via %s", contract.Hex(), rpcURL)
+
+	// 订阅建立之后再回溯，回溯期间的新日志由订阅缓存，不会遗漏；不晚于回溯终点的日志已经输出过，实时阶段跳过
+	var backfilledTo uint64
+	if *fromBlock > 0 {
+		backfillCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		backfilledTo, err = backfill(backfillCtx, client, out, scanOpts, query, parsedABI, *fromBlock, *chunk)
+		stop()
+		if errors.Is(err, scan.ErrInterrupted) {
+			return
+		}
+		if err != nil {
+			log.Fatalf("failed to backfill logs: %v", err)
+		}
+	}
+	out.Note("Listening for events...\n")
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -100,27 +99,63 @@ func main() {
 	for {
 		select {
 		case vLog := <-logsCh:
+			if vLog.BlockNumber <= backfilledTo {
+				continue
+			}
 			// 解析日志事件
-			parseLogEvent(&vLog, parsedABI)
+			parseLogEvent(out, &vLog, parsedABI)
 		case err := <-sub.Err():
 			log.Printf("subscription error: %v", err)
 			return
 		case sig := <-sigCh:
-			fmt.Printf("received signal %s, shutting down...\n", sig.String())
+			out.Note("received signal %s, shutting down...", sig.String())
 			return
 		case <-ctx.Done():
-			fmt.Println("context cancelled, exiting...")
+			out.Note("context cancelled, exiting...")
 			return
 		}
 	}
 }
 
-// parseLogEvent 解析日志事件，展示如何从 logs 中提取事件信息
-func parseLogEvent(vLog *types.Log, parsedABI abi.ABI) {
+// backfill 按 chunk 个区块一段查询 [from, 当前最新区块] 的历史日志并逐条输出，返回回溯到的区块号
+func backfill(ctx context.Context, client *ethclient.Client, out *output.Printer, opts *scan.Options, query ethereum.FilterQuery, parsedABI abi.ABI, from, chunk uint64) (uint64, error) {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	if from > head {
+		return head, nil
+	}
+	out.Note("Backfilling logs from block %d to %d...\n", from, head)
+
+	res, err := opts.Run(ctx, scan.Scan{Name: "06-logs-backfill", Key: query.Addresses[0].Hex(), From: from, To: head, Step: chunk, Unit: "logs"},
+		func(ctx context.Context, from, to uint64) (int, error) {
+			q := query
+			q.FromBlock = new(big.Int).SetUint64(from)
+			q.ToBlock = new(big.Int).SetUint64(to)
+			logs, err := client.FilterLogs(ctx, q)
+			if err != nil {
+				return 0, err
+			}
+			for i := range logs {
+				parseLogEvent(out, &logs[i], parsedABI)
+			}
+			return len(logs), nil
+		})
+	if err == nil {
+		out.Note("Backfilled %d logs in %v\n", res.Items, res.Elapsed.Round(time.Millisecond))
+	}
+	return head, err
+}
+
+// parseLogEvent 解析日志事件，展示如何从 logs 中提取事件信息。
+// pretty 模式下逐步输出解析过程，结构化格式下每个事件输出一条记录（参数按名称展开）
+func parseLogEvent(out *output.Printer, vLog *types.Log, parsedABI abi.ABI) {
 	// 检查是否有 Topics（没有 Topics 的日志可能是无效的）
 	if len(vLog.Topics) == 0 {
 		return
 	}
+	now := time.Now().Format(time.RFC3339)
 
 	// 步骤 1: 识别事件类型
 	// Topics[0] 是事件签名的 keccak256 哈希值
@@ -143,29 +178,26 @@ func parseLogEvent(vLog *types.Log, parsedABI abi.ABI) {
 	}
 
 	if eventName == "" {
-		// 如果无法识别事件类型，打印原始信息
-		fmt.Printf("[%s] Unknown Event - Block: %d, Tx: %s, Topic[0]: %s\n",
-			time.Now().Format(time.RFC3339),
-			vLog.BlockNumber,
-			vLog.TxHash.Hex(),
-			eventTopic.Hex(),
-		)
+		// 如果无法识别事件类型，输出原始信息
+		out.Note("[%s] Unknown Event - Block: %d, Tx: %s, Topic[0]: %s", now, vLog.BlockNumber, vLog.TxHash.Hex(), eventTopic.Hex())
+		writeRecord(out, "unknown", "time", now, "block", vLog.BlockNumber, "tx", vLog.TxHash, "logIndex", vLog.Index, "topic0", eventTopic)
 		return
 	}
 
 	// 步骤 2: 解析事件参数
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	fmt.Printf("[%s] Event: %s\n", time.Now().Format(time.RFC3339), eventName)
-	fmt.Printf("  Block Number: %d\n", vLog.BlockNumber)
-	fmt.Printf("  Tx Hash     : %s\n", vLog.TxHash.Hex())
-	fmt.Printf("  Log Index   : %d\n", vLog.Index)
-	fmt.Printf("  Contract    : %s\n", vLog.Address.Hex())
-	fmt.Printf("  Topics Count: %d\n", len(vLog.Topics))
+	out.Note("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	out.Note("[%s] Event: %s", now, eventName)
+	out.Note("  Block Number: %d", vLog.BlockNumber)
+	out.Note("  Tx Hash     : %s", vLog.TxHash.Hex())
+	out.Note("  Log Index   : %d", vLog.Index)
+	out.Note("  Contract    : %s", vLog.Address.Hex())
+	out.Note("  Topics Count: %d", len(vLog.Topics))
+	record := []any{"time", now, "event", eventName, "block", vLog.BlockNumber, "tx", vLog.TxHash, "logIndex", vLog.Index, "contract", vLog.Address}
 
 	// 步骤 3: 解析 indexed 参数（从 Topics 中解析）
 	// Topics[0] 是事件签名哈希，Topics[1..N] 是 indexed 参数
 	// 注意：只有前 3 个 indexed 参数会放在 Topics 中（Ethereum 限制）
-	fmt.Printf("\n  Indexed Parameters (from Topics):\n")
+	out.Note("\n  Indexed Parameters (from Topics):")
 
 	// Topics[0] 是事件签名，所以 indexed 参数从 Topics[1] 开始
 	// 注意：topicIndex 只针对 indexed 参数计数，不考虑非 indexed 参数
@@ -183,34 +215,35 @@ func parseLogEvent(vLog *types.Log, parsedABI abi.ABI) {
 		}
 
 		topic := vLog.Topics[topicIndex]
-		fmt.Printf("    [%d] %s (%s): ", i+1, input.Name, input.Type)
+		var value any
+		suffix := ""
 
 		// 根据类型解析 indexed 参数
 		switch input.Type.T {
 		case abi.AddressTy:
 			// address 类型：去除前 12 字节的 0 填充，后 20 字节是地址
-			addr := common.BytesToAddress(topic.Bytes())
-			fmt.Printf("%s\n", addr.Hex())
+			value = common.BytesToAddress(topic.Bytes())
 		case abi.IntTy, abi.UintTy:
 			// 整数类型：直接转换为 big.Int
-			value := new(big.Int).SetBytes(topic.Bytes())
-			fmt.Printf("%s\n", value.String())
+			value = new(big.Int).SetBytes(topic.Bytes())
 		case abi.BoolTy:
 			// bool 类型：检查最后一个字节
-			fmt.Printf("%t\n", topic[31] != 0)
+			value = topic[31] != 0
 		case abi.BytesTy, abi.FixedBytesTy:
 			// bytes 类型：直接显示十六进制
-			fmt.Printf("%s\n", topic.Hex())
+			value = topic
 		default:
 			// 其他类型：显示原始十六进制
-			fmt.Printf("%s (raw)\n", topic.Hex())
+			value, suffix = topic, " (raw)"
 		}
+		out.Note("    [%d] %s (%s): %v%s", i+1, input.Name, input.Type, value, suffix)
+		record = append(record, input.Name, value)
 	}
 
 	// 步骤 4: 解析非 indexed 参数（从 Data 字段中解析）
 	// Data 字段包含所有非 indexed 参数的编码数据
 	if len(vLog.Data) > 0 {
-		fmt.Printf("\n  Non-Indexed Parameters (from Data):\n")
+		out.Note("\n  Non-Indexed Parameters (from Data):")
 
 		// 创建一个结构体来接收解码后的参数
 		// 注意：这里使用通用方法，实际应用中可能需要根据具体事件定义结构体
@@ -227,7 +260,7 @@ func parseLogEvent(vLog *types.Log, parsedABI abi.ABI) {
 			// 方法 2: 使用 Unpack（返回 []interface{}）
 			values, err := parsedABI.Unpack(eventName, vLog.Data)
 			if err != nil {
-				fmt.Printf("    Error decoding data: %v\n", err)
+				out.Note("    Error decoding data: %v", err)
 			} else {
 				// 只输出非 indexed 参数
 				nonIndexedIdx := 0
@@ -235,19 +268,15 @@ func parseLogEvent(vLog *types.Log, parsedABI abi.ABI) {
 					if !input.Indexed {
 						if nonIndexedIdx < len(values) {
 							value := values[nonIndexedIdx]
-							fmt.Printf("    [%d] %s (%s): ", i+1, input.Name, input.Type)
 
 							// 根据类型格式化输出
 							switch v := value.(type) {
-							case *big.Int:
-								fmt.Printf("%s\n", v.String())
-							case common.Address:
-								fmt.Printf("%s\n", v.Hex())
 							case []byte:
-								fmt.Printf("0x%x\n", v)
+								out.Note("    [%d] %s (%s): 0x%x", i+1, input.Name, input.Type, v)
 							default:
-								fmt.Printf("%v\n", v)
+								out.Note("    [%d] %s (%s): %v", i+1, input.Name, input.Type, v)
 							}
+							record = append(record, input.Name, value)
 							nonIndexedIdx++
 						}
 					}
@@ -255,8 +284,16 @@ func parseLogEvent(vLog *types.Log, parsedABI abi.ABI) {
 			}
 		}
 	} else {
-		fmt.Printf("\n  Non-Indexed Parameters: None\n")
+		out.Note("\n  Non-Indexed Parameters: None")
 	}
 
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n")
+	out.Note("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	writeRecord(out, "event", record...)
+}
+
+// writeRecord 结构化格式下输出一条记录，写入失败只记录日志，不中断订阅
+func writeRecord(out *output.Printer, kind string, kv ...any) {
+	if err := out.Record(kind, kv...); err != nil {
+		log.Printf("failed to write record: %v", err)
+	}
 }
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"flag"
 	"log"
 	"math"
 	"os"
@@ -11,19 +11,25 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
 )
 
 // 04-reconnect-strategy.go
 // 展示订阅断线后的简单重连策略（示意实现）。
 
 func main() {
-	rpcURL := os.Getenv("ETH_WS_URL")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	rpcURL := config.Get().WSURL
 	if rpcURL == "" {
-		rpcURL = os.Getenv("ETH_RPC_URL")
+		rpcURL = config.Get().RPCURL
 	}
 	if rpcURL == "" {
-		log.Fatal("ETH_WS_URL or ETH_RPC_URL must be set")
+		log.Fatal(config.ErrNoRPC)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -34,20 +40,20 @@ func main() {
 
 	go func() {
 		sig := <-sigCh
-		fmt.Printf("received signal %s, shutting down...\n", sig.String())
+		out.Note("received signal %s, shutting down...", sig.String())
 		cancel()
 	}()
 
-	runWithReconnect(ctx, rpcURL)
+	runWithReconnect(ctx, out, rpcURL)
 }
 
-func runWithReconnect(ctx context.Context, rpcURL string) {
+func runWithReconnect(ctx context.Context, out *output.Printer, rpcURL string) {
 	var attempt int
 
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("context cancelled, stop reconnect loop")
+			out.Note("context cancelled, stop reconnect loop")
 			return
 		default:
 		}
@@ -55,7 +61,7 @@ func runWithReconnect(ctx context.Context, rpcURL string) {
 		attempt++
 		log.Printf("connect attempt #%d to %s", attempt, rpcURL)
 
-		client, err := ethclient.DialContext(ctx, rpcURL)
+		client, err := rpcretry.Dial(ctx, rpcURL)
 		if err != nil {
 			log.Printf("failed to connect: %v", err)
 			sleepWithBackoff(ctx, attempt)
@@ -80,7 +86,10 @@ func runWithReconnect(ctx context.Context, rpcURL string) {
 				if h == nil {
 					continue
 				}
-				fmt.Printf("New Block: %d, Hash: %s\n", h.Number.Uint64(), h.Hash().Hex())
+				out.Note("New Block: %d, Hash: %s", h.Number.Uint64(), h.Hash().Hex())
+				if err := out.Record("block", "number", h.Number.Uint64(), "hash", h.Hash(), "attempt", attempt); err != nil {
+					log.Printf("failed to write record: %v", err)
+				}
 			case err := <-sub.Err():
 				log.Printf("subscription error: %v", err)
 				client.Close()
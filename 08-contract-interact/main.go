@@ -1,16 +1,17 @@
 package main
 
 import (
+	"cmp"
 	"context"
-	"crypto/ecdsa"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
-	"math"
 	"math/big"
 	"os"
-	"strconv"
-	"strings"
+	"os/signal"
+	"slices"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -19,13 +20,29 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/24-gas-tracker/gasoracle"
+	"github.com/yzucdh1/examples/internal/abis"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/decimal"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+	"github.com/yzucdh1/examples/internal/scan"
+	"github.com/yzucdh1/examples/internal/signer"
+	_ "github.com/yzucdh1/examples/internal/signer/kms" // --signer kms: / gcp-kms: / vault:
+	"github.com/yzucdh1/examples/internal/txbuilder"
+	"github.com/yzucdh1/examples/internal/txwait"
 )
 
 // 08-contract-interact.go
 // 使用通用 ABI 调用 ERC-20 合约的方法，包括：
 // 1. balanceOf: 查询余额（只读调用）
-// 2. transfer: 发送 ERC-20 转账交易（需要设置 SENDER_PRIVATE_KEY 环境变量）
+// 2. transfer: 发送 ERC-20 转账交易（需要设置 SENDER_PRIVATE_KEY 环境变量），
+//    费用按 --speed slow|standard|fast 档位由 24-gas-tracker/gasoracle 给出，
+//    发送后等待 --confirmations 个确认（见 internal/txwait，可感知重组）
 // 3. parse-event: 从交易回执中解析 Transfer 事件，展示 indexed 参数和 data 的对应关系
+// 4. history: 按 --chunk 个区块一段扫描区块范围内的 Transfer 事件（可按 --address 过滤转入 / 转出），
+//    定期输出进度，Ctrl-C 后以相同参数重新运行会从中断处继续（见 internal/scan）
 //
 // 执行示例：
 //
@@ -56,94 +73,86 @@ import (
 //    go run main.go --mode parse-event \
 //      --tx 0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef
 //
+// 5. 扫描某地址的历史转账（--to-block 缺省为最新区块）：
+//    export ETH_RPC_URL="http://127.0.0.1:8545"
+//    go run main.go --mode history \
+//      --contract 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48 \
+//      --address 0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb \
+//      --from-block 19000000 --to-block 19100000
+//
 // 注意事项：
 // - 所有示例中的地址和交易哈希都是示例，请替换为实际值
-// - transfer 模式需要设置 SENDER_PRIVATE_KEY 环境变量（私钥十六进制，可带或不带 0x 前缀）
+// - transfer 模式需要设置 SENDER_PRIVATE_KEY 环境变量（私钥十六进制，可带或不带 0x 前缀），
+//   或在配置文件的 profile 中配置 keystore、助记词或远程签名服务（见 internal/config、internal/signer）；
+//   生产环境可用 --signer kms:alias/...（AWS KMS）、gcp-kms:... 或 vault:...，私钥不出现在环境变量中（见 internal/signer/kms）
 // - 仅在测试网或本地开发链上使用，不要在主网使用包含真实资产的私钥
 // - amount 参数支持两种格式：
 //   * 小数格式（如 "1.5"）：自动根据代币的 decimals 转换为最小单位
 //   * 整数格式（如 "1500000"）：直接作为代币的最小单位使用
 
-const erc20ABIJSON = `[
-  {
-    "constant": true,
-    "inputs": [{"name": "owner", "type": "address"}],
-    "name": "balanceOf",
-    "outputs": [{"name": "balance", "type": "uint256"}],
-    "type": "function"
-  },
-  {
-    "constant": true,
-    "inputs": [],
-    "name": "decimals",
-    "outputs": [{"name": "", "type": "uint8"}],
-    "type": "function"
-  },
-  {
-    "constant": false,
-    "inputs": [
-      {"name": "to", "type": "address"},
-      {"name": "value", "type": "uint256"}
-    ],
-    "name": "transfer",
-    "outputs": [{"name": "", "type": "bool"}],
-    "type": "function"
-  },
-  {
-    "anonymous": false,
-    "inputs": [
-      {"indexed": true, "name": "from", "type": "address"},
-      {"indexed": true, "name": "to", "type": "address"},
-      {"indexed": false, "name": "value", "type": "uint256"}
-    ],
-    "name": "Transfer",
-    "type": "event"
-  }
-]`
-
 func main() {
 	// 命令行参数
-	mode := flag.String("mode", "balance", "operation mode: balance, transfer, or parse-event")
+	mode := flag.String("mode", "balance", "operation mode: balance, transfer, parse-event, or history")
 	contractHex := flag.String("contract", "", "ERC-20 contract address")
-	addrHex := flag.String("address", "", "address (for balanceOf or transfer to)")
+	addrHex := flag.String("address", "", "address (for balanceOf, or to filter transfers in history)")
 	toHex := flag.String("to", "", "recipient address (for transfer)")
 	amount := flag.String("amount", "", "transfer amount (for transfer, can be token amount like 1.5 or raw amount)")
 	txHashHex := flag.String("tx", "", "transaction hash (for parse-event)")
+	speedFlag := flag.String("speed", "standard", "fee level for transfer: slow | standard | fast")
+	confirmations := flag.Uint64("confirmations", 1, "confirmations to wait for after transfer")
+	fromBlock := flag.Uint64("from-block", 0, "first block to scan (for history)")
+	toBlock := flag.Uint64("to-block", 0, "last block to scan, 0 means latest (for history)")
+	chunk := flag.Uint64("chunk", 2000, "blocks per eth_getLogs request (for history)")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	scanOpts := scan.AddFlags(flag.CommandLine, "transfer-history.state.json")
+	signerFlag := flag.String("signer", "", "signer for transfer as scheme:ref, e.g. kms:alias/deployer (default $SENDER_SIGNER)")
 	flag.Parse()
 
-	rpcURL := os.Getenv("ETH_RPC_URL")
+	// --signer 优先于 SENDER_SIGNER、SENDER_PRIVATE_KEY 和配置文件
+	if *signerFlag != "" {
+		config.Get().Signer = *signerFlag
+	}
+
+	rpcURL := config.Get().RPCURL
 	if rpcURL == "" {
-		log.Fatal("ETH_RPC_URL is not set")
+		log.Fatal(config.ErrNoRPC)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(20*time.Second))
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	client, err := rpcretry.Dial(ctx, rpcURL)
 	if err != nil {
 		log.Fatalf("failed to connect to Ethereum node: %v", err)
 	}
 	defer client.Close()
 
-	parsedABI, err := abi.JSON(strings.NewReader(erc20ABIJSON))
-	if err != nil {
-		log.Fatalf("failed to parse ABI: %v", err)
-	}
+	parsedABI := abis.ERC20
 
 	switch *mode {
 	case "balance":
-		handleBalanceOf(ctx, client, parsedABI, *contractHex, *addrHex)
+		handleBalanceOf(ctx, client, out, parsedABI, *contractHex, *addrHex)
 	case "transfer":
-		handleTransfer(ctx, client, parsedABI, *contractHex, *toHex, *amount)
+		speed, err := gasoracle.ParseSpeed(*speedFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		handleTransfer(ctx, client, out, parsedABI, *contractHex, *toHex, *amount, speed, *confirmations)
 	case "parse-event":
-		handleParseEvent(ctx, client, parsedABI, *txHashHex)
+		handleParseEvent(ctx, client, out, parsedABI, *txHashHex)
+	case "history":
+		handleHistory(ctx, client, out, scanOpts, parsedABI, *contractHex, *addrHex, *fromBlock, *toBlock, *chunk)
 	default:
-		log.Fatalf("unknown mode: %s (use: balance, transfer, or parse-event)", *mode)
+		log.Fatalf("unknown mode: %s (use: balance, transfer, parse-event, or history)", *mode)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
 	}
 }
 
 // handleBalanceOf 查询 ERC-20 代币余额
-func handleBalanceOf(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, contractHex, addrHex string) {
+func handleBalanceOf(ctx context.Context, client *ethclient.Client, out *output.Printer, parsedABI abi.ABI, contractHex, addrHex string) {
 	if contractHex == "" || addrHex == "" {
 		log.Fatal("missing --contract or --address flag for balance mode")
 	}
@@ -163,48 +172,41 @@ func handleBalanceOf(ctx context.Context, client *ethclient.Client, parsedABI ab
 	}
 
 	// 执行只读调用
-	output, err := client.CallContract(ctx, callMsg, nil)
+	result, err := client.CallContract(ctx, callMsg, nil)
 	if err != nil {
 		log.Fatalf("CallContract error: %v", err)
 	}
 
 	// 解码返回值
 	var balance *big.Int
-	err = parsedABI.UnpackIntoInterface(&balance, "balanceOf", output)
+	err = parsedABI.UnpackIntoInterface(&balance, "balanceOf", result)
 	if err != nil {
 		log.Fatalf("failed to unpack output: %v", err)
 	}
 
-	fmt.Printf("Contract : %s\n", contractAddr.Hex())
-	fmt.Printf("Address  : %s\n", targetAddr.Hex())
-	fmt.Printf("Balance  : %s (raw uint256)\n", balance.String())
+	out.Section("Token Balance")
+	out.Field("Contract", contractAddr)
+	out.Field("Address", targetAddr)
+	out.Field("Balance", balance) // raw uint256
+	// 按 decimals 精确换算为代币数量；不实现 decimals() 的合约只显示原始值
+	if decimals, err := getTokenDecimals(ctx, client, parsedABI, contractAddr); err == nil {
+		out.Field("Decimals", decimals)
+		out.Field("Tokens", decimal.FormatUnits(balance, decimals))
+	}
 }
 
 // handleTransfer 发送 ERC-20 transfer 交易
-func handleTransfer(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, contractHex, toHex, amountStr string) {
+func handleTransfer(ctx context.Context, client *ethclient.Client, out *output.Printer, parsedABI abi.ABI, contractHex, toHex, amountStr string, speed gasoracle.Speed, confirmations uint64) {
 	if contractHex == "" || toHex == "" || amountStr == "" {
 		log.Fatal("missing --contract, --to, or --amount flag for transfer mode")
 	}
 
-	// 检查私钥环境变量
-	privKeyHex := os.Getenv("SENDER_PRIVATE_KEY")
-	if privKeyHex == "" {
-		log.Fatal("SENDER_PRIVATE_KEY is not set (required for transfer mode)")
-	}
-
-	// 解析私钥
-	privKey, err := crypto.HexToECDSA(trim0x(privKeyHex))
+	// 按配置创建签名器（--signer、SENDER_PRIVATE_KEY、keystore、助记词或远程签名服务，见 internal/signer）
+	sgn, err := signer.FromConfig(ctx, config.Get())
 	if err != nil {
-		log.Fatalf("invalid private key: %v", err)
-	}
-
-	// 获取发送方地址
-	publicKey := privKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("error casting public key to ECDSA")
+		log.Fatalf("failed to load signer: %v", err)
 	}
-	fromAddr := crypto.PubkeyToAddress(*publicKeyECDSA)
+	fromAddr := sgn.Address()
 
 	contractAddr := common.HexToAddress(contractHex)
 	toAddr := common.HexToAddress(toHex)
@@ -215,26 +217,14 @@ func handleTransfer(ctx context.Context, client *ethclient.Client, parsedABI abi
 		log.Fatalf("failed to get token decimals: %v", err)
 	}
 
-	// 解析转账金额
+	// 解析转账金额（按十进制精确换算，不经过 float64，见 internal/decimal）
 	// 如果输入包含小数点，则认为是代币数量，需要根据 decimals 转换
 	// 如果输入是整数，则认为是代币的最小单位（类似 wei 的概念）
-	amount, err := parseTokenAmount(amountStr, decimals)
+	amount, err := ethutil.ParseTokenAmount(amountStr, decimals)
 	if err != nil {
 		log.Fatalf("invalid amount: %v", err)
 	}
 
-	// 获取链 ID
-	chainID, err := client.ChainID(ctx)
-	if err != nil {
-		log.Fatalf("failed to get chain id: %v", err)
-	}
-
-	// 获取 nonce
-	nonce, err := client.PendingNonceAt(ctx, fromAddr)
-	if err != nil {
-		log.Fatalf("failed to get nonce: %v", err)
-	}
-
 	// 编码 transfer 调用数据
 	// transfer(address to, uint256 value)
 	callData, err := parsedABI.Pack("transfer", toAddr, amount)
@@ -242,168 +232,88 @@ func handleTransfer(ctx context.Context, client *ethclient.Client, parsedABI abi
 		log.Fatalf("failed to pack transfer data: %v", err)
 	}
 
-	// 估算 Gas Limit（合约调用需要更多 Gas）
-	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
-		From: fromAddr,
-		To:   &contractAddr,
-		Data: callData,
+	// 构造、签名并发送交易（EIP-1559 动态费用交易）：nonce、gas（估算值加 20% 缓冲）、费用由 txbuilder 补全，
+	// 并检查 ETH 余额是否足够支付 gasFeeCap * gasLimit（ERC-20 转账不需要发送 ETH，只需要支付 Gas）。
+	// 费用按 --speed 档位取 24-gas-tracker 的 feeHistory 分位数建议，替代 "base fee * 2 + tip" 的简单策略
+	builder := txbuilder.New(client, sgn)
+	builder.Fees = gasoracle.FeeFunc(client, gasoracle.DefaultConfig(), speed)
+	signedTx, err := builder.Send(ctx, txbuilder.Intent{
+		To:   &contractAddr, // 合约地址，value 为 0
+		Data: callData,      // transfer 调用数据
 	})
 	if err != nil {
-		log.Fatalf("failed to estimate gas: %v", err)
+		log.Fatal(err)
 	}
-	// 增加 20% 的缓冲，避免 Gas 不足
-	gasLimit = gasLimit * 120 / 100
+	gasLimit, gasTipCap, gasFeeCap := signedTx.Gas(), signedTx.GasTipCap(), signedTx.GasFeeCap()
+	totalGasCost := new(big.Int).Mul(gasFeeCap, new(big.Int).SetUint64(gasLimit))
 
-	// 获取建议的 Gas 价格（使用 EIP-1559 动态费用）
-	gasTipCap, err := client.SuggestGasTipCap(ctx)
-	if err != nil {
-		log.Fatalf("failed to get gas tip cap: %v", err)
-	}
+	// 输出交易信息
+	out.Section("ERC-20 Transfer Transaction Sent")
+	out.Field("From", fromAddr)
+	out.Field("To", toAddr)
+	out.Field("Contract", contractAddr)
+	out.Field("Token Decimals", decimals)
+	// 显示代币数量（根据 decimals 转换）
+	out.Field("Amount", decimal.FormatUnits(amount, decimals))
+	out.Field("Raw Amount", amount)
+	out.Field("Gas Limit", gasLimit)
+	out.Field("Gas Tip Cap", gasTipCap) // wei
+	out.Field("Gas Fee Cap", gasFeeCap) // wei
+	out.Field("Estimated Cost", totalGasCost)
+	out.Field("Nonce", signedTx.Nonce())
+	out.Field("Tx Hash", signedTx.Hash())
+	out.Note("\nTransaction is pending. Waiting for confirmation...\n")
 
-	// 获取 base fee，计算 fee cap
-	header, err := client.HeaderByNumber(ctx, nil)
-	if err != nil {
-		log.Fatalf("failed to get header: %v", err)
-	}
+	// 等待交易确认
+	waitForTransaction(ctx, client, out, signedTx.Hash(), confirmations)
+}
 
-	baseFee := header.BaseFee
-	if baseFee == nil {
-		// 如果不支持 EIP-1559，使用传统 gas price
-		gasPrice, err := client.SuggestGasPrice(ctx)
-		if err != nil {
-			log.Fatalf("failed to get gas price: %v", err)
+// waitForTransaction 等待交易达到 confirmations 个确认并显示回执信息
+func waitForTransaction(ctx context.Context, client *ethclient.Client, out *output.Printer, txHash common.Hash, confirmations uint64) {
+	// 最多等待 2 分钟；每 3 秒轮询一次，期间回执所在区块被重组掉时继续等待重新打包
+	cfg := txwait.DefaultConfig()
+	cfg.Timeout = 2 * time.Minute
+	cfg.OnUpdate = func(u txwait.Update) {
+		switch {
+		case u.Reorged != nil:
+			out.Note("Block %d was reorged out, waiting for the transaction to be included again...", u.Reorged.BlockNumber.Uint64())
+		case u.Receipt != nil && u.Confirmations < confirmations:
+			out.Note("Included in block %d, %d/%d confirmations", u.Receipt.BlockNumber.Uint64(), u.Confirmations, confirmations)
 		}
-		baseFee = gasPrice
-	}
-
-	// fee cap = base fee * 2 + tip cap（简单策略）
-	gasFeeCap := new(big.Int).Add(
-		new(big.Int).Mul(baseFee, big.NewInt(2)),
-		gasTipCap,
-	)
-
-	// 检查 ETH 余额是否足够支付 Gas 费用
-	balance, err := client.BalanceAt(ctx, fromAddr, nil)
-	if err != nil {
-		log.Fatalf("failed to get balance: %v", err)
-	}
-
-	// 计算总费用：gasFeeCap * gasLimit（ERC-20 转账不需要发送 ETH，只需要支付 Gas）
-	totalGasCost := new(big.Int).Mul(gasFeeCap, big.NewInt(int64(gasLimit)))
-
-	if balance.Cmp(totalGasCost) < 0 {
-		log.Fatalf("insufficient ETH balance for gas: have %s wei, need %s wei", balance.String(), totalGasCost.String())
 	}
 
-	// 构造交易（EIP-1559 动态费用交易）
-	// 注意：ERC-20 transfer 的 value 为 0，调用数据在 Data 字段中
-	txData := &types.DynamicFeeTx{
-		ChainID:   chainID,
-		Nonce:     nonce,
-		GasTipCap: gasTipCap,
-		GasFeeCap: gasFeeCap,
-		Gas:       gasLimit,
-		To:        &contractAddr, // 合约地址
-		Value:     big.NewInt(0), // ERC-20 转账不需要发送 ETH
-		Data:      callData,      // transfer 调用数据
+	out.Note("Polling for transaction receipt...")
+	// main 中的 ctx 只有 20 秒，等待确认不受其限制，由 cfg.Timeout 控制
+	receipt, err := txwait.Wait(context.WithoutCancel(ctx), client, txHash, confirmations, cfg)
+	if errors.Is(err, txwait.ErrTimeout) {
+		out.Note("\nTimeout waiting for transaction confirmation: %v", err)
+		out.Note("You can check the transaction status later:")
+		out.Note("  go run main.go --mode parse-event --tx %s", txHash.Hex())
+		return
 	}
-	tx := types.NewTx(txData)
-
-	// 签名交易
-	signer := types.NewLondonSigner(chainID)
-	signedTx, err := types.SignTx(tx, signer, privKey)
 	if err != nil {
-		log.Fatalf("failed to sign transaction: %v", err)
-	}
-
-	// 发送交易
-	if err := client.SendTransaction(ctx, signedTx); err != nil {
-		log.Fatalf("failed to send transaction: %v", err)
+		log.Fatalf("failed to wait for transaction: %v", err)
 	}
 
-	// 输出交易信息
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	fmt.Printf("ERC-20 Transfer Transaction Sent\n")
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	fmt.Printf("From          : %s\n", fromAddr.Hex())
-	fmt.Printf("To            : %s\n", toAddr.Hex())
-	fmt.Printf("Contract      : %s\n", contractAddr.Hex())
-	fmt.Printf("Token Decimals: %d\n", decimals)
-	// 显示代币数量（根据 decimals 转换）
-	tokenAmount := formatTokenAmount(amount, decimals)
-	fmt.Printf("Amount        : %s tokens (%s raw units)\n", tokenAmount, amount.String())
-	fmt.Printf("Gas Limit     : %d\n", gasLimit)
-	fmt.Printf("Gas Tip Cap   : %s Wei\n", gasTipCap.String())
-	fmt.Printf("Gas Fee Cap   : %s Wei\n", gasFeeCap.String())
-	fmt.Printf("Estimated Cost: %s Wei\n", totalGasCost.String())
-	fmt.Printf("Nonce         : %d\n", nonce)
-	fmt.Printf("Tx Hash       : %s\n", signedTx.Hash().Hex())
-	fmt.Printf("\n")
-	fmt.Printf("Transaction is pending. Waiting for confirmation...\n")
-	fmt.Printf("\n")
-
-	// 等待交易确认
-	waitForTransaction(ctx, client, signedTx.Hash())
-}
-
-// waitForTransaction 等待交易确认并显示回执信息
-func waitForTransaction(ctx context.Context, client *ethclient.Client, txHash common.Hash) {
-	// 设置超时上下文（最多等待 2 分钟）
-	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
-	defer cancel()
-
-	ticker := time.NewTicker(3 * time.Second)
-	defer ticker.Stop()
-
-	fmt.Printf("Polling for transaction receipt...\n")
-	for {
-		select {
-		case <-waitCtx.Done():
-			fmt.Printf("\nTimeout waiting for transaction confirmation.\n")
-			fmt.Printf("You can check the transaction status later:\n")
-			fmt.Printf("  go run main.go --mode parse-event --tx %s\n", txHash.Hex())
-			return
+	// 交易已确认
+	out.Section("Transaction Confirmed")
+	out.Field("Status", receipt.Status) // 1=success, 0=failed
+	out.Field("Block Number", receipt.BlockNumber.Uint64())
+	out.Field("Block Hash", receipt.BlockHash)
+	out.Field("Gas Used", receipt.GasUsed)
+	out.Field("Logs Count", len(receipt.Logs))
 
-		case <-ticker.C:
-			receipt, err := client.TransactionReceipt(waitCtx, txHash)
-			if err != nil {
-				// 交易可能还在 pending
-				continue
-			}
-
-			// 交易已确认
-			fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-			fmt.Printf("Transaction Confirmed!\n")
-			fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-			fmt.Printf("Status       : %d (1=success, 0=failed)\n", receipt.Status)
-			fmt.Printf("Block Number : %d\n", receipt.BlockNumber.Uint64())
-			fmt.Printf("Block Hash   : %s\n", receipt.BlockHash.Hex())
-			fmt.Printf("Gas Used     : %d / %d\n", receipt.GasUsed, receipt.GasUsed)
-			fmt.Printf("Logs Count   : %d\n", len(receipt.Logs))
-
-			if receipt.Status == 0 {
-				fmt.Printf("\n⚠️  Transaction failed! Check the transaction on block explorer.\n")
-			} else {
-				fmt.Printf("\n✅ Transaction successful!\n")
-				if len(receipt.Logs) > 0 {
-					fmt.Printf("\nTo parse Transfer event from this transaction:\n")
-					fmt.Printf("  go run main.go --mode parse-event --tx %s\n", txHash.Hex())
-				}
-			}
-			fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-			return
+	if receipt.Status == 0 {
+		out.Note("\n⚠️  Transaction failed! Check the transaction on block explorer.")
+	} else {
+		out.Note("\n✅ Transaction successful!")
+		if len(receipt.Logs) > 0 {
+			out.Note("\nTo parse Transfer event from this transaction:")
+			out.Note("  go run main.go --mode parse-event --tx %s", txHash.Hex())
 		}
 	}
 }
 
-// trim0x 移除十六进制字符串前缀 "0x"
-func trim0x(s string) string {
-	if len(s) >= 2 && s[0:2] == "0x" {
-		return s[2:]
-	}
-	return s
-}
-
 // getTokenDecimals 查询 ERC-20 代币的 decimals（精度）
 func getTokenDecimals(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, contractAddr common.Address) (uint8, error) {
 	// 编码 decimals() 调用数据
@@ -418,14 +328,14 @@ func getTokenDecimals(ctx context.Context, client *ethclient.Client, parsedABI a
 	}
 
 	// 执行只读调用
-	output, err := client.CallContract(ctx, callMsg, nil)
+	result, err := client.CallContract(ctx, callMsg, nil)
 	if err != nil {
 		return 0, fmt.Errorf("failed to call decimals: %w", err)
 	}
 
 	// 解码返回值
 	var decimals uint8
-	err = parsedABI.UnpackIntoInterface(&decimals, "decimals", output)
+	err = parsedABI.UnpackIntoInterface(&decimals, "decimals", result)
 	if err != nil {
 		return 0, fmt.Errorf("failed to unpack decimals output: %w", err)
 	}
@@ -433,54 +343,9 @@ func getTokenDecimals(ctx context.Context, client *ethclient.Client, parsedABI a
 	return decimals, nil
 }
 
-// parseTokenAmount 解析代币数量字符串
-// 如果输入包含小数点（如 "1.5"），则认为是代币数量，需要根据 decimals 转换为最小单位
-// 如果输入是整数（如 "1500000000000000000"），则认为是代币的最小单位（类似 wei 的概念）
-func parseTokenAmount(amountStr string, decimals uint8) (*big.Int, error) {
-	// 检查是否包含小数点
-	if strings.Contains(amountStr, ".") {
-		// 解析为浮点数
-		amountFloat, err := strconv.ParseFloat(amountStr, 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid decimal amount: %w", err)
-		}
-
-		// 转换为 big.Float 进行精确计算
-		bigFloat := big.NewFloat(amountFloat)
-
-		// 乘以 10^decimals
-		multiplier := new(big.Float).SetFloat64(math.Pow10(int(decimals)))
-		bigFloat.Mul(bigFloat, multiplier)
-
-		// 转换为 big.Int（截断小数部分）
-		amount, _ := bigFloat.Int(nil)
-		return amount, nil
-	} else {
-		// 直接解析为整数（代币的最小单位）
-		amount, ok := new(big.Int).SetString(amountStr, 10)
-		if !ok {
-			return nil, fmt.Errorf("invalid integer amount: %s", amountStr)
-		}
-		return amount, nil
-	}
-}
-
-// formatTokenAmount 将代币的最小单位转换为可读的代币数量
-func formatTokenAmount(amount *big.Int, decimals uint8) string {
-	// 转换为 big.Float
-	amountFloat := new(big.Float).SetInt(amount)
-
-	// 除以 10^decimals
-	divisor := new(big.Float).SetFloat64(math.Pow10(int(decimals)))
-	amountFloat.Quo(amountFloat, divisor)
-
-	// 格式化为字符串，保留足够的小数位
-	return amountFloat.Text('f', int(decimals))
-}
-
 // handleParseEvent 从交易回执中解析 Transfer 事件
 // 详细展示 indexed 参数（存储在 Topics 中）和 non-indexed 参数（存储在 Data 中）的对应关系
-func handleParseEvent(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, txHashHex string) {
+func handleParseEvent(ctx context.Context, client *ethclient.Client, out *output.Printer, parsedABI abi.ABI, txHashHex string) {
 	if txHashHex == "" {
 		log.Fatal("missing --tx flag for parse-event mode")
 	}
@@ -493,15 +358,13 @@ func handleParseEvent(ctx context.Context, client *ethclient.Client, parsedABI a
 		log.Fatalf("failed to get transaction receipt: %v", err)
 	}
 
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	fmt.Printf("Transaction Receipt Analysis\n")
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	fmt.Printf("Tx Hash      : %s\n", txHash.Hex())
-	fmt.Printf("Block Number : %d\n", receipt.BlockNumber.Uint64())
-	fmt.Printf("Status       : %d (1=success, 0=failed)\n", receipt.Status)
-	fmt.Printf("Gas Used     : %d\n", receipt.GasUsed)
-	fmt.Printf("Logs Count   : %d\n", len(receipt.Logs))
-	fmt.Printf("\n")
+	out.Section("Transaction Receipt Analysis")
+	out.Field("Tx Hash", txHash)
+	out.Field("Block Number", receipt.BlockNumber.Uint64())
+	out.Field("Status", receipt.Status) // 1=success, 0=failed
+	out.Field("Gas Used", receipt.GasUsed)
+	out.Field("Logs Count", len(receipt.Logs))
+	out.Note("")
 
 	// 查找 Transfer 事件
 	transferEvent := parsedABI.Events["Transfer"]
@@ -515,109 +378,109 @@ func handleParseEvent(ctx context.Context, client *ethclient.Client, parsedABI a
 		}
 
 		foundTransfer = true
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("Transfer Event #%d\n", i+1)
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("Contract Address: %s\n", vLog.Address.Hex())
-		fmt.Printf("Log Index       : %d\n", vLog.Index)
-		fmt.Printf("\n")
+		out.Note("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		out.Note("Transfer Event #%d", i+1)
+		out.Note("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		out.Note("Contract Address: %s", vLog.Address.Hex())
+		out.Note("Log Index       : %d", vLog.Index)
+		out.Note("")
 
 		// ============================================================
 		// 事件存储结构说明
 		// ============================================================
-		fmt.Printf("Event Storage Structure:\n")
-		fmt.Printf("────────────────────────────────────────────────────────\n")
-		fmt.Printf("Transfer(address indexed from, address indexed to, uint256 value)\n")
-		fmt.Printf("\n")
-		fmt.Printf("事件参数存储位置：\n")
-		fmt.Printf("  • Topics[0]: 事件签名哈希 (Event Signature Hash)\n")
-		fmt.Printf("  • Topics[1]: from (indexed address) - 存储在 Topics 中\n")
-		fmt.Printf("  • Topics[2]: to (indexed address) - 存储在 Topics 中\n")
-		fmt.Printf("  • Data     : value (non-indexed uint256) - 存储在 Data 中\n")
-		fmt.Printf("\n")
-		fmt.Printf("为什么这样存储？\n")
-		fmt.Printf("  • indexed 参数：可以用于事件过滤和搜索，存储在 Topics 中\n")
-		fmt.Printf("  • non-indexed 参数：完整数据存储在 Data 中，使用 ABI 编码\n")
-		fmt.Printf("  • Topics 最多 4 个元素，因此最多 3 个 indexed 参数\n")
-		fmt.Printf("────────────────────────────────────────────────────────\n")
-		fmt.Printf("\n")
+		out.Note("Event Storage Structure:")
+		out.Note("────────────────────────────────────────────────────────")
+		out.Note("Transfer(address indexed from, address indexed to, uint256 value)")
+		out.Note("")
+		out.Note("事件参数存储位置：")
+		out.Note("  • Topics[0]: 事件签名哈希 (Event Signature Hash)")
+		out.Note("  • Topics[1]: from (indexed address) - 存储在 Topics 中")
+		out.Note("  • Topics[2]: to (indexed address) - 存储在 Topics 中")
+		out.Note("  • Data     : value (non-indexed uint256) - 存储在 Data 中")
+		out.Note("")
+		out.Note("为什么这样存储？")
+		out.Note("  • indexed 参数：可以用于事件过滤和搜索，存储在 Topics 中")
+		out.Note("  • non-indexed 参数：完整数据存储在 Data 中，使用 ABI 编码")
+		out.Note("  • Topics 最多 4 个元素，因此最多 3 个 indexed 参数")
+		out.Note("────────────────────────────────────────────────────────")
+		out.Note("")
 
 		// ============================================================
 		// 解析 Topics
 		// ============================================================
-		fmt.Printf("Topics (Indexed Parameters):\n")
-		fmt.Printf("────────────────────────────────────────────────────────\n")
-		fmt.Printf("Topics Count: %d\n", len(vLog.Topics))
-		fmt.Printf("\n")
+		out.Note("Topics (Indexed Parameters):")
+		out.Note("────────────────────────────────────────────────────────")
+		out.Note("Topics Count: %d", len(vLog.Topics))
+		out.Note("")
 
 		// Topics[0]: 事件签名哈希
-		fmt.Printf("Topics[0] (Event Signature Hash):\n")
-		fmt.Printf("  Hex: %s\n", vLog.Topics[0].Hex())
-		fmt.Printf("  Event: Transfer(address,address,uint256)\n")
-		fmt.Printf("  Signature: %s\n", transferEvent.Sig)
-		fmt.Printf("\n")
+		out.Note("Topics[0] (Event Signature Hash):")
+		out.Note("  Hex: %s", vLog.Topics[0].Hex())
+		out.Note("  Event: Transfer(address,address,uint256)")
+		out.Note("  Signature: %s", transferEvent.Sig)
+		out.Note("")
 
 		// Topics[1]: from (indexed address)
 		if len(vLog.Topics) >= 2 {
-			fmt.Printf("Topics[1] (from - indexed address):\n")
-			fmt.Printf("  Raw Hex: %s\n", vLog.Topics[1].Hex())
-			fmt.Printf("  Explanation: address 类型在 topic 中是 32 字节，前 12 字节为 0 填充\n")
+			out.Note("Topics[1] (from - indexed address):")
+			out.Note("  Raw Hex: %s", vLog.Topics[1].Hex())
+			out.Note("  Explanation: address 类型在 topic 中是 32 字节，前 12 字节为 0 填充")
 			// 解析 address：去除前 12 字节的 0 填充，后 20 字节是地址
 			fromAddr := common.BytesToAddress(vLog.Topics[1].Bytes())
-			fmt.Printf("  Parsed Address: %s\n", fromAddr.Hex())
-			fmt.Printf("\n")
+			out.Note("  Parsed Address: %s", fromAddr.Hex())
+			out.Note("")
 		}
 
 		// Topics[2]: to (indexed address)
 		if len(vLog.Topics) >= 3 {
-			fmt.Printf("Topics[2] (to - indexed address):\n")
-			fmt.Printf("  Raw Hex: %s\n", vLog.Topics[2].Hex())
-			fmt.Printf("  Explanation: address 类型在 topic 中是 32 字节，前 12 字节为 0 填充\n")
+			out.Note("Topics[2] (to - indexed address):")
+			out.Note("  Raw Hex: %s", vLog.Topics[2].Hex())
+			out.Note("  Explanation: address 类型在 topic 中是 32 字节，前 12 字节为 0 填充")
 			// 解析 address：去除前 12 字节的 0 填充，后 20 字节是地址
 			toAddr := common.BytesToAddress(vLog.Topics[2].Bytes())
-			fmt.Printf("  Parsed Address: %s\n", toAddr.Hex())
-			fmt.Printf("\n")
+			out.Note("  Parsed Address: %s", toAddr.Hex())
+			out.Note("")
 		}
 
 		// ============================================================
 		// 解析 Data
 		// ============================================================
-		fmt.Printf("Data (Non-Indexed Parameters):\n")
-		fmt.Printf("────────────────────────────────────────────────────────\n")
+		out.Note("Data (Non-Indexed Parameters):")
+		out.Note("────────────────────────────────────────────────────────")
 		if len(vLog.Data) > 0 {
-			fmt.Printf("Data Length: %d bytes\n", len(vLog.Data))
-			fmt.Printf("Raw Hex: 0x%x\n", vLog.Data)
-			fmt.Printf("\n")
-			fmt.Printf("Data 字段包含所有 non-indexed 参数的 ABI 编码数据\n")
-			fmt.Printf("对于 Transfer 事件，Data 中只包含 value (uint256)\n")
-			fmt.Printf("\n")
+			out.Note("Data Length: %d bytes", len(vLog.Data))
+			out.Note("Raw Hex: 0x%x", vLog.Data)
+			out.Note("")
+			out.Note("Data 字段包含所有 non-indexed 参数的 ABI 编码数据")
+			out.Note("对于 Transfer 事件，Data 中只包含 value (uint256)")
+			out.Note("")
 
 			// 使用 ABI 解码 Data 字段
 			// 注意：Unpack 只解码 Data 字段，不包含 Topics 中的 indexed 参数
 			values, err := parsedABI.Unpack("Transfer", vLog.Data)
 			if err != nil {
-				fmt.Printf("Error decoding data: %v\n", err)
+				out.Note("Error decoding data: %v", err)
 			} else {
-				fmt.Printf("Decoded Parameters from Data:\n")
+				out.Note("Decoded Parameters from Data:")
 				// Transfer 事件只有一个 non-indexed 参数：value
 				if len(values) > 0 {
 					value, ok := values[0].(*big.Int)
 					if ok {
-						fmt.Printf("  value (uint256): %s\n", value.String())
-						fmt.Printf("  Explanation: uint256 类型直接存储在 Data 中，使用 ABI 编码\n")
+						out.Note("  value (uint256): %s", value.String())
+						out.Note("  Explanation: uint256 类型直接存储在 Data 中，使用 ABI 编码")
 					}
 				}
 			}
 		} else {
-			fmt.Printf("Data is empty (all parameters are indexed)\n")
+			out.Note("Data is empty (all parameters are indexed)")
 		}
-		fmt.Printf("\n")
+		out.Note("")
 
 		// ============================================================
 		// 完整解析结果
 		// ============================================================
-		fmt.Printf("Complete Parsed Event:\n")
-		fmt.Printf("────────────────────────────────────────────────────────\n")
+		out.Note("Complete Parsed Event:")
+		out.Note("────────────────────────────────────────────────────────")
 		if len(vLog.Topics) >= 3 {
 			fromAddr := common.BytesToAddress(vLog.Topics[1].Bytes())
 			toAddr := common.BytesToAddress(vLog.Topics[2].Bytes())
@@ -633,17 +496,133 @@ func handleParseEvent(ctx context.Context, client *ethclient.Client, parsedABI a
 			}
 
 			if value != nil {
-				fmt.Printf("  from  : %s (from Topics[1])\n", fromAddr.Hex())
-				fmt.Printf("  to    : %s (from Topics[2])\n", toAddr.Hex())
-				fmt.Printf("  value : %s (from Data)\n", value.String())
+				// 各事件使用同一个段名，结构化输出中合并为数组
+				out.Section("Transfer Event")
+				out.Field("Log Index", vLog.Index)
+				out.Field("Contract", vLog.Address)
+				out.Field("From", fromAddr) // Topics[1]
+				out.Field("To", toAddr)     // Topics[2]
+				out.Field("Value", value)   // Data
 			}
 		}
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("\n")
+		out.Note("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 	}
 
 	if !foundTransfer {
-		fmt.Printf("No Transfer event found in this transaction.\n")
-		fmt.Printf("Total logs: %d\n", len(receipt.Logs))
+		out.Note("No Transfer event found in this transaction.")
+		out.Note("Total logs: %d", len(receipt.Logs))
+	}
+}
+
+// handleHistory 扫描区块范围内的 Transfer 事件；指定 --address 时只统计该地址转入和转出的记录
+func handleHistory(ctx context.Context, client *ethclient.Client, out *output.Printer, opts *scan.Options, parsedABI abi.ABI, contractHex, addrHex string, fromBlock, toBlock, chunk uint64) {
+	if contractHex == "" {
+		log.Fatal("missing --contract flag for history mode")
+	}
+	contractAddr := common.HexToAddress(contractHex)
+	if toBlock == 0 {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			log.Fatalf("failed to get latest block: %v", err)
+		}
+		toBlock = head
+	}
+	decimals, decErr := getTokenDecimals(ctx, client, parsedABI, contractAddr)
+
+	// 同一个过滤条件无法表达"from 或 to 是该地址"，指定地址时分别按 Topics[1] 和 Topics[2] 查询后合并
+	transferSig := crypto.Keccak256Hash([]byte(parsedABI.Events["Transfer"].Sig))
+	queries := []ethereum.FilterQuery{{Addresses: []common.Address{contractAddr}, Topics: [][]common.Hash{{transferSig}}}}
+	key := contractAddr.Hex()
+	var addr common.Address
+	if addrHex != "" {
+		addr = common.HexToAddress(addrHex)
+		topic := common.BytesToHash(addr.Bytes())
+		queries = []ethereum.FilterQuery{
+			{Addresses: []common.Address{contractAddr}, Topics: [][]common.Hash{{transferSig}, {topic}}},
+			{Addresses: []common.Address{contractAddr}, Topics: [][]common.Hash{{transferSig}, nil, {topic}}},
+		}
+		key += "/" + addr.Hex()
+	}
+
+	totalIn, totalOut := new(big.Int), new(big.Int)
+	format := func(v *big.Int) string {
+		if decErr != nil {
+			return v.String()
+		}
+		return decimal.FormatUnits(v, decimals)
+	}
+
+	// 扫描可能持续很久，不受整体超时限制；Ctrl-C 时保存进度后输出已统计的结果
+	scanCtx, stop := signal.NotifyContext(context.WithoutCancel(ctx), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	res, err := opts.Run(scanCtx, scan.Scan{Name: "08-transfer-history", Key: key, From: fromBlock, To: toBlock, Step: chunk, Unit: "transfers"},
+		func(ctx context.Context, from, to uint64) (int, error) {
+			var logs []types.Log
+			for _, q := range queries {
+				q.FromBlock = new(big.Int).SetUint64(from)
+				q.ToBlock = new(big.Int).SetUint64(to)
+				found, err := client.FilterLogs(ctx, q)
+				if err != nil {
+					return 0, err
+				}
+				logs = append(logs, found...)
+			}
+			// 自己转给自己的记录会被两个查询各返回一次
+			slices.SortFunc(logs, func(a, b types.Log) int {
+				return cmp.Or(cmp.Compare(a.BlockNumber, b.BlockNumber), cmp.Compare(a.Index, b.Index))
+			})
+			logs = slices.CompactFunc(logs, func(a, b types.Log) bool {
+				return a.BlockNumber == b.BlockNumber && a.Index == b.Index
+			})
+
+			for _, vLog := range logs {
+				if len(vLog.Topics) < 3 {
+					continue
+				}
+				values, err := parsedABI.Unpack("Transfer", vLog.Data)
+				if err != nil || len(values) == 0 {
+					continue
+				}
+				value, _ := values[0].(*big.Int)
+				if value == nil {
+					continue
+				}
+				from := common.BytesToAddress(vLog.Topics[1].Bytes())
+				to := common.BytesToAddress(vLog.Topics[2].Bytes())
+				if addrHex != "" {
+					if to == addr {
+						totalIn.Add(totalIn, value)
+					}
+					if from == addr {
+						totalOut.Add(totalOut, value)
+					}
+				}
+				out.Note("Block %d  %s -> %s  %s  (tx %s)", vLog.BlockNumber, from.Hex(), to.Hex(), format(value), vLog.TxHash.Hex())
+				if err := out.Record("transfer", "block", vLog.BlockNumber, "tx", vLog.TxHash, "logIndex", vLog.Index, "from", from, "to", to, "value", value); err != nil {
+					log.Printf("failed to write record: %v", err)
+				}
+			}
+			return len(logs), nil
+		})
+	if err != nil && !errors.Is(err, scan.ErrInterrupted) {
+		log.Fatalf("failed to scan transfers: %v", err)
+	}
+
+	out.Section("Transfer History")
+	out.Field("Contract", contractAddr)
+	out.Field("From Block", fromBlock)
+	out.Field("To Block", toBlock)
+	if res.Resumed {
+		out.Field("Resumed At", res.Start)
+	}
+	if err != nil {
+		out.Field("Stopped At", res.Next)
+	}
+	out.Field("Transfers", res.Items)
+	// 转入 / 转出合计只包含本次运行扫描到的区块，续扫时不含之前的部分
+	if addrHex != "" {
+		out.Field("Address", addr)
+		out.Field("Total In", format(totalIn))
+		out.Field("Total Out", format(totalOut))
 	}
 }
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 索引进度检查点：记录最后一条已处理日志的 (区块号, 日志索引)。
+// 退出前落盘，重启后从该区块开始补齐历史日志，并跳过位置不晚于检查点的日志，
+// 保证同一条日志不会因重启而重复入库。
+
+// Position 日志在链上的位置
+type Position struct {
+	BlockNumber uint64 `json:"block_number"`
+	LogIndex    uint   `json:"log_index"`
+}
+
+// After 判断 p 是否严格晚于 other
+func (p Position) After(other Position) bool {
+	if p.BlockNumber != other.BlockNumber {
+		return p.BlockNumber > other.BlockNumber
+	}
+	return p.LogIndex > other.LogIndex
+}
+
+// Checkpoint 线程安全的检查点，持久化为 JSON 文件
+type Checkpoint struct {
+	mu    sync.Mutex
+	path  string
+	pos   Position
+	valid bool
+	dirty bool
+}
+
+type checkpointFile struct {
+	Position
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoadCheckpoint 从文件加载检查点，文件不存在时返回空检查点
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	cp := &Checkpoint{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var f checkpointFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	cp.pos = f.Position
+	cp.valid = true
+	return cp, nil
+}
+
+// Get 返回当前检查点，ok=false 表示尚无检查点
+func (c *Checkpoint) Get() (Position, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pos, c.valid
+}
+
+// Advance 推进检查点（只前进不后退）
+func (c *Checkpoint) Advance(pos Position) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.valid && !pos.After(c.pos) {
+		return
+	}
+	c.pos = pos
+	c.valid = true
+	c.dirty = true
+}
+
+// Save 将检查点写入文件（先写临时文件再 rename，避免写一半时进程退出导致文件损坏）
+func (c *Checkpoint) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(checkpointFile{Position: c.pos, UpdatedAt: time.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace checkpoint: %w", err)
+	}
+
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Indexer 订阅 Transfer 日志并写入存储。
+// 订阅与处理解耦：订阅协程只负责把日志放进有界队列，处理协程从队列取出解码入库。
+// 停止时先退订并关闭队列，处理协程把队列中已收到的日志全部处理完才退出，
+// 这样 SIGTERM 时不会丢掉「已收到但尚未入库」的事件。
+
+const (
+	indexerQueueSize       = 1024
+	backfillChunkSize      = 2000
+	checkpointSaveInterval = 10 * time.Second
+)
+
+type Indexer struct {
+	client     *ethclient.Client
+	parsedABI  abi.ABI
+	contract   common.Address
+	store      *EventStore
+	watchlist  *Watchlist
+	checkpoint *Checkpoint
+	logger     *slog.Logger
+
+	queue chan types.Log
+	done  chan struct{}
+}
+
+// NewIndexer 创建索引器
+func NewIndexer(client *ethclient.Client, parsedABI abi.ABI, contract common.Address, store *EventStore, watchlist *Watchlist, checkpoint *Checkpoint) *Indexer {
+	return &Indexer{
+		client:     client,
+		parsedABI:  parsedABI,
+		contract:   contract,
+		store:      store,
+		watchlist:  watchlist,
+		checkpoint: checkpoint,
+		// 每次订阅会话分配一个 correlation_id，串联该会话内的所有索引日志
+		logger: slog.Default().With(
+			"component", "indexer",
+			"contract", contract.Hex(),
+			"correlation_id", newID(),
+		),
+		queue: make(chan types.Log, indexerQueueSize),
+		done:  make(chan struct{}),
+	}
+}
+
+// Start 建立订阅并启动处理协程。
+// subCtx 取消时停止订阅并关闭队列；workCtx 用于处理协程（应在排空队列之后再取消）。
+func (ix *Indexer) Start(subCtx, workCtx context.Context) {
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{ix.contract},
+	}
+
+	logsCh := make(chan types.Log)
+	spanCtx, span := startSpan(subCtx, "rpc.eth_subscribe",
+		attribute.String("rpc.method", "eth_subscribe"),
+		attribute.String("subscription", "logs"),
+	)
+	sub, err := ix.client.SubscribeFilterLogs(spanCtx, query, logsCh)
+	endSpan(span, err)
+	if err != nil {
+		fatal("failed to subscribe logs", "contract", ix.contract.Hex(), "err", err)
+	}
+
+	ix.logger.Info("listening Transfer events")
+
+	go ix.process(workCtx)
+	go ix.forward(subCtx, sub, logsCh)
+}
+
+// forward 先补齐检查点之后的历史日志，再把订阅收到的日志转发到队列
+func (ix *Indexer) forward(ctx context.Context, sub ethereum.Subscription, logsCh <-chan types.Log) {
+	defer close(ix.queue)
+	defer sub.Unsubscribe()
+
+	// 补齐阶段推送过的最后位置，订阅中不晚于该位置的日志已处理过，直接跳过
+	lastBackfilled, err := ix.backfill(ctx)
+	if err != nil {
+		ix.logger.Error("backfill failed", "err", err)
+	}
+
+	for {
+		select {
+		case vLog := <-logsCh:
+			if len(vLog.Topics) == 0 {
+				continue
+			}
+			if lastBackfilled != nil && !logPosition(vLog).After(*lastBackfilled) {
+				continue
+			}
+			select {
+			case ix.queue <- vLog:
+			case <-ctx.Done():
+				ix.logger.Info("context cancelled, stop subscription")
+				return
+			}
+		case err := <-sub.Err():
+			ix.logger.Error("subscription error", "err", err)
+			return
+		case <-ctx.Done():
+			ix.logger.Info("context cancelled, stop subscription")
+			return
+		}
+	}
+}
+
+// backfill 从检查点所在区块补齐到当前最新区块，返回最后推送的日志位置
+func (ix *Indexer) backfill(ctx context.Context) (*Position, error) {
+	from, ok := ix.checkpoint.Get()
+	if !ok {
+		return nil, nil
+	}
+
+	head, err := ix.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	ix.logger.Info("resuming from checkpoint", "block", from.BlockNumber, "log_index", from.LogIndex, "head", head)
+
+	var last *Position
+	for start := from.BlockNumber; start <= head; start += backfillChunkSize {
+		end := min(start+backfillChunkSize-1, head)
+
+		reqCtx, span := startSpan(ctx, "rpc.eth_getLogs",
+			attribute.String("rpc.method", "eth_getLogs"),
+			attribute.Int64("from_block", int64(start)),
+			attribute.Int64("to_block", int64(end)),
+		)
+		logs, err := ix.client.FilterLogs(reqCtx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{ix.contract},
+		})
+		endSpan(span, err)
+		if err != nil {
+			return last, fmt.Errorf("failed to filter logs [%d, %d]: %w", start, end, err)
+		}
+
+		for _, vLog := range logs {
+			pos := logPosition(vLog)
+			if len(vLog.Topics) == 0 || !pos.After(from) {
+				continue
+			}
+			select {
+			case ix.queue <- vLog:
+				last = &pos
+			case <-ctx.Done():
+				return last, ctx.Err()
+			}
+		}
+	}
+	return last, nil
+}
+
+// process 处理协程：队列关闭且排空后退出
+func (ix *Indexer) process(ctx context.Context) {
+	defer close(ix.done)
+
+	ticker := time.NewTicker(checkpointSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case vLog, ok := <-ix.queue:
+			if !ok {
+				return
+			}
+			ix.handleTransferLog(ctx, vLog)
+			ix.checkpoint.Advance(logPosition(vLog))
+		case <-ticker.C:
+			// 定期落盘，避免进程异常退出时检查点过旧
+			if err := ix.checkpoint.Save(); err != nil {
+				ix.logger.Warn("failed to save checkpoint", "err", err)
+			}
+		}
+	}
+}
+
+// Wait 等待队列中已收到的日志全部处理完，ctx 超时则放弃等待
+func (ix *Indexer) Wait(ctx context.Context) error {
+	select {
+	case <-ix.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d logs still queued: %w", len(ix.queue), ctx.Err())
+	}
+}
+
+// handleTransferLog 解码单条 Transfer 日志并入库，整个处理过程对应一个 span
+func (ix *Indexer) handleTransferLog(ctx context.Context, vLog types.Log) {
+	ctx, span := startSpan(ctx, "indexer.handle_log",
+		attribute.Int64("block.number", int64(vLog.BlockNumber)),
+		attribute.String("tx.hash", vLog.TxHash.Hex()),
+		attribute.Int("log.index", int(vLog.Index)),
+	)
+	defer span.End()
+
+	traceID := traceIDFrom(ctx)
+	logLogger := ix.logger.With(
+		"block", vLog.BlockNumber,
+		"tx", vLog.TxHash.Hex(),
+		"log_index", vLog.Index,
+	)
+	if traceID != "" {
+		logLogger = logLogger.With("trace_id", traceID)
+	}
+
+	// 解码事件
+	var event struct {
+		From  common.Address
+		To    common.Address
+		Value *big.Int
+	}
+
+	// 非 indexed 参数从 Data 解码
+	if err := ix.parsedABI.UnpackIntoInterface(&event, "Transfer", vLog.Data); err != nil {
+		logLogger.Warn("failed to unpack log data", "err", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "unpack failed")
+		return
+	}
+	// indexed 地址从 Topics[1], Topics[2]
+	if len(vLog.Topics) >= 3 {
+		event.From = common.BytesToAddress(vLog.Topics[1].Bytes())
+		event.To = common.BytesToAddress(vLog.Topics[2].Bytes())
+	}
+
+	_, storeSpan := startSpan(ctx, "store.add")
+	ix.store.Add(TransferEvent{
+		BlockNumber: vLog.BlockNumber,
+		BlockHash:   vLog.BlockHash.Hex(),
+		TxHash:      vLog.TxHash.Hex(),
+		From:        event.From.Hex(),
+		To:          event.To.Hex(),
+		Value:       event.Value.String(),
+		Timestamp:   time.Now(), // 简化：使用当前时间；可扩展为查询区块时间
+		TraceID:     traceID,
+	})
+	storeSpan.End()
+
+	ix.watchlist.OnTransfer(vLog.BlockNumber, vLog.TxHash, event.From, event.To, event.Value)
+
+	logLogger.Debug("transfer event stored", "from", event.From.Hex(), "to", event.To.Hex(), "value", event.Value.String())
+}
+
+func logPosition(vLog types.Log) Position {
+	return Position{BlockNumber: vLog.BlockNumber, LogIndex: vLog.Index}
+}
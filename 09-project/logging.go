@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// 结构化日志：
+// - 基于 log/slog，LOG_FORMAT=json|text 选择输出格式，LOG_LEVEL=debug|info|warn|error 控制级别
+// - HTTP 中间件为每个请求分配 request_id，并透传/生成 correlation_id，
+//   同时写回响应头，便于和调用方日志串联
+// - 索引器日志以字段形式携带 block / tx / log_index
+
+const (
+	headerRequestID     = "X-Request-ID"
+	headerCorrelationID = "X-Correlation-ID"
+)
+
+type ctxKey int
+
+const loggerKey ctxKey = iota
+
+// newLogger 根据环境变量构造 slog.Logger
+func newLogger() *slog.Logger {
+	var level slog.Level
+	switch strings.ToLower(os.Getenv("LOG_LEVEL")) {
+	case "debug":
+		level = slog.LevelDebug
+	case "warn":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		level = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	if strings.ToLower(os.Getenv("LOG_FORMAT")) == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
+
+// fatal 记录错误日志后退出进程（替代 log.Fatalf）
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// newID 生成 16 位十六进制随机 ID
+func newID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// withLogger 将 logger 放入 context
+func withLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// loggerFrom 从 context 取出 logger，不存在时返回默认 logger
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// statusRecorder 记录响应状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// requestLogging HTTP 中间件：分配 request_id / correlation_id 并记录访问日志
+func requestLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(headerRequestID)
+		if requestID == "" {
+			requestID = newID()
+		}
+		correlationID := r.Header.Get(headerCorrelationID)
+		if correlationID == "" {
+			correlationID = requestID
+		}
+		w.Header().Set(headerRequestID, requestID)
+		w.Header().Set(headerCorrelationID, correlationID)
+
+		logger := slog.Default().With(
+			"request_id", requestID,
+			"correlation_id", correlationID,
+		)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r.WithContext(withLogger(r.Context(), logger)))
+
+		logger.Info("http request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration", time.Since(start),
+			"remote", r.RemoteAddr,
+		)
+	})
+}
@@ -2,50 +2,65 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"log"
-	"math/big"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/abis"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/rpcmetrics"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // 一个最小可运行的"迷你区块浏览器 / ERC-20 监听服务"示例：
 // - 后台 goroutine 订阅指定 ERC-20 合约的 Transfer 事件
 // - 将最近 N 条事件缓存在内存中
 // - 通过 HTTP 接口 GET /events 返回最近事件列表
+// - GET /search?q=... 按交易哈希、区块号 / 区块哈希、地址搜索已索引事件
+// - 可选的数据保留任务，按时间 / 区块深度清理过期事件（GET /retention 查看统计）
+// - 节点参数与其他示例一致：--profile、--rpc、--timeout 或 ETH_WS_URL / ETH_RPC_URL
+// - 基于 slog 的结构化日志（LOG_FORMAT=json|text，LOG_LEVEL=debug|info|warn|error）
+// - 可选的 OpenTelemetry 链路追踪（设置 OTEL_EXPORTER_OTLP_ENDPOINT 启用）
+// - GET /metrics 以 Prometheus 格式输出按 RPC 方法统计的请求数、错误数和延迟（HTTP 节点，见 internal/rpcmetrics）
+// - 内嵌的 Web 看板：浏览器访问 http://localhost:8080/ 查看实时转账、成交量图表和过滤搜索
+// - 钱包关注列表：POST /watchlist 注册地址，跟踪其代币余额变化（GET /watchlist/{address}/history）
+// - 优雅退出：SIGTERM 后停止接收 HTTP 请求，排空已收到的日志并落盘检查点后再退出
+//
+// 数据保留相关环境变量（均为可选）：
+//   RETENTION_MAX_AGE      事件最长保留时长，例如 24h
+//   RETENTION_BLOCK_DEPTH  仅保留最近 N 个区块内的事件
+//   RETENTION_INTERVAL     清理任务执行间隔，默认 1m
+//
+// 关注列表相关环境变量（可选）：
+//   WATCHLIST_VERIFY_INTERVAL  通过 balanceOf 校准余额的间隔，默认 5m
+//
+// 退出与恢复相关环境变量（可选）：
+//   SHUTDOWN_TIMEOUT  优雅退出的总期限，默认 15s
+//   CHECKPOINT_FILE   索引检查点文件路径，默认 checkpoint.json；重启后从检查点补齐历史日志
 
-const erc20ABIJSON = `[
-  {
-    "anonymous": false,
-    "inputs": [
-      {"indexed": true, "name": "from", "type": "address"},
-      {"indexed": true, "name": "to", "type": "address"},
-      {"indexed": false, "name": "value", "type": "uint256"}
-    ],
-    "name": "Transfer",
-    "type": "event"
-  }
-]`
+//go:embed dashboard.html
+var dashboardHTML []byte
 
 type TransferEvent struct {
 	BlockNumber uint64    `json:"block_number"`
+	BlockHash   string    `json:"block_hash"`
 	TxHash      string    `json:"tx_hash"`
 	From        string    `json:"from"`
 	To          string    `json:"to"`
 	Value       string    `json:"value"` // 原始 uint256 字符串
 	Timestamp   time.Time `json:"timestamp"`
+	TraceID     string    `json:"trace_id,omitempty"` // 入库时的链路 ID，未启用追踪时为空
 }
 
 type EventStore struct {
@@ -79,59 +94,149 @@ func (s *EventStore) List() []TransferEvent {
 	return out
 }
 
+// Prune 删除入库时间早于 before 或区块号低于 minBlock 的事件，最多删除 max 条，
+// 返回实际删除条数。before 为零值 / minBlock 为 0 时对应规则不生效。
+func (s *EventStore) Prune(before time.Time, minBlock uint64, max int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.events[:0]
+	removed := 0
+	for _, e := range s.events {
+		expired := (!before.IsZero() && e.Timestamp.Before(before)) ||
+			(minBlock > 0 && e.BlockNumber < minBlock)
+		if expired && removed < max {
+			removed++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	s.events = kept
+	return removed
+}
+
 func main() {
-	rpcURL := os.Getenv("ETH_WS_URL")
+	config.AddFlags(flag.CommandLine)
+	flag.Parse()
+	slog.SetDefault(newLogger())
+
+	rpcURL := config.Get().WSURL
 	if rpcURL == "" {
-		rpcURL = os.Getenv("ETH_RPC_URL")
+		rpcURL = config.Get().RPCURL
 	}
 	if rpcURL == "" {
-		log.Fatal("ETH_WS_URL or ETH_RPC_URL must be set")
+		fatal(config.ErrNoRPC.Error())
 	}
 
 	contractHex := os.Getenv("ERC20_CONTRACT")
 	if contractHex == "" {
-		log.Fatal("ERC20_CONTRACT env is not set")
+		fatal("ERC20_CONTRACT env is not set")
 	}
 	contractAddr := common.HexToAddress(contractHex)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	shutdownTracing, err := initTracing(ctx)
 	if err != nil {
-		log.Fatalf("failed to connect to Ethereum node: %v", err)
+		fatal("failed to init tracing", "err", err)
 	}
-	defer client.Close()
 
-	parsedABI, err := abi.JSON(strings.NewReader(erc20ABIJSON))
+	client, err := rpcretry.Dial(ctx, rpcURL)
 	if err != nil {
-		log.Fatalf("failed to parse ABI: %v", err)
+		fatal("failed to connect to Ethereum node", "err", err)
 	}
+	defer client.Close()
+
+	parsedABI := abis.ERC20
 
 	store := NewEventStore(100)
 
-	// 启动后台订阅协程
-	go subscribeTransferEvents(ctx, client, parsedABI, contractAddr, store)
+	retentionCfg, err := loadRetentionConfig()
+	if err != nil {
+		fatal("invalid retention config", "err", err)
+	}
+	retainer := NewRetainer(retentionCfg, store, client)
+
+	verifyInterval := 5 * time.Minute
+	if v := os.Getenv("WATCHLIST_VERIFY_INTERVAL"); v != "" {
+		verifyInterval, err = time.ParseDuration(v)
+		if err != nil {
+			fatal("invalid WATCHLIST_VERIFY_INTERVAL", "err", err)
+		}
+	}
+	watchlist := NewWatchlist(client, parsedABI, contractAddr, 200)
+
+	shutdownTimeout := 15 * time.Second
+	if v := os.Getenv("SHUTDOWN_TIMEOUT"); v != "" {
+		shutdownTimeout, err = time.ParseDuration(v)
+		if err != nil {
+			fatal("invalid SHUTDOWN_TIMEOUT", "err", err)
+		}
+	}
+
+	checkpointPath := os.Getenv("CHECKPOINT_FILE")
+	if checkpointPath == "" {
+		checkpointPath = "checkpoint.json"
+	}
+	checkpoint, err := LoadCheckpoint(checkpointPath)
+	if err != nil {
+		fatal("failed to load checkpoint", "path", checkpointPath, "err", err)
+	}
+
+	// 启动订阅与处理协程；订阅使用独立的 context，退出时先于其他组件停止
+	subCtx, subCancel := context.WithCancel(ctx)
+	defer subCancel()
+	indexer := NewIndexer(client, parsedABI, contractAddr, store, watchlist, checkpoint)
+	indexer.Start(subCtx, ctx)
+
+	// 启动关注列表余额校准任务
+	go watchlist.Run(ctx, verifyInterval)
+
+	// 启动数据保留任务
+	if retentionCfg.Enabled() {
+		go retainer.Run(ctx)
+	}
 
 	// HTTP 接口
 	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(dashboardHTML)
+	})
 	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
+		_, span := startSpan(r.Context(), "store.list")
 		events := store.List()
-		_ = json.NewEncoder(w).Encode(events)
+		span.SetAttributes(attribute.Int("events.count", len(events)))
+		span.End()
+		if err := json.NewEncoder(w).Encode(events); err != nil {
+			loggerFrom(r.Context()).Warn("failed to encode events", "err", err)
+		}
 	})
+	mux.HandleFunc("/search", handleSearch(store))
+	registerWatchlistRoutes(mux, watchlist)
+	mux.HandleFunc("/retention", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(retainer.Stats())
+	})
+	mux.Handle("/metrics", rpcmetrics.Handler())
 
 	server := &http.Server{
 		Addr:         ":8080",
-		Handler:      mux,
+		Handler:      traceHTTP(requestLogging(mux)),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
 
 	go func() {
-		log.Printf("HTTP server listening on %s", server.Addr)
+		slog.Info("HTTP server listening", "addr", server.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("http server error: %v", err)
+			fatal("http server error", "err", err)
 		}
 	}()
 
@@ -139,66 +244,61 @@ func main() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	sig := <-sigCh
-	fmt.Printf("received signal %s, shutting down...\n", sig.String())
+	slog.Info("received signal, shutting down", "signal", sig.String(), "timeout", shutdownTimeout)
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer shutdownCancel()
-	_ = server.Shutdown(shutdownCtx)
+
+	// 1. 停止接收新的 HTTP 请求，等待进行中的请求结束
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("http server shutdown incomplete", "err", err)
+	}
+
+	// 2. 退订并排空已收到的日志
+	subCancel()
+	if err := indexer.Wait(shutdownCtx); err != nil {
+		slog.Warn("indexer drain incomplete", "err", err)
+	}
+
+	// 3. 落盘检查点（当前为内存存储，没有需要 flush 的待写数据）
+	if err := checkpoint.Save(); err != nil {
+		slog.Error("failed to save checkpoint", "path", checkpointPath, "err", err)
+	} else if pos, ok := checkpoint.Get(); ok {
+		slog.Info("checkpoint saved", "block", pos.BlockNumber, "log_index", pos.LogIndex)
+	}
+
+	// 4. 停止后台任务，刷新未导出的 trace
 	cancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Warn("failed to flush traces", "err", err)
+	}
+	slog.Info("shutdown complete")
 }
 
-func subscribeTransferEvents(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, contract common.Address, store *EventStore) {
-	query := ethereum.FilterQuery{
-		Addresses: []common.Address{contract},
-	}
+// loadRetentionConfig 从环境变量读取数据保留配置
+func loadRetentionConfig() (RetentionConfig, error) {
+	var cfg RetentionConfig
 
-	logsCh := make(chan types.Log)
-	sub, err := client.SubscribeFilterLogs(ctx, query, logsCh)
-	if err != nil {
-		log.Fatalf("failed to subscribe logs: %v", err)
-	}
-
-	log.Printf("listening Transfer events of %s", contract.Hex())
-
-	for {
-		select {
-		case vLog := <-logsCh:
-			if len(vLog.Topics) == 0 {
-				continue
-			}
-
-			// 解码事件
-			var event struct {
-				From  common.Address
-				To    common.Address
-				Value *big.Int
-			}
-
-			// 非 indexed 参数从 Data 解码
-			if err := parsedABI.UnpackIntoInterface(&event, "Transfer", vLog.Data); err != nil {
-				log.Printf("failed to unpack log data: %v", err)
-				continue
-			}
-			// indexed 地址从 Topics[1], Topics[2]
-			if len(vLog.Topics) >= 3 {
-				event.From = common.BytesToAddress(vLog.Topics[1].Bytes())
-				event.To = common.BytesToAddress(vLog.Topics[2].Bytes())
-			}
-
-			store.Add(TransferEvent{
-				BlockNumber: vLog.BlockNumber,
-				TxHash:      vLog.TxHash.Hex(),
-				From:        event.From.Hex(),
-				To:          event.To.Hex(),
-				Value:       event.Value.String(),
-				Timestamp:   time.Now(), // 简化：使用当前时间；可扩展为查询区块时间
-			})
-		case err := <-sub.Err():
-			log.Printf("subscription error: %v", err)
-			return
-		case <-ctx.Done():
-			log.Println("context cancelled, stop subscription")
-			return
+	if v := os.Getenv("RETENTION_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("RETENTION_MAX_AGE: %w", err)
+		}
+		cfg.MaxAge = d
+	}
+	if v := os.Getenv("RETENTION_BLOCK_DEPTH"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return cfg, fmt.Errorf("RETENTION_BLOCK_DEPTH: %w", err)
+		}
+		cfg.BlockDepth = n
+	}
+	if v := os.Getenv("RETENTION_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("RETENTION_INTERVAL: %w", err)
 		}
+		cfg.Interval = d
 	}
+	return cfg, nil
 }
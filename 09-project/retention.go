@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 数据保留与清理任务：
+// - 按时间（事件入库时间早于 now - MaxAge）清理
+// - 按区块深度（事件区块号低于 latest - BlockDepth）清理
+// - 记录每轮清理删除的条数，通过 GET /retention 暴露
+//
+// 清理按批次进行（每批最多 BatchSize 条），每批之间释放写锁，避免长时间阻塞 Add/List。
+//
+// 目前只作用于内存中的 EventStore（容量由 NewEventStore 限定，超出后本就会丢弃最旧的事件），
+// 规则只是在容量之外再按时间 / 区块深度提前淘汰。本示例没有持久化存储，
+// 也没有实现 SQLite 下的分批 DELETE 和增量 VACUUM。
+
+// RetentionConfig 数据保留策略配置，零值字段表示不启用对应规则
+type RetentionConfig struct {
+	MaxAge     time.Duration // 超过该时长的事件将被清理
+	BlockDepth uint64        // 距最新区块超过该深度的事件将被清理
+	Interval   time.Duration // 清理任务执行间隔
+	BatchSize  int           // 单批次最多删除条数
+}
+
+// Enabled 是否配置了任意一条保留规则
+func (c RetentionConfig) Enabled() bool {
+	return c.MaxAge > 0 || c.BlockDepth > 0
+}
+
+// RetentionStats 清理任务的运行统计
+type RetentionStats struct {
+	Runs         uint64    `json:"runs"`
+	TotalRemoved uint64    `json:"total_removed"`
+	LastRemoved  int       `json:"last_removed"`
+	LastRun      time.Time `json:"last_run"`
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// Retainer 周期性清理 EventStore 中的过期事件
+type Retainer struct {
+	cfg    RetentionConfig
+	store  *EventStore
+	client *ethclient.Client
+
+	mu    sync.RWMutex
+	stats RetentionStats
+}
+
+// NewRetainer 创建清理任务，BatchSize/Interval 未设置时使用默认值
+func NewRetainer(cfg RetentionConfig, store *EventStore, client *ethclient.Client) *Retainer {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	return &Retainer{
+		cfg:    cfg,
+		store:  store,
+		client: client,
+	}
+}
+
+// Run 阻塞运行清理循环，直到 ctx 被取消
+func (r *Retainer) Run(ctx context.Context) {
+	slog.Info("retention job started",
+		"max_age", r.cfg.MaxAge,
+		"block_depth", r.cfg.BlockDepth,
+		"interval", r.cfg.Interval,
+	)
+
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.runOnce(ctx)
+		case <-ctx.Done():
+			slog.Info("context cancelled, stop retention job")
+			return
+		}
+	}
+}
+
+// runOnce 执行一轮清理
+func (r *Retainer) runOnce(ctx context.Context) {
+	var cutoffTime time.Time
+	if r.cfg.MaxAge > 0 {
+		cutoffTime = time.Now().Add(-r.cfg.MaxAge)
+	}
+
+	var minBlock uint64
+	var runErr error
+	if r.cfg.BlockDepth > 0 {
+		reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		reqCtx, span := startSpan(reqCtx, "rpc.eth_blockNumber",
+			attribute.String("rpc.method", "eth_blockNumber"),
+		)
+		latest, err := r.client.BlockNumber(reqCtx)
+		endSpan(span, err)
+		cancel()
+		if err != nil {
+			// 拿不到最新区块时只跳过按深度清理，按时间清理照常进行
+			slog.Warn("retention: failed to get latest block", "err", err)
+			runErr = err
+		} else if latest > r.cfg.BlockDepth {
+			minBlock = latest - r.cfg.BlockDepth
+		}
+	}
+
+	_, span := startSpan(ctx, "store.prune")
+	removed := 0
+	for {
+		n := r.store.Prune(cutoffTime, minBlock, r.cfg.BatchSize)
+		removed += n
+		if n < r.cfg.BatchSize {
+			break
+		}
+	}
+
+	span.SetAttributes(attribute.Int("events.removed", removed))
+	span.End()
+
+	r.mu.Lock()
+	r.stats.Runs++
+	r.stats.TotalRemoved += uint64(removed)
+	r.stats.LastRemoved = removed
+	r.stats.LastRun = time.Now()
+	r.stats.LastError = ""
+	if runErr != nil {
+		r.stats.LastError = runErr.Error()
+	}
+	r.mu.Unlock()
+
+	if removed > 0 {
+		slog.Info("retention: events removed",
+			"removed", removed,
+			"cutoff_time", cutoffTime,
+			"min_block", minBlock,
+		)
+	}
+}
+
+// Stats 返回清理统计快照
+func (r *Retainer) Stats() RetentionStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.stats
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GET /search?q=... 搜索已索引的事件，根据查询串格式自动识别类型：
+//   - 0x + 64 位十六进制：先按交易哈希匹配，无结果再按区块哈希匹配
+//   - 0x + 40 位十六进制：地址，匹配 from 或 to
+//   - 十进制数字：区块号
+//
+// 只在内存中已缓存的事件里查找，不会回源到节点。
+
+// 查询类型
+const (
+	searchKindTxHash      = "tx_hash"
+	searchKindBlockHash   = "block_hash"
+	searchKindBlockNumber = "block_number"
+	searchKindAddress     = "address"
+)
+
+// SearchResult /search 接口返回结构
+type SearchResult struct {
+	Query  string          `json:"query"`
+	Kind   string          `json:"kind"`
+	Events []TransferEvent `json:"events"`
+}
+
+// Filter 返回满足 match 的事件副本
+func (s *EventStore) Filter(match func(TransferEvent) bool) []TransferEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]TransferEvent, 0)
+	for _, e := range s.events {
+		if match(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// searchEvents 识别查询类型并在 store 中查找，q 格式无法识别时返回 ok=false
+func searchEvents(store *EventStore, q string) (SearchResult, bool) {
+	q = strings.TrimSpace(q)
+	res := SearchResult{Query: q}
+
+	switch {
+	case len(q) == 66 && strings.HasPrefix(q, "0x") && isHex(q[2:]):
+		hash := common.HexToHash(q).Hex()
+		res.Kind = searchKindTxHash
+		res.Events = store.Filter(func(e TransferEvent) bool {
+			return strings.EqualFold(e.TxHash, hash)
+		})
+		if len(res.Events) == 0 {
+			res.Kind = searchKindBlockHash
+			res.Events = store.Filter(func(e TransferEvent) bool {
+				return strings.EqualFold(e.BlockHash, hash)
+			})
+		}
+	case common.IsHexAddress(q):
+		addr := common.HexToAddress(q).Hex()
+		res.Kind = searchKindAddress
+		res.Events = store.Filter(func(e TransferEvent) bool {
+			return e.From == addr || e.To == addr
+		})
+	default:
+		num, err := strconv.ParseUint(q, 10, 64)
+		if err != nil {
+			return res, false
+		}
+		res.Kind = searchKindBlockNumber
+		res.Events = store.Filter(func(e TransferEvent) bool {
+			return e.BlockNumber == num
+		})
+	}
+	return res, true
+}
+
+// isHex 判断字符串是否全部为十六进制字符
+func isHex(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// handleSearch GET /search 处理函数
+func handleSearch(store *EventStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		if q == "" {
+			http.Error(w, "missing q parameter", http.StatusBadRequest)
+			return
+		}
+
+		_, span := startSpan(r.Context(), "store.search")
+		res, ok := searchEvents(store, q)
+		span.SetAttributes(
+			attribute.String("search.kind", res.Kind),
+			attribute.Int("events.count", len(res.Events)),
+		)
+		span.End()
+		if !ok {
+			http.Error(w, "unrecognized query: expect tx hash, block hash, block number or address", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			loggerFrom(r.Context()).Warn("failed to encode search result", "err", err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OpenTelemetry 链路追踪：
+// - 设置 OTEL_EXPORTER_OTLP_ENDPOINT（如 http://localhost:4318）后启用 OTLP/HTTP 导出，
+//   未设置时使用全局 no-op tracer，不产生任何开销
+// - RPC 调用、存储操作、HTTP 处理各自生成 span
+// - 每条事件记录入库时的 trace_id，/events 返回时带上，
+//   从而可以把「收到日志 → 入库 → 对外提供」串成一条链路来排查延迟
+//
+// 其余 OTEL_* 环境变量（OTEL_SERVICE_NAME、OTEL_EXPORTER_OTLP_HEADERS 等）由 SDK 自动读取。
+
+const tracerName = "github.com/yzucdh1/examples/09-project"
+
+var tracer = otel.Tracer(tracerName)
+
+// initTracing 初始化全局 TracerProvider，返回的 shutdown 用于退出前刷新未导出的 span
+func initTracing(ctx context.Context) (func(context.Context) error, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" &&
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "09-project")),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	slog.Info("OpenTelemetry tracing enabled")
+	return tp.Shutdown, nil
+}
+
+// startSpan 创建子 span 的简写
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan 结束 span，err 非空时记录错误状态
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceHTTP 为 HTTP handler 包装服务端 span，span 名为 "METHOD /path"
+func traceHTTP(h http.Handler) http.Handler {
+	return otelhttp.NewHandler(h, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}),
+	)
+}
+
+// traceIDFrom 返回 ctx 中当前 span 的 trace_id，未采样时返回空字符串
+func traceIDFrom(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 钱包关注列表：
+// - POST   /watchlist                     注册关注地址，body: {"address": "0x..."}
+// - GET    /watchlist                     列出关注地址及当前余额
+// - DELETE /watchlist/{address}           取消关注
+// - GET    /watchlist/{address}/history   余额变化历史
+//
+// 余额维护方式：
+// - 注册时在某个确定区块 N 调用 balanceOf 取得基准余额（syncedBlock = N）
+// - 之后收到的 Transfer 事件若涉及该地址且区块号 > syncedBlock，则按 value 增减余额
+// - 后台定期重新调用 balanceOf 校准，发现偏差时记录告警并以链上值为准
+
+// 余额来源
+const (
+	balanceSourceSnapshot = "balanceOf"
+	balanceSourceTransfer = "transfer"
+)
+
+// BalancePoint 某一时刻的余额记录
+type BalancePoint struct {
+	BlockNumber uint64    `json:"block_number"`
+	Balance     string    `json:"balance"`
+	Source      string    `json:"source"`
+	TxHash      string    `json:"tx_hash,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// WatchedWallet 关注地址的对外展示结构
+type WatchedWallet struct {
+	Address     string    `json:"address"`
+	Balance     string    `json:"balance"`
+	SyncedBlock uint64    `json:"synced_block"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+type watchedWallet struct {
+	addedAt     time.Time
+	balance     *big.Int
+	syncedBlock uint64
+	history     []BalancePoint
+}
+
+// Watchlist 关注地址集合
+type Watchlist struct {
+	mu      sync.RWMutex
+	wallets map[common.Address]*watchedWallet
+
+	client       *ethclient.Client
+	parsedABI    abi.ABI
+	contract     common.Address
+	historyLimit int
+}
+
+// NewWatchlist 创建关注列表，historyLimit 为每个地址保留的余额历史条数
+func NewWatchlist(client *ethclient.Client, parsedABI abi.ABI, contract common.Address, historyLimit int) *Watchlist {
+	return &Watchlist{
+		wallets:      make(map[common.Address]*watchedWallet),
+		client:       client,
+		parsedABI:    parsedABI,
+		contract:     contract,
+		historyLimit: historyLimit,
+	}
+}
+
+// errNotWatched 地址未在关注列表中
+var errNotWatched = errors.New("address is not watched")
+
+// Add 注册关注地址，并读取链上余额作为基准
+func (wl *Watchlist) Add(ctx context.Context, addr common.Address) (WatchedWallet, error) {
+	wl.mu.RLock()
+	_, exists := wl.wallets[addr]
+	wl.mu.RUnlock()
+	if exists {
+		return wl.get(addr)
+	}
+
+	block, balance, err := wl.snapshot(ctx, addr)
+	if err != nil {
+		return WatchedWallet{}, err
+	}
+
+	wl.mu.Lock()
+	if _, exists := wl.wallets[addr]; !exists {
+		w := &watchedWallet{addedAt: time.Now()}
+		wl.applySnapshot(w, block, balance)
+		wl.wallets[addr] = w
+	}
+	wl.mu.Unlock()
+
+	slog.Info("watchlist: address added", "address", addr.Hex(), "balance", balance.String(), "block", block)
+	return wl.get(addr)
+}
+
+// Remove 取消关注
+func (wl *Watchlist) Remove(addr common.Address) error {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	if _, ok := wl.wallets[addr]; !ok {
+		return errNotWatched
+	}
+	delete(wl.wallets, addr)
+	return nil
+}
+
+// List 返回所有关注地址，按地址排序
+func (wl *Watchlist) List() []WatchedWallet {
+	wl.mu.RLock()
+	defer wl.mu.RUnlock()
+	out := make([]WatchedWallet, 0, len(wl.wallets))
+	for addr, w := range wl.wallets {
+		out = append(out, toWatchedWallet(addr, w))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out
+}
+
+// History 返回地址的余额历史
+func (wl *Watchlist) History(addr common.Address) ([]BalancePoint, error) {
+	wl.mu.RLock()
+	defer wl.mu.RUnlock()
+	w, ok := wl.wallets[addr]
+	if !ok {
+		return nil, errNotWatched
+	}
+	out := make([]BalancePoint, len(w.history))
+	copy(out, w.history)
+	return out, nil
+}
+
+// OnTransfer 根据 Transfer 事件更新相关地址余额
+func (wl *Watchlist) OnTransfer(blockNumber uint64, txHash common.Hash, from, to common.Address, value *big.Int) {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+
+	if w, ok := wl.wallets[from]; ok && blockNumber > w.syncedBlock {
+		w.balance = new(big.Int).Sub(w.balance, value)
+		wl.record(w, blockNumber, balanceSourceTransfer, txHash)
+	}
+	if w, ok := wl.wallets[to]; ok && blockNumber > w.syncedBlock {
+		w.balance = new(big.Int).Add(w.balance, value)
+		wl.record(w, blockNumber, balanceSourceTransfer, txHash)
+	}
+}
+
+// Run 定期通过 balanceOf 校准所有关注地址余额，直到 ctx 被取消
+func (wl *Watchlist) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			wl.verifyAll(ctx)
+		case <-ctx.Done():
+			slog.Info("context cancelled, stop watchlist verifier")
+			return
+		}
+	}
+}
+
+// verifyAll 逐个地址重新读取链上余额
+func (wl *Watchlist) verifyAll(ctx context.Context) {
+	wl.mu.RLock()
+	addrs := make([]common.Address, 0, len(wl.wallets))
+	for addr := range wl.wallets {
+		addrs = append(addrs, addr)
+	}
+	wl.mu.RUnlock()
+
+	for _, addr := range addrs {
+		block, balance, err := wl.snapshot(ctx, addr)
+		if err != nil {
+			slog.Warn("watchlist: balanceOf failed", "address", addr.Hex(), "err", err)
+			continue
+		}
+
+		wl.mu.Lock()
+		w, ok := wl.wallets[addr]
+		if ok && block >= w.syncedBlock {
+			if w.balance.Cmp(balance) != 0 {
+				slog.Warn("watchlist: tracked balance drifted from chain",
+					"address", addr.Hex(),
+					"block", block,
+					"tracked", w.balance.String(),
+					"onchain", balance.String(),
+				)
+			}
+			wl.applySnapshot(w, block, balance)
+		}
+		wl.mu.Unlock()
+	}
+}
+
+// snapshot 在最新区块号 N 上调用 balanceOf，返回 (N, balance)
+func (wl *Watchlist) snapshot(ctx context.Context, addr common.Address) (uint64, *big.Int, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	reqCtx, span := startSpan(reqCtx, "rpc.balanceOf",
+		attribute.String("rpc.method", "eth_call"),
+		attribute.String("address", addr.Hex()),
+	)
+	block, balance, err := wl.callBalanceOf(reqCtx, addr)
+	endSpan(span, err)
+	return block, balance, err
+}
+
+func (wl *Watchlist) callBalanceOf(ctx context.Context, addr common.Address) (uint64, *big.Int, error) {
+	block, err := wl.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	data, err := wl.parsedABI.Pack("balanceOf", addr)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to pack balanceOf: %w", err)
+	}
+
+	output, err := wl.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &wl.contract,
+		Data: data,
+	}, new(big.Int).SetUint64(block))
+	if err != nil {
+		return 0, nil, fmt.Errorf("balanceOf call failed: %w", err)
+	}
+
+	var balance *big.Int
+	if err := wl.parsedABI.UnpackIntoInterface(&balance, "balanceOf", output); err != nil {
+		return 0, nil, fmt.Errorf("failed to unpack balanceOf: %w", err)
+	}
+	return block, balance, nil
+}
+
+// applySnapshot 以链上快照覆盖余额（调用方持有写锁）
+func (wl *Watchlist) applySnapshot(w *watchedWallet, block uint64, balance *big.Int) {
+	changed := w.balance == nil || w.balance.Cmp(balance) != 0
+	w.balance = balance
+	w.syncedBlock = block
+	if changed {
+		wl.record(w, block, balanceSourceSnapshot, common.Hash{})
+	}
+}
+
+// record 追加一条余额历史（调用方持有写锁）
+func (wl *Watchlist) record(w *watchedWallet, block uint64, source string, txHash common.Hash) {
+	p := BalancePoint{
+		BlockNumber: block,
+		Balance:     w.balance.String(),
+		Source:      source,
+		Timestamp:   time.Now(),
+	}
+	if txHash != (common.Hash{}) {
+		p.TxHash = txHash.Hex()
+	}
+	if len(w.history) >= wl.historyLimit {
+		w.history = w.history[1:]
+	}
+	w.history = append(w.history, p)
+}
+
+func (wl *Watchlist) get(addr common.Address) (WatchedWallet, error) {
+	wl.mu.RLock()
+	defer wl.mu.RUnlock()
+	w, ok := wl.wallets[addr]
+	if !ok {
+		return WatchedWallet{}, errNotWatched
+	}
+	return toWatchedWallet(addr, w), nil
+}
+
+func toWatchedWallet(addr common.Address, w *watchedWallet) WatchedWallet {
+	return WatchedWallet{
+		Address:     addr.Hex(),
+		Balance:     w.balance.String(),
+		SyncedBlock: w.syncedBlock,
+		AddedAt:     w.addedAt,
+	}
+}
+
+// registerWatchlistRoutes 注册 /watchlist 相关接口
+func registerWatchlistRoutes(mux *http.ServeMux, wl *Watchlist) {
+	mux.HandleFunc("POST /watchlist", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Address string `json:"address"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json body", http.StatusBadRequest)
+			return
+		}
+		if !common.IsHexAddress(req.Address) {
+			http.Error(w, "invalid address", http.StatusBadRequest)
+			return
+		}
+
+		wallet, err := wl.Add(r.Context(), common.HexToAddress(req.Address))
+		if err != nil {
+			loggerFrom(r.Context()).Error("watchlist add failed", "address", req.Address, "err", err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeJSON(w, r, http.StatusCreated, wallet)
+	})
+
+	mux.HandleFunc("GET /watchlist", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, http.StatusOK, wl.List())
+	})
+
+	mux.HandleFunc("DELETE /watchlist/{address}", func(w http.ResponseWriter, r *http.Request) {
+		addr, ok := pathAddress(w, r)
+		if !ok {
+			return
+		}
+		if err := wl.Remove(addr); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("GET /watchlist/{address}/history", func(w http.ResponseWriter, r *http.Request) {
+		addr, ok := pathAddress(w, r)
+		if !ok {
+			return
+		}
+		history, err := wl.History(addr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, r, http.StatusOK, history)
+	})
+}
+
+// pathAddress 解析路径参数 {address}
+func pathAddress(w http.ResponseWriter, r *http.Request) (common.Address, bool) {
+	raw := r.PathValue("address")
+	if !common.IsHexAddress(raw) {
+		http.Error(w, "invalid address", http.StatusBadRequest)
+		return common.Address{}, false
+	}
+	return common.HexToAddress(raw), true
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		loggerFrom(r.Context()).Warn("failed to encode response", "err", err)
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/rpcmetrics"
+)
+
+// 让 EthClientPool 满足 go-ethereum 的标准客户端接口，
+// 从而可以直接替换 *ethclient.Client 传给 abigen 生成的绑定代码、bind.WaitMined 等。
+//
+// 路由规则：
+// - 普通读操作：轮询可用节点，失败自动换节点（readWithFailover）
+// - 写操作与 pending 状态读取：走主节点（保证 nonce 等状态与发送交易的节点一致）
+// - 订阅：绑定到当前选中的单个节点，节点断开时订阅的 Err() 会返回错误，由调用方重新订阅
+
+var (
+	_ ethereum.ChainReader           = (*EthClientPool)(nil)
+	_ ethereum.TransactionReader     = (*EthClientPool)(nil)
+	_ ethereum.ChainStateReader      = (*EthClientPool)(nil)
+	_ ethereum.ContractCaller        = (*EthClientPool)(nil)
+	_ ethereum.LogFilterer           = (*EthClientPool)(nil)
+	_ ethereum.TransactionSender     = (*EthClientPool)(nil)
+	_ ethereum.GasPricer             = (*EthClientPool)(nil)
+	_ ethereum.GasPricer1559         = (*EthClientPool)(nil)
+	_ ethereum.FeeHistoryReader      = (*EthClientPool)(nil)
+	_ ethereum.PendingStateReader    = (*EthClientPool)(nil)
+	_ ethereum.PendingContractCaller = (*EthClientPool)(nil)
+	_ ethereum.GasEstimator          = (*EthClientPool)(nil)
+	_ ethereum.BlockNumberReader     = (*EthClientPool)(nil)
+	_ ethereum.ChainIDReader         = (*EthClientPool)(nil)
+	_ bind.ContractBackend           = (*EthClientPool)(nil)
+	_ bind.DeployBackend             = (*EthClientPool)(nil)
+)
+
+// primaryWithFailover 在主节点上执行操作，可重试错误时标记主节点失效并切换到新的主节点
+func primaryWithFailover[T any](ctx context.Context, p *EthClientPool, op string, fn func(context.Context, *ethclient.Client) (T, error)) (T, error) {
+	var zero T
+	var lastErr error
+
+	for attempt := 1; attempt <= p.maxReadAttempts; attempt++ {
+		node := p.pickPrimaryNode()
+		if node == nil {
+			break
+		}
+
+		start := time.Now()
+		result, err := fn(ctx, node.Client)
+		p.metrics.observe(node.URL, time.Since(start), err)
+		rpcmetrics.Default.Observe(op, time.Since(start), err)
+		p.recordResult(node.URL, err)
+		node.release()
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		if !isRetryableError(err) || ctx.Err() != nil {
+			return zero, err
+		}
+
+		log.Printf("[WARN] %s failed on primary %s (attempt %d/%d), failover: %v",
+			op, node.URL, attempt, p.maxReadAttempts, err)
+	}
+
+	if lastErr == nil {
+		return zero, fmt.Errorf("no alive node for write")
+	}
+	return zero, fmt.Errorf("%s failed on primary: %w", op, lastErr)
+}
+
+// ---------------------------------------------------------------------------
+// ethereum.ChainReader
+// ---------------------------------------------------------------------------
+
+func (p *EthClientPool) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return readWithFailover(ctx, p, "eth_getBlockByHash", func(ctx context.Context, c *ethclient.Client) (*types.Block, error) {
+		return c.BlockByHash(ctx, hash)
+	})
+}
+
+func (p *EthClientPool) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return readWithFailover(ctx, p, "eth_getBlockByNumber", func(ctx context.Context, c *ethclient.Client) (*types.Block, error) {
+		return c.BlockByNumber(ctx, number)
+	})
+}
+
+func (p *EthClientPool) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return readWithFailover(ctx, p, "eth_getBlockByHash", func(ctx context.Context, c *ethclient.Client) (*types.Header, error) {
+		return c.HeaderByHash(ctx, hash)
+	})
+}
+
+func (p *EthClientPool) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return readWithFailover(ctx, p, "eth_getBlockByNumber", func(ctx context.Context, c *ethclient.Client) (*types.Header, error) {
+		return c.HeaderByNumber(ctx, number)
+	})
+}
+
+func (p *EthClientPool) TransactionCount(ctx context.Context, blockHash common.Hash) (uint, error) {
+	return readWithFailover(ctx, p, "eth_getBlockTransactionCountByHash", func(ctx context.Context, c *ethclient.Client) (uint, error) {
+		return c.TransactionCount(ctx, blockHash)
+	})
+}
+
+func (p *EthClientPool) TransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (*types.Transaction, error) {
+	return readWithFailover(ctx, p, "eth_getTransactionByBlockHashAndIndex", func(ctx context.Context, c *ethclient.Client) (*types.Transaction, error) {
+		return c.TransactionInBlock(ctx, blockHash, index)
+	})
+}
+
+func (p *EthClientPool) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return readWithFailover(ctx, p, "eth_subscribe(newHeads)", func(ctx context.Context, c *ethclient.Client) (ethereum.Subscription, error) {
+		return c.SubscribeNewHead(ctx, ch)
+	})
+}
+
+// ---------------------------------------------------------------------------
+// ethereum.TransactionReader
+// ---------------------------------------------------------------------------
+
+func (p *EthClientPool) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx        *types.Transaction
+		isPending bool
+	}
+	r, err := readWithFailover(ctx, p, "eth_getTransactionByHash", func(ctx context.Context, c *ethclient.Client) (result, error) {
+		tx, isPending, err := c.TransactionByHash(ctx, txHash)
+		return result{tx, isPending}, err
+	})
+	return r.tx, r.isPending, err
+}
+
+func (p *EthClientPool) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, err := readWithFailover(ctx, p, "eth_getTransactionReceipt", func(ctx context.Context, c *ethclient.Client) (*types.Receipt, error) {
+		return c.TransactionReceipt(ctx, txHash)
+	})
+	if err == nil {
+		if s := sessionFrom(ctx); s != nil {
+			s.recordReceipt(receipt)
+		}
+	}
+	return receipt, err
+}
+
+func (p *EthClientPool) SubscribeTransactionReceipts(ctx context.Context, q *ethereum.TransactionReceiptsQuery, ch chan<- []*types.Receipt) (ethereum.Subscription, error) {
+	return readWithFailover(ctx, p, "eth_subscribe(transactionReceipts)", func(ctx context.Context, c *ethclient.Client) (ethereum.Subscription, error) {
+		return c.SubscribeTransactionReceipts(ctx, q, ch)
+	})
+}
+
+// ---------------------------------------------------------------------------
+// ethereum.ChainStateReader / ContractCaller / BlockNumberReader
+// ---------------------------------------------------------------------------
+
+func (p *EthClientPool) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return readWithFailover(ctx, p, "eth_getBalance", func(ctx context.Context, c *ethclient.Client) (*big.Int, error) {
+		return c.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (p *EthClientPool) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return readWithFailover(ctx, p, "eth_getStorageAt", func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.StorageAt(ctx, account, key, blockNumber)
+	})
+}
+
+func (p *EthClientPool) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return readWithFailover(ctx, p, "eth_getCode", func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+func (p *EthClientPool) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return readWithFailover(ctx, p, "eth_getTransactionCount", func(ctx context.Context, c *ethclient.Client) (uint64, error) {
+		return c.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (p *EthClientPool) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return readWithFailover(ctx, p, "eth_call", func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.CallContract(ctx, call, blockNumber)
+	})
+}
+
+func (p *EthClientPool) BlockNumber(ctx context.Context) (uint64, error) {
+	return readWithFailover(ctx, p, "eth_blockNumber", func(ctx context.Context, c *ethclient.Client) (uint64, error) {
+		return c.BlockNumber(ctx)
+	})
+}
+
+// ---------------------------------------------------------------------------
+// ethereum.LogFilterer
+// ---------------------------------------------------------------------------
+
+func (p *EthClientPool) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return readWithFailover(ctx, p, "eth_getLogs", func(ctx context.Context, c *ethclient.Client) ([]types.Log, error) {
+		return c.FilterLogs(ctx, q)
+	})
+}
+
+func (p *EthClientPool) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return readWithFailover(ctx, p, "eth_subscribe(logs)", func(ctx context.Context, c *ethclient.Client) (ethereum.Subscription, error) {
+		return c.SubscribeFilterLogs(ctx, q, ch)
+	})
+}
+
+// ---------------------------------------------------------------------------
+// 费用相关：GasPricer / GasPricer1559 / FeeHistoryReader / GasEstimator
+// ---------------------------------------------------------------------------
+
+func (p *EthClientPool) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return readWithFailover(ctx, p, "eth_gasPrice", func(ctx context.Context, c *ethclient.Client) (*big.Int, error) {
+		return c.SuggestGasPrice(ctx)
+	})
+}
+
+func (p *EthClientPool) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return readWithFailover(ctx, p, "eth_maxPriorityFeePerGas", func(ctx context.Context, c *ethclient.Client) (*big.Int, error) {
+		return c.SuggestGasTipCap(ctx)
+	})
+}
+
+func (p *EthClientPool) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return readWithFailover(ctx, p, "eth_feeHistory", func(ctx context.Context, c *ethclient.Client) (*ethereum.FeeHistory, error) {
+		return c.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+func (p *EthClientPool) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return readWithFailover(ctx, p, "eth_estimateGas", func(ctx context.Context, c *ethclient.Client) (uint64, error) {
+		return c.EstimateGas(ctx, call)
+	})
+}
+
+// ---------------------------------------------------------------------------
+// pending 状态：走主节点，与发送交易的节点保持一致
+// ---------------------------------------------------------------------------
+
+func (p *EthClientPool) PendingBalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
+	return primaryWithFailover(ctx, p, "eth_getBalance(pending)", func(ctx context.Context, c *ethclient.Client) (*big.Int, error) {
+		return c.PendingBalanceAt(ctx, account)
+	})
+}
+
+func (p *EthClientPool) PendingStorageAt(ctx context.Context, account common.Address, key common.Hash) ([]byte, error) {
+	return primaryWithFailover(ctx, p, "eth_getStorageAt(pending)", func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.PendingStorageAt(ctx, account, key)
+	})
+}
+
+func (p *EthClientPool) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return primaryWithFailover(ctx, p, "eth_getCode(pending)", func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.PendingCodeAt(ctx, account)
+	})
+}
+
+func (p *EthClientPool) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return primaryWithFailover(ctx, p, "eth_getTransactionCount(pending)", func(ctx context.Context, c *ethclient.Client) (uint64, error) {
+		return c.PendingNonceAt(ctx, account)
+	})
+}
+
+func (p *EthClientPool) PendingTransactionCount(ctx context.Context) (uint, error) {
+	return primaryWithFailover(ctx, p, "eth_getBlockTransactionCountByNumber(pending)", func(ctx context.Context, c *ethclient.Client) (uint, error) {
+		return c.PendingTransactionCount(ctx)
+	})
+}
+
+func (p *EthClientPool) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
+	return primaryWithFailover(ctx, p, "eth_call(pending)", func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.PendingCallContract(ctx, call)
+	})
+}
+
+// ---------------------------------------------------------------------------
+// ethereum.TransactionSender
+// ---------------------------------------------------------------------------
+
+// SendTransaction 发送已签名交易：默认通过主节点，主节点出现可重试错误时切换主节点重发；
+// 广播模式下同时发给所有可用节点（见 broadcast.go）。
+// 同一笔已签名交易的哈希不变，重发到新节点不会产生重复交易。
+// ctx 携带 Session 时记录接收交易的节点，之后会话内的读请求固定到该节点直到看到回执。
+func (p *EthClientPool) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	p.mu.RLock()
+	mode := p.writeMode
+	p.mu.RUnlock()
+
+	var acceptedURL string
+	if mode == WriteBroadcast {
+		url, err := p.broadcastTransaction(ctx, tx)
+		if err != nil {
+			return err
+		}
+		acceptedURL = url
+	} else {
+		accepted, err := primaryWithFailover(ctx, p, "eth_sendRawTransaction", func(ctx context.Context, c *ethclient.Client) (*ethclient.Client, error) {
+			return c, c.SendTransaction(ctx, tx)
+		})
+		if err != nil {
+			return err
+		}
+		acceptedURL = p.nodeURLByClient(accepted)
+	}
+
+	if s := sessionFrom(ctx); s != nil {
+		s.recordWrite(tx.Hash(), acceptedURL)
+	}
+	return nil
+}
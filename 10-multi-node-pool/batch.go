@@ -0,0 +1,189 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/yzucdh1/examples/internal/rpcmetrics"
+)
+
+// 批量请求分发：
+// - BatchCall 把一大批 JSON-RPC 请求按读权重（容量）比例拆分到各个可用节点并发执行
+// - 每个节点上再按 defaultMaxBatchSize 切成多个 batch 依次发送，避免单个请求过大被服务商拒绝
+// - 结果直接写回调用方传入的 rpc.BatchElem，顺序与传入时一致
+// - 整个 batch 传输失败、或单个元素返回可归因于节点的错误时，这部分请求换其他节点重试
+// 适合回填历史数据这种大量读取的场景，避免把压力全部压在一个节点上。
+
+// defaultMaxBatchSize 单个 JSON-RPC batch 的最大元素数
+const defaultMaxBatchSize = 100
+
+// BatchCall 把 elems 分发到多个节点执行；单个元素的错误写在 elem.Error 中，
+// 只有部分元素在所有重试后仍因传输错误失败时才返回 error
+func (p *EthClientPool) BatchCall(ctx context.Context, elems []rpc.BatchElem) error {
+	pending := make([]int, len(elems))
+	for i := range elems {
+		pending[i] = i
+	}
+
+	tried := make(map[string]bool)
+	var lastErr error
+	for attempt := 1; attempt <= p.maxReadAttempts && len(pending) > 0; attempt++ {
+		nodes := p.pickBatchNodes(tried)
+		if len(nodes) == 0 {
+			break
+		}
+
+		failed, err := p.batchRound(ctx, nodes, elems, pending, tried)
+		for _, node := range nodes {
+			node.release()
+		}
+		if err != nil {
+			lastErr = err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if len(failed) > 0 {
+			log.Printf("[WARN] batch round %d: %d/%d element(s) failed, retry on other nodes",
+				attempt, len(failed), len(pending))
+		}
+		pending = failed
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no alive node for batch")
+	}
+	return fmt.Errorf("batch: %d element(s) failed: %w", len(pending), lastErr)
+}
+
+// pickBatchNodes 返回所有可用且未出过错的节点，已计入 inflight，调用方用完后需 release
+func (p *EthClientPool) pickBatchNodes(exclude map[string]bool) []*NodeStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var nodes []*NodeStatus
+	for _, node := range p.nodes {
+		if node.usable() && !exclude[node.URL] {
+			node.inflight.Add(1)
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// batchRound 把 pending 中的元素按权重分给 nodes 执行一轮，返回需要重试的元素下标；
+// 出错的节点加入 tried，后续轮次不再使用
+func (p *EthClientPool) batchRound(ctx context.Context, nodes []*NodeStatus, elems []rpc.BatchElem, pending []int, tried map[string]bool) ([]int, error) {
+	shares := splitByWeight(pending, nodes)
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		failed  []int
+		lastErr error
+	)
+	for i, node := range nodes {
+		if len(shares[i]) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(node *NodeStatus, idxs []int) {
+			defer wg.Done()
+			retry, err := p.batchOnNode(ctx, node, elems, idxs)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(retry) > 0 {
+				failed = append(failed, retry...)
+				tried[node.URL] = true
+			}
+			if err != nil {
+				lastErr = err
+			}
+		}(node, shares[i])
+	}
+	wg.Wait()
+
+	sort.Ints(failed)
+	return failed, lastErr
+}
+
+// batchOnNode 在单个节点上按 defaultMaxBatchSize 分批执行，返回需要换节点重试的元素下标
+func (p *EthClientPool) batchOnNode(ctx context.Context, node *NodeStatus, elems []rpc.BatchElem, idxs []int) ([]int, error) {
+	var retry []int
+	for start := 0; start < len(idxs); start += defaultMaxBatchSize {
+		chunk := idxs[start:min(start+defaultMaxBatchSize, len(idxs))]
+
+		batch := make([]rpc.BatchElem, len(chunk))
+		for i, idx := range chunk {
+			batch[i] = elems[idx]
+			batch[i].Error = nil
+		}
+
+		began := time.Now()
+		err := node.Client.Client().BatchCallContext(ctx, batch)
+		p.metrics.observe(node.URL, time.Since(began), err)
+		for _, elem := range batch {
+			rpcmetrics.Default.Observe(elem.Method, time.Since(began), cmp.Or(err, elem.Error))
+		}
+		p.recordResult(node.URL, err)
+
+		if err != nil {
+			// 传输层失败：本节点剩余的元素全部换节点
+			log.Printf("[WARN] batch of %d failed on %s: %v", len(chunk), node.URL, err)
+			return append(retry, idxs[start:]...), err
+		}
+		for i, idx := range chunk {
+			elems[idx].Error = batch[i].Error
+			if batch[i].Error != nil && isRetryableError(batch[i].Error) {
+				retry = append(retry, idx)
+			}
+		}
+	}
+	return retry, nil
+}
+
+// splitByWeight 按节点读权重把下标切分成连续的若干段（最大余数法），
+// 返回值与 nodes 一一对应
+func splitByWeight(idxs []int, nodes []*NodeStatus) [][]int {
+	total := 0
+	for _, node := range nodes {
+		total += node.weight()
+	}
+
+	counts := make([]int, len(nodes))
+	remainders := make([]int, len(nodes))
+	assigned := 0
+	for i, node := range nodes {
+		counts[i] = len(idxs) * node.weight() / total
+		remainders[i] = len(idxs) * node.weight() % total
+		assigned += counts[i]
+	}
+
+	order := make([]int, len(nodes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; assigned < len(idxs); i++ {
+		counts[order[i%len(order)]]++
+		assigned++
+	}
+
+	shares := make([][]int, len(nodes))
+	pos := 0
+	for i, n := range counts {
+		shares[i] = idxs[pos : pos+n]
+		pos += n
+	}
+	return shares
+}
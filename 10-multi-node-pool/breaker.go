@@ -0,0 +1,186 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// 节点熔断器（替代“出一次错就标记失效”的二元状态）：
+// - closed（闭合）：正常接收请求，在最近 Window 次请求的滑动窗口内统计失败率
+//   请求数达到 MinRequests 且失败率 >= FailureRate 时熔断（open），偶发的单次错误不会摘除节点
+// - open（断开）：不接收任何请求；经过 OpenTimeout 后由健康检查探活，成功则进入半开
+//   连续熔断时 OpenTimeout 翻倍（最多 MaxOpenTimeout），持续故障的节点被探活的频率越来越低
+// - half-open（半开）：重新接收请求，连续 HalfOpenSuccesses 次成功后闭合；期间任何一次失败立即重新熔断
+// 只有可归因于节点的错误（见 isRetryableError）计入失败，revert、NotFound 等视为节点工作正常。
+// 节点的 Alive 字段与熔断器同步：open 时为 false，closed / half-open 时为 true。
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// BreakerConfig 熔断器配置
+type BreakerConfig struct {
+	Window            int           // 滑动窗口大小（请求数）
+	MinRequests       int           // 窗口内至少有这么多请求才判断失败率
+	FailureRate       float64       // 熔断的失败率阈值（0~1）
+	OpenTimeout       time.Duration // 熔断后多久允许探活
+	MaxOpenTimeout    time.Duration // 连续熔断时 OpenTimeout 翻倍的上限
+	HalfOpenSuccesses int           // 半开状态下闭合所需的连续成功次数
+}
+
+// DefaultBreakerConfig 默认熔断器配置
+var DefaultBreakerConfig = BreakerConfig{
+	Window:            20,
+	MinRequests:       5,
+	FailureRate:       0.5,
+	OpenTimeout:       10 * time.Second,
+	MaxOpenTimeout:    5 * time.Minute,
+	HalfOpenSuccesses: 3,
+}
+
+// circuitBreaker 单个节点的熔断器，零值为闭合状态；由 EthClientPool.mu 保护
+type circuitBreaker struct {
+	state BreakerState
+
+	// 闭合状态的滑动窗口
+	results  []bool // true 表示失败
+	pos      int
+	failures int
+
+	openedAt    time.Time
+	openTimeout time.Duration // 本次熔断的等待时间
+	halfOpenOK  int           // 半开状态下的连续成功次数
+}
+
+// record 记录一次请求结果，返回状态是否发生变化
+func (b *circuitBreaker) record(cfg BreakerConfig, failed bool, now time.Time) bool {
+	switch b.state {
+	case BreakerOpen:
+		// 熔断期间不应再有请求，在途请求的结果忽略
+		return false
+
+	case BreakerHalfOpen:
+		if failed {
+			b.trip(cfg, now)
+			return true
+		}
+		b.halfOpenOK++
+		if b.halfOpenOK >= cfg.HalfOpenSuccesses {
+			b.reset(BreakerClosed)
+			b.openTimeout = 0
+			return true
+		}
+		return false
+	}
+
+	if len(b.results) != cfg.Window {
+		b.results = make([]bool, 0, cfg.Window)
+		b.pos, b.failures = 0, 0
+	}
+	if len(b.results) < cfg.Window {
+		b.results = append(b.results, failed)
+	} else {
+		if b.results[b.pos] {
+			b.failures--
+		}
+		b.results[b.pos] = failed
+		b.pos = (b.pos + 1) % cfg.Window
+	}
+	if failed {
+		b.failures++
+	}
+
+	if len(b.results) >= cfg.MinRequests &&
+		float64(b.failures)/float64(len(b.results)) >= cfg.FailureRate {
+		b.trip(cfg, now)
+		return true
+	}
+	return false
+}
+
+// trip 进入熔断状态；从半开或刚恢复的状态再次熔断时等待时间翻倍
+func (b *circuitBreaker) trip(cfg BreakerConfig, now time.Time) {
+	timeout := cfg.OpenTimeout
+	if b.openTimeout > 0 {
+		timeout = min(b.openTimeout*2, cfg.MaxOpenTimeout)
+	}
+	b.reset(BreakerOpen)
+	b.openedAt = now
+	b.openTimeout = timeout
+}
+
+// reset 切换状态并清空统计
+func (b *circuitBreaker) reset(state BreakerState) {
+	b.state = state
+	b.results = b.results[:0]
+	b.pos, b.failures, b.halfOpenOK = 0, 0, 0
+}
+
+// probeDue 熔断等待时间是否已过，可以探活
+func (b *circuitBreaker) probeDue(now time.Time) bool {
+	return b.state != BreakerOpen || now.Sub(b.openedAt) >= b.openTimeout
+}
+
+// recordResult 把请求结果计入节点熔断器，按需切换节点状态
+func (p *EthClientPool) recordResult(url string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	node := p.findNodeLocked(url)
+	if node == nil {
+		return
+	}
+	failed := isRetryableError(err)
+	if !node.breaker.record(p.breakerCfg, failed, time.Now()) {
+		return
+	}
+	p.syncBreakerLocked(node, err)
+}
+
+// syncBreakerLocked 熔断器状态变化后同步节点的 Alive 标记并输出日志，调用方需持有 p.mu 写锁
+func (p *EthClientPool) syncBreakerLocked(node *NodeStatus, cause error) {
+	switch node.breaker.state {
+	case BreakerOpen:
+		log.Printf("[ERROR] circuit open, node removed from rotation, url=%s, retry_after=%s, err=%v",
+			node.URL, node.breaker.openTimeout, cause)
+		node.Alive = false
+		node.AliveSince = time.Time{}
+	case BreakerHalfOpen:
+		log.Printf("[INFO] circuit half-open, node on probation, url=%s", node.URL)
+		node.Alive = true
+		node.AliveSince = time.Now()
+	case BreakerClosed:
+		log.Printf("[INFO] circuit closed, node fully recovered, url=%s", node.URL)
+		if !node.Alive {
+			node.AliveSince = time.Now()
+		}
+		node.Alive = true
+	}
+}
+
+// findNodeLocked 按 URL 查找节点，调用方需持有 p.mu
+func (p *EthClientPool) findNodeLocked(url string) *NodeStatus {
+	for _, node := range p.nodes {
+		if node.URL == url {
+			return node
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yzucdh1/examples/internal/rpcerr"
+	"github.com/yzucdh1/examples/internal/rpcmetrics"
+)
+
+// 写广播模式：
+// - 默认（WritePrimary）只把交易发给主节点
+// - WriteBroadcast 把同一笔已签名交易同时发给所有可用节点，任意一个节点接受即返回成功，
+//   其余节点继续在后台发送完成，提高传播速度，也避免单个服务商悄悄丢弃交易
+// - 节点返回 “already known” 等表示交易已在其交易池中的错误视为成功
+// - 同一笔交易（按哈希）并发重复调用时只广播一次，后来的调用等待同一个结果
+
+// WriteMode 写操作模式
+type WriteMode int
+
+const (
+	// WritePrimary 只发给主节点（默认）
+	WritePrimary WriteMode = iota
+	// WriteBroadcast 发给所有可用节点，第一个成功的结果生效
+	WriteBroadcast
+)
+
+func (m WriteMode) String() string {
+	if m == WriteBroadcast {
+		return "broadcast"
+	}
+	return "primary"
+}
+
+// broadcastTimeout 首个节点接受后，其余节点在后台继续发送的最长时间
+const broadcastTimeout = 30 * time.Second
+
+// broadcastCall 一次进行中的广播，供相同哈希的并发调用共享结果
+type broadcastCall struct {
+	done     chan struct{}
+	accepted string // 第一个接受交易的节点 URL
+	err      error
+}
+
+// isKnownTxError 节点是否因为已经收到过这笔交易而拒绝（不同客户端的措辞由 rpcerr 识别）
+func isKnownTxError(err error) bool {
+	return errors.Is(rpcerr.Classify(err), rpcerr.ErrAlreadyKnown)
+}
+
+// SetWriteMode 设置写操作模式
+func (p *EthClientPool) SetWriteMode(mode WriteMode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.writeMode = mode
+}
+
+// broadcastTransaction 把交易同时发给所有可用节点，返回第一个接受交易的节点 URL
+func (p *EthClientPool) broadcastTransaction(ctx context.Context, tx *types.Transaction) (string, error) {
+	hash := tx.Hash()
+
+	p.mu.Lock()
+	if call, ok := p.broadcasts[hash]; ok {
+		p.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.accepted, call.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	call := &broadcastCall{done: make(chan struct{})}
+	p.broadcasts[hash] = call
+
+	var nodes []*NodeStatus
+	for _, node := range p.nodes {
+		if node.usable() {
+			node.inflight.Add(1)
+			nodes = append(nodes, node)
+		}
+	}
+	p.mu.Unlock()
+
+	call.accepted, call.err = p.sendToAll(ctx, hash, tx, nodes)
+	close(call.done)
+
+	p.mu.Lock()
+	delete(p.broadcasts, hash)
+	p.mu.Unlock()
+
+	return call.accepted, call.err
+}
+
+// sendToAll 并发发送到 nodes，第一个成功即返回；其余发送在后台继续并释放节点
+func (p *EthClientPool) sendToAll(ctx context.Context, hash common.Hash, tx *types.Transaction, nodes []*NodeStatus) (string, error) {
+	if len(nodes) == 0 {
+		return "", fmt.Errorf("no alive node for write")
+	}
+
+	// 首个成功后调用方可能取消 ctx，后台发送使用独立的超时
+	sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), broadcastTimeout)
+
+	type result struct {
+		url string
+		err error
+	}
+	results := make(chan result, len(nodes))
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node *NodeStatus) {
+			defer wg.Done()
+			defer node.release()
+
+			start := time.Now()
+			err := node.Client.SendTransaction(sendCtx, tx)
+			if isKnownTxError(err) {
+				err = nil
+			}
+			p.metrics.observe(node.URL, time.Since(start), err)
+			rpcmetrics.Default.Observe("eth_sendRawTransaction", time.Since(start), err)
+			p.recordResult(node.URL, err)
+			results <- result{url: node.URL, err: err}
+		}(node)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(results)
+	}()
+
+	var errs []string
+	for {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				return "", fmt.Errorf("broadcast tx %s rejected by all %d node(s): %s",
+					hash.Hex(), len(nodes), strings.Join(errs, "; "))
+			}
+			if r.err == nil {
+				log.Printf("[INFO] broadcast tx %s accepted first by %s", hash.Hex(), r.url)
+				return r.url, nil
+			}
+			log.Printf("[WARN] broadcast tx %s rejected by %s: %v", hash.Hex(), r.url, r.err)
+			errs = append(errs, fmt.Sprintf("%s: %v", r.url, r.err))
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+)
+
+// 链 ID 一致性校验：
+// - 启动时查询所有已连接节点的 eth_chainId
+// - 指定了期望链 ID 时以其为准，否则取多数节点报告的链 ID
+// - 链 ID 不一致的节点直接拒绝加入连接池（例如把 Sepolia 的 URL 混进主网连接池）
+// - 健康检查探活时同样校验，链 ID 不一致的节点不会被恢复
+
+// errChainIDMismatch 节点链 ID 与连接池不一致
+type errChainIDMismatch struct {
+	URL      string
+	Got      *big.Int
+	Expected *big.Int
+}
+
+func (e *errChainIDMismatch) Error() string {
+	return fmt.Sprintf("chain id mismatch, url=%s, got=%s, expected=%s", e.URL, e.Got, e.Expected)
+}
+
+// verifyChainIDs 确定连接池的链 ID，返回链 ID 与通过校验的节点列表。
+// 查询失败的节点保留但标记为失效，交给健康检查后续处理。
+func verifyChainIDs(ctx context.Context, nodes []*NodeStatus, expected *big.Int) (*big.Int, []*NodeStatus) {
+	reported := make(map[*NodeStatus]*big.Int, len(nodes))
+	counts := make(map[string]int)
+	var order []*big.Int
+
+	for _, node := range nodes {
+		if node.Client == nil {
+			continue
+		}
+		id, err := node.Client.ChainID(ctx)
+		if err != nil {
+			log.Printf("[WARN] query chain id failed, url=%s, err=%v", node.URL, err)
+			node.Alive = false
+			continue
+		}
+		reported[node] = id
+		if counts[id.String()] == 0 {
+			order = append(order, id)
+		}
+		counts[id.String()]++
+	}
+
+	chainID := expected
+	if chainID == nil {
+		// 取多数；票数相同时取配置顺序中先出现的
+		for _, id := range order {
+			if chainID == nil || counts[id.String()] > counts[chainID.String()] {
+				chainID = id
+			}
+		}
+	}
+
+	if chainID == nil {
+		return nil, nodes
+	}
+
+	kept := make([]*NodeStatus, 0, len(nodes))
+	for _, node := range nodes {
+		id, ok := reported[node]
+		if ok && id.Cmp(chainID) != 0 {
+			log.Printf("[ERROR] refuse node: %v", &errChainIDMismatch{URL: node.URL, Got: id, Expected: chainID})
+			node.Client.Close()
+			continue
+		}
+		kept = append(kept, node)
+	}
+	return chainID, kept
+}
+
+// checkChainID 校验单个节点的链 ID；连接池尚未确定链 ID 时采用该节点的链 ID
+func (p *EthClientPool) checkChainID(url string, id *big.Int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.chainID == nil {
+		log.Printf("[INFO] pool chain id set to %s by node %s", id, url)
+		p.chainID = id
+		return nil
+	}
+	if id.Cmp(p.chainID) != 0 {
+		return &errChainIDMismatch{URL: url, Got: id, Expected: p.chainID}
+	}
+	return nil
+}
+
+// ChainID 返回连接池的链 ID（与 ethclient.Client.ChainID 签名一致）
+func (p *EthClientPool) ChainID(ctx context.Context) (*big.Int, error) {
+	_ = ctx
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.chainID == nil {
+		return nil, fmt.Errorf("chain id unknown: no node reported it yet")
+	}
+	return new(big.Int).Set(p.chainID), nil
+}
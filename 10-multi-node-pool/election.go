@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// 基于健康得分的主节点选举：
+// - 每个节点按 延迟、错误率、区块高度、在线时长 计算 0~1 的综合得分
+// - 当前主节点不可用时立即切换到得分最高的节点（pickPrimaryNode）
+// - 当前主节点可用时只在挑战者得分连续多次高出 Margin 才切换（迟滞），
+//   避免一次偶发错误或延迟抖动就在节点之间来回切换
+// - 每次切换记录到主节点变更日志，可通过 PrimaryHistory 查询
+
+// ElectionConfig 主节点选举配置
+type ElectionConfig struct {
+	Interval      time.Duration // 评估间隔
+	Margin        float64       // 挑战者得分需要高出当前主节点的幅度
+	Confirmations int           // 挑战者需要连续胜出的评估次数
+	MaxLag        uint64        // 高度得分归零的落后区块数
+	FullUptime    time.Duration // 在线时长达到该值时在线得分为满分
+}
+
+// DefaultElectionConfig 默认主节点选举配置
+var DefaultElectionConfig = ElectionConfig{
+	Interval:      30 * time.Second,
+	Margin:        0.1,
+	Confirmations: 3,
+	MaxLag:        DefaultLagConfig.MaxLag,
+	FullUptime:    5 * time.Minute,
+}
+
+// 各项得分的权重，总和为 1
+const (
+	scoreWeightLatency = 0.3
+	scoreWeightErrors  = 0.3
+	scoreWeightHeight  = 0.2
+	scoreWeightUptime  = 0.2
+
+	// scoreLatencyRef 延迟得分减半的参考延迟（秒）
+	scoreLatencyRef = 0.1
+)
+
+// PrimaryChange 一次主节点切换记录
+type PrimaryChange struct {
+	Time   time.Time `json:"time"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Reason string    `json:"reason"`
+}
+
+// maxPrimaryEvents 保留的主节点切换记录条数
+const maxPrimaryEvents = 100
+
+// StartPrimaryElection 启动后台主节点评估协程，ctx 取消时退出
+func (p *EthClientPool) StartPrimaryElection(ctx context.Context, cfg ElectionConfig) {
+	p.mu.Lock()
+	p.election = cfg
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.electPrimary()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// electPrimary 做一次带迟滞的主节点评估
+func (p *EthClientPool) electPrimary() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cfg := p.election
+	best, bestScore := p.bestScoredLocked()
+	if best < 0 {
+		return
+	}
+
+	if p.primaryIdx >= len(p.nodes) || !p.nodes[p.primaryIdx].usable() {
+		p.switchPrimaryLocked(best, "primary unusable")
+		return
+	}
+	if best == p.primaryIdx {
+		p.challenger, p.challengerStreak = "", 0
+		return
+	}
+
+	current := p.scoreLocked(p.nodes[p.primaryIdx])
+	if bestScore < current+cfg.Margin {
+		p.challenger, p.challengerStreak = "", 0
+		return
+	}
+
+	url := p.nodes[best].URL
+	if p.challenger != url {
+		p.challenger, p.challengerStreak = url, 0
+	}
+	p.challengerStreak++
+	if p.challengerStreak < cfg.Confirmations {
+		log.Printf("[DEBUG] primary challenger %s scored %.2f vs %.2f (%d/%d)",
+			url, bestScore, current, p.challengerStreak, cfg.Confirmations)
+		return
+	}
+	p.switchPrimaryLocked(best, "higher health score")
+}
+
+// switchPrimaryLocked 切换主节点并记录变更，调用方需持有 p.mu 写锁
+func (p *EthClientPool) switchPrimaryLocked(idx int, reason string) {
+	var from string
+	if p.primaryIdx < len(p.nodes) {
+		from = p.nodes[p.primaryIdx].URL
+	}
+	to := p.nodes[idx].URL
+
+	p.primaryIdx = idx
+	p.challenger, p.challengerStreak = "", 0
+	if from == to {
+		return
+	}
+
+	log.Printf("[WARN] switch primary node, from=%s, to=%s, reason=%s", from, to, reason)
+	p.primaryEvents = append(p.primaryEvents, PrimaryChange{
+		Time:   time.Now(),
+		From:   from,
+		To:     to,
+		Reason: reason,
+	})
+	if len(p.primaryEvents) > maxPrimaryEvents {
+		p.primaryEvents = p.primaryEvents[len(p.primaryEvents)-maxPrimaryEvents:]
+	}
+}
+
+// PrimaryHistory 返回主节点切换记录（从旧到新）
+func (p *EthClientPool) PrimaryHistory() []PrimaryChange {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]PrimaryChange(nil), p.primaryEvents...)
+}
+
+// bestScoredLocked 返回得分最高的可用节点索引，没有可用节点时返回 -1；
+// 得分相同时取配置顺序靠前的节点。调用方需持有 p.mu。
+func (p *EthClientPool) bestScoredLocked() (int, float64) {
+	best, bestScore := -1, -1.0
+	for i, node := range p.nodes {
+		if !node.usable() {
+			continue
+		}
+		if s := p.scoreLocked(node); s > bestScore {
+			best, bestScore = i, s
+		}
+	}
+	return best, bestScore
+}
+
+// scoresLocked 返回所有节点的得分，顺序与 p.nodes 一致。调用方需持有 p.mu。
+func (p *EthClientPool) scoresLocked() []float64 {
+	scores := make([]float64, len(p.nodes))
+	for i, node := range p.nodes {
+		scores[i] = p.scoreLocked(node)
+	}
+	return scores
+}
+
+// scoreLocked 计算单个节点的综合得分，不可用节点为 0。调用方需持有 p.mu。
+func (p *EthClientPool) scoreLocked(node *NodeStatus) float64 {
+	if !node.usable() {
+		return 0
+	}
+	cfg := p.election
+
+	// 没有请求记录的节点按中性值处理，既不惩罚也不偏袒
+	latencyScore, errorScore := 0.5, 1.0
+	p.metrics.mu.Lock()
+	if nm, ok := p.metrics.nodes[node.URL]; ok && nm.hasEWMA {
+		latencyScore = scoreLatencyRef / (scoreLatencyRef + nm.ewmaLatency)
+		errorScore = 1 - nm.ewmaErrRate
+	}
+	p.metrics.mu.Unlock()
+
+	heightScore := 1.0
+	var maxHeight uint64
+	for _, n := range p.nodes {
+		maxHeight = max(maxHeight, n.Height)
+	}
+	if cfg.MaxLag > 0 && node.Height > 0 && maxHeight > node.Height {
+		heightScore = max(0, 1-float64(maxHeight-node.Height)/float64(cfg.MaxLag))
+	}
+
+	uptimeScore := 0.0
+	if !node.AliveSince.IsZero() {
+		uptimeScore = min(1, float64(time.Since(node.AliveSince))/float64(cfg.FullUptime))
+	}
+
+	return scoreWeightLatency*latencyScore +
+		scoreWeightErrors*errorScore +
+		scoreWeightHeight*heightScore +
+		scoreWeightUptime*uptimeScore
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// 后台健康检查：
+// - 定期对节点重新拨号（若之前未连上）并调用 eth_chainId 探活
+// - 熔断的节点等熔断等待时间过后才探活，探活成功且链 ID 与连接池一致则进入半开状态重新接收请求，
+//   避免节点永久下线，也避免所有节点同时抖动后整个连接池再也无法恢复
+// - 可用节点的探活失败计入熔断器；链 ID 不一致（例如节点被切到了其他网络）则立即熔断
+
+// HealthCheckConfig 健康检查配置
+type HealthCheckConfig struct {
+	Interval time.Duration // 检查间隔
+	Timeout  time.Duration // 单个节点拨号 + 探活的超时
+}
+
+// DefaultHealthCheckConfig 默认健康检查配置
+var DefaultHealthCheckConfig = HealthCheckConfig{
+	Interval: 10 * time.Second,
+	Timeout:  3 * time.Second,
+}
+
+// StartHealthCheck 启动后台健康检查协程，ctx 取消时退出
+func (p *EthClientPool) StartHealthCheck(ctx context.Context, cfg HealthCheckConfig) {
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.checkNodes(ctx, cfg.Timeout)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// checkNodes 对所有节点做一次探活
+func (p *EthClientPool) checkNodes(ctx context.Context, timeout time.Duration) {
+	p.mu.RLock()
+	nodes := make([]*NodeStatus, len(p.nodes))
+	copy(nodes, p.nodes)
+	p.mu.RUnlock()
+
+	for _, node := range nodes {
+		p.probeNode(ctx, node, timeout)
+	}
+}
+
+// probeNode 对单个节点拨号（如需要）并调用 eth_chainId，成功则标记为可用
+func (p *EthClientPool) probeNode(ctx context.Context, node *NodeStatus, timeout time.Duration) {
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	p.mu.RLock()
+	client := node.Client
+	due := node.breaker.probeDue(time.Now())
+	p.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	dialed := false
+	if client == nil {
+		c, err := ethclient.DialContext(probeCtx, node.URL)
+		if err != nil {
+			log.Printf("[DEBUG] health check dial failed, url=%s, err=%v", node.URL, err)
+			return
+		}
+		client = c
+		dialed = true
+	}
+
+	id, err := client.ChainID(probeCtx)
+	if err == nil {
+		err = p.checkChainID(node.URL, id)
+	}
+	if err != nil {
+		log.Printf("[DEBUG] health check probe failed, url=%s, err=%v", node.URL, err)
+		var mismatch *errChainIDMismatch
+		switch {
+		case dialed:
+			client.Close()
+		case errors.As(err, &mismatch):
+			p.markNodeDead(node.URL, err)
+		default:
+			p.recordResult(node.URL, err)
+		}
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if node.Client == nil {
+		node.Client = client
+	} else if dialed {
+		client.Close()
+	}
+	switch {
+	case node.breaker.state == BreakerOpen:
+		node.breaker.reset(BreakerHalfOpen)
+		p.syncBreakerLocked(node, nil)
+	case !node.Alive:
+		// 启动或新增时没连上的节点，首次连上直接可用
+		log.Printf("[INFO] node revived, url=%s", node.URL)
+		node.Alive = true
+		node.AliveSince = time.Now()
+	}
+}
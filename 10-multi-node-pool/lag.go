@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// 区块高度落后检测：
+// - 定期并发查询所有可用节点的 eth_blockNumber
+// - 落后最高节点超过 MaxLag 个区块的节点标记为 Stale，暂时不参与读写选择
+// - Stale 节点追上后自动恢复并输出日志
+// 避免读请求被路由到落后节点，返回过期的余额、回执等数据。
+
+// LagConfig 高度落后检测配置
+type LagConfig struct {
+	Interval time.Duration // 检测间隔
+	Timeout  time.Duration // 单个节点查询超时
+	MaxLag   uint64        // 允许落后的最大区块数
+}
+
+// DefaultLagConfig 默认高度落后检测配置
+var DefaultLagConfig = LagConfig{
+	Interval: 15 * time.Second,
+	Timeout:  3 * time.Second,
+	MaxLag:   5,
+}
+
+// StartLagMonitor 启动后台高度检测协程，ctx 取消时退出
+func (p *EthClientPool) StartLagMonitor(ctx context.Context, cfg LagConfig) {
+	go func() {
+		// 启动时先检测一次，避免第一个周期内读到落后节点
+		p.checkLag(ctx, cfg)
+
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.checkLag(ctx, cfg)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// checkLag 查询各节点高度并更新 Stale 标记
+func (p *EthClientPool) checkLag(ctx context.Context, cfg LagConfig) {
+	p.mu.RLock()
+	alive := make(map[*NodeStatus]*ethclient.Client, len(p.nodes))
+	for _, node := range p.nodes {
+		if node.Alive && node.Client != nil {
+			alive[node] = node.Client
+		}
+	}
+	p.mu.RUnlock()
+
+	heights := make(map[*NodeStatus]uint64, len(alive))
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for node, client := range alive {
+		wg.Add(1)
+		go func(node *NodeStatus, client *ethclient.Client) {
+			defer wg.Done()
+			reqCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+			defer cancel()
+
+			height, err := client.BlockNumber(reqCtx)
+			p.recordResult(node.URL, err)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			heights[node] = height
+			mu.Unlock()
+		}(node, client)
+	}
+	wg.Wait()
+
+	var maxHeight uint64
+	for _, h := range heights {
+		maxHeight = max(maxHeight, h)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for node, h := range heights {
+		node.Height = h
+		lag := maxHeight - h
+		switch {
+		case lag > cfg.MaxLag && !node.Stale:
+			log.Printf("[WARN] node is lagging, exclude from rotation, url=%s, height=%d, max=%d, lag=%d",
+				node.URL, h, maxHeight, lag)
+			node.Stale = true
+		case lag <= cfg.MaxLag && node.Stale:
+			log.Printf("[INFO] node caught up, back to rotation, url=%s, height=%d, max=%d",
+				node.URL, h, maxHeight)
+			node.Stale = false
+		}
+	}
+}
@@ -2,33 +2,96 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/decimal"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/signer"
 )
 
 // 本示例演示一个“简单连接池与多节点策略”：
 // - 多个 ethclient.Client 连接不同节点
 // - 读操作做简单负载均衡（轮询）
-// - 写操作固定主节点（主节点挂了再切换）
-// - 节点不可用时自动标记失效并输出告警日志
+// - 写操作固定主节点：按延迟、错误率、高度、在线时长综合评分选举，带迟滞避免频繁切换，
+//   主节点不可用时立即切换，所有切换记录在主节点变更日志中
+// - 每个节点一个熔断器（closed / open / half-open），按失败率熔断，偶发错误不会摘除节点
+// - 后台健康检查对熔断的节点探活，成功后半开试用，连续成功才完全恢复
+// - 定期比较各节点区块高度，落后过多的节点暂时移出轮询，追上后自动恢复
+// - 启动及健康检查时校验各节点链 ID 一致，不一致的节点拒绝加入
+// - 读操作出现可重试错误时自动换下一个健康节点重试，对调用方透明
+// - 实现 go-ethereum 标准客户端接口（ethereum.ChainReader、bind.ContractBackend 等），
+//   可直接替换 *ethclient.Client 用于 abigen 绑定和其他示例
+// - 可选的多数一致（quorum）读模式：同一请求发给 K 个节点比较结果，返回多数结果并告警不一致的节点
+// - 读己之写：同一 Session 内发出交易后，读请求固定到已看到该交易的节点，避免读到过期的 nonce / 回执
+// - 节点列表可写在配置文件中热更新：增删节点、调整读权重无需重启，被移除节点的在途请求处理完再断开
+// - 写路径示例：签名并发送真实转账，本地跟踪 nonce 保证主节点切换后 nonce 连续，通过读节点等待回执
+// - 可选写广播模式：交易同时发给所有可用节点，第一个接受即成功，默认仍只发主节点
+// - BatchCall 把大批量 JSON-RPC 请求按节点权重拆分并发执行，失败部分换节点重试，结果按原顺序写回
+// - 按节点统计请求数、错误数、延迟直方图和存活状态，另按 RPC 方法统计（internal/rpcmetrics），可选通过 /metrics 以 Prometheus 格式暴露
 //
 // 使用方式：
 //   export ETH_RPC_URLS="http://127.0.0.1:8545,https://sepolia.infura.io/v3/<project-id>"
-//   go run main.go
+//   export ETH_CHAIN_ID=11155111   # 可选，指定期望的链 ID
+//   export NODES_CONFIG=nodes.json  # 可选，从 JSON 文件读取节点列表（替代 ETH_RPC_URLS），修改后自动热更新
+//   export WRITE_MODE=broadcast     # 可选，交易广播到所有可用节点（默认 primary）
+//   export METRICS_ADDR=:9100      # 可选，设置后在该地址提供 /metrics，演示结束后继续运行直到 Ctrl+C
+//   go run .
+//
+// 发送真实交易（演示写路径：主节点故障转移、nonce 连续性、通过读节点查回执，需要至少 2 个节点）：
+//   export SENDER_PRIVATE_KEY=<hex>
+//   go run . --to 0xRecipient --amount 0.0001
 
 // NodeStatus 表示单个节点的状态
 type NodeStatus struct {
 	URL    string
 	Client *ethclient.Client
 	Alive  bool
+
+	// Stale 节点高度落后过多，暂时不参与读写选择
+	Stale bool
+	// Height 最近一次检测到的区块高度
+	Height uint64
+	// AliveSince 最近一次变为可用的时间，失效时清零，用于计算在线时长得分
+	AliveSince time.Time
+
+	// Weight 读请求权重（平滑加权轮询），默认 1
+	Weight int
+	// currentWeight 平滑加权轮询的当前权重
+	currentWeight int
+	// breaker 熔断器，决定 Alive 的取值（见 breaker.go）
+	breaker circuitBreaker
+	// inflight 正在执行的请求数，节点被移除时等待其归零后再关闭连接
+	inflight atomic.Int64
+}
+
+// usable 节点当前是否可以接收请求
+func (n *NodeStatus) usable() bool {
+	return n.Alive && !n.Stale && n.Client != nil
+}
+
+// weight 有效读权重，未配置时为 1
+func (n *NodeStatus) weight() int {
+	return max(1, n.Weight)
+}
+
+// release 请求结束，与 pickReadNode / pickPrimaryNode 成对调用
+func (n *NodeStatus) release() {
+	n.inflight.Add(-1)
 }
 
 // EthClientPool 简单连接池
@@ -40,12 +103,36 @@ type EthClientPool struct {
 	// 写主节点索引（默认 0）
 	primaryIdx int
 
-	// 读操作轮询索引
-	readIdx int
+	// 连接池的链 ID，所有节点必须一致
+	chainID *big.Int
+
+	// 主节点选举：当前候选节点连续胜出次数与主节点切换记录
+	challenger       string
+	challengerStreak int
+	primaryEvents    []PrimaryChange
+	election         ElectionConfig
+
+	// 读操作最多尝试的节点数
+	maxReadAttempts int
+
+	// 按节点统计的请求指标
+	metrics *poolMetrics
+
+	// 节点熔断器配置
+	breakerCfg BreakerConfig
+
+	// 写操作模式与进行中的广播（按交易哈希去重）
+	writeMode  WriteMode
+	broadcasts map[common.Hash]*broadcastCall
+
+	// 发送交易时的 nonce 分配（见 write.go）
+	sendMu sync.Mutex
+	nonces *nonceTracker
 }
 
 // NewEthClientPool 根据多个 RPC URL 初始化连接池
-func NewEthClientPool(ctx context.Context, urls []string) (*EthClientPool, error) {
+// expectedChainID 为 nil 时以多数节点报告的链 ID 为准，链 ID 不一致的节点不会加入连接池
+func NewEthClientPool(ctx context.Context, urls []string, expectedChainID *big.Int) (*EthClientPool, error) {
 	if len(urls) == 0 {
 		return nil, fmt.Errorf("no rpc urls provided")
 	}
@@ -64,6 +151,7 @@ func NewEthClientPool(ctx context.Context, urls []string) (*EthClientPool, error
 				URL:    u,
 				Client: nil,
 				Alive:  false,
+				Weight: 1,
 			})
 			continue
 		}
@@ -73,9 +161,14 @@ func NewEthClientPool(ctx context.Context, urls []string) (*EthClientPool, error
 			URL:    u,
 			Client: client,
 			Alive:  true,
+			Weight: 1,
+
+			AliveSince: time.Now(),
 		})
 	}
 
+	chainID, nodes := verifyChainIDs(ctx, nodes, expectedChainID)
+
 	if len(nodes) == 0 {
 		return nil, fmt.Errorf("no node connected successfully")
 	}
@@ -83,30 +176,62 @@ func NewEthClientPool(ctx context.Context, urls []string) (*EthClientPool, error
 	p := &EthClientPool{
 		nodes:      nodes,
 		primaryIdx: 0,
-		readIdx:    0,
+		chainID:    chainID,
+
+		maxReadAttempts: defaultMaxReadAttempts,
+		metrics:         newPoolMetrics(),
+		election:        DefaultElectionConfig,
+		breakerCfg:      DefaultBreakerConfig,
+		broadcasts:      make(map[common.Hash]*broadcastCall),
+		nonces:          newNonceTracker(),
 	}
 
 	return p, nil
 }
 
-// pickReadNode 轮询选择一个可用节点
-func (p *EthClientPool) pickReadNode() *NodeStatus {
+// pickReadNode 按权重平滑轮询选择一个可用节点，跳过 exclude 中的节点（按 URL）；
+// ctx 携带 Session 时按读己之写约束选择（见 session.go）。
+// 返回的节点已计入 inflight，调用方用完后必须调用 node.release()。
+func (p *EthClientPool) pickReadNode(ctx context.Context, exclude map[string]bool) *NodeStatus {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	n := len(p.nodes)
-	for i := 0; i < n; i++ {
-		idx := (p.readIdx + i) % n
-		node := p.nodes[idx]
-		if node.Alive && node.Client != nil {
-			p.readIdx = (idx + 1) % n
-			return node
+	var picked *NodeStatus
+	if s := sessionFrom(ctx); s != nil {
+		picked = p.pickSessionNodeLocked(s, exclude)
+	} else {
+		picked = p.pickWeightedLocked(func(n *NodeStatus) bool { return !exclude[n.URL] })
+	}
+	if picked == nil {
+		return nil
+	}
+	picked.inflight.Add(1)
+	return picked
+}
+
+// pickWeightedLocked 在满足 accept 的可用节点中做一次平滑加权轮询，调用方需持有 p.mu 写锁
+func (p *EthClientPool) pickWeightedLocked(accept func(*NodeStatus) bool) *NodeStatus {
+	var picked *NodeStatus
+	total := 0
+	for _, node := range p.nodes {
+		if !node.usable() || !accept(node) {
+			continue
+		}
+		node.currentWeight += node.weight()
+		total += node.weight()
+		if picked == nil || node.currentWeight > picked.currentWeight {
+			picked = node
 		}
 	}
-	return nil
+	if picked != nil {
+		picked.currentWeight -= total
+	}
+	return picked
 }
 
-// pickPrimaryNode 选择当前写主节点（如挂了则尝试切换）
+// pickPrimaryNode 选择当前写主节点；当前主节点不可用时立即切换到健康得分最高的节点
+// （平时的择优切换由 StartPrimaryElection 带迟滞地进行，见 election.go）。
+// 返回的节点已计入 inflight，调用方用完后必须调用 node.release()。
 func (p *EthClientPool) pickPrimaryNode() *NodeStatus {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -116,109 +241,174 @@ func (p *EthClientPool) pickPrimaryNode() *NodeStatus {
 	// 先看当前 primary 是否可用
 	if n > 0 && p.primaryIdx < n {
 		node := p.nodes[p.primaryIdx]
-		if node.Alive && node.Client != nil {
+		if node.usable() {
+			node.inflight.Add(1)
 			return node
 		}
 	}
 
-	// 否则从头找一个可用的，顺便更新 primaryIdx
-	for i := 0; i < n; i++ {
-		node := p.nodes[i]
-		if node.Alive && node.Client != nil {
-			log.Printf("[WARN] switch primary node to %s", node.URL)
-			p.primaryIdx = i
-			return node
-		}
+	// 否则选得分最高的可用节点，顺便更新 primaryIdx
+	idx, _ := p.bestScoredLocked()
+	if idx < 0 {
+		return nil
 	}
-	return nil
+	p.switchPrimaryLocked(idx, "primary unusable")
+	p.nodes[idx].inflight.Add(1)
+	return p.nodes[idx]
 }
 
-// markNodeDead 标记节点不可用
+// markNodeDead 立即熔断节点，用于链 ID 不一致等确定性故障；
+// 普通请求错误通过 recordResult 计入熔断器，由失败率决定是否熔断
 func (p *EthClientPool) markNodeDead(url string, cause error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	for _, node := range p.nodes {
-		if node.URL == url {
-			if node.Alive {
-				log.Printf("[ERROR] mark node dead, url=%s, err=%v", url, cause)
-			}
-			node.Alive = false
-			return
-		}
+	node := p.findNodeLocked(url)
+	if node == nil || node.breaker.state == BreakerOpen {
+		return
 	}
+	node.breaker.trip(p.breakerCfg, time.Now())
+	p.syncBreakerLocked(node, cause)
 }
 
-// GetLatestBlockNumber 读操作：获取最新区块号（简单读负载均衡）
-func (p *EthClientPool) GetLatestBlockNumber(ctx context.Context) (*big.Int, error) {
-	node := p.pickReadNode()
-	if node == nil {
-		return nil, fmt.Errorf("no alive node for read")
-	}
+// SetBreakerConfig 修改节点熔断器配置，对所有节点生效
+func (p *EthClientPool) SetBreakerConfig(cfg BreakerConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.breakerCfg = cfg
+}
 
-	number, err := node.Client.BlockNumber(ctx)
+// GetLatestBlockNumber 读操作：获取最新区块号（简单读负载均衡，失败自动换节点）
+func (p *EthClientPool) GetLatestBlockNumber(ctx context.Context) (*big.Int, error) {
+	number, err := readWithFailover(ctx, p, "eth_blockNumber", func(ctx context.Context, c *ethclient.Client) (uint64, error) {
+		return c.BlockNumber(ctx)
+	})
 	if err != nil {
-		p.markNodeDead(node.URL, err)
 		return nil, err
 	}
 
 	return new(big.Int).SetUint64(number), nil
 }
 
-// GetBalance 读操作示例：查余额
+// GetBalance 读操作示例：查余额（失败自动换节点）
 func (p *EthClientPool) GetBalance(ctx context.Context, addr common.Address) (*big.Int, error) {
-	node := p.pickReadNode()
-	if node == nil {
-		return nil, fmt.Errorf("no alive node for read")
-	}
-
-	bal, err := node.Client.BalanceAt(ctx, addr, nil)
-	if err != nil {
-		p.markNodeDead(node.URL, err)
-		return nil, err
-	}
-	return bal, nil
+	return readWithFailover(ctx, p, "eth_getBalance", func(ctx context.Context, c *ethclient.Client) (*big.Int, error) {
+		return c.BalanceAt(ctx, addr, nil)
+	})
 }
 
-// SendDummyWrite 写操作示例：通过主节点发送“写请求”
-// 这里不真正发交易，只是展示如何选用主节点。
-func (p *EthClientPool) SendDummyWrite(ctx context.Context) error {
-	_ = ctx
-
-	node := p.pickPrimaryNode()
-	if node == nil {
-		return fmt.Errorf("no alive node for write")
+func main() {
+	toAddrHex := flag.String("to", "", "recipient address for the write demo (optional)")
+	amountEth := flag.String("amount", "0.0001", "amount in ETH per transfer in the write demo")
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	// 写入演示的参数在连接节点之前校验；金额按十进制精确换算为 wei，不经过 float64
+	var amountWei *big.Int
+	if *toAddrHex != "" {
+		if !common.IsHexAddress(*toAddrHex) {
+			log.Fatalf("invalid --to address: %s", *toAddrHex)
+		}
+		var err error
+		if amountWei, err = decimal.ParseEther(*amountEth); err != nil {
+			log.Fatalf("invalid --amount: %v", err)
+		}
+		if amountWei.Sign() <= 0 {
+			log.Fatal("--amount must be positive")
+		}
 	}
 
-	log.Printf("[INFO] perform write operation via primary node: %s", node.URL)
-	// 真实场景中，这里会调用：
-	//   client.SendTransaction(ctx, signedTx)
-	// 或其他写操作。
-	return nil
-}
+	var (
+		urls      []string
+		poolCfg   *PoolConfig
+		configEnv = os.Getenv("NODES_CONFIG")
+	)
+	if configEnv != "" {
+		cfg, err := LoadPoolConfig(configEnv)
+		if err != nil {
+			log.Fatalf("failed to load nodes config: %v", err)
+		}
+		poolCfg = cfg
+		urls = cfg.URLs()
+	} else {
+		rpcURLsEnv := os.Getenv("ETH_RPC_URLS")
+		if rpcURLsEnv == "" {
+			log.Fatal("ETH_RPC_URLS is not set (example: http://127.0.0.1:8545,https://sepolia.infura.io/v3/<project-id>)")
+		}
+		urls = strings.Split(rpcURLsEnv, ",")
+	}
 
-func main() {
-	rpcURLsEnv := os.Getenv("ETH_RPC_URLS")
-	if rpcURLsEnv == "" {
-		log.Fatal("ETH_RPC_URLS is not set (example: http://127.0.0.1:8545,https://sepolia.infura.io/v3/<project-id>)")
+	var expectedChainID *big.Int
+	if v := os.Getenv("ETH_CHAIN_ID"); v != "" {
+		id, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			log.Fatalf("invalid ETH_CHAIN_ID: %s", v)
+		}
+		expectedChainID = id
 	}
 
-	urls := strings.Split(rpcURLsEnv, ",")
+	metricsAddr := os.Getenv("METRICS_ADDR")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	// 开启 /metrics 时常驻运行，直到 Ctrl+C；否则演示完即退出
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if metricsAddr != "" {
+		ctx, cancel = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	} else {
+		// 写演示需要等待两笔交易上链，给足时间
+		timeout := 15 * time.Second
+		if *toAddrHex != "" {
+			timeout = 3 * time.Minute
+		}
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
 	defer cancel()
 
-	pool, err := NewEthClientPool(ctx, urls)
+	pool, err := NewEthClientPool(ctx, urls, expectedChainID)
 	if err != nil {
 		log.Fatalf("failed to init client pool: %v", err)
 	}
 
-	fmt.Println("=== Multi Node Pool Demo ===")
-	fmt.Printf("Configured RPC URLs:\n")
+	switch mode := os.Getenv("WRITE_MODE"); mode {
+	case "", "primary":
+	case "broadcast":
+		pool.SetWriteMode(WriteBroadcast)
+	default:
+		log.Fatalf("invalid WRITE_MODE: %s (primary|broadcast)", mode)
+	}
+
+	if poolCfg != nil {
+		// 应用配置中的权重，之后监听文件变化
+		pool.UpdateNodes(ctx, poolCfg.Nodes)
+		pool.WatchConfig(ctx, configEnv, 5*time.Second)
+	}
+
+	out.Table("Configured RPC URLs", "URL")
 	for _, u := range urls {
-		fmt.Printf("  - %s\n", strings.TrimSpace(u))
+		out.Row(strings.TrimSpace(u))
 	}
-	fmt.Println("============================")
+
+	if metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", pool.MetricsHandler())
+		server := &http.Server{Addr: metricsAddr, Handler: mux}
+		go func() {
+			log.Printf("[INFO] metrics listening on %s/metrics", metricsAddr)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("metrics server failed: %v", err)
+			}
+		}()
+		defer server.Close()
+	}
+
+	// 后台健康检查：失效节点恢复后自动回到轮询
+	pool.StartHealthCheck(ctx, DefaultHealthCheckConfig)
+	// 后台高度检测：落后节点暂不参与读写
+	pool.StartLagMonitor(ctx, DefaultLagConfig)
+	// 后台主节点选举：按健康得分带迟滞地择优切换主节点
+	pool.StartPrimaryElection(ctx, DefaultElectionConfig)
 
 	// 示例 1：多次获取最新区块号，演示读负载均衡（轮询不同节点）
 	for i := 0; i < 3; i++ {
@@ -239,8 +429,48 @@ func main() {
 		log.Printf("[READ] balance of %s: %s wei", addr.Hex(), bal.String())
 	}
 
-	// 示例 3：写操作通过主节点执行
-	if err := pool.SendDummyWrite(ctx); err != nil {
-		log.Printf("[WRITE] write operation failed: %v", err)
+	// 示例 2.1：quorum 读，至少需要 2 个可用节点
+	if k := min(3, len(urls)); k >= 2 {
+		bal, err := pool.QuorumBalanceAt(ctx, addr, nil, k)
+		if err != nil {
+			log.Printf("[READ] quorum balance failed: %v", err)
+		} else {
+			log.Printf("[READ] quorum(%d) balance of %s: %s wei", k, addr.Hex(), bal.String())
+		}
+	}
+
+	// 示例 3：作为 bind.ContractBackend 使用（任何接受 ethclient 接口的代码都可以直接传入 pool）
+	var backend bind.ContractBackend = pool
+	if header, err := backend.HeaderByNumber(ctx, nil); err != nil {
+		log.Printf("[READ] header via ContractBackend failed: %v", err)
+	} else {
+		log.Printf("[READ] header via ContractBackend: number=%d, hash=%s", header.Number.Uint64(), header.Hash().Hex())
+	}
+
+	// 示例 4：通过连接池发送真实转账（演示主节点故障转移、nonce 连续性与回执查询）
+	if *toAddrHex != "" {
+		// 按配置创建签名器（SENDER_PRIVATE_KEY、keystore、助记词或远程签名服务，见 internal/signer）
+		sgn, err := signer.FromConfig(ctx, config.Get())
+		if err != nil {
+			log.Fatalf("failed to load signer: %v", err)
+		}
+		runWriteDemo(ctx, pool, sgn, common.HexToAddress(*toAddrHex), amountWei)
+	} else {
+		log.Printf("[WRITE] skip write demo (pass --to and --amount with SENDER_PRIVATE_KEY to send real transfers)")
+	}
+
+	pool.PrintMetrics(out)
+	if history := pool.PrimaryHistory(); len(history) > 0 {
+		out.Table("Primary Changes", "Time", "From", "To", "Reason")
+		for _, ev := range history {
+			out.Row(ev.Time.Format(time.RFC3339), redactURL(ev.From), redactURL(ev.To), ev.Reason)
+		}
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+
+	if metricsAddr != "" {
+		<-ctx.Done()
 	}
 }
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcmetrics"
+)
+
+// 按节点统计的运行指标：
+// - 请求数、错误数（仅统计可归因于节点的错误）、延迟直方图（秒）、当前存活/落后状态
+// - MetricsHandler 以 Prometheus 文本格式输出，不引入额外依赖；按方法的统计由 internal/rpcmetrics 记录，一并输出
+// - 指标标签使用节点序号和 scheme://host，避免把 URL 路径中的 API Key 暴露给监控系统
+
+// latencyBuckets 延迟直方图的桶上界（秒）
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// nodeMetrics 单个节点的累计指标
+type nodeMetrics struct {
+	requests uint64
+	errors   uint64
+	buckets  []uint64 // 与 latencyBuckets 一一对应，非累计
+	sum      float64  // 延迟总和（秒）
+
+	// 指数加权移动平均，反映最近的表现，用于主节点评分
+	ewmaLatency float64 // 秒
+	ewmaErrRate float64 // 0~1
+	hasEWMA     bool
+}
+
+// ewmaAlpha 移动平均的平滑系数，越大越偏重最近的请求
+const ewmaAlpha = 0.2
+
+// poolMetrics 连接池指标，按节点 URL 归档
+type poolMetrics struct {
+	mu    sync.Mutex
+	nodes map[string]*nodeMetrics
+}
+
+func newPoolMetrics() *poolMetrics {
+	return &poolMetrics{nodes: make(map[string]*nodeMetrics)}
+}
+
+// observe 记录一次请求的耗时和结果
+func (m *poolMetrics) observe(url string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nm, ok := m.nodes[url]
+	if !ok {
+		nm = &nodeMetrics{buckets: make([]uint64, len(latencyBuckets))}
+		m.nodes[url] = nm
+	}
+
+	nm.requests++
+	// NotFound、revert 等请求本身导致的错误不算节点故障
+	failed := 0.0
+	if isRetryableError(err) {
+		nm.errors++
+		failed = 1
+	}
+	sec := d.Seconds()
+	if nm.hasEWMA {
+		nm.ewmaLatency += ewmaAlpha * (sec - nm.ewmaLatency)
+		nm.ewmaErrRate += ewmaAlpha * (failed - nm.ewmaErrRate)
+	} else {
+		nm.ewmaLatency, nm.ewmaErrRate, nm.hasEWMA = sec, failed, true
+	}
+	nm.sum += sec
+	for i, le := range latencyBuckets {
+		if sec <= le {
+			nm.buckets[i]++
+			break
+		}
+	}
+}
+
+// NodeMetrics 单个节点的指标快照
+type NodeMetrics struct {
+	Index    int     `json:"index"`
+	Host     string  `json:"host"`
+	Alive    bool    `json:"alive"`
+	Stale    bool    `json:"stale"`
+	Breaker  string  `json:"breaker"`
+	Height   uint64  `json:"height"`
+	Requests uint64  `json:"requests"`
+	Errors   uint64  `json:"errors"`
+	AvgMs    float64 `json:"avg_ms"`
+	Score    float64 `json:"score"`
+	Primary  bool    `json:"primary"`
+
+	buckets []uint64
+	sum     float64
+}
+
+// MetricsSnapshot 返回所有节点的指标快照，按节点配置顺序排列
+func (p *EthClientPool) MetricsSnapshot() []NodeMetrics {
+	p.mu.RLock()
+	out := make([]NodeMetrics, len(p.nodes))
+	urls := make([]string, len(p.nodes))
+	scores := p.scoresLocked()
+	for i, node := range p.nodes {
+		urls[i] = node.URL
+		out[i] = NodeMetrics{
+			Index:   i,
+			Host:    redactURL(node.URL),
+			Alive:   node.Alive,
+			Stale:   node.Stale,
+			Breaker: node.breaker.state.String(),
+			Height:  node.Height,
+			Score:   scores[i],
+			Primary: i == p.primaryIdx,
+		}
+	}
+	p.mu.RUnlock()
+
+	p.metrics.mu.Lock()
+	defer p.metrics.mu.Unlock()
+	for i, u := range urls {
+		nm, ok := p.metrics.nodes[u]
+		if !ok {
+			out[i].buckets = make([]uint64, len(latencyBuckets))
+			continue
+		}
+		out[i].Requests = nm.requests
+		out[i].Errors = nm.errors
+		out[i].sum = nm.sum
+		out[i].buckets = append([]uint64(nil), nm.buckets...)
+		if nm.requests > 0 {
+			out[i].AvgMs = nm.sum / float64(nm.requests) * 1000
+		}
+	}
+	return out
+}
+
+// MetricsHandler 返回以 Prometheus 文本格式输出指标的 HTTP handler
+func (p *EthClientPool) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snap := p.MetricsSnapshot()
+
+		var b strings.Builder
+		writeHeader := func(name, typ, help string) {
+			fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		}
+		labels := func(n NodeMetrics) string {
+			return fmt.Sprintf(`node="%d",host=%s`, n.Index, strconv.Quote(n.Host))
+		}
+
+		writeHeader("ethpool_requests_total", "counter", "Total RPC requests sent to the node.")
+		for _, n := range snap {
+			fmt.Fprintf(&b, "ethpool_requests_total{%s} %d\n", labels(n), n.Requests)
+		}
+
+		writeHeader("ethpool_errors_total", "counter", "Total RPC requests that failed due to the node (network, rate limit, server errors).")
+		for _, n := range snap {
+			fmt.Fprintf(&b, "ethpool_errors_total{%s} %d\n", labels(n), n.Errors)
+		}
+
+		writeHeader("ethpool_request_duration_seconds", "histogram", "RPC request latency per node.")
+		for _, n := range snap {
+			var cum uint64
+			for i, le := range latencyBuckets {
+				cum += n.buckets[i]
+				fmt.Fprintf(&b, "ethpool_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+					labels(n), strconv.FormatFloat(le, 'g', -1, 64), cum)
+			}
+			fmt.Fprintf(&b, "ethpool_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels(n), n.Requests)
+			fmt.Fprintf(&b, "ethpool_request_duration_seconds_sum{%s} %g\n", labels(n), n.sum)
+			fmt.Fprintf(&b, "ethpool_request_duration_seconds_count{%s} %d\n", labels(n), n.Requests)
+		}
+
+		writeHeader("ethpool_node_alive", "gauge", "Whether the node is currently alive (1) or marked dead (0).")
+		for _, n := range snap {
+			fmt.Fprintf(&b, "ethpool_node_alive{%s} %d\n", labels(n), boolToInt(n.Alive))
+		}
+
+		writeHeader("ethpool_node_breaker_state", "gauge", "Circuit breaker state: 0 closed, 1 half-open, 2 open.")
+		for _, n := range snap {
+			fmt.Fprintf(&b, "ethpool_node_breaker_state{%s} %d\n", labels(n), breakerStateValue(n.Breaker))
+		}
+
+		writeHeader("ethpool_node_stale", "gauge", "Whether the node is excluded for lagging behind (1) or not (0).")
+		for _, n := range snap {
+			fmt.Fprintf(&b, "ethpool_node_stale{%s} %d\n", labels(n), boolToInt(n.Stale))
+		}
+
+		writeHeader("ethpool_node_height", "gauge", "Last observed block height of the node.")
+		for _, n := range snap {
+			fmt.Fprintf(&b, "ethpool_node_height{%s} %d\n", labels(n), n.Height)
+		}
+
+		writeHeader("ethpool_node_score", "gauge", "Health score used for primary election (0-1).")
+		for _, n := range snap {
+			fmt.Fprintf(&b, "ethpool_node_score{%s} %g\n", labels(n), n.Score)
+		}
+
+		writeHeader("ethpool_node_primary", "gauge", "Whether the node is the current write primary (1) or not (0).")
+		for _, n := range snap {
+			fmt.Fprintf(&b, "ethpool_node_primary{%s} %d\n", labels(n), boolToInt(n.Primary))
+		}
+
+		// 按方法统计的请求数、错误数和延迟（internal/rpcmetrics）
+		_, _ = rpcmetrics.Default.WriteTo(&b)
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(b.String()))
+	})
+}
+
+// PrintMetrics 把各节点指标输出为表格，按请求数从高到低排列
+func (p *EthClientPool) PrintMetrics(out *output.Printer) {
+	snap := p.MetricsSnapshot()
+	sort.SliceStable(snap, func(i, j int) bool { return snap[i].Requests > snap[j].Requests })
+	out.Table("Node Metrics", "Index", "Host", "Primary", "Breaker", "Stale", "Height", "Requests", "Errors", "Avg Ms", "Score")
+	for _, n := range snap {
+		out.Row(n.Index, n.Host, n.Primary, n.Breaker, n.Stale, n.Height, n.Requests, n.Errors,
+			strconv.FormatFloat(n.AvgMs, 'f', 1, 64), strconv.FormatFloat(n.Score, 'f', 2, 64))
+	}
+}
+
+// redactURL 只保留 scheme://host，去掉路径和查询参数中可能携带的 API Key
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "invalid"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// breakerStateValue 把熔断器状态名转换成指标值
+func breakerStateValue(state string) int {
+	for s := BreakerClosed; s <= BreakerOpen; s++ {
+		if s.String() == state {
+			return int(s)
+		}
+	}
+	return -1
+}
+
+func boolToInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/rpcmetrics"
+)
+
+// 多数一致（quorum）读模式：
+// - 同一个读请求并发发给 K 个不同的可用节点，比较结果
+// - 超过半数节点结果一致时返回该结果，少数派节点输出告警日志（可能是节点数据错误或被篡改）
+// - 没有多数一致时返回 errNoQuorum，调用方可以选择重试或报警
+// - “latest” 在各节点上对应的区块可能不同，因此查询前先固定到所有可用节点都已同步到的高度
+// 适合不愿意完全信任单个服务商的场景，代价是 K 倍的请求量。
+
+// errNoQuorum 没有超过半数的节点给出一致结果
+type errNoQuorum struct {
+	Op      string
+	Asked   int
+	Results map[string][]string // 结果摘要 -> 给出该结果的节点 URL
+	Errors  map[string]error    // 节点 URL -> 请求失败原因
+}
+
+func (e *errNoQuorum) Error() string {
+	return fmt.Sprintf("%s: no quorum among %d node(s), distinct results=%d, failed=%d",
+		e.Op, e.Asked, len(e.Results), len(e.Errors))
+}
+
+// quorumVote 单个节点的返回结果
+type quorumVote[T any] struct {
+	url   string
+	value T
+	key   string
+	err   error
+}
+
+// quorumRead 在 k 个不同节点上并发执行 fn，key 把结果转换成用于比较的摘要；
+// 超过半数（k/2+1）的节点摘要一致时返回该结果
+func quorumRead[T any](ctx context.Context, p *EthClientPool, op string, k int, fn func(context.Context, *ethclient.Client) (T, error), key func(T) string) (T, error) {
+	var zero T
+	if k < 1 {
+		return zero, fmt.Errorf("%s: invalid quorum size %d", op, k)
+	}
+
+	exclude := make(map[string]bool)
+	nodes := make([]*NodeStatus, 0, k)
+	for len(nodes) < k {
+		node := p.pickReadNode(ctx, exclude)
+		if node == nil {
+			break
+		}
+		exclude[node.URL] = true
+		nodes = append(nodes, node)
+	}
+	if len(nodes) < k {
+		for _, node := range nodes {
+			node.release()
+		}
+		return zero, fmt.Errorf("%s: need %d usable node(s) for quorum, have %d", op, k, len(nodes))
+	}
+
+	votes := make([]quorumVote[T], len(nodes))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node *NodeStatus) {
+			defer wg.Done()
+			defer node.release()
+			start := time.Now()
+			value, err := fn(ctx, node.Client)
+			p.metrics.observe(node.URL, time.Since(start), err)
+			rpcmetrics.Default.Observe(op, time.Since(start), err)
+			p.recordResult(node.URL, err)
+
+			v := quorumVote[T]{url: node.URL, value: value, err: err}
+			switch {
+			case err == nil:
+				v.key = key(value)
+			case errors.Is(err, ethereum.NotFound):
+				// “不存在”本身也是一种结果，参与投票
+				v.key, v.err = "not found", nil
+			}
+			votes[i] = v
+		}(i, node)
+	}
+	wg.Wait()
+
+	results := make(map[string][]string)
+	failed := make(map[string]error)
+	for _, v := range votes {
+		if v.err != nil {
+			failed[v.url] = v.err
+			continue
+		}
+		results[v.key] = append(results[v.key], v.url)
+	}
+
+	need := k/2 + 1
+	for winner, urls := range results {
+		if len(urls) < need {
+			continue
+		}
+		for other, minority := range results {
+			if other != winner {
+				log.Printf("[WARN] quorum discrepancy, op=%s, nodes=%v disagree with majority (%d/%d)",
+					op, minority, len(urls), k)
+			}
+		}
+		for _, v := range votes {
+			if v.err == nil && v.key == winner {
+				if winner == "not found" {
+					return zero, ethereum.NotFound
+				}
+				return v.value, nil
+			}
+		}
+	}
+
+	return zero, &errNoQuorum{Op: op, Asked: k, Results: results, Errors: failed}
+}
+
+// quorumHeight 返回所有可用节点都已同步到的区块高度，用于固定 “latest” 查询。
+// 高度检测尚未产出数据时退回到普通读取的最新高度。
+func (p *EthClientPool) quorumHeight(ctx context.Context) (*big.Int, error) {
+	p.mu.RLock()
+	var height uint64
+	for _, node := range p.nodes {
+		if !node.usable() || node.Height == 0 {
+			continue
+		}
+		if height == 0 || node.Height < height {
+			height = node.Height
+		}
+	}
+	p.mu.RUnlock()
+
+	if height == 0 {
+		return p.GetLatestBlockNumber(ctx)
+	}
+	return new(big.Int).SetUint64(height), nil
+}
+
+// QuorumBalanceAt 在 k 个节点上查询余额并取多数结果；blockNumber 为 nil 时固定到公共高度
+func (p *EthClientPool) QuorumBalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int, k int) (*big.Int, error) {
+	if blockNumber == nil {
+		h, err := p.quorumHeight(ctx)
+		if err != nil {
+			return nil, err
+		}
+		blockNumber = h
+	}
+	return quorumRead(ctx, p, "eth_getBalance", k, func(ctx context.Context, c *ethclient.Client) (*big.Int, error) {
+		return c.BalanceAt(ctx, account, blockNumber)
+	}, func(v *big.Int) string {
+		return v.String()
+	})
+}
+
+// QuorumNonceAt 在 k 个节点上查询 nonce 并取多数结果；blockNumber 为 nil 时固定到公共高度
+func (p *EthClientPool) QuorumNonceAt(ctx context.Context, account common.Address, blockNumber *big.Int, k int) (uint64, error) {
+	if blockNumber == nil {
+		h, err := p.quorumHeight(ctx)
+		if err != nil {
+			return 0, err
+		}
+		blockNumber = h
+	}
+	return quorumRead(ctx, p, "eth_getTransactionCount", k, func(ctx context.Context, c *ethclient.Client) (uint64, error) {
+		return c.NonceAt(ctx, account, blockNumber)
+	}, func(v uint64) string {
+		return fmt.Sprint(v)
+	})
+}
+
+// QuorumCallContract 在 k 个节点上执行 eth_call 并取多数结果；blockNumber 为 nil 时固定到公共高度
+func (p *EthClientPool) QuorumCallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int, k int) ([]byte, error) {
+	if blockNumber == nil {
+		h, err := p.quorumHeight(ctx)
+		if err != nil {
+			return nil, err
+		}
+		blockNumber = h
+	}
+	return quorumRead(ctx, p, "eth_call", k, func(ctx context.Context, c *ethclient.Client) ([]byte, error) {
+		return c.CallContract(ctx, call, blockNumber)
+	}, func(v []byte) string {
+		return hex.EncodeToString(v)
+	})
+}
+
+// QuorumTransactionReceipt 在 k 个节点上查询交易回执并取多数结果。
+// 比较回执的共识编码（状态、累计 gas、bloom、日志）以及所在区块哈希，
+// 因此处于不同分叉上的节点会被识别为不一致。
+func (p *EthClientPool) QuorumTransactionReceipt(ctx context.Context, txHash common.Hash, k int) (*types.Receipt, error) {
+	return quorumRead(ctx, p, "eth_getTransactionReceipt", k, func(ctx context.Context, c *ethclient.Client) (*types.Receipt, error) {
+		return c.TransactionReceipt(ctx, txHash)
+	}, func(r *types.Receipt) string {
+		enc, err := r.MarshalBinary()
+		if err != nil {
+			// 编码失败时退回到关键字段，仍然可以比较
+			return fmt.Sprintf("%d/%d/%s", r.Status, r.GasUsed, r.BlockHash.Hex())
+		}
+		return hex.EncodeToString(enc) + "@" + r.BlockHash.Hex()
+	})
+}
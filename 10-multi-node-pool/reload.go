@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// 节点列表热更新：
+// - 节点列表写在 JSON 配置文件中，后台定期检查文件修改时间，变化后重新加载
+// - 新增节点：拨号并校验链 ID，连不上的节点先以失效状态加入，由健康检查后续恢复
+// - 删除节点：立即从选择列表中摘除，等待该节点上正在执行的请求结束（或超时）后再关闭连接
+// - 已有节点：只更新读权重，连接和统计数据保持不变
+// 更换服务商时不需要重启进程。
+//
+// 配置文件格式：
+//   {"nodes": [{"url": "https://a.example/v3/<key>", "weight": 2}, {"url": "http://127.0.0.1:8545"}]}
+
+// NodeConfig 单个节点配置
+type NodeConfig struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight,omitempty"` // 读权重，默认 1
+}
+
+// PoolConfig 节点列表配置文件
+type PoolConfig struct {
+	Nodes []NodeConfig `json:"nodes"`
+}
+
+// drainTimeout 被移除节点等待在途请求结束的最长时间
+const drainTimeout = 30 * time.Second
+
+// LoadPoolConfig 读取并校验节点列表配置文件
+func LoadPoolConfig(path string) (*PoolConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read pool config: %w", err)
+	}
+
+	var cfg PoolConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse pool config: %w", err)
+	}
+
+	seen := make(map[string]bool, len(cfg.Nodes))
+	for i := range cfg.Nodes {
+		n := &cfg.Nodes[i]
+		n.URL = strings.TrimSpace(n.URL)
+		if n.URL == "" {
+			return nil, fmt.Errorf("pool config: node #%d has empty url", i)
+		}
+		if seen[n.URL] {
+			return nil, fmt.Errorf("pool config: duplicate node url %s", redactURL(n.URL))
+		}
+		seen[n.URL] = true
+		if n.Weight < 0 {
+			return nil, fmt.Errorf("pool config: node %s has negative weight", redactURL(n.URL))
+		}
+	}
+	if len(cfg.Nodes) == 0 {
+		return nil, fmt.Errorf("pool config: no nodes")
+	}
+	return &cfg, nil
+}
+
+// URLs 返回配置中的全部节点 URL
+func (c *PoolConfig) URLs() []string {
+	urls := make([]string, len(c.Nodes))
+	for i, n := range c.Nodes {
+		urls[i] = n.URL
+	}
+	return urls
+}
+
+// WatchConfig 启动后台协程，定期检查配置文件，修改后重新加载节点列表；ctx 取消时退出
+func (p *EthClientPool) WatchConfig(ctx context.Context, path string, interval time.Duration) {
+	go func() {
+		var lastMod time.Time
+		if fi, err := os.Stat(path); err == nil {
+			lastMod = fi.ModTime()
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+
+			fi, err := os.Stat(path)
+			if err != nil {
+				log.Printf("[WARN] stat pool config failed, path=%s, err=%v", path, err)
+				continue
+			}
+			if !fi.ModTime().After(lastMod) {
+				continue
+			}
+			lastMod = fi.ModTime()
+
+			cfg, err := LoadPoolConfig(path)
+			if err != nil {
+				// 配置有误时保留当前节点列表
+				log.Printf("[ERROR] reload pool config failed, keep current nodes: %v", err)
+				continue
+			}
+			p.UpdateNodes(ctx, cfg.Nodes)
+		}
+	}()
+}
+
+// UpdateNodes 把连接池的节点列表调整为 cfgs：新增、移除节点并更新权重
+func (p *EthClientPool) UpdateNodes(ctx context.Context, cfgs []NodeConfig) {
+	p.mu.RLock()
+	existing := make(map[string]bool, len(p.nodes))
+	for _, node := range p.nodes {
+		existing[node.URL] = true
+	}
+	p.mu.RUnlock()
+
+	// 新节点的拨号和链 ID 校验在锁外进行，避免阻塞正常请求
+	added := make(map[string]*NodeStatus)
+	for _, c := range cfgs {
+		if existing[c.URL] {
+			continue
+		}
+		added[c.URL] = p.dialNode(ctx, c)
+	}
+
+	p.mu.Lock()
+	var primaryURL string
+	if p.primaryIdx < len(p.nodes) {
+		primaryURL = p.nodes[p.primaryIdx].URL
+	}
+
+	current := make(map[string]*NodeStatus, len(p.nodes))
+	for _, node := range p.nodes {
+		current[node.URL] = node
+	}
+
+	nodes := make([]*NodeStatus, 0, len(cfgs))
+	for _, c := range cfgs {
+		node, ok := current[c.URL]
+		if ok {
+			if node.weight() != max(1, c.Weight) {
+				log.Printf("[INFO] node weight changed, url=%s, weight=%d->%d", node.URL, node.weight(), max(1, c.Weight))
+			}
+			node.Weight = c.Weight
+			delete(current, c.URL)
+		} else if node = added[c.URL]; node == nil {
+			// 第一次读取后被并发的 UpdateNodes 移除了，以本次调用之后的配置为准，跳过
+			continue
+		} else {
+			log.Printf("[INFO] node added, url=%s, alive=%t", node.URL, node.Alive)
+		}
+		nodes = append(nodes, node)
+	}
+	p.nodes = nodes
+
+	// 主节点按 URL 重新定位，被移除则立即选出新的主节点
+	p.primaryIdx = len(p.nodes)
+	for i, node := range p.nodes {
+		if node.URL == primaryURL {
+			p.primaryIdx = i
+		}
+	}
+	if p.primaryIdx == len(p.nodes) {
+		if idx, _ := p.bestScoredLocked(); idx >= 0 {
+			p.switchPrimaryLocked(idx, "primary removed from config")
+		} else {
+			p.primaryIdx = 0
+		}
+	}
+	p.mu.Unlock()
+
+	for _, node := range current {
+		log.Printf("[INFO] node removed, draining in-flight requests, url=%s, inflight=%d", node.URL, node.inflight.Load())
+		go p.drainNode(node)
+	}
+}
+
+// dialNode 为新节点拨号并校验链 ID；失败的节点以失效状态返回，交给健康检查恢复
+func (p *EthClientPool) dialNode(ctx context.Context, c NodeConfig) *NodeStatus {
+	node := &NodeStatus{URL: c.URL, Weight: c.Weight}
+
+	dialCtx, cancel := context.WithTimeout(ctx, DefaultHealthCheckConfig.Timeout)
+	defer cancel()
+
+	client, err := ethclient.DialContext(dialCtx, c.URL)
+	if err != nil {
+		log.Printf("[WARN] connect rpc failed, url=%s, err=%v", c.URL, err)
+		return node
+	}
+	id, err := client.ChainID(dialCtx)
+	if err == nil {
+		err = p.checkChainID(c.URL, id)
+	}
+	if err != nil {
+		log.Printf("[WARN] new node failed chain id check, url=%s, err=%v", c.URL, err)
+		client.Close()
+		return node
+	}
+
+	node.Client = client
+	node.Alive = true
+	node.AliveSince = time.Now()
+	return node
+}
+
+// drainNode 等待被移除节点上的在途请求结束后关闭连接
+func (p *EthClientPool) drainNode(node *NodeStatus) {
+	deadline := time.Now().Add(drainTimeout)
+	for node.inflight.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if n := node.inflight.Load(); n > 0 {
+		log.Printf("[WARN] drain timeout, closing node with %d in-flight request(s), url=%s", n, node.URL)
+	}
+	p.mu.Lock()
+	client := node.Client
+	node.Client, node.Alive = nil, false
+	p.mu.Unlock()
+	if client != nil {
+		client.Close()
+	}
+	log.Printf("[INFO] node closed, url=%s", node.URL)
+}
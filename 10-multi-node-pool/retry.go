@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/yzucdh1/examples/internal/rpcerr"
+	"github.com/yzucdh1/examples/internal/rpcmetrics"
+)
+
+// 读操作自动重试与故障转移：
+// - 某个节点出错时，先对错误分类：
+//   * 可重试（网络错误、超时、429 限流、节点同步中、5xx、节点内部错误等）：标记该节点失效，换下一个节点重试
+//   * 不可重试（NotFound、参数错误、执行 revert、nonce / 费用 / 余额错误、调用方取消等）：直接返回，不影响节点状态
+//   分类见 internal/rpcerr
+// - 最多尝试 maxReadAttempts 个不同节点，全部失败才把最后一个错误返回给调用方
+
+// defaultMaxReadAttempts 默认读操作最大尝试次数
+const defaultMaxReadAttempts = 3
+
+// JSON-RPC 错误码
+const (
+	rpcCodeMethodNotFound   = -32601
+	rpcCodeInvalidParams    = -32602
+	rpcCodeInvalidRequest   = -32600
+	rpcCodeParseError       = -32700
+	httpStatusServerErrorLo = 500
+)
+
+// isRetryableError 判断错误换节点重试是否可能成功
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// 调用方主动取消，不重试
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	// 结果不存在在所有节点上都一样
+	if errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+
+	// 按 rpcerr 分类：限流、节点落后换节点可能成功；revert、nonce、费用、余额问题在所有节点上结果相同
+	switch classified := rpcerr.Classify(err); {
+	case rpcerr.Temporary(classified):
+		return true
+	case errors.Is(classified, rpcerr.ErrExecutionReverted),
+		errors.Is(classified, rpcerr.ErrNonceTooLow),
+		errors.Is(classified, rpcerr.ErrReplacementUnderpriced),
+		errors.Is(classified, rpcerr.ErrInsufficientFunds),
+		errors.Is(classified, rpcerr.ErrAlreadyKnown):
+		return false
+	}
+
+	// HTTP 层错误：服务端错误可重试，其余 4xx 视为请求本身有问题
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= httpStatusServerErrorLo
+	}
+
+	// JSON-RPC 错误：请求本身有问题的错误码不重试
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.ErrorCode() {
+		case rpcCodeMethodNotFound, rpcCodeInvalidParams,
+			rpcCodeInvalidRequest, rpcCodeParseError:
+			return false
+		}
+		// -32000 等通用服务端错误（如 header not found）通常是节点状态问题，换节点可能成功
+		return true
+	}
+
+	// 网络层错误
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	// 未知错误：读操作是幂等的，保守起见换节点再试
+	return true
+}
+
+// readWithFailover 在可用节点上执行读操作，可重试错误时自动换节点
+func readWithFailover[T any](ctx context.Context, p *EthClientPool, op string, fn func(context.Context, *ethclient.Client) (T, error)) (T, error) {
+	var zero T
+	tried := make(map[string]bool)
+	var lastErr error
+
+	for attempt := 1; attempt <= p.maxReadAttempts; attempt++ {
+		node := p.pickReadNode(ctx, tried)
+		if node == nil {
+			break
+		}
+		tried[node.URL] = true
+
+		start := time.Now()
+		result, err := fn(ctx, node.Client)
+		p.metrics.observe(node.URL, time.Since(start), err)
+		rpcmetrics.Default.Observe(op, time.Since(start), err)
+		p.recordResult(node.URL, err)
+		node.release()
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		if !isRetryableError(err) || ctx.Err() != nil {
+			return zero, err
+		}
+
+		log.Printf("[WARN] %s failed on %s (attempt %d/%d), failover: %v",
+			op, node.URL, attempt, p.maxReadAttempts, err)
+	}
+
+	if lastErr == nil {
+		return zero, fmt.Errorf("no alive node for read")
+	}
+	return zero, fmt.Errorf("%s failed after trying %d node(s): %w", op, len(tried), lastErr)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// 读己之写（read-your-writes）一致性：
+// - 负载均衡的读请求可能落到还没收到新交易、或还没同步到交易所在区块的节点上，
+//   刚发完交易就查 nonce / 回执会得到过期结果
+// - 调用方用 WithSession 把一个 Session 放进 ctx，同一个 Session 内：
+//   * 发出交易后、看到回执前：读请求固定发给接收交易的节点（其交易池里一定有这笔交易）
+//   * 看到回执后：读请求只发给高度 >= 交易所在区块的节点，没有满足条件的节点时退回主节点
+// - 不带 Session 的请求不受影响，仍按连接池的默认策略选择节点
+
+// Session 一个逻辑会话（例如一个用户、一个任务）的读写一致性状态
+type Session struct {
+	mu sync.Mutex
+
+	// pending 已发送但尚未看到回执的交易 -> 接收该交易的节点 URL
+	pending map[common.Hash]string
+	// minHeight 会话内读请求要求节点达到的最低高度
+	minHeight uint64
+}
+
+// NewSession 创建一个新的读写会话
+func NewSession() *Session {
+	return &Session{pending: make(map[common.Hash]string)}
+}
+
+type sessionKey struct{}
+
+// WithSession 返回携带 Session 的 ctx，之后通过连接池发出的请求遵循读己之写
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+// sessionFrom 取出 ctx 中的 Session，没有时返回 nil
+func sessionFrom(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionKey{}).(*Session)
+	return s
+}
+
+// recordWrite 记录会话内发出的交易及接收它的节点
+func (s *Session) recordWrite(txHash common.Hash, url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[txHash] = url
+}
+
+// recordReceipt 会话内看到交易回执后，解除对接收节点的固定，改为要求节点高度达到回执所在区块
+func (s *Session) recordReceipt(r *types.Receipt) {
+	if r == nil || r.BlockNumber == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, r.TxHash)
+	s.minHeight = max(s.minHeight, r.BlockNumber.Uint64())
+}
+
+// constraints 返回会话当前的节点约束：需要优先使用的节点 URL 与最低高度
+func (s *Session) constraints() (map[string]bool, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pinned map[string]bool
+	if len(s.pending) > 0 {
+		pinned = make(map[string]bool, len(s.pending))
+		for _, url := range s.pending {
+			pinned[url] = true
+		}
+	}
+	return pinned, s.minHeight
+}
+
+// pickSessionNodeLocked 按会话约束选择节点，调用方需持有 p.mu 写锁。
+// 约束无法满足时返回当前主节点（主节点负责写入，最可能已经看到会话的写操作）。
+func (p *EthClientPool) pickSessionNodeLocked(s *Session, exclude map[string]bool) *NodeStatus {
+	pinned, minHeight := s.constraints()
+
+	if len(pinned) > 0 {
+		for _, node := range p.nodes {
+			if pinned[node.URL] && node.usable() && !exclude[node.URL] {
+				return node
+			}
+		}
+	} else if node := p.pickWeightedLocked(func(n *NodeStatus) bool {
+		return !exclude[n.URL] && n.Height >= minHeight
+	}); node != nil {
+		return node
+	}
+
+	if p.primaryIdx < len(p.nodes) {
+		if node := p.nodes[p.primaryIdx]; node.usable() && !exclude[node.URL] {
+			return node
+		}
+	}
+	return nil
+}
+
+// nodeURLByClient 根据 client 反查节点 URL
+func (p *EthClientPool) nodeURLByClient(c *ethclient.Client) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, node := range p.nodes {
+		if node.Client == c {
+			return node.URL
+		}
+	}
+	return ""
+}
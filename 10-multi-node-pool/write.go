@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yzucdh1/examples/internal/signer"
+	"github.com/yzucdh1/examples/internal/txbuilder"
+	"github.com/yzucdh1/examples/internal/txwait"
+)
+
+// 通过连接池发送真实交易（与 03-tx-ops 相同，由 internal/txbuilder 构造 EIP-1559 交易、internal/signer 签名）：
+// - nonce：主节点切换后，新主节点的交易池里可能还没有刚发出的交易，PendingNonceAt 会偏小，
+//   因此连接池在本地记录每个地址已使用的最大 nonce，取 max(本地记录+1, 主节点 pending nonce)
+// - 故障转移：SendTransaction 在主节点出错时把同一笔已签名交易重发给新主节点，哈希不变，不会重复扣款
+// - 回执：由 internal/txwait 经普通读节点等待，回执所在区块被重组掉时继续等待；
+//   配合 Session 使用时，看到回执前读请求固定到接收交易的节点
+
+// nonceTracker 按地址记录本进程已经使用过的最大 nonce
+type nonceTracker struct {
+	mu   sync.Mutex
+	used map[common.Address]uint64
+}
+
+func newNonceTracker() *nonceTracker {
+	return &nonceTracker{used: make(map[common.Address]uint64)}
+}
+
+// next 结合节点返回的 pending nonce 计算下一个可用 nonce
+func (t *nonceTracker) next(addr common.Address, pending uint64) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.used[addr]; ok && last+1 > pending {
+		log.Printf("[WARN] node pending nonce %d is behind local record %d for %s, use local", pending, last+1, addr.Hex())
+		return last + 1
+	}
+	return pending
+}
+
+// commit 记录交易已成功发出
+func (t *nonceTracker) commit(addr common.Address, nonce uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.used[addr]; !ok || nonce > last {
+		t.used[addr] = nonce
+	}
+}
+
+// SendTransfer 通过 internal/txbuilder 构造、签名并发送一笔 ETH 转账，返回已发送的交易
+func (p *EthClientPool) SendTransfer(ctx context.Context, sgn signer.Signer, to common.Address, valueWei *big.Int) (*types.Transaction, error) {
+	from := sgn.Address()
+
+	// 同一地址的 nonce 分配与发送串行进行，避免并发调用拿到相同 nonce
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+
+	pendingNonce, err := p.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("get nonce: %w", err)
+	}
+	nonce := p.nonces.next(from, pendingNonce)
+
+	// 连接池本身满足 txbuilder.Backend：费用、余额检查走读节点，发送走主节点（带故障转移）
+	signedTx, err := txbuilder.New(p, sgn).Send(ctx, txbuilder.Intent{
+		Type:  txbuilder.DynamicFee,
+		To:    &to,
+		Value: valueWei,
+		Nonce: &nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.nonces.commit(from, nonce)
+
+	log.Printf("[WRITE] sent tx %s, from=%s, to=%s, nonce=%d, value=%s wei",
+		signedTx.Hash().Hex(), from.Hex(), to.Hex(), nonce, valueWei)
+	return signedTx, nil
+}
+
+// runWriteDemo 演示通过连接池发送两笔转账：
+// 第一笔发出后模拟主节点故障，第二笔自动切到新主节点且 nonce 连续，最后通过读节点等待两笔回执
+func runWriteDemo(ctx context.Context, pool *EthClientPool, sgn signer.Signer, to common.Address, valueWei *big.Int) {
+	// 同一个会话内的读请求能看到本会话刚发出的交易
+	ctx = WithSession(ctx, NewSession())
+
+	tx1, err := pool.SendTransfer(ctx, sgn, to, valueWei)
+	if err != nil {
+		log.Printf("[WRITE] first transfer failed: %v", err)
+		return
+	}
+
+	// 模拟主节点在两笔交易之间宕机：新主节点的交易池里可能还没有 tx1，
+	// 此时 nonce 依靠本地记录保持连续
+	if primary := pool.pickPrimaryNode(); primary != nil {
+		primary.release()
+		pool.markNodeDead(primary.URL, errors.New("simulated primary outage"))
+	}
+
+	tx2, err := pool.SendTransfer(ctx, sgn, to, valueWei)
+	if err != nil {
+		log.Printf("[WRITE] second transfer failed (need at least 2 nodes for failover): %v", err)
+		return
+	}
+	if tx2.Nonce() != tx1.Nonce()+1 {
+		log.Printf("[WARN] nonce gap between transfers: %d -> %d", tx1.Nonce(), tx2.Nonce())
+	}
+
+	for _, tx := range []*types.Transaction{tx1, tx2} {
+		receipt, err := txwait.WaitMined(ctx, pool, tx.Hash(), 1)
+		if err != nil {
+			log.Printf("[WRITE] wait receipt for %s failed: %v", tx.Hash().Hex(), err)
+			continue
+		}
+		log.Printf("[WRITE] tx %s mined, block=%d, status=%d, gas_used=%d",
+			tx.Hash().Hex(), receipt.BlockNumber.Uint64(), receipt.Status, receipt.GasUsed)
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// 11-wallet-keystore
+// 基于 accounts/keystore 的钱包与 keystore 管理命令行工具：
+// - new：创建新账户，私钥以加密 keystore JSON 形式保存在 keystore 目录
+// - list：列出 keystore 目录中的全部账户地址
+// - import：导入 keystore JSON 文件，或通过环境变量导入十六进制私钥
+// - export：把账户导出为 keystore JSON（可用新密码重新加密）
+// - passwd：修改账户密码
+// - show：显示地址与公钥；只有显式传入 --reveal-private-key 时才输出私钥
+//
+// 密码读取顺序：环境变量 KEYSTORE_PASSWORD / KEYSTORE_NEW_PASSWORD，未设置时从标准输入读取。
+// 注意：标准输入读取时会回显，仅用于本地演示；生产环境请使用专门的密码输入或密钥管理服务。
+//
+// 使用方式：
+//   go run . new
+//   go run . list
+//   go run . import --file UTC--2024-...json
+//   IMPORT_PRIVATE_KEY=<hex> go run . import
+//   go run . export --address 0x... --out backup.json
+//   go run . passwd --address 0x...
+//   go run . show --address 0x...
+//
+// 所有子命令都支持 --keystore 指定目录（默认 ./keystore）、--light 使用轻量 scrypt 参数（仅用于测试）
+// 和 --format pretty|json|yaml|csv（export 不带 --out 时直接输出 keystore JSON，不受 --format 影响）。
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "new":
+		cmdNew(args)
+	case "list":
+		cmdList(args)
+	case "import":
+		cmdImport(args)
+	case "export":
+		cmdExport(args)
+	case "passwd":
+		cmdPasswd(args)
+	case "show":
+		cmdShow(args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: go run . <new|list|import|export|passwd|show> [flags]")
+	fmt.Fprintln(os.Stderr, "run a subcommand with -h to see its flags")
+}
+
+// commonFlags 所有子命令共用的参数
+type commonFlags struct {
+	dir   *string
+	light *bool
+	out   *output.Printer
+}
+
+// newFlagSet 创建子命令参数集并注册共用参数
+func newFlagSet(name string) (*flag.FlagSet, commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	return fs, commonFlags{
+		dir:   fs.String("keystore", "keystore", "keystore directory"),
+		light: fs.Bool("light", false, "use light scrypt parameters (fast, for testing only)"),
+		out:   output.AddFlags(fs),
+	}
+}
+
+// openKeyStore 打开 keystore 目录，不存在时自动创建
+func (c commonFlags) openKeyStore() *keystore.KeyStore {
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if *c.light {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+	return keystore.NewKeyStore(*c.dir, scryptN, scryptP)
+}
+
+// cmdNew 创建新账户
+func cmdNew(args []string) {
+	fs, cf := newFlagSet("new")
+	fs.Parse(args)
+
+	ks := cf.openKeyStore()
+	password := readPassword("KEYSTORE_PASSWORD", "Password for new account: ")
+	confirm := readPassword("KEYSTORE_PASSWORD", "Repeat password: ")
+	if password != confirm {
+		log.Fatal("passwords do not match")
+	}
+
+	account, err := ks.NewAccount(password)
+	if err != nil {
+		log.Fatalf("failed to create account: %v", err)
+	}
+
+	cf.out.Section("New Account")
+	printAccount(cf.out, account)
+	cf.out.Note("Back up the keystore file and remember the password: losing either means losing the funds.")
+	flush(cf.out)
+}
+
+// cmdList 列出所有账户
+func cmdList(args []string) {
+	fs, cf := newFlagSet("list")
+	fs.Parse(args)
+
+	ks := cf.openKeyStore()
+	accs := ks.Accounts()
+	if len(accs) == 0 {
+		cf.out.Note("no accounts in %s", *cf.dir)
+	}
+
+	cf.out.Table("Accounts", "#", "Address", "Keystore")
+	for i, acc := range accs {
+		cf.out.Row(i, acc.Address, acc.URL.Path)
+	}
+	flush(cf.out)
+}
+
+// cmdImport 导入 keystore JSON 文件或十六进制私钥
+func cmdImport(args []string) {
+	fs, cf := newFlagSet("import")
+	file := fs.String("file", "", "keystore JSON file to import (if empty, read hex key from IMPORT_PRIVATE_KEY)")
+	fs.Parse(args)
+
+	ks := cf.openKeyStore()
+
+	var (
+		account accounts.Account
+		err     error
+	)
+	if *file != "" {
+		keyJSON, readErr := os.ReadFile(*file)
+		if readErr != nil {
+			log.Fatalf("failed to read keystore file: %v", readErr)
+		}
+		password := readPassword("KEYSTORE_PASSWORD", "Password of the imported file: ")
+		newPassword := readPassword("KEYSTORE_NEW_PASSWORD", "Password to store it with: ")
+		account, err = ks.Import(keyJSON, password, newPassword)
+	} else {
+		keyHex := os.Getenv("IMPORT_PRIVATE_KEY")
+		if keyHex == "" {
+			log.Fatal("either --file or IMPORT_PRIVATE_KEY is required")
+		}
+		key, parseErr := crypto.HexToECDSA(strings.TrimPrefix(keyHex, "0x"))
+		if parseErr != nil {
+			log.Fatalf("invalid private key: %v", parseErr)
+		}
+		password := readPassword("KEYSTORE_PASSWORD", "Password to store it with: ")
+		account, err = ks.ImportECDSA(key, password)
+	}
+	if err != nil {
+		log.Fatalf("failed to import account: %v", err)
+	}
+
+	cf.out.Section("Imported Account")
+	printAccount(cf.out, account)
+	flush(cf.out)
+}
+
+// cmdExport 把账户导出为 keystore JSON
+func cmdExport(args []string) {
+	fs, cf := newFlagSet("export")
+	addrHex := fs.String("address", "", "account address (required)")
+	out := fs.String("out", "", "output file (default: stdout)")
+	fs.Parse(args)
+
+	ks := cf.openKeyStore()
+	account := findAccount(ks, *addrHex)
+
+	password := readPassword("KEYSTORE_PASSWORD", "Current password: ")
+	newPassword := readPassword("KEYSTORE_NEW_PASSWORD", "Password for the exported file: ")
+	keyJSON, err := ks.Export(account, password, newPassword)
+	if err != nil {
+		log.Fatalf("failed to export account: %v", err)
+	}
+
+	if *out == "" {
+		fmt.Println(string(keyJSON))
+		return
+	}
+	// keystore 文件只允许所有者读写
+	if err := os.WriteFile(*out, keyJSON, 0o600); err != nil {
+		log.Fatalf("failed to write %s: %v", *out, err)
+	}
+	cf.out.Section("Exported Account")
+	cf.out.Field("Address", account.Address)
+	cf.out.Field("File", *out)
+	flush(cf.out)
+}
+
+// cmdPasswd 修改账户密码
+func cmdPasswd(args []string) {
+	fs, cf := newFlagSet("passwd")
+	addrHex := fs.String("address", "", "account address (required)")
+	fs.Parse(args)
+
+	ks := cf.openKeyStore()
+	account := findAccount(ks, *addrHex)
+
+	password := readPassword("KEYSTORE_PASSWORD", "Current password: ")
+	newPassword := readPassword("KEYSTORE_NEW_PASSWORD", "New password: ")
+	if err := ks.Update(account, password, newPassword); err != nil {
+		log.Fatalf("failed to update password: %v", err)
+	}
+	cf.out.Section("Password Updated")
+	cf.out.Field("Address", account.Address)
+	flush(cf.out)
+}
+
+// cmdShow 显示地址与公钥，可选显示私钥
+func cmdShow(args []string) {
+	fs, cf := newFlagSet("show")
+	addrHex := fs.String("address", "", "account address (required)")
+	reveal := fs.Bool("reveal-private-key", false, "DANGEROUS: also print the private key")
+	fs.Parse(args)
+
+	ks := cf.openKeyStore()
+	account := findAccount(ks, *addrHex)
+
+	keyJSON, err := os.ReadFile(account.URL.Path)
+	if err != nil {
+		log.Fatalf("failed to read keystore file: %v", err)
+	}
+	password := readPassword("KEYSTORE_PASSWORD", "Password: ")
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		log.Fatalf("failed to decrypt key: %v", err)
+	}
+
+	cf.out.Section("Account")
+	printAccount(cf.out, account)
+	printPublicKey(cf.out, &key.PrivateKey.PublicKey)
+	if *reveal {
+		fmt.Fprintln(os.Stderr, "WARNING: anyone who sees the private key controls the account")
+		cf.out.Field("Private Key", hexutil.Encode(crypto.FromECDSA(key.PrivateKey)))
+	}
+	flush(cf.out)
+}
+
+// findAccount 在 keystore 中按地址查找账户
+func findAccount(ks *keystore.KeyStore, addrHex string) accounts.Account {
+	if !common.IsHexAddress(addrHex) {
+		log.Fatalf("missing or invalid --address: %q", addrHex)
+	}
+	account, err := ks.Find(accounts.Account{Address: common.HexToAddress(addrHex)})
+	if err != nil {
+		log.Fatalf("account %s not found: %v", addrHex, err)
+	}
+	return account
+}
+
+// printAccount 输出账户地址与 keystore 文件位置
+func printAccount(out *output.Printer, account accounts.Account) {
+	out.Field("Address", account.Address)
+	out.Field("Keystore", account.URL.Path)
+}
+
+// printPublicKey 输出公钥（未压缩 65 字节与压缩 33 字节两种格式）
+func printPublicKey(out *output.Printer, pub *ecdsa.PublicKey) {
+	out.Field("Public Key", hexutil.Encode(crypto.FromECDSAPub(pub)))
+	out.Field("Compressed", hexutil.Encode(crypto.CompressPubkey(pub)))
+}
+
+// flush 输出结构化格式收集到的结果
+func flush(out *output.Printer) {
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// stdin 交互式读取密码时复用同一个 reader
+var stdin = bufio.NewReader(os.Stdin)
+
+// readPassword 优先从环境变量读取密码，未设置时从标准输入读取一行
+func readPassword(envName, prompt string) string {
+	if v, ok := os.LookupEnv(envName); ok {
+		return v
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		log.Fatalf("failed to read password: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n")
+}
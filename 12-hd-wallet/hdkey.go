@@ -0,0 +1,90 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BIP-32 分层确定性密钥派生（仅私钥派生，secp256k1）：
+// - 主密钥：I = HMAC-SHA512(key="Bitcoin seed", data=seed)，左 32 字节为私钥，右 32 字节为链码
+// - 子密钥：I = HMAC-SHA512(key=父链码, data=...)，子私钥 = (IL + 父私钥) mod n
+//   * 硬化派生（索引 >= 2^31）：data = 0x00 || 父私钥 || 索引
+//   * 普通派生：data = 压缩父公钥 || 索引
+// 路径解析使用 go-ethereum 的 accounts.ParseDerivationPath，与 MetaMask / Ledger 的路径写法一致。
+
+// hdKey 派生过程中的扩展私钥
+type hdKey struct {
+	key       []byte // 32 字节私钥
+	chainCode []byte // 32 字节链码
+}
+
+// masterKey 从 BIP-39 种子计算主扩展私钥
+func masterKey(seed []byte) (*hdKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	if !validPrivateKey(sum[:32]) {
+		return nil, fmt.Errorf("invalid master key derived from seed")
+	}
+	return &hdKey{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// child 派生第 index 个子扩展私钥
+func (k *hdKey) child(index uint32) (*hdKey, error) {
+	var data []byte
+	if index >= 0x80000000 {
+		data = append([]byte{0x00}, k.key...)
+	} else {
+		priv, err := crypto.ToECDSA(k.key)
+		if err != nil {
+			return nil, err
+		}
+		data = crypto.CompressPubkey(&priv.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	il := new(big.Int).SetBytes(sum[:32])
+	n := crypto.S256().Params().N
+	if il.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("invalid child key at index %d, try the next index", index)
+	}
+	childKey := il.Add(il, new(big.Int).SetBytes(k.key))
+	childKey.Mod(childKey, n)
+	if childKey.Sign() == 0 {
+		return nil, fmt.Errorf("invalid child key at index %d, try the next index", index)
+	}
+
+	return &hdKey{key: math.PaddedBigBytes(childKey, 32), chainCode: sum[32:]}, nil
+}
+
+// derive 沿派生路径从主密钥派生出最终私钥
+func derive(master *hdKey, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	k := master
+	for _, index := range path {
+		next, err := k.child(index)
+		if err != nil {
+			return nil, err
+		}
+		k = next
+	}
+	return crypto.ToECDSA(k.key)
+}
+
+// validPrivateKey 私钥必须在 [1, n-1] 范围内
+func validPrivateKey(key []byte) bool {
+	v := new(big.Int).SetBytes(key)
+	return v.Sign() > 0 && v.Cmp(crypto.S256().Params().N) < 0
+}
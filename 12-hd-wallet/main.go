@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 12-hd-wallet
+// BIP-39 助记词 + BIP-32/BIP-44 分层确定性钱包派生工具：
+// - 生成新助记词（12/15/18/21/24 个单词），或通过环境变量导入已有助记词
+// - 支持可选的 BIP-39 密码（passphrase，俗称“第 25 个单词”）
+// - 按路径模板批量派生 N 个账户并输出地址，默认路径与 MetaMask 一致：m/44'/60'/0'/0/{i}
+//   Ledger Live 使用 m/44'/60'/{i}'/0/0，可通过 --path 指定
+// - 可选连接节点查询每个账户的余额
+// - 默认不输出私钥；只有显式传入 --reveal-private-key 时才输出
+//
+// 使用方式：
+//   go run . --generate --words 24                  # 生成新助记词并派生 5 个地址
+//   export MNEMONIC="abandon abandon ... about"
+//   export MNEMONIC_PASSPHRASE=""                    # 可选
+//   go run . --count 10
+//   go run . --path "m/44'/60'/{i}'/0/0" --count 3   # Ledger Live 路径
+//   ETH_RPC_URL=... go run . --balance               # 同时查询余额
+//   go run . --count 100 --format csv > accounts.csv  # 输出格式：pretty（默认）| json | yaml | csv
+
+func main() {
+	generate := flag.Bool("generate", false, "generate a new mnemonic instead of reading MNEMONIC")
+	words := flag.Int("words", 12, "number of words when generating (12, 15, 18, 21 or 24)")
+	pathTmpl := flag.String("path", "m/44'/60'/0'/0/{i}", "derivation path template, {i} is replaced by the account index")
+	start := flag.Int("start", 0, "first account index")
+	count := flag.Int("count", 5, "number of accounts to derive")
+	withBalance := flag.Bool("balance", false, "query balances via ETH_RPC_URL")
+	reveal := flag.Bool("reveal-private-key", false, "DANGEROUS: also print derived private keys")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if !strings.Contains(*pathTmpl, "{i}") {
+		log.Fatalf("path template must contain {i}: %s", *pathTmpl)
+	}
+	if *count <= 0 || *start < 0 {
+		log.Fatal("--count must be positive and --start must not be negative")
+	}
+
+	mnemonic := loadMnemonic(out, *generate, *words)
+	passphrase := os.Getenv("MNEMONIC_PASSPHRASE")
+
+	// 种子 = PBKDF2-HMAC-SHA512(助记词, "mnemonic"+passphrase, 2048 轮)
+	seed := bip39.NewSeed(mnemonic, passphrase)
+	master, err := masterKey(seed)
+	if err != nil {
+		log.Fatalf("failed to derive master key: %v", err)
+	}
+
+	var client *ethclient.Client
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(30*time.Second))
+	defer cancel()
+	if *withBalance {
+		rpcURL := config.Get().RPCURL
+		if rpcURL == "" {
+			log.Fatalf("--balance: %v", config.ErrNoRPC)
+		}
+		client, err = rpcretry.Dial(ctx, rpcURL)
+		if err != nil {
+			log.Fatalf("failed to connect to Ethereum node: %v", err)
+		}
+		defer client.Close()
+	}
+
+	out.Section("Derivation")
+	out.Field("Path", *pathTmpl)
+	out.Field("Passphrase", passphrase != "")
+
+	columns := []string{"Path", "Address"}
+	if client != nil {
+		columns = append(columns, "Balance (ETH)")
+	}
+	if *reveal {
+		columns = append(columns, "Private Key")
+	}
+	out.Table("Derived Accounts", columns...)
+	for i := *start; i < *start+*count; i++ {
+		pathStr := strings.ReplaceAll(*pathTmpl, "{i}", fmt.Sprint(i))
+		path, err := accounts.ParseDerivationPath(pathStr)
+		if err != nil {
+			log.Fatalf("invalid derivation path %s: %v", pathStr, err)
+		}
+
+		key, err := derive(master, path)
+		if err != nil {
+			log.Printf("skip %s: %v", pathStr, err)
+			continue
+		}
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+
+		row := []any{path.String(), addr}
+		if client != nil {
+			bal, err := client.BalanceAt(ctx, addr, nil)
+			if err != nil {
+				row = append(row, "error: "+err.Error())
+			} else {
+				row = append(row, ethutil.FormatEth(bal, 6))
+			}
+		}
+		if *reveal {
+			row = append(row, hexutil.Encode(crypto.FromECDSA(key)))
+		}
+		out.Row(row...)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadMnemonic 生成新助记词或从 MNEMONIC 环境变量读取并校验
+func loadMnemonic(out *output.Printer, generate bool, words int) string {
+	if generate {
+		// 单词数 = (熵位数 + 校验位数) / 11，熵位数 = 单词数 * 32 / 3
+		if words < 12 || words > 24 || words%3 != 0 {
+			log.Fatalf("invalid --words %d (12, 15, 18, 21 or 24)", words)
+		}
+		entropy, err := bip39.NewEntropy(words * 32 / 3)
+		if err != nil {
+			log.Fatalf("failed to generate entropy: %v", err)
+		}
+		mnemonic, err := bip39.NewMnemonic(entropy)
+		if err != nil {
+			log.Fatalf("failed to generate mnemonic: %v", err)
+		}
+		fmt.Fprintln(os.Stderr, "WARNING: write the mnemonic down offline; anyone who sees it controls every derived account")
+		out.Section("New Mnemonic")
+		out.Field("Mnemonic", mnemonic)
+		return mnemonic
+	}
+
+	mnemonic := strings.Join(strings.Fields(os.Getenv("MNEMONIC")), " ")
+	if mnemonic == "" {
+		log.Fatal("MNEMONIC is not set (or pass --generate)")
+	}
+	// 校验单词是否都在词表中以及校验和是否正确，避免抄错一个词后派生出完全不同的账户
+	if _, err := bip39.MnemonicToByteArray(mnemonic); err != nil {
+		log.Fatalf("invalid mnemonic: %v", err)
+	}
+	return mnemonic
+}
+
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// avatar 记录格式（ENSIP-12）：
+// - https://... / http://...：直接是图片地址
+// - ipfs://<cid>：需要通过网关访问
+// - data:image/...：内联图片
+// - eip155:<chainId>/erc721:<合约>/<tokenId> 或 erc1155：引用一个 NFT，
+//   图片需要再调用合约的 tokenURI / uri 读取元数据后获得，这里只解析出引用本身
+
+// ipfsGateway 把 ipfs:// 转换为可直接访问的 https 地址时使用的公共网关
+const ipfsGateway = "https://ipfs.io/ipfs/"
+
+// avatarInfo avatar 记录解析结果
+type avatarInfo struct {
+	Kind     string // https | ipfs | data | nft | unknown
+	URL      string // 可直接访问的地址（nft 类型为空）
+	ChainID  string
+	Standard string // erc721 | erc1155
+	Contract string
+	TokenID  string
+}
+
+// parseAvatar 解析 avatar 文本记录
+func parseAvatar(record string) avatarInfo {
+	switch {
+	case strings.HasPrefix(record, "https://"), strings.HasPrefix(record, "http://"):
+		return avatarInfo{Kind: "https", URL: record}
+	case strings.HasPrefix(record, "ipfs://"):
+		return avatarInfo{Kind: "ipfs", URL: ipfsGateway + strings.TrimPrefix(strings.TrimPrefix(record, "ipfs://"), "ipfs/")}
+	case strings.HasPrefix(record, "data:"):
+		return avatarInfo{Kind: "data"}
+	case strings.HasPrefix(strings.ToLower(record), "eip155:"):
+		// eip155:1/erc721:0xb47e.../2430
+		parts := strings.Split(record, "/")
+		if len(parts) != 3 {
+			break
+		}
+		asset := strings.SplitN(parts[1], ":", 2)
+		if len(asset) != 2 {
+			break
+		}
+		return avatarInfo{
+			Kind:     "nft",
+			ChainID:  strings.TrimPrefix(strings.ToLower(parts[0]), "eip155:"),
+			Standard: strings.ToLower(asset[0]),
+			Contract: asset[1],
+			TokenID:  parts[2],
+		}
+	}
+	return avatarInfo{Kind: "unknown"}
+}
+
+// printAvatar 输出 avatar 解析结果
+func printAvatar(out *output.Printer, info avatarInfo) {
+	out.Field("Kind", info.Kind)
+	switch info.Kind {
+	case "https", "ipfs":
+		out.Field("URL", info.URL)
+	case "data":
+		out.Field("URL", "(inline data URI)")
+	case "nft":
+		out.Field("Chain ID", info.ChainID)
+		out.Field("Standard", info.Standard)
+		out.Field("Contract", info.Contract)
+		out.Field("Token ID", info.TokenID)
+		out.Note("Note        : the image is in the token metadata (tokenURI / uri); the owner should be checked before trusting it")
+	}
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 13-ens
+// ENS（Ethereum Name Service）解析示例，直接调用 ENS Registry 与 Resolver 合约：
+// 1. namehash：离线计算域名的 namehash（ENS 合约内部使用的节点 ID）
+// 2. resolve：正向解析，域名 -> 地址（Registry.resolver(node) -> Resolver.addr(node)），同时输出 contenthash
+// 3. reverse：反向解析，地址 -> 主域名（<addr>.addr.reverse），并做正向确认防止伪造
+// 4. text：读取文本记录（avatar、url、email、com.twitter 等）
+// 5. avatar：读取 avatar 记录并解析其格式（https / ipfs / NFT 引用）
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://mainnet.infura.io/v3/<project-id>"
+//    go run . --mode namehash --name vitalik.eth
+//    go run . --mode resolve --name vitalik.eth
+//    go run . --mode reverse --address 0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045
+//    go run . --mode text --name vitalik.eth --keys avatar,url,com.twitter
+//    go run . --mode avatar --name vitalik.eth
+//    go run . --mode text --name vitalik.eth --format json   # 输出格式：pretty（默认）| json | yaml | csv
+//
+// 注意事项：
+// - ENS Registry 在主网及 Sepolia / Holesky 上地址相同，其他链需通过 --registry 指定
+// - 这里只做简单的规范化（去空格、转小写）；完整的 ENSIP-15 规范化（emoji、Unicode 混淆字符）
+//   请使用专门的库，否则包含特殊字符的域名可能解析到错误的节点
+// - 未实现 ENSIP-10 通配符解析和 CCIP-Read（离链解析），这类域名会解析失败
+
+// defaultRegistry ENS Registry 合约地址（主网 / Sepolia / Holesky 相同）
+const defaultRegistry = "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"
+
+const registryABIJSON = `[
+  {
+    "inputs": [{"name": "node", "type": "bytes32"}],
+    "name": "resolver",
+    "outputs": [{"name": "", "type": "address"}],
+    "stateMutability": "view",
+    "type": "function"
+  }
+]`
+
+const resolverABIJSON = `[
+  {
+    "inputs": [{"name": "node", "type": "bytes32"}],
+    "name": "addr",
+    "outputs": [{"name": "", "type": "address"}],
+    "stateMutability": "view",
+    "type": "function"
+  },
+  {
+    "inputs": [{"name": "node", "type": "bytes32"}],
+    "name": "name",
+    "outputs": [{"name": "", "type": "string"}],
+    "stateMutability": "view",
+    "type": "function"
+  },
+  {
+    "inputs": [{"name": "node", "type": "bytes32"}, {"name": "key", "type": "string"}],
+    "name": "text",
+    "outputs": [{"name": "", "type": "string"}],
+    "stateMutability": "view",
+    "type": "function"
+  },
+  {
+    "inputs": [{"name": "node", "type": "bytes32"}],
+    "name": "contenthash",
+    "outputs": [{"name": "", "type": "bytes"}],
+    "stateMutability": "view",
+    "type": "function"
+  }
+]`
+
+// ENS 通过 registry 和 resolver 合约提供解析功能
+type ENS struct {
+	client      *ethclient.Client
+	registry    common.Address
+	registryABI abi.ABI
+	resolverABI abi.ABI
+}
+
+func main() {
+	mode := flag.String("mode", "resolve", "operation mode: namehash | resolve | reverse | text | avatar")
+	name := flag.String("name", "", "ENS name, e.g. vitalik.eth")
+	addrHex := flag.String("address", "", "address for reverse resolution")
+	keys := flag.String("keys", "avatar,url,email,description,com.twitter,com.github", "comma separated text record keys")
+	registryHex := flag.String("registry", defaultRegistry, "ENS registry address")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+	defer func() {
+		if err := out.Flush(); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	if *mode == "namehash" {
+		if *name == "" {
+			log.Fatal("namehash mode requires --name")
+		}
+		n := normalize(*name)
+		out.Section("Namehash")
+		out.Field("Name", n)
+		out.Field("Namehash", Namehash(n))
+		out.Field("Labelhash", crypto.Keccak256Hash([]byte(strings.Split(n, ".")[0])))
+		return
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(20*time.Second))
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	ens, err := NewENS(client, common.HexToAddress(*registryHex))
+	if err != nil {
+		log.Fatalf("failed to init ens: %v", err)
+	}
+
+	switch *mode {
+	case "resolve":
+		requireName(*name)
+		n := normalize(*name)
+		addr, err := ens.Resolve(ctx, n)
+		if err != nil {
+			log.Fatalf("failed to resolve %s: %v", n, err)
+		}
+		out.Section("Forward Resolution")
+		out.Field("Name", n)
+		out.Field("Namehash", Namehash(n))
+		out.Field("Address", addr)
+		if hash, err := ens.Contenthash(ctx, n); err == nil && len(hash) > 0 {
+			out.Field("Content", hexutil.Encode(hash))
+		}
+
+	case "reverse":
+		if !common.IsHexAddress(*addrHex) {
+			log.Fatal("reverse mode requires a valid --address")
+		}
+		addr := common.HexToAddress(*addrHex)
+		primary, verified, err := ens.Reverse(ctx, addr)
+		if err != nil {
+			log.Fatalf("failed to reverse resolve %s: %v", addr.Hex(), err)
+		}
+		out.Section("Reverse Resolution")
+		out.Field("Address", addr)
+		out.Field("Reverse", reverseName(addr))
+		out.Field("Name", primary)
+		out.Field("Verified", verified)
+		if !verified {
+			out.Note("WARNING: forward resolution of the name does not point back to the address; do not display it as the primary name")
+		}
+
+	case "text":
+		requireName(*name)
+		n := normalize(*name)
+		out.Section("Text Records")
+		out.Field("Name", n)
+		for _, key := range strings.Split(*keys, ",") {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			value, err := ens.Text(ctx, n, key)
+			if err != nil {
+				log.Fatalf("failed to read text record %s: %v", key, err)
+			}
+			if value == "" {
+				value = "(not set)"
+			}
+			out.Field(key, value)
+		}
+
+	case "avatar":
+		requireName(*name)
+		n := normalize(*name)
+		record, err := ens.Text(ctx, n, "avatar")
+		if err != nil {
+			log.Fatalf("failed to read avatar record: %v", err)
+		}
+		out.Section("Avatar")
+		out.Field("Name", n)
+		if record == "" {
+			out.Field("Record", "(not set)")
+			return
+		}
+		out.Field("Record", record)
+		printAvatar(out, parseAvatar(record))
+
+	default:
+		log.Fatalf("unknown mode: %s", *mode)
+	}
+}
+
+// NewENS 创建 ENS 解析器
+func NewENS(client *ethclient.Client, registry common.Address) (*ENS, error) {
+	registryABI, err := abi.JSON(strings.NewReader(registryABIJSON))
+	if err != nil {
+		return nil, fmt.Errorf("parse registry abi: %w", err)
+	}
+	resolverABI, err := abi.JSON(strings.NewReader(resolverABIJSON))
+	if err != nil {
+		return nil, fmt.Errorf("parse resolver abi: %w", err)
+	}
+	return &ENS{client: client, registry: registry, registryABI: registryABI, resolverABI: resolverABI}, nil
+}
+
+// Namehash 按 EIP-137 计算 namehash：
+// namehash("") = 0x00..00，namehash(label.rest) = keccak256(namehash(rest) ++ keccak256(label))
+func Namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node.Bytes(), labelHash)
+	}
+	return node
+}
+
+// Resolver 查询域名对应的 resolver 合约地址
+func (e *ENS) Resolver(ctx context.Context, name string) (common.Address, error) {
+	var resolver common.Address
+	if err := e.call(ctx, e.registry, e.registryABI, &resolver, "resolver", Namehash(name)); err != nil {
+		return common.Address{}, err
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("no resolver set for %s", name)
+	}
+	return resolver, nil
+}
+
+// Resolve 正向解析：域名 -> 地址
+func (e *ENS) Resolve(ctx context.Context, name string) (common.Address, error) {
+	resolver, err := e.Resolver(ctx, name)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var addr common.Address
+	if err := e.call(ctx, resolver, e.resolverABI, &addr, "addr", Namehash(name)); err != nil {
+		return common.Address{}, err
+	}
+	if addr == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%s has no address record", name)
+	}
+	return addr, nil
+}
+
+// Reverse 反向解析：地址 -> 主域名，并用正向解析确认该域名确实指向此地址
+// （任何人都能把自己地址的反向记录设置成任意域名，不确认就展示会被钓鱼利用）
+func (e *ENS) Reverse(ctx context.Context, addr common.Address) (string, bool, error) {
+	rname := reverseName(addr)
+	resolver, err := e.Resolver(ctx, rname)
+	if err != nil {
+		return "", false, err
+	}
+	var name string
+	if err := e.call(ctx, resolver, e.resolverABI, &name, "name", Namehash(rname)); err != nil {
+		return "", false, err
+	}
+	if name == "" {
+		return "", false, fmt.Errorf("no primary name set for %s", addr.Hex())
+	}
+
+	forward, err := e.Resolve(ctx, normalize(name))
+	if err != nil {
+		return name, false, nil
+	}
+	return name, forward == addr, nil
+}
+
+// Text 读取文本记录，未设置时返回空字符串
+func (e *ENS) Text(ctx context.Context, name, key string) (string, error) {
+	resolver, err := e.Resolver(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	var value string
+	if err := e.call(ctx, resolver, e.resolverABI, &value, "text", Namehash(name), key); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// Contenthash 读取内容哈希记录（ENSIP-7，IPFS / Swarm 等去中心化网站），返回原始编码
+func (e *ENS) Contenthash(ctx context.Context, name string) ([]byte, error) {
+	resolver, err := e.Resolver(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	var hash []byte
+	if err := e.call(ctx, resolver, e.resolverABI, &hash, "contenthash", Namehash(name)); err != nil {
+		return nil, err
+	}
+	return hash, nil
+}
+
+// call 对合约发起只读调用并解码单个返回值
+func (e *ENS) call(ctx context.Context, to common.Address, contractABI abi.ABI, out interface{}, method string, args ...interface{}) error {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("pack %s: %w", method, err)
+	}
+	result, err := e.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("call %s on %s: %w", method, to.Hex(), err)
+	}
+	if len(result) == 0 {
+		return fmt.Errorf("call %s on %s: empty result (not a resolver or method not supported)", method, to.Hex())
+	}
+	values, err := contractABI.Unpack(method, result)
+	if err != nil {
+		return fmt.Errorf("unpack %s: %w", method, err)
+	}
+	if err := contractABI.Methods[method].Outputs.Copy(out, values); err != nil {
+		return fmt.Errorf("copy %s result: %w", method, err)
+	}
+	return nil
+}
+
+// reverseName 地址对应的反向解析域名：<小写十六进制地址，不带 0x>.addr.reverse
+func reverseName(addr common.Address) string {
+	return strings.ToLower(strings.TrimPrefix(addr.Hex(), "0x")) + ".addr.reverse"
+}
+
+// normalize 简单规范化域名（见文件头注意事项）
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func requireName(name string) {
+	if name == "" {
+		log.Fatal("this mode requires --name")
+	}
+}
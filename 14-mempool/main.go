@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 14-mempool
+// 监控交易池（mempool）中的待打包交易：
+// - 订阅 newPendingTransactions；节点支持时使用完整交易模式（--full），直接推送交易内容，
+//   否则只推送交易哈希，再通过 eth_getTransactionByHash 拉取交易详情
+// - 按目标合约（--to）和方法选择器（--selectors）过滤关心的交易，并解码常见 ERC-20 调用
+// - 同时订阅新区块，关注的交易被打包后输出从“首次看到”到“打包上链”的延迟
+// - 长时间未打包的交易视为被替换或丢弃，从跟踪列表中移除
+//
+// 执行示例：
+//    export ETH_WS_URL="wss://mainnet.infura.io/ws/v3/<project-id>"
+//    go run . --to 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48
+//    go run . --selectors 0xa9059cbb,0x095ea7b3 --full
+//    go run . --full --format json | jq 'select(.type == "mined")'   # 每个事件输出一行 JSON
+//
+// 注意事项：
+// - 订阅需要 WebSocket 连接；很多公共 RPC 服务不开放交易池订阅，或只推送部分交易
+// - 哈希模式下每笔交易都要额外请求一次，主网交易量很大，建议使用 --full 或较小的 --workers
+// - 看到的只是所连节点的交易池视图，私有交易（如通过 Flashbots 提交）不会出现在这里
+
+func main() {
+	toFlag := flag.String("to", "", "comma separated target contract addresses to watch (empty = any)")
+	selectorsFlag := flag.String("selectors", "", "comma separated 4-byte method selectors to watch, e.g. 0xa9059cbb (empty = any)")
+	full := flag.Bool("full", false, "subscribe to full pending transactions (newPendingTransactions with true, geth/reth), falls back to hash mode if unsupported")
+	workers := flag.Int("workers", 8, "max concurrent eth_getTransactionByHash requests in hash mode")
+	maxAge := flag.Duration("max-age", 30*time.Minute, "stop tracking a pending transaction after this long")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	filter, err := parseFilter(*toFlag, *selectorsFlag)
+	if err != nil {
+		log.Fatalf("invalid filter: %v", err)
+	}
+
+	rpcURL := config.Get().WSURL
+	if rpcURL == "" {
+		rpcURL = config.Get().RPCURL
+	}
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+	// 交易池订阅不在 ethclient 中，直接通过底层 rpc.Client 调用 eth_subscribe
+	rpcClient := client.Client()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatalf("failed to get chain id: %v", err)
+	}
+
+	monitor := newMonitor(client, types.LatestSignerForChainID(chainID), filter, *maxAge, out)
+
+	// 新区块用于检测关注的交易何时被打包
+	headers := make(chan *types.Header, 16)
+	headSub, err := client.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		log.Fatalf("failed to subscribe new heads: %v", err)
+	}
+	defer headSub.Unsubscribe()
+
+	txs := make(chan *types.Transaction, 1024)
+	hashes := make(chan common.Hash, 4096)
+	var pendingErr <-chan error
+
+	mode := "hash"
+	if *full {
+		sub, err := rpcClient.EthSubscribe(ctx, txs, "newPendingTransactions", true)
+		if err != nil {
+			log.Printf("[WARN] full pending transaction subscription not supported, fall back to hash mode: %v", err)
+		} else {
+			defer sub.Unsubscribe()
+			pendingErr = sub.Err()
+			mode = "full"
+		}
+	}
+	if mode == "hash" {
+		sub, err := rpcClient.EthSubscribe(ctx, hashes, "newPendingTransactions")
+		if err != nil {
+			log.Fatalf("failed to subscribe pending transactions: %v", err)
+		}
+		defer sub.Unsubscribe()
+		pendingErr = sub.Err()
+	}
+
+	out.Note("=== Mempool Monitor ===")
+	out.Note("Endpoint    : %s", rpcURL)
+	out.Note("Chain ID    : %s", chainID)
+	out.Note("Mode        : %s", mode)
+	out.Note("Filter      : %s", filter)
+
+	// 哈希模式下限制并发拉取数量，避免压垮节点
+	sem := make(chan struct{}, *workers)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	statsTicker := time.NewTicker(time.Minute)
+	defer statsTicker.Stop()
+
+	for {
+		select {
+		case tx := <-txs:
+			monitor.handlePending(tx, time.Now())
+
+		case hash := <-hashes:
+			seenAt := time.Now()
+			select {
+			case sem <- struct{}{}:
+			default:
+				monitor.dropped.Add(1)
+				continue
+			}
+			go func() {
+				defer func() { <-sem }()
+				fetchCtx, fetchCancel := context.WithTimeout(ctx, 5*time.Second)
+				defer fetchCancel()
+				tx, _, err := client.TransactionByHash(fetchCtx, hash)
+				if err != nil {
+					// 拉取前交易可能已被打包或替换，直接忽略
+					return
+				}
+				monitor.handlePending(tx, seenAt)
+			}()
+
+		case h := <-headers:
+			monitor.handleHead(ctx, h)
+
+		case <-statsTicker.C:
+			monitor.logStats()
+
+		case err := <-pendingErr:
+			log.Printf("pending subscription error: %v", err)
+			return
+		case err := <-headSub.Err():
+			log.Printf("head subscription error: %v", err)
+			return
+		case sig := <-sigCh:
+			out.Note("received signal %s, shutting down...", sig.String())
+			monitor.logStats()
+			return
+		}
+	}
+}
+
+// txFilter 关注的目标合约与方法选择器，均为空时匹配全部交易
+type txFilter struct {
+	to        map[common.Address]bool
+	selectors map[[4]byte]bool
+}
+
+// parseFilter 解析逗号分隔的地址与方法选择器
+func parseFilter(toList, selectorList string) (txFilter, error) {
+	f := txFilter{to: make(map[common.Address]bool), selectors: make(map[[4]byte]bool)}
+	for _, s := range splitList(toList) {
+		if !common.IsHexAddress(s) {
+			return f, fmt.Errorf("invalid address %q", s)
+		}
+		f.to[common.HexToAddress(s)] = true
+	}
+	for _, s := range splitList(selectorList) {
+		b, err := hexutil.Decode(s)
+		if err != nil || len(b) != 4 {
+			return f, fmt.Errorf("invalid selector %q, want 0x + 8 hex chars", s)
+		}
+		f.selectors[[4]byte(b)] = true
+	}
+	return f, nil
+}
+
+// match 判断交易是否需要关注
+func (f txFilter) match(tx *types.Transaction) bool {
+	if len(f.to) > 0 && (tx.To() == nil || !f.to[*tx.To()]) {
+		return false
+	}
+	if len(f.selectors) > 0 {
+		if len(tx.Data()) < 4 || !f.selectors[[4]byte(tx.Data()[:4])] {
+			return false
+		}
+	}
+	return true
+}
+
+func (f txFilter) String() string {
+	if len(f.to) == 0 && len(f.selectors) == 0 {
+		return "all transactions"
+	}
+	var parts []string
+	for addr := range f.to {
+		parts = append(parts, "to="+addr.Hex())
+	}
+	for sel := range f.selectors {
+		parts = append(parts, "selector="+hexutil.Encode(sel[:]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
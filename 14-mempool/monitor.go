@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// 用于解码常见调用的 ERC-20 方法 ABI
+const erc20ABIJSON = `[
+  {
+    "inputs": [{"name": "to", "type": "address"}, {"name": "value", "type": "uint256"}],
+    "name": "transfer",
+    "outputs": [{"name": "", "type": "bool"}],
+    "stateMutability": "nonpayable",
+    "type": "function"
+  },
+  {
+    "inputs": [{"name": "spender", "type": "address"}, {"name": "value", "type": "uint256"}],
+    "name": "approve",
+    "outputs": [{"name": "", "type": "bool"}],
+    "stateMutability": "nonpayable",
+    "type": "function"
+  },
+  {
+    "inputs": [{"name": "from", "type": "address"}, {"name": "to", "type": "address"}, {"name": "value", "type": "uint256"}],
+    "name": "transferFrom",
+    "outputs": [{"name": "", "type": "bool"}],
+    "stateMutability": "nonpayable",
+    "type": "function"
+  }
+]`
+
+var erc20ABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// pendingTx 正在跟踪的待打包交易
+type pendingTx struct {
+	tx     *types.Transaction
+	from   common.Address
+	seenAt time.Time
+}
+
+// monitor 跟踪关注的待打包交易，并在其被打包后统计延迟
+type monitor struct {
+	client *ethclient.Client
+	signer types.Signer
+	filter txFilter
+	maxAge time.Duration
+	out    *output.Printer
+
+	mu      sync.Mutex // 同时保护 tracked 和 out
+	tracked map[common.Hash]*pendingTx
+
+	seen     atomic.Int64 // 收到的待打包交易总数
+	matched  atomic.Int64 // 命中过滤条件的交易数
+	dropped  atomic.Int64 // 哈希模式下因并发已满而跳过的交易数
+	included atomic.Int64 // 已确认打包的关注交易数
+	expired  atomic.Int64 // 超过 maxAge 仍未打包的关注交易数
+	latency  atomic.Int64 // 已打包交易的延迟总和（毫秒）
+}
+
+func newMonitor(client *ethclient.Client, signer types.Signer, filter txFilter, maxAge time.Duration, out *output.Printer) *monitor {
+	return &monitor{
+		client:  client,
+		signer:  signer,
+		filter:  filter,
+		maxAge:  maxAge,
+		out:     out,
+		tracked: make(map[common.Hash]*pendingTx),
+	}
+}
+
+// handlePending 处理一笔待打包交易：命中过滤条件时解码输出并开始跟踪
+func (m *monitor) handlePending(tx *types.Transaction, seenAt time.Time) {
+	m.seen.Add(1)
+	if !m.filter.match(tx) {
+		return
+	}
+
+	m.mu.Lock()
+	if _, ok := m.tracked[tx.Hash()]; ok {
+		m.mu.Unlock()
+		return
+	}
+	from, err := types.Sender(m.signer, tx)
+	if err != nil {
+		m.mu.Unlock()
+		log.Printf("[WARN] recover sender of %s failed: %v", tx.Hash().Hex(), err)
+		return
+	}
+	m.tracked[tx.Hash()] = &pendingTx{tx: tx, from: from, seenAt: seenAt}
+	defer m.mu.Unlock()
+	m.matched.Add(1)
+
+	m.out.Note("[%s] PENDING %s from=%s to=%s nonce=%d value=%s tip=%s call=%s",
+		seenAt.Format(time.RFC3339),
+		tx.Hash().Hex(),
+		from.Hex(),
+		toString(tx.To()),
+		tx.Nonce(),
+		tx.Value(),
+		ethutil.FormatGwei(tx.GasTipCap()),
+		describeCall(tx.Data()),
+	)
+	m.record("pending", "time", seenAt.Format(time.RFC3339), "hash", tx.Hash(), "from", from, "to", tx.To(),
+		"nonce", tx.Nonce(), "value", tx.Value(), "tip", tx.GasTipCap(), "call", describeCall(tx.Data()))
+}
+
+// handleHead 在新区块到达时检查关注的交易是否被打包，并清理过期交易
+func (m *monitor) handleHead(ctx context.Context, h *types.Header) {
+	m.mu.Lock()
+	empty := len(m.tracked) == 0
+	m.mu.Unlock()
+	if empty {
+		return
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	block, err := m.client.BlockByHash(fetchCtx, h.Hash())
+	if err != nil {
+		log.Printf("[WARN] fetch block %d failed: %v", h.Number.Uint64(), err)
+		return
+	}
+	blockTime := time.Unix(int64(block.Time()), 0)
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tx := range block.Transactions() {
+		p, ok := m.tracked[tx.Hash()]
+		if !ok {
+			continue
+		}
+		delete(m.tracked, tx.Hash())
+		latency := now.Sub(p.seenAt)
+		m.included.Add(1)
+		m.latency.Add(latency.Milliseconds())
+		m.out.Note("[%s] MINED   %s block=%d latency=%s (block time - seen: %s)",
+			now.Format(time.RFC3339), tx.Hash().Hex(), block.NumberU64(),
+			latency.Round(time.Millisecond), blockTime.Sub(p.seenAt).Round(time.Second))
+		m.record("mined", "time", now.Format(time.RFC3339), "hash", tx.Hash(), "block", block.NumberU64(),
+			"latency_ms", latency.Milliseconds())
+	}
+
+	// 长时间未打包：可能被同 nonce 的交易替换、因 gas 过低被丢弃，或者被私有通道打包而我们错过了区块
+	for hash, p := range m.tracked {
+		if now.Sub(p.seenAt) > m.maxAge {
+			delete(m.tracked, hash)
+			m.expired.Add(1)
+			m.out.Note("[%s] EXPIRED %s from=%s nonce=%d (not mined within %s)",
+				now.Format(time.RFC3339), hash.Hex(), p.from.Hex(), p.tx.Nonce(), m.maxAge)
+			m.record("expired", "time", now.Format(time.RFC3339), "hash", hash, "from", p.from, "nonce", p.tx.Nonce())
+		}
+	}
+}
+
+// record 在结构化格式下输出一条事件记录，调用方需持有 m.mu
+func (m *monitor) record(kind string, kv ...any) {
+	if err := m.out.Record(kind, kv...); err != nil {
+		log.Printf("[WARN] write record failed: %v", err)
+	}
+}
+
+// logStats 输出统计信息
+func (m *monitor) logStats() {
+	m.mu.Lock()
+	tracking := len(m.tracked)
+	m.mu.Unlock()
+
+	avg := time.Duration(0)
+	if n := m.included.Load(); n > 0 {
+		avg = time.Duration(m.latency.Load()/n) * time.Millisecond
+	}
+	log.Printf("[STATS] seen=%d matched=%d tracking=%d included=%d expired=%d skipped=%d avg_latency=%s",
+		m.seen.Load(), m.matched.Load(), tracking, m.included.Load(), m.expired.Load(), m.dropped.Load(), avg)
+}
+
+// describeCall 解码调用数据：已知的 ERC-20 方法输出参数，否则只输出方法选择器
+func describeCall(data []byte) string {
+	if len(data) == 0 {
+		return "(plain transfer)"
+	}
+	if len(data) < 4 {
+		return fmt.Sprintf("(invalid calldata, %d bytes)", len(data))
+	}
+	method, err := erc20ABI.MethodById(data[:4])
+	if err != nil {
+		return fmt.Sprintf("selector 0x%x (%d bytes)", data[:4], len(data))
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return fmt.Sprintf("%s (decode failed: %v)", method.Name, err)
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case common.Address:
+			parts[i] = method.Inputs[i].Name + "=" + v.Hex()
+		default:
+			parts[i] = fmt.Sprintf("%s=%v", method.Inputs[i].Name, v)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", method.Name, strings.Join(parts, ", "))
+}
+
+func toString(to *common.Address) string {
+	if to == nil {
+		return "(contract creation)"
+	}
+	return to.Hex()
+}
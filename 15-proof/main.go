@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 15-proof
+// 通过 eth_getProof 获取账户与存储证明，并在本地用区块头中的状态根校验：
+// - 不需要信任 RPC 服务商返回的余额 / nonce / 存储值，只需要信任区块头（状态根）
+//   （区块头本身可以通过轻客户端、多个独立节点交叉比对或 beacon 链的同步委员会获得）
+// - 依次校验：账户证明 -> 账户字段（nonce、余额、storageHash、codeHash）-> 各存储槽位证明
+// - 校验通过后运行反例：篡改余额、篡改证明节点、使用错误状态根、篡改存储值，确认都会被拒绝
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://mainnet.infura.io/v3/<project-id>"
+//    # USDC 合约，槽位 0 和 1
+//    go run . --address 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48 --slots 0,1
+//    go run . --address 0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045 --block 19000000
+//    go run . --address 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48 --slots 0 --format json
+//
+// 注意事项：
+// - 查询历史区块需要节点保留历史状态（归档节点，或 path-based 存储下最近约 128 个区块）
+// - 槽位可以是十进制或 0x 开头的十六进制；mapping 等派生槽位需要先自行计算 keccak256
+
+func main() {
+	addrHex := flag.String("address", "", "account address (required)")
+	slotsFlag := flag.String("slots", "", "comma separated storage slots (decimal or 0x hex)")
+	blockNum := flag.Int64("block", -1, "block number to prove against (default: latest)")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if !common.IsHexAddress(*addrHex) {
+		log.Fatal("missing or invalid --address")
+	}
+	address := common.HexToAddress(*addrHex)
+	slots, err := parseSlots(*slotsFlag)
+	if err != nil {
+		log.Fatalf("invalid --slots: %v", err)
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(30*time.Second))
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	// 先确定区块头，再针对同一个区块号请求证明，避免 latest 在两次请求之间前进
+	var number *big.Int
+	if *blockNum >= 0 {
+		number = big.NewInt(*blockNum)
+	}
+	header, err := client.HeaderByNumber(ctx, number)
+	if err != nil {
+		log.Fatalf("failed to get header: %v", err)
+	}
+
+	keys := make([]string, len(slots))
+	for i, slot := range slots {
+		keys[i] = slot.Hex()
+	}
+	var res accountResult
+	if err := client.Client().CallContext(ctx, &res, "eth_getProof", address, keys, hexutil.EncodeBig(header.Number)); err != nil {
+		log.Fatalf("failed to get proof: %v", err)
+	}
+
+	out.Section("Proof")
+	out.Field("Block", header.Number)
+	out.Field("Block Hash", header.Hash())
+	out.Field("State Root", header.Root)
+	out.Field("Address", res.Address)
+	out.Field("Nonce", uint64(res.Nonce))
+	out.Field("Balance", res.Balance.ToInt())
+	out.Field("Storage Hash", res.StorageHash)
+	out.Field("Code Hash", res.CodeHash)
+	out.Field("Proof Nodes", len(res.AccountProof))
+
+	out.Table("Verification", "Proof", "Result", "Value")
+	if err := verifyAccount(header.Root, &res); err != nil {
+		log.Fatalf("account proof REJECTED: %v", err)
+	}
+	out.Row("account", "OK", "")
+	for i := range res.StorageProof {
+		sp := &res.StorageProof[i]
+		if err := verifyStorage(res.StorageHash, sp); err != nil {
+			log.Fatalf("storage proof REJECTED: %v", err)
+		}
+		out.Row("slot "+sp.Key.Hex(), "OK", sp.Value.ToInt())
+	}
+
+	out.Table("Tampered Proofs (must be rejected)", "Tamper", "Result")
+	runNegativeChecks(out, header.Root, &res)
+
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runNegativeChecks 对一份合法证明做各种篡改，确认校验逻辑会拒绝它们
+func runNegativeChecks(out *output.Printer, stateRoot common.Hash, res *accountResult) {
+	check := func(name string, err error) {
+		if err == nil {
+			log.Fatalf("%-28s: ACCEPTED (verification is broken!)", name)
+		}
+		out.Row(name, fmt.Sprintf("rejected (%v)", err))
+	}
+
+	// 1. 节点谎报余额
+	tampered := cloneResult(res)
+	tampered.Balance = (*hexutil.Big)(new(big.Int).Add(res.Balance.ToInt(), big.NewInt(1)))
+	check("balance +1 wei", verifyAccount(stateRoot, tampered))
+
+	// 2. 节点谎报 nonce
+	tampered = cloneResult(res)
+	tampered.Nonce++
+	check("nonce +1", verifyAccount(stateRoot, tampered))
+
+	// 3. 修改证明中最后一个节点的一个字节：它的哈希变了，父节点中的引用对不上
+	tampered = cloneResult(res)
+	last := tampered.AccountProof[len(tampered.AccountProof)-1]
+	last[len(last)-1] ^= 0xff
+	check("flipped byte in proof node", verifyAccount(stateRoot, tampered))
+
+	// 4. 去掉根节点
+	tampered = cloneResult(res)
+	tampered.AccountProof = tampered.AccountProof[1:]
+	check("missing root node", verifyAccount(stateRoot, tampered))
+
+	// 5. 用错误的状态根（例如来自另一条分叉的区块头）
+	wrongRoot := stateRoot
+	wrongRoot[0] ^= 0x01
+	check("wrong state root", verifyAccount(wrongRoot, res))
+
+	// 6. 节点谎报存储值
+	if len(res.StorageProof) > 0 {
+		tampered = cloneResult(res)
+		sp := &tampered.StorageProof[0]
+		sp.Value = (*hexutil.Big)(new(big.Int).Add(sp.Value.ToInt(), big.NewInt(1)))
+		check("storage value +1", verifyStorage(tampered.StorageHash, sp))
+
+		// 7. 拿另一个槽位的证明冒充
+		tampered = cloneResult(res)
+		sp = &tampered.StorageProof[0]
+		sp.Key[31] ^= 0x01
+		check("proof for a different slot", verifyStorage(tampered.StorageHash, sp))
+	}
+}
+
+// cloneResult 深拷贝证明结果，避免篡改影响原始数据
+func cloneResult(res *accountResult) *accountResult {
+	c := *res
+	c.Balance = (*hexutil.Big)(new(big.Int).Set(res.Balance.ToInt()))
+	c.AccountProof = cloneProof(res.AccountProof)
+	c.StorageProof = make([]storageResult, len(res.StorageProof))
+	for i, sp := range res.StorageProof {
+		c.StorageProof[i] = storageResult{
+			Key:   sp.Key,
+			Value: (*hexutil.Big)(new(big.Int).Set(sp.Value.ToInt())),
+			Proof: cloneProof(sp.Proof),
+		}
+	}
+	return &c
+}
+
+func cloneProof(proof []hexutil.Bytes) []hexutil.Bytes {
+	out := make([]hexutil.Bytes, len(proof))
+	for i, node := range proof {
+		out[i] = common.CopyBytes(node)
+	}
+	return out
+}
+
+// parseSlots 解析逗号分隔的存储槽位
+func parseSlots(s string) ([]common.Hash, error) {
+	var slots []common.Hash
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		v, ok := new(big.Int).SetString(item, 0)
+		if !ok || v.Sign() < 0 || v.BitLen() > 256 {
+			return nil, fmt.Errorf("bad slot %q", item)
+		}
+		slots = append(slots, common.BigToHash(v))
+	}
+	return slots, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 16-trace
+// debug / trace API 示例：通过 debug_traceTransaction 和 debug_traceBlockByNumber 重放交易，
+// 使用节点内置的两个 tracer：
+// - callTracer：输出内部调用树（CALL / DELEGATECALL / STATICCALL / CREATE ...），包含 gas、value、revert 原因
+// - prestateTracer（diffMode）：输出交易前后发生变化的账户状态（余额、nonce、代码、存储）
+// 渲染为便于阅读的树形 / 差异格式，并可通过 --out 导出原始 JSON 供其他工具分析
+//
+// 执行示例：
+//    export ETH_RPC_URL="http://127.0.0.1:8545"
+//    go run . --tx 0x<hash>
+//    go run . --tx 0x<hash> --tracer prestate
+//    go run . --block 19000000 --tracer call --out block.json
+//    go run . --tx 0x<hash> --tracer prestate --format csv    # 输出格式：pretty（默认）| json | yaml | csv
+//
+// 注意事项：
+// - 需要节点开启 debug 命名空间（geth: --http.api eth,debug），大部分公共 RPC 不提供
+// - 追踪需要重放交易，依赖历史状态：较老的区块需要归档节点
+// - 大区块追踪耗时较长，可通过 --timeout 调整节点端超时
+
+func main() {
+	txHex := flag.String("tx", "", "transaction hash to trace")
+	blockNum := flag.Int64("block", -1, "block number to trace (all transactions)")
+	tracer := flag.String("tracer", "call", "tracer: call | prestate")
+	outFile := flag.String("out", "", "write raw trace JSON to this file")
+	timeout := flag.Duration("timeout", time.Minute, "trace timeout (passed to the node and used for the request)")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if (*txHex == "") == (*blockNum < 0) {
+		log.Fatal("exactly one of --tx or --block is required")
+	}
+
+	tracerCfg, err := tracerConfig(*tracer, *timeout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout+10*time.Second)
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+	// ethclient 没有封装 debug 命名空间，直接通过底层 rpc.Client 调用
+	rpcClient := client.Client()
+
+	var raw json.RawMessage
+	if *txHex != "" {
+		raw, err = traceTransaction(ctx, rpcClient, common.HexToHash(*txHex), tracerCfg)
+	} else {
+		raw, err = traceBlock(ctx, rpcClient, big.NewInt(*blockNum), tracerCfg)
+	}
+	if err != nil {
+		log.Fatalf("failed to trace: %v", err)
+	}
+
+	if *outFile != "" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, raw, "", "  "); err != nil {
+			log.Fatalf("failed to format trace json: %v", err)
+		}
+		if err := os.WriteFile(*outFile, buf.Bytes(), 0o644); err != nil {
+			log.Fatalf("failed to write %s: %v", *outFile, err)
+		}
+		out.Note("raw trace written to %s", *outFile)
+	}
+
+	if *txHex != "" {
+		out.Section("Trace")
+		out.Field("Transaction", common.HexToHash(*txHex))
+		out.Field("Tracer", *tracer)
+		if err := render(out, *tracer, raw); err != nil {
+			log.Fatalf("failed to render trace: %v", err)
+		}
+	} else {
+		var results []blockTraceResult
+		if err := json.Unmarshal(raw, &results); err != nil {
+			log.Fatalf("failed to decode block trace: %v", err)
+		}
+		out.Section("Block")
+		out.Field("Number", *blockNum)
+		out.Field("Transactions", len(results))
+		out.Field("Tracer", *tracer)
+		// 结构化格式下 Transaction 与 Calls / State Diff 都是数组，按下标一一对应
+		for i, r := range results {
+			out.Section("Transaction")
+			out.Field("Index", i)
+			out.Field("Hash", r.TxHash)
+			if r.Error != "" {
+				out.Field("Error", r.Error)
+				r.Result = nil
+			}
+			if err := render(out, *tracer, r.Result); err != nil {
+				log.Printf("[WARN] render tx %s failed: %v", r.TxHash.Hex(), err)
+			}
+		}
+	}
+
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// blockTraceResult debug_traceBlockByNumber 返回的单笔交易结果
+type blockTraceResult struct {
+	TxHash common.Hash     `json:"txHash"`
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// tracerConfig 构造 debug_trace* 的 TraceConfig 参数
+func tracerConfig(tracer string, timeout time.Duration) (map[string]interface{}, error) {
+	config := map[string]interface{}{"timeout": timeout.String()}
+	switch tracer {
+	case "call":
+		config["tracer"] = "callTracer"
+		config["tracerConfig"] = map[string]interface{}{"withLog": false}
+	case "prestate":
+		// diffMode 只返回发生变化的账户，分为 pre / post 两部分
+		config["tracer"] = "prestateTracer"
+		config["tracerConfig"] = map[string]interface{}{"diffMode": true}
+	default:
+		return nil, fmt.Errorf("unknown tracer %q (call | prestate)", tracer)
+	}
+	return config, nil
+}
+
+// traceTransaction 调用 debug_traceTransaction，返回原始 JSON
+func traceTransaction(ctx context.Context, c *rpc.Client, hash common.Hash, config map[string]interface{}) (json.RawMessage, error) {
+	var raw json.RawMessage
+	if err := c.CallContext(ctx, &raw, "debug_traceTransaction", hash, config); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// traceBlock 调用 debug_traceBlockByNumber，返回原始 JSON
+func traceBlock(ctx context.Context, c *rpc.Client, number *big.Int, config map[string]interface{}) (json.RawMessage, error) {
+	var raw json.RawMessage
+	if err := c.CallContext(ctx, &raw, "debug_traceBlockByNumber", hexutil.EncodeBig(number), config); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
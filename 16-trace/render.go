@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// callFrame callTracer 输出的调用帧（字段与 geth callTracer 一致，只保留需要的部分）
+type callFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to"`
+	Value        *hexutil.Big    `json:"value"`
+	Gas          hexutil.Uint64  `json:"gas"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       hexutil.Bytes   `json:"output"`
+	Error        string          `json:"error"`
+	RevertReason string          `json:"revertReason"`
+	Calls        []callFrame     `json:"calls"`
+}
+
+// prestateAccount prestateTracer 输出的账户状态，未变化的字段在 diffMode 下会被省略
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   *uint64                     `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// stateDiff prestateTracer diffMode 的输出
+type stateDiff struct {
+	Pre  map[common.Address]*prestateAccount `json:"pre"`
+	Post map[common.Address]*prestateAccount `json:"post"`
+}
+
+// render 按 tracer 类型渲染单笔交易的追踪结果。raw 为空（追踪失败）时只输出表头，
+// 使区块模式下每笔交易都对应一个表格
+func render(out *output.Printer, tracer string, raw json.RawMessage) error {
+	switch tracer {
+	case "call":
+		out.Table("Calls", "Type", "Depth", "From", "To", "Gas", "Gas Used", "Value", "Selector", "Error")
+		if len(raw) == 0 {
+			return nil
+		}
+		var frame callFrame
+		if err := json.Unmarshal(raw, &frame); err != nil {
+			return fmt.Errorf("decode call frame: %w", err)
+		}
+		printCallTree(out, &frame, "", 0, true, true)
+	case "prestate":
+		out.Table("State Diff", "Account", "Field", "Before", "After", "Delta")
+		if len(raw) == 0 {
+			return nil
+		}
+		var diff stateDiff
+		if err := json.Unmarshal(raw, &diff); err != nil {
+			return fmt.Errorf("decode state diff: %w", err)
+		}
+		printStateDiff(out, &diff)
+	}
+	return nil
+}
+
+// printCallTree 每个调用帧输出一行，pretty 模式下 Type 列带树形前缀
+func printCallTree(out *output.Printer, f *callFrame, prefix string, depth int, last, root bool) {
+	branch, childPrefix := "├─ ", prefix+"│  "
+	if last {
+		branch, childPrefix = "└─ ", prefix+"   "
+	}
+	if root {
+		branch, childPrefix = "", ""
+	}
+
+	typ := f.Type
+	if out.Pretty() {
+		typ = prefix + branch + f.Type
+	}
+	to := "(create)"
+	if f.To != nil {
+		to = f.To.Hex()
+	}
+	value := ""
+	if f.Value != nil && f.Value.ToInt().Sign() > 0 {
+		value = f.Value.ToInt().String()
+	}
+	selector := ""
+	if len(f.Input) >= 4 {
+		selector = fmt.Sprintf("%s (%d bytes)", hexutil.Encode(f.Input[:4]), len(f.Input))
+	}
+	errMsg := f.Error
+	if f.Error != "" && f.RevertReason != "" {
+		errMsg += fmt.Sprintf(" (%s)", f.RevertReason)
+	}
+	out.Row(typ, depth, f.From, to, uint64(f.Gas), uint64(f.GasUsed), value, selector, errMsg)
+
+	for i := range f.Calls {
+		printCallTree(out, &f.Calls[i], childPrefix, depth+1, i == len(f.Calls)-1, false)
+	}
+}
+
+// printStateDiff 按账户输出交易前后的状态变化，每个变化的字段一行
+func printStateDiff(out *output.Printer, d *stateDiff) {
+	addrs := make(map[common.Address]bool)
+	for a := range d.Pre {
+		addrs[a] = true
+	}
+	for a := range d.Post {
+		addrs[a] = true
+	}
+	sorted := make([]common.Address, 0, len(addrs))
+	for a := range addrs {
+		sorted = append(sorted, a)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	for _, addr := range sorted {
+		pre, post := d.Pre[addr], d.Post[addr]
+		switch {
+		case pre == nil:
+			out.Row(addr, "account", "", "created", "")
+			pre = &prestateAccount{}
+		case post == nil:
+			// 账户在 post 中完全消失：被 SELFDESTRUCT 删除
+			out.Row(addr, "account", "", "deleted", "")
+			continue
+		}
+
+		if post.Balance != nil {
+			out.Row(addr, "balance", bigString(pre.Balance), bigString(post.Balance), balanceDelta(pre.Balance, post.Balance))
+		}
+		if post.Nonce != nil {
+			out.Row(addr, "nonce", nonceString(pre.Nonce), fmt.Sprint(*post.Nonce), "")
+		}
+		if post.Code != nil {
+			out.Row(addr, "code", fmt.Sprintf("%d bytes", len(pre.Code)), fmt.Sprintf("%d bytes", len(post.Code)), "")
+		}
+
+		slots := make(map[common.Hash]bool)
+		for k := range pre.Storage {
+			slots[k] = true
+		}
+		for k := range post.Storage {
+			slots[k] = true
+		}
+		keys := make([]string, 0, len(slots))
+		for k := range slots {
+			keys = append(keys, k.Hex())
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			slot := common.HexToHash(k)
+			// diffMode 下被清零的槽位不会出现在 post 中
+			out.Row(addr, "storage "+k, pre.Storage[slot], post.Storage[slot], "")
+		}
+	}
+}
+
+func bigString(v *hexutil.Big) string {
+	if v == nil {
+		return "0"
+	}
+	return v.ToInt().String()
+}
+
+func nonceString(n *uint64) string {
+	if n == nil {
+		return "0"
+	}
+	return fmt.Sprint(*n)
+}
+
+// balanceDelta 计算余额变化，带正负号
+func balanceDelta(pre, post *hexutil.Big) string {
+	a, b := new(big.Int), new(big.Int)
+	if pre != nil {
+		a = pre.ToInt()
+	}
+	if post != nil {
+		b = post.ToInt()
+	}
+	delta := new(big.Int).Sub(b, a)
+	if delta.Sign() > 0 {
+		return "+" + delta.String()
+	}
+	return delta.String()
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/decimal"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 17-flashbots
+// 通过 Flashbots relay 私有提交交易（bundle），交易不进入公开交易池，避免被抢跑 / 三明治攻击：
+// - 构造并签名一笔 EIP-1559 转账（与 03-tx-ops 相同），放入 bundle
+// - 可选先用 eth_callBundle 模拟
+// - eth_sendBundle：bundle 只对指定区块有效，因此对接下来的 N 个区块各提交一次
+//   mev_sendBundle：一次提交，通过 inclusion.block / maxBlock 指定有效区块区间
+// - 每个请求带 X-Flashbots-Signature 签名头（见 relay.go）
+// - 轮询新区块，检查交易回执确认是否被打包；超过目标区块仍未打包则视为失败（交易不会上链，也不花费 gas）
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://sepolia.infura.io/v3/<project-id>"
+//    export SENDER_PRIVATE_KEY="<hex private key>"
+//    export FLASHBOTS_SIGNING_KEY="<hex private key>"   # 可选，未设置时使用临时密钥
+//    go run . --relay https://relay-sepolia.flashbots.net --to 0x... --amount 0.001 --simulate
+//    go run . --method mev --blocks 10 --to 0x... --amount 0.001
+//    go run . --to 0x... --amount 0.001 --format json   # 输出格式：pretty（默认）| json | yaml | csv
+//
+// 注意事项：
+// - relay 默认为主网地址，测试网请通过 --relay 指定对应 relay
+// - builder 只会打包对自己有利的 bundle，优先费（--tip-gwei）过低时 bundle 会一直不被打包
+// - 签名密钥只用于身份识别，不要使用持有资金的私钥
+
+const defaultRelay = "https://relay.flashbots.net"
+
+func main() {
+	relayURL := flag.String("relay", defaultRelay, "Flashbots relay URL")
+	toAddrHex := flag.String("to", "", "recipient address (required)")
+	amountEth := flag.String("amount", "", "amount in ETH (required)")
+	tipGwei := flag.String("tip-gwei", "2", "priority fee in gwei paid to the builder")
+	blocks := flag.Uint64("blocks", 5, "number of future blocks to target")
+	method := flag.String("method", "eth", "bundle method: eth (eth_sendBundle) | mev (mev_sendBundle)")
+	simulate := flag.Bool("simulate", false, "simulate the bundle with eth_callBundle before sending")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if !common.IsHexAddress(*toAddrHex) || *amountEth == "" {
+		log.Fatal("--to and --amount are required")
+	}
+	// 金额和小费按十进制精确换算为 wei，不经过 float64
+	valueWei, err := decimal.ParseEther(*amountEth)
+	if err != nil {
+		log.Fatalf("invalid --amount: %v", err)
+	}
+	if valueWei.Sign() <= 0 {
+		log.Fatal("--amount must be positive")
+	}
+	tipCap, err := decimal.ParseGwei(*tipGwei)
+	if err != nil {
+		log.Fatalf("invalid --tip-gwei: %v", err)
+	}
+	if *method != "eth" && *method != "mev" {
+		log.Fatalf("unknown --method %q (eth | mev)", *method)
+	}
+	if *blocks == 0 {
+		log.Fatal("--blocks must be positive")
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+	privKeyHex := config.Get().PrivateKey
+	if privKeyHex == "" {
+		log.Fatal("SENDER_PRIVATE_KEY is not set")
+	}
+	privKey, err := crypto.HexToECDSA(strings.TrimPrefix(privKeyHex, "0x"))
+	if err != nil {
+		log.Fatalf("invalid private key: %v", err)
+	}
+	signingKey := loadSigningKey()
+
+	// 需要等待多个区块，整体超时按每个区块 15 秒估算
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*blocks+4)*15*time.Second)
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	signedTx, err := buildTransfer(ctx, client, privKey, common.HexToAddress(*toAddrHex), valueWei, tipCap)
+	if err != nil {
+		log.Fatalf("failed to build transaction: %v", err)
+	}
+	rawTx, err := signedTx.MarshalBinary()
+	if err != nil {
+		log.Fatalf("failed to encode transaction: %v", err)
+	}
+	rawTxs := []hexutil.Bytes{rawTx}
+
+	current, err := client.BlockNumber(ctx)
+	if err != nil {
+		log.Fatalf("failed to get block number: %v", err)
+	}
+	first, last := current+1, current+*blocks
+
+	relay := newRelayClient(*relayURL, signingKey)
+	out.Section("Flashbots Bundle")
+	out.Field("Relay", *relayURL)
+	out.Field("Signer", crypto.PubkeyToAddress(signingKey.PublicKey))
+	out.Field("Tx Hash", signedTx.Hash())
+	out.Field("Nonce", signedTx.Nonce())
+	out.Field("Tip Cap", signedTx.GasTipCap())
+	out.Field("Fee Cap", signedTx.GasFeeCap())
+	out.Field("First Block", first)
+	out.Field("Last Block", last)
+
+	if *simulate {
+		result, err := relay.callBundle(ctx, rawTxs, first, current)
+		if err != nil {
+			log.Fatalf("bundle simulation failed: %v", err)
+		}
+		// 结构化格式下直接嵌入模拟结果的 JSON
+		var simulation any = result
+		if out.Pretty() {
+			simulation = string(result)
+		}
+		out.Field("Simulation", simulation)
+	}
+
+	out.Table("Bundles", "Bundle Hash", "Block")
+	switch *method {
+	case "eth":
+		for block := first; block <= last; block++ {
+			bundleHash, err := relay.sendBundle(ctx, rawTxs, block)
+			if err != nil {
+				log.Fatalf("failed to send bundle for block %d: %v", block, err)
+			}
+			out.Row(bundleHash, block)
+		}
+	case "mev":
+		bundleHash, err := relay.mevSendBundle(ctx, rawTxs, first, last)
+		if err != nil {
+			log.Fatalf("failed to send bundle: %v", err)
+		}
+		out.Row(bundleHash, fmt.Sprintf("%d - %d", first, last))
+	}
+
+	receipt, err := waitInclusion(ctx, client, out, signedTx.Hash(), last)
+	if err != nil {
+		log.Fatalf("bundle not included: %v", err)
+	}
+	out.Section("Included")
+	out.Field("Block", receipt.BlockNumber)
+	out.Field("Status", receipt.Status)
+	out.Field("Gas Used", receipt.GasUsed)
+
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadSigningKey 读取 FLASHBOTS_SIGNING_KEY，未设置时生成一个临时密钥（每次运行身份不同，无法积累信誉）
+func loadSigningKey() *ecdsa.PrivateKey {
+	if keyHex := os.Getenv("FLASHBOTS_SIGNING_KEY"); keyHex != "" {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(keyHex, "0x"))
+		if err != nil {
+			log.Fatalf("invalid FLASHBOTS_SIGNING_KEY: %v", err)
+		}
+		return key
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		log.Fatalf("failed to generate signing key: %v", err)
+	}
+	log.Printf("[WARN] FLASHBOTS_SIGNING_KEY is not set, using an ephemeral signing key")
+	return key
+}
+
+// buildTransfer 构造并签名一笔 EIP-1559 转账
+func buildTransfer(ctx context.Context, client *ethclient.Client, privKey *ecdsa.PrivateKey, to common.Address, valueWei, tipCap *big.Int) (*types.Transaction, error) {
+	from := crypto.PubkeyToAddress(privKey.PublicKey)
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get chain id: %w", err)
+	}
+	// 私有交易不在公开交易池中，使用 latest 而不是 pending 的 nonce，避免被公开交易池中的交易影响
+	nonce, err := client.NonceAt(ctx, from, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get nonce: %w", err)
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get header: %w", err)
+	}
+	if header.BaseFee == nil {
+		return nil, errors.New("chain does not support EIP-1559")
+	}
+
+	// bundle 要在接下来多个区块内都有效：base fee 每个区块最多上涨 12.5%，这里留 2 倍余量
+	gasFeeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), tipCap)
+	gasLimit := uint64(21000)
+
+	balance, err := client.BalanceAt(ctx, from, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get balance: %w", err)
+	}
+	totalCost := new(big.Int).Add(valueWei, new(big.Int).Mul(gasFeeCap, new(big.Int).SetUint64(gasLimit)))
+	if balance.Cmp(totalCost) < 0 {
+		return nil, fmt.Errorf("insufficient balance: have %s wei, need %s wei", balance, totalCost)
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     valueWei,
+	})
+	return types.SignTx(tx, types.NewLondonSigner(chainID), privKey)
+}
+
+// waitInclusion 每个区块检查一次交易回执，直到被打包或超过最后一个目标区块
+func waitInclusion(ctx context.Context, client *ethclient.Client, out *output.Printer, hash common.Hash, lastBlock uint64) (*types.Receipt, error) {
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+
+	var lastSeen uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		receipt, err := client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			log.Printf("[WARN] get receipt failed: %v", err)
+		}
+
+		current, err := client.BlockNumber(ctx)
+		if err != nil {
+			log.Printf("[WARN] get block number failed: %v", err)
+			continue
+		}
+		if current != lastSeen {
+			lastSeen = current
+			out.Note("block %d: not included yet", current)
+		}
+		if current >= lastBlock {
+			return nil, fmt.Errorf("not included in target blocks (last target %d); the bundle has expired", lastBlock)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 18-l2-fees
+// L2 交易费用计算（OP Stack / Arbitrum）：
+// 03-tx-ops 中的费用 = gasLimit * gasFeeCap 只适用于 L1。L2 交易还要为把交易数据发布到 L1 付费：
+// - OP Stack（Optimism、Base 等）：总费用 = L2 执行费（gasUsed * gasPrice）+ L1 数据费，
+//   L1 数据费由预部署合约 GasPriceOracle（0x42..0F）的 getL1Fee(交易 RLP) 计算，单独从余额扣除
+// - Arbitrum：L1 数据费折算成额外的 L2 gas，由虚拟合约 NodeInterface（0x..C8）的 gasEstimateL1Component 估算，
+//   总费用 = (L2 执行 gas + L1 折算 gas) * baseFee（Arbitrum 忽略优先费）
+// 功能：
+// - 自动识别链类型（GasPriceOracle 有代码 -> OP Stack；ArbSys.arbChainID() 调用成功 -> Arbitrum）
+// - estimate 模式：估算一笔交易的完整费用并给出分项
+// - receipt 模式：输出已上链交易回执中 L2 特有的字段（OP: l1Fee / l1GasUsed / 存款交易的 depositNonce 等；
+//   Arbitrum: gasUsedForL1 / l1BlockNumber）
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://mainnet.optimism.io"
+//    go run . --mode estimate --from 0x... --to 0x... --amount 0.01
+//    go run . --mode estimate --from 0x... --to 0x<token> --data 0xa9059cbb...
+//    go run . --mode receipt --tx 0x<hash>
+//    go run . --mode receipt --tx 0x<hash> --format json   # 输出格式：pretty（默认）| json | yaml | csv
+//
+// 注意事项：
+// - 估算需要 --from 有足够余额，否则 eth_estimateGas 会因余额不足失败
+// - L1 数据费随 L1 gas 价格波动，估算值只在当前时刻有效
+
+const (
+	// gasPriceOracleAddr OP Stack GasPriceOracle 预部署合约地址
+	gasPriceOracleAddr = "0x420000000000000000000000000000000000000F"
+	// arbSysAddr Arbitrum ArbSys 预编译合约地址
+	arbSysAddr = "0x0000000000000000000000000000000000000064"
+	// nodeInterfaceAddr Arbitrum NodeInterface 虚拟合约地址（只能通过 eth_call / eth_estimateGas 调用）
+	nodeInterfaceAddr = "0x00000000000000000000000000000000000000C8"
+)
+
+// chainKind 链类型
+type chainKind string
+
+const (
+	chainL1       chainKind = "l1"
+	chainOPStack  chainKind = "op-stack"
+	chainArbitrum chainKind = "arbitrum"
+)
+
+// feeEstimate 费用分项
+type feeEstimate struct {
+	L2Gas      uint64   // L2 执行 gas
+	L2GasPrice *big.Int // L2 有效 gas 价格
+	L2Fee      *big.Int // L2 执行费
+	L1Gas      uint64   // Arbitrum：L1 数据费折算的 L2 gas
+	L1Fee      *big.Int // L1 数据费
+	Total      *big.Int
+}
+
+func main() {
+	mode := flag.String("mode", "estimate", "mode: estimate | receipt")
+	fromHex := flag.String("from", "", "sender address (estimate mode)")
+	toHex := flag.String("to", "", "recipient or contract address (estimate mode)")
+	amountEth := flag.Float64("amount", 0, "value in ETH (estimate mode)")
+	dataHex := flag.String("data", "", "calldata hex (estimate mode)")
+	txHex := flag.String("tx", "", "transaction hash (receipt mode)")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(30*time.Second))
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatalf("failed to get chain id: %v", err)
+	}
+	kind, err := detectChain(ctx, client)
+	if err != nil {
+		log.Fatalf("failed to detect chain type: %v", err)
+	}
+	out.Section("Chain")
+	out.Field("Chain ID", chainID)
+	out.Field("Chain Type", kind)
+
+	switch *mode {
+	case "estimate":
+		if !common.IsHexAddress(*toHex) {
+			log.Fatal("estimate mode requires --to")
+		}
+		data, err := hexutil.Decode(orEmptyHex(*dataHex))
+		if err != nil {
+			log.Fatalf("invalid --data: %v", err)
+		}
+		to := common.HexToAddress(*toHex)
+		valueWei, _ := new(big.Float).Mul(big.NewFloat(*amountEth), big.NewFloat(params.Ether)).Int(nil)
+		msg := ethereum.CallMsg{From: common.HexToAddress(*fromHex), To: &to, Value: valueWei, Data: data}
+
+		est, err := estimateFee(ctx, client, kind, chainID, msg)
+		if err != nil {
+			log.Fatalf("failed to estimate fee: %v", err)
+		}
+		printEstimate(out, kind, est)
+
+	case "receipt":
+		if *txHex == "" {
+			log.Fatal("receipt mode requires --tx")
+		}
+		if err := printReceipt(ctx, client, out, common.HexToHash(*txHex)); err != nil {
+			log.Fatalf("failed to get receipt: %v", err)
+		}
+
+	default:
+		log.Fatalf("unknown mode: %s", *mode)
+	}
+
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// detectChain 通过预部署 / 预编译合约识别链类型
+func detectChain(ctx context.Context, client *ethclient.Client) (chainKind, error) {
+	code, err := client.CodeAt(ctx, common.HexToAddress(gasPriceOracleAddr), nil)
+	if err != nil {
+		return "", err
+	}
+	if len(code) > 0 {
+		return chainOPStack, nil
+	}
+	// Arbitrum 预编译合约没有真正的字节码，只能通过调用是否成功判断
+	if _, err := callArbChainID(ctx, client); err == nil {
+		return chainArbitrum, nil
+	}
+	return chainL1, nil
+}
+
+// estimateFee 按链类型估算完整费用
+func estimateFee(ctx context.Context, client *ethclient.Client, kind chainKind, chainID *big.Int, msg ethereum.CallMsg) (*feeEstimate, error) {
+	l2Gas, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("estimate gas: %w", err)
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get header: %w", err)
+	}
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+
+	est := &feeEstimate{L2Gas: l2Gas, L1Fee: new(big.Int)}
+	switch kind {
+	case chainArbitrum:
+		// Arbitrum 不收优先费，实际价格就是 baseFee
+		l1Gas, err := arbL1Component(ctx, client, msg)
+		if err != nil {
+			return nil, err
+		}
+		// Arbitrum 的 eth_estimateGas 返回值已经包含 L1 折算 gas，拆分出纯 L2 执行部分
+		if l1Gas < l2Gas {
+			est.L2Gas = l2Gas - l1Gas
+		}
+		est.L1Gas = l1Gas
+		est.L2GasPrice = baseFee
+		est.L1Fee = new(big.Int).Mul(baseFee, new(big.Int).SetUint64(l1Gas))
+
+	default:
+		tip, err := client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get gas tip cap: %w", err)
+		}
+		est.L2GasPrice = new(big.Int).Add(baseFee, tip)
+		if kind == chainOPStack {
+			// getL1Fee 需要完整的交易 RLP，用实际参数构造一笔未签名交易（费用按数据大小计算，签名字段由合约补足）
+			nonce, err := client.PendingNonceAt(ctx, msg.From)
+			if err != nil {
+				return nil, fmt.Errorf("get nonce: %w", err)
+			}
+			tx := types.NewTx(&types.DynamicFeeTx{
+				ChainID:   chainID,
+				Nonce:     nonce,
+				GasTipCap: tip,
+				GasFeeCap: est.L2GasPrice,
+				Gas:       l2Gas,
+				To:        msg.To,
+				Value:     msg.Value,
+				Data:      msg.Data,
+			})
+			raw, err := tx.MarshalBinary()
+			if err != nil {
+				return nil, fmt.Errorf("encode tx: %w", err)
+			}
+			if est.L1Fee, err = opL1Fee(ctx, client, raw); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	est.L2Fee = new(big.Int).Mul(est.L2GasPrice, new(big.Int).SetUint64(est.L2Gas))
+	est.Total = new(big.Int).Add(est.L2Fee, est.L1Fee)
+	return est, nil
+}
+
+// printEstimate 输出费用分项
+func printEstimate(out *output.Printer, kind chainKind, est *feeEstimate) {
+	out.Section("Fee Estimate")
+	out.Field("L2 Gas", est.L2Gas)
+	out.Field("L2 GasPrice (wei)", est.L2GasPrice)
+	out.Field("L2 Fee (ETH)", ethutil.FormatEth(est.L2Fee, 9))
+	switch kind {
+	case chainOPStack:
+		// GasPriceOracle.getL1Fee
+		out.Field("L1 Data Fee (ETH)", ethutil.FormatEth(est.L1Fee, 9))
+	case chainArbitrum:
+		// NodeInterface.gasEstimateL1Component；发送交易时 gas limit 应使用 L2 + L1 部分之和
+		out.Field("L1 Gas", est.L1Gas)
+		out.Field("L1 Data Fee (ETH)", ethutil.FormatEth(est.L1Fee, 9))
+		out.Field("Gas Limit", est.L2Gas+est.L1Gas)
+	}
+	out.Field("Total (ETH)", ethutil.FormatEth(est.Total, 9))
+}
+
+// l2ReceiptFields L2 回执中特有的字段
+var l2ReceiptFields = []string{
+	// OP Stack
+	"l1Fee", "l1GasUsed", "l1GasPrice", "l1FeeScalar", "l1BaseFeeScalar", "l1BlobBaseFee", "l1BlobBaseFeeScalar",
+	"depositNonce", "depositReceiptVersion",
+	// Arbitrum
+	"gasUsedForL1", "l1BlockNumber",
+}
+
+// printReceipt 以原始 JSON 读取回执，输出标准字段和 L2 特有字段
+// （types.Receipt 会丢弃未知字段，也无法解码 OP 存款交易类型 0x7e）
+func printReceipt(ctx context.Context, client *ethclient.Client, out *output.Printer, hash common.Hash) error {
+	var receipt map[string]json.RawMessage
+	if err := client.Client().CallContext(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return err
+	}
+	if receipt == nil {
+		return ethereum.NotFound
+	}
+
+	out.Section("Receipt")
+	for _, k := range []string{"type", "status", "blockNumber", "gasUsed", "effectiveGasPrice"} {
+		out.Field(k, unquote(receipt[k]))
+	}
+
+	gasUsed := hexBig(receipt["gasUsed"])
+	price := hexBig(receipt["effectiveGasPrice"])
+	l2Fee := new(big.Int).Mul(gasUsed, price)
+	l1Fee := hexBig(receipt["l1Fee"])
+
+	var extra []string
+	for _, k := range l2ReceiptFields {
+		if _, ok := receipt[k]; ok {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+	if len(extra) > 0 {
+		out.Section("L2 Fields")
+		for _, k := range extra {
+			out.Field(k, unquote(receipt[k]))
+		}
+	}
+	if unquote(receipt["type"]) == "0x7e" {
+		// 存款交易由 L1 发起，gas 已在 L1 支付，L2 上不收费
+		out.Note("deposit transaction: fees were paid on L1")
+		return nil
+	}
+
+	out.Section("Fee")
+	out.Field("L2 Execution Fee (ETH)", ethutil.FormatEth(l2Fee, 9))
+	if l1Fee.Sign() > 0 {
+		out.Field("L1 Data Fee (ETH)", ethutil.FormatEth(l1Fee, 9))
+	}
+	// Arbitrum 的 gasUsed 已包含 gasUsedForL1，effectiveGasPrice * gasUsed 即为总费用
+	out.Field("Total (ETH)", ethutil.FormatEth(new(big.Int).Add(l2Fee, l1Fee), 9))
+	return nil
+}
+
+func unquote(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return string(raw)
+	}
+	return s
+}
+
+func hexBig(raw json.RawMessage) *big.Int {
+	v, err := hexutil.DecodeBig(unquote(raw))
+	if err != nil {
+		return new(big.Int)
+	}
+	return v
+}
+
+func orEmptyHex(s string) string {
+	if s == "" {
+		return "0x"
+	}
+	return s
+}
+
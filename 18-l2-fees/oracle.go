@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// L2 费用相关的系统合约 ABI（只包含用到的方法）
+const l2ABIJSON = `[
+  {
+    "inputs": [{"name": "_data", "type": "bytes"}],
+    "name": "getL1Fee",
+    "outputs": [{"name": "", "type": "uint256"}],
+    "stateMutability": "view",
+    "type": "function"
+  },
+  {
+    "inputs": [],
+    "name": "arbChainID",
+    "outputs": [{"name": "", "type": "uint256"}],
+    "stateMutability": "view",
+    "type": "function"
+  },
+  {
+    "inputs": [
+      {"name": "to", "type": "address"},
+      {"name": "contractCreation", "type": "bool"},
+      {"name": "data", "type": "bytes"}
+    ],
+    "name": "gasEstimateL1Component",
+    "outputs": [
+      {"name": "gasEstimateForL1", "type": "uint64"},
+      {"name": "baseFee", "type": "uint256"},
+      {"name": "l1BaseFeeEstimate", "type": "uint256"}
+    ],
+    "stateMutability": "payable",
+    "type": "function"
+  }
+]`
+
+var l2ABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(l2ABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// callL2 调用系统合约的方法并返回解码后的结果
+func callL2(ctx context.Context, client *ethclient.Client, contract, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := l2ABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s: %w", method, err)
+	}
+	to := common.HexToAddress(contract)
+	result, err := client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("call %s: %w", method, err)
+	}
+	values, err := l2ABI.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("unpack %s: %w", method, err)
+	}
+	return values, nil
+}
+
+// opL1Fee 调用 OP Stack GasPriceOracle.getL1Fee 计算 L1 数据费
+func opL1Fee(ctx context.Context, client *ethclient.Client, rawTx []byte) (*big.Int, error) {
+	values, err := callL2(ctx, client, gasPriceOracleAddr, "getL1Fee", rawTx)
+	if err != nil {
+		return nil, err
+	}
+	return values[0].(*big.Int), nil
+}
+
+// callArbChainID 调用 ArbSys.arbChainID，仅在 Arbitrum 上成功
+func callArbChainID(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+	values, err := callL2(ctx, client, arbSysAddr, "arbChainID")
+	if err != nil {
+		return nil, err
+	}
+	return values[0].(*big.Int), nil
+}
+
+// arbL1Component 调用 Arbitrum NodeInterface.gasEstimateL1Component，返回 L1 数据费折算的 L2 gas
+func arbL1Component(ctx context.Context, client *ethclient.Client, msg ethereum.CallMsg) (uint64, error) {
+	var to common.Address
+	if msg.To != nil {
+		to = *msg.To
+	}
+	values, err := callL2(ctx, client, nodeInterfaceAddr, "gasEstimateL1Component", to, msg.To == nil, msg.Data)
+	if err != nil {
+		return 0, err
+	}
+	return values[0].(uint64), nil
+}
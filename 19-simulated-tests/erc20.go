@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/ethutil"
+)
+
+// ERC-20 交互辅助函数，逻辑与 08-contract-interact 中的同名函数一致，
+// 区别是参数从 *ethclient.Client 换成了 go-ethereum 定义的接口（ethereum.ContractCaller 等）：
+// *ethclient.Client 和模拟链的 simulated.Client 都实现了这些接口，
+// 因此同一份代码既能连接真实节点运行，也能在单元测试里对模拟链运行，无需任何外部节点。
+
+// 与 08 相同的 ERC-20 ABI，额外加入 totalSupply 和测试合约的构造函数
+const erc20ABIJSON = `[
+  {
+    "inputs": [{"name": "supply", "type": "uint256"}],
+    "type": "constructor"
+  },
+  {
+    "constant": true,
+    "inputs": [{"name": "owner", "type": "address"}],
+    "name": "balanceOf",
+    "outputs": [{"name": "balance", "type": "uint256"}],
+    "type": "function"
+  },
+  {
+    "constant": true,
+    "inputs": [],
+    "name": "decimals",
+    "outputs": [{"name": "", "type": "uint8"}],
+    "type": "function"
+  },
+  {
+    "constant": true,
+    "inputs": [],
+    "name": "totalSupply",
+    "outputs": [{"name": "", "type": "uint256"}],
+    "type": "function"
+  },
+  {
+    "constant": false,
+    "inputs": [
+      {"name": "to", "type": "address"},
+      {"name": "value", "type": "uint256"}
+    ],
+    "name": "transfer",
+    "outputs": [{"name": "", "type": "bool"}],
+    "type": "function"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true, "name": "from", "type": "address"},
+      {"indexed": true, "name": "to", "type": "address"},
+      {"indexed": false, "name": "value", "type": "uint256"}
+    ],
+    "name": "Transfer",
+    "type": "event"
+  }
+]`
+
+// tokenBytecode 测试用最小 ERC-20 合约的部署字节码。
+// 为了不依赖 solc，字节码是手写汇编得到的，行为等价于下面的 Solidity：
+//
+//	contract Token {
+//	    uint256 public totalSupply;                        // slot 0
+//	    mapping(address => uint256) public balanceOf;      // slot 1
+//	    event Transfer(address indexed from, address indexed to, uint256 value);
+//
+//	    constructor(uint256 supply) {
+//	        totalSupply = supply;
+//	        balanceOf[msg.sender] = supply;
+//	        emit Transfer(address(0), msg.sender, supply);
+//	    }
+//	    function decimals() external pure returns (uint8) { return 18; }
+//	    function transfer(address to, uint256 value) external returns (bool) {
+//	        require(balanceOf[msg.sender] >= value);         // 余额不足时 revert，无错误信息
+//	        balanceOf[msg.sender] -= value;
+//	        balanceOf[to] += value;
+//	        emit Transfer(msg.sender, to, value);
+//	        return true;
+//	    }
+//	}
+//
+// 实际项目中应使用 solc 编译合约，并用 abigen 生成带 ABI 与字节码的 Go 绑定。
+const tokenBytecode = "0x" +
+	"6020602038036000396000518060005533600052600160205260406000208190556000523360007fddf252ad1be2c89b69c2b068fc378daa952ba7f1" +
+	"63c4a11628f55a4df523b3ef60206000a36100d28061005a6000396000f360003560e01c8063313ce5671461003757806318160ddd1461004257806370" +
+	"a082311461004e578063a9059cbb14610068575b600080fd5b601260005260206000f35b60005460005260206000f35b60043560005260016020526040" +
+	"6000205460005260206000f35b33600052600160205260406000208054602435808210610032579081900382556004356000526040600020805482019055" +
+	"600052600435337fddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef60206000a350600160005260206000f3"
+
+// txBackend 发送交易所需的节点能力，*ethclient.Client 与 simulated.Client 都满足
+type txBackend interface {
+	bind.ContractBackend
+	ethereum.ChainIDReader
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// transferEvent 解析后的 Transfer 事件
+type transferEvent struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+// deployToken 部署测试代币，初始供应量全部归部署者
+func deployToken(opts *bind.TransactOpts, backend bind.ContractBackend, parsedABI abi.ABI, supply *big.Int) (common.Address, *types.Transaction, error) {
+	addr, tx, _, err := bind.DeployContract(opts, parsedABI, common.FromHex(tokenBytecode), backend, supply)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to deploy token: %w", err)
+	}
+	return addr, tx, nil
+}
+
+// getTokenDecimals 查询 ERC-20 代币的 decimals（精度）
+func getTokenDecimals(ctx context.Context, caller ethereum.ContractCaller, parsedABI abi.ABI, contractAddr common.Address) (uint8, error) {
+	var decimals uint8
+	if err := callToken(ctx, caller, parsedABI, contractAddr, &decimals, "decimals"); err != nil {
+		return 0, err
+	}
+	return decimals, nil
+}
+
+// getTokenBalance 查询 ERC-20 代币余额（最小单位）
+func getTokenBalance(ctx context.Context, caller ethereum.ContractCaller, parsedABI abi.ABI, contractAddr, owner common.Address) (*big.Int, error) {
+	balance := new(big.Int)
+	if err := callToken(ctx, caller, parsedABI, contractAddr, &balance, "balanceOf", owner); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// callToken 执行只读调用并把返回值解码到 out
+func callToken(ctx context.Context, caller ethereum.ContractCaller, parsedABI abi.ABI, contractAddr common.Address, out interface{}, method string, args ...interface{}) error {
+	data, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s data: %w", method, err)
+	}
+	output, err := caller.CallContract(ctx, ethereum.CallMsg{To: &contractAddr, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	if err := parsedABI.UnpackIntoInterface(out, method, output); err != nil {
+		return fmt.Errorf("failed to unpack %s output: %w", method, err)
+	}
+	return nil
+}
+
+// sendTransfer 构造、签名并发送 ERC-20 transfer 交易（与 08 的 handleTransfer 相同的 EIP-1559 费用策略）
+func sendTransfer(ctx context.Context, backend txBackend, parsedABI abi.ABI, privKey *ecdsa.PrivateKey, contractAddr, toAddr common.Address, amount *big.Int) (*types.Transaction, error) {
+	fromAddr := crypto.PubkeyToAddress(privKey.PublicKey)
+
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	nonce, err := backend.PendingNonceAt(ctx, fromAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	callData, err := parsedABI.Pack("transfer", toAddr, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack transfer data: %w", err)
+	}
+
+	// 余额不足时 transfer 会 revert，估算 gas 阶段就会返回错误
+	gasLimit, err := backend.EstimateGas(ctx, ethereum.CallMsg{From: fromAddr, To: &contractAddr, Data: callData})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	gasLimit = gasLimit * 120 / 100
+
+	gasTipCap, gasFeeCap, err := ethutil.DynamicFees(ctx, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute fees: %w", err)
+	}
+
+	balance, err := backend.BalanceAt(ctx, fromAddr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	totalGasCost := new(big.Int).Mul(gasFeeCap, new(big.Int).SetUint64(gasLimit))
+	if balance.Cmp(totalGasCost) < 0 {
+		return nil, fmt.Errorf("insufficient ETH balance for gas: have %s wei, need %s wei", balance, totalGasCost)
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &contractAddr,
+		Value:     big.NewInt(0),
+		Data:      callData,
+	})
+	signedTx, err := types.SignTx(tx, types.NewLondonSigner(chainID), privKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := backend.SendTransaction(ctx, signedTx); err != nil {
+		return nil, fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return signedTx, nil
+}
+
+// parseTransferEvents 从交易回执中解析 Transfer 事件
+func parseTransferEvents(parsedABI abi.ABI, receipt *types.Receipt) ([]transferEvent, error) {
+	transferEventSigHash := parsedABI.Events["Transfer"].ID
+
+	var events []transferEvent
+	for _, vLog := range receipt.Logs {
+		if len(vLog.Topics) != 3 || vLog.Topics[0] != transferEventSigHash {
+			continue
+		}
+		values, err := parsedABI.Unpack("Transfer", vLog.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack Transfer data: %w", err)
+		}
+		events = append(events, transferEvent{
+			From:  common.BytesToAddress(vLog.Topics[1].Bytes()),
+			To:    common.BytesToAddress(vLog.Topics[2].Bytes()),
+			Value: values[0].(*big.Int),
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/ethutil"
+)
+
+// 1000 tokens（18 位精度）
+var testSupply, _ = new(big.Int).SetString("1000000000000000000000", 10)
+
+// setupToken 创建模拟链并部署测试代币，测试结束时自动关闭模拟链
+func setupToken(t *testing.T) (*simEnv, common.Address) {
+	t.Helper()
+	env, err := newSimEnv()
+	if err != nil {
+		t.Fatalf("newSimEnv: %v", err)
+	}
+	t.Cleanup(func() { env.backend.Close() })
+
+	addr, tx, err := deployToken(env.auth, env.backend.Client(), env.abi, testSupply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.backend.Commit()
+
+	receipt, err := bind.WaitMined(context.Background(), env.backend.Client(), tx)
+	if err != nil {
+		t.Fatalf("WaitMined: %v", err)
+	}
+	if receipt.Status != 1 {
+		t.Fatalf("deploy failed, status %d", receipt.Status)
+	}
+	return env, addr
+}
+
+// mustTransfer 发送转账并出块，返回回执中的 Transfer 事件
+func mustTransfer(t *testing.T, env *simEnv, token, to common.Address, amount *big.Int) []transferEvent {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := sendTransfer(ctx, env.backend.Client(), env.abi, env.key, token, to, amount)
+	if err != nil {
+		t.Fatalf("sendTransfer: %v", err)
+	}
+	env.backend.Commit()
+
+	receipt, err := bind.WaitMined(ctx, env.backend.Client(), tx)
+	if err != nil {
+		t.Fatalf("WaitMined: %v", err)
+	}
+	if receipt.Status != 1 {
+		t.Fatalf("transfer failed, status %d", receipt.Status)
+	}
+	events, err := parseTransferEvents(env.abi, receipt)
+	if err != nil {
+		t.Fatalf("parseTransferEvents: %v", err)
+	}
+	return events
+}
+
+func assertBalance(t *testing.T, env *simEnv, token, owner common.Address, want *big.Int) {
+	t.Helper()
+	got, err := getTokenBalance(context.Background(), env.backend.Client(), env.abi, token, owner)
+	if err != nil {
+		t.Fatalf("getTokenBalance: %v", err)
+	}
+	if got.Cmp(want) != 0 {
+		t.Fatalf("balance of %s = %s, want %s", owner.Hex(), got, want)
+	}
+}
+
+func randomAddress(t *testing.T) common.Address {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestDeploy(t *testing.T) {
+	env, token := setupToken(t)
+	deployer := env.auth.From
+
+	decimals, err := getTokenDecimals(context.Background(), env.backend.Client(), env.abi, token)
+	if err != nil {
+		t.Fatalf("getTokenDecimals: %v", err)
+	}
+	if decimals != 18 {
+		t.Fatalf("decimals = %d, want 18", decimals)
+	}
+
+	supply := new(big.Int)
+	if err := callToken(context.Background(), env.backend.Client(), env.abi, token, &supply, "totalSupply"); err != nil {
+		t.Fatalf("totalSupply: %v", err)
+	}
+	if supply.Cmp(testSupply) != 0 {
+		t.Fatalf("totalSupply = %s, want %s", supply, testSupply)
+	}
+	assertBalance(t, env, token, deployer, testSupply)
+}
+
+func TestTransfer(t *testing.T) {
+	env, token := setupToken(t)
+	deployer := env.auth.From
+	recipient := randomAddress(t)
+
+	amount, err := ethutil.ParseTokenAmount("1.5", 18)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := mustTransfer(t, env, token, recipient, amount)
+
+	if len(events) != 1 {
+		t.Fatalf("got %d Transfer events, want 1", len(events))
+	}
+	ev := events[0]
+	if ev.From != deployer || ev.To != recipient || ev.Value.Cmp(amount) != 0 {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+	assertBalance(t, env, token, recipient, amount)
+	assertBalance(t, env, token, deployer, new(big.Int).Sub(testSupply, amount))
+}
+
+func TestTransferInsufficientBalance(t *testing.T) {
+	env, token := setupToken(t)
+
+	tooMuch := new(big.Int).Add(testSupply, big.NewInt(1))
+	_, err := sendTransfer(context.Background(), env.backend.Client(), env.abi, env.key, token, randomAddress(t), tooMuch)
+	if err == nil {
+		t.Fatal("expected transfer exceeding balance to fail")
+	}
+	// 失败发生在 gas 估算阶段，交易没有发出，余额不变
+	assertBalance(t, env, token, env.auth.From, testSupply)
+}
+
+// 模拟链不会自动出块：Commit 之前交易只在交易池中，状态不变
+func TestMineOnDemand(t *testing.T) {
+	env, token := setupToken(t)
+	ctx := context.Background()
+	client := env.backend.Client()
+	recipient := randomAddress(t)
+	amount := big.NewInt(42)
+
+	tx, err := sendTransfer(ctx, client, env.abi, env.key, token, recipient, amount)
+	if err != nil {
+		t.Fatalf("sendTransfer: %v", err)
+	}
+	if _, err := client.TransactionReceipt(ctx, tx.Hash()); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("receipt before Commit: err = %v, want NotFound", err)
+	}
+	assertBalance(t, env, token, recipient, big.NewInt(0))
+
+	env.backend.Commit()
+	if _, err := client.TransactionReceipt(ctx, tx.Hash()); err != nil {
+		t.Fatalf("receipt after Commit: %v", err)
+	}
+	assertBalance(t, env, token, recipient, amount)
+}
+
+// 多个区块中的 Transfer 事件可以像真实节点一样用 FilterLogs 按 indexed 参数过滤
+func TestFilterTransferLogs(t *testing.T) {
+	env, token := setupToken(t)
+	alice, bob := randomAddress(t), randomAddress(t)
+
+	mustTransfer(t, env, token, alice, big.NewInt(1))
+	mustTransfer(t, env, token, bob, big.NewInt(2))
+	mustTransfer(t, env, token, alice, big.NewInt(3))
+
+	query := ethereum.FilterQuery{
+		FromBlock: big.NewInt(0),
+		Addresses: []common.Address{token},
+		Topics: [][]common.Hash{
+			{env.abi.Events["Transfer"].ID},
+			nil,
+			{common.BytesToHash(alice.Bytes())},
+		},
+	}
+	logs, err := env.backend.Client().FilterLogs(context.Background(), query)
+	if err != nil {
+		t.Fatalf("FilterLogs: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("got %d logs to alice, want 2", len(logs))
+	}
+	if logs[0].BlockNumber >= logs[1].BlockNumber {
+		t.Fatalf("logs not in separate increasing blocks: %d, %d", logs[0].BlockNumber, logs[1].BlockNumber)
+	}
+	assertBalance(t, env, token, alice, big.NewInt(4))
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"flag"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// 19-simulated-tests
+// 使用 go-ethereum 的模拟链（ethclient/simulated）离线开发和测试合约交互代码：
+// - 模拟链在进程内运行完整的以太坊节点逻辑，不需要 RPC 节点、不需要测试币
+// - 创世块中直接给测试账户分配 ETH；交易发送后不会自动出块，调用 Commit() 按需出块，测试结果可重复
+// - erc20.go 中的辅助函数与 08-contract-interact 相同，只是参数换成了接口，真实节点与模拟链通用
+// - erc20_test.go 演示如何在单元测试中部署 ERC-20、出块、断言余额与 Transfer 事件、断言 revert
+//
+// 执行示例：
+//    go run .          # 在模拟链上跑一遍部署 + 转账流程
+//    go test -v ./...  # 运行单元测试
+//    go run . --json   # 以 JSON 输出结果
+
+func main() {
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	env, err := newSimEnv()
+	if err != nil {
+		log.Fatalf("failed to create simulated backend: %v", err)
+	}
+	defer env.backend.Close()
+	client := env.backend.Client()
+
+	supply, _ := new(big.Int).SetString("1000000000000000000000", 10) // 1000 tokens
+	tokenAddr, _, err := deployToken(env.auth, client, env.abi, supply)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	// 部署交易进入模拟链的交易池，Commit 后才会被打包
+	env.backend.Commit()
+
+	decimals, err := getTokenDecimals(ctx, client, env.abi, tokenAddr)
+	if err != nil {
+		log.Fatalf("failed to get token decimals: %v", err)
+	}
+
+	recipient := crypto.PubkeyToAddress(mustGenerateKey().PublicKey)
+	amount, err := ethutil.ParseTokenAmount("1.5", decimals)
+	if err != nil {
+		log.Fatalf("invalid amount: %v", err)
+	}
+	tx, err := sendTransfer(ctx, client, env.abi, env.key, tokenAddr, recipient, amount)
+	if err != nil {
+		log.Fatalf("failed to send transfer: %v", err)
+	}
+	env.backend.Commit()
+
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		log.Fatalf("failed to wait for receipt: %v", err)
+	}
+	events, err := parseTransferEvents(env.abi, receipt)
+	if err != nil {
+		log.Fatalf("failed to parse events: %v", err)
+	}
+	balance, err := getTokenBalance(ctx, client, env.abi, tokenAddr, recipient)
+	if err != nil {
+		log.Fatalf("failed to get balance: %v", err)
+	}
+
+	out.Section("Simulated Backend")
+	out.Field("Token", tokenAddr)
+	out.Field("Decimals", decimals)
+	out.Field("Transfer Tx", tx.Hash())
+	out.Field("Block", receipt.BlockNumber)
+	out.Field("Status", receipt.Status)
+	out.Field("Recipient", ethutil.FormatUnits(balance, decimals))
+
+	out.Table("Transfer Events", "From", "To", "Amount")
+	for _, ev := range events {
+		out.Row(ev.From, ev.To, ethutil.FormatUnits(ev.Value, decimals))
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// simEnv 模拟链环境：一个有 ETH 的部署者账户 + 模拟链
+type simEnv struct {
+	backend *simulated.Backend
+	key     *ecdsa.PrivateKey
+	auth    *bind.TransactOpts
+	abi     abi.ABI
+}
+
+// newSimEnv 创建模拟链，并在创世块中给部署者分配 100 ETH
+func newSimEnv() (*simEnv, error) {
+	key := mustGenerateKey()
+	deployer := crypto.PubkeyToAddress(key.PublicKey)
+
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		deployer: {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))},
+	})
+
+	chainID, err := backend.Client().ChainID(context.Background())
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABIJSON))
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+	return &simEnv{backend: backend, key: key, auth: auth, abi: parsedABI}, nil
+}
+
+// mustGenerateKey 生成随机私钥
+func mustGenerateKey() *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		log.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
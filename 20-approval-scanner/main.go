@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/abis"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 20-approval-scanner
+// ERC-20 授权（approve）扫描与撤销工具：
+// 1. 在给定代币列表中扫描 owner 发出的 Approval 事件（按区块区间分段查询 eth_getLogs），得到所有授权过的 spender
+// 2. 逐个查询当前 allowance(owner, spender)：历史事件只说明授权过，当前额度以合约状态为准
+// 3. 标记无限授权（额度 >= 2^255，通常是 approve 了 type(uint256).max）——spender 合约一旦被攻破，代币可被全部转走
+// 4. --revoke 模式下逐条询问是否撤销，确认后发送 approve(spender, 0) 交易并等待确认
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://mainnet.infura.io/v3/<project-id>"
+//    go run . --owner 0x... \
+//      --tokens 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48,0xdAC17F958D2ee523a2206206994597C13D831ec7 \
+//      --from-block 17000000
+//    go run . --owner 0x... --tokens 0x... --format csv > approvals.csv
+//    # 撤销（私钥必须属于 owner）
+//    export SENDER_PRIVATE_KEY="your_private_key_hex"
+//    go run . --owner 0x... --tokens 0x... --revoke
+//
+// 注意事项：
+// - 很多 RPC 服务限制单次 eth_getLogs 的区块范围，可通过 --chunk 调整每次查询的区块数
+// - ERC-721 的 Approval 事件签名相同但 tokenId 也是 indexed（4 个 topic），这里会跳过；
+//   NFT 的 setApprovalForAll 授权不在本工具范围内
+// - 部分代币（如 USDT）要求先把额度改为 0 才能设置新额度，撤销正好满足这一要求
+
+// unlimitedThreshold 额度达到 2^255 即视为无限授权
+var unlimitedThreshold = new(big.Int).Lsh(big.NewInt(1), 255)
+
+// approval 一条 (代币, spender) 授权及其当前额度
+type approval struct {
+	Token     common.Address
+	Symbol    string
+	Decimals  uint8
+	Spender   common.Address
+	LastBlock uint64 // 最近一次 Approval 事件所在区块
+	Allowance *big.Int
+}
+
+// Unlimited 是否为无限授权
+func (a *approval) Unlimited() bool {
+	return a.Allowance.Cmp(unlimitedThreshold) >= 0
+}
+
+func main() {
+	ownerHex := flag.String("owner", "", "owner address to scan (required)")
+	tokensFlag := flag.String("tokens", "", "comma separated ERC-20 token addresses (required)")
+	fromBlock := flag.Uint64("from-block", 0, "first block to scan for Approval events")
+	chunk := flag.Uint64("chunk", 10000, "max block range per eth_getLogs request")
+	revoke := flag.Bool("revoke", false, "interactively revoke active approvals (requires SENDER_PRIVATE_KEY of the owner)")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if !common.IsHexAddress(*ownerHex) {
+		log.Fatal("missing or invalid --owner")
+	}
+	owner := common.HexToAddress(*ownerHex)
+	tokens, err := parseAddresses(*tokensFlag)
+	if err != nil || len(tokens) == 0 {
+		log.Fatalf("missing or invalid --tokens: %v", err)
+	}
+	if *chunk == 0 {
+		log.Fatal("--chunk must be positive")
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	// 扫描大区块范围和等待撤销交易确认都比较耗时，不设置整体超时，单个请求各自设置超时
+	ctx := context.Background()
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	parsedABI := abis.ERC20
+
+	approvals, err := scanApprovals(ctx, client, parsedABI, owner, tokens, *fromBlock, *chunk)
+	if err != nil {
+		log.Fatalf("failed to scan approvals: %v", err)
+	}
+	for _, a := range approvals {
+		if err := loadAllowance(ctx, client, parsedABI, owner, a); err != nil {
+			log.Fatalf("failed to query allowance: %v", err)
+		}
+	}
+
+	active := printApprovals(out, owner, approvals)
+	if *revoke && len(active) > 0 {
+		revokeInteractive(ctx, client, out, parsedABI, owner, active)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// scanApprovals 分段扫描 owner 在各代币上的 Approval 事件，按 (代币, spender) 去重
+func scanApprovals(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, owner common.Address, tokens []common.Address, fromBlock, chunk uint64) ([]*approval, error) {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	type key struct{ token, spender common.Address }
+	found := make(map[key]*approval)
+	topics := [][]common.Hash{
+		{parsedABI.Events["Approval"].ID},
+		{common.BytesToHash(owner.Bytes())},
+	}
+
+	for start := fromBlock; start <= head; start += chunk {
+		end := min(start+chunk-1, head)
+		reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		logs, err := client.FilterLogs(reqCtx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: tokens,
+			Topics:    topics,
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter logs [%d, %d]: %w", start, end, err)
+		}
+		for _, vLog := range logs {
+			// ERC-20: Approval(owner indexed, spender indexed, value)，共 3 个 topic
+			if len(vLog.Topics) != 3 || vLog.Removed {
+				continue
+			}
+			k := key{vLog.Address, common.BytesToAddress(vLog.Topics[2].Bytes())}
+			a, ok := found[k]
+			if !ok {
+				a = &approval{Token: k.token, Spender: k.spender}
+				found[k] = a
+			}
+			a.LastBlock = max(a.LastBlock, vLog.BlockNumber)
+		}
+		fmt.Fprintf(os.Stderr, "scanned blocks %d - %d, %d approval pairs so far\n", start, end, len(found))
+	}
+
+	result := make([]*approval, 0, len(found))
+	for _, a := range found {
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Token != result[j].Token {
+			return result[i].Token.Cmp(result[j].Token) < 0
+		}
+		return result[i].Spender.Cmp(result[j].Spender) < 0
+	})
+	return result, nil
+}
+
+// loadAllowance 查询当前额度以及代币的 symbol / decimals（后两者失败时使用默认值）
+func loadAllowance(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, owner common.Address, a *approval) error {
+	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	a.Allowance = new(big.Int)
+	if err := callToken(reqCtx, client, parsedABI, a.Token, &a.Allowance, "allowance", owner, a.Spender); err != nil {
+		return err
+	}
+	if err := callToken(reqCtx, client, parsedABI, a.Token, &a.Symbol, "symbol"); err != nil {
+		// 部分老代币（如 MKR）的 symbol 返回 bytes32，不是 string
+		a.Symbol = "?"
+	}
+	if err := callToken(reqCtx, client, parsedABI, a.Token, &a.Decimals, "decimals"); err != nil {
+		a.Decimals = 18
+	}
+	return nil
+}
+
+// callToken 执行只读调用并解码返回值
+func callToken(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, token common.Address, out interface{}, method string, args ...interface{}) error {
+	data, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s data: %w", method, err)
+	}
+	output, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s on %s: %w", method, token.Hex(), err)
+	}
+	if err := parsedABI.UnpackIntoInterface(out, method, output); err != nil {
+		return fmt.Errorf("failed to unpack %s output: %w", method, err)
+	}
+	return nil
+}
+
+// printApprovals 输出扫描结果，返回当前额度不为 0 的授权
+func printApprovals(out *output.Printer, owner common.Address, approvals []*approval) []*approval {
+	out.Table("Approvals", "Status", "Symbol", "Token", "Spender", "Allowance", "Last Block")
+	var active []*approval
+	unlimited := 0
+	for _, a := range approvals {
+		status := "revoked"
+		amount := "0"
+		switch {
+		case a.Unlimited():
+			status = "UNLIMITED"
+			amount = "unlimited"
+			unlimited++
+		case a.Allowance.Sign() > 0:
+			status = "active"
+			amount = ethutil.FormatUnits(a.Allowance, a.Decimals)
+		}
+		if a.Allowance.Sign() > 0 {
+			active = append(active, a)
+		}
+		out.Row(status, a.Symbol, a.Token, a.Spender, amount, a.LastBlock)
+	}
+
+	out.Section("Summary")
+	out.Field("Owner", owner)
+	out.Field("Pairs", len(approvals))
+	out.Field("Active", len(active))
+	out.Field("Unlimited", unlimited)
+	return active
+}
+
+// revokeInteractive 逐条询问并撤销授权
+func revokeInteractive(ctx context.Context, client *ethclient.Client, out *output.Printer, parsedABI abi.ABI, owner common.Address, active []*approval) {
+	privKeyHex := config.Get().PrivateKey
+	if privKeyHex == "" {
+		log.Fatal("SENDER_PRIVATE_KEY is not set (required for --revoke)")
+	}
+	privKey, err := crypto.HexToECDSA(strings.TrimPrefix(privKeyHex, "0x"))
+	if err != nil {
+		log.Fatalf("invalid private key: %v", err)
+	}
+	if crypto.PubkeyToAddress(privKey.PublicKey) != owner {
+		log.Fatal("SENDER_PRIVATE_KEY does not belong to --owner")
+	}
+
+	// 提示写到 stderr，不混入 --format json 等结构化输出
+	stdin := bufio.NewReader(os.Stdin)
+	for _, a := range active {
+		fmt.Fprintf(os.Stderr, "revoke %s approval for spender %s? [y/N] ", a.Symbol, a.Spender.Hex())
+		line, _ := stdin.ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(line)); answer != "y" && answer != "yes" {
+			continue
+		}
+		if err := sendRevoke(ctx, client, out, parsedABI, privKey, a); err != nil {
+			log.Printf("[WARN] revoke failed: %v", err)
+		}
+	}
+}
+
+// parseAddresses 解析逗号分隔的地址列表
+func parseAddresses(s string) ([]common.Address, error) {
+	var out []common.Address
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !common.IsHexAddress(item) {
+			return nil, fmt.Errorf("invalid address %q", item)
+		}
+		out = append(out, common.HexToAddress(item))
+	}
+	return out, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/txwait"
+)
+
+// sendRevoke 发送 approve(spender, 0) 并等待确认（EIP-1559 费用策略与 08-contract-interact 相同）
+func sendRevoke(ctx context.Context, client *ethclient.Client, out *output.Printer, parsedABI abi.ABI, privKey *ecdsa.PrivateKey, a *approval) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+
+	fromAddr := crypto.PubkeyToAddress(privKey.PublicKey)
+	callData, err := parsedABI.Pack("approve", a.Spender, big.NewInt(0))
+	if err != nil {
+		return fmt.Errorf("failed to pack approve data: %w", err)
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %w", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, fromAddr)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{From: fromAddr, To: &a.Token, Data: callData})
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	gasLimit = gasLimit * 120 / 100
+
+	gasTipCap, gasFeeCap, err := ethutil.DynamicFees(ctx, client)
+	if err != nil {
+		return fmt.Errorf("failed to compute fees: %w", err)
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &a.Token,
+		Value:     big.NewInt(0),
+		Data:      callData,
+	})
+	signedTx, err := types.SignTx(tx, types.NewLondonSigner(chainID), privKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	out.Note("sent revoke tx %s, waiting for confirmation...", signedTx.Hash().Hex())
+
+	receipt, err := txwait.WaitMined(ctx, client, signedTx.Hash(), 1)
+	if err != nil {
+		return fmt.Errorf("failed to wait for receipt: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("revoke tx %s failed in block %d", signedTx.Hash().Hex(), receipt.BlockNumber.Uint64())
+	}
+
+	// 以链上状态为准确认额度已归零
+	if err := loadAllowance(ctx, client, parsedABI, fromAddr, a); err != nil {
+		return err
+	}
+	out.Section("Revoked")
+	out.Field("Token", a.Token)
+	out.Field("Spender", a.Spender)
+	out.Field("Tx Hash", signedTx.Hash())
+	out.Field("Block", receipt.BlockNumber)
+	out.Field("Allowance", a.Allowance)
+	return nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultIPFSGateway = "https://ipfs.io/ipfs/"
+	arweaveGateway     = "https://arweave.net/"
+
+	maxMetadataSize = 1 << 20  // 1 MiB
+	maxImageSize    = 20 << 20 // 20 MiB
+)
+
+// fetcher 负责把各种 URI 解析为内容，并缓存下载的图片
+type fetcher struct {
+	gateway  string
+	cacheDir string
+	http     *http.Client
+}
+
+// imageResult 图片下载结果
+type imageResult struct {
+	Source      string // 实际访问的地址（data: URI 为原始 URI）
+	Path        string // 本地缓存文件
+	ContentType string
+	Size        int64
+	Cached      bool
+}
+
+func newFetcher(gateway, cacheDir string) *fetcher {
+	if !strings.HasSuffix(gateway, "/") {
+		gateway += "/"
+	}
+	return &fetcher{
+		gateway:  gateway,
+		cacheDir: cacheDir,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// resolveURI 把 ipfs:// / ar:// 转换为网关地址，http(s) 原样返回
+func (f *fetcher) resolveURI(uri string) (string, error) {
+	uri = strings.TrimSpace(uri)
+	switch {
+	case strings.HasPrefix(uri, "ipfs://"):
+		// 兼容 ipfs://<cid>/path 和不规范的 ipfs://ipfs/<cid>/path
+		return f.gateway + strings.TrimPrefix(strings.TrimPrefix(uri, "ipfs://"), "ipfs/"), nil
+	case strings.HasPrefix(uri, "ar://"):
+		return arweaveGateway + strings.TrimPrefix(uri, "ar://"), nil
+	case strings.HasPrefix(uri, "https://"), strings.HasPrefix(uri, "http://"):
+		return uri, nil
+	}
+	return "", fmt.Errorf("unsupported URI scheme: %s", shorten(uri, 60))
+}
+
+// fetch 读取 URI 内容，返回数据、内容类型以及实际访问的地址
+func (f *fetcher) fetch(ctx context.Context, uri string, limit int64) ([]byte, string, string, error) {
+	if strings.HasPrefix(uri, "data:") {
+		data, contentType, err := decodeDataURI(uri)
+		if err != nil {
+			return nil, "", "", err
+		}
+		if int64(len(data)) > limit {
+			return nil, "", "", fmt.Errorf("data URI exceeds %d bytes", limit)
+		}
+		return data, contentType, uri, nil
+	}
+
+	target, err := f.resolveURI(uri)
+	if err != nil {
+		return nil, "", "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to build request: %w", err)
+	}
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to request %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", "", fmt.Errorf("unexpected status %s from %s", resp.Status, target)
+	}
+
+	// 多读 1 字节用于判断是否超过大小限制
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, "", "", fmt.Errorf("response from %s exceeds %d bytes", target, limit)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, target, nil
+}
+
+// decodeDataURI 解析 data:[<mediatype>][;base64],<data>
+func decodeDataURI(uri string) ([]byte, string, error) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return nil, "", fmt.Errorf("malformed data URI")
+	}
+	contentType := "text/plain"
+	isBase64 := false
+	for i, part := range strings.Split(header, ";") {
+		switch {
+		case i == 0 && part != "":
+			contentType = part
+		case part == "base64":
+			isBase64 = true
+		}
+	}
+
+	if isBase64 {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode base64 data URI: %w", err)
+		}
+		return data, contentType, nil
+	}
+	// 不少合约直接拼接未转义的 JSON（包含 %、空格等），百分号解码失败时按原文处理
+	if data, err := url.PathUnescape(payload); err == nil {
+		return []byte(data), contentType, nil
+	}
+	return []byte(payload), contentType, nil
+}
+
+// FetchMetadata 下载并解析元数据 JSON
+func (f *fetcher) FetchMetadata(ctx context.Context, uri string) (*metadata, error) {
+	data, contentType, source, err := f.fetch(ctx, uri, maxMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+	return parseMetadata(data, contentType, source)
+}
+
+// FetchImage 下载图片到缓存目录，缓存文件名为 URI 的 sha256 前缀 + 扩展名
+func (f *fetcher) FetchImage(ctx context.Context, uri string) (*imageResult, error) {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(uri)))
+	key := hex.EncodeToString(sum[:8])
+
+	// 同一 URI 已下载过：直接返回缓存文件
+	if matches, _ := filepath.Glob(filepath.Join(f.cacheDir, key+".*")); len(matches) > 0 {
+		return cachedImage(uri, matches[0])
+	}
+
+	data, contentType, source, err := f.fetch(ctx, uri, maxImageSize)
+	if err != nil {
+		return nil, err
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !strings.HasPrefix(mediaType, "image/") {
+		// 部分网关返回 application/octet-stream，以内容嗅探结果为准
+		mediaType = http.DetectContentType(data)
+		if strings.HasPrefix(strings.TrimSpace(string(data[:min(len(data), 512)])), "<svg") {
+			mediaType = "image/svg+xml"
+		}
+		if !strings.HasPrefix(mediaType, "image/") {
+			return nil, fmt.Errorf("not an image: content type %q", contentType)
+		}
+	}
+
+	if err := os.MkdirAll(f.cacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	path := filepath.Join(f.cacheDir, key+imageExtension(mediaType))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return &imageResult{Source: source, Path: path, ContentType: mediaType, Size: int64(len(data))}, nil
+}
+
+// cachedImage 读取缓存文件信息
+func cachedImage(uri, path string) (*imageResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return &imageResult{Source: uri, Path: path, ContentType: contentType, Size: int64(len(data)), Cached: true}, nil
+}
+
+// imageExtension 根据内容类型选择缓存文件扩展名
+func imageExtension(mediaType string) string {
+	switch mediaType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "image/svg+xml":
+		return ".svg"
+	}
+	if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 21-nft-metadata
+// NFT 元数据读取示例，覆盖 NFT 开发中"链上 + 链下"两部分：
+// 1. 链上：通过 ERC-165 supportsInterface 判断合约是 ERC-721 还是 ERC-1155，
+//    分别调用 tokenURI(tokenId) / uri(id) 读取元数据地址（ERC-1155 需要替换 {id} 占位符）
+// 2. 链下：解析 ipfs:// / ar:// / data: / https:// 等 URI，下载元数据 JSON 并校验常用字段
+// 3. 下载 image 指向的图片，按 URL 的哈希缓存到本地目录，重复运行时直接命中缓存
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://mainnet.infura.io/v3/<project-id>"
+//    # ERC-721（BAYC #1）
+//    go run . --contract 0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D --token-id 1
+//    # ERC-1155，指定网关并跳过图片下载
+//    go run . --contract 0x... --token-id 10 --gateway https://cloudflare-ipfs.com/ipfs/ --no-image
+//    go run . --contract 0x... --token-id 1 --no-image --format json | jq .Attributes
+//
+// 注意事项：
+// - 元数据和图片都由合约方控制，内容随时可能变化；data: URI 形式的链上元数据（全链 NFT）才是不可变的
+// - 公共 IPFS 网关经常限流或超时，生产环境建议使用自建节点或付费网关
+// - 下载大小有限制（元数据 1 MiB、图片 20 MiB），防止恶意元数据指向超大文件
+
+const nftABIJSON = `[
+  {
+    "constant": true,
+    "inputs": [{"name": "interfaceId", "type": "bytes4"}],
+    "name": "supportsInterface",
+    "outputs": [{"name": "", "type": "bool"}],
+    "type": "function"
+  },
+  {
+    "constant": true,
+    "inputs": [{"name": "tokenId", "type": "uint256"}],
+    "name": "tokenURI",
+    "outputs": [{"name": "", "type": "string"}],
+    "type": "function"
+  },
+  {
+    "constant": true,
+    "inputs": [{"name": "id", "type": "uint256"}],
+    "name": "uri",
+    "outputs": [{"name": "", "type": "string"}],
+    "type": "function"
+  },
+  {
+    "constant": true,
+    "inputs": [{"name": "tokenId", "type": "uint256"}],
+    "name": "ownerOf",
+    "outputs": [{"name": "", "type": "address"}],
+    "type": "function"
+  },
+  {
+    "constant": true,
+    "inputs": [],
+    "name": "name",
+    "outputs": [{"name": "", "type": "string"}],
+    "type": "function"
+  }
+]`
+
+// ERC-165 接口 ID
+var (
+	erc721InterfaceID  = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	erc1155InterfaceID = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+)
+
+func main() {
+	contractHex := flag.String("contract", "", "NFT contract address (required)")
+	tokenIDStr := flag.String("token-id", "", "token id, decimal or 0x-prefixed hex (required)")
+	standard := flag.String("standard", "auto", "token standard: auto, erc721 or erc1155")
+	gateway := flag.String("gateway", defaultIPFSGateway, "IPFS HTTP gateway used for ipfs:// URIs")
+	cacheDir := flag.String("cache-dir", ".nft-cache", "directory for cached images")
+	noImage := flag.Bool("no-image", false, "skip downloading the image")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+	defer func() {
+		if err := out.Flush(); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	if !common.IsHexAddress(*contractHex) {
+		log.Fatal("missing or invalid --contract")
+	}
+	contract := common.HexToAddress(*contractHex)
+	tokenID, ok := new(big.Int).SetString(*tokenIDStr, 0)
+	if !ok || tokenID.Sign() < 0 {
+		log.Fatal("missing or invalid --token-id")
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(2*time.Minute))
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	parsedABI, err := abi.JSON(strings.NewReader(nftABIJSON))
+	if err != nil {
+		log.Fatalf("failed to parse ABI: %v", err)
+	}
+
+	std := strings.ToLower(*standard)
+	if std == "auto" {
+		if std, err = detectStandard(ctx, client, parsedABI, contract); err != nil {
+			log.Fatalf("failed to detect token standard: %v", err)
+		}
+	}
+
+	var rawURI string
+	switch std {
+	case "erc721":
+		err = callNFT(ctx, client, parsedABI, contract, &rawURI, "tokenURI", tokenID)
+	case "erc1155":
+		err = callNFT(ctx, client, parsedABI, contract, &rawURI, "uri", tokenID)
+		rawURI = expandERC1155ID(rawURI, tokenID)
+	default:
+		log.Fatalf("unsupported --standard %q", *standard)
+	}
+	if err != nil {
+		log.Fatalf("failed to read token URI: %v", err)
+	}
+
+	out.Section("Token")
+	out.Field("Contract", contract)
+	var collection string
+	if err := callNFT(ctx, client, parsedABI, contract, &collection, "name"); err == nil {
+		out.Field("Collection", collection)
+	}
+	out.Field("Standard", std)
+	out.Field("Token ID", tokenID)
+	if std == "erc721" {
+		var owner common.Address
+		if err := callNFT(ctx, client, parsedABI, contract, &owner, "ownerOf", tokenID); err == nil {
+			out.Field("Owner", owner)
+		} else {
+			log.Printf("[WARN] ownerOf failed (token may not exist or be burned): %v", err)
+		}
+	}
+	out.Field("Token URI", shorten(rawURI, 120))
+
+	fetcher := newFetcher(*gateway, *cacheDir)
+	meta, err := fetcher.FetchMetadata(ctx, rawURI)
+	if err != nil {
+		log.Fatalf("failed to fetch metadata: %v", err)
+	}
+	printMetadata(out, meta)
+
+	if *noImage {
+		return
+	}
+	imageURI := meta.Image
+	if imageURI == "" {
+		imageURI = meta.ImageURL
+	}
+	if imageURI == "" {
+		if meta.ImageData != "" {
+			out.Note("\nImage       : inline image_data (SVG), nothing to download")
+		}
+		return
+	}
+	img, err := fetcher.FetchImage(ctx, imageURI)
+	if err != nil {
+		log.Fatalf("failed to fetch image: %v", err)
+	}
+	out.Section("Image")
+	out.Field("Source", shorten(img.Source, 120))
+	out.Field("Content Type", img.ContentType)
+	out.Field("Size", img.Size)
+	out.Field("Cached File", img.Path)
+	out.Field("Cache Hit", img.Cached)
+}
+
+// detectStandard 通过 ERC-165 判断代币标准
+func detectStandard(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, contract common.Address) (string, error) {
+	var is721, is1155 bool
+	if err := callNFT(ctx, client, parsedABI, contract, &is721, "supportsInterface", erc721InterfaceID); err != nil {
+		return "", fmt.Errorf("contract does not implement ERC-165 (use --standard): %w", err)
+	}
+	if is721 {
+		return "erc721", nil
+	}
+	if err := callNFT(ctx, client, parsedABI, contract, &is1155, "supportsInterface", erc1155InterfaceID); err != nil {
+		return "", err
+	}
+	if is1155 {
+		return "erc1155", nil
+	}
+	return "", fmt.Errorf("contract supports neither ERC-721 nor ERC-1155 interface")
+}
+
+// expandERC1155ID 替换 ERC-1155 URI 中的 {id}：小写十六进制、左补零到 64 位、不带 0x
+func expandERC1155ID(uri string, id *big.Int) string {
+	return strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", id))
+}
+
+// callNFT 执行只读调用并解码返回值
+func callNFT(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, contract common.Address, out interface{}, method string, args ...interface{}) error {
+	data, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s data: %w", method, err)
+	}
+	output, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	if err := parsedABI.UnpackIntoInterface(out, method, output); err != nil {
+		return fmt.Errorf("failed to unpack %s output: %w", method, err)
+	}
+	return nil
+}
+
+// shorten 截断过长的字符串（data: URI 可能有几十 KB）
+func shorten(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + fmt.Sprintf("... (%d bytes)", len(s))
+}
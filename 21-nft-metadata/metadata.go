@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// metadata ERC-721 / ERC-1155 元数据 JSON（EIP-721 / EIP-1155 定义的字段 + OpenSea 约定的常用扩展）
+type metadata struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	Image        string          `json:"image"`
+	ImageURL     string          `json:"image_url"`  // 非标准，部分项目用它代替 image
+	ImageData    string          `json:"image_data"` // 内联 SVG 原文
+	ExternalURL  string          `json:"external_url"`
+	AnimationURL string          `json:"animation_url"`
+	RawAttrs     json.RawMessage `json:"attributes"`
+
+	Attributes []attribute `json:"-"`
+	Source     string      `json:"-"` // 元数据实际来源地址
+	Warnings   []string    `json:"-"` // 校验发现的问题
+}
+
+// attribute OpenSea 风格的属性：value 可能是字符串、数字或布尔值
+type attribute struct {
+	TraitType   string      `json:"trait_type"`
+	Value       interface{} `json:"value"`
+	DisplayType string      `json:"display_type"`
+}
+
+// parseMetadata 解析元数据 JSON 并做基本校验：
+// JSON 本身不合法视为错误；字段缺失或格式不规范只记录警告
+func parseMetadata(data []byte, contentType, source string) (*metadata, error) {
+	var meta metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("invalid metadata JSON (content type %q): %w", contentType, err)
+	}
+	meta.Source = source
+
+	if meta.Name == "" {
+		meta.Warnings = append(meta.Warnings, "missing name")
+	}
+	if meta.Image == "" && meta.ImageURL == "" && meta.ImageData == "" {
+		meta.Warnings = append(meta.Warnings, "missing image / image_data")
+	}
+	if meta.Image != "" && !isSupportedURI(meta.Image) {
+		meta.Warnings = append(meta.Warnings, fmt.Sprintf("unsupported image URI scheme: %s", shorten(meta.Image, 60)))
+	}
+	if strings.HasPrefix(meta.Image, "http://") {
+		meta.Warnings = append(meta.Warnings, "image served over plain http")
+	}
+
+	if len(meta.RawAttrs) > 0 && string(meta.RawAttrs) != "null" {
+		if err := json.Unmarshal(meta.RawAttrs, &meta.Attributes); err != nil {
+			meta.Warnings = append(meta.Warnings, "attributes is not an array of {trait_type, value} objects")
+		}
+		for i, attr := range meta.Attributes {
+			if attr.TraitType == "" {
+				meta.Warnings = append(meta.Warnings, fmt.Sprintf("attribute #%d has no trait_type", i))
+			}
+			if attr.Value == nil {
+				meta.Warnings = append(meta.Warnings, fmt.Sprintf("attribute #%d has no value", i))
+			}
+		}
+	}
+	return &meta, nil
+}
+
+// isSupportedURI 是否为本示例可以下载的 URI
+func isSupportedURI(uri string) bool {
+	for _, prefix := range []string{"https://", "http://", "ipfs://", "ar://", "data:"} {
+		if strings.HasPrefix(uri, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// printMetadata 输出元数据与校验结果
+func printMetadata(out *output.Printer, meta *metadata) {
+	out.Section("Metadata")
+	out.Field("Source", shorten(meta.Source, 120))
+	out.Field("Name", meta.Name)
+	if meta.Description != "" {
+		out.Field("Description", shorten(meta.Description, 120))
+	}
+	if meta.Image != "" {
+		out.Field("Image", shorten(meta.Image, 120))
+	}
+	if meta.ImageURL != "" {
+		out.Field("Image URL", shorten(meta.ImageURL, 120))
+	}
+	if meta.ImageData != "" {
+		out.Field("Image Data", fmt.Sprintf("%d bytes inline", len(meta.ImageData)))
+	}
+	if meta.AnimationURL != "" {
+		out.Field("Animation", shorten(meta.AnimationURL, 120))
+	}
+	if meta.ExternalURL != "" {
+		out.Field("External URL", meta.ExternalURL)
+	}
+	if len(meta.Attributes) > 0 {
+		out.Table("Attributes", "Trait", "Value")
+		for _, attr := range meta.Attributes {
+			out.Row(attr.TraitType, attr.Value)
+		}
+	}
+	for _, w := range meta.Warnings {
+		log.Printf("[WARN] metadata: %s", w)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// deployViaProxy 通过确定性部署代理执行 CREATE2：
+// 代理合约没有函数选择器，calldata 直接是 salt(32 字节) ++ initCode，成功时返回新合约地址（20 字节）
+func deployViaProxy(out *output.Printer, salt common.Hash, initCode []byte) error {
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		return config.ErrNoRPC
+	}
+	privKeyHex := config.Get().PrivateKey
+	if privKeyHex == "" {
+		return fmt.Errorf("SENDER_PRIVATE_KEY is not set")
+	}
+	privKey, err := crypto.HexToECDSA(strings.TrimPrefix(privKeyHex, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+	fromAddr := crypto.PubkeyToAddress(privKey.PublicKey)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(3*time.Minute))
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Ethereum node: %w", err)
+	}
+	defer client.Close()
+
+	expected := crypto.CreateAddress2(deterministicDeployer, salt, crypto.Keccak256(initCode))
+	printCreate2(out, deterministicDeployer, salt, initCode, crypto.Keccak256Hash(initCode))
+
+	proxyCode, err := client.CodeAt(ctx, deterministicDeployer, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get proxy code: %w", err)
+	}
+	if len(proxyCode) == 0 {
+		return fmt.Errorf("deterministic deployment proxy %s is not deployed on this chain", deterministicDeployer.Hex())
+	}
+	// 同一 salt + init code 只能部署一次，再次部署会失败；已存在时直接返回
+	existing, err := client.CodeAt(ctx, expected, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get code at %s: %w", expected.Hex(), err)
+	}
+	if len(existing) > 0 {
+		out.Section("Already Deployed")
+		out.Field("Address", expected)
+		out.Field("Code Size", len(existing))
+		return nil
+	}
+
+	callData := append(salt.Bytes(), initCode...)
+	msg := ethereum.CallMsg{From: fromAddr, To: &deterministicDeployer, Data: callData}
+
+	// 先 eth_call 模拟：返回值应为预期地址，构造函数 revert 时这里就会报错
+	ret, err := client.CallContract(ctx, msg, nil)
+	if err != nil {
+		return fmt.Errorf("deployment simulation failed: %w", err)
+	}
+	if common.BytesToAddress(ret) != expected {
+		return fmt.Errorf("proxy returned %s, expected %s", common.BytesToAddress(ret).Hex(), expected.Hex())
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %w", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, fromAddr)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	gasLimit, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	gasLimit = gasLimit * 120 / 100
+
+	gasTipCap, gasFeeCap, err := ethutil.DynamicFees(ctx, client)
+	if err != nil {
+		return fmt.Errorf("failed to compute fees: %w", err)
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &deterministicDeployer,
+		Value:     big.NewInt(0),
+		Data:      callData,
+	})
+	signedTx, err := types.SignTx(tx, types.NewLondonSigner(chainID), privKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	out.Note("sent deploy tx %s, waiting for confirmation...", signedTx.Hash().Hex())
+
+	receipt, err := bind.WaitMined(ctx, client, signedTx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for receipt: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("deploy tx failed in block %d", receipt.BlockNumber.Uint64())
+	}
+	code, err := client.CodeAt(ctx, expected, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get deployed code: %w", err)
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("no code at %s after deployment", expected.Hex())
+	}
+
+	out.Section("Deployed")
+	out.Field("Address", expected)
+	out.Field("Tx Hash", signedTx.Hash())
+	out.Field("Block", receipt.BlockNumber)
+	out.Field("Gas Used", receipt.GasUsed)
+	out.Field("Code Size", len(code))
+	return nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// 22-create2
+// 合约地址计算与确定性部署示例：
+// - CREATE：address = keccak256(rlp([deployer, nonce]))[12:]，依赖部署者 nonce，跨链很难保持一致
+// - CREATE2（EIP-1014）：address = keccak256(0xff ++ deployer ++ salt ++ keccak256(initCode))[12:]，
+//   只由部署者、salt 和 init code 决定，与 nonce 无关
+// - vanity 模式多协程暴力搜索 salt，使 CREATE2 地址带有指定前缀 / 后缀
+// - deploy 模式通过确定性部署代理（Arachnid deterministic-deployment-proxy，
+//   0x4e59b44847b379578588920cA78FbF26c0B4956C）执行 CREATE2：代理在各条链上地址相同，
+//   因此同一 salt + init code 在所有链上都会部署到同一地址
+//
+// 执行示例：
+//    go run . --mode create --deployer 0x... --nonce 7
+//    go run . --mode create2 --salt 0x01 --init-code 0x6080...           # 默认部署者为代理合约
+//    go run . --mode create2 --deployer 0x... --salt 42 --init-code-hash 0x...
+//    go run . --mode vanity --init-code @build/Token.bin --prefix 0000 --workers 8
+//    go run . --mode create2 --salt 0x01 --init-code-hash 0x... --format json
+//    export ETH_RPC_URL="https://sepolia.infura.io/v3/<project-id>"
+//    export SENDER_PRIVATE_KEY="your_private_key_hex"
+//    go run . --mode deploy --salt 0x01 --init-code @build/Token.bin
+//
+// 注意事项：
+// - init code 是部署字节码（含构造函数参数），不是部署后的 runtime code；构造参数不同地址也不同
+// - 前缀每多 1 个十六进制字符，期望尝试次数乘以 16；8 个字符约需 40 亿次
+// - 代理合约由一笔无链 ID 的预签名交易部署，个别链（如强制 EIP-155 的链）上可能不存在
+
+// deterministicDeployer Arachnid 确定性部署代理地址
+var deterministicDeployer = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+
+func main() {
+	mode := flag.String("mode", "create2", "operation mode: create | create2 | vanity | deploy")
+	deployerHex := flag.String("deployer", "", "deployer address (create2/vanity default: deterministic deployment proxy)")
+	nonce := flag.Uint64("nonce", 0, "deployer nonce for CREATE")
+	saltStr := flag.String("salt", "", "CREATE2 salt: 0x-prefixed hex (left padded to 32 bytes) or decimal")
+	initCodeStr := flag.String("init-code", "", "contract init code as hex, or @file containing hex")
+	initCodeHashStr := flag.String("init-code-hash", "", "keccak256 of the init code (alternative to --init-code)")
+	prefix := flag.String("prefix", "", "vanity: required hex prefix of the address (without 0x)")
+	suffix := flag.String("suffix", "", "vanity: required hex suffix of the address")
+	workers := flag.Int("workers", 4, "vanity: number of search goroutines")
+	maxTries := flag.Uint64("max-tries", 1<<32, "vanity: give up after this many salts")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	deployer := deterministicDeployer
+	if *deployerHex != "" {
+		if !common.IsHexAddress(*deployerHex) {
+			log.Fatalf("invalid --deployer: %s", *deployerHex)
+		}
+		deployer = common.HexToAddress(*deployerHex)
+	}
+
+	switch *mode {
+	case "create":
+		if *deployerHex == "" {
+			log.Fatal("create mode requires --deployer")
+		}
+		out.Section("CREATE")
+		out.Field("Deployer", deployer)
+		out.Field("Nonce", *nonce)
+		out.Field("Address", crypto.CreateAddress(deployer, *nonce))
+
+	case "create2":
+		salt := mustParseSalt(*saltStr)
+		initCode, codeHash := mustLoadInitCode(*initCodeStr, *initCodeHashStr)
+		printCreate2(out, deployer, salt, initCode, codeHash)
+
+	case "vanity":
+		_, codeHash := mustLoadInitCode(*initCodeStr, *initCodeHashStr)
+		pattern, err := newVanityPattern(*prefix, *suffix)
+		if err != nil {
+			log.Fatalf("invalid vanity pattern: %v", err)
+		}
+		out.Note("searching salt for pattern %s (expected ~%s tries, %d workers)...",
+			pattern, pattern.ExpectedTries(), *workers)
+		res, err := searchSalt(out, deployer, codeHash, pattern, *workers, *maxTries)
+		if err != nil {
+			log.Fatalf("vanity search failed: %v", err)
+		}
+		out.Section("Vanity Result")
+		out.Field("Deployer", deployer)
+		out.Field("Salt", res.Salt)
+		out.Field("Address", res.Address)
+		out.Field("Tries", res.Tries)
+		out.Field("Salts/s", math.Round(float64(res.Tries)/res.Elapsed.Seconds()))
+
+	case "deploy":
+		if *deployerHex != "" && deployer != deterministicDeployer {
+			log.Fatal("deploy mode always uses the deterministic deployment proxy; omit --deployer")
+		}
+		salt := mustParseSalt(*saltStr)
+		initCode, _ := mustLoadInitCode(*initCodeStr, "")
+		if initCode == nil {
+			log.Fatal("deploy mode requires --init-code")
+		}
+		if err := deployViaProxy(out, salt, initCode); err != nil {
+			log.Fatalf("deploy failed: %v", err)
+		}
+
+	default:
+		log.Fatalf("unknown mode: %s", *mode)
+	}
+
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printCreate2 输出 CREATE2 地址计算的各个输入与结果
+func printCreate2(out *output.Printer, deployer common.Address, salt common.Hash, initCode []byte, codeHash common.Hash) {
+	out.Section("CREATE2")
+	out.Field("Deployer", deployer)
+	out.Field("Salt", salt)
+	if initCode != nil {
+		out.Field("Init Code", fmt.Sprintf("%d bytes", len(initCode)))
+	}
+	out.Field("Code Hash", codeHash)
+	out.Field("Address", crypto.CreateAddress2(deployer, salt, codeHash.Bytes()))
+}
+
+// mustParseSalt 解析 salt：0x 开头按十六进制（左侧补零到 32 字节），否则按十进制
+func mustParseSalt(s string) common.Hash {
+	if s == "" {
+		log.Fatal("--salt is required")
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		digits := s[2:]
+		if len(digits)%2 == 1 {
+			digits = "0" + digits
+		}
+		b, err := hexBytes(digits)
+		if err != nil || len(b) > common.HashLength {
+			log.Fatalf("invalid --salt %q: must be at most 32 bytes of hex", s)
+		}
+		return common.BytesToHash(b)
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+		log.Fatalf("invalid --salt %q", s)
+	}
+	return common.BigToHash(n)
+}
+
+// mustLoadInitCode 读取 init code（或直接使用给定的 init code hash），返回 init code 与其哈希
+// 只给出 --init-code-hash 时 init code 为 nil
+func mustLoadInitCode(codeStr, hashStr string) ([]byte, common.Hash) {
+	switch {
+	case codeStr != "":
+		if strings.HasPrefix(codeStr, "@") {
+			data, err := os.ReadFile(strings.TrimPrefix(codeStr, "@"))
+			if err != nil {
+				log.Fatalf("failed to read init code file: %v", err)
+			}
+			codeStr = strings.TrimSpace(string(data))
+		}
+		code, err := hexBytes(codeStr)
+		if err != nil || len(code) == 0 {
+			log.Fatalf("invalid init code: %v", err)
+		}
+		return code, crypto.Keccak256Hash(code)
+	case hashStr != "":
+		b, err := hexBytes(hashStr)
+		if err != nil || len(b) != common.HashLength {
+			log.Fatalf("invalid --init-code-hash %q", hashStr)
+		}
+		return nil, common.BytesToHash(b)
+	}
+	log.Fatal("--init-code or --init-code-hash is required")
+	return nil, common.Hash{}
+}
+
+// hexBytes 解析十六进制字符串（0x 前缀可选）
+func hexBytes(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	return hex.DecodeString(s)
+}
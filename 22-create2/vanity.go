@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// vanityPattern 地址需要满足的前缀 / 后缀（十六进制半字节，不区分大小写）
+type vanityPattern struct {
+	prefix []byte
+	suffix []byte
+}
+
+func newVanityPattern(prefix, suffix string) (*vanityPattern, error) {
+	prefix = strings.ToLower(strings.TrimPrefix(prefix, "0x"))
+	suffix = strings.ToLower(suffix)
+	if prefix == "" && suffix == "" {
+		return nil, fmt.Errorf("--prefix or --suffix is required")
+	}
+	if len(prefix)+len(suffix) > 2*common.AddressLength {
+		return nil, fmt.Errorf("pattern longer than an address")
+	}
+	p := &vanityPattern{}
+	var err error
+	if p.prefix, err = nibbles(prefix); err != nil {
+		return nil, fmt.Errorf("invalid prefix: %w", err)
+	}
+	if p.suffix, err = nibbles(suffix); err != nil {
+		return nil, fmt.Errorf("invalid suffix: %w", err)
+	}
+	return p, nil
+}
+
+// nibbles 把十六进制字符串转换为半字节序列
+func nibbles(s string) ([]byte, error) {
+	out := make([]byte, len(s))
+	for i, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			out[i] = byte(c - '0')
+		case c >= 'a' && c <= 'f':
+			out[i] = byte(c - 'a' + 10)
+		default:
+			return nil, fmt.Errorf("non-hex character %q", c)
+		}
+	}
+	return out, nil
+}
+
+// nibbleAt 地址第 i 个十六进制字符对应的半字节
+func nibbleAt(addr []byte, i int) byte {
+	if i%2 == 0 {
+		return addr[i/2] >> 4
+	}
+	return addr[i/2] & 0x0f
+}
+
+// Match 地址是否满足模式（直接比较半字节，避免每次做十六进制编码）
+func (p *vanityPattern) Match(addr []byte) bool {
+	for i, n := range p.prefix {
+		if nibbleAt(addr, i) != n {
+			return false
+		}
+	}
+	offset := 2*len(addr) - len(p.suffix)
+	for i, n := range p.suffix {
+		if nibbleAt(addr, offset+i) != n {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpectedTries 期望尝试次数：16^(模式长度)
+func (p *vanityPattern) ExpectedTries() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(4*(len(p.prefix)+len(p.suffix))))
+}
+
+func (p *vanityPattern) String() string {
+	const hexDigits = "0123456789abcdef"
+	var b strings.Builder
+	b.WriteString("0x")
+	for _, n := range p.prefix {
+		b.WriteByte(hexDigits[n])
+	}
+	b.WriteString("...")
+	for _, n := range p.suffix {
+		b.WriteByte(hexDigits[n])
+	}
+	return b.String()
+}
+
+// vanityResult 搜索结果
+type vanityResult struct {
+	Salt    common.Hash
+	Address common.Address
+	Tries   uint64
+	Elapsed time.Duration
+}
+
+// searchSalt 多协程搜索 salt：每个协程使用随机的 24 字节前缀 + 8 字节递增计数器，
+// 协程之间、多次运行之间都不会重复搜索同一段 salt
+func searchSalt(out *output.Printer, deployer common.Address, codeHash common.Hash, pattern *vanityPattern, workers int, maxTries uint64) (*vanityResult, error) {
+	if workers <= 0 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		tries  atomic.Uint64
+		once   sync.Once
+		result *vanityResult
+		wg     sync.WaitGroup
+		start  = time.Now()
+	)
+
+	for w := 0; w < workers; w++ {
+		var base common.Hash
+		if _, err := rand.Read(base[:24]); err != nil {
+			return nil, fmt.Errorf("failed to generate random salt: %w", err)
+		}
+		wg.Add(1)
+		go func(salt common.Hash) {
+			defer wg.Done()
+			// 预先拼好 0xff ++ deployer ++ salt ++ codeHash，循环中只改 salt 的计数器部分
+			buf := make([]byte, 1+common.AddressLength+2*common.HashLength)
+			buf[0] = 0xff
+			copy(buf[1:], deployer.Bytes())
+			copy(buf[1+common.AddressLength:], salt[:])
+			copy(buf[1+common.AddressLength+common.HashLength:], codeHash[:])
+			counter := buf[1+common.AddressLength+24 : 1+common.AddressLength+common.HashLength]
+
+			hasher := crypto.NewKeccakState()
+			var digest common.Hash
+			for i := uint64(0); ; i++ {
+				// 每 4096 次检查一次是否需要停止，减少原子操作开销
+				if i%4096 == 0 {
+					if ctx.Err() != nil || tries.Add(4096) > maxTries {
+						return
+					}
+				}
+				binary.BigEndian.PutUint64(counter, i)
+				hasher.Reset()
+				hasher.Write(buf)
+				hasher.Read(digest[:])
+				if pattern.Match(digest[12:]) {
+					once.Do(func() {
+						var found common.Hash
+						copy(found[:], buf[1+common.AddressLength:1+common.AddressLength+common.HashLength])
+						result = &vanityResult{
+							Salt:    found,
+							Address: common.BytesToAddress(digest[12:]),
+						}
+						cancel()
+					})
+					return
+				}
+			}
+		}(base)
+	}
+
+	// 定期输出进度
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			if result == nil {
+				return nil, fmt.Errorf("no matching salt after %d tries", tries.Load())
+			}
+			result.Tries = tries.Load()
+			result.Elapsed = time.Since(start)
+			return result, nil
+		case <-ticker.C:
+			n := tries.Load()
+			out.Note("  %d salts tried (%.0f/s)", n, float64(n)/time.Since(start).Seconds())
+		}
+	}
+}
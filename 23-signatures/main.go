@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcerr"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 23-signatures
+// 签名恢复与验证工具：
+// - sign：使用 SENDER_PRIVATE_KEY 按 EIP-191（personal_sign）签名消息，输出 65 字节、EIP-2098 紧凑格式与 r / s / v
+// - recover：从签名恢复签名者地址；自动处理 v=0/1、27/28、EIP-155 形式、64 字节紧凑签名和高 S 签名（见 sig.go）
+// - verify：验证签名是否属于 --address：
+//   - EOA：ecrecover 后比较地址
+//   - 合约钱包（Safe、Argent 等）：调用 EIP-1271 isValidSignature(hash, signature)，返回 0x1626ba7e 表示有效
+//
+// 消息来源三选一：--message（UTF-8 文本）、--message-hex（原始字节）、--hash（已计算好的 32 字节摘要，不加 EIP-191 前缀）
+//
+// 执行示例：
+//    export SENDER_PRIVATE_KEY="your_private_key_hex"
+//    go run . sign --message "hello"
+//    go run . recover --message "hello" --signature 0x...
+//    go run . verify --message "hello" --signature 0x... --address 0x...
+//    go run . sign --message "hello" --json           # 输出格式：--format pretty（默认）| json | yaml | csv
+//    # 合约钱包需要节点
+//    export ETH_RPC_URL="https://mainnet.infura.io/v3/<project-id>"
+//    go run . verify --message "hello" --signature 0x... --address <safe-address>
+//
+// 注意事项：
+// - EIP-191 摘要为 keccak256("\x19Ethereum Signed Message:\n" + len(message) + message)，长度是字节数的十进制字符串
+// - 合约钱包的签名格式由合约自行定义（可能是多个签名拼接），因此 EIP-1271 验证时原样传入，不做规范化
+// - recover 总能"恢复"出一个地址：签名与消息不匹配时得到的是一个随机地址，必须与预期地址比较
+
+// EIP-1271 魔数：bytes4(keccak256("isValidSignature(bytes32,bytes)"))
+var eip1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+const eip1271ABIJSON = `[
+  {
+    "constant": true,
+    "inputs": [
+      {"name": "hash", "type": "bytes32"},
+      {"name": "signature", "type": "bytes"}
+    ],
+    "name": "isValidSignature",
+    "outputs": [{"name": "magicValue", "type": "bytes4"}],
+    "type": "function"
+  }
+]`
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "sign":
+		cmdSign(args)
+	case "recover":
+		cmdRecover(args)
+	case "verify":
+		cmdVerify(args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: go run . <sign|recover|verify> [flags]")
+	fmt.Fprintln(os.Stderr, "run a subcommand with -h to see its flags")
+}
+
+// messageFlags 消息相关的共用参数
+type messageFlags struct {
+	message    *string
+	messageHex *string
+	hash       *string
+	out        *output.Printer
+}
+
+// newFlagSet 创建子命令参数集并注册消息参数
+func newFlagSet(name string) (*flag.FlagSet, messageFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	config.AddFlags(fs)
+	return fs, messageFlags{
+		message:    fs.String("message", "", "UTF-8 message (EIP-191 personal_sign)"),
+		messageHex: fs.String("message-hex", "", "message as 0x-prefixed hex bytes (EIP-191 personal_sign)"),
+		hash:       fs.String("hash", "", "raw 32-byte digest to sign / verify (no EIP-191 prefix)"),
+		out:        output.AddFlags(fs),
+	}
+}
+
+// digest 计算待签名 / 待验证的 32 字节摘要
+func (m messageFlags) digest() common.Hash {
+	set := 0
+	for _, s := range []string{*m.message, *m.messageHex, *m.hash} {
+		if s != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		log.Fatal("exactly one of --message, --message-hex or --hash is required")
+	}
+
+	switch {
+	case *m.message != "":
+		return common.BytesToHash(accounts.TextHash([]byte(*m.message)))
+	case *m.messageHex != "":
+		data, err := hexutil.Decode(*m.messageHex)
+		if err != nil {
+			log.Fatalf("invalid --message-hex: %v", err)
+		}
+		return common.BytesToHash(accounts.TextHash(data))
+	default:
+		data, err := hexutil.Decode(*m.hash)
+		if err != nil || len(data) != common.HashLength {
+			log.Fatalf("invalid --hash: must be 32 bytes of hex")
+		}
+		return common.BytesToHash(data)
+	}
+}
+
+// cmdSign 使用私钥签名
+func cmdSign(args []string) {
+	fs, mf := newFlagSet("sign")
+	fs.Parse(args)
+	hash := mf.digest()
+
+	privKeyHex := config.Get().PrivateKey
+	if privKeyHex == "" {
+		log.Fatal("SENDER_PRIVATE_KEY is not set")
+	}
+	privKey, err := crypto.HexToECDSA(strings.TrimPrefix(privKeyHex, "0x"))
+	if err != nil {
+		log.Fatalf("invalid private key: %v", err)
+	}
+
+	raw, err := crypto.Sign(hash.Bytes(), privKey)
+	if err != nil {
+		log.Fatalf("failed to sign: %v", err)
+	}
+	sig, err := normalizeSignature(raw)
+	if err != nil {
+		log.Fatalf("failed to normalize signature: %v", err)
+	}
+
+	mf.out.Section("Signature")
+	mf.out.Field("Signer", crypto.PubkeyToAddress(privKey.PublicKey))
+	printSignature(mf.out, hash, sig)
+	flush(mf.out)
+}
+
+// cmdRecover 恢复签名者地址
+func cmdRecover(args []string) {
+	fs, mf := newFlagSet("recover")
+	sigHex := fs.String("signature", "", "signature hex (64 or 65 bytes)")
+	fs.Parse(args)
+	hash := mf.digest()
+
+	sig := mustNormalize(*sigHex)
+	signer, err := recoverSigner(hash, sig)
+	if err != nil {
+		log.Fatalf("failed to recover signer: %v", err)
+	}
+
+	mf.out.Section("Recover")
+	mf.out.Field("Signer", signer)
+	printSignature(mf.out, hash, sig)
+	flush(mf.out)
+}
+
+// cmdVerify 验证签名是否属于指定地址（EOA 或 EIP-1271 合约钱包）
+func cmdVerify(args []string) {
+	fs, mf := newFlagSet("verify")
+	sigHex := fs.String("signature", "", "signature hex")
+	addrHex := fs.String("address", "", "expected signer address (EOA or contract wallet)")
+	fs.Parse(args)
+	hash := mf.digest()
+
+	if !common.IsHexAddress(*addrHex) {
+		log.Fatal("missing or invalid --address")
+	}
+	expected := common.HexToAddress(*addrHex)
+	rawSig, err := hexutil.Decode(*sigHex)
+	if err != nil {
+		log.Fatalf("invalid --signature: %v", err)
+	}
+
+	mf.out.Section("Verify")
+	mf.out.Field("Address", expected)
+	mf.out.Field("Digest", hash)
+
+	// 有节点时先检查地址是否为合约；没有节点只能按 EOA 验证
+	rpcURL := config.Get().RPCURL
+	if rpcURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(15*time.Second))
+		defer cancel()
+
+		client, err := rpcretry.Dial(ctx, rpcURL)
+		if err != nil {
+			log.Fatalf("failed to connect to Ethereum node: %v", err)
+		}
+		defer client.Close()
+
+		code, err := client.CodeAt(ctx, expected, nil)
+		if err != nil {
+			log.Fatalf("failed to get code: %v", err)
+		}
+		if len(code) > 0 {
+			valid, err := verifyEIP1271(ctx, client, expected, hash, rawSig)
+			if err != nil {
+				log.Fatalf("EIP-1271 check failed: %v", err)
+			}
+			mf.out.Field("Type", "contract wallet (EIP-1271)")
+			printResult(mf.out, valid)
+			return
+		}
+	} else {
+		log.Printf("[WARN] no RPC URL configured, assuming %s is an EOA", expected.Hex())
+	}
+
+	sig, err := normalizeSignature(rawSig)
+	if err != nil {
+		log.Fatalf("invalid signature: %v", err)
+	}
+	signer, err := recoverSigner(hash, sig)
+	if err != nil {
+		log.Fatalf("failed to recover signer: %v", err)
+	}
+	mf.out.Field("Type", "EOA (ecrecover)")
+	mf.out.Field("Recovered", signer)
+	printNotes(mf.out, sig)
+	printResult(mf.out, signer == expected)
+}
+
+// verifyEIP1271 调用合约钱包的 isValidSignature
+// 调用 revert 或返回值不是魔数都视为无效签名，只有网络错误才返回 error
+func verifyEIP1271(ctx context.Context, client *ethclient.Client, wallet common.Address, hash common.Hash, sig []byte) (bool, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(eip1271ABIJSON))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	data, err := parsedABI.Pack("isValidSignature", hash, sig)
+	if err != nil {
+		return false, fmt.Errorf("failed to pack isValidSignature data: %w", err)
+	}
+	output, err := client.CallContract(ctx, ethereum.CallMsg{To: &wallet, Data: data}, nil)
+	if err != nil {
+		// 执行 revert：签名无效（部分合约钱包对无效签名直接 revert 而不是返回其他值）
+		if errors.Is(rpcerr.Classify(err), rpcerr.ErrExecutionReverted) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to call isValidSignature: %w", err)
+	}
+	// 返回值是 ABI 编码的 bytes4（左对齐到 32 字节）；不实现该方法的合约可能返回空数据
+	if len(output) < 4 {
+		return false, nil
+	}
+	return [4]byte(output[:4]) == eip1271MagicValue, nil
+}
+
+// mustNormalize 解析并规范化签名
+func mustNormalize(sigHex string) *normalizedSig {
+	raw, err := hexutil.Decode(sigHex)
+	if err != nil {
+		log.Fatalf("invalid --signature: %v", err)
+	}
+	sig, err := normalizeSignature(raw)
+	if err != nil {
+		log.Fatalf("invalid signature: %v", err)
+	}
+	return sig
+}
+
+// printSignature 输出签名的各种表示形式
+func printSignature(out *output.Printer, hash common.Hash, sig *normalizedSig) {
+	out.Field("Digest", hash)
+	out.Field("Signature", hexutil.Encode(sig.Wallet()))
+	out.Field("Compact", hexutil.Encode(sig.Compact())) // EIP-2098
+	out.Field("r", fmt.Sprintf("%#066x", sig.R))
+	out.Field("s", fmt.Sprintf("%#066x", sig.S))
+	out.Field("v", sig.V+27)
+	out.Field("Recovery ID", sig.V)
+	if sig.ChainID != nil {
+		out.Field("Chain ID", sig.ChainID) // 从 EIP-155 的 v 中解出
+	}
+	printNotes(out, sig)
+}
+
+// printNotes 输出规范化签名时的提示
+func printNotes(out *output.Printer, sig *normalizedSig) {
+	if len(sig.Notes) > 0 {
+		out.Field("Notes", strings.Join(sig.Notes, "; "))
+	}
+}
+
+// printResult 输出验证结论，签名无效时以状态码 1 退出
+func printResult(out *output.Printer, valid bool) {
+	if valid {
+		out.Field("Result", "VALID")
+		flush(out)
+		return
+	}
+	out.Field("Result", "INVALID")
+	flush(out)
+	os.Exit(1)
+}
+
+// flush 输出结构化格式收集到的结果
+func flush(out *output.Printer) {
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
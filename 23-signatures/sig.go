@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 签名格式说明：
+// - go-ethereum 内部使用 65 字节 [R(32) || S(32) || V(1)]，V 为 recovery id，取值 0 / 1
+// - 钱包（eth_sign / personal_sign）和 Solidity ecrecover 使用 V = 27 / 28
+// - 旧式 EIP-155 交易签名中 V = chainId*2 + 35 / 36
+// - EIP-2098 紧凑签名只有 64 字节：[R(32) || yParity(1 bit) + S(255 bits)]
+// - S 大于 n/2 的"高 S"签名同样有效，但 (r, n-s, v^1) 是同一消息的另一个合法签名（延展性），
+//   EIP-2 之后交易签名必须为低 S，很多合约（如 OpenZeppelin ECDSA）也会拒绝高 S
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// normalizedSig 规范化后的签名
+type normalizedSig struct {
+	R, S    *big.Int
+	V       byte     // recovery id：0 / 1
+	ChainID *big.Int // 从 EIP-155 形式的 V 中推导出的链 ID，其他形式为 nil
+	Notes   []string // 规范化过程中做过的调整
+}
+
+// Bytes 返回 go-ethereum 使用的 65 字节格式（V 为 0 / 1）
+func (s *normalizedSig) Bytes() []byte {
+	out := make([]byte, crypto.SignatureLength)
+	s.R.FillBytes(out[:32])
+	s.S.FillBytes(out[32:64])
+	out[64] = s.V
+	return out
+}
+
+// Wallet 返回钱包 / Solidity ecrecover 使用的格式（V 为 27 / 28）
+func (s *normalizedSig) Wallet() []byte {
+	out := s.Bytes()
+	out[64] += 27
+	return out
+}
+
+// Compact 返回 EIP-2098 的 64 字节紧凑格式
+func (s *normalizedSig) Compact() []byte {
+	out := make([]byte, 64)
+	s.R.FillBytes(out[:32])
+	s.S.FillBytes(out[32:64])
+	if s.V == 1 {
+		out[32] |= 0x80
+	}
+	return out
+}
+
+// normalizeSignature 把 64 / 65 字节签名统一为 V ∈ {0, 1}、低 S 的形式
+func normalizeSignature(sig []byte) (*normalizedSig, error) {
+	n := &normalizedSig{}
+	switch len(sig) {
+	case 64:
+		// EIP-2098：S 的最高位存放 yParity
+		vs := common.CopyBytes(sig[32:])
+		n.V = vs[0] >> 7
+		vs[0] &= 0x7f
+		n.R = new(big.Int).SetBytes(sig[:32])
+		n.S = new(big.Int).SetBytes(vs)
+		n.Notes = append(n.Notes, "expanded EIP-2098 compact signature")
+	case 65:
+		n.R = new(big.Int).SetBytes(sig[:32])
+		n.S = new(big.Int).SetBytes(sig[32:64])
+		v := sig[64]
+		switch {
+		case v == 0 || v == 1:
+			n.V = v
+		case v == 27 || v == 28:
+			n.V = v - 27
+			n.Notes = append(n.Notes, fmt.Sprintf("converted v=%d to recovery id %d", v, n.V))
+		case v >= 35:
+			// 单字节 V 只能表示很小的链 ID（如主网 37 / 38），更大的链 ID 无法放进 65 字节签名
+			n.V = (v - 35) % 2
+			n.ChainID = big.NewInt(int64(v-35) / 2)
+			n.Notes = append(n.Notes, fmt.Sprintf("converted EIP-155 v=%d (chain id %s) to recovery id %d", v, n.ChainID, n.V))
+		default:
+			return nil, fmt.Errorf("invalid v value %d", v)
+		}
+	default:
+		return nil, fmt.Errorf("invalid signature length %d (want 64 or 65 bytes)", len(sig))
+	}
+
+	if n.R.Sign() == 0 || n.R.Cmp(secp256k1N) >= 0 {
+		return nil, fmt.Errorf("r out of range")
+	}
+	if n.S.Sign() == 0 || n.S.Cmp(secp256k1N) >= 0 {
+		return nil, fmt.Errorf("s out of range")
+	}
+	if n.S.Cmp(secp256k1HalfN) > 0 {
+		// 高 S → 低 S：取 n-s 并翻转 recovery id，恢复出的公钥不变
+		n.S = new(big.Int).Sub(secp256k1N, n.S)
+		n.V ^= 1
+		n.Notes = append(n.Notes, "high-s (malleable) signature converted to low-s form")
+	}
+	return n, nil
+}
+
+// recoverSigner 从消息哈希和规范化签名恢复签名者地址
+func recoverSigner(hash common.Hash, sig *normalizedSig) (common.Address, error) {
+	pub, err := crypto.SigToPub(hash.Bytes(), sig.Bytes())
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover public key: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,299 @@
+// Package gasoracle 基于 eth_feeHistory 的 EIP-1559 费用预估：
+// 根据最近 N 个区块的优先费分位数给出 slow / standard / fast 三档 tip，
+// 并按各档愿意等待的区块数预估 base fee 上限，得到 maxFeePerGas。
+//
+// 与 "base fee × 2 + SuggestGasTipCap" 的简单策略相比：
+// - tip 来自真实成交交易的分位数，而不是节点单一的建议值
+// - fee cap 与速度挂钩：fast 能扛住更长时间的 base fee 上涨，slow 则不为不需要的余量锁定资金
+// - 使用 feeHistory 返回的"下一个区块" base fee，而不是最新区块的 base fee
+package gasoracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// Speed 费用档位
+type Speed string
+
+const (
+	Slow     Speed = "slow"
+	Standard Speed = "standard"
+	Fast     Speed = "fast"
+)
+
+// ParseSpeed 解析档位名称
+func ParseSpeed(s string) (Speed, error) {
+	switch Speed(s) {
+	case Slow, Standard, Fast:
+		return Speed(s), nil
+	}
+	return "", fmt.Errorf("unknown speed %q (slow | standard | fast)", s)
+}
+
+// Backend 费用预估需要的节点能力，*ethclient.Client 满足该接口
+type Backend interface {
+	ethereum.FeeHistoryReader
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// Config 预估参数
+type Config struct {
+	Blocks uint64 // 采样的区块数
+
+	// 各档位使用的优先费分位数（0-100）
+	SlowPercentile     float64
+	StandardPercentile float64
+	FastPercentile     float64
+
+	// 各档位 fee cap 需要覆盖的区块数：base fee 每个满区块最多上涨 12.5%，
+	// 覆盖 k 个区块即 fee cap 按 nextBaseFee × 1.125^k 计算
+	SlowHorizon     int
+	StandardHorizon int
+	FastHorizon     int
+
+	MinTip *big.Int // tip 下限，防止空闲链上分位数为 0 导致交易没有激励
+}
+
+// DefaultConfig 默认参数：20 个区块，10 / 50 / 90 分位；standard 覆盖 6 个区块（≈ base fee × 2）
+func DefaultConfig() Config {
+	return Config{
+		Blocks:             20,
+		SlowPercentile:     10,
+		StandardPercentile: 50,
+		FastPercentile:     90,
+		SlowHorizon:        2,
+		StandardHorizon:    6,
+		FastHorizon:        8,
+		MinTip:             big.NewInt(1e6), // 0.001 gwei
+	}
+}
+
+// Recommendation 单个档位的建议费用
+type Recommendation struct {
+	Speed     Speed    `json:"speed"`
+	TipCap    *big.Int `json:"maxPriorityFeePerGas"`
+	FeeCap    *big.Int `json:"maxFeePerGas"`
+	BaseFeeAt *big.Int `json:"baseFeeCeiling"` // fee cap 中预留的 base fee 上限
+}
+
+// Estimate 一次预估的结果
+type Estimate struct {
+	BlockNumber  uint64            `json:"blockNumber"`
+	BaseFee      *big.Int          `json:"baseFee"`     // 最新区块 base fee
+	NextBaseFee  *big.Int          `json:"nextBaseFee"` // 下一个区块 base fee（由协议确定）
+	GasUsedRatio float64           `json:"gasUsedRatio"`
+	Trend        string            `json:"trend"` // rising | falling | stable
+	Legacy       bool              `json:"legacy"`
+	Fees         []*Recommendation `json:"recommendations"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+}
+
+// Recommendation 返回指定档位的建议；未知档位返回 standard
+func (e *Estimate) Recommendation(speed Speed) *Recommendation {
+	for _, r := range e.Fees {
+		if r.Speed == speed {
+			return r
+		}
+	}
+	for _, r := range e.Fees {
+		if r.Speed == Standard {
+			return r
+		}
+	}
+	return nil
+}
+
+// FeeFunc 返回按档位取 tip cap / fee cap 的函数，每次调用重新预估，可直接用作 txbuilder 的费用策略
+func FeeFunc(backend Backend, cfg Config, speed Speed) func(ctx context.Context) (tipCap, feeCap *big.Int, err error) {
+	return func(ctx context.Context) (*big.Int, *big.Int, error) {
+		est, err := Compute(ctx, backend, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		rec := est.Recommendation(speed)
+		return rec.TipCap, rec.FeeCap, nil
+	}
+}
+
+// Compute 执行一次费用预估
+func Compute(ctx context.Context, backend Backend, cfg Config) (*Estimate, error) {
+	if cfg.Blocks == 0 {
+		return nil, errors.New("gasoracle: Blocks must be positive")
+	}
+	percentiles := []float64{cfg.SlowPercentile, cfg.StandardPercentile, cfg.FastPercentile}
+	hist, err := backend.FeeHistory(ctx, cfg.Blocks, nil, percentiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	if len(hist.BaseFee) < 2 || len(hist.GasUsedRatio) == 0 {
+		return nil, errors.New("gasoracle: empty fee history")
+	}
+
+	n := len(hist.GasUsedRatio)
+	est := &Estimate{
+		BlockNumber:  hist.OldestBlock.Uint64() + uint64(n) - 1,
+		BaseFee:      hist.BaseFee[n-1],
+		NextBaseFee:  hist.BaseFee[n],
+		GasUsedRatio: hist.GasUsedRatio[n-1],
+		Trend:        trend(hist.GasUsedRatio),
+		UpdatedAt:    time.Now(),
+	}
+
+	// 不支持 EIP-1559 的链 base fee 为 0：与 03 / 08 相同，退回到 gasPrice
+	if est.NextBaseFee == nil || est.NextBaseFee.Sign() == 0 {
+		gasPrice, err := backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get gas price: %w", err)
+		}
+		est.Legacy = true
+		est.BaseFee, est.NextBaseFee = gasPrice, gasPrice
+	}
+
+	tips := tipPercentiles(hist, len(percentiles))
+	// 所有采样区块都没有交易（或节点不返回 reward）时，使用节点建议值
+	if tips == nil {
+		tip, err := backend.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get gas tip cap: %w", err)
+		}
+		tips = []*big.Int{tip, tip, tip}
+	}
+
+	horizons := []int{cfg.SlowHorizon, cfg.StandardHorizon, cfg.FastHorizon}
+	for i, speed := range []Speed{Slow, Standard, Fast} {
+		tip := tips[i]
+		if cfg.MinTip != nil && tip.Cmp(cfg.MinTip) < 0 {
+			tip = new(big.Int).Set(cfg.MinTip)
+		}
+		ceiling := baseFeeCeiling(est.NextBaseFee, horizons[i])
+		est.Fees = append(est.Fees, &Recommendation{
+			Speed:     speed,
+			TipCap:    tip,
+			FeeCap:    new(big.Int).Add(ceiling, tip),
+			BaseFeeAt: ceiling,
+		})
+	}
+	// 保证档位单调：slow <= standard <= fast
+	for i := 1; i < len(est.Fees); i++ {
+		prev, cur := est.Fees[i-1], est.Fees[i]
+		if cur.TipCap.Cmp(prev.TipCap) < 0 {
+			cur.FeeCap = new(big.Int).Add(cur.BaseFeeAt, prev.TipCap)
+			cur.TipCap = prev.TipCap
+		}
+	}
+	return est, nil
+}
+
+// tipPercentiles 对每个分位数取各区块值的中位数；跳过没有交易的区块（reward 全为 0 且 gasUsedRatio 为 0）
+func tipPercentiles(hist *ethereum.FeeHistory, count int) []*big.Int {
+	samples := make([][]*big.Int, count)
+	for b, rewards := range hist.Reward {
+		if len(rewards) != count || (b < len(hist.GasUsedRatio) && hist.GasUsedRatio[b] == 0) {
+			continue
+		}
+		for k, r := range rewards {
+			samples[k] = append(samples[k], r)
+		}
+	}
+	if len(samples[0]) == 0 {
+		return nil
+	}
+	out := make([]*big.Int, count)
+	for k, values := range samples {
+		sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+		out[k] = new(big.Int).Set(values[len(values)/2])
+	}
+	return out
+}
+
+// baseFeeCeiling base fee 连续 blocks 个满区块后的上限：nextBaseFee × (9/8)^blocks
+func baseFeeCeiling(nextBaseFee *big.Int, blocks int) *big.Int {
+	v := new(big.Int).Set(nextBaseFee)
+	for i := 0; i < blocks; i++ {
+		v.Mul(v, big.NewInt(9))
+		v.Div(v, big.NewInt(8))
+	}
+	return v
+}
+
+// trend 根据最近区块的平均利用率判断 base fee 走势（目标利用率为 50%）
+func trend(ratios []float64) string {
+	recent := ratios
+	if len(recent) > 5 {
+		recent = recent[len(recent)-5:]
+	}
+	var sum float64
+	for _, r := range recent {
+		sum += r
+	}
+	avg := sum / float64(len(recent))
+	switch {
+	case avg > 0.55:
+		return "rising"
+	case avg < 0.45:
+		return "falling"
+	}
+	return "stable"
+}
+
+// Oracle 后台定期刷新的费用预估，Latest 可并发调用
+type Oracle struct {
+	backend Backend
+	cfg     Config
+
+	mu     sync.RWMutex
+	latest *Estimate
+	err    error
+}
+
+// New 创建 Oracle，需要调用 Run 开始采样
+func New(backend Backend, cfg Config) *Oracle {
+	return &Oracle{backend: backend, cfg: cfg}
+}
+
+// Run 每隔 interval 刷新一次，直到 ctx 取消；onUpdate 可为 nil
+func (o *Oracle) Run(ctx context.Context, interval time.Duration, onUpdate func(*Estimate, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		o.refresh(ctx, onUpdate)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Oracle) refresh(ctx context.Context, onUpdate func(*Estimate, error)) {
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	est, err := Compute(reqCtx, o.backend, o.cfg)
+	cancel()
+
+	o.mu.Lock()
+	if err == nil {
+		o.latest = est
+	}
+	o.err = err
+	o.mu.Unlock()
+
+	if onUpdate != nil {
+		onUpdate(est, err)
+	}
+}
+
+// Latest 返回最近一次成功的预估以及最近一次刷新的错误（刷新失败时仍返回上一次的结果）
+func (o *Oracle) Latest() (*Estimate, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.latest, o.err
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/yzucdh1/examples/24-gas-tracker/gasoracle"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 24-gas-tracker
+// Gas 费用追踪服务：
+// - 定期调用 eth_feeHistory 采样最近 N 个区块的 base fee、利用率和优先费分位数（算法见 gasoracle 包）
+// - 输出 slow / standard / fast 三档 maxPriorityFeePerGas 与 maxFeePerGas 建议
+// - 通过 HTTP 提供查询：GET /fees 返回全部档位，GET /fees?speed=fast 返回单个档位，GET /healthz 健康检查
+// - gasoracle 包可以被其他示例直接导入（03-tx-ops、08-contract-interact 的 --speed 参数）
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://mainnet.infura.io/v3/<project-id>"
+//    go run . --listen :8080 --interval 12s
+//    go run . --once                       # 只输出一次预估后退出
+//    go run . --once --format json         # 输出格式：pretty（默认）| json | yaml | csv
+//    curl -s localhost:8080/fees | jq .
+//
+// 注意事项：
+// - 分位数只反映已打包交易付出的 tip，交易池拥堵时 fast 档位可能仍偏低
+// - 部分 L2 的 base fee 极低且几乎不变，tip 分位数基本为 0，此时以 MinTip 为下限
+
+func main() {
+	listen := flag.String("listen", ":8080", "HTTP listen address")
+	interval := flag.Duration("interval", 12*time.Second, "sampling interval")
+	blocks := flag.Uint64("blocks", 20, "number of recent blocks to sample")
+	once := flag.Bool("once", false, "print one estimate and exit")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	cfg := gasoracle.DefaultConfig()
+	cfg.Blocks = *blocks
+
+	if *once {
+		reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		est, err := gasoracle.Compute(reqCtx, client, cfg)
+		if err != nil {
+			log.Fatalf("failed to estimate fees: %v", err)
+		}
+		printEstimate(out, est)
+		if err := out.Flush(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	oracle := gasoracle.New(client, cfg)
+	go oracle.Run(ctx, *interval, func(est *gasoracle.Estimate, err error) {
+		if err != nil {
+			log.Printf("[WARN] fee estimate failed: %v", err)
+			return
+		}
+		r := est.Recommendation(gasoracle.Standard)
+		log.Printf("block %d base fee %s gwei (next %s, %s), standard tip %s / cap %s gwei",
+			est.BlockNumber, toGwei(est.BaseFee), toGwei(est.NextBaseFee), est.Trend, toGwei(r.TipCap), toGwei(r.FeeCap))
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fees", func(w http.ResponseWriter, r *http.Request) {
+		handleFees(w, r, oracle)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		est, err := oracle.Latest()
+		// 超过 3 个采样周期没有成功刷新视为不健康
+		if est == nil || time.Since(est.UpdatedAt) > 3**interval {
+			http.Error(w, fmt.Sprintf("stale estimate: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+
+	srv := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("gas tracker listening on %s", *listen)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("http server failed: %v", err)
+	}
+}
+
+// handleFees 返回最新预估；带 speed 参数时只返回对应档位
+func handleFees(w http.ResponseWriter, r *http.Request, oracle *gasoracle.Oracle) {
+	est, _ := oracle.Latest()
+	if est == nil {
+		http.Error(w, "no estimate yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	var body interface{} = est
+	if s := r.URL.Query().Get("speed"); s != "" {
+		speed, err := gasoracle.ParseSpeed(s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = est.Recommendation(speed)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(body); err != nil {
+		log.Printf("[WARN] failed to write response: %v", err)
+	}
+}
+
+// printEstimate 输出一次预估结果
+func printEstimate(out *output.Printer, est *gasoracle.Estimate) {
+	out.Section("Gas Estimate")
+	out.Field("Block", est.BlockNumber)
+	out.Field("Base Fee", toGwei(est.BaseFee)+" gwei")
+	out.Field("Next Base", toGwei(est.NextBaseFee)+" gwei")
+	out.Field("Trend", est.Trend)
+	out.Field("Last Block", fmt.Sprintf("%.0f%% full", est.GasUsedRatio*100))
+	if est.Legacy {
+		out.Note("Note        : chain has no base fee, using gas price")
+	}
+
+	out.Table("Recommendations", "Speed", "Tip Cap (gwei)", "Max Fee (gwei)")
+	for _, r := range est.Fees {
+		out.Row(r.Speed, toGwei(r.TipCap), toGwei(r.FeeCap))
+	}
+}
+
+// toGwei wei 转 gwei 字符串
+func toGwei(wei *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.GWei)).Text('f', 3)
+}
@@ -0,0 +1,67 @@
+package chaintracker
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Backend Follow 需要的节点能力，*ethclient.Client（WebSocket / IPC 连接）满足该接口
+type Backend interface {
+	HeaderSource
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// Follow 订阅新区块头并把规范链事件依次交给 handler，直到 ctx 取消或订阅出错。
+// 订阅建立后先处理一次最新区块头：重连时断线期间漏掉的区块会被补齐，
+// 因此调用方只需在 Follow 返回错误后重新连接节点、用同一个 Tracker 再次调用 Follow
+func (t *Tracker) Follow(ctx context.Context, backend Backend, handler func(Event)) error {
+	// 补齐缺失区块时使用当前连接
+	t.mu.Lock()
+	t.src = backend
+	t.mu.Unlock()
+
+	headers := make(chan *types.Header, 16)
+	sub, err := backend.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe new heads: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	latest, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get latest header: %w", err)
+	}
+	if err := t.apply(ctx, latest, handler); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case h := <-headers:
+			if err := t.apply(ctx, h, handler); err != nil {
+				return err
+			}
+		case err := <-sub.Err():
+			return fmt.Errorf("subscription error: %w", err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// apply 处理一个区块头并分发事件
+func (t *Tracker) apply(ctx context.Context, h *types.Header, handler func(Event)) error {
+	events, err := t.AddHeader(ctx, h)
+	if err != nil {
+		return err
+	}
+	for _, ev := range events {
+		handler(ev)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Package chaintracker 根据节点推送的新区块头维护最近 N 个区块的区块树和规范链，
+// 把"新区块头"这一原始信号转换为显式的事件流：
+// - BlockAdded：区块成为规范链的一部分（按高度升序发出，中间漏掉的区块会自动补齐）
+// - BlockRemoved：区块因重组离开规范链（按高度降序发出，先于新分支的 BlockAdded）
+//
+// 消费者只要按顺序应用这两种事件（添加 / 回滚），状态就始终与节点的规范链一致，
+// 不需要自己处理漏块、重复推送和重组。
+package chaintracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// EventType 事件类型
+type EventType int
+
+const (
+	BlockAdded EventType = iota
+	BlockRemoved
+)
+
+func (t EventType) String() string {
+	if t == BlockRemoved {
+		return "removed"
+	}
+	return "added"
+}
+
+// Event 规范链变化事件
+type Event struct {
+	Type   EventType
+	Header *types.Header
+	// Reorg 为 true 表示该事件属于一次重组（BlockRemoved 以及随后新分支上的 BlockAdded）
+	Reorg bool
+}
+
+// HeaderSource 补齐缺失区块时使用，*ethclient.Client 满足该接口
+type HeaderSource interface {
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// Tracker 区块树与规范链；所有方法可并发调用
+type Tracker struct {
+	src   HeaderSource
+	depth uint64 // 保留的区块数，也是能处理的最大重组深度
+
+	mu      sync.RWMutex
+	headers map[common.Hash]*types.Header // 区块树：规范链 + 最近见过的分叉区块
+	canon   map[uint64]common.Hash        // 高度 -> 规范链区块哈希
+	head    *types.Header
+}
+
+// New 创建 Tracker，depth 为保留的区块数（建议不小于 64，即两个 epoch）；
+// 只通过 Follow 使用时 src 可以为 nil，Follow 会使用当前连接补齐区块
+func New(src HeaderSource, depth uint64) *Tracker {
+	if depth == 0 {
+		depth = 64
+	}
+	return &Tracker{
+		src:     src,
+		depth:   depth,
+		headers: make(map[common.Hash]*types.Header),
+		canon:   make(map[uint64]common.Hash),
+	}
+}
+
+// Head 当前规范链头，尚未收到区块时为 nil
+func (t *Tracker) Head() *types.Header {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.head
+}
+
+// Canonical 返回指定高度的规范链区块哈希（只在保留窗口内可查）
+func (t *Tracker) Canonical(number uint64) (common.Hash, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	hash, ok := t.canon[number]
+	return hash, ok
+}
+
+// AddHeader 处理节点推送的新区块头：节点推送的区块总是它当前的规范链头，
+// 因此无论高度比当前链头高还是低，都把它作为新的链头
+func (t *Tracker) AddHeader(ctx context.Context, h *types.Header) ([]Event, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.head != nil && t.head.Hash() == h.Hash() {
+		return nil, nil // 重复推送
+	}
+
+	// 向前补齐父区块，直到连接上已知的区块树；第一个区块不补齐。
+	// 高于当前链头的部分是漏掉的区块（如断线期间），必须全部补齐；
+	// 低于链头后最多再回溯 depth 个区块，仍连接不上说明重组深度超过了保留窗口
+	branch := []*types.Header{h}
+	for cur := h; t.head != nil && cur.Number.Sign() > 0; {
+		if _, ok := t.headers[cur.ParentHash]; ok {
+			break
+		}
+		if uint64(len(branch)) > t.depth && cur.Number.Cmp(t.head.Number) <= 0 {
+			break
+		}
+		parent, err := t.src.HeaderByHash(ctx, cur.ParentHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent %s of block %d: %w", cur.ParentHash.Hex(), cur.Number.Uint64(), err)
+		}
+		branch = append(branch, parent)
+		cur = parent
+	}
+	for _, hdr := range branch {
+		t.headers[hdr.Hash()] = hdr
+	}
+
+	// 从新链头沿父哈希回溯，找到与当前规范链的共同祖先
+	var newPath []*types.Header
+	var ancestor *types.Header
+	for cur := h; cur != nil; cur = t.headers[cur.ParentHash] {
+		if t.canon[cur.Number.Uint64()] == cur.Hash() {
+			ancestor = cur
+			break
+		}
+		newPath = append(newPath, cur)
+	}
+
+	var events []Event
+	// 回滚：当前规范链上高于共同祖先的区块全部移除（降序）。
+	// 找不到共同祖先（重组深度超过保留窗口）时整条已知规范链都被移除
+	if t.head != nil {
+		from := uint64(0)
+		if ancestor != nil {
+			from = ancestor.Number.Uint64() + 1
+		}
+		for n := t.head.Number.Uint64(); n >= from; n-- {
+			hash, ok := t.canon[n]
+			if !ok {
+				break
+			}
+			events = append(events, Event{Type: BlockRemoved, Header: t.headers[hash], Reorg: true})
+			delete(t.canon, n)
+			if n == 0 {
+				break
+			}
+		}
+	}
+	reorg := len(events) > 0
+
+	// 新分支按升序加入规范链
+	for i := len(newPath) - 1; i >= 0; i-- {
+		hdr := newPath[i]
+		t.canon[hdr.Number.Uint64()] = hdr.Hash()
+		events = append(events, Event{Type: BlockAdded, Header: hdr, Reorg: reorg})
+	}
+	t.head = h
+	t.prune()
+	return events, nil
+}
+
+// prune 删除保留窗口之外的区块
+func (t *Tracker) prune() {
+	headNum := t.head.Number.Uint64()
+	if headNum < t.depth {
+		return
+	}
+	limit := headNum - t.depth
+	for hash, hdr := range t.headers {
+		if hdr.Number.Uint64() < limit {
+			delete(t.headers, hash)
+		}
+	}
+	for n := range t.canon {
+		if n < limit || n > headNum {
+			delete(t.canon, n)
+		}
+	}
+}
+
+// Confirmations 区块在当前规范链上的确认数（链头为 1）；不在规范链上返回 0
+func (t *Tracker) Confirmations(hash common.Hash) uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	hdr, ok := t.headers[hash]
+	if !ok || t.head == nil || t.canon[hdr.Number.Uint64()] != hash {
+		return 0
+	}
+	return t.head.Number.Uint64() - hdr.Number.Uint64() + 1
+}
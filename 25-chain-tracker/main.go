@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/yzucdh1/examples/25-chain-tracker/chaintracker"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 25-chain-tracker
+// 规范链追踪服务：05 / 07 中的订阅示例直接打印收到的区块头，无法处理漏块和重组；
+// 这里由 chaintracker 包维护最近 N 个区块的区块树，把新区块头转换为显式事件：
+// - "+ added"：区块进入规范链（漏掉的区块自动补齐，按高度升序）
+// - "- removed"：区块因重组离开规范链（按高度降序，先于新分支的 added）
+// - --confirmations N：区块在规范链上达到 N 个确认时额外输出一次 "✓ confirmed"
+// 订阅断开后按指数退避重连（与 07-reconnect-strategy 相同），重连后自动补齐断线期间的区块
+//
+// 执行示例：
+//    export ETH_WS_URL="wss://mainnet.infura.io/ws/v3/<project-id>"
+//    go run .
+//    go run . --depth 128 --confirmations 12
+//    go run . --format json | jq -c 'select(.type == "removed")'   # 每个事件输出一行 JSON
+//
+// 注意事项：
+// - 需要 WebSocket / IPC 连接（eth_subscribe），HTTP 连接无法订阅
+// - 重组深度超过 --depth 时无法找到共同祖先，整条已知规范链都会以 removed 事件回滚
+
+func main() {
+	depth := flag.Uint64("depth", 64, "number of recent blocks kept in the tree (max reorg depth)")
+	confirmations := flag.Uint64("confirmations", 0, "emit a confirmed event when a block reaches this many confirmations (0 = off)")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	rpcURL := config.Get().WSURL
+	if rpcURL == "" {
+		rpcURL = config.Get().RPCURL
+	}
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tracker := chaintracker.New(nil, *depth)
+	handler := func(ev chaintracker.Event) {
+		printEvent(out, ev)
+		if *confirmations == 0 || ev.Type != chaintracker.BlockAdded {
+			return
+		}
+		// 新区块加入后，高度为 head-(N-1) 的规范链区块恰好达到 N 个确认
+		head := ev.Header.Number.Uint64()
+		if head+1 < *confirmations {
+			return
+		}
+		target := head + 1 - *confirmations
+		if hash, ok := tracker.Canonical(target); ok {
+			out.Note("✓ confirmed  #%d %s (%d confirmations)", target, hash.Hex(), *confirmations)
+			writeRecord(out, "confirmed", "number", target, "hash", hash, "confirmations", *confirmations)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		client, err := rpcretry.Dial(ctx, rpcURL)
+		if err == nil {
+			log.Printf("connected to %s", rpcURL)
+			started := time.Now()
+			err = tracker.Follow(ctx, client, handler)
+			client.Close()
+			// 正常运行过一段时间后才断开，重置退避计数
+			if time.Since(started) > time.Minute {
+				attempt = 1
+			}
+		}
+		if ctx.Err() != nil {
+			out.Note("shutting down")
+			return
+		}
+		log.Printf("[WARN] tracker stopped: %v", err)
+		sleepWithBackoff(ctx, attempt)
+	}
+}
+
+// printEvent 输出规范链事件
+func printEvent(out *output.Printer, ev chaintracker.Event) {
+	h := ev.Header
+	switch ev.Type {
+	case chaintracker.BlockRemoved:
+		out.Note("- removed    #%d %s", h.Number.Uint64(), h.Hash().Hex())
+		writeRecord(out, "removed", "number", h.Number, "hash", h.Hash())
+	case chaintracker.BlockAdded:
+		suffix := ""
+		if ev.Reorg {
+			suffix = " (reorg)"
+		}
+		out.Note("+ added      #%d %s parent=%s txs_root=%s%s",
+			h.Number.Uint64(), h.Hash().Hex(), h.ParentHash.TerminalString(), h.TxHash.TerminalString(), suffix)
+		writeRecord(out, "added", "number", h.Number, "hash", h.Hash(), "parent", h.ParentHash, "txs_root", h.TxHash, "reorg", ev.Reorg)
+	}
+}
+
+// writeRecord 在结构化格式下输出一条事件记录
+func writeRecord(out *output.Printer, kind string, kv ...any) {
+	if err := out.Record(kind, kv...); err != nil {
+		log.Printf("[WARN] failed to write record: %v", err)
+	}
+}
+
+// sleepWithBackoff 指数退避，最大 1 分钟
+func sleepWithBackoff(ctx context.Context, attempt int) {
+	d := time.Duration(math.Min(60, math.Pow(2, float64(attempt)))) * time.Second
+	log.Printf("will retry in %s", d)
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// 26-rlp-decoder
+// 离线解码原始十六进制数据（不连接节点、不广播任何内容）：
+// - tx：交易的规范编码（eth_getRawTransactionByHash / eth_sendRawTransaction 的参数），
+//   支持 legacy、0x01 access list、0x02 EIP-1559、0x03 blob（含带 sidecar 的网络格式）、0x04 set-code（EIP-7702），
+//   输出全部字段并恢复发送者；set-code 交易还会恢复每个授权的签名者
+// - receipt：回执的共识编码（status / cumulativeGasUsed / bloom / logs）
+// - header：区块头 RLP（debug_getRawHeader），输出全部字段并校验哈希
+// - rlp：任意 RLP 数据，按树形结构输出每个元素
+// 默认 --kind auto：按 tx → header → receipt → rlp 的顺序尝试
+//
+// 执行示例：
+//    go run . 0x02f8...
+//    go run . --kind header @header.hex
+//    cast tx 0x<hash> --raw | go run . -
+//    go run . --kind rlp 0xc88363617483646f67
+//    go run . --format json 0x02f8...     # 输出格式：pretty（默认）| json | yaml | csv
+//
+// 注意事项：
+// - auto 模式下，同一段数据可能恰好能按多种格式解码（例如类型字节相同的 typed tx 和 typed receipt），有疑问时请显式指定 --kind
+// - 发送者恢复只依赖签名，不检查 nonce / 余额，不代表交易能被打包
+
+func main() {
+	kind := flag.String("kind", "auto", "data kind: auto | tx | receipt | header | rlp")
+	out := output.AddFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go run . [--kind auto|tx|receipt|header|rlp] [--format pretty|json|yaml|csv] <0xhex | @file | ->")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := readInput(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("failed to read input: %v", err)
+	}
+	if len(data) == 0 {
+		log.Fatal("empty input")
+	}
+
+	var print printFunc
+	switch *kind {
+	case "auto":
+		print = decodeAuto(out, data)
+	case "tx":
+		print = mustDecode(decodeTx(data))
+	case "receipt":
+		print = mustDecode(decodeReceipt(data))
+	case "header":
+		print = mustDecode(decodeHeader(data))
+	case "rlp":
+		print = mustDecode(decodeRLP(data))
+	default:
+		log.Fatalf("unknown --kind %q", *kind)
+	}
+	print(out)
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printFunc 输出解码结果
+type printFunc func(out *output.Printer)
+
+// readInput 读取十六进制输入：直接参数、@文件 或 -（标准输入）
+func readInput(arg string) ([]byte, error) {
+	var text string
+	switch {
+	case arg == "-":
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	case strings.HasPrefix(arg, "@"):
+		b, err := os.ReadFile(strings.TrimPrefix(arg, "@"))
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	default:
+		text = arg
+	}
+	// 兼容从浏览器 / 日志复制时带的引号和空白
+	text = strings.Trim(strings.TrimSpace(text), `"'`)
+	if !strings.HasPrefix(text, "0x") && !strings.HasPrefix(text, "0X") {
+		text = "0x" + text
+	}
+	return hexutil.Decode(strings.ToLower(text))
+}
+
+// decodeAuto 依次尝试各种格式，使用第一个能完整解码的
+func decodeAuto(out *output.Printer, data []byte) printFunc {
+	decoders := []struct {
+		name string
+		fn   func([]byte) (printFunc, error)
+	}{
+		{"tx", decodeTx},
+		{"header", decodeHeader},
+		{"receipt", decodeReceipt},
+		{"rlp", decodeRLP},
+	}
+	var errs []string
+	for _, d := range decoders {
+		print, err := d.fn(data)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", d.name, err))
+			continue
+		}
+		out.Note("(detected: %s)", d.name)
+		return print
+	}
+	log.Fatalf("could not decode input:\n  %s", strings.Join(errs, "\n  "))
+	return nil
+}
+
+// mustDecode 返回解码结果的输出函数，解码失败时退出
+func mustDecode(print printFunc, err error) printFunc {
+	if err != nil {
+		log.Fatalf("failed to decode: %v", err)
+	}
+	return print
+}
+
+// decodeTx 解码交易；返回输出函数，便于 auto 模式先确认解码成功再输出
+func decodeTx(data []byte) (printFunc, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return func(out *output.Printer) { printTx(out, tx) }, nil
+}
+
+// decodeReceipt 解码回执（共识编码）
+func decodeReceipt(data []byte) (printFunc, error) {
+	r := new(types.Receipt)
+	if err := r.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return func(out *output.Printer) { printReceipt(out, r) }, nil
+}
+
+// decodeHeader 解码区块头；要求数据被完整消费，避免把其他列表误判为区块头
+func decodeHeader(data []byte) (printFunc, error) {
+	h := new(types.Header)
+	s := rlp.NewStream(bytes.NewReader(data), uint64(len(data)))
+	if err := s.Decode(h); err != nil {
+		return nil, err
+	}
+	if _, _, err := s.Kind(); err != io.EOF {
+		return nil, fmt.Errorf("trailing data after header")
+	}
+	return func(out *output.Printer) { printHeader(out, h, data) }, nil
+}
+
+// decodeRLP 通用 RLP 解码
+func decodeRLP(data []byte) (printFunc, error) {
+	if err := validateRLP(data); err != nil {
+		return nil, err
+	}
+	return func(out *output.Printer) {
+		out.Section("RLP")
+		if out.Pretty() {
+			printRLP(out, data, 0)
+			return
+		}
+		// 结构化格式下列表为数组、字符串为十六进制
+		out.Field("Value", rlpValue(data))
+	}, nil
+}
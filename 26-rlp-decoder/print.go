@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+var txTypeNames = map[uint8]string{
+	types.LegacyTxType:     "legacy",
+	types.AccessListTxType: "access list (EIP-2930)",
+	types.DynamicFeeTxType: "dynamic fee (EIP-1559)",
+	types.BlobTxType:       "blob (EIP-4844)",
+	types.SetCodeTxType:    "set code (EIP-7702)",
+}
+
+// printTx 输出交易全部字段并恢复发送者
+func printTx(out *output.Printer, tx *types.Transaction) {
+	out.Section("Transaction")
+	out.Field("Type", fmt.Sprintf("%d (%s)", tx.Type(), txTypeNames[tx.Type()]))
+	out.Field("Hash", tx.Hash())
+	if tx.Protected() || tx.Type() != types.LegacyTxType {
+		out.Field("Chain ID", tx.ChainId())
+	} else {
+		out.Field("Chain ID", "(none, pre-EIP-155 legacy tx)")
+	}
+	out.Field("Nonce", tx.Nonce())
+	if tx.To() != nil {
+		out.Field("To", tx.To())
+	} else {
+		out.Field("To", "(contract creation)")
+	}
+	out.Field("Value (wei)", tx.Value())
+	out.Field("Gas Limit", tx.Gas())
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		out.Field("Gas Price", tx.GasPrice())
+	default:
+		out.Field("Max Tip", tx.GasTipCap())
+		out.Field("Max Fee", tx.GasFeeCap())
+	}
+	out.Field("Data", shortHex(tx.Data()))
+
+	if tx.Type() == types.BlobTxType {
+		out.Field("Blob Fee Cap", tx.BlobGasFeeCap())
+		out.Field("Blob Gas", tx.BlobGas())
+		for i, h := range tx.BlobHashes() {
+			out.Field(fmt.Sprintf("Blob Hash %d", i), h)
+		}
+		if sc := tx.BlobTxSidecar(); sc != nil {
+			out.Field("Sidecar", fmt.Sprintf("version %d, %d blobs, %d commitments, %d proofs",
+				sc.Version, len(sc.Blobs), len(sc.Commitments), len(sc.Proofs)))
+			if err := sc.ValidateBlobCommitmentHashes(tx.BlobHashes()); err != nil {
+				out.Field("Sidecar Error", fmt.Sprintf("INVALID (%v)", err))
+			}
+		} else {
+			out.Field("Sidecar", "(none, canonical form without blobs)")
+		}
+	}
+
+	v, r, s := tx.RawSignatureValues()
+	out.Field("v", v)
+	out.Field("r", fmt.Sprintf("%#x", r))
+	out.Field("s", fmt.Sprintf("%#x", s))
+
+	// 按交易自身的类型和链 ID 选择 signer；无链 ID 的 legacy 交易使用 Homestead 规则
+	var signer types.Signer
+	if tx.Type() == types.LegacyTxType && !tx.Protected() {
+		signer = types.HomesteadSigner{}
+	} else {
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	}
+	out.Field("Signing Hash", signer.Hash(tx))
+	if from, err := types.Sender(signer, tx); err == nil {
+		out.Field("From", from)
+		if tx.To() == nil {
+			out.Field("Contract", crypto.CreateAddress(from, tx.Nonce()))
+		}
+	} else {
+		out.Field("From", fmt.Sprintf("(failed to recover: %v)", err))
+	}
+
+	if al := tx.AccessList(); len(al) > 0 {
+		out.Table("Access List", "Address", "Storage Keys")
+		for _, t := range al {
+			out.Row(t.Address, t.StorageKeys)
+		}
+	}
+
+	if auths := tx.SetCodeAuthorizations(); len(auths) > 0 {
+		out.Table("Authorizations", "#", "Chain ID", "Address", "Nonce", "Authority")
+		for i, a := range auths {
+			authority := "invalid signature"
+			if addr, err := a.Authority(); err == nil {
+				authority = addr.Hex()
+			}
+			out.Row(i, a.ChainID.Dec(), a.Address, a.Nonce, authority)
+		}
+	}
+}
+
+// printReceipt 输出回执（共识编码中只包含这些字段，txHash / gasUsed 等需要节点补充）
+func printReceipt(out *output.Printer, r *types.Receipt) {
+	out.Section("Receipt")
+	out.Field("Type", fmt.Sprintf("%d (%s)", r.Type, txTypeNames[r.Type]))
+	if len(r.PostState) > 0 {
+		// pre-Byzantium 回执使用 post state 而不是 status
+		out.Field("Post State", hexutil.Encode(r.PostState))
+	} else {
+		out.Field("Status", r.Status)
+	}
+	out.Field("Cumulative", r.CumulativeGasUsed)
+	out.Field("Bloom", shortHex(r.Bloom.Bytes()))
+	// 校验 bloom 是否与日志一致
+	out.Field("Bloom Valid", types.CreateBloom(r) == r.Bloom)
+
+	if len(r.Logs) > 0 {
+		out.Table("Logs", "#", "Address", "Topics", "Data")
+		for i, l := range r.Logs {
+			out.Row(i, l.Address, l.Topics, shortHex(l.Data))
+		}
+	}
+}
+
+// printHeader 输出区块头全部字段
+func printHeader(out *output.Printer, h *types.Header, raw []byte) {
+	out.Section("Header")
+	out.Field("Hash", h.Hash())
+	// 重新编码后的哈希与输入的 keccak256 不一致，说明输入不是规范编码（或包含本版本未知的字段）
+	if crypto.Keccak256Hash(raw) != h.Hash() {
+		out.Field("Input Hash", crypto.Keccak256Hash(raw))
+		out.Note("Note        : keccak256(input) differs from re-encoded hash")
+	}
+	out.Field("Parent", h.ParentHash)
+	out.Field("Uncle Hash", h.UncleHash)
+	out.Field("Coinbase", h.Coinbase)
+	out.Field("State Root", h.Root)
+	out.Field("Tx Root", h.TxHash)
+	out.Field("Receipt Root", h.ReceiptHash)
+	out.Field("Bloom", shortHex(h.Bloom.Bytes()))
+	out.Field("Difficulty", h.Difficulty)
+	out.Field("Number", h.Number)
+	out.Field("Gas Limit", h.GasLimit)
+	out.Field("Gas Used", h.GasUsed)
+	out.Field("Timestamp", h.Time)
+	out.Field("Extra", hexutil.Encode(h.Extra)+printableSuffix(h.Extra))
+	out.Field("Mix Digest", h.MixDigest)
+	out.Field("Nonce", h.Nonce.Uint64())
+	if h.BaseFee != nil {
+		out.Field("Base Fee", h.BaseFee)
+	}
+	if h.WithdrawalsHash != nil {
+		out.Field("Withdrawals", h.WithdrawalsHash)
+	}
+	if h.BlobGasUsed != nil {
+		out.Field("Blob Gas", *h.BlobGasUsed)
+	}
+	if h.ExcessBlobGas != nil {
+		out.Field("Excess Blob", *h.ExcessBlobGas)
+	}
+	if h.ParentBeaconRoot != nil {
+		out.Field("Beacon Root", h.ParentBeaconRoot)
+	}
+	if h.RequestsHash != nil {
+		out.Field("Requests", h.RequestsHash)
+	}
+}
+
+// validateRLP 检查数据是否为单个完整的 RLP 元素（递归检查列表内容）
+func validateRLP(data []byte) error {
+	kind, content, rest, err := rlp.Split(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("%d trailing bytes after RLP value", len(rest))
+	}
+	if kind != rlp.List {
+		return nil
+	}
+	for len(content) > 0 {
+		_, _, next, err := rlp.Split(content)
+		if err != nil {
+			return err
+		}
+		if err := validateRLP(content[:len(content)-len(next)]); err != nil {
+			return err
+		}
+		content = next
+	}
+	return nil
+}
+
+// printRLP 以缩进树形输出 RLP 结构
+func printRLP(out *output.Printer, data []byte, depth int) {
+	indent := strings.Repeat("  ", depth)
+	kind, content, _, err := rlp.Split(data)
+	if err != nil {
+		out.Note("%s<invalid: %v>", indent, err)
+		return
+	}
+	if kind != rlp.List {
+		out.Note("%s%s%s", indent, shortHex(content), printableSuffix(content))
+		return
+	}
+	count, _ := rlp.CountValues(content)
+	out.Note("%slist (%d items, %d bytes)", indent, count, len(content))
+	for len(content) > 0 {
+		_, _, rest, err := rlp.Split(content)
+		if err != nil {
+			out.Note("%s  <invalid: %v>", indent, err)
+			return
+		}
+		printRLP(out, content[:len(content)-len(rest)], depth+1)
+		content = rest
+	}
+}
+
+// rlpValue 把已校验的 RLP 数据转换为嵌套数组，字符串元素为完整的十六进制
+func rlpValue(data []byte) any {
+	kind, content, _, _ := rlp.Split(data)
+	if kind != rlp.List {
+		return hexutil.Bytes(content)
+	}
+	items := []any{}
+	for len(content) > 0 {
+		_, _, rest, _ := rlp.Split(content)
+		items = append(items, rlpValue(content[:len(content)-len(rest)]))
+		content = rest
+	}
+	return items
+}
+
+// shortHex 十六进制输出，过长时截断
+func shortHex(b []byte) string {
+	if len(b) == 0 {
+		return "0x"
+	}
+	const max = 64
+	if len(b) <= max {
+		return hexutil.Encode(b)
+	}
+	return fmt.Sprintf("%s... (%d bytes)", hexutil.Encode(b[:max]), len(b))
+}
+
+// printableSuffix 内容是可打印 ASCII 时附加文本形式，方便识别 extraData / 字符串
+func printableSuffix(b []byte) string {
+	if len(b) == 0 || !utf8.Valid(b) {
+		return ""
+	}
+	for _, c := range string(b) {
+		if c < 0x20 || c > 0x7e {
+			return ""
+		}
+	}
+	return fmt.Sprintf(" (%q)", string(b))
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// location 变量在存储中的位置：起始 slot + slot 内的字节偏移（从低位算起）
+type location struct {
+	slot   *big.Int
+	offset int
+}
+
+// storageReader 读取合约存储，同一 slot 只请求一次
+type storageReader struct {
+	ctx      context.Context
+	client   *ethclient.Client
+	contract common.Address
+	block    *big.Int
+	cache    map[string]common.Hash
+	out      *output.Printer // dump 输出到当前表格
+}
+
+// word 读取一个 slot 的 32 字节
+func (r *storageReader) word(slot *big.Int) (common.Hash, error) {
+	key := slot.String()
+	if v, ok := r.cache[key]; ok {
+		return v, nil
+	}
+	data, err := r.client.StorageAt(r.ctx, r.contract, common.BigToHash(slot), r.block)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to read slot %s: %w", key, err)
+	}
+	v := common.BytesToHash(data)
+	r.cache[key] = v
+	return v, nil
+}
+
+// region 读取 slot 中 [offset, offset+size) 字节（偏移从低位算起，与 solc 的打包方式一致）
+func (r *storageReader) region(loc location, size int) ([]byte, error) {
+	w, err := r.word(loc.slot)
+	if err != nil {
+		return nil, err
+	}
+	end := common.HashLength - loc.offset
+	return w[end-size : end], nil
+}
+
+// slotAdd 计算 slot + n（存储地址空间按 2^256 取模）
+func slotAdd(slot *big.Int, n int64) *big.Int {
+	v := new(big.Int).Add(slot, big.NewInt(n))
+	return v.And(v, common.MaxHash.Big())
+}
+
+// keccakSlot keccak256(slot)：动态数组与长 bytes / string 的数据起始位置
+func keccakSlot(slot *big.Int) *big.Int {
+	return new(big.Int).SetBytes(crypto.Keccak256(common.BigToHash(slot).Bytes()))
+}
+
+// mappingSlot mapping 元素位置：keccak256(h(key) ++ slot)
+// 值类型的 key 填充为 32 字节；string / bytes 类型的 key 直接使用原始字节
+func (l *storageLayout) mappingSlot(keyType string, key string, slot *big.Int) (*big.Int, error) {
+	t, err := l.typeOf(keyType)
+	if err != nil {
+		return nil, err
+	}
+	var encoded []byte
+	if t.Encoding == "bytes" {
+		encoded = []byte(key)
+		if t.Label == "bytes" && strings.HasPrefix(key, "0x") {
+			if encoded, err = hexutil.Decode(key); err != nil {
+				return nil, fmt.Errorf("invalid bytes key %q: %w", key, err)
+			}
+		}
+	} else {
+		word, err := encodeKey(t, key)
+		if err != nil {
+			return nil, err
+		}
+		encoded = word
+	}
+	buf := append(encoded, common.BigToHash(slot).Bytes()...)
+	return new(big.Int).SetBytes(crypto.Keccak256(buf)), nil
+}
+
+// encodeKey 把值类型 key 编码为 32 字节
+func encodeKey(t *typeInfo, key string) ([]byte, error) {
+	label := t.Label
+	switch {
+	case label == "address" || strings.HasPrefix(label, "contract ") || label == "address payable":
+		if !common.IsHexAddress(key) {
+			return nil, fmt.Errorf("invalid address key %q", key)
+		}
+		return common.LeftPadBytes(common.HexToAddress(key).Bytes(), 32), nil
+	case label == "bool":
+		switch key {
+		case "true":
+			return common.LeftPadBytes([]byte{1}, 32), nil
+		case "false":
+			return make([]byte, 32), nil
+		}
+		return nil, fmt.Errorf("invalid bool key %q", key)
+	case strings.HasPrefix(label, "bytes"):
+		b, err := hexutil.Decode(key)
+		if err != nil || len(b) > 32 {
+			return nil, fmt.Errorf("invalid %s key %q", label, key)
+		}
+		return common.RightPadBytes(b, 32), nil
+	case strings.HasPrefix(label, "uint"), strings.HasPrefix(label, "int"), strings.HasPrefix(label, "enum "):
+		n, ok := new(big.Int).SetString(key, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer key %q", key)
+		}
+		// 负数按二进制补码编码
+		if n.Sign() < 0 {
+			n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return common.BigToHash(n).Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported mapping key type %s", label)
+}
+
+// decodeValue 读取并解码单个值类型
+func (r *storageReader) decodeValue(t *typeInfo, loc location) (string, error) {
+	b, err := r.region(loc, t.size())
+	if err != nil {
+		return "", err
+	}
+	label := t.Label
+	switch {
+	case label == "bool":
+		return strconv.FormatBool(b[len(b)-1] != 0), nil
+	case label == "address" || label == "address payable" || strings.HasPrefix(label, "contract "):
+		return common.BytesToAddress(b).Hex(), nil
+	case strings.HasPrefix(label, "int"):
+		// 有符号整数：最高位为 1 时按补码转换为负数
+		n := new(big.Int).SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+		}
+		return n.String(), nil
+	case strings.HasPrefix(label, "uint"), strings.HasPrefix(label, "enum "):
+		return new(big.Int).SetBytes(b).String(), nil
+	}
+	// bytesN、函数指针等：原样输出十六进制
+	return hexutil.Encode(b), nil
+}
+
+// decodeBytes 读取 bytes / string：
+// 短（< 32 字节）：数据在 slot 高位，最低字节为 length*2
+// 长：slot 中为 length*2+1，数据从 keccak256(slot) 开始连续存放
+func (r *storageReader) decodeBytes(t *typeInfo, slot *big.Int) (string, error) {
+	w, err := r.word(slot)
+	if err != nil {
+		return "", err
+	}
+	var data []byte
+	if w[31]&1 == 0 {
+		n := int(w[31] / 2)
+		data = w[:n]
+	} else {
+		length := new(big.Int).Rsh(w.Big(), 1)
+		if !length.IsInt64() || length.Int64() > 1<<20 {
+			return "", fmt.Errorf("implausible length %s", length)
+		}
+		n := int(length.Int64())
+		start := keccakSlot(slot)
+		for i := 0; len(data) < n; i++ {
+			chunk, err := r.word(slotAdd(start, int64(i)))
+			if err != nil {
+				return "", err
+			}
+			data = append(data, chunk[:]...)
+		}
+		data = data[:n]
+	}
+	if t.Label == "string" && utf8.Valid(data) {
+		return strconv.Quote(string(data)), nil
+	}
+	return hexutil.Encode(data), nil
+}
+
+// elementLocation 数组第 i 个元素的位置：元素不超过 16 字节时多个元素打包在同一 slot
+func elementLocation(base *big.Int, elem *typeInfo, i int) location {
+	size := elem.size()
+	if size <= 16 && !elem.isStruct() && !elem.isStaticArray() {
+		perSlot := common.HashLength / size
+		return location{slot: slotAdd(base, int64(i/perSlot)), offset: (i % perSlot) * size}
+	}
+	slotsPer := (size + 31) / 32
+	return location{slot: slotAdd(base, int64(i*slotsPer))}
+}
+
+// dump 递归输出一个变量：值类型直接解码；结构体、数组逐项展开；mapping 需要 key，只输出位置
+func (r *storageReader) dump(l *storageLayout, name, typeID string, loc location, indent string, maxItems int) error {
+	t, err := l.typeOf(typeID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case t.Encoding == "mapping":
+		r.row(indent, name, t.Label, fmt.Sprintf("mapping at slot %s, use --var '%s[<key>]'", loc.slot, name))
+	case t.Encoding == "bytes":
+		v, err := r.decodeBytes(t, loc.slot)
+		if err != nil {
+			return err
+		}
+		r.row(indent, name, t.Label, v)
+	case t.Encoding == "dynamic_array":
+		w, err := r.word(loc.slot)
+		if err != nil {
+			return err
+		}
+		length := w.Big()
+		r.row(indent, name, t.Label, fmt.Sprintf("length %s", length))
+		return r.dumpElements(l, name, t.Base, keccakSlot(loc.slot), length, indent, maxItems)
+	case t.isStaticArray():
+		r.row(indent, name, t.Label, "")
+		return r.dumpElements(l, name, t.Base, loc.slot, big.NewInt(int64(t.staticLength())), indent, maxItems)
+	case t.isStruct():
+		r.row(indent, name, t.Label, "")
+		for _, m := range t.Members {
+			mloc, err := memberLocation(loc.slot, m)
+			if err != nil {
+				return err
+			}
+			if err := r.dump(l, name+"."+m.Label, m.Type, mloc, indent+"  ", maxItems); err != nil {
+				return err
+			}
+		}
+	default:
+		v, err := r.decodeValue(t, loc)
+		if err != nil {
+			return err
+		}
+		r.row(indent, name, t.Label, v)
+	}
+	return nil
+}
+
+// row 输出一个变量；name 已是完整路径，pretty 模式下再按层级缩进
+func (r *storageReader) row(indent, name, label, value string) {
+	if r.out.Pretty() {
+		name = indent + name
+	}
+	r.out.Row(name, label, value)
+}
+
+// dumpElements 输出数组元素，最多 maxItems 个
+func (r *storageReader) dumpElements(l *storageLayout, name, elemType string, base, length *big.Int, indent string, maxItems int) error {
+	elem, err := l.typeOf(elemType)
+	if err != nil {
+		return err
+	}
+	n := maxItems
+	if length.IsInt64() && length.Int64() < int64(n) {
+		n = int(length.Int64())
+	}
+	for i := 0; i < n; i++ {
+		if err := r.dump(l, fmt.Sprintf("%s[%d]", name, i), elemType, elementLocation(base, elem, i), indent+"  ", maxItems); err != nil {
+			return err
+		}
+	}
+	if big.NewInt(int64(n)).Cmp(length) < 0 {
+		r.row(indent+"  ", name+"[...]", "", fmt.Sprintf("%s more, raise --max-items", new(big.Int).Sub(length, big.NewInt(int64(n)))))
+	}
+	return nil
+}
+
+// memberLocation 结构体成员位置：成员 slot 相对于结构体起始 slot
+func memberLocation(base *big.Int, m storageItem) (location, error) {
+	rel, err := parseSlot(m.Slot)
+	if err != nil {
+		return location{}, err
+	}
+	return location{slot: slotAdd(base, rel.Int64()), offset: m.Offset}, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// solc 存储布局 JSON（solc --storage-layout 或 standard JSON 的 outputSelection "storageLayout"）：
+//
+//	{
+//	  "storage": [{"label": "owner", "slot": "0", "offset": 0, "type": "t_address"}, ...],
+//	  "types": {
+//	    "t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+//	    "t_mapping(t_address,t_uint256)": {"encoding": "mapping", "key": "t_address", "value": "t_uint256", ...},
+//	    "t_array(t_uint256)dyn_storage": {"encoding": "dynamic_array", "base": "t_uint256", ...},
+//	    "t_string_storage": {"encoding": "bytes", ...},
+//	    "t_struct(Info)12_storage": {"encoding": "inplace", "members": [...], ...}
+//	  }
+//	}
+
+// storageLayout 存储布局
+type storageLayout struct {
+	Storage []storageItem        `json:"storage"`
+	Types   map[string]*typeInfo `json:"types"`
+}
+
+// storageItem 一个状态变量或结构体成员
+type storageItem struct {
+	Label  string `json:"label"`
+	Slot   string `json:"slot"` // 十进制字符串，可能超过 uint64（如 ERC-7201 命名空间存储）
+	Offset int    `json:"offset"`
+	Type   string `json:"type"`
+}
+
+// typeInfo 类型描述
+type typeInfo struct {
+	Encoding      string        `json:"encoding"` // inplace | mapping | dynamic_array | bytes
+	Label         string        `json:"label"`
+	NumberOfBytes string        `json:"numberOfBytes"`
+	Key           string        `json:"key"`     // mapping
+	Value         string        `json:"value"`   // mapping
+	Base          string        `json:"base"`    // 数组元素类型
+	Members       []storageItem `json:"members"` // 结构体
+}
+
+// loadLayout 读取布局文件；兼容直接的布局对象，以及 Foundry / Hardhat 产物中嵌套的 "storageLayout" 字段
+func loadLayout(path string) (*storageLayout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var wrapper struct {
+		StorageLayout *storageLayout `json:"storageLayout"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err == nil && wrapper.StorageLayout != nil {
+		return wrapper.StorageLayout, nil
+	}
+	var layout storageLayout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return nil, fmt.Errorf("invalid storage layout JSON: %w", err)
+	}
+	if len(layout.Storage) == 0 || len(layout.Types) == 0 {
+		return nil, fmt.Errorf("layout has no storage / types (was the contract compiled with storageLayout output?)")
+	}
+	return &layout, nil
+}
+
+// typeOf 查找类型描述
+func (l *storageLayout) typeOf(id string) (*typeInfo, error) {
+	t, ok := l.Types[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %s", id)
+	}
+	return t, nil
+}
+
+// size 类型占用的字节数
+func (t *typeInfo) size() int {
+	n, _ := strconv.Atoi(t.NumberOfBytes)
+	return n
+}
+
+// isStruct 是否为结构体
+func (t *typeInfo) isStruct() bool {
+	return len(t.Members) > 0
+}
+
+// isStaticArray 是否为定长数组（inplace 编码且带有元素类型）
+func (t *typeInfo) isStaticArray() bool {
+	return t.Encoding == "inplace" && t.Base != ""
+}
+
+// staticLength 定长数组的长度：从类型标签 "uint256[3]" 中解析
+func (t *typeInfo) staticLength() int {
+	label := t.Label
+	open := strings.LastIndex(label, "[")
+	if open < 0 || !strings.HasSuffix(label, "]") {
+		return 0
+	}
+	n, _ := strconv.Atoi(label[open+1 : len(label)-1])
+	return n
+}
+
+// parseSlot 解析十进制 slot 字符串
+func parseSlot(s string) (*big.Int, error) {
+	slot, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid slot %q", s)
+	}
+	return slot, nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 27-storage-layout
+// 根据 solc 输出的存储布局 JSON 直接读取并解码合约存储（eth_getStorageAt），不需要合约提供任何 getter：
+// - 值类型：uintN / intN / bool / address / bytesN / enum，按 offset 从打包的 slot 中截取
+// - mapping：slot = keccak256(h(key) ++ slot)，支持嵌套 mapping 和 string / bytes 类型的 key
+// - 动态数组：slot 中为长度，元素从 keccak256(slot) 开始，小于 16 字节的元素打包存放
+// - 定长数组、结构体：逐项展开
+// - string / bytes：区分短（slot 内）和长（keccak256(slot) 起）两种编码
+// 不指定 --var 时输出全部状态变量（mapping 只输出所在 slot）
+//
+// 执行示例：
+//    forge inspect MyToken storageLayout --json > layout.json
+//    export ETH_RPC_URL="https://mainnet.infura.io/v3/<project-id>"
+//    go run . --layout layout.json --contract 0x...
+//    go run . --layout layout.json --contract 0x... --var '_balances[0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045]'
+//    go run . --layout layout.json --contract 0x... --var '_allowances[0xabc...][0xdef...]'
+//    go run . --layout layout.json --contract 0x... --var 'users[3].name' --block 19000000
+//    go run . --layout layout.json --contract 0x... --format csv > storage.csv
+//
+// 注意事项：
+// - 布局必须与链上部署的合约源码 / 编译器版本一致，否则读出的数据没有意义
+// - 代理合约请用 --contract 指定代理地址（存储在代理中），布局取自实现合约
+
+func main() {
+	layoutPath := flag.String("layout", "", "path to solc storage layout JSON (required)")
+	contractHex := flag.String("contract", "", "contract address (required)")
+	varExpr := flag.String("var", "", "variable path, e.g. balances[0x...] or users[2].name (default: all variables)")
+	blockNum := flag.Int64("block", -1, "block number to read at (-1 = latest)")
+	maxItems := flag.Int("max-items", 10, "maximum array elements to print")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *layoutPath == "" || !common.IsHexAddress(*contractHex) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	layout, err := loadLayout(*layoutPath)
+	if err != nil {
+		log.Fatalf("failed to load layout: %v", err)
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+	ctx := context.Background()
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	var block *big.Int
+	if *blockNum >= 0 {
+		block = big.NewInt(*blockNum)
+	}
+	reader := &storageReader{
+		ctx:      ctx,
+		client:   client,
+		contract: common.HexToAddress(*contractHex),
+		block:    block,
+		cache:    make(map[string]common.Hash),
+		out:      out,
+	}
+
+	out.Section("Storage")
+	out.Field("Contract", reader.contract)
+	if block != nil {
+		out.Field("Block", block)
+	} else {
+		out.Field("Block", "latest")
+	}
+
+	if *varExpr == "" {
+		out.Table("Variables", "Name", "Type", "Value")
+		for _, item := range layout.Storage {
+			slot, err := parseSlot(item.Slot)
+			if err != nil {
+				log.Fatalf("failed to parse layout: %v", err)
+			}
+			if err := reader.dump(layout, item.Label, item.Type, location{slot: slot, offset: item.Offset}, "", *maxItems); err != nil {
+				log.Fatalf("failed to read %s: %v", item.Label, err)
+			}
+		}
+	} else {
+		typeID, loc, err := resolve(reader, layout, *varExpr)
+		if err != nil {
+			log.Fatalf("failed to resolve %s: %v", *varExpr, err)
+		}
+		out.Field("Slot", common.BigToHash(loc.slot))
+		out.Field("Offset", loc.offset)
+		out.Table("Variables", "Name", "Type", "Value")
+		if err := reader.dump(layout, *varExpr, typeID, loc, "", *maxItems); err != nil {
+			log.Fatalf("failed to read %s: %v", *varExpr, err)
+		}
+	}
+
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// accessor 变量路径中的一步：[key] 或 .member
+type accessor struct {
+	key    string
+	member string
+	index  bool
+}
+
+// parsePath 解析 name[key][key].member 形式的路径；key 可以用引号包裹（string 类型 key 中含有 ] 时）
+func parsePath(expr string) (string, []accessor, error) {
+	end := strings.IndexAny(expr, "[.")
+	if end < 0 {
+		return expr, nil, nil
+	}
+	name, rest := expr[:end], expr[end:]
+	if name == "" {
+		return "", nil, fmt.Errorf("missing variable name")
+	}
+	var path []accessor
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, "[.")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return "", nil, fmt.Errorf("empty member name")
+			}
+			path = append(path, accessor{member: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			rest = rest[1:]
+			var key string
+			if strings.HasPrefix(rest, `"`) {
+				q, err := strconv.QuotedPrefix(rest)
+				if err != nil {
+					return "", nil, fmt.Errorf("invalid quoted key: %w", err)
+				}
+				key, _ = strconv.Unquote(q)
+				rest = rest[len(q):]
+				if !strings.HasPrefix(rest, "]") {
+					return "", nil, fmt.Errorf("missing ] after quoted key")
+				}
+			} else {
+				end := strings.Index(rest, "]")
+				if end < 0 {
+					return "", nil, fmt.Errorf("missing ]")
+				}
+				key = strings.TrimSpace(rest[:end])
+				rest = rest[end:]
+			}
+			rest = rest[1:]
+			path = append(path, accessor{key: key, index: true})
+		default:
+			return "", nil, fmt.Errorf("unexpected %q", rest[0])
+		}
+	}
+	return name, path, nil
+}
+
+// resolve 沿路径计算最终变量的类型和存储位置
+func resolve(r *storageReader, l *storageLayout, expr string) (string, location, error) {
+	name, path, err := parsePath(expr)
+	if err != nil {
+		return "", location{}, err
+	}
+	var item *storageItem
+	for i := range l.Storage {
+		if l.Storage[i].Label == name {
+			item = &l.Storage[i]
+			break
+		}
+	}
+	if item == nil {
+		return "", location{}, fmt.Errorf("no state variable named %q", name)
+	}
+	slot, err := parseSlot(item.Slot)
+	if err != nil {
+		return "", location{}, err
+	}
+	typeID, loc := item.Type, location{slot: slot, offset: item.Offset}
+
+	for _, step := range path {
+		t, err := l.typeOf(typeID)
+		if err != nil {
+			return "", location{}, err
+		}
+		if !step.index {
+			found := false
+			for _, m := range t.Members {
+				if m.Label == step.member {
+					if loc, err = memberLocation(loc.slot, m); err != nil {
+						return "", location{}, err
+					}
+					typeID, found = m.Type, true
+					break
+				}
+			}
+			if !found {
+				return "", location{}, fmt.Errorf("%s has no member %q", t.Label, step.member)
+			}
+			continue
+		}
+
+		switch {
+		case t.Encoding == "mapping":
+			s, err := l.mappingSlot(t.Key, step.key, loc.slot)
+			if err != nil {
+				return "", location{}, err
+			}
+			typeID, loc = t.Value, location{slot: s}
+		case t.Encoding == "dynamic_array" || t.isStaticArray():
+			i, err := strconv.Atoi(step.key)
+			if err != nil || i < 0 {
+				return "", location{}, fmt.Errorf("invalid array index %q", step.key)
+			}
+			elem, err := l.typeOf(t.Base)
+			if err != nil {
+				return "", location{}, err
+			}
+			base := loc.slot
+			if t.Encoding == "dynamic_array" {
+				w, err := r.word(loc.slot)
+				if err != nil {
+					return "", location{}, err
+				}
+				if big.NewInt(int64(i)).Cmp(w.Big()) >= 0 {
+					return "", location{}, fmt.Errorf("index %d out of range (length %s)", i, w.Big())
+				}
+				base = keccakSlot(loc.slot)
+			} else if i >= t.staticLength() {
+				return "", location{}, fmt.Errorf("index %d out of range (length %d)", i, t.staticLength())
+			}
+			typeID, loc = t.Base, elementLocation(base, elem, i)
+		default:
+			return "", location{}, fmt.Errorf("cannot index into %s", t.Label)
+		}
+	}
+	return typeID, loc, nil
+}
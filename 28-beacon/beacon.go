@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// beaconClient 共识层节点 Beacon REST API（/eth/v1/...）的最小客户端
+// 所有响应都包在 {"data": ...} 中，数字以十进制字符串表示
+type beaconClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+// newBeaconClient 创建客户端
+func newBeaconClient(baseURL string) *beaconClient {
+	return &beaconClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// get 请求 path 并把 data 字段解码到 out
+func (c *beaconClient) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// 错误响应格式：{"code": 404, "message": "..."}
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("%s: %s (%s)", path, apiErr.Message, resp.Status)
+		}
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, path)
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode %s data: %w", path, err)
+	}
+	return nil
+}
+
+// syncStatus /eth/v1/node/syncing
+type syncStatus struct {
+	HeadSlot     uint64 `json:"head_slot,string"`
+	SyncDistance uint64 `json:"sync_distance,string"`
+	IsSyncing    bool   `json:"is_syncing"`
+	IsOptimistic bool   `json:"is_optimistic"` // 执行层尚未验证头部区块的 payload
+	ELOffline    bool   `json:"el_offline"`
+}
+
+// SyncStatus 查询同步状态
+func (c *beaconClient) SyncStatus(ctx context.Context) (*syncStatus, error) {
+	var s syncStatus
+	if err := c.get(ctx, "/eth/v1/node/syncing", &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// chainSpec 计算 slot 时间所需的链参数
+type chainSpec struct {
+	GenesisTime    uint64
+	SecondsPerSlot uint64
+	SlotsPerEpoch  uint64
+}
+
+// Spec 查询创世时间和 slot / epoch 参数
+func (c *beaconClient) Spec(ctx context.Context) (*chainSpec, error) {
+	var genesis struct {
+		GenesisTime uint64 `json:"genesis_time,string"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/genesis", &genesis); err != nil {
+		return nil, err
+	}
+	// /config/spec 的值全部是字符串，这里只取需要的两项
+	var spec map[string]any
+	if err := c.get(ctx, "/eth/v1/config/spec", &spec); err != nil {
+		return nil, err
+	}
+	specUint := func(key string, def uint64) uint64 {
+		if s, ok := spec[key].(string); ok {
+			if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+				return v
+			}
+		}
+		return def
+	}
+	return &chainSpec{
+		GenesisTime:    genesis.GenesisTime,
+		SecondsPerSlot: specUint("SECONDS_PER_SLOT", 12),
+		SlotsPerEpoch:  specUint("SLOTS_PER_EPOCH", 32),
+	}, nil
+}
+
+// SlotTime slot 的开始时间
+func (s *chainSpec) SlotTime(slot uint64) time.Time {
+	return time.Unix(int64(s.GenesisTime+slot*s.SecondsPerSlot), 0)
+}
+
+// checkpoint epoch + 该 epoch 第一个 slot 的区块根（该 slot 为空时取之前最近的区块）
+type checkpoint struct {
+	Epoch uint64      `json:"epoch,string"`
+	Root  common.Hash `json:"root"`
+}
+
+// finalityCheckpoints /eth/v1/beacon/states/{state_id}/finality_checkpoints
+type finalityCheckpoints struct {
+	PreviousJustified checkpoint `json:"previous_justified"`
+	CurrentJustified  checkpoint `json:"current_justified"`
+	Finalized         checkpoint `json:"finalized"`
+}
+
+// FinalityCheckpoints 查询 head 状态的 justified / finalized checkpoint
+func (c *beaconClient) FinalityCheckpoints(ctx context.Context) (*finalityCheckpoints, error) {
+	var fc finalityCheckpoints
+	if err := c.get(ctx, "/eth/v1/beacon/states/head/finality_checkpoints", &fc); err != nil {
+		return nil, err
+	}
+	return &fc, nil
+}
+
+// executionPayload 信标区块中携带的执行层区块摘要（Bellatrix 之后）
+type executionPayload struct {
+	BlockNumber uint64      `json:"block_number,string"`
+	BlockHash   common.Hash `json:"block_hash"`
+	Timestamp   uint64      `json:"timestamp,string"`
+}
+
+// beaconBlock /eth/v2/beacon/blocks/{block_id} 中需要的字段
+type beaconBlock struct {
+	Slot    uint64
+	Payload *executionPayload // 合并前的区块为 nil
+}
+
+// Block 查询信标区块；blockID 可以是 head / finalized / slot / 0x 区块根
+func (c *beaconClient) Block(ctx context.Context, blockID string) (*beaconBlock, error) {
+	var signed struct {
+		Message struct {
+			Slot uint64 `json:"slot,string"`
+			Body struct {
+				ExecutionPayload *executionPayload `json:"execution_payload"`
+			} `json:"body"`
+		} `json:"message"`
+	}
+	if err := c.get(ctx, "/eth/v2/beacon/blocks/"+url.PathEscape(blockID), &signed); err != nil {
+		return nil, err
+	}
+	return &beaconBlock{Slot: signed.Message.Slot, Payload: signed.Message.Body.ExecutionPayload}, nil
+}
+
+// validatorInfo /eth/v1/beacon/states/{state_id}/validators 中的一项
+type validatorInfo struct {
+	Index     uint64 `json:"index,string"`
+	Balance   uint64 `json:"balance,string"` // Gwei
+	Status    string `json:"status"`
+	Validator struct {
+		Pubkey           string `json:"pubkey"`
+		EffectiveBalance uint64 `json:"effective_balance,string"` // Gwei
+		Slashed          bool   `json:"slashed"`
+	} `json:"validator"`
+}
+
+// Validators 查询验证者余额和状态；ids 可以是验证者索引或 0x 公钥
+func (c *beaconClient) Validators(ctx context.Context, stateID string, ids []string) ([]validatorInfo, error) {
+	path := "/eth/v1/beacon/states/" + url.PathEscape(stateID) + "/validators"
+	if len(ids) > 0 {
+		path += "?id=" + url.QueryEscape(strings.Join(ids, ","))
+	}
+	var vals []validatorInfo
+	if err := c.get(ctx, path, &vals); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 28-beacon
+// 通过共识层节点的 Beacon REST API 查看最终性，并与执行层对照：
+// - 同步状态：head slot、落后距离、是否 optimistic（执行层尚未验证）、执行层是否离线
+// - 最终性 checkpoint：previous / current justified 和 finalized 的 epoch 与区块根
+// - 把 finalized checkpoint 对应信标区块中的 execution_payload 与执行层
+//   eth_getBlockByNumber("finalized") 返回的区块对照（01-connect-node 只看到了执行层这一侧）
+// - --validators：按索引或公钥查询验证者余额、有效余额和状态
+//
+// 执行示例：
+//    export BEACON_API_URL="http://localhost:5052"
+//    export ETH_RPC_URL="http://localhost:8545"
+//    go run .
+//    go run . --format json
+//    go run . --validators 0,1,0x933ad9491b62059dd065b560d256d8957a8c402cc6e8d8ee7290ae11e8f7329267a8811c397529dac52ae1342ba58c95
+//
+// 注意事项：
+// - 大部分公共 RPC 服务不提供 Beacon API，通常需要自建共识层客户端（Lighthouse / Prysm / Teku / Nimbus / Lodestar）或使用专门的 Beacon API 服务
+// - 未设置 ETH_RPC_URL 时只输出共识层信息
+// - 两侧节点不是同一对 CL/EL 时，finalized 区块相差一两个 epoch 属于正常的观测时间差
+
+func main() {
+	validators := flag.String("validators", "", "comma-separated validator indices or 0x pubkeys")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	beaconURL := os.Getenv("BEACON_API_URL")
+	if beaconURL == "" {
+		log.Fatal("BEACON_API_URL is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(60*time.Second))
+	defer cancel()
+
+	beacon := newBeaconClient(beaconURL)
+
+	spec, err := beacon.Spec(ctx)
+	if err != nil {
+		log.Fatalf("failed to get chain spec: %v", err)
+	}
+
+	syncing, err := beacon.SyncStatus(ctx)
+	if err != nil {
+		log.Fatalf("failed to get sync status: %v", err)
+	}
+	out.Section("Sync Status")
+	out.Field("Head Slot", syncing.HeadSlot)
+	out.Field("Head Epoch", syncing.HeadSlot/spec.SlotsPerEpoch)
+	out.Field("Head Time", spec.SlotTime(syncing.HeadSlot).Format(time.RFC3339))
+	out.Field("Distance", syncing.SyncDistance)
+	out.Field("Syncing", syncing.IsSyncing)
+	out.Field("Optimistic", syncing.IsOptimistic)
+	out.Field("EL Offline", syncing.ELOffline)
+	if syncing.IsSyncing || syncing.IsOptimistic || syncing.ELOffline {
+		log.Printf("[WARN] beacon node is not fully synced, checkpoints below may be stale")
+	}
+
+	fc, err := beacon.FinalityCheckpoints(ctx)
+	if err != nil {
+		log.Fatalf("failed to get finality checkpoints: %v", err)
+	}
+	out.Table("Finality Checkpoints", "Checkpoint", "Epoch", "Root", "Start", "Age")
+	printCheckpoint(out, "Prev Just.", fc.PreviousJustified, spec)
+	printCheckpoint(out, "Curr Just.", fc.CurrentJustified, spec)
+	printCheckpoint(out, "Finalized", fc.Finalized, spec)
+	headEpoch := syncing.HeadSlot / spec.SlotsPerEpoch
+	if headEpoch > fc.Finalized.Epoch+2 {
+		// 正常情况下 finalized 落后当前 epoch 2 个；更多说明链上超过 1/3 的质押没有参与投票
+		log.Printf("[WARN] finality is lagging: head epoch %d, finalized epoch %d", headEpoch, fc.Finalized.Epoch)
+	}
+
+	var finalizedPayload *executionPayload
+	if fc.Finalized.Root != (common.Hash{}) {
+		block, err := beacon.Block(ctx, fc.Finalized.Root.Hex())
+		if err != nil {
+			log.Fatalf("failed to get finalized beacon block: %v", err)
+		}
+		finalizedPayload = block.Payload
+		out.Section("Finalized Block")
+		out.Field("Slot", block.Slot)
+		if block.Payload != nil {
+			out.Field("Exec Block", block.Payload.BlockNumber)
+			out.Field("Exec Hash", block.Payload.BlockHash)
+		} else {
+			out.Field("Exec Block", "(none, pre-merge block)")
+		}
+	}
+
+	if rpcURL := config.Get().RPCURL; rpcURL != "" {
+		compareExecution(ctx, out, rpcURL, finalizedPayload)
+	}
+
+	if *validators != "" {
+		printValidators(ctx, beacon, out, strings.Split(*validators, ","))
+	}
+
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printCheckpoint 输出 checkpoint 及其 epoch 开始时间
+func printCheckpoint(out *output.Printer, label string, cp checkpoint, spec *chainSpec) {
+	start := spec.SlotTime(cp.Epoch * spec.SlotsPerEpoch)
+	out.Row(label, cp.Epoch, cp.Root, start.Format(time.RFC3339), time.Since(start).Truncate(time.Second).String())
+}
+
+// compareExecution 对照执行层的 finalized 区块与共识层 finalized checkpoint 中的 execution_payload
+func compareExecution(ctx context.Context, out *output.Printer, rpcURL string, payload *executionPayload) {
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	// 与 01-connect-node 相同：直接使用 RPC 返回的 hash，不依赖本地重新计算
+	var block struct {
+		Number    *hexutil.Big   `json:"number"`
+		Hash      common.Hash    `json:"hash"`
+		Timestamp hexutil.Uint64 `json:"timestamp"`
+	}
+	var raw json.RawMessage
+	if err := client.Client().CallContext(ctx, &raw, "eth_getBlockByNumber", "finalized", false); err != nil {
+		log.Fatalf("failed to get 'finalized' block: %v", err)
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		log.Fatal("execution node returned no 'finalized' block (not synced, or pre-merge chain)")
+	}
+	if err := json.Unmarshal(raw, &block); err != nil {
+		log.Fatalf("failed to unmarshal block: %v", err)
+	}
+
+	out.Section("Execution Layer")
+	number := block.Number.ToInt().Uint64()
+	out.Field("Finalized", number)
+	out.Field("Hash", block.Hash)
+	out.Field("Time", time.Unix(int64(block.Timestamp), 0).Format(time.RFC3339))
+	if payload == nil {
+		return
+	}
+
+	switch {
+	case number == payload.BlockNumber && block.Hash == payload.BlockHash:
+		out.Field("Match", "✓ execution finalized block equals finalized checkpoint payload")
+	case number == payload.BlockNumber:
+		// 同一高度哈希不同：两个节点不在同一条链上
+		out.Field("Match", "✗ same height, different hash (nodes are on different chains?)")
+	case number < payload.BlockNumber:
+		out.Field("Match", fmt.Sprintf("execution node is %d blocks behind the beacon node", payload.BlockNumber-number))
+	default:
+		out.Field("Match", fmt.Sprintf("execution node is %d blocks ahead of the beacon node", number-payload.BlockNumber))
+	}
+
+	// 确认 checkpoint 中的执行区块确实在执行层的规范链上
+	if number >= payload.BlockNumber {
+		h, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(payload.BlockNumber))
+		if err != nil {
+			log.Printf("[WARN] failed to get block %d: %v", payload.BlockNumber, err)
+			return
+		}
+		if h.Hash() != payload.BlockHash {
+			out.Field("Canonical", fmt.Sprintf("✗ block #%d on execution node is %s", payload.BlockNumber, h.Hash().Hex()))
+		} else {
+			out.Field("Canonical", fmt.Sprintf("✓ block #%d is canonical on execution node", payload.BlockNumber))
+		}
+	}
+}
+
+// printValidators 输出验证者余额和状态
+func printValidators(ctx context.Context, beacon *beaconClient, out *output.Printer, ids []string) {
+	for i := range ids {
+		ids[i] = strings.TrimSpace(ids[i])
+	}
+	vals, err := beacon.Validators(ctx, "head", ids)
+	if err != nil {
+		log.Fatalf("failed to get validators: %v", err)
+	}
+	if len(vals) < len(ids) {
+		log.Printf("[WARN] %d of %d requested validators not found", len(ids)-len(vals), len(ids))
+	}
+	out.Table("Validators", "Index", "Pubkey", "Status", "Balance (ETH)", "Effective (ETH)", "Slashed")
+	var total uint64
+	for _, v := range vals {
+		pubkey := v.Validator.Pubkey
+		if out.Pretty() {
+			pubkey = shortPubkey(pubkey)
+		}
+		out.Row(v.Index, pubkey, v.Status, gweiToEth(v.Balance), gweiToEth(v.Validator.EffectiveBalance), v.Validator.Slashed)
+		total += v.Balance
+	}
+	if len(vals) > 1 {
+		out.Section("Validator Total")
+		out.Field("Balance (ETH)", gweiToEth(total))
+	}
+}
+
+// gweiToEth 共识层余额单位为 Gwei
+func gweiToEth(gwei uint64) string {
+	return fmt.Sprintf("%d.%09d", gwei/1e9, gwei%1e9)
+}
+
+// shortPubkey 缩短 48 字节 BLS 公钥
+func shortPubkey(pk string) string {
+	if len(pk) <= 18 {
+		return pk
+	}
+	return pk[:10] + "…" + pk[len(pk)-6:]
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// txRecord 一笔与地址相关的交易
+type txRecord struct {
+	Block     uint64
+	Index     uint
+	Time      time.Time
+	Hash      common.Hash
+	Direction string // out | in | self | internal（地址只出现在内部调用中，仅 trace 模式）
+	From      common.Address
+	To        *common.Address
+	Value     *big.Int
+	Method    string
+	Success   bool
+	GasUsed   uint64
+	Fee       *big.Int // 发送者支付的执行层手续费（含 blob 费用，不含 L2 的 L1 数据费）
+}
+
+// collector 收集交易所需的连接和地址
+type collector struct {
+	client  *ethclient.Client
+	signer  types.Signer
+	address common.Address
+	workers int
+}
+
+// scanBlocks 逐块扫描：适用于任何节点，但每个区块都要请求一次完整交易列表
+func (c *collector) scanBlocks(ctx context.Context, from, to uint64) ([]*txRecord, error) {
+	numbers := make(chan uint64)
+	var (
+		mu       sync.Mutex
+		records  []*txRecord
+		firstErr error
+		done     uint64
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range numbers {
+				found, err := c.scanBlock(ctx, n)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				records = append(records, found...)
+				done++
+				if done%1000 == 0 {
+					log.Printf("scanned %d / %d blocks, %d transactions found", done, to-from+1, len(records))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for n := from; n <= to; n++ {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		select {
+		case numbers <- n:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(numbers)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	sortRecords(records)
+	return records, nil
+}
+
+// scanBlock 在单个区块中查找地址发出或接收的交易
+func (c *collector) scanBlock(ctx context.Context, number uint64) ([]*txRecord, error) {
+	block, err := c.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %d: %w", number, err)
+	}
+	var records []*txRecord
+	for _, tx := range block.Transactions() {
+		// ethclient 会缓存节点返回的 from 字段，这里不需要真正做签名恢复
+		from, err := types.Sender(c.signer, tx)
+		if err != nil {
+			log.Printf("[WARN] failed to get sender of %s: %v", tx.Hash().Hex(), err)
+			continue
+		}
+		to := tx.To()
+		if from != c.address && (to == nil || *to != c.address) {
+			continue
+		}
+		r, _, err := c.newRecord(ctx, tx, from, block.Time())
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// traceEntry trace_filter 返回的单条调用轨迹（只取需要的字段）
+type traceEntry struct {
+	BlockNumber     uint64       `json:"blockNumber"`
+	TransactionHash *common.Hash `json:"transactionHash"` // 区块奖励等没有交易哈希
+}
+
+// traceFilter 通过 trace_filter（Erigon / Nethermind / Reth 提供）查找地址参与的交易，包括内部调用
+func (c *collector) traceFilter(ctx context.Context, from, to, chunk uint64) ([]*txRecord, error) {
+	hashes := make(map[common.Hash]bool)
+	var ordered []common.Hash
+	for start := from; start <= to; start += chunk {
+		end := min(start+chunk-1, to)
+		// fromAddress 和 toAddress 同时指定时各实现的语义不一致（并集 / 交集），分两次查询
+		for _, field := range []string{"fromAddress", "toAddress"} {
+			var traces []traceEntry
+			filter := map[string]any{
+				"fromBlock": hexutil.EncodeUint64(start),
+				"toBlock":   hexutil.EncodeUint64(end),
+				field:       []common.Address{c.address},
+			}
+			if err := c.client.Client().CallContext(ctx, &traces, "trace_filter", filter); err != nil {
+				return nil, fmt.Errorf("trace_filter %d-%d: %w", start, end, err)
+			}
+			for _, t := range traces {
+				if t.TransactionHash == nil || hashes[*t.TransactionHash] {
+					continue
+				}
+				hashes[*t.TransactionHash] = true
+				ordered = append(ordered, *t.TransactionHash)
+			}
+		}
+		log.Printf("traced blocks %d-%d, %d transactions found", start, end, len(ordered))
+	}
+
+	blockTimes := make(map[common.Hash]uint64)
+	records := make([]*txRecord, 0, len(ordered))
+	for _, hash := range ordered {
+		tx, _, err := c.client.TransactionByHash(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction %s: %w", hash.Hex(), err)
+		}
+		sender, err := types.Sender(c.signer, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sender of %s: %w", hash.Hex(), err)
+		}
+		r, receipt, err := c.newRecord(ctx, tx, sender, 0)
+		if err != nil {
+			return nil, err
+		}
+		ts, ok := blockTimes[receipt.BlockHash]
+		if !ok {
+			h, err := c.client.HeaderByHash(ctx, receipt.BlockHash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get block %s: %w", receipt.BlockHash.Hex(), err)
+			}
+			ts = h.Time
+			blockTimes[receipt.BlockHash] = ts
+		}
+		r.Time = time.Unix(int64(ts), 0)
+		records = append(records, r)
+	}
+	sortRecords(records)
+	return records, nil
+}
+
+// newRecord 查询回执并构造记录；blockTime 为 0 时由调用方根据回执中的区块补充
+func (c *collector) newRecord(ctx context.Context, tx *types.Transaction, from common.Address, blockTime uint64) (*txRecord, *types.Receipt, error) {
+	receipt, err := c.client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get receipt %s: %w", tx.Hash().Hex(), err)
+	}
+	r := &txRecord{
+		Block:   receipt.BlockNumber.Uint64(),
+		Index:   receipt.TransactionIndex,
+		Time:    time.Unix(int64(blockTime), 0),
+		Hash:    tx.Hash(),
+		From:    from,
+		To:      tx.To(),
+		Value:   tx.Value(),
+		Method:  methodName(tx.Data()),
+		Success: receipt.Status == types.ReceiptStatusSuccessful,
+		GasUsed: receipt.GasUsed,
+		Fee:     new(big.Int),
+	}
+	isTo := r.To != nil && *r.To == c.address
+	switch {
+	case from == c.address && isTo:
+		r.Direction = "self"
+	case from == c.address:
+		r.Direction = "out"
+	case isTo:
+		r.Direction = "in"
+	default:
+		r.Direction = "internal"
+	}
+	if receipt.EffectiveGasPrice != nil {
+		r.Fee.Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	}
+	if receipt.BlobGasPrice != nil {
+		r.Fee.Add(r.Fee, new(big.Int).Mul(new(big.Int).SetUint64(receipt.BlobGasUsed), receipt.BlobGasPrice))
+	}
+	return r, receipt, nil
+}
+
+// traceSupported 用一个很小的范围探测节点是否提供 trace_filter
+func (c *collector) traceSupported(ctx context.Context, block uint64) bool {
+	var traces []traceEntry
+	err := c.client.Client().CallContext(ctx, &traces, "trace_filter", map[string]any{
+		"fromBlock":   hexutil.EncodeUint64(block),
+		"toBlock":     hexutil.EncodeUint64(block),
+		"fromAddress": []common.Address{c.address},
+	})
+	if err == nil {
+		return true
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		log.Printf("trace_filter not available (%v), falling back to block scan", err)
+	} else {
+		log.Printf("[WARN] trace_filter probe failed (%v), falling back to block scan", err)
+	}
+	return false
+}
+
+// sortRecords 按区块和交易序号排序
+func sortRecords(records []*txRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Block != records[j].Block {
+			return records[i].Block < records[j].Block
+		}
+		return records[i].Index < records[j].Index
+	})
+}
+
+// findBlockByTime 二分查找时间戳不早于 t 的第一个区块
+func findBlockByTime(ctx context.Context, client *ethclient.Client, t time.Time, head uint64) (uint64, error) {
+	target := uint64(t.Unix())
+	lo, hi := uint64(0), head
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		h, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0, fmt.Errorf("failed to get block %d: %w", mid, err)
+		}
+		if h.Time < target {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
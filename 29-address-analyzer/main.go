@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 29-address-analyzer
+// 地址活动与手续费分析：在一段区块范围内找出地址的全部交易，统计
+// - 交易数量（发出 / 收到 / 仅出现在内部调用中）、成功率
+// - 发出交易支付的总手续费、平均 / 最大手续费、失败交易浪费的手续费
+// - 主要交易对手、调用的方法分布（常见方法显示签名，其他显示选择器）
+// 两种收集方式（--mode auto 时先探测 trace_filter，不可用则逐块扫描）：
+// - trace：trace_filter（Erigon / Nethermind / Reth），只请求相关交易，速度快，且能发现内部调用
+// - scan：逐块获取完整交易列表，任何节点都可用，但只能发现地址作为顶层 from / to 的交易
+// --csv 导出逐笔明细；--format 控制汇总报告的输出格式
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://mainnet.infura.io/v3/<project-id>"
+//    go run . --address 0x... --since 720h --csv bot.csv
+//    go run . --address 0x... --from-block 19000000 --to-block 19010000 --mode scan --workers 16
+//    go run . --address 0x... --since 24h --format json   # 输出格式：pretty（默认）| json | yaml | csv
+//
+// 注意事项：
+// - scan 模式每个区块一次请求，一个月约 21 万个区块，公共 RPC 很容易触发限流，请缩小范围或使用自建节点
+// - 手续费为执行层费用（gasUsed × effectiveGasPrice + blob 费用）；L2 上的 L1 数据费不包含在内
+
+func main() {
+	addressHex := flag.String("address", "", "address to analyze (required)")
+	fromBlock := flag.Int64("from-block", -1, "first block (default: latest - 7200)")
+	toBlock := flag.Int64("to-block", -1, "last block (-1 = latest)")
+	since := flag.Duration("since", 0, "analyze blocks newer than this duration, e.g. 720h (overrides --from-block)")
+	mode := flag.String("mode", "auto", "collection mode: auto | trace | scan")
+	workers := flag.Int("workers", 8, "concurrent block requests in scan mode")
+	chunk := flag.Uint64("chunk", 5000, "block range per trace_filter request")
+	top := flag.Int("top", 10, "number of counterparties / methods to show")
+	csvPath := flag.String("csv", "", "write per-transaction CSV to this file")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if !common.IsHexAddress(*addressHex) {
+		log.Fatal("missing or invalid --address")
+	}
+	if *workers <= 0 || *chunk == 0 {
+		log.Fatal("--workers and --chunk must be positive")
+	}
+	address := common.HexToAddress(*addressHex)
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	// 大范围扫描耗时较长，不设置整体超时，Ctrl+C 中断
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatalf("failed to get chain id: %v", err)
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		log.Fatalf("failed to get block number: %v", err)
+	}
+
+	to := head
+	if *toBlock >= 0 {
+		to = min(uint64(*toBlock), head)
+	}
+	var from uint64
+	switch {
+	case *since > 0:
+		if from, err = findBlockByTime(ctx, client, time.Now().Add(-*since), to); err != nil {
+			log.Fatalf("failed to find start block: %v", err)
+		}
+	case *fromBlock >= 0:
+		from = uint64(*fromBlock)
+	case to > 7200:
+		from = to - 7200
+	}
+	if from > to {
+		log.Fatalf("empty block range %d - %d", from, to)
+	}
+
+	c := &collector{
+		client:  client,
+		signer:  types.LatestSignerForChainID(chainID),
+		address: address,
+		workers: *workers,
+	}
+	useTrace := false
+	switch *mode {
+	case "trace":
+		useTrace = true
+	case "scan":
+	case "auto":
+		useTrace = c.traceSupported(ctx, to)
+	default:
+		log.Fatalf("unknown --mode %q", *mode)
+	}
+
+	var records []*txRecord
+	if useTrace {
+		log.Printf("analyzing %s in blocks %d - %d via trace_filter", address.Hex(), from, to)
+		records, err = c.traceFilter(ctx, from, to, *chunk)
+	} else {
+		log.Printf("analyzing %s in blocks %d - %d via block scan (%d blocks)", address.Hex(), from, to, to-from+1)
+		records, err = c.scanBlocks(ctx, from, to)
+	}
+	if err != nil {
+		log.Fatalf("failed to collect transactions: %v", err)
+	}
+
+	printReport(out, address, from, to, records, *top)
+
+	if *csvPath != "" {
+		if err := writeCSV(*csvPath, records); err != nil {
+			log.Fatalf("failed to write CSV: %v", err)
+		}
+		out.Note("\nwrote %d rows to %s", len(records), *csvPath)
+	}
+
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
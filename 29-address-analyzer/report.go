@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// knownMethods 常见方法选择器，用于方法统计时显示可读名称
+var knownMethods = map[string]string{}
+
+func init() {
+	for _, sig := range []string{
+		"transfer(address,uint256)",
+		"transferFrom(address,address,uint256)",
+		"approve(address,uint256)",
+		"safeTransferFrom(address,address,uint256)",
+		"safeTransferFrom(address,address,uint256,bytes)",
+		"setApprovalForAll(address,bool)",
+		"deposit()",
+		"withdraw(uint256)",
+		"multicall(bytes[])",
+		"multicall(uint256,bytes[])",
+		"execute(bytes,bytes[],uint256)",
+		"swapExactTokensForTokens(uint256,uint256,address[],address,uint256)",
+		"swapExactETHForTokens(uint256,address[],address,uint256)",
+		"exactInputSingle((address,address,uint24,address,uint256,uint256,uint160))",
+		"execTransaction(address,uint256,bytes,uint8,uint256,uint256,uint256,address,address,bytes)",
+	} {
+		knownMethods[string(crypto.Keccak256([]byte(sig))[:4])] = sig
+	}
+}
+
+// methodName 交易的方法名：无 calldata 为 ETH 转账，已知选择器显示签名，否则显示选择器
+func methodName(data []byte) string {
+	if len(data) == 0 {
+		return "(ETH transfer)"
+	}
+	if len(data) < 4 {
+		return fmt.Sprintf("(invalid calldata, %d bytes)", len(data))
+	}
+	if sig, ok := knownMethods[string(data[:4])]; ok {
+		return sig
+	}
+	return fmt.Sprintf("0x%x", data[:4])
+}
+
+// counter 分组统计
+type counter struct {
+	Key   string
+	Count int
+	Fee   *big.Int
+}
+
+// tally 按 key 分组计数和累计手续费，按次数降序返回
+func tally(records []*txRecord, key func(*txRecord) (string, bool)) []*counter {
+	groups := make(map[string]*counter)
+	for _, r := range records {
+		k, ok := key(r)
+		if !ok {
+			continue
+		}
+		c, exists := groups[k]
+		if !exists {
+			c = &counter{Key: k, Fee: new(big.Int)}
+			groups[k] = c
+		}
+		c.Count++
+		if r.Direction == "out" || r.Direction == "self" {
+			c.Fee.Add(c.Fee, r.Fee)
+		}
+	}
+	list := make([]*counter, 0, len(groups))
+	for _, c := range groups {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
+
+// printReport 输出汇总：交易数量、手续费、成功率、交易对手和方法分布
+func printReport(out *output.Printer, address common.Address, from, to uint64, records []*txRecord, top int) {
+	var sent, failed, received, internal int
+	var gasUsed uint64
+	totalFee, maxFee, sentValue, receivedValue := new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+	for _, r := range records {
+		switch r.Direction {
+		case "out", "self":
+			sent++
+			if !r.Success {
+				failed++
+			}
+			gasUsed += r.GasUsed
+			totalFee.Add(totalFee, r.Fee)
+			if r.Fee.Cmp(maxFee) > 0 {
+				maxFee.Set(r.Fee)
+			}
+			if r.Direction == "out" && r.Success {
+				sentValue.Add(sentValue, r.Value)
+			}
+		case "in":
+			received++
+			if r.Success {
+				receivedValue.Add(receivedValue, r.Value)
+			}
+		default:
+			internal++
+		}
+	}
+
+	out.Section("Address Activity")
+	out.Field("Address", address)
+	out.Field("Blocks", fmt.Sprintf("%d - %d", from, to))
+	if len(records) > 0 {
+		out.Field("Period", fmt.Sprintf("%s - %s", records[0].Time.Format(time.RFC3339), records[len(records)-1].Time.Format(time.RFC3339)))
+	}
+	out.Field("Transactions", len(records))
+	out.Field("Sent", sent)
+	out.Field("Received", received)
+	out.Field("Internal", internal)
+	if sent > 0 {
+		out.Field("Success", fmt.Sprintf("%d / %d (%.1f%%)", sent-failed, sent, 100*float64(sent-failed)/float64(sent)))
+		out.Field("Gas Used", gasUsed)
+		out.Field("Total Fee (ETH)", ethutil.FormatEth(totalFee, 6))
+		avg := new(big.Int).Div(totalFee, big.NewInt(int64(sent)))
+		out.Field("Avg Fee (ETH)", ethutil.FormatEth(avg, 6))
+		out.Field("Max Fee (ETH)", ethutil.FormatEth(maxFee, 6))
+		if failed > 0 {
+			// 失败交易浪费的手续费
+			var failedFee big.Int
+			for _, r := range records {
+				if !r.Success && (r.Direction == "out" || r.Direction == "self") {
+					failedFee.Add(&failedFee, r.Fee)
+				}
+			}
+			out.Field("Failed Fee (ETH)", ethutil.FormatEth(&failedFee, 6))
+		}
+	}
+	out.Field("ETH Sent", ethutil.FormatEth(sentValue, 6))
+	// 只统计顶层交易转入的 ETH
+	out.Field("ETH Received", ethutil.FormatEth(receivedValue, 6))
+
+	counterparties := tally(records, func(r *txRecord) (string, bool) {
+		switch r.Direction {
+		case "out":
+			if r.To == nil {
+				return "(contract creation)", true
+			}
+			return r.To.Hex(), true
+		case "in", "internal":
+			return r.From.Hex(), true
+		}
+		return "", false
+	})
+	methods := tally(records, func(r *txRecord) (string, bool) {
+		return r.Method, r.Direction == "out" || r.Direction == "self"
+	})
+	out.Field("Counterparties", len(counterparties))
+	out.Field("Methods", len(methods))
+
+	out.Table("Top Counterparties", "Count", "Fee (ETH)", "Counterparty")
+	printCounters(out, counterparties, top)
+	out.Table("Methods Called", "Count", "Fee (ETH)", "Method")
+	printCounters(out, methods, top)
+}
+
+// printCounters 输出前 top 个分组
+func printCounters(out *output.Printer, list []*counter, top int) {
+	for i, c := range list {
+		if i == top {
+			out.Note("  ... %d more", len(list)-top)
+			break
+		}
+		out.Row(c.Count, ethutil.FormatEth(c.Fee, 6), c.Key)
+	}
+}
+
+// writeCSV 导出明细，金额使用 wei 保证精度
+func writeCSV(path string, records []*txRecord) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"block", "index", "time", "hash", "direction", "from", "to", "value_wei", "method", "status", "gas_used", "fee_wei"})
+	for _, r := range records {
+		to := ""
+		if r.To != nil {
+			to = r.To.Hex()
+		}
+		status := "success"
+		if !r.Success {
+			status = "failed"
+		}
+		w.Write([]string{
+			strconv.FormatUint(r.Block, 10),
+			strconv.FormatUint(uint64(r.Index), 10),
+			r.Time.UTC().Format(time.RFC3339),
+			r.Hash.Hex(),
+			r.Direction,
+			r.From.Hex(),
+			to,
+			r.Value.String(),
+			r.Method,
+			status,
+			strconv.FormatUint(r.GasUsed, 10),
+			r.Fee.String(),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// actor 触发后执行的动作：向固定地址发送预先构造好的 calldata / value
+type actor struct {
+	client   *ethclient.Client
+	key      *ecdsa.PrivateKey
+	from     common.Address
+	chainID  *big.Int
+	to       common.Address
+	data     []byte
+	value    *big.Int
+	maxFee   *big.Int // gasFeeCap 上限，超过时放弃本次动作
+	execute  bool     // false 为 dry-run：构造、模拟、签名，但不广播
+	cooldown time.Duration
+	limit    int // 最多执行次数，0 不限制
+	out      *output.Printer
+}
+
+// errSkipped 守卫条件不满足，本次触发不执行动作
+var errSkipped = errors.New("skipped")
+
+// checkGuards 执行动作前的保护：冷却时间、次数上限、未确认的上一笔动作、账户是否有其他待处理交易
+func (a *actor) checkGuards(ctx context.Context, st *botState) error {
+	if a.limit > 0 && st.Actions >= a.limit {
+		return fmt.Errorf("%w: reached --max-actions %d", errSkipped, a.limit)
+	}
+	if since := time.Since(st.LastActionAt); since < a.cooldown {
+		return fmt.Errorf("%w: cooldown, %s remaining", errSkipped, (a.cooldown - since).Truncate(time.Second))
+	}
+	if st.PendingTx != nil {
+		if err := a.resolvePending(ctx, st); err != nil {
+			return err
+		}
+	}
+	if !a.execute {
+		return nil
+	}
+	// 账户在交易池中还有其他交易（人工操作或另一个实例）时不发送，避免 nonce 冲突或重复执行
+	latest, err := a.client.NonceAt(ctx, a.from, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	pending, err := a.client.PendingNonceAt(ctx, a.from)
+	if err != nil {
+		return fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	if pending != latest {
+		return fmt.Errorf("%w: %d transactions from %s still pending", errSkipped, pending-latest, a.from.Hex())
+	}
+	return nil
+}
+
+// resolvePending 检查上一笔动作交易：已确认则清除；仍在交易池中则跳过；节点上找不到（被丢弃或从未广播）也清除
+func (a *actor) resolvePending(ctx context.Context, st *botState) error {
+	hash := *st.PendingTx
+	receipt, err := a.client.TransactionReceipt(ctx, hash)
+	if err == nil {
+		status := "success"
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			status = "FAILED"
+		}
+		log.Printf("previous action %s mined in block %d (%s)", hash.Hex(), receipt.BlockNumber.Uint64(), status)
+		st.PendingTx = nil
+		return nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return fmt.Errorf("failed to get receipt %s: %w", hash.Hex(), err)
+	}
+	if _, _, err := a.client.TransactionByHash(ctx, hash); err == nil {
+		return fmt.Errorf("%w: previous action %s still pending", errSkipped, hash.Hex())
+	} else if !errors.Is(err, ethereum.NotFound) {
+		return fmt.Errorf("failed to get transaction %s: %w", hash.Hex(), err)
+	}
+	log.Printf("[WARN] previous action %s not found on node (dropped?), clearing", hash.Hex())
+	st.PendingTx = nil
+	return nil
+}
+
+// act 构造、模拟并签名动作交易；execute 时先把交易哈希写入状态文件再广播，
+// 即使广播后进程崩溃，重启时也能找到这笔交易而不会重复发送
+func (a *actor) act(ctx context.Context, st *botState, f firing) error {
+	nonce, err := a.client.PendingNonceAt(ctx, a.from)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	// EstimateGas 同时是模拟：条件已被其他人抢先处理时通常会 revert，此时放弃
+	gasLimit, err := a.client.EstimateGas(ctx, ethereum.CallMsg{From: a.from, To: &a.to, Value: a.value, Data: a.data})
+	if err != nil {
+		return fmt.Errorf("%w: simulation failed: %v", errSkipped, err)
+	}
+	gasLimit = gasLimit * 120 / 100
+
+	gasTipCap, gasFeeCap, err := ethutil.DynamicFees(ctx, a.client)
+	if err != nil {
+		return fmt.Errorf("failed to compute fees: %w", err)
+	}
+	if a.maxFee != nil && gasFeeCap.Cmp(a.maxFee) > 0 {
+		// 手续费上限只约束 feeCap，不调低：低于 2×baseFee 的 feeCap 很容易在下一个区块就失效
+		return fmt.Errorf("%w: fee cap %s gwei exceeds --max-fee-gwei", errSkipped, new(big.Int).Div(gasFeeCap, big.NewInt(params.GWei)))
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   a.chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &a.to,
+		Value:     a.value,
+		Data:      a.data,
+	})
+	signedTx, err := types.SignTx(tx, types.NewLondonSigner(a.chainID), a.key)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	hash := signedTx.Hash()
+
+	if !a.execute {
+		a.out.Note("[DRY-RUN] would send %s: to=%s value=%s gas=%d nonce=%d feeCap=%s tip=%s",
+			hash.Hex(), a.to.Hex(), a.value, gasLimit, nonce, gasFeeCap, gasTipCap)
+		a.record("dry-run", "hash", hash, "to", a.to, "value", a.value, "gas", gasLimit, "nonce", nonce,
+			"fee_cap", gasFeeCap, "tip_cap", gasTipCap, "reason", f.Reason)
+		st.LastActionAt = time.Now()
+		return nil
+	}
+
+	st.PendingTx = &hash
+	st.LastActionAt = time.Now()
+	st.Actions++
+	if err := st.save(); err != nil {
+		return fmt.Errorf("failed to save state before sending: %w", err)
+	}
+	if err := a.client.SendTransaction(ctx, signedTx); err != nil {
+		// 广播失败：交易没有进入交易池，下一轮 resolvePending 会发现并清除
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	a.out.Note("sent action tx %s (nonce %d) for %s", hash.Hex(), nonce, f.Reason)
+	a.record("sent", "hash", hash, "to", a.to, "value", a.value, "gas", gasLimit, "nonce", nonce,
+		"fee_cap", gasFeeCap, "tip_cap", gasTipCap, "reason", f.Reason)
+	return nil
+}
+
+// record 在结构化格式下输出一条动作记录
+func (a *actor) record(kind string, kv ...any) {
+	if err := a.out.Record(kind, kv...); err != nil {
+		log.Printf("[WARN] failed to write record: %v", err)
+	}
+}
+
+// newActor 从私钥创建动作执行者
+func newActor(ctx context.Context, client *ethclient.Client, key *ecdsa.PrivateKey) (*actor, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	return &actor{
+		client:  client,
+		key:     key,
+		from:    crypto.PubkeyToAddress(key.PublicKey),
+		chainID: chainID,
+	}, nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 30-event-bot
+// 事件驱动的自动化机器人骨架：监听链上条件，满足时通过签名者发送预先构造好的交易。
+// 触发条件（二选一）：
+// - --event：合约发出指定事件，每条日志触发一次
+// - --call-data + --op + --threshold：每个区块 eth_call 读取一个 uint256，与阈值比较，条件由假变真时触发一次
+// 动作：向 --action-to 发送 --action-data / --action-value（EIP-1559，费用策略与 08-contract-interact 相同）
+// 保护措施：
+// - 默认 dry-run：构造、模拟（EstimateGas）、签名并打印交易，不广播；加 --execute 才真正发送
+// - --cooldown 两次动作的最小间隔，--max-actions 动作次数上限，--max-fee-gwei 手续费上限
+// - 幂等：已处理的触发 ID、检查进度和未确认的动作交易持久化到 --state-file；
+//   交易先写入状态文件再广播，上一笔动作确认前不会发送新动作，账户有其他待处理交易时也不会发送
+// - 只处理已有 --confirmations 个确认的区块，降低重组导致误触发的概率
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://sepolia.infura.io/v3/<project-id>"
+//    export SENDER_PRIVATE_KEY="0x..."
+//    # 合约发出 Paused() 事件时调用 harvest()（先 dry-run 观察）
+//    go run . --contract 0x... --event "Paused(address)" --action-to 0x... --action-data 0x4641257d
+//    # 余额低于 1 ETH（balanceOf(vault)）时调用 refill()，实际发送
+//    go run . --contract 0x... --call-data 0x70a08231000000000000000000000000<vault> --op lt --threshold 1000000000000000000 \
+//        --action-to 0x... --action-data 0x... --cooldown 1h --execute
+//    # 每个动作输出一行 JSON（dry-run / sent）
+//    go run . --contract 0x... --event "Paused(address)" --action-to 0x... --format json
+//
+// 注意事项：
+// - 这是骨架：真实场景中动作的 calldata 往往依赖触发事件的内容，可以在 actor.act 中根据 firing 构造
+// - 多个实例使用同一个私钥时，状态文件无法跨进程协调，请只运行一个实例
+
+func main() {
+	contractHex := flag.String("contract", "", "contract to watch (required)")
+	eventSig := flag.String("event", "", "event signature to trigger on, e.g. Transfer(address,address,uint256)")
+	callDataHex := flag.String("call-data", "", "calldata for state trigger; the first returned uint256 is compared with --threshold")
+	op := flag.String("op", "gt", "state trigger comparison: gt | lt")
+	thresholdStr := flag.String("threshold", "0", "state trigger threshold (integer)")
+	actionToHex := flag.String("action-to", "", "action transaction recipient (required)")
+	actionDataHex := flag.String("action-data", "", "action transaction calldata (hex)")
+	actionValue := flag.String("action-value", "0", "action transaction value in wei")
+	execute := flag.Bool("execute", false, "actually broadcast transactions (default: dry-run)")
+	cooldown := flag.Duration("cooldown", 5*time.Minute, "minimum time between actions")
+	maxActions := flag.Int("max-actions", 0, "stop acting after this many transactions (0 = unlimited)")
+	maxFeeGwei := flag.Int64("max-fee-gwei", 0, "skip the action when the fee cap exceeds this (0 = no limit)")
+	confirmations := flag.Uint64("confirmations", 2, "only check blocks with at least this many confirmations")
+	poll := flag.Duration("poll", 12*time.Second, "polling interval")
+	statePath := flag.String("state-file", "bot-state.json", "state file for idempotency across restarts")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if !common.IsHexAddress(*contractHex) || !common.IsHexAddress(*actionToHex) {
+		log.Fatal("missing or invalid --contract / --action-to")
+	}
+	if (*eventSig == "") == (*callDataHex == "") {
+		log.Fatal("exactly one of --event or --call-data is required")
+	}
+	actionData, err := hexutil.Decode(orEmpty(*actionDataHex))
+	if err != nil {
+		log.Fatalf("invalid --action-data: %v", err)
+	}
+	value, ok := new(big.Int).SetString(*actionValue, 10)
+	if !ok || value.Sign() < 0 {
+		log.Fatalf("invalid --action-value %q", *actionValue)
+	}
+
+	st, err := loadState(*statePath)
+	if err != nil {
+		log.Fatalf("failed to load state: %v", err)
+	}
+
+	contract := common.HexToAddress(*contractHex)
+	var trig trigger
+	if *eventSig != "" {
+		trig = &eventTrigger{contract: contract, signature: *eventSig, topic: crypto.Keccak256Hash([]byte(*eventSig))}
+	} else {
+		callData, err := hexutil.Decode(*callDataHex)
+		if err != nil {
+			log.Fatalf("invalid --call-data: %v", err)
+		}
+		threshold, ok := new(big.Int).SetString(*thresholdStr, 0)
+		if !ok {
+			log.Fatalf("invalid --threshold %q", *thresholdStr)
+		}
+		if *op != "gt" && *op != "lt" {
+			log.Fatalf("unknown --op %q", *op)
+		}
+		trig = &stateTrigger{contract: contract, callData: callData, op: *op, threshold: threshold, armed: &st.Armed}
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+	privKeyHex := config.Get().PrivateKey
+	if privKeyHex == "" {
+		log.Fatal("SENDER_PRIVATE_KEY is not set")
+	}
+	privKey, err := crypto.HexToECDSA(strings.TrimPrefix(privKeyHex, "0x"))
+	if err != nil {
+		log.Fatalf("invalid private key: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	a, err := newActor(ctx, client, privKey)
+	if err != nil {
+		log.Fatalf("failed to create actor: %v", err)
+	}
+	a.to = common.HexToAddress(*actionToHex)
+	a.data = actionData
+	a.value = value
+	a.execute = *execute
+	a.cooldown = *cooldown
+	a.limit = *maxActions
+	a.out = out
+	if *maxFeeGwei > 0 {
+		a.maxFee = new(big.Int).Mul(big.NewInt(*maxFeeGwei), big.NewInt(params.GWei))
+	}
+
+	mode := "DRY-RUN"
+	if *execute {
+		mode = "EXECUTE"
+	}
+	out.Note("=== Event Bot ===")
+	out.Note("Mode        : %s", mode)
+	out.Note("Trigger     : %s", trig.Describe())
+	out.Note("Action      : %s -> %s (%d bytes, %s wei)", a.from.Hex(), a.to.Hex(), len(a.data), a.value)
+	out.Note("State File  : %s (last block %d, %d actions)", *statePath, st.LastBlock, st.Actions)
+
+	ticker := time.NewTicker(*poll)
+	defer ticker.Stop()
+	for {
+		if err := tick(ctx, client, trig, a, st, *confirmations); err != nil {
+			log.Printf("[WARN] %v", err)
+		}
+		if err := st.save(); err != nil {
+			log.Printf("[WARN] failed to save state: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			out.Note("shutting down")
+			return
+		}
+	}
+}
+
+// tick 检查新确认的区块并处理触发
+func tick(ctx context.Context, client *ethclient.Client, trig trigger, a *actor, st *botState, confirmations uint64) error {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+	if head < confirmations {
+		return nil
+	}
+	to := head - confirmations
+	// 首次运行从当前区块开始，不处理历史
+	if st.LastBlock == 0 && to > 0 {
+		st.LastBlock = to - 1
+	}
+	if to <= st.LastBlock {
+		return nil
+	}
+	from := st.LastBlock + 1
+	// 长时间停机后只补查最近一段，避免对很久以前的事件做出反应
+	const maxRange = 2000
+	if to-from+1 > maxRange {
+		log.Printf("[WARN] %d blocks behind, skipping to %d", to-from+1, to-maxRange+1)
+		from = to - maxRange + 1
+	}
+
+	firings, err := trig.Check(ctx, client, from, to)
+	if err != nil {
+		return err
+	}
+	st.LastBlock = to
+
+	for _, f := range firings {
+		if _, done := st.Handled[f.ID]; done {
+			continue
+		}
+		log.Printf("triggered at block %d: %s", f.Block, f.Reason)
+		// 无论执行还是跳过都记为已处理：触发时机已过，稍后再执行可能基于过期的条件
+		st.markHandled(f.ID)
+		err := a.checkGuards(ctx, st)
+		if err == nil {
+			err = a.act(ctx, st, f)
+		}
+		if errors.Is(err, errSkipped) {
+			log.Printf("action %v", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orEmpty 空字符串视为空字节
+func orEmpty(s string) string {
+	if s == "" {
+		return "0x"
+	}
+	return s
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxHandled 最多记住的已处理触发数量（只需覆盖重组 / 重启可能重放的范围）
+const maxHandled = 1000
+
+// botState 机器人持久化状态，是幂等保护的基础：
+// - LastBlock：已检查到的区块，重启后从下一个区块继续，不会重新检查
+// - Handled：已处理的触发 ID，同一事件即使被重复扫描也不会再次执行动作
+// - PendingTx：已广播但尚未确认的动作交易，确认前不会发送新的动作
+// - Armed：状态阈值触发器是否处于待触发状态（只在条件由假变真时触发一次）
+type botState struct {
+	LastBlock    uint64               `json:"last_block"`
+	Handled      map[string]time.Time `json:"handled"`
+	LastActionAt time.Time            `json:"last_action_at"`
+	PendingTx    *common.Hash         `json:"pending_tx,omitempty"`
+	Actions      int                  `json:"actions"`
+	Armed        bool                 `json:"armed"`
+
+	path string
+}
+
+// loadState 读取状态文件，不存在时返回初始状态
+func loadState(path string) (*botState, error) {
+	s := &botState{Handled: make(map[string]time.Time), Armed: true, path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse state %s: %w", path, err)
+	}
+	if s.Handled == nil {
+		s.Handled = make(map[string]time.Time)
+	}
+	return s, nil
+}
+
+// markHandled 记录触发 ID，超过上限时淘汰最早的记录
+func (s *botState) markHandled(id string) {
+	s.Handled[id] = time.Now()
+	for len(s.Handled) > maxHandled {
+		var oldestID string
+		var oldest time.Time
+		for k, t := range s.Handled {
+			if oldestID == "" || t.Before(oldest) {
+				oldestID, oldest = k, t
+			}
+		}
+		delete(s.Handled, oldestID)
+	}
+}
+
+// save 写入状态文件（与 09-project 的检查点相同：先写临时文件再 rename）
+func (s *botState) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".bot-state-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// firing 一次触发
+type firing struct {
+	ID     string // 幂等键：同一个 ID 只会执行一次动作
+	Block  uint64
+	Reason string
+}
+
+// trigger 链上条件；Check 检查 [from, to] 区块范围，返回新的触发
+type trigger interface {
+	Check(ctx context.Context, client *ethclient.Client, from, to uint64) ([]firing, error)
+	Describe() string
+}
+
+// eventTrigger 合约发出指定事件时触发，每条日志一次
+type eventTrigger struct {
+	contract  common.Address
+	signature string
+	topic     common.Hash
+}
+
+// Describe 触发器说明
+func (t *eventTrigger) Describe() string {
+	return fmt.Sprintf("event %s on %s", t.signature, t.contract.Hex())
+}
+
+// Check 查询范围内的事件日志
+func (t *eventTrigger) Check(ctx context.Context, client *ethclient.Client, from, to uint64) ([]firing, error) {
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{t.contract},
+		Topics:    [][]common.Hash{{t.topic}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs: %w", err)
+	}
+	var out []firing
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		out = append(out, firing{
+			// 用 (交易哈希, 日志序号) 而不是区块号作为 ID：重组后同一事件被打包进其他区块时不会重复触发
+			ID:     fmt.Sprintf("%s:%d", l.TxHash.Hex(), l.Index),
+			Block:  l.BlockNumber,
+			Reason: fmt.Sprintf("%s in tx %s", t.signature, l.TxHash.Hex()),
+		})
+	}
+	return out, nil
+}
+
+// stateTrigger 每个区块读取一次合约状态（eth_call 返回的第一个 uint256），与阈值比较；
+// 边沿触发：条件由假变真时触发一次，条件恢复为假后重新待触发
+type stateTrigger struct {
+	contract  common.Address
+	callData  []byte
+	op        string // gt | lt
+	threshold *big.Int
+	armed     *bool // 指向持久化状态，重启后保持
+}
+
+// Describe 触发器说明
+func (t *stateTrigger) Describe() string {
+	return fmt.Sprintf("call 0x%x on %s %s %s", t.callData, t.contract.Hex(), t.op, t.threshold)
+}
+
+// Check 只读取范围内最新区块的状态：中间区块的状态对于"当前是否需要行动"没有意义
+func (t *stateTrigger) Check(ctx context.Context, client *ethclient.Client, from, to uint64) ([]firing, error) {
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &t.contract, Data: t.callData}, new(big.Int).SetUint64(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to call contract: %w", err)
+	}
+	if len(out) < 32 {
+		return nil, fmt.Errorf("call returned %d bytes, expected at least 32", len(out))
+	}
+	value := new(big.Int).SetBytes(out[:32])
+
+	var met bool
+	switch t.op {
+	case "gt":
+		met = value.Cmp(t.threshold) > 0
+	case "lt":
+		met = value.Cmp(t.threshold) < 0
+	}
+	if !met {
+		*t.armed = true
+		return nil, nil
+	}
+	if !*t.armed {
+		return nil, nil
+	}
+	*t.armed = false
+	return []firing{{
+		ID:     fmt.Sprintf("state:%d", to),
+		Block:  to,
+		Reason: fmt.Sprintf("value %s %s %s at block %d", value, t.op, t.threshold, to),
+	}}, nil
+}
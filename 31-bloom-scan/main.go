@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 31-bloom-scan
+// 用区块头的 logsBloom 预筛，加速大范围历史日志扫描（自建索引时的常用技巧）：
+// - bloom：批量（JSON-RPC batch）获取区块头，检查 logsBloom 中是否可能包含目标地址 / 事件 topic，
+//   只对命中的候选区块按 blockHash 调用 eth_getLogs；bloom 只有误报没有漏报，结果与完整扫描一致
+// - getlogs：朴素方式，按 --chunk 个区块一段调用 eth_getLogs
+// - both（默认）：两种方式都运行，对比请求数、耗时、误报率，并校验结果一致
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://mainnet.infura.io/v3/<project-id>"
+//    go run . --address 0x... --topic "OwnershipTransferred(address,address)" --from-block 19000000 --to-block 19020000
+//    go run . --address 0x... --mode bloom --batch 200 --workers 8 --from-block 18000000 --to-block 19000000
+//    go run . --address 0x... --from-block 19000000 --to-block 19001000 --format json   # 输出格式：pretty（默认）| json | yaml | csv
+//
+// 注意事项：
+// - 节点实现 eth_getLogs 时自己也会用 bloom（geth 还有按段聚合的 bloombits 索引），对于稀有事件朴素方式未必慢；
+//   bloom 预筛的优势在于：提供商限制 eth_getLogs 范围 / 结果条数时，区块头请求便宜且可以大批量并发，
+//   而且区块头可以缓存复用于多个过滤条件
+// - 热门合约（如 USDT、WETH）几乎每个区块都命中，此时预筛只会增加请求数
+// - 部分提供商限制批量请求大小，请按需调整 --batch
+
+func main() {
+	addressHex := flag.String("address", "", "contract address emitting the logs (required)")
+	topicFlag := flag.String("topic", "", "event topic0: 0x hash or event signature, e.g. Transfer(address,address,uint256)")
+	fromBlock := flag.Uint64("from-block", 0, "first block (required)")
+	toBlock := flag.Int64("to-block", -1, "last block (-1 = latest)")
+	mode := flag.String("mode", "both", "scan mode: bloom | getlogs | both")
+	chunk := flag.Uint64("chunk", 2000, "block range per eth_getLogs request in getlogs mode")
+	batchSize := flag.Int("batch", 100, "headers per JSON-RPC batch in bloom mode")
+	workers := flag.Int("workers", 4, "concurrent header batches in bloom mode")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if !common.IsHexAddress(*addressHex) {
+		log.Fatal("missing or invalid --address")
+	}
+	if *fromBlock == 0 {
+		log.Fatal("--from-block is required")
+	}
+	if *mode != "bloom" && *mode != "getlogs" && *mode != "both" {
+		log.Fatalf("unknown --mode %q", *mode)
+	}
+	if *chunk == 0 || *batchSize <= 0 || *workers <= 0 {
+		log.Fatal("--chunk, --batch and --workers must be positive")
+	}
+	var topic *common.Hash
+	if *topicFlag != "" {
+		t := parseTopic(*topicFlag)
+		topic = &t
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	to := uint64(*toBlock)
+	if *toBlock < 0 {
+		if to, err = client.BlockNumber(ctx); err != nil {
+			log.Fatalf("failed to get block number: %v", err)
+		}
+	}
+	if *fromBlock > to {
+		log.Fatalf("empty block range %d - %d", *fromBlock, to)
+	}
+
+	s := &scanner{client: client, address: common.HexToAddress(*addressHex), topic: topic, batchSize: *batchSize, workers: *workers}
+	out.Section("Bloom Scan")
+	out.Field("Address", s.address)
+	if topic != nil {
+		out.Field("Topic", *topic)
+	}
+	out.Field("Blocks", fmt.Sprintf("%d - %d", *fromBlock, to))
+	out.Field("Block Count", to-*fromBlock+1)
+
+	// 两种方式都扫描完再输出，表格中每种方式一行
+	var bloomRes, logsRes *scanResult
+	if *mode == "bloom" || *mode == "both" {
+		if bloomRes, err = s.scanBloom(ctx, *fromBlock, to); err != nil {
+			log.Fatalf("bloom scan failed: %v", err)
+		}
+	}
+	if *mode == "getlogs" || *mode == "both" {
+		if logsRes, err = s.scanGetLogs(ctx, *fromBlock, to, *chunk); err != nil {
+			log.Fatalf("eth_getLogs scan failed: %v", err)
+		}
+	}
+
+	out.Table("Results", "Mode", "Logs", "Requests", "Round Trips", "Elapsed", "Candidates", "False Positives")
+	if bloomRes != nil {
+		printResult(out, "bloom", bloomRes)
+	}
+	if logsRes != nil {
+		printResult(out, "getlogs", logsRes)
+	}
+
+	if bloomRes != nil && logsRes != nil {
+		out.Section("Comparison")
+		out.Field("Speedup", fmt.Sprintf("%.2fx", logsRes.Elapsed.Seconds()/bloomRes.Elapsed.Seconds()))
+		if missing, extra := diffLogs(logsRes.Logs, bloomRes.Logs); missing == 0 && extra == 0 {
+			out.Field("Results", "✓ identical")
+		} else {
+			out.Field("Results", fmt.Sprintf("✗ bloom scan missing %d, extra %d (reorg during scan?)", missing, extra))
+		}
+	}
+
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printResult 输出一种扫描方式的统计；候选区块和误报只有 bloom 方式才有
+func printResult(out *output.Printer, name string, r *scanResult) {
+	candidates, falsePos := "-", "-"
+	if name == "bloom" {
+		candidates = fmt.Sprint(r.Candidates)
+		falsePos = fmt.Sprintf("%d (%.1f%%)", r.FalsePos, percent(r.FalsePos, r.Candidates))
+	}
+	out.Row(name, len(r.Logs), r.Requests, r.Batches, r.Elapsed.Round(1e6).String(), candidates, falsePos)
+}
+
+// parseTopic 解析 topic：0x 开头的 32 字节哈希，或事件签名
+func parseTopic(s string) common.Hash {
+	if strings.HasPrefix(s, "0x") && len(s) == 66 {
+		return common.HexToHash(s)
+	}
+	return crypto.Keccak256Hash([]byte(s))
+}
+
+// diffLogs 以 (交易哈希, 日志序号) 比较两组日志
+func diffLogs(want, got []types.Log) (missing, extra int) {
+	key := func(l types.Log) string { return fmt.Sprintf("%s:%d", l.TxHash.Hex(), l.Index) }
+	seen := make(map[string]bool, len(got))
+	for _, l := range got {
+		seen[key(l)] = true
+	}
+	for _, l := range want {
+		if !seen[key(l)] {
+			missing++
+		}
+		delete(seen, key(l))
+	}
+	return missing, len(seen)
+}
+
+// percent 百分比，分母为 0 时返回 0
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
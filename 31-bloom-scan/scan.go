@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// scanResult 一种扫描方式的结果与开销统计
+type scanResult struct {
+	Logs       []types.Log
+	Requests   int64 // RPC 请求数（批量请求中的每一项单独计数）
+	Batches    int64 // HTTP 往返次数
+	Candidates int   // bloom 命中的区块数（仅 bloom 方式）
+	FalsePos   int   // bloom 命中但实际没有匹配日志的区块数
+	Elapsed    time.Duration
+}
+
+// scanner 扫描参数
+type scanner struct {
+	client    *ethclient.Client
+	address   common.Address
+	topic     *common.Hash // nil 表示不限事件
+	batchSize int
+	workers   int
+}
+
+// query 构造日志过滤条件
+func (s *scanner) query() ethereum.FilterQuery {
+	q := ethereum.FilterQuery{Addresses: []common.Address{s.address}}
+	if s.topic != nil {
+		q.Topics = [][]common.Hash{{*s.topic}}
+	}
+	return q
+}
+
+// scanGetLogs 朴素方式：按固定区块段调用 eth_getLogs
+func (s *scanner) scanGetLogs(ctx context.Context, from, to, chunk uint64) (*scanResult, error) {
+	start := time.Now()
+	res := &scanResult{}
+	for lo := from; lo <= to; lo += chunk {
+		hi := min(lo+chunk-1, to)
+		q := s.query()
+		q.FromBlock = new(big.Int).SetUint64(lo)
+		q.ToBlock = new(big.Int).SetUint64(hi)
+		logs, err := s.client.FilterLogs(ctx, q)
+		res.Requests++
+		res.Batches++
+		if err != nil {
+			return nil, fmt.Errorf("eth_getLogs %d-%d: %w", lo, hi, err)
+		}
+		res.Logs = append(res.Logs, logs...)
+	}
+	res.Elapsed = time.Since(start)
+	return res, nil
+}
+
+// bloomHeader 区块头中 bloom 预筛需要的字段
+type bloomHeader struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+	Bloom  types.Bloom    `json:"logsBloom"`
+}
+
+// scanBloom bloom 预筛：批量获取区块头，用 logsBloom 判断区块是否"可能"包含目标日志，
+// 只对候选区块按 blockHash 查询日志。bloom 没有漏报，只有误报，所以结果与 eth_getLogs 完全一致
+func (s *scanner) scanBloom(ctx context.Context, from, to uint64) (*scanResult, error) {
+	start := time.Now()
+	res := &scanResult{}
+
+	var (
+		mu         sync.Mutex
+		candidates []bloomHeader
+		firstErr   error
+		requests   atomic.Int64
+		batches    atomic.Int64
+	)
+	ranges := make(chan [2]uint64)
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range ranges {
+				found, err := s.screenRange(ctx, r[0], r[1])
+				requests.Add(int64(r[1] - r[0] + 1))
+				batches.Add(1)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				candidates = append(candidates, found...)
+				mu.Unlock()
+			}
+		}()
+	}
+	for lo := from; lo <= to; lo += uint64(s.batchSize) {
+		ranges <- [2]uint64{lo, min(lo+uint64(s.batchSize)-1, to)}
+	}
+	close(ranges)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Number < candidates[j].Number })
+	res.Candidates = len(candidates)
+	for _, h := range candidates {
+		q := s.query()
+		hash := h.Hash
+		q.BlockHash = &hash
+		logs, err := s.client.FilterLogs(ctx, q)
+		requests.Add(1)
+		batches.Add(1)
+		if err != nil {
+			return nil, fmt.Errorf("eth_getLogs block %d: %w", h.Number, err)
+		}
+		if len(logs) == 0 {
+			res.FalsePos++
+		}
+		res.Logs = append(res.Logs, logs...)
+	}
+	res.Requests = requests.Load()
+	res.Batches = batches.Load()
+	res.Elapsed = time.Since(start)
+	return res, nil
+}
+
+// screenRange 用一个批量请求获取 [lo, hi] 的区块头，返回 bloom 命中的区块
+func (s *scanner) screenRange(ctx context.Context, lo, hi uint64) ([]bloomHeader, error) {
+	headers := make([]*bloomHeader, hi-lo+1)
+	batch := make([]rpc.BatchElem, len(headers))
+	for i := range batch {
+		headers[i] = new(bloomHeader)
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []any{hexutil.EncodeUint64(lo + uint64(i)), false},
+			Result: headers[i],
+		}
+	}
+	if err := s.client.Client().BatchCallContext(ctx, batch); err != nil {
+		return nil, fmt.Errorf("batch headers %d-%d: %w", lo, hi, err)
+	}
+	var out []bloomHeader
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("header %d: %w", lo+uint64(i), elem.Error)
+		}
+		h := headers[i]
+		if !types.BloomLookup(h.Bloom, s.address) {
+			continue
+		}
+		if s.topic != nil && !types.BloomLookup(h.Bloom, *s.topic) {
+			continue
+		}
+		out = append(out, *h)
+	}
+	return out, nil
+}
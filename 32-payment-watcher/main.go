@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 32-payment-watcher
+// 入金检测服务（交易所 / 支付网关的典型集成方式）：
+// - 监听转入一组充值地址的原生 ETH 转账（顶层交易）和指定 ERC-20 代币的 Transfer 事件
+// - 新入金先记为 pending，达到 --confirmations 个确认后回调（POST --webhook，未设置时输出到标准输出）
+// - 每笔入金只回调一次：入金和通知状态持久化到 --store 文件，回调成功后立即落盘；
+//   请求头带 Idempotency-Key（入金 ID），设置 WEBHOOK_SECRET 时带 X-Signature-256（HMAC-SHA256）
+// - 重组处理：记录最近扫描的区块哈希，发现重组时回退到共同祖先，删除其中尚未确认的入金并重新扫描
+// - 回调失败按指数退避重试（最长 10 分钟一次）
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://sepolia.infura.io/v3/<project-id>"
+//    go run . --addresses 0xaaa...,0xbbb... --tokens 0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238 --confirmations 12
+//    WEBHOOK_SECRET=s3cret go run . --addresses @deposit-addresses.txt --webhook https://example.com/hooks/deposit
+//    # 每条 pending / confirmed 入金输出一行 JSON，便于交给其他程序处理
+//    go run . --addresses 0xaaa... --format json
+//
+// 注意事项：
+// - 原生 ETH 只检测顶层交易的 value，合约内部转账（例如从多签 / 合约钱包提现）需要 trace API，见 29-address-analyzer
+// - 投递语义是"至少一次"：回调成功但落盘前进程崩溃时会重复投递，接收方必须按 Idempotency-Key 去重
+// - 使用非标准代币（手续费代币、rebase 代币）时，Transfer 金额可能与实际到账不一致
+
+func main() {
+	addressesFlag := flag.String("addresses", "", "comma separated deposit addresses, or @file with one address per line (required)")
+	tokensFlag := flag.String("tokens", "", "comma separated ERC-20 token contracts to watch")
+	confirmations := flag.Uint64("confirmations", 12, "confirmations required before the callback")
+	webhookURL := flag.String("webhook", "", "POST confirmed deposits to this URL (default: print to stdout)")
+	storePath := flag.String("store", "deposits.json", "state file")
+	fromBlock := flag.Uint64("from-block", 0, "first block to scan on first start (default: latest)")
+	poll := flag.Duration("poll", 6*time.Second, "polling interval")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	addresses, err := parseAddressList(*addressesFlag)
+	if err != nil || len(addresses) == 0 {
+		log.Fatalf("missing or invalid --addresses: %v", err)
+	}
+	var tokens []common.Address
+	if *tokensFlag != "" {
+		if tokens, err = parseAddressList(*tokensFlag); err != nil {
+			log.Fatalf("invalid --tokens: %v", err)
+		}
+	}
+	if *confirmations == 0 {
+		log.Fatal("--confirmations must be positive")
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatalf("failed to get chain id: %v", err)
+	}
+	st, err := loadStore(*storePath)
+	if err != nil {
+		log.Fatalf("failed to load store: %v", err)
+	}
+	if st.LastBlock == 0 {
+		start := *fromBlock
+		if start == 0 {
+			if start, err = client.BlockNumber(ctx); err != nil {
+				log.Fatalf("failed to get block number: %v", err)
+			}
+		}
+		st.LastBlock = start - 1
+	}
+
+	w := &watcher{
+		client:        client,
+		signer:        types.LatestSignerForChainID(chainID),
+		deposits:      make(map[common.Address]bool),
+		tokens:        tokens,
+		store:         st,
+		confirmations: *confirmations,
+		out:           out,
+		notifier: &notifier{
+			url:     *webhookURL,
+			secret:  []byte(os.Getenv("WEBHOOK_SECRET")),
+			chainID: chainID.String(),
+			http:    &http.Client{},
+			out:     out,
+		},
+	}
+	for _, a := range addresses {
+		w.deposits[a] = true
+	}
+
+	out.Note("=== Payment Watcher ===")
+	out.Note("Chain ID    : %s", chainID)
+	out.Note("Addresses   : %d deposit addresses", len(addresses))
+	out.Note("Tokens      : %d (+ native ETH)", len(tokens))
+	out.Note("Confirm     : %d blocks", *confirmations)
+	out.Note("Resume From : %d", st.LastBlock+1)
+
+	ticker := time.NewTicker(*poll)
+	defer ticker.Stop()
+	for {
+		if err := w.poll(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[WARN] %v", err)
+		}
+		if err := st.save(); err != nil {
+			log.Printf("[WARN] failed to save store: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			out.Note("shutting down")
+			return
+		}
+	}
+}
+
+// parseAddressList 解析逗号分隔的地址，或 @file（每行一个地址，# 开头为注释）
+func parseAddressList(s string) ([]common.Address, error) {
+	var parts []string
+	if strings.HasPrefix(s, "@") {
+		data, err := os.ReadFile(strings.TrimPrefix(s, "@"))
+		if err != nil {
+			return nil, err
+		}
+		parts = strings.Split(string(data), "\n")
+	} else {
+		parts = strings.Split(s, ",")
+	}
+	var out []common.Address
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		if !common.IsHexAddress(p) {
+			return nil, fmt.Errorf("invalid address %q", p)
+		}
+		out = append(out, common.HexToAddress(p))
+	}
+	return out, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// recentBlocks 保留最近多少个已扫描区块的哈希，用于发现重组（需大于 --confirmations）
+const recentBlocks = 256
+
+// deposit 一笔入金
+type deposit struct {
+	ID         string         `json:"id"`    // 幂等键：交易哈希 + 日志序号（原生 ETH 为 "native"）
+	Asset      string         `json:"asset"` // "ETH" 或代币合约地址
+	From       common.Address `json:"from"`
+	To         common.Address `json:"to"`
+	Amount     *big.Int       `json:"amount"` // 最小单位（wei / 代币最小单位）
+	TxHash     common.Hash    `json:"tx_hash"`
+	Block      uint64         `json:"block_number"`
+	BlockHash  common.Hash    `json:"block_hash"`
+	SeenAt     time.Time      `json:"seen_at"`
+	NotifiedAt *time.Time     `json:"notified_at,omitempty"`
+	Attempts   int            `json:"attempts"`
+	NextTry    time.Time      `json:"next_try"`
+}
+
+// store 持久化状态：已扫描进度、最近区块哈希、所有入金及其通知状态
+type store struct {
+	LastBlock uint64                 `json:"last_block"`
+	Recent    map[uint64]common.Hash `json:"recent"`
+	Deposits  map[string]*deposit    `json:"deposits"`
+
+	path string
+}
+
+// loadStore 读取状态文件，不存在时返回空状态
+func loadStore(path string) (*store, error) {
+	s := &store{Recent: make(map[uint64]common.Hash), Deposits: make(map[string]*deposit), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse store %s: %w", path, err)
+	}
+	if s.Recent == nil {
+		s.Recent = make(map[uint64]common.Hash)
+	}
+	if s.Deposits == nil {
+		s.Deposits = make(map[string]*deposit)
+	}
+	return s, nil
+}
+
+// recordBlock 记录已扫描区块的哈希，淘汰过旧的记录
+func (s *store) recordBlock(number uint64, hash common.Hash) {
+	s.Recent[number] = hash
+	s.LastBlock = number
+	if number >= recentBlocks {
+		for n := range s.Recent {
+			if n <= number-recentBlocks {
+				delete(s.Recent, n)
+			}
+		}
+	}
+}
+
+// rewind 重组后回退到共同祖先：删除祖先之后的区块记录和其中尚未通知的入金，之后重新扫描
+func (s *store) rewind(ancestor uint64) []*deposit {
+	var dropped []*deposit
+	for n := range s.Recent {
+		if n > ancestor {
+			delete(s.Recent, n)
+		}
+	}
+	for id, d := range s.Deposits {
+		if d.Block > ancestor && d.NotifiedAt == nil {
+			dropped = append(dropped, d)
+			delete(s.Deposits, id)
+		}
+	}
+	s.LastBlock = ancestor
+	return dropped
+}
+
+// prune 删除已通知且早于重组窗口的入金：这些区块不会再被重新扫描，不需要再用于去重
+func (s *store) prune() {
+	if s.LastBlock < recentBlocks {
+		return
+	}
+	for id, d := range s.Deposits {
+		if d.NotifiedAt != nil && d.Block <= s.LastBlock-recentBlocks {
+			delete(s.Deposits, id)
+		}
+	}
+}
+
+// save 写入状态文件（与 09-project 的检查点相同：先写临时文件再 rename）
+func (s *store) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".deposits-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp store: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace store: %w", err)
+	}
+	return nil
+}
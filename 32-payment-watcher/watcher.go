@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// transferTopic ERC-20 Transfer(address,address,uint256) 事件签名
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// maxBlocksPerPoll 每轮最多扫描的区块数，避免长时间停机后一轮阻塞太久
+const maxBlocksPerPoll = 500
+
+// watcher 入金检测：逐块扫描原生 ETH 转账和代币 Transfer 日志，达到确认数后回调
+type watcher struct {
+	client        *ethclient.Client
+	signer        types.Signer
+	deposits      map[common.Address]bool
+	tokens        []common.Address
+	store         *store
+	notifier      *notifier
+	confirmations uint64
+	out           *output.Printer
+}
+
+// poll 一轮处理：检查重组 → 扫描新区块 → 处理达到确认数的入金
+func (w *watcher) poll(ctx context.Context) error {
+	head, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+	if err := w.checkReorg(ctx); err != nil {
+		return err
+	}
+	for n := w.store.LastBlock + 1; n <= head && n <= w.store.LastBlock+maxBlocksPerPoll; n++ {
+		reorged, err := w.scanBlock(ctx, n)
+		if err != nil {
+			return err
+		}
+		if reorged {
+			// 扫描过程中发生了重组，下一轮由 checkReorg 回退
+			break
+		}
+	}
+	w.confirm(ctx, head)
+	w.store.prune()
+	return nil
+}
+
+// checkReorg 最后扫描的区块已不在规范链上时，向前找到共同祖先并回退
+func (w *watcher) checkReorg(ctx context.Context) error {
+	last := w.store.LastBlock
+	known, ok := w.store.Recent[last]
+	if !ok {
+		return nil
+	}
+	h, err := w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(last))
+	if err != nil {
+		return fmt.Errorf("failed to get block %d: %w", last, err)
+	}
+	if h.Hash() == known {
+		return nil
+	}
+
+	ancestor := last
+	for {
+		ancestor--
+		known, ok := w.store.Recent[ancestor]
+		if !ok {
+			log.Printf("[WARN] reorg deeper than %d tracked blocks, rescanning from %d", recentBlocks, ancestor+1)
+			break
+		}
+		h, err := w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(ancestor))
+		if err != nil {
+			return fmt.Errorf("failed to get block %d: %w", ancestor, err)
+		}
+		if h.Hash() == known {
+			break
+		}
+	}
+	log.Printf("[REORG] block %d replaced, rewinding to %d", last, ancestor)
+	for _, d := range w.store.rewind(ancestor) {
+		log.Printf("[REORG] dropped unconfirmed deposit %s (%s %s to %s), will rescan", d.ID, d.Amount, d.Asset, d.To.Hex())
+	}
+	for _, d := range w.store.Deposits {
+		if d.Block > ancestor {
+			log.Printf("[WARN] already notified deposit %s was in a reorged block; raise --confirmations", d.ID)
+		}
+	}
+	return nil
+}
+
+// scanBlock 扫描单个区块；返回 true 表示区块的父哈希与已记录的不一致（发生了重组）
+func (w *watcher) scanBlock(ctx context.Context, n uint64) (bool, error) {
+	block, err := w.client.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return false, fmt.Errorf("failed to get block %d: %w", n, err)
+	}
+	if parent, ok := w.store.Recent[n-1]; ok && block.ParentHash() != parent {
+		return true, nil
+	}
+
+	var found []*deposit
+	for _, tx := range block.Transactions() {
+		to := tx.To()
+		if to == nil || !w.deposits[*to] || tx.Value().Sign() == 0 {
+			continue
+		}
+		receipt, err := w.client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return false, fmt.Errorf("failed to get receipt %s: %w", tx.Hash().Hex(), err)
+		}
+		// 失败的交易不会转移 ETH
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		from, err := types.Sender(w.signer, tx)
+		if err != nil {
+			return false, fmt.Errorf("failed to get sender of %s: %w", tx.Hash().Hex(), err)
+		}
+		found = append(found, &deposit{
+			ID:     tx.Hash().Hex() + ":native",
+			Asset:  "ETH",
+			From:   from,
+			To:     *to,
+			Amount: tx.Value(),
+			TxHash: tx.Hash(),
+		})
+	}
+
+	if len(w.tokens) > 0 {
+		toTopics := make([]common.Hash, 0, len(w.deposits))
+		for addr := range w.deposits {
+			toTopics = append(toTopics, common.BytesToHash(addr.Bytes()))
+		}
+		hash := block.Hash()
+		logs, err := w.client.FilterLogs(ctx, ethereum.FilterQuery{
+			BlockHash: &hash,
+			Addresses: w.tokens,
+			Topics:    [][]common.Hash{{transferTopic}, nil, toTopics},
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to filter logs in block %d: %w", n, err)
+		}
+		for _, l := range logs {
+			// ERC-721 的 Transfer 签名相同但 tokenId 是第 4 个 topic，按结构区分
+			if len(l.Topics) != 3 || len(l.Data) != 32 {
+				continue
+			}
+			found = append(found, &deposit{
+				ID:     fmt.Sprintf("%s:%d", l.TxHash.Hex(), l.Index),
+				Asset:  l.Address.Hex(),
+				From:   common.BytesToAddress(l.Topics[1].Bytes()),
+				To:     common.BytesToAddress(l.Topics[2].Bytes()),
+				Amount: new(big.Int).SetBytes(l.Data),
+				TxHash: l.TxHash,
+			})
+		}
+	}
+
+	for _, d := range found {
+		if _, exists := w.store.Deposits[d.ID]; exists {
+			continue
+		}
+		d.Block = n
+		d.BlockHash = block.Hash()
+		d.SeenAt = time.Now()
+		w.store.Deposits[d.ID] = d
+		w.out.Note("[PENDING] %s %s %s -> %s (block %d)", d.Amount, d.Asset, d.From.Hex(), d.To.Hex(), n)
+		w.record("pending", d, 0)
+	}
+	w.store.recordBlock(n, block.Hash())
+	return false, nil
+}
+
+// confirm 对达到确认数且尚未通知的入金发送回调；失败按指数退避重试，成功后立即落盘
+func (w *watcher) confirm(ctx context.Context, head uint64) {
+	var ready []*deposit
+	for _, d := range w.store.Deposits {
+		if d.NotifiedAt == nil && head+1 >= d.Block+w.confirmations && time.Now().After(d.NextTry) {
+			ready = append(ready, d)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool { return ready[i].Block < ready[j].Block })
+
+	for _, d := range ready {
+		// 入金所在区块必须仍是本轮确认过的规范链区块
+		if known, ok := w.store.Recent[d.Block]; ok && known != d.BlockHash {
+			continue
+		}
+		confs := head + 1 - d.Block
+		if err := w.notifier.Notify(ctx, d, confs); err != nil {
+			d.Attempts++
+			delay := time.Duration(math.Min(600, math.Pow(2, float64(d.Attempts)))) * time.Second
+			d.NextTry = time.Now().Add(delay)
+			log.Printf("[WARN] failed to notify %s (attempt %d, retry in %s): %v", d.ID, d.Attempts, delay, err)
+			continue
+		}
+		now := time.Now()
+		d.NotifiedAt = &now
+		w.out.Note("[CONFIRMED] %s %s -> %s tx %s (%d confirmations)", d.Amount, d.Asset, d.To.Hex(), d.TxHash.Hex(), confs)
+		w.record("confirmed", d, confs)
+		if err := w.store.save(); err != nil {
+			log.Printf("[WARN] failed to save store: %v", err)
+		}
+	}
+}
+
+// record 在结构化格式下输出一条入金记录
+func (w *watcher) record(kind string, d *deposit, confs uint64) {
+	err := w.out.Record(kind, "id", d.ID, "asset", d.Asset, "from", d.From, "to", d.To, "amount", d.Amount,
+		"tx_hash", d.TxHash, "block_number", d.Block, "confirmations", confs)
+	if err != nil {
+		log.Printf("[WARN] failed to write record: %v", err)
+	}
+}
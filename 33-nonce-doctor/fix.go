@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/yzucdh1/examples/internal/rpcerr"
+)
+
+// 替换交易的费用至少要比原交易高 10%（geth 默认 --txpool.pricebump）；这里取 12.5%，给舍入和其他客户端留余量
+const bumpPermille = 1125
+
+// bump 在旧值基础上提高 12.5%，并且不低于 floor
+func bump(old, floor *big.Int) *big.Int {
+	v := new(big.Int).Mul(old, big.NewInt(bumpPermille))
+	v.Div(v, big.NewInt(1000))
+	v.Add(v, big.NewInt(1))
+	if v.Cmp(floor) < 0 {
+		return new(big.Int).Set(floor)
+	}
+	return v
+}
+
+// replacementFees 计算替换交易的 tip / feeCap：既满足交易池的涨价规则，又不低于当前市场
+func replacementFees(old *types.Transaction, m *market) (tip, feeCap *big.Int) {
+	tip = m.Tip
+	marketCap := new(big.Int).Add(new(big.Int).Mul(m.BaseFee, big.NewInt(2)), m.Tip)
+	if old == nil {
+		// 看不到原交易（节点不支持 txpool_contentFrom）：使用两倍市场小费，尽量超过未知的原费用
+		tip = new(big.Int).Mul(m.Tip, big.NewInt(2))
+		return tip, new(big.Int).Add(new(big.Int).Mul(m.BaseFee, big.NewInt(2)), tip)
+	}
+	tip = bump(old.GasTipCap(), m.Tip)
+	feeCap = bump(old.GasFeeCap(), marketCap)
+	if feeCap.Cmp(tip) < 0 {
+		feeCap = new(big.Int).Set(tip)
+	}
+	return tip, feeCap
+}
+
+// buildReplacement 构造替换交易：
+// - speedup：保持原交易的 to / value / data / gas，只提高费用
+// - cancel：向自己发送 0 ETH，占用同一个 nonce 使原交易失效；也用于填补缺口
+func buildReplacement(chainID *big.Int, from common.Address, nonce uint64, old *types.Transaction, mode string, m *market) (*types.Transaction, error) {
+	tip, feeCap := replacementFees(old, m)
+	if old != nil && mode == "speedup" {
+		switch old.Type() {
+		case types.BlobTxType:
+			return nil, fmt.Errorf("blob transactions need their sidecar to be re-sent, use --mode cancel")
+		case types.SetCodeTxType:
+			return nil, fmt.Errorf("set-code transactions need their authorizations re-signed, use --mode cancel")
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      nonce,
+			GasTipCap:  tip,
+			GasFeeCap:  feeCap,
+			Gas:        old.Gas(),
+			To:         old.To(),
+			Value:      old.Value(),
+			Data:       old.Data(),
+			AccessList: old.AccessList(),
+		}), nil
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       params.TxGas,
+		To:        &from,
+		Value:     big.NewInt(0),
+	}), nil
+}
+
+// sendReplacement 签名并广播替换交易
+func sendReplacement(ctx context.Context, client *ethclient.Client, key *ecdsa.PrivateKey, chainID *big.Int, tx *types.Transaction) (*types.Transaction, error) {
+	signedTx, err := types.SignTx(tx, types.NewLondonSigner(chainID), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return nil, fmt.Errorf("failed to send transaction: %w", rpcerr.Classify(err))
+	}
+	return signedTx, nil
+}
+
+// keyAddress 私钥对应的地址
+func keyAddress(key *ecdsa.PrivateKey) common.Address {
+	return crypto.PubkeyToAddress(key.PublicKey)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcerr"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 33-nonce-doctor
+// 热钱包最常见的故障排查：交易"卡住"。诊断内容：
+// - 链上 nonce（latest）与交易池 nonce（pending）对比：pending > latest 说明有未打包交易
+// - 通过 txpool_contentFrom 列出地址在交易池中的交易，与当前 baseFee / 建议小费对比，找出费用过低的交易
+// - 找出 nonce 缺口：缺口之后的交易进入 queued 队列，永远不会被打包
+// - 给出处理建议；加 --fix 后逐条确认并发送替换交易（speedup：原样提高费用；cancel：0 ETH 转给自己）
+//
+// 执行示例：
+//    export ETH_RPC_URL="http://localhost:8545"
+//    go run . --address 0x...
+//    SENDER_PRIVATE_KEY=0x... go run . --fix --mode cancel
+//    go run . --address 0x... --format json | jq '.Transactions'
+//
+// 注意事项：
+// - txpool_* 只有自建节点（geth / Erigon / Reth）提供，且看到的是该节点自己的交易池；公共 RPC 通常不支持，此时只能对比 nonce
+// - 替换交易的费用需要比原交易高至少 10%（这里提高 12.5%），否则节点返回 "replacement transaction underpriced"
+// - 替换交易广播后，原交易仍可能先被打包；以最终上链的为准
+
+func main() {
+	addressHex := flag.String("address", "", "address to diagnose (default: address of SENDER_PRIVATE_KEY)")
+	fix := flag.Bool("fix", false, "interactively send replacement transactions (requires SENDER_PRIVATE_KEY)")
+	mode := flag.String("mode", "speedup", "replacement for stuck transactions: speedup | cancel (gaps are always filled with cancel)")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *mode != "speedup" && *mode != "cancel" {
+		log.Fatalf("unknown --mode %q", *mode)
+	}
+	var key *ecdsa.PrivateKey
+	if privKeyHex := config.Get().PrivateKey; privKeyHex != "" {
+		k, err := crypto.HexToECDSA(strings.TrimPrefix(privKeyHex, "0x"))
+		if err != nil {
+			log.Fatalf("invalid private key: %v", err)
+		}
+		key = k
+	}
+	var address common.Address
+	switch {
+	case *addressHex != "":
+		if !common.IsHexAddress(*addressHex) {
+			log.Fatalf("invalid --address %q", *addressHex)
+		}
+		address = common.HexToAddress(*addressHex)
+	case key != nil:
+		address = keyAddress(key)
+	default:
+		log.Fatal("--address or SENDER_PRIVATE_KEY is required")
+	}
+	if *fix {
+		if key == nil {
+			log.Fatal("SENDER_PRIVATE_KEY is not set (required for --fix)")
+		}
+		if keyAddress(key) != address {
+			log.Fatal("SENDER_PRIVATE_KEY does not belong to --address")
+		}
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(5*time.Minute))
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatalf("failed to get chain id: %v", err)
+	}
+	latest, err := client.NonceAt(ctx, address, nil)
+	if err != nil {
+		log.Fatalf("failed to get nonce: %v", err)
+	}
+	pending, err := client.PendingNonceAt(ctx, address)
+	if err != nil {
+		log.Fatalf("failed to get pending nonce: %v", err)
+	}
+	m, err := loadMarket(ctx, client)
+	if err != nil {
+		log.Fatalf("failed to load market fees: %v", err)
+	}
+
+	out.Section("Nonce Status")
+	out.Field("Address", address)
+	// latest 为下一个待打包的 nonce，pending - latest 为交易池中等待的交易数
+	out.Field("Latest Nonce", latest)
+	out.Field("Pending", pending)
+	out.Field("Waiting", pending-latest)
+	out.Field("Base Fee", ethutil.FormatGwei(m.BaseFee))
+	out.Field("Market Tip", ethutil.FormatGwei(m.Tip))
+
+	txs, err := poolContent(ctx, client, address)
+	var diags []diagnosis
+	if err != nil {
+		log.Printf("[WARN] txpool_contentFrom unavailable (%v), only nonces can be compared", err)
+		// 看不到交易池内容时，只能把 [latest, pending) 视为卡住的 nonce
+		for n := latest; n < pending; n++ {
+			diags = append(diags, diagnosis{Nonce: n, Problem: "pending (details unavailable)"})
+		}
+	} else {
+		diags = diagnose(latest, txs, m)
+	}
+
+	defer func() {
+		if err := out.Flush(); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	out.Table("Transactions", "Nonce", "Hash", "Tip", "Fee Cap", "Status")
+	if len(diags) == 0 {
+		out.Note("no pending transactions, nothing to fix")
+		return
+	}
+	var problems []diagnosis
+	for _, d := range diags {
+		printDiagnosis(out, d)
+		if d.Problem != "" {
+			problems = append(problems, d)
+		}
+	}
+
+	out.Table("Suggestions", "Nonce", "Suggestion")
+	if len(problems) == 0 {
+		out.Note("all pending transactions are priced at or above market, wait for inclusion")
+		return
+	}
+	for _, d := range problems {
+		out.Row(d.Nonce, suggestion(d, *mode))
+	}
+	if !*fix {
+		out.Note("\nrun with --fix (and SENDER_PRIVATE_KEY) to send these replacements")
+		return
+	}
+
+	out.Table("Fix", "Nonce", "Mode", "Tx Hash")
+	// 提示写到 stderr，不混入 --format json 等结构化输出
+	stdin := bufio.NewReader(os.Stdin)
+	for _, d := range problems {
+		if !d.actionable() {
+			continue
+		}
+		var oldTx *types.Transaction
+		replaceMode := *mode
+		if d.Tx != nil {
+			oldTx = d.Tx.Tx
+		} else {
+			replaceMode = "cancel"
+		}
+		tx, err := buildReplacement(chainID, address, d.Nonce, oldTx, replaceMode, m)
+		if err != nil {
+			log.Printf("[WARN] nonce %d: %v", d.Nonce, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "send %s for nonce %d (tip %s, fee cap %s)? [y/N] ", replaceMode, d.Nonce, ethutil.FormatGwei(tx.GasTipCap()), ethutil.FormatGwei(tx.GasFeeCap()))
+		line, _ := stdin.ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(line)); answer != "y" && answer != "yes" {
+			continue
+		}
+		signedTx, err := sendReplacement(ctx, client, key, chainID, tx)
+		switch {
+		case errors.Is(err, rpcerr.ErrNonceTooLow):
+			// 诊断之后原交易已经上链，不需要再处理
+			log.Printf("[INFO] nonce %d was mined in the meantime, skipping", d.Nonce)
+			continue
+		case err != nil:
+			if hint := rpcerr.Hint(err); hint != "" {
+				log.Printf("[WARN] nonce %d: %v (hint: %s)", d.Nonce, err, hint)
+			} else {
+				log.Printf("[WARN] nonce %d: %v", d.Nonce, err)
+			}
+			continue
+		}
+		out.Row(d.Nonce, replaceMode, signedTx.Hash())
+	}
+}
+
+// printDiagnosis 输出单个 nonce 的状态
+func printDiagnosis(out *output.Printer, d diagnosis) {
+	status := "ok"
+	if d.Problem != "" {
+		status = d.Problem
+	}
+	if d.Tx == nil {
+		out.Row(d.Nonce, "-", "-", "-", status)
+		return
+	}
+	tx := d.Tx.Tx
+	out.Row(d.Nonce, tx.Hash(), ethutil.FormatGwei(tx.GasTipCap()), ethutil.FormatGwei(tx.GasFeeCap()), status)
+}
+
+// suggestion 针对问题给出处理建议
+func suggestion(d diagnosis, mode string) string {
+	switch {
+	case d.gap:
+		return "fill the gap with a 0 ETH self-transfer (cancel)"
+	case d.Tx == nil:
+		return "replace with a 0 ETH self-transfer at a higher fee (cancel)"
+	case !d.actionable():
+		return "no action needed once earlier nonces are fixed"
+	default:
+		return fmt.Sprintf("replace with higher fees (%s)", mode)
+	}
+}
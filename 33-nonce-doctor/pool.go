@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/ethutil"
+)
+
+// poolTx 交易池中的一笔交易
+type poolTx struct {
+	Tx     *types.Transaction
+	Queued bool // true：nonce 不连续，在 queued 队列中，永远不会被打包，直到缺口被填上
+}
+
+// poolContent 读取地址在交易池中的交易（txpool_contentFrom，geth / Erigon / Reth 支持）
+func poolContent(ctx context.Context, client *ethclient.Client, addr common.Address) (map[uint64]*poolTx, error) {
+	var content map[string]map[string]json.RawMessage
+	if err := client.Client().CallContext(ctx, &content, "txpool_contentFrom", addr); err != nil {
+		return nil, err
+	}
+	txs := make(map[uint64]*poolTx)
+	for queue, byNonce := range content {
+		for nonceStr, raw := range byNonce {
+			nonce, err := strconv.ParseUint(nonceStr, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid nonce %q in txpool response", nonceStr)
+			}
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalJSON(raw); err != nil {
+				return nil, fmt.Errorf("failed to decode pool tx with nonce %d: %w", nonce, err)
+			}
+			txs[nonce] = &poolTx{Tx: tx, Queued: queue == "queued"}
+		}
+	}
+	return txs, nil
+}
+
+// market 当前市场费用：下一个区块的 baseFee 和建议小费
+type market struct {
+	BaseFee *big.Int
+	Tip     *big.Int
+}
+
+// loadMarket 读取最新区块的 baseFee（已足够判断交易是否明显低于市场）和建议小费
+func loadMarket(ctx context.Context, client *ethclient.Client) (*market, error) {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header: %w", err)
+	}
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas tip cap: %w", err)
+	}
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		if baseFee, err = client.SuggestGasPrice(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get gas price: %w", err)
+		}
+	}
+	return &market{BaseFee: baseFee, Tip: tip}, nil
+}
+
+// diagnosis 一个 nonce 的诊断结果
+type diagnosis struct {
+	Nonce     uint64
+	Tx        *poolTx // nil 表示缺口，或节点不支持 txpool 时无法获取
+	Problem   string  // 空表示正常
+	gap       bool
+	underpaid bool
+}
+
+// actionable 是否需要发送替换交易：缺口、费用过低，或看不到详情的待处理 nonce；
+// 只是排在缺口后面的 queued 交易在缺口填上后会自动恢复
+func (d diagnosis) actionable() bool {
+	return d.Problem != "" && (d.Tx == nil || d.underpaid)
+}
+
+// diagnose 从链上 nonce 开始检查每个 nonce：缺口、queued、费用低于市场
+func diagnose(latest uint64, txs map[uint64]*poolTx, m *market) []diagnosis {
+	if len(txs) == 0 {
+		return nil
+	}
+	nonces := make([]uint64, 0, len(txs))
+	for n := range txs {
+		nonces = append(nonces, n)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	highest := nonces[len(nonces)-1]
+
+	var out []diagnosis
+	for n := latest; n <= highest; n++ {
+		ptx, ok := txs[n]
+		if !ok {
+			out = append(out, diagnosis{Nonce: n, Problem: "gap: no transaction with this nonce, later ones are blocked", gap: true})
+			continue
+		}
+		d := diagnosis{Nonce: n, Tx: ptx}
+		tx := ptx.Tx
+		switch {
+		case tx.GasFeeCap().Cmp(m.BaseFee) < 0:
+			d.Problem = fmt.Sprintf("fee cap %s below base fee %s", ethutil.FormatGwei(tx.GasFeeCap()), ethutil.FormatGwei(m.BaseFee))
+			d.underpaid = true
+		case tx.GasTipCap().Cmp(new(big.Int).Div(m.Tip, big.NewInt(2))) < 0:
+			d.Problem = fmt.Sprintf("tip %s far below market %s", ethutil.FormatGwei(tx.GasTipCap()), ethutil.FormatGwei(m.Tip))
+			d.underpaid = true
+		case ptx.Queued:
+			d.Problem = "queued behind an earlier nonce"
+		}
+		out = append(out, d)
+	}
+	return out
+}
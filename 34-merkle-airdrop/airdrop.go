@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// claimInfo 单个地址的领取信息
+type claimInfo struct {
+	Index  uint64        `json:"index"`
+	Amount *hexutil.Big  `json:"amount"`
+	Proof  []common.Hash `json:"proof"`
+}
+
+// distribution 输出文件，格式与 Uniswap merkle-distributor 的 parse-balance-map 相同，可直接给前端使用
+type distribution struct {
+	MerkleRoot common.Hash                   `json:"merkleRoot"`
+	TokenTotal *hexutil.Big                  `json:"tokenTotal"`
+	Claims     map[common.Address]*claimInfo `json:"claims"`
+}
+
+// entry CSV 中的一行
+type entry struct {
+	Account common.Address
+	Amount  *big.Int
+}
+
+// readCSV 读取 "address,amount" 格式的名单；第一行不是地址时视为表头跳过。
+// amount 按 decimals 解析十进制小数，例如 decimals=18 时 "1.5" 为 1.5e18
+func readCSV(path string, decimals int) ([]entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	seen := make(map[common.Address]int)
+	var entries []entry
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(rec) < 2 || strings.HasPrefix(strings.TrimSpace(rec[0]), "#") {
+			continue
+		}
+		addr := strings.TrimSpace(rec[0])
+		if !common.IsHexAddress(addr) {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid address %q", line, addr)
+		}
+		amount, err := parseAmount(strings.TrimSpace(rec[1]), decimals)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		account := common.HexToAddress(addr)
+		// 同一地址出现多次时报错而不是合并：名单里的重复通常是数据问题
+		if prev, dup := seen[account]; dup {
+			return nil, fmt.Errorf("line %d: duplicate address %s (first on line %d)", line, account.Hex(), prev)
+		}
+		seen[account] = line
+		entries = append(entries, entry{Account: account, Amount: amount})
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entries in %s", path)
+	}
+	return entries, nil
+}
+
+// parseAmount 把十进制字符串按 decimals 转换为最小单位，小数位超过 decimals 时报错
+func parseAmount(s string, decimals int) (*big.Int, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > decimals {
+		return nil, fmt.Errorf("amount %q has more than %d decimals", s, decimals)
+	}
+	v, ok := new(big.Int).SetString(whole+frac+strings.Repeat("0", decimals-len(frac)), 10)
+	if !ok || v.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	return v, nil
+}
+
+// buildDistribution 按名单顺序分配 index，构建树并生成每个地址的证明
+func buildDistribution(entries []entry) (*distribution, error) {
+	leaves := make([]common.Hash, len(entries))
+	total := new(big.Int)
+	for i, e := range entries {
+		leaves[i] = leafHash(uint64(i), e.Account, e.Amount)
+		total.Add(total, e.Amount)
+	}
+	tree, err := buildTree(leaves)
+	if err != nil {
+		return nil, err
+	}
+	d := &distribution{
+		MerkleRoot: tree.Root(),
+		TokenTotal: (*hexutil.Big)(total),
+		Claims:     make(map[common.Address]*claimInfo, len(entries)),
+	}
+	for i, e := range entries {
+		proof, err := tree.Proof(leaves[i])
+		if err != nil {
+			return nil, err
+		}
+		d.Claims[e.Account] = &claimInfo{Index: uint64(i), Amount: (*hexutil.Big)(e.Amount), Proof: proof}
+	}
+	return d, nil
+}
+
+// loadDistribution 读取 build 生成的文件
+func loadDistribution(path string) (*distribution, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var d distribution
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("invalid distribution file: %w", err)
+	}
+	return &d, nil
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+	"github.com/yzucdh1/examples/internal/txwait"
+)
+
+// 34-merkle-airdrop
+// Merkle 空投工具，兼容 Uniswap MerkleDistributor（以及大量基于它的分发合约）：
+// - build：从 "address,amount" CSV 构建 Merkle 树，输出根和每个地址的 index / amount / proof（JSON）
+//   叶子 = keccak256(abi.encodePacked(uint256 index, address account, uint256 amount))，节点按 sorted pairs 哈希
+// - proof：查询某个地址的证明，并在本地验证
+// - check：对照链上分发合约：merkleRoot 是否一致、是否已领取、合约代币余额是否足够，并用 eth_call 模拟 claim；
+//   加 --claim 使用 SENDER_PRIVATE_KEY 发送领取交易（任何人都可以替 account 领取，代币发给 account）
+//
+// 执行示例：
+//    go run . build --csv airdrop.csv --decimals 18 --out airdrop.json
+//    go run . proof --tree airdrop.json --address 0x...
+//    go run . proof --tree airdrop.json --address 0x... --format json | jq -r '.Proof.Proof[]'
+//    export ETH_RPC_URL="https://sepolia.infura.io/v3/<project-id>"
+//    go run . check --tree airdrop.json --address 0x... --distributor 0x...
+//    SENDER_PRIVATE_KEY=0x... go run . check --tree airdrop.json --address 0x... --distributor 0x... --claim
+//
+// 注意事项：
+// - index 按 CSV 中的顺序分配；同一份 CSV 总是得到同一个根，修改顺序会改变根
+// - 叶子是 64 + 20 字节的 encodePacked，长度不等于 64 字节，不会与内部节点混淆（第二原像攻击）；
+//   OpenZeppelin StandardMerkleTree 使用双重哈希的叶子，与这里的格式不兼容
+
+const distributorABIJSON = `[
+  {"inputs": [], "name": "merkleRoot", "outputs": [{"name": "", "type": "bytes32"}], "stateMutability": "view", "type": "function"},
+  {"inputs": [], "name": "token", "outputs": [{"name": "", "type": "address"}], "stateMutability": "view", "type": "function"},
+  {"inputs": [{"name": "index", "type": "uint256"}], "name": "isClaimed", "outputs": [{"name": "", "type": "bool"}], "stateMutability": "view", "type": "function"},
+  {"inputs": [
+    {"name": "index", "type": "uint256"},
+    {"name": "account", "type": "address"},
+    {"name": "amount", "type": "uint256"},
+    {"name": "merkleProof", "type": "bytes32[]"}
+  ], "name": "claim", "outputs": [], "stateMutability": "nonpayable", "type": "function"},
+  {"inputs": [{"name": "account", "type": "address"}], "name": "balanceOf", "outputs": [{"name": "", "type": "uint256"}], "stateMutability": "view", "type": "function"}
+]`
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "build":
+		cmdBuild(args)
+	case "proof":
+		cmdProof(args)
+	case "check":
+		cmdCheck(args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: go run . <build|proof|check> [flags]")
+	fmt.Fprintln(os.Stderr, "run a subcommand with -h to see its flags")
+}
+
+// cmdBuild 从 CSV 构建 Merkle 树并输出分发文件
+func cmdBuild(args []string) {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	csvPath := fs.String("csv", "", "CSV file with address,amount rows (required)")
+	decimals := fs.Int("decimals", 0, "decimals of the amount column (0 = amounts are already in base units)")
+	outPath := fs.String("out", "airdrop.json", "output JSON file")
+	out := output.AddFlags(fs)
+	fs.Parse(args)
+
+	if *csvPath == "" {
+		log.Fatal("--csv is required")
+	}
+	entries, err := readCSV(*csvPath, *decimals)
+	if err != nil {
+		log.Fatalf("failed to read CSV: %v", err)
+	}
+	dist, err := buildDistribution(entries)
+	if err != nil {
+		log.Fatalf("failed to build tree: %v", err)
+	}
+
+	// 写出前逐个自检证明
+	for addr, c := range dist.Claims {
+		if !verifyProof(leafHash(c.Index, addr, c.Amount.ToInt()), c.Proof, dist.MerkleRoot) {
+			log.Fatalf("internal error: proof for %s does not verify", addr.Hex())
+		}
+	}
+
+	data, err := json.MarshalIndent(dist, "", "  ")
+	if err != nil {
+		log.Fatalf("failed to encode distribution: %v", err)
+	}
+	if err := os.WriteFile(*outPath, data, 0o644); err != nil {
+		log.Fatalf("failed to write %s: %v", *outPath, err)
+	}
+
+	out.Section("Merkle Airdrop")
+	out.Field("Recipients", len(entries))
+	out.Field("Token Total", dist.TokenTotal.ToInt())
+	out.Field("Merkle Root", dist.MerkleRoot)
+	out.Field("Output", *outPath)
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// cmdProof 输出并验证某个地址的证明
+func cmdProof(args []string) {
+	fs := flag.NewFlagSet("proof", flag.ExitOnError)
+	treePath := fs.String("tree", "airdrop.json", "distribution JSON generated by build")
+	addrHex := fs.String("address", "", "recipient address (required)")
+	out := output.AddFlags(fs)
+	fs.Parse(args)
+
+	dist, account, claim := mustLoadClaim(*treePath, *addrHex)
+	leaf := leafHash(claim.Index, account, claim.Amount.ToInt())
+
+	valid := verifyProof(leaf, claim.Proof, dist.MerkleRoot)
+	out.Section("Proof")
+	out.Field("Account", account)
+	out.Field("Index", claim.Index)
+	out.Field("Amount", claim.Amount.ToInt())
+	out.Field("Leaf", leaf)
+	out.Field("Root", dist.MerkleRoot)
+	if out.Pretty() {
+		for i, p := range claim.Proof {
+			out.Field(fmt.Sprintf("Proof[%d]", i), p)
+		}
+	} else {
+		out.Field("Proof", claim.Proof)
+	}
+	if valid {
+		out.Field("Result", "VALID")
+	} else {
+		out.Field("Result", "INVALID (file was modified?)")
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// cmdCheck 对照链上分发合约检查领取状态，可选发送领取交易
+func cmdCheck(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	treePath := fs.String("tree", "airdrop.json", "distribution JSON generated by build")
+	addrHex := fs.String("address", "", "recipient address (required)")
+	distHex := fs.String("distributor", "", "MerkleDistributor contract address (required)")
+	doClaim := fs.Bool("claim", false, "send the claim transaction (requires SENDER_PRIVATE_KEY)")
+	config.AddFlags(fs)
+	out := output.AddFlags(fs)
+	fs.Parse(args)
+
+	dist, account, claim := mustLoadClaim(*treePath, *addrHex)
+	if !common.IsHexAddress(*distHex) {
+		log.Fatal("missing or invalid --distributor")
+	}
+	distributor := common.HexToAddress(*distHex)
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(3*time.Minute))
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	parsedABI, err := abi.JSON(strings.NewReader(distributorABIJSON))
+	if err != nil {
+		log.Fatalf("failed to parse ABI: %v", err)
+	}
+
+	var root [32]byte
+	if err := callContract(ctx, client, parsedABI, distributor, &root, "merkleRoot"); err != nil {
+		log.Fatalf("failed to read merkleRoot: %v", err)
+	}
+	var claimed bool
+	if err := callContract(ctx, client, parsedABI, distributor, &claimed, "isClaimed", new(big.Int).SetUint64(claim.Index)); err != nil {
+		log.Fatalf("failed to read isClaimed: %v", err)
+	}
+
+	out.Section("Claim Check")
+	out.Field("Distributor", distributor)
+	out.Field("Account", account)
+	out.Field("Index", claim.Index)
+	out.Field("Amount", claim.Amount.ToInt())
+	rootOK := common.Hash(root) == dist.MerkleRoot
+	out.Field("Merkle Root", dist.MerkleRoot)
+	if rootOK {
+		out.Field("Root Match", "✓")
+	} else {
+		out.Field("Root Match", fmt.Sprintf("✗ on-chain %s", common.Hash(root).Hex()))
+	}
+	out.Field("Claimed", claimed)
+
+	var token common.Address
+	if err := callContract(ctx, client, parsedABI, distributor, &token, "token"); err != nil {
+		log.Printf("[WARN] failed to read token: %v", err)
+	} else {
+		var balance *big.Int
+		if err := callContract(ctx, client, parsedABI, token, &balance, "balanceOf", distributor); err != nil {
+			log.Printf("[WARN] failed to read distributor balance: %v", err)
+		} else {
+			out.Field("Token", token)
+			out.Field("Balance", balance)
+			if balance.Cmp(claim.Amount.ToInt()) < 0 {
+				out.Note("Note        : distributor balance is lower than the claim amount")
+			}
+		}
+	}
+
+	callData, err := parsedABI.Pack("claim", new(big.Int).SetUint64(claim.Index), account, claim.Amount.ToInt(), toBytes32(claim.Proof))
+	if err != nil {
+		log.Fatalf("failed to pack claim data: %v", err)
+	}
+	_, simErr := client.CallContract(ctx, ethereum.CallMsg{From: account, To: &distributor, Data: callData}, nil)
+	if simErr != nil {
+		out.Field("Simulation", fmt.Sprintf("✗ claim would revert: %v", simErr))
+	} else {
+		out.Field("Simulation", "✓ claim would succeed")
+	}
+
+	if *doClaim {
+		if claimed || !rootOK || simErr != nil {
+			log.Fatal("refusing to send claim: checks above failed")
+		}
+		if err := sendClaim(ctx, client, out, distributor, callData); err != nil {
+			log.Fatalf("claim failed: %v", err)
+		}
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// sendClaim 发送领取交易并等待确认（EIP-1559 费用策略与 08-contract-interact 相同）
+func sendClaim(ctx context.Context, client *ethclient.Client, out *output.Printer, distributor common.Address, callData []byte) error {
+	privKeyHex := config.Get().PrivateKey
+	if privKeyHex == "" {
+		return fmt.Errorf("SENDER_PRIVATE_KEY is not set (required for --claim)")
+	}
+	privKey, err := crypto.HexToECDSA(strings.TrimPrefix(privKeyHex, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+	fromAddr := crypto.PubkeyToAddress(privKey.PublicKey)
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %w", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, fromAddr)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{From: fromAddr, To: &distributor, Data: callData})
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	gasLimit = gasLimit * 120 / 100
+
+	gasTipCap, gasFeeCap, err := ethutil.DynamicFees(ctx, client)
+	if err != nil {
+		return fmt.Errorf("failed to compute fees: %w", err)
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &distributor,
+		Value:     big.NewInt(0),
+		Data:      callData,
+	})
+	signedTx, err := types.SignTx(tx, types.NewLondonSigner(chainID), privKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	out.Note("sent claim tx %s, waiting for confirmation...", signedTx.Hash().Hex())
+
+	receipt, err := txwait.WaitMined(ctx, client, signedTx.Hash(), 1)
+	if err != nil {
+		return fmt.Errorf("failed to wait for receipt: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("claim tx %s failed in block %d", signedTx.Hash().Hex(), receipt.BlockNumber.Uint64())
+	}
+	out.Section("Claim")
+	out.Field("Tx Hash", signedTx.Hash())
+	out.Field("Block", receipt.BlockNumber)
+	return nil
+}
+
+// mustLoadClaim 读取分发文件并查找地址的领取信息
+func mustLoadClaim(treePath, addrHex string) (*distribution, common.Address, *claimInfo) {
+	if !common.IsHexAddress(addrHex) {
+		log.Fatal("missing or invalid --address")
+	}
+	dist, err := loadDistribution(treePath)
+	if err != nil {
+		log.Fatalf("failed to load %s: %v", treePath, err)
+	}
+	account := common.HexToAddress(addrHex)
+	claim, ok := dist.Claims[account]
+	if !ok {
+		log.Fatalf("%s is not in the airdrop", account.Hex())
+	}
+	return dist, account, claim
+}
+
+// callContract 调用只读方法并解码返回值
+func callContract(ctx context.Context, client *ethclient.Client, parsedABI abi.ABI, to common.Address, out interface{}, method string, args ...interface{}) error {
+	data, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s data: %w", method, err)
+	}
+	res, err := client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return err
+	}
+	return parsedABI.UnpackIntoInterface(out, method, res)
+}
+
+// toBytes32 转换为 ABI 编码需要的 [][32]byte
+func toBytes32(hashes []common.Hash) [][32]byte {
+	out := make([][32]byte, len(hashes))
+	for i, h := range hashes {
+		out[i] = h
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// leafHash 与 Uniswap MerkleDistributor 一致：keccak256(abi.encodePacked(uint256 index, address account, uint256 amount))
+func leafHash(index uint64, account common.Address, amount *big.Int) common.Hash {
+	buf := make([]byte, 0, 32+20+32)
+	buf = append(buf, common.BigToHash(new(big.Int).SetUint64(index)).Bytes()...)
+	buf = append(buf, account.Bytes()...)
+	buf = append(buf, common.BigToHash(amount).Bytes()...)
+	return crypto.Keccak256Hash(buf)
+}
+
+// hashPair 排序后拼接再哈希（sorted pairs）：验证时不需要知道兄弟节点在左还是右，
+// 与 OpenZeppelin MerkleProof.verify 和 Uniswap MerkleDistributor 的验证逻辑一致
+func hashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
+
+// merkleTree 逐层保存的 Merkle 树，layers[0] 为排序去重后的叶子，最后一层为根
+type merkleTree struct {
+	layers [][]common.Hash
+}
+
+// buildTree 构建 Merkle 树；叶子先排序（与 Uniswap 的 JS 生成脚本一致，保证同一份名单得到相同的根），
+// 某一层节点数为奇数时，最后一个节点直接提升到上一层
+func buildTree(leaves []common.Hash) (*merkleTree, error) {
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("no leaves")
+	}
+	layer := append([]common.Hash(nil), leaves...)
+	sort.Slice(layer, func(i, j int) bool { return bytes.Compare(layer[i][:], layer[j][:]) < 0 })
+	for i := 1; i < len(layer); i++ {
+		if layer[i] == layer[i-1] {
+			return nil, fmt.Errorf("duplicate leaf %s", layer[i].Hex())
+		}
+	}
+
+	t := &merkleTree{layers: [][]common.Hash{layer}}
+	for len(layer) > 1 {
+		next := make([]common.Hash, 0, (len(layer)+1)/2)
+		for i := 0; i < len(layer); i += 2 {
+			if i+1 == len(layer) {
+				next = append(next, layer[i])
+				continue
+			}
+			next = append(next, hashPair(layer[i], layer[i+1]))
+		}
+		t.layers = append(t.layers, next)
+		layer = next
+	}
+	return t, nil
+}
+
+// Root 树根
+func (t *merkleTree) Root() common.Hash {
+	return t.layers[len(t.layers)-1][0]
+}
+
+// Proof 叶子的证明：自底向上每一层的兄弟节点（被提升的节点在该层没有兄弟，跳过）
+func (t *merkleTree) Proof(leaf common.Hash) ([]common.Hash, error) {
+	leaves := t.layers[0]
+	idx := sort.Search(len(leaves), func(i int) bool { return bytes.Compare(leaves[i][:], leaf[:]) >= 0 })
+	if idx == len(leaves) || leaves[idx] != leaf {
+		return nil, fmt.Errorf("leaf %s not in tree", leaf.Hex())
+	}
+	var proof []common.Hash
+	for _, layer := range t.layers[:len(t.layers)-1] {
+		sibling := idx ^ 1
+		if sibling < len(layer) {
+			proof = append(proof, layer[sibling])
+		}
+		idx /= 2
+	}
+	return proof, nil
+}
+
+// verifyProof 与合约中的 MerkleProof.verify 相同
+func verifyProof(leaf common.Hash, proof []common.Hash, root common.Hash) bool {
+	h := leaf
+	for _, p := range proof {
+		h = hashPair(h, p)
+	}
+	return h == root
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const multisigABIJSON = `[
+  {"inputs": [], "name": "nonce", "outputs": [{"name": "", "type": "uint256"}], "stateMutability": "view", "type": "function"},
+  {"inputs": [], "name": "threshold", "outputs": [{"name": "", "type": "uint256"}], "stateMutability": "view", "type": "function"},
+  {"inputs": [{"name": "", "type": "uint256"}], "name": "ownersArr", "outputs": [{"name": "", "type": "address"}], "stateMutability": "view", "type": "function"},
+  {"inputs": [
+    {"name": "sigV", "type": "uint8[]"},
+    {"name": "sigR", "type": "bytes32[]"},
+    {"name": "sigS", "type": "bytes32[]"},
+    {"name": "destination", "type": "address"},
+    {"name": "value", "type": "uint256"},
+    {"name": "data", "type": "bytes"},
+    {"name": "executor", "type": "address"},
+    {"name": "gasLimit", "type": "uint256"}
+  ], "name": "execute", "outputs": [], "stateMutability": "nonpayable", "type": "function"}
+]`
+
+// maxOwners 读取 ownersArr 的上限；合约没有提供数组长度的 getter，只能逐个读取直到 revert
+const maxOwners = 64
+
+// multisigState 链上多签合约的当前状态
+type multisigState struct {
+	Nonce     uint64
+	Threshold uint64
+	Owners    []common.Address
+}
+
+// multisig 多签合约的只读调用
+type multisig struct {
+	client  *ethclient.Client
+	abi     abi.ABI
+	address common.Address
+}
+
+func newMultisig(client *ethclient.Client, address common.Address) (*multisig, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(multisigABIJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	return &multisig{client: client, abi: parsedABI, address: address}, nil
+}
+
+// call 调用只读方法并解码返回值
+func (m *multisig) call(ctx context.Context, out interface{}, method string, args ...interface{}) error {
+	data, err := m.abi.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s data: %w", method, err)
+	}
+	output, err := m.client.CallContract(ctx, ethereum.CallMsg{To: &m.address, Data: data}, nil)
+	if err != nil {
+		return err
+	}
+	return m.abi.UnpackIntoInterface(out, method, output)
+}
+
+// State 读取 nonce、阈值和所有者列表
+func (m *multisig) State(ctx context.Context) (*multisigState, error) {
+	var nonce, threshold *big.Int
+	if err := m.call(ctx, &nonce, "nonce"); err != nil {
+		return nil, fmt.Errorf("failed to read nonce: %w", err)
+	}
+	if err := m.call(ctx, &threshold, "threshold"); err != nil {
+		return nil, fmt.Errorf("failed to read threshold: %w", err)
+	}
+	st := &multisigState{Nonce: nonce.Uint64(), Threshold: threshold.Uint64()}
+	for i := int64(0); i < maxOwners; i++ {
+		var owner common.Address
+		if err := m.call(ctx, &owner, "ownersArr", big.NewInt(i)); err != nil {
+			break
+		}
+		st.Owners = append(st.Owners, owner)
+	}
+	if len(st.Owners) == 0 {
+		return nil, fmt.Errorf("no owners found, is %s a SimpleMultiSig contract?", m.address.Hex())
+	}
+	return st, nil
+}
+
+// checkAgainstChain 确认提案仍然与链上状态一致：nonce 未被其他交易消耗，阈值和所有者集合没有变化
+func checkAgainstChain(p *proposal, st *multisigState) error {
+	if p.Nonce != st.Nonce {
+		return fmt.Errorf("proposal nonce %d, but multisig nonce is %d (already executed or superseded)", p.Nonce, st.Nonce)
+	}
+	if p.Threshold != st.Threshold {
+		return fmt.Errorf("proposal threshold %d, but multisig threshold is %d", p.Threshold, st.Threshold)
+	}
+	if len(p.Owners) != len(st.Owners) {
+		return fmt.Errorf("proposal has %d owners, multisig has %d", len(p.Owners), len(st.Owners))
+	}
+	for _, o := range st.Owners {
+		if !p.isOwner(o) {
+			return fmt.Errorf("owner %s is missing from the proposal", o.Hex())
+		}
+	}
+	return nil
+}
+
+// packExecute 把签名拆分为 sigV / sigR / sigS 并编码 execute 调用
+func (m *multisig) packExecute(p *proposal, sigs []partialSig) ([]byte, error) {
+	sigV := make([]uint8, len(sigs))
+	sigR := make([][32]byte, len(sigs))
+	sigS := make([][32]byte, len(sigs))
+	for i, s := range sigs {
+		copy(sigR[i][:], s.Signature[:32])
+		copy(sigS[i][:], s.Signature[32:64])
+		sigV[i] = s.Signature[64]
+	}
+	return m.abi.Pack("execute", sigV, sigR, sigS, p.Destination, p.Value.ToInt(), []byte(p.Data), p.Executor, new(big.Int).SetUint64(p.GasLimit))
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+	"github.com/yzucdh1/examples/internal/txwait"
+)
+
+// 35-multisig
+// 多签协调工具：收集多个所有者的签名，聚合成一笔执行交易。03 / 08 中只有单个私钥签名交易，
+// 这里演示 M-of-N 多签（例如 2-of-3）中签名是如何传递、校验和组装的。
+// 目标合约为 SimpleMultiSig（github.com/christianlundkvist/simple-multisig）：
+// 所有者对 EIP-712 结构化数据离线签名，任何人把凑够阈值的签名提交给 execute 执行
+//
+// - propose：读取链上 nonce / 阈值 / 所有者，生成提案文件（包含待签名的 EIP-712 摘要）
+// - sign：所有者用 SENDER_PRIVATE_KEY 离线签名，把签名追加到提案文件（可以在断网的机器上执行）
+// - status：校验提案和所有签名：摘要与字段一致、签名者是所有者、不重复、是否达到阈值
+// - execute：再次校验签名集合并对照链上状态，按地址升序取阈值个签名，模拟后广播
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://sepolia.infura.io/v3/<project-id>"
+//    go run . propose --multisig 0x... --to 0x... --value 1000000000000000 --out proposal.json
+//    SENDER_PRIVATE_KEY=<owner1> go run . sign --proposal proposal.json
+//    SENDER_PRIVATE_KEY=<owner2> go run . sign --proposal proposal.json
+//    go run . status --proposal proposal.json
+//    go run . status --proposal proposal.json --format json
+//    SENDER_PRIVATE_KEY=<any> go run . execute --proposal proposal.json
+//
+// 注意事项：
+// - 签名绑定 chainId、合约地址和 nonce：同一签名不能在其他链、其他合约上重放，执行后也不能再次使用
+// - 任一提案被执行后 nonce 加一，其余基于旧 nonce 的提案全部作废，需要重新 propose
+// - sign 前务必核对打印出来的 destination / value / data，签名即授权
+// - 合约要求签名个数恰好等于阈值、签名者地址严格递增，多余的签名不会被提交
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "propose":
+		cmdPropose(args)
+	case "sign":
+		cmdSign(args)
+	case "status":
+		cmdStatus(args)
+	case "execute":
+		cmdExecute(args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: go run . <propose|sign|status|execute> [flags]")
+	fmt.Fprintln(os.Stderr, "run a subcommand with -h to see its flags")
+}
+
+// cmdPropose 创建提案文件
+func cmdPropose(args []string) {
+	fs := flag.NewFlagSet("propose", flag.ExitOnError)
+	multisigHex := fs.String("multisig", "", "SimpleMultiSig contract address (required)")
+	toHex := fs.String("to", "", "destination address (required)")
+	valueStr := fs.String("value", "0", "ETH value in wei sent from the multisig")
+	dataHex := fs.String("data", "0x", "calldata for the destination")
+	executorHex := fs.String("executor", "", "only this address may execute (default: anyone)")
+	gasLimit := fs.Uint64("gas-limit", 100000, "gas limit for the inner call")
+	outPath := fs.String("out", "proposal.json", "proposal file to write")
+	config.AddFlags(fs)
+	out := output.AddFlags(fs)
+	fs.Parse(args)
+
+	if !common.IsHexAddress(*multisigHex) {
+		log.Fatal("missing or invalid --multisig")
+	}
+	if !common.IsHexAddress(*toHex) {
+		log.Fatal("missing or invalid --to")
+	}
+	value, ok := new(big.Int).SetString(*valueStr, 10)
+	if !ok || value.Sign() < 0 {
+		log.Fatalf("invalid --value %q", *valueStr)
+	}
+	data, err := hexutil.Decode(*dataHex)
+	if err != nil {
+		log.Fatalf("invalid --data: %v", err)
+	}
+	var executor common.Address
+	if *executorHex != "" {
+		if !common.IsHexAddress(*executorHex) {
+			log.Fatalf("invalid --executor %q", *executorHex)
+		}
+		executor = common.HexToAddress(*executorHex)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(time.Minute))
+	defer cancel()
+	client := mustDial(ctx)
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatalf("failed to get chain id: %v", err)
+	}
+	ms, err := newMultisig(client, common.HexToAddress(*multisigHex))
+	if err != nil {
+		log.Fatal(err)
+	}
+	st, err := ms.State(ctx)
+	if err != nil {
+		log.Fatalf("failed to read multisig state: %v", err)
+	}
+
+	p := &proposal{
+		ChainID:     (*hexutil.Big)(chainID),
+		Multisig:    ms.address,
+		Nonce:       st.Nonce,
+		Destination: common.HexToAddress(*toHex),
+		Value:       (*hexutil.Big)(value),
+		Data:        data,
+		Executor:    executor,
+		GasLimit:    *gasLimit,
+		Threshold:   st.Threshold,
+		Owners:      st.Owners,
+		Signatures:  []partialSig{},
+	}
+	p.Digest = p.computeDigest()
+	if err := p.save(*outPath); err != nil {
+		log.Fatalf("failed to write %s: %v", *outPath, err)
+	}
+
+	printProposal(out, p)
+	out.Field("Output", *outPath)
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// cmdSign 所有者签名并追加到提案文件
+func cmdSign(args []string) {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	path := fs.String("proposal", "proposal.json", "proposal file")
+	yes := fs.Bool("yes", false, "sign without asking for confirmation")
+	config.AddFlags(fs)
+	out := output.AddFlags(fs)
+	fs.Parse(args)
+
+	p := mustLoad(*path)
+	key := mustKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	// 先校验已有内容，避免在被篡改的提案上追加签名
+	valid, err := p.verifiedSigners()
+	if err != nil {
+		log.Fatalf("proposal failed verification: %v", err)
+	}
+	if !p.isOwner(signer) {
+		log.Fatalf("%s is not an owner of %s", signer.Hex(), p.Multisig.Hex())
+	}
+	for _, s := range valid {
+		if s.Signer == signer {
+			log.Fatalf("%s has already signed this proposal", signer.Hex())
+		}
+	}
+
+	printProposal(out, p)
+	out.Field("Signer", signer)
+	if !*yes {
+		// 提示写到 stderr，不混入 --format json 等结构化输出
+		fmt.Fprint(os.Stderr, "sign this transaction? [y/N] ")
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(line)); answer != "y" && answer != "yes" {
+			fmt.Fprintln(os.Stderr, "aborted")
+			return
+		}
+	}
+
+	sig, err := crypto.Sign(p.Digest.Bytes(), key)
+	if err != nil {
+		log.Fatalf("failed to sign: %v", err)
+	}
+	sig[64] += 27 // 合约中的 ecrecover 使用 V = 27 / 28
+	p.Signatures = append(p.Signatures, partialSig{Signer: signer, Signature: sig})
+	if err := p.save(*path); err != nil {
+		log.Fatalf("failed to write %s: %v", *path, err)
+	}
+	out.Field("Signatures", len(valid)+1)
+	out.Field("Threshold", p.Threshold)
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// cmdStatus 校验提案和签名集合；配置了节点地址时同时对照链上状态
+func cmdStatus(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	path := fs.String("proposal", "proposal.json", "proposal file")
+	config.AddFlags(fs)
+	out := output.AddFlags(fs)
+	fs.Parse(args)
+
+	p := mustLoad(*path)
+	printProposal(out, p)
+
+	// result 输出结论；结论不是 READY 时以状态码 1 退出
+	result := func(ready bool, format string, args ...any) {
+		out.Section("Result")
+		out.Field("Result", fmt.Sprintf(format, args...))
+		if err := out.Flush(); err != nil {
+			log.Fatal(err)
+		}
+		if !ready {
+			os.Exit(1)
+		}
+	}
+
+	valid, err := p.verifiedSigners()
+	if err != nil {
+		result(false, "INVALID (%v)", err)
+	}
+	out.Table("Signers", "Signed", "Owner")
+	signed := make(map[common.Address]bool)
+	for _, s := range valid {
+		signed[s.Signer] = true
+	}
+	for _, o := range p.Owners {
+		out.Row(signed[o], o)
+	}
+
+	if config.Get().RPCURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(time.Minute))
+		defer cancel()
+		client := mustDial(ctx)
+		defer client.Close()
+		if err := verifyChain(ctx, client, p); err != nil {
+			result(false, "STALE (%v)", err)
+		}
+	}
+
+	if uint64(len(valid)) < p.Threshold {
+		result(false, "%d / %d signatures, waiting for %d more", len(valid), p.Threshold, p.Threshold-uint64(len(valid)))
+	}
+	result(true, "READY (%d / %d signatures)", len(valid), p.Threshold)
+}
+
+// cmdExecute 聚合签名并广播执行交易
+func cmdExecute(args []string) {
+	fs := flag.NewFlagSet("execute", flag.ExitOnError)
+	path := fs.String("proposal", "proposal.json", "proposal file")
+	config.AddFlags(fs)
+	out := output.AddFlags(fs)
+	fs.Parse(args)
+
+	p := mustLoad(*path)
+	key := mustKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	if p.Executor != (common.Address{}) && p.Executor != from {
+		log.Fatalf("proposal can only be executed by %s", p.Executor.Hex())
+	}
+
+	valid, err := p.verifiedSigners()
+	if err != nil {
+		log.Fatalf("proposal failed verification: %v", err)
+	}
+	if uint64(len(valid)) < p.Threshold {
+		log.Fatalf("not enough signatures: %d / %d", len(valid), p.Threshold)
+	}
+	// verifiedSigners 已按地址升序排列，取前 threshold 个仍然有序
+	sigs := valid[:p.Threshold]
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(3*time.Minute))
+	defer cancel()
+	client := mustDial(ctx)
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatalf("failed to get chain id: %v", err)
+	}
+	if chainID.Cmp(p.ChainID.ToInt()) != 0 {
+		log.Fatalf("proposal is for chain %s, node is on chain %s", p.ChainID.ToInt(), chainID)
+	}
+	if err := verifyChain(ctx, client, p); err != nil {
+		log.Fatalf("proposal is stale: %v", err)
+	}
+	balance, err := client.BalanceAt(ctx, p.Multisig, nil)
+	if err != nil {
+		log.Fatalf("failed to get multisig balance: %v", err)
+	}
+	if balance.Cmp(p.Value.ToInt()) < 0 {
+		log.Fatalf("multisig balance %s wei is lower than value %s wei", balance, p.Value.ToInt())
+	}
+
+	ms, err := newMultisig(client, p.Multisig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	callData, err := ms.packExecute(p, sigs)
+	if err != nil {
+		log.Fatalf("failed to pack execute data: %v", err)
+	}
+
+	out.Table("Signers", "Signer")
+	for _, s := range sigs {
+		out.Row(s.Signer)
+	}
+
+	// EstimateGas 同时起到模拟的作用：签名无效、nonce 不对时这里就会 revert
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &p.Multisig, Data: callData})
+	if err != nil {
+		log.Fatalf("execute would revert: %v", err)
+	}
+	gasLimit = gasLimit * 120 / 100
+
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		log.Fatalf("failed to get nonce: %v", err)
+	}
+	gasTipCap, gasFeeCap, err := ethutil.DynamicFees(ctx, client)
+	if err != nil {
+		log.Fatalf("failed to compute fees: %v", err)
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &p.Multisig,
+		Value:     big.NewInt(0),
+		Data:      callData,
+	})
+	signedTx, err := types.SignTx(tx, types.NewLondonSigner(chainID), key)
+	if err != nil {
+		log.Fatalf("failed to sign transaction: %v", err)
+	}
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		log.Fatalf("failed to send transaction: %v", err)
+	}
+	out.Note("sent execute tx %s, waiting for confirmation...", signedTx.Hash().Hex())
+
+	receipt, err := txwait.WaitMined(ctx, client, signedTx.Hash(), 1)
+	if err != nil {
+		log.Fatalf("failed to wait for receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		log.Fatalf("execute tx failed in block %d", receipt.BlockNumber.Uint64())
+	}
+	out.Section("Execute")
+	out.Field("Tx Hash", signedTx.Hash())
+	out.Field("Block", receipt.BlockNumber)
+	out.Field("Gas Used", receipt.GasUsed)
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// verifyChain 对照链上的 nonce、阈值和所有者集合
+func verifyChain(ctx context.Context, client *ethclient.Client, p *proposal) error {
+	ms, err := newMultisig(client, p.Multisig)
+	if err != nil {
+		return err
+	}
+	st, err := ms.State(ctx)
+	if err != nil {
+		return err
+	}
+	return checkAgainstChain(p, st)
+}
+
+// printProposal 输出提案内容，签名前供所有者核对
+func printProposal(out *output.Printer, p *proposal) {
+	executor := "anyone"
+	if p.Executor != (common.Address{}) {
+		executor = p.Executor.Hex()
+	}
+	out.Section("Proposal")
+	out.Field("Chain ID", p.ChainID.ToInt())
+	out.Field("Multisig", p.Multisig)
+	out.Field("Threshold", fmt.Sprintf("%d-of-%d", p.Threshold, len(p.Owners)))
+	out.Field("Nonce", p.Nonce)
+	out.Field("Destination", p.Destination)
+	out.Field("Value (wei)", p.Value.ToInt())
+	out.Field("Data", hexutil.Encode(p.Data))
+	out.Field("Executor", executor)
+	out.Field("Gas Limit", p.GasLimit)
+	out.Field("Digest", p.Digest)
+}
+
+// mustLoad 读取提案文件
+func mustLoad(path string) *proposal {
+	p, err := loadProposal(path)
+	if err != nil {
+		log.Fatalf("failed to load %s: %v", path, err)
+	}
+	return p
+}
+
+// mustKey 读取 SENDER_PRIVATE_KEY
+func mustKey() *ecdsa.PrivateKey {
+	privKeyHex := config.Get().PrivateKey
+	if privKeyHex == "" {
+		log.Fatal("SENDER_PRIVATE_KEY is not set")
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privKeyHex, "0x"))
+	if err != nil {
+		log.Fatalf("invalid private key: %v", err)
+	}
+	return key
+}
+
+// mustDial 连接配置中的节点（--rpc / ETH_RPC_URL / profile）
+func mustDial(ctx context.Context) *ethclient.Client {
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	return client
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SimpleMultiSig（github.com/christianlundkvist/simple-multisig）的 EIP-712 常量
+var (
+	domainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract,bytes32 salt)"))
+	txTypeHash     = crypto.Keccak256Hash([]byte("MultiSigTransaction(address destination,uint256 value,bytes data,uint256 nonce,address executor,uint256 gasLimit)"))
+	nameHash       = crypto.Keccak256Hash([]byte("Simple MultiSig"))
+	versionHash    = crypto.Keccak256Hash([]byte("1"))
+	domainSalt     = common.HexToHash("0x251543af6a222378665a76fe38dbceae4871a070b7fdaf5c6c30cf758dc33cc0")
+)
+
+// partialSig 一个所有者的签名
+type partialSig struct {
+	Signer    common.Address `json:"signer"`
+	Signature hexutil.Bytes  `json:"signature"` // 65 字节，V 为 27 / 28
+}
+
+// proposal 待签名的多签交易，以 JSON 文件在所有者之间传递：
+// propose 创建，每个所有者 sign 追加自己的签名，最后任何人 execute
+type proposal struct {
+	ChainID     *hexutil.Big     `json:"chainId"`
+	Multisig    common.Address   `json:"multisig"`
+	Nonce       uint64           `json:"nonce"`
+	Destination common.Address   `json:"destination"`
+	Value       *hexutil.Big     `json:"value"`
+	Data        hexutil.Bytes    `json:"data"`
+	Executor    common.Address   `json:"executor"` // 零地址表示任何人都可以执行
+	GasLimit    uint64           `json:"gasLimit"` // 内部调用的 gas 上限，不是执行交易的 gas
+	Threshold   uint64           `json:"threshold"`
+	Owners      []common.Address `json:"owners"`
+	Digest      common.Hash      `json:"digest"`
+	Signatures  []partialSig     `json:"signatures"`
+}
+
+// word 把整数编码为 32 字节的 ABI 字
+func word(v *big.Int) []byte {
+	return common.BigToHash(v).Bytes()
+}
+
+// computeDigest 按合约中的方式计算 EIP-712 摘要：
+// keccak256("\x19\x01" || domainSeparator || keccak256(abi.encode(TXTYPE_HASH, destination, value, keccak256(data), nonce, executor, gasLimit)))
+func (p *proposal) computeDigest() common.Hash {
+	domainSeparator := crypto.Keccak256Hash(
+		domainTypeHash[:],
+		nameHash[:],
+		versionHash[:],
+		word(p.ChainID.ToInt()),
+		common.BytesToHash(p.Multisig[:]).Bytes(),
+		domainSalt[:],
+	)
+	txInputHash := crypto.Keccak256Hash(
+		txTypeHash[:],
+		common.BytesToHash(p.Destination[:]).Bytes(),
+		word(p.Value.ToInt()),
+		crypto.Keccak256(p.Data),
+		word(new(big.Int).SetUint64(p.Nonce)),
+		common.BytesToHash(p.Executor[:]).Bytes(),
+		word(new(big.Int).SetUint64(p.GasLimit)),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator[:], txInputHash[:])
+}
+
+// isOwner 地址是否在提案记录的所有者列表中
+func (p *proposal) isOwner(addr common.Address) bool {
+	for _, o := range p.Owners {
+		if o == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// verifiedSigners 校验提案和全部签名，返回按地址升序排列的有效签名：
+// - 摘要必须与提案字段重新计算的结果一致（防止文件在传递中被篡改）
+// - 每个签名恢复出的地址必须与记录的 signer 一致，且属于所有者
+// - 同一所有者只计一次
+func (p *proposal) verifiedSigners() ([]partialSig, error) {
+	if digest := p.computeDigest(); digest != p.Digest {
+		return nil, fmt.Errorf("digest mismatch: file says %s, fields hash to %s", p.Digest.Hex(), digest.Hex())
+	}
+	seen := make(map[common.Address]bool)
+	var valid []partialSig
+	for i, s := range p.Signatures {
+		signer, err := recoverSigner(p.Digest, s.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("signature #%d: %w", i, err)
+		}
+		if signer != s.Signer {
+			return nil, fmt.Errorf("signature #%d: recovered %s, but recorded signer is %s", i, signer.Hex(), s.Signer.Hex())
+		}
+		if !p.isOwner(signer) {
+			return nil, fmt.Errorf("signature #%d: %s is not an owner", i, signer.Hex())
+		}
+		if seen[signer] {
+			return nil, fmt.Errorf("signature #%d: duplicate signature from %s", i, signer.Hex())
+		}
+		seen[signer] = true
+		valid = append(valid, s)
+	}
+	// 合约要求签名者地址严格递增，以此保证不重复
+	sort.Slice(valid, func(i, j int) bool { return bytes.Compare(valid[i].Signer[:], valid[j].Signer[:]) < 0 })
+	return valid, nil
+}
+
+// recoverSigner 从 V 为 27 / 28 的 65 字节签名恢复地址
+func recoverSigner(hash common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes, got %d", crypto.SignatureLength, len(sig))
+	}
+	raw := append([]byte(nil), sig...)
+	if raw[64] >= 27 {
+		raw[64] -= 27
+	}
+	pub, err := crypto.SigToPub(hash.Bytes(), raw)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover public key: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// loadProposal 读取提案文件
+func loadProposal(path string) (*proposal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p proposal
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid proposal file: %w", err)
+	}
+	if p.ChainID == nil || p.Value == nil {
+		return nil, fmt.Errorf("invalid proposal file: missing chainId or value")
+	}
+	return &p, nil
+}
+
+// save 原子写入提案文件（先写临时文件再重命名），避免签名过程中中断导致文件损坏
+func (p *proposal) save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".proposal-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// target 配置中的一项：调用 view 函数，或读取存储槽（二选一）
+//
+//	{"name": "USDC totalSupply", "address": "0x...", "call": "totalSupply()(uint256)"}
+//	{"name": "vault balance", "address": "0x...", "call": "balanceOf(address)(uint256)", "args": ["0x..."]}
+//	{"name": "proxy impl", "address": "0x...", "slot": "0x3608...", "type": "address"}
+type target struct {
+	Name    string   `json:"name"`
+	Address string   `json:"address"`
+	Call    string   `json:"call,omitempty"` // name(inputs)(outputs)，与 cast call 的写法相同
+	Args    []string `json:"args,omitempty"`
+	Slot    string   `json:"slot,omitempty"`
+	Type    string   `json:"type,omitempty"` // 存储槽的解释方式：bytes32（默认）| uint256 | address
+
+	addr     common.Address
+	method   *abi.Method
+	callData []byte
+	slot     common.Hash
+}
+
+// diffConfig 配置文件：{"targets": [...]}
+type diffConfig struct {
+	Targets []*target `json:"targets"`
+}
+
+// loadConfig 读取并校验配置，预先编码好每个调用的 calldata
+func loadConfig(path string) (*diffConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg diffConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if len(cfg.Targets) == 0 {
+		return nil, fmt.Errorf("config has no targets")
+	}
+	for i, t := range cfg.Targets {
+		if t.Name == "" {
+			t.Name = fmt.Sprintf("#%d", i)
+		}
+		if err := t.prepare(); err != nil {
+			return nil, fmt.Errorf("target %s: %w", t.Name, err)
+		}
+	}
+	return &cfg, nil
+}
+
+// prepare 解析地址、函数签名和参数
+func (t *target) prepare() error {
+	if !common.IsHexAddress(t.Address) {
+		return fmt.Errorf("invalid address %q", t.Address)
+	}
+	t.addr = common.HexToAddress(t.Address)
+	if (t.Call == "") == (t.Slot == "") {
+		return fmt.Errorf("exactly one of call or slot is required")
+	}
+
+	if t.Slot != "" {
+		// 槽号可以写十进制（"3"）或十六进制（"0x3608..."）
+		n, ok := new(big.Int).SetString(t.Slot, 0)
+		if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+			return fmt.Errorf("invalid slot %q", t.Slot)
+		}
+		t.slot = common.BigToHash(n)
+		switch t.Type {
+		case "":
+			t.Type = "bytes32"
+		case "bytes32", "uint256", "address":
+		default:
+			return fmt.Errorf("unsupported slot type %q", t.Type)
+		}
+		return nil
+	}
+
+	m, err := parseSignature(t.Call)
+	if err != nil {
+		return err
+	}
+	if len(t.Args) != len(m.Inputs) {
+		return fmt.Errorf("%s takes %d args, got %d", m.Sig, len(m.Inputs), len(t.Args))
+	}
+	args := make([]interface{}, len(t.Args))
+	for i, s := range t.Args {
+		if args[i], err = convertArg(m.Inputs[i].Type, s); err != nil {
+			return fmt.Errorf("arg %d: %w", i, err)
+		}
+	}
+	input, err := m.Inputs.Pack(args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack args: %w", err)
+	}
+	t.method = m
+	t.callData = append(append([]byte(nil), m.ID...), input...)
+	return nil
+}
+
+// parseSignature 解析 "balanceOf(address)(uint256)"；不支持 tuple 类型
+func parseSignature(sig string) (*abi.Method, error) {
+	open := strings.Index(sig, "(")
+	closeIdx := strings.Index(sig, ")")
+	if open <= 0 || closeIdx < open {
+		return nil, fmt.Errorf("invalid call %q, expected name(inputs)(outputs)", sig)
+	}
+	name := sig[:open]
+	rest := strings.TrimSpace(sig[closeIdx+1:])
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return nil, fmt.Errorf("invalid call %q: missing output types, e.g. totalSupply()(uint256)", sig)
+	}
+	inputs, err := parseArgs(sig[open+1 : closeIdx])
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := parseArgs(rest[1 : len(rest)-1])
+	if err != nil {
+		return nil, err
+	}
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("invalid call %q: function must return a value", sig)
+	}
+	m := abi.NewMethod(name, name, abi.Function, "view", false, false, inputs, outputs)
+	return &m, nil
+}
+
+// parseArgs 把逗号分隔的类型列表转换为 abi.Arguments
+func parseArgs(list string) (abi.Arguments, error) {
+	var args abi.Arguments
+	if strings.TrimSpace(list) == "" {
+		return args, nil
+	}
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSpace(s)
+		if strings.Contains(s, "(") {
+			return nil, fmt.Errorf("tuple types are not supported: %q", s)
+		}
+		typ, err := abi.NewType(s, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type %q: %w", s, err)
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return args, nil
+}
+
+// convertArg 把配置中的字符串参数转换为 abi.Pack 需要的 Go 类型（地址、整数、bool、bytes32、bytes、string）
+func convertArg(typ abi.Type, s string) (interface{}, error) {
+	switch typ.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("invalid address %q", s)
+		}
+		return common.HexToAddress(s), nil
+	case abi.BoolTy:
+		switch s {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid bool %q", s)
+	case abi.UintTy, abi.IntTy:
+		v, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", s)
+		}
+		if typ.Size > 64 {
+			return v, nil
+		}
+		// 64 位及以下的整数，abi.Pack 要求对应的原生类型（uint8、int32 等）
+		switch {
+		case typ.T == abi.UintTy && typ.Size == 8:
+			return uint8(v.Uint64()), nil
+		case typ.T == abi.UintTy && typ.Size == 16:
+			return uint16(v.Uint64()), nil
+		case typ.T == abi.UintTy && typ.Size == 32:
+			return uint32(v.Uint64()), nil
+		case typ.T == abi.UintTy && typ.Size == 64:
+			return v.Uint64(), nil
+		case typ.Size == 8:
+			return int8(v.Int64()), nil
+		case typ.Size == 16:
+			return int16(v.Int64()), nil
+		case typ.Size == 32:
+			return int32(v.Int64()), nil
+		case typ.Size == 64:
+			return v.Int64(), nil
+		}
+		return v, nil
+	case abi.FixedBytesTy:
+		b, err := hexutil.Decode(s)
+		if err != nil || len(b) != typ.Size || typ.Size != 32 {
+			return nil, fmt.Errorf("invalid %s %q (only bytes32 is supported)", typ.String(), s)
+		}
+		return [32]byte(common.BytesToHash(b)), nil
+	case abi.BytesTy:
+		return hexutil.Decode(s)
+	case abi.StringTy:
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported argument type %s", typ.String())
+}
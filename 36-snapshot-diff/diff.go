@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// change 一个 target 在两个区块之间的差异
+type change struct {
+	Name    string   `json:"name"`
+	Address string   `json:"address"`
+	Status  string   `json:"status"` // changed | unchanged | error
+	Before  string   `json:"before"`
+	After   string   `json:"after"`
+	Delta   string   `json:"delta,omitempty"`   // 仅单个整数返回值
+	Percent *float64 `json:"percent,omitempty"` // 相对 before 的变化百分比，before 为 0 时省略
+}
+
+// diffReadings 逐项比较两次快照
+func diffReadings(targets []*target, before, after []reading) []change {
+	out := make([]change, len(targets))
+	for i, t := range targets {
+		c := change{Name: t.Name, Address: t.addr.Hex(), Before: formatReading(before[i]), After: formatReading(after[i])}
+		switch {
+		case before[i].Err != "" && after[i].Err != "":
+			c.Status = "error"
+		case c.Before == c.After:
+			c.Status = "unchanged"
+		default:
+			c.Status = "changed"
+		}
+		if c.Status == "changed" {
+			if a, b, ok := numericPair(before[i], after[i]); ok {
+				delta := new(big.Int).Sub(b, a)
+				c.Delta = signed(delta)
+				if a.Sign() != 0 {
+					pct, _ := new(big.Float).Quo(new(big.Float).SetInt(delta), new(big.Float).SetInt(a)).Float64()
+					pct *= 100
+					c.Percent = &pct
+				}
+			}
+		}
+		out[i] = c
+	}
+	return out
+}
+
+// numericPair 两次读数都是单个整数时返回这两个值
+func numericPair(before, after reading) (*big.Int, *big.Int, bool) {
+	if len(before.Values) != 1 || len(after.Values) != 1 {
+		return nil, nil, false
+	}
+	a, ok1 := toBig(before.Values[0])
+	b, ok2 := toBig(after.Values[0])
+	return a, b, ok1 && ok2
+}
+
+// toBig 把 abi 解码出的各种整数类型统一为 *big.Int
+func toBig(v interface{}) (*big.Int, bool) {
+	switch x := v.(type) {
+	case *big.Int:
+		return x, true
+	case uint8:
+		return new(big.Int).SetUint64(uint64(x)), true
+	case uint16:
+		return new(big.Int).SetUint64(uint64(x)), true
+	case uint32:
+		return new(big.Int).SetUint64(uint64(x)), true
+	case uint64:
+		return new(big.Int).SetUint64(x), true
+	case int8:
+		return big.NewInt(int64(x)), true
+	case int16:
+		return big.NewInt(int64(x)), true
+	case int32:
+		return big.NewInt(int64(x)), true
+	case int64:
+		return big.NewInt(x), true
+	}
+	return nil, false
+}
+
+// signed 带符号输出整数，正数加 "+"
+func signed(v *big.Int) string {
+	if v.Sign() > 0 {
+		return "+" + v.String()
+	}
+	return v.String()
+}
+
+// formatReading 把读数格式化为字符串，多个返回值用逗号分隔
+func formatReading(r reading) string {
+	if r.Err != "" {
+		return "error: " + r.Err
+	}
+	parts := make([]string, len(r.Values))
+	for i, v := range r.Values {
+		parts[i] = formatValue(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatValue 格式化单个值：地址用校验和格式，字节用 0x 十六进制
+func formatValue(v interface{}) string {
+	switch x := v.(type) {
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	case [32]byte:
+		return common.Hash(x).Hex()
+	case []byte:
+		return hexutil.Encode(x)
+	case []common.Address:
+		parts := make([]string, len(x))
+		for i, a := range x {
+			parts[i] = a.Hex()
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	}
+	if n, ok := toBig(v); ok {
+		return n.String()
+	}
+	return fmt.Sprintf("%v", v)
+}
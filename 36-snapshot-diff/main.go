@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// 36-snapshot-diff
+// 链上状态快照对比：在两个区块高度读取一组配置好的 view 函数 / 存储槽，输出结构化的差异（哪些变了、变了多少）。
+// 典型用途：
+// - 合约升级前后核对：实现地址槽、owner、参数、总量等是否只有预期的变化
+// - 事故取证：攻击交易前后的余额、储备量、价格预言机读数
+//
+// - 函数调用通过 Multicall3 aggregate3 合并为少量 eth_call（每 --batch 个一次）；区块上 Multicall3 尚未部署时退回逐个调用
+// - 存储槽通过一个 JSON-RPC batch 的 eth_getStorageAt 读取
+// - 整数值输出差值和变化百分比；--json 输出机器可读的结果
+//
+// 执行示例：
+//    export ETH_RPC_URL="https://mainnet.infura.io/v3/<project-id>"
+//    go run . --config targets.json --from-block 19000000 --to-block 19001000
+//    go run . --config targets.json --from-block 19000000 --all --format json > diff.json
+//
+// targets.json 示例：
+//    {"targets": [
+//      {"name": "USDC totalSupply", "address": "0xA0b8...eB48", "call": "totalSupply()(uint256)"},
+//      {"name": "vault USDC", "address": "0xA0b8...eB48", "call": "balanceOf(address)(uint256)", "args": ["0x..."]},
+//      {"name": "USDC impl", "address": "0xA0b8...eB48", "slot": "0x7050c9e0f4ca769c69bd3a8ef740bc37934f8e2c036e5a723fd8ee048ed3f8c3", "type": "address"}
+//    ]}
+//
+// 注意事项：
+// - 读取历史区块的状态需要归档节点（全节点通常只保留最近 128 个区块的状态）
+// - 函数签名写法与 cast call 相同：name(inputs)(outputs)，不支持 tuple
+// - 调用失败（revert、合约在该区块尚未部署）会作为读数的一部分输出，不会中止整个对比
+
+func main() {
+	configPath := flag.String("config", "targets.json", "JSON file listing the targets to read")
+	fromBlock := flag.Int64("from-block", -1, "block of the first snapshot (required)")
+	toBlock := flag.Int64("to-block", -1, "block of the second snapshot (-1 = latest)")
+	batchSize := flag.Int("batch", 100, "calls per Multicall3 aggregate3 request")
+	showAll := flag.Bool("all", false, "also list targets that did not change")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *fromBlock < 0 {
+		log.Fatal("--from-block is required")
+	}
+	if *batchSize <= 0 {
+		log.Fatal("--batch must be positive")
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	rpcURL := config.Get().RPCURL
+	if rpcURL == "" {
+		log.Fatal(config.ErrNoRPC)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutOr(2*time.Minute))
+	defer cancel()
+
+	client, err := rpcretry.Dial(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	to := uint64(*toBlock)
+	if *toBlock < 0 {
+		if to, err = client.BlockNumber(ctx); err != nil {
+			log.Fatalf("failed to get block number: %v", err)
+		}
+	}
+	from := uint64(*fromBlock)
+	if from > to {
+		log.Fatalf("--from-block %d is after --to-block %d", from, to)
+	}
+
+	s, err := newSnapshotter(client, *batchSize)
+	if err != nil {
+		log.Fatal(err)
+	}
+	before, err := s.Snapshot(ctx, cfg.Targets, new(big.Int).SetUint64(from))
+	if err != nil {
+		log.Fatalf("failed to read snapshot at block %d: %v", from, err)
+	}
+	after, err := s.Snapshot(ctx, cfg.Targets, new(big.Int).SetUint64(to))
+	if err != nil {
+		log.Fatalf("failed to read snapshot at block %d: %v", to, err)
+	}
+	changes := diffReadings(cfg.Targets, before, after)
+
+	printDiff(out, from, to, changes, *showAll)
+	if err := out.Flush(); err != nil {
+		log.Fatalf("failed to write diff: %v", err)
+	}
+}
+
+// printDiff 输出汇总和差异列表
+func printDiff(out *output.Printer, from, to uint64, changes []change, showAll bool) {
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Status]++
+	}
+
+	out.Section("Snapshot Diff")
+	out.Field("From Block", from)
+	out.Field("To Block", to)
+	out.Field("Targets", len(changes))
+	out.Field("Changed", counts["changed"])
+	out.Field("Unchanged", counts["unchanged"])
+	out.Field("Error", counts["error"])
+
+	out.Table("Changes", "Status", "Name", "Address", "Before", "After", "Delta", "Percent")
+	for _, c := range changes {
+		if c.Status == "unchanged" && !showAll {
+			continue
+		}
+		// 百分比：pretty 模式下格式化为带符号的字符串，结构化格式保留数值
+		var percent any
+		switch {
+		case c.Percent == nil:
+			if out.Pretty() {
+				percent = ""
+			}
+		case out.Pretty():
+			percent = fmt.Sprintf("%+.4f%%", *c.Percent)
+		default:
+			percent = *c.Percent
+		}
+		out.Row(c.Status, c.Name, c.Address, c.Before, c.After, c.Delta, percent)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Multicall3 在绝大多数 EVM 链上部署在同一地址（确定性部署）
+var multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+const multicall3ABIJSON = `[
+  {"inputs": [{"components": [
+      {"name": "target", "type": "address"},
+      {"name": "allowFailure", "type": "bool"},
+      {"name": "callData", "type": "bytes"}
+    ], "name": "calls", "type": "tuple[]"}],
+   "name": "aggregate3",
+   "outputs": [{"components": [
+      {"name": "success", "type": "bool"},
+      {"name": "returnData", "type": "bytes"}
+    ], "name": "returnData", "type": "tuple[]"}],
+   "stateMutability": "payable", "type": "function"}
+]`
+
+// call3 / result3 与 aggregate3 的 tuple 字段一一对应（abi 包按字段名匹配）
+type call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type result3 struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// reading 某个 target 在某个区块的读数
+type reading struct {
+	Values []interface{} // 解码后的返回值
+	Err    string        // 调用失败（revert、合约尚未部署等）
+}
+
+// snapshotter 在指定区块读取全部 target
+type snapshotter struct {
+	client    *ethclient.Client
+	multicall abi.ABI
+	batchSize int
+}
+
+func newSnapshotter(client *ethclient.Client, batchSize int) (*snapshotter, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(multicall3ABIJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	return &snapshotter{client: client, multicall: parsedABI, batchSize: batchSize}, nil
+}
+
+// Snapshot 读取所有 target 在 block 时的值：函数调用经 Multicall3 合并为少量 eth_call，
+// 存储槽用一个 JSON-RPC batch 读取；返回的切片与 targets 顺序一致
+func (s *snapshotter) Snapshot(ctx context.Context, targets []*target, block *big.Int) ([]reading, error) {
+	out := make([]reading, len(targets))
+	var calls, slots []int
+	for i, t := range targets {
+		if t.method != nil {
+			calls = append(calls, i)
+		} else {
+			slots = append(slots, i)
+		}
+	}
+
+	if len(calls) > 0 {
+		// 旧区块上 Multicall3 可能尚未部署，此时退回逐个 eth_call
+		code, err := s.client.CodeAt(ctx, multicall3Address, block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check multicall code: %w", err)
+		}
+		for lo := 0; lo < len(calls); lo += s.batchSize {
+			hi := min(lo+s.batchSize, len(calls))
+			if len(code) > 0 {
+				err = s.aggregate(ctx, targets, calls[lo:hi], block, out)
+			} else {
+				err = s.callEach(ctx, targets, calls[lo:hi], block, out)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(slots) > 0 {
+		if err := s.readSlots(ctx, targets, slots, block, out); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// aggregate 用一次 aggregate3 调用读取一批函数；allowFailure=true，单个调用失败不影响其他调用
+func (s *snapshotter) aggregate(ctx context.Context, targets []*target, idx []int, block *big.Int, out []reading) error {
+	batch := make([]call3, len(idx))
+	for j, i := range idx {
+		batch[j] = call3{Target: targets[i].addr, AllowFailure: true, CallData: targets[i].callData}
+	}
+	data, err := s.multicall.Pack("aggregate3", batch)
+	if err != nil {
+		return fmt.Errorf("failed to pack aggregate3: %w", err)
+	}
+	output, err := s.client.CallContract(ctx, ethereum.CallMsg{To: &multicall3Address, Data: data}, block)
+	if err != nil {
+		return fmt.Errorf("aggregate3 at block %s: %w", block, err)
+	}
+	var results []result3
+	if err := s.multicall.UnpackIntoInterface(&results, "aggregate3", output); err != nil {
+		return fmt.Errorf("failed to decode aggregate3 result: %w", err)
+	}
+	if len(results) != len(idx) {
+		return fmt.Errorf("aggregate3 returned %d results for %d calls", len(results), len(idx))
+	}
+	for j, i := range idx {
+		if !results[j].Success {
+			out[i] = reading{Err: "call reverted"}
+			continue
+		}
+		out[i] = decodeReturn(targets[i], results[j].ReturnData)
+	}
+	return nil
+}
+
+// callEach 逐个 eth_call，用于 Multicall3 不可用的区块
+func (s *snapshotter) callEach(ctx context.Context, targets []*target, idx []int, block *big.Int, out []reading) error {
+	for _, i := range idx {
+		t := targets[i]
+		output, err := s.client.CallContract(ctx, ethereum.CallMsg{To: &t.addr, Data: t.callData}, block)
+		if err != nil {
+			out[i] = reading{Err: err.Error()}
+			continue
+		}
+		out[i] = decodeReturn(t, output)
+	}
+	return nil
+}
+
+// decodeReturn 按函数签名解码返回值；不是合约的地址调用"成功"但返回空数据，这里视为错误
+func decodeReturn(t *target, data []byte) reading {
+	if len(data) == 0 {
+		return reading{Err: "empty return data (no contract at this block?)"}
+	}
+	values, err := t.method.Outputs.Unpack(data)
+	if err != nil {
+		return reading{Err: fmt.Sprintf("failed to decode: %v", err)}
+	}
+	return reading{Values: values}
+}
+
+// readSlots 用一个 JSON-RPC batch 读取所有存储槽
+func (s *snapshotter) readSlots(ctx context.Context, targets []*target, idx []int, block *big.Int, out []reading) error {
+	results := make([]hexutil.Bytes, len(idx))
+	batch := make([]rpc.BatchElem, len(idx))
+	for j, i := range idx {
+		batch[j] = rpc.BatchElem{
+			Method: "eth_getStorageAt",
+			Args:   []interface{}{targets[i].addr, targets[i].slot, hexutil.EncodeBig(block)},
+			Result: &results[j],
+		}
+	}
+	if err := s.client.Client().BatchCallContext(ctx, batch); err != nil {
+		return fmt.Errorf("batch eth_getStorageAt: %w", err)
+	}
+	for j, i := range idx {
+		if batch[j].Error != nil {
+			out[i] = reading{Err: batch[j].Error.Error()}
+			continue
+		}
+		out[i] = reading{Values: []interface{}{decodeSlot(targets[i].Type, common.BytesToHash(results[j]))}}
+	}
+	return nil
+}
+
+// decodeSlot 按配置的类型解释 32 字节的槽值
+func decodeSlot(typ string, v common.Hash) interface{} {
+	switch typ {
+	case "uint256":
+		return v.Big()
+	case "address":
+		return common.BytesToAddress(v[12:])
+	default:
+		return v
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/yzucdh1/examples/25-chain-tracker/chaintracker"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// collector 跟踪一个 endpoint 的规范链，把 chaintracker 的 removed / added 事件组装成重组记录
+type collector struct {
+	label string // 写入数据库的名称，只含 scheme://host，不保存 URL 中的 API key
+	url   string
+	store *store
+
+	tracker *chaintracker.Tracker
+	pending *reorgRecord // 正在组装的重组：已收到 removed，新分支还没有全部加入
+
+	onReorg func(r *reorgRecord) // 输出识别到的重组，可为 nil
+}
+
+func newCollector(rawURL, label string, st *store, depth uint64) *collector {
+	return &collector{label: label, url: rawURL, store: st, tracker: chaintracker.New(nil, depth)}
+}
+
+// endpointLabel 去掉 URL 的路径和查询参数（Infura / Alchemy 的 key 在路径里）
+func endpointLabel(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Run 连接节点并持续跟踪，断开后按指数退避重连（与 25-chain-tracker 相同），直到 ctx 取消
+func (c *collector) Run(ctx context.Context) {
+	for attempt := 1; ; attempt++ {
+		client, err := rpcretry.Dial(ctx, c.url)
+		if err == nil {
+			log.Printf("[%s] connected", c.label)
+			started := time.Now()
+			err = c.tracker.Follow(ctx, client, c.handle)
+			client.Close()
+			if time.Since(started) > time.Minute {
+				attempt = 1
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("[WARN] [%s] tracker stopped: %v", c.label, err)
+		sleepWithBackoff(ctx, attempt)
+	}
+}
+
+// handle 处理规范链事件。一次重组的事件顺序为：旧分支 removed（高度降序），
+// 然后新分支 added（高度升序，Reorg=true），最后一个 added 就是新的链头
+func (c *collector) handle(ev chaintracker.Event) {
+	h := ev.Header
+	switch ev.Type {
+	case chaintracker.BlockRemoved:
+		if c.pending == nil {
+			c.pending = &reorgRecord{Endpoint: c.label, DetectedAt: time.Now(), OldHead: h.Hash()}
+		}
+		c.pending.Removed = append(c.pending.Removed, h.Hash())
+		c.pending.Ancestor = h.Number.Uint64() - 1
+
+	case chaintracker.BlockAdded:
+		if err := c.store.CountBlocks(c.label, 1, time.Now()); err != nil {
+			log.Printf("[WARN] [%s] failed to count block: %v", c.label, err)
+		}
+		if c.pending == nil || !ev.Reorg {
+			return
+		}
+		c.pending.Added = append(c.pending.Added, h.Hash())
+		if head := c.tracker.Head(); head == nil || head.Hash() != h.Hash() {
+			return
+		}
+		c.finish()
+	}
+}
+
+// finish 新分支全部加入后写入重组记录
+func (c *collector) finish() {
+	r := c.pending
+	c.pending = nil
+	// removed 事件按高度降序到达，统一改为升序保存
+	for i, j := 0, len(r.Removed)-1; i < j; i, j = i+1, j-1 {
+		r.Removed[i], r.Removed[j] = r.Removed[j], r.Removed[i]
+	}
+	r.Depth = len(r.Removed)
+	r.NewHead = r.Added[len(r.Added)-1]
+
+	if c.onReorg != nil {
+		c.onReorg(r)
+	}
+	if err := c.store.InsertReorg(r); err != nil {
+		log.Printf("[WARN] [%s] failed to save reorg: %v", c.label, err)
+	}
+}
+
+// sleepWithBackoff 指数退避，最大 1 分钟
+func sleepWithBackoff(ctx context.Context, attempt int) {
+	d := time.Duration(math.Min(60, math.Pow(2, float64(attempt)))) * time.Second
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
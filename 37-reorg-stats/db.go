@@ -0,0 +1,167 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	_ "modernc.org/sqlite" // 纯 Go 的 SQLite 驱动，不需要 cgo
+)
+
+// reorgRecord 一次观察到的重组
+type reorgRecord struct {
+	ID         int64
+	Endpoint   string
+	DetectedAt time.Time
+	Depth      int    // 被移除的规范链区块数
+	Ancestor   uint64 // 共同祖先高度
+	OldHead    common.Hash
+	NewHead    common.Hash
+	Removed    []common.Hash // 被替换的区块，按高度升序
+	Added      []common.Hash // 新分支区块，按高度升序
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS reorgs (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	endpoint    TEXT    NOT NULL,
+	detected_at INTEGER NOT NULL,
+	depth       INTEGER NOT NULL,
+	ancestor    INTEGER NOT NULL,
+	old_head    TEXT    NOT NULL,
+	new_head    TEXT    NOT NULL,
+	removed     TEXT    NOT NULL,
+	added       TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS reorgs_detected_at ON reorgs (detected_at);
+CREATE TABLE IF NOT EXISTS endpoints (
+	endpoint   TEXT    PRIMARY KEY,
+	first_seen INTEGER NOT NULL,
+	last_seen  INTEGER NOT NULL,
+	blocks     INTEGER NOT NULL DEFAULT 0
+);`
+
+// store SQLite 存储
+type store struct {
+	db *sql.DB
+}
+
+// openStore 打开（必要时创建）数据库
+func openStore(path string) (*store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite 同一时间只允许一个写者，多个 endpoint 的 goroutine 共用一个连接即可
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+	return &store{db: db}, nil
+}
+
+func (s *store) Close() error {
+	return s.db.Close()
+}
+
+// InsertReorg 写入一次重组
+func (s *store) InsertReorg(r *reorgRecord) error {
+	_, err := s.db.Exec(
+		`INSERT INTO reorgs (endpoint, detected_at, depth, ancestor, old_head, new_head, removed, added) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Endpoint, r.DetectedAt.Unix(), r.Depth, r.Ancestor, r.OldHead.Hex(), r.NewHead.Hex(), joinHashes(r.Removed), joinHashes(r.Added),
+	)
+	return err
+}
+
+// CountBlocks 累加 endpoint 观察到的规范链区块数，作为计算重组率的分母
+func (s *store) CountBlocks(endpoint string, n int, at time.Time) error {
+	_, err := s.db.Exec(
+		`INSERT INTO endpoints (endpoint, first_seen, last_seen, blocks) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (endpoint) DO UPDATE SET last_seen = excluded.last_seen, blocks = blocks + excluded.blocks`,
+		endpoint, at.Unix(), at.Unix(), n,
+	)
+	return err
+}
+
+// Reorgs 读取 since 之后的全部重组，按时间升序
+func (s *store) Reorgs(since time.Time) ([]reorgRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT id, endpoint, detected_at, depth, ancestor, old_head, new_head, removed, added FROM reorgs WHERE detected_at >= ? ORDER BY detected_at, id`,
+		since.Unix(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []reorgRecord
+	for rows.Next() {
+		var (
+			r                                reorgRecord
+			detectedAt                       int64
+			oldHead, newHead, removed, added string
+		)
+		if err := rows.Scan(&r.ID, &r.Endpoint, &detectedAt, &r.Depth, &r.Ancestor, &oldHead, &newHead, &removed, &added); err != nil {
+			return nil, err
+		}
+		r.DetectedAt = time.Unix(detectedAt, 0)
+		r.OldHead = common.HexToHash(oldHead)
+		r.NewHead = common.HexToHash(newHead)
+		r.Removed = splitHashes(removed)
+		r.Added = splitHashes(added)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// endpointStats 每个 endpoint 观察的时间范围和区块数
+type endpointStats struct {
+	Endpoint  string    `json:"endpoint"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Blocks    int64     `json:"blocks"`
+}
+
+// Endpoints 读取所有 endpoint 的观察记录
+func (s *store) Endpoints() ([]endpointStats, error) {
+	rows, err := s.db.Query(`SELECT endpoint, first_seen, last_seen, blocks FROM endpoints ORDER BY endpoint`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []endpointStats
+	for rows.Next() {
+		var e endpointStats
+		var first, last int64
+		if err := rows.Scan(&e.Endpoint, &first, &last, &e.Blocks); err != nil {
+			return nil, err
+		}
+		e.FirstSeen, e.LastSeen = time.Unix(first, 0), time.Unix(last, 0)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func joinHashes(hashes []common.Hash) string {
+	parts := make([]string, len(hashes))
+	for i, h := range hashes {
+		parts[i] = h.Hex()
+	}
+	return strings.Join(parts, ",")
+}
+
+func splitHashes(s string) []common.Hash {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	out := make([]common.Hash, len(parts))
+	for i, p := range parts {
+		out[i] = common.HexToHash(p)
+	}
+	return out
+}
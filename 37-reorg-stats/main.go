@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// 37-reorg-stats
+// 重组统计服务：同时订阅多个节点的新区块头，用 25-chain-tracker 的 chaintracker 包识别重组，
+// 把每次重组（深度、共同祖先、被替换 / 新加入的区块哈希、时间）写入 SQLite，并通过 HTTP 提供汇总统计。
+// 选择确认数时不必凭感觉：跑一段时间后就能看到目标链上实际出现过的重组深度和频率。
+//
+// - 每个 endpoint 独立维护区块树；同一次重组被多个节点看到时按被替换的旧链头去重
+// - 只有某一个节点报告的"重组"通常是该节点自己落后 / 切换了对等节点，对比多个节点可以区分
+// - --report 直接读取数据库输出统计后退出
+//
+// 执行示例：
+//    export ETH_WS_URL="wss://mainnet.infura.io/ws/v3/<project-id>"
+//    go run .
+//    go run . --endpoints wss://a.example/ws,wss://b.example/ws --db reorgs.db --listen :8080
+//    curl "localhost:8080/stats?since=24h"
+//    go run . --report --since 168h
+//    go run . --report --format json | jq '.Summary'
+//
+// 注意事项：
+// - 需要 WebSocket / IPC 连接（eth_subscribe）
+// - 数据库中只保存 endpoint 的 scheme://host，不保存 URL 路径中的 API key
+// - 重组深度超过 --depth 时无法找到共同祖先，会记录为整个保留窗口的深度
+
+func main() {
+	endpointsFlag := flag.String("endpoints", "", "comma-separated WebSocket endpoints (default: ETH_WS_URL)")
+	dbPath := flag.String("db", "reorgs.db", "SQLite database file")
+	depth := flag.Uint64("depth", 64, "blocks kept per endpoint (max detectable reorg depth)")
+	listen := flag.String("listen", ":8080", "HTTP address serving /stats (empty = disabled)")
+	report := flag.Bool("report", false, "print stats from the database and exit")
+	since := flag.Duration("since", 30*24*time.Hour, "time window for --report")
+	config.AddFlags(flag.CommandLine)
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	st, err := openStore(*dbPath)
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+	defer st.Close()
+
+	if *report {
+		s, err := buildSummary(st, time.Now().Add(-*since), 10)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printSummary(out, s)
+		if err := out.Flush(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var urls []string
+	if *endpointsFlag != "" {
+		for _, u := range strings.Split(*endpointsFlag, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				urls = append(urls, u)
+			}
+		}
+	} else if u := config.Get().WSURL; u != "" {
+		urls = append(urls, u)
+	}
+	if len(urls) == 0 {
+		log.Fatal("--endpoints, a ws:// --rpc or ETH_WS_URL is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 各 collector 在自己的 goroutine 中报告重组，Printer 不可并发使用
+	var outMu sync.Mutex
+	onReorg := func(r *reorgRecord) {
+		outMu.Lock()
+		defer outMu.Unlock()
+		out.Note("reorg  [%s] depth=%d ancestor=#%d old=%s new=%s", r.Endpoint, r.Depth, r.Ancestor, r.OldHead.TerminalString(), r.NewHead.TerminalString())
+		err := out.Record("reorg", "endpoint", r.Endpoint, "detected_at", r.DetectedAt, "depth", r.Depth, "ancestor", r.Ancestor,
+			"old_head", r.OldHead, "new_head", r.NewHead)
+		if err != nil {
+			log.Printf("[WARN] failed to write record: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	labels := make(map[string]int)
+	for _, u := range urls {
+		label := endpointLabel(u)
+		// 同一主机的多个 endpoint 加序号区分
+		if labels[label]++; labels[label] > 1 {
+			label = fmt.Sprintf("%s#%d", label, labels[label])
+		}
+		c := newCollector(u, label, st, *depth)
+		c.onReorg = onReorg
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Run(ctx)
+		}()
+	}
+
+	var srv *http.Server
+	if *listen != "" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /stats", statsHandler(st))
+		srv = &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			log.Printf("serving stats on %s/stats", *listen)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("[WARN] http server: %v", err)
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	out.Note("shutting down")
+	if srv != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// summary 重组统计
+type summary struct {
+	Since          time.Time       `json:"since"`
+	Endpoints      []endpointStats `json:"endpoints"`
+	Observations   int             `json:"observations"`   // 所有 endpoint 的重组记录数
+	DistinctReorgs int             `json:"distinctReorgs"` // 按被替换的旧链头去重（多个节点看到的同一次重组只算一次）
+	ByDepth        map[int]int     `json:"byDepth"`        // 深度 -> 次数（去重后）
+	ByEndpoint     map[string]int  `json:"byEndpoint"`
+	MaxDepth       int             `json:"maxDepth"`
+	PerThousand    float64         `json:"reorgsPer1000Blocks"` // 以观察区块最多的 endpoint 为分母
+	Suggested      int             `json:"suggestedConfirmations"`
+	Recent         []recentReorg   `json:"recent"`
+}
+
+// recentReorg 最近的重组（JSON 输出用）
+type recentReorg struct {
+	Endpoint   string        `json:"endpoint"`
+	DetectedAt time.Time     `json:"detectedAt"`
+	Depth      int           `json:"depth"`
+	Ancestor   uint64        `json:"ancestor"`
+	Removed    []common.Hash `json:"removed"`
+	Added      []common.Hash `json:"added"`
+}
+
+// buildSummary 汇总 since 之后的重组
+func buildSummary(st *store, since time.Time, recent int) (*summary, error) {
+	reorgs, err := st.Reorgs(since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reorgs: %w", err)
+	}
+	endpoints, err := st.Endpoints()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read endpoints: %w", err)
+	}
+
+	s := &summary{
+		Since:        since,
+		Endpoints:    endpoints,
+		Observations: len(reorgs),
+		ByDepth:      make(map[int]int),
+		ByEndpoint:   make(map[string]int),
+	}
+	seen := make(map[common.Hash]bool)
+	for _, r := range reorgs {
+		s.ByEndpoint[r.Endpoint]++
+		if seen[r.OldHead] {
+			continue
+		}
+		seen[r.OldHead] = true
+		s.DistinctReorgs++
+		s.ByDepth[r.Depth]++
+		s.MaxDepth = max(s.MaxDepth, r.Depth)
+	}
+
+	var blocks int64
+	for _, e := range endpoints {
+		blocks = max(blocks, e.Blocks)
+	}
+	if blocks > 0 {
+		s.PerThousand = float64(s.DistinctReorgs) * 1000 / float64(blocks)
+	}
+	// 建议的确认数：比观察到的最深重组多一个区块。这只是经验下限，
+	// 观察时间越短越不可靠；PoS 主网上需要最终性保证时应等待 finalized
+	s.Suggested = s.MaxDepth + 1
+
+	for i := len(reorgs) - 1; i >= 0 && len(s.Recent) < recent; i-- {
+		r := reorgs[i]
+		s.Recent = append(s.Recent, recentReorg{Endpoint: r.Endpoint, DetectedAt: r.DetectedAt, Depth: r.Depth, Ancestor: r.Ancestor, Removed: r.Removed, Added: r.Added})
+	}
+	return s, nil
+}
+
+// printSummary 输出统计
+func printSummary(out *output.Printer, s *summary) {
+	out.Section("Summary")
+	out.Field("Since", s.Since.Format(time.RFC3339))
+	out.Field("Reorgs", s.DistinctReorgs)
+	out.Field("Observations", s.Observations)
+	out.Field("Per 1k Blocks", fmt.Sprintf("%.3f", s.PerThousand))
+	out.Field("Max Depth", s.MaxDepth)
+	out.Field("Suggested", s.Suggested)
+
+	out.Table("Endpoints", "Endpoint", "Blocks", "First Seen", "Last Seen", "Reorgs")
+	for _, e := range s.Endpoints {
+		out.Row(e.Endpoint, e.Blocks, e.FirstSeen.Format(time.RFC3339), e.LastSeen.Format(time.RFC3339), s.ByEndpoint[e.Endpoint])
+	}
+
+	if len(s.ByDepth) > 0 {
+		out.Table("By Depth", "Depth", "Reorgs")
+		depths := make([]int, 0, len(s.ByDepth))
+		for d := range s.ByDepth {
+			depths = append(depths, d)
+		}
+		sort.Ints(depths)
+		for _, d := range depths {
+			out.Row(d, s.ByDepth[d])
+		}
+	}
+	if len(s.Recent) > 0 {
+		out.Table("Recent", "Detected At", "Endpoint", "Depth", "Ancestor")
+		for _, r := range s.Recent {
+			out.Row(r.DetectedAt.Format(time.RFC3339), r.Endpoint, r.Depth, r.Ancestor)
+		}
+	}
+}
+
+// statsHandler GET /stats?since=24h，返回 JSON 统计
+func statsHandler(st *store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		window := 30 * 24 * time.Hour
+		if v := r.URL.Query().Get("since"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				http.Error(w, "invalid since, expected a duration such as 24h", http.StatusBadRequest)
+				return
+			}
+			window = d
+		}
+		s, err := buildSummary(st, time.Now().Add(-window), 20)
+		if err != nil {
+			log.Printf("[WARN] %v", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s)
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// txTypes 可选的交易类型，名称 -> 类型字节
+var txTypes = map[string]uint8{
+	"legacy":     types.LegacyTxType,
+	"accesslist": types.AccessListTxType,
+	"dynamic":    types.DynamicFeeTxType,
+	"blob":       types.BlobTxType,
+	"setcode":    types.SetCodeTxType,
+}
+
+// txTypeNames 类型字节 -> 名称（与 26-rlp-decoder 相同）
+var txTypeNames = map[uint8]string{
+	types.LegacyTxType:     "legacy",
+	types.AccessListTxType: "access list (EIP-2930)",
+	types.DynamicFeeTxType: "dynamic fee (EIP-1559)",
+	types.BlobTxType:       "blob (EIP-4844)",
+	types.SetCodeTxType:    "set code (EIP-7702)",
+}
+
+// fields 逐项收集到的交易字段
+type fields struct {
+	Type       uint8
+	ChainID    *big.Int
+	Nonce      uint64
+	To         *common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList types.AccessList
+	Auths      []types.SetCodeAuthorization
+	GasPrice   *big.Int // legacy / access list
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	BlobFeeCap *big.Int
+	BlobHashes []common.Hash
+	Gas        uint64
+}
+
+// collect 按交易类型逐项提问。顺序：先确定会影响 intrinsic gas 的内容（to / data / access list / 授权），
+// 最后问 gas limit，默认值就是计算出的 intrinsic gas
+func collect(p *prompter) (*fields, error) {
+	var (
+		f   fields
+		err error
+	)
+	typeName, err := ask(p, "type (legacy | accesslist | dynamic | blob | setcode)", "dynamic", parseChoice("legacy", "accesslist", "dynamic", "blob", "setcode"))
+	if err != nil {
+		return nil, err
+	}
+	f.Type = txTypes[typeName]
+
+	if f.ChainID, err = ask(p, "chain id", "", func(s string) (*big.Int, error) {
+		v, err := parseBig(s)
+		if err == nil && v.Sign() == 0 {
+			err = fmt.Errorf("chain id must be positive")
+		}
+		return v, err
+	}); err != nil {
+		return nil, err
+	}
+	if f.Nonce, err = ask(p, "nonce", "0", parseUint); err != nil {
+		return nil, err
+	}
+
+	// blob / set-code 交易不能用于创建合约
+	needTo := f.Type == types.BlobTxType || f.Type == types.SetCodeTxType
+	toDefault := "-"
+	if needTo {
+		toDefault = ""
+	}
+	if f.To, err = ask(p, "to (empty = contract creation)", toDefault, func(s string) (*common.Address, error) {
+		addr, err := parseOptionalAddress(s)
+		if err == nil && addr == nil && needTo {
+			err = fmt.Errorf("%s transactions cannot create contracts", typeName)
+		}
+		return addr, err
+	}); err != nil {
+		return nil, err
+	}
+	if f.Value, err = ask(p, "value (ether, or with unit: wei | gwei | ether)", "0", parseUnits("ether")); err != nil {
+		return nil, err
+	}
+	if f.Data, err = ask(p, "data (0x hex, - for none)", "-", parseHex); err != nil {
+		return nil, err
+	}
+
+	if f.Type != types.LegacyTxType {
+		if f.AccessList, err = collectAccessList(p); err != nil {
+			return nil, err
+		}
+	}
+	if f.Type == types.SetCodeTxType {
+		if f.Auths, err = collectAuths(p, f.ChainID); err != nil {
+			return nil, err
+		}
+	}
+	if f.Type == types.BlobTxType {
+		if err := collectBlob(p, &f); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := collectFees(p, &f); err != nil {
+		return nil, err
+	}
+
+	intrinsic, err := intrinsicGas(&f)
+	if err != nil {
+		return nil, err
+	}
+	if f.Gas, err = ask(p, "gas limit", fmt.Sprint(intrinsic), func(s string) (uint64, error) {
+		v, err := parseUint(s)
+		if err == nil && v < intrinsic {
+			err = fmt.Errorf("below intrinsic gas %d, the transaction would be rejected", intrinsic)
+		}
+		return v, err
+	}); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// collectAccessList 每行一项："address [slot1,slot2,...]"，空行结束
+func collectAccessList(p *prompter) (types.AccessList, error) {
+	var al types.AccessList
+	for {
+		tuple, err := ask(p, fmt.Sprintf("access list #%d (address [slot,...], empty to finish)", len(al)), "-", func(s string) (*types.AccessTuple, error) {
+			if s == "-" {
+				return nil, nil
+			}
+			addrStr, slotsStr, _ := strings.Cut(s, " ")
+			addr, err := parseAddress(addrStr)
+			if err != nil {
+				return nil, err
+			}
+			t := &types.AccessTuple{Address: addr, StorageKeys: []common.Hash{}}
+			for _, k := range strings.Split(slotsStr, ",") {
+				if k = strings.TrimSpace(k); k == "" {
+					continue
+				}
+				// 槽号可以写十进制或十六进制，编码为 32 字节
+				n, err := parseBig(k)
+				if err != nil || n.BitLen() > 256 {
+					return nil, fmt.Errorf("invalid storage slot %q", k)
+				}
+				t.StorageKeys = append(t.StorageKeys, common.BigToHash(n))
+			}
+			return t, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if tuple == nil {
+			return al, nil
+		}
+		al = append(al, *tuple)
+	}
+}
+
+// collectAuths EIP-7702 授权："address nonce"，由授权账户的私钥签名；空行结束，至少一项
+func collectAuths(p *prompter, chainID *big.Int) ([]types.SetCodeAuthorization, error) {
+	var auths []types.SetCodeAuthorization
+	for {
+		def := "-"
+		if len(auths) == 0 {
+			def = ""
+		}
+		auth, err := ask(p, fmt.Sprintf("authorization #%d (delegate-address authority-nonce [chain-id|0], empty to finish)", len(auths)), def, func(s string) (*types.SetCodeAuthorization, error) {
+			if s == "-" {
+				return nil, nil
+			}
+			parts := strings.Fields(s)
+			if len(parts) < 2 || len(parts) > 3 {
+				return nil, fmt.Errorf("expected: address nonce [chain-id]")
+			}
+			addr, err := parseAddress(parts[0])
+			if err != nil {
+				return nil, err
+			}
+			nonce, err := parseUint(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			// chain id 为 0 的授权在所有链上都有效，默认绑定当前链
+			authChain := chainID
+			if len(parts) == 3 {
+				if authChain, err = parseBig(parts[2]); err != nil {
+					return nil, err
+				}
+			}
+			a := &types.SetCodeAuthorization{Address: addr, Nonce: nonce}
+			a.ChainID.SetFromBig(authChain)
+			return a, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if auth == nil {
+			return auths, nil
+		}
+		key, err := ask(p, "  authority private key (hex)", "", parseKey)
+		if err != nil {
+			return nil, err
+		}
+		signed, err := types.SignSetCode(key, *auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign authorization: %w", err)
+		}
+		fmt.Fprintf(p.out, "  authority %s, sig hash %s\n", crypto.PubkeyToAddress(key.PublicKey).Hex(), auth.SigHash().Hex())
+		auths = append(auths, signed)
+	}
+}
+
+// collectBlob blob 费用上限和 versioned hash（只构建不带 sidecar 的规范形式，广播时需要另外附上 blob）
+func collectBlob(p *prompter, f *fields) error {
+	var err error
+	if f.BlobFeeCap, err = ask(p, "max fee per blob gas (gwei, or with unit)", "", parseUnits("gwei")); err != nil {
+		return err
+	}
+	for {
+		def := "-"
+		if len(f.BlobHashes) == 0 {
+			def = ""
+		}
+		h, err := ask(p, fmt.Sprintf("blob versioned hash #%d (empty to finish)", len(f.BlobHashes)), def, func(s string) (*common.Hash, error) {
+			if s == "-" {
+				return nil, nil
+			}
+			h, err := parseHash(s)
+			if err != nil {
+				return nil, err
+			}
+			// versioned hash 的第一个字节是版本号，KZG 承诺为 0x01
+			if h[0] != 0x01 {
+				return nil, fmt.Errorf("versioned hash must start with 0x01 (KZG)")
+			}
+			return &h, nil
+		})
+		if err != nil {
+			return err
+		}
+		if h == nil {
+			return nil
+		}
+		f.BlobHashes = append(f.BlobHashes, *h)
+	}
+}
+
+// collectFees legacy / access list 交易只有 gasPrice；其余类型为小费和费用上限，要求 maxFee >= tip
+func collectFees(p *prompter, f *fields) error {
+	var err error
+	if f.Type == types.LegacyTxType || f.Type == types.AccessListTxType {
+		f.GasPrice, err = ask(p, "gas price (gwei, or with unit)", "", parseUnits("gwei"))
+		return err
+	}
+	if f.GasTipCap, err = ask(p, "max priority fee (gwei, or with unit)", "", parseUnits("gwei")); err != nil {
+		return err
+	}
+	f.GasFeeCap, err = ask(p, "max fee (gwei, or with unit)", "", func(s string) (*big.Int, error) {
+		v, err := parseUnits("gwei")(s)
+		if err == nil && v.Cmp(f.GasTipCap) < 0 {
+			err = fmt.Errorf("max fee must not be lower than max priority fee")
+		}
+		return v, err
+	})
+	return err
+}
+
+// intrinsicGas 按当前（Prague）规则计算最低 gas：基础 21000 + calldata + access list + 授权，
+// 并且不低于 EIP-7623 的 calldata 下限
+func intrinsicGas(f *fields) (uint64, error) {
+	gas, err := core.IntrinsicGas(f.Data, f.AccessList, f.Auths, f.To == nil, true, true, true)
+	if err != nil {
+		return 0, err
+	}
+	floor, err := core.FloorDataGas(f.Data)
+	if err != nil {
+		return 0, err
+	}
+	return max(gas, floor), nil
+}
+
+// toTx 组装未签名交易
+func (f *fields) toTx() *types.Transaction {
+	switch f.Type {
+	case types.LegacyTxType:
+		return types.NewTx(&types.LegacyTx{Nonce: f.Nonce, GasPrice: f.GasPrice, Gas: f.Gas, To: f.To, Value: f.Value, Data: f.Data})
+	case types.AccessListTxType:
+		return types.NewTx(&types.AccessListTx{ChainID: f.ChainID, Nonce: f.Nonce, GasPrice: f.GasPrice, Gas: f.Gas, To: f.To, Value: f.Value, Data: f.Data, AccessList: f.AccessList})
+	case types.DynamicFeeTxType:
+		return types.NewTx(&types.DynamicFeeTx{ChainID: f.ChainID, Nonce: f.Nonce, GasTipCap: f.GasTipCap, GasFeeCap: f.GasFeeCap, Gas: f.Gas, To: f.To, Value: f.Value, Data: f.Data, AccessList: f.AccessList})
+	case types.BlobTxType:
+		return types.NewTx(&types.BlobTx{
+			ChainID: uint256.MustFromBig(f.ChainID), Nonce: f.Nonce,
+			GasTipCap: uint256.MustFromBig(f.GasTipCap), GasFeeCap: uint256.MustFromBig(f.GasFeeCap),
+			Gas: f.Gas, To: *f.To, Value: uint256.MustFromBig(f.Value), Data: f.Data, AccessList: f.AccessList,
+			BlobFeeCap: uint256.MustFromBig(f.BlobFeeCap), BlobHashes: f.BlobHashes,
+		})
+	default:
+		return types.NewTx(&types.SetCodeTx{
+			ChainID: uint256.MustFromBig(f.ChainID), Nonce: f.Nonce,
+			GasTipCap: uint256.MustFromBig(f.GasTipCap), GasFeeCap: uint256.MustFromBig(f.GasFeeCap),
+			Gas: f.Gas, To: *f.To, Value: uint256.MustFromBig(f.Value), Data: f.Data, AccessList: f.AccessList,
+			AuthList: f.Auths,
+		})
+	}
+}
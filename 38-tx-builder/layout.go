@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// fieldNames 各类型交易 RLP 列表中字段的顺序（与 go-ethereum core/types 中的结构体一致）
+var fieldNames = map[uint8][]string{
+	types.LegacyTxType:     {"nonce", "gasPrice", "gas", "to", "value", "data", "v", "r", "s"},
+	types.AccessListTxType: {"chainId", "nonce", "gasPrice", "gas", "to", "value", "data", "accessList", "yParity", "r", "s"},
+	types.DynamicFeeTxType: {"chainId", "nonce", "maxPriorityFeePerGas", "maxFeePerGas", "gas", "to", "value", "data", "accessList", "yParity", "r", "s"},
+	types.BlobTxType:       {"chainId", "nonce", "maxPriorityFeePerGas", "maxFeePerGas", "gas", "to", "value", "data", "accessList", "maxFeePerBlobGas", "blobVersionedHashes", "yParity", "r", "s"},
+	types.SetCodeTxType:    {"chainId", "nonce", "maxPriorityFeePerGas", "maxFeePerGas", "gas", "to", "value", "data", "accessList", "authorizationList", "yParity", "r", "s"},
+}
+
+// printLayout 逐字段输出交易规范编码的字节结构：
+// typed 交易为 类型字节 || RLP 列表，legacy 交易直接是 RLP 列表
+func printLayout(out *output.Printer, txType uint8, raw []byte) error {
+	out.Table("Byte Layout", "Offset", "Field", "Bytes", "Description")
+	body := raw
+	offset := 0
+	if txType != types.LegacyTxType {
+		out.Row(0, "type", hexutil.Encode(raw[:1]), "")
+		body = raw[1:]
+		offset = 1
+	}
+
+	content, _, err := rlp.SplitList(body)
+	if err != nil {
+		return fmt.Errorf("invalid RLP list: %w", err)
+	}
+	headerLen := len(body) - len(content)
+	out.Row(offset, "list header", hexutil.Encode(body[:headerLen]), fmt.Sprintf("list, %d bytes payload", len(content)))
+	offset += headerLen
+
+	names := fieldNames[txType]
+	for i := 0; len(content) > 0; i++ {
+		kind, val, rest, err := rlp.Split(content)
+		if err != nil {
+			return fmt.Errorf("invalid RLP element %d: %w", i, err)
+		}
+		size := len(content) - len(rest)
+		name := fmt.Sprintf("field %d", i)
+		if i < len(names) {
+			name = names[i]
+		}
+		desc := fmt.Sprintf("%d bytes", len(val))
+		if kind == rlp.List {
+			desc = fmt.Sprintf("list, %d bytes", len(val))
+		}
+		out.Row(offset, name, shortHex(content[:size]), desc)
+		offset += size
+		content = rest
+	}
+	return nil
+}
+
+// shortHex 过长的十六进制只保留首尾
+func shortHex(b []byte) string {
+	if len(b) <= 40 {
+		return hexutil.Encode(b)
+	}
+	return fmt.Sprintf("%s...%x", hexutil.Encode(b[:16]), b[len(b)-8:])
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// 38-tx-builder
+// 离线交易构建器：逐步提问构建交易（选择类型 → 填写字段 → 计算签名哈希 → 签名 → 输出原始交易），全程不连接节点。
+// 适合冷钱包 / 隔离网络签名流程，也适合学习每种交易类型的字节结构：
+// - 支持 legacy、access list（EIP-2930）、dynamic fee（EIP-1559）、blob（EIP-4844）、set code（EIP-7702）
+// - 每个字段即时校验：地址校验和、整数范围、maxFee >= tip、gas limit 不低于 intrinsic gas（含 EIP-7623 calldata 下限）等
+// - 金额可带单位："0.1 ether"、"2 gwei"、"21000 wei"
+// - 签名方式：私钥（输入或 SENDER_PRIVATE_KEY）、粘贴在其他设备上对签名哈希做出的 65 字节签名，或不签名只输出签名哈希
+// - 输出原始交易、交易哈希、发送者，以及逐字段的字节布局（偏移、RLP 编码、长度）
+//
+// 执行示例：
+//    go run .
+//    go run . --script answers.txt     # 每行一个回答，空行表示使用默认值
+//    SENDER_PRIVATE_KEY=0x... go run .
+//    go run . --script answers.txt --format json | jq -r '.Signed.Raw'   # 提示写到 stderr
+//
+// 注意事项：
+// - 离线构建无法获知 nonce 和当前费用，请在联网设备上查询后填写；填错的 nonce 会导致交易卡住（见 33-nonce-doctor）
+// - blob 交易只构建不带 sidecar 的规范形式，广播时需要附上 blob、承诺和证明
+// - 输出的原始交易可以用 26-rlp-decoder 再次解码核对，或通过 eth_sendRawTransaction 广播
+
+func main() {
+	scriptPath := flag.String("script", "", "read answers from this file, one per line (non-interactive)")
+	out := output.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	// 结构化格式下提问写到 stderr，标准输出中只有结果
+	promptOut := os.Stdout
+	if !out.Pretty() {
+		promptOut = os.Stderr
+	}
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: promptOut}
+	if *scriptPath != "" {
+		f, err := os.Open(*scriptPath)
+		if err != nil {
+			log.Fatalf("failed to open script: %v", err)
+		}
+		defer f.Close()
+		p = &prompter{in: bufio.NewReader(f), out: promptOut, script: true}
+	}
+
+	out.Note("=== Build Transaction ===")
+	f, err := collect(p)
+	if err != nil {
+		log.Fatalf("failed to build transaction: %v", err)
+	}
+	tx := f.toTx()
+	signer := types.LatestSignerForChainID(f.ChainID)
+	sigHash := signer.Hash(tx)
+
+	out.Section("Unsigned")
+	out.Field("Type", fmt.Sprintf("%d (%s)", tx.Type(), txTypeNames[tx.Type()]))
+	out.Field("Signing Hash", sigHash)
+	// gas * fee cap + value
+	out.Field("Max Cost", tx.Cost())
+
+	signed, err := sign(p, tx, signer)
+	if err != nil {
+		log.Fatalf("failed to sign: %v", err)
+	}
+	if signed == nil {
+		out.Note("\nnot signed: sign the signing hash on another device and run again with the signature")
+		if err := out.Flush(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		log.Fatalf("failed to encode transaction: %v", err)
+	}
+	sender, err := types.Sender(signer, signed)
+	if err != nil {
+		log.Fatalf("failed to recover sender: %v", err)
+	}
+
+	out.Section("Signed")
+	out.Field("Sender", sender)
+	out.Field("Tx Hash", signed.Hash())
+	out.Field("Size", len(raw))
+	out.Field("Raw", hexutil.Encode(raw))
+	if err := printLayout(out, signed.Type(), raw); err != nil {
+		log.Fatalf("failed to print layout: %v", err)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// sign 选择签名方式并签名；选择 none 时返回 nil
+func sign(p *prompter, tx *types.Transaction, signer types.Signer) (*types.Transaction, error) {
+	mode, err := ask(p, "sign with (key | signature | none)", "key", parseChoice("key", "signature", "none"))
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case "key":
+		var key *ecdsa.PrivateKey
+		if env := config.Get().PrivateKey; env != "" {
+			if key, err = parseKey(env); err != nil {
+				return nil, errors.New("SENDER_PRIVATE_KEY is invalid")
+			}
+			fmt.Fprintf(p.out, "using SENDER_PRIVATE_KEY (%s)\n", crypto.PubkeyToAddress(key.PublicKey).Hex())
+		} else if key, err = ask(p, "private key (hex)", "", parseKey); err != nil {
+			return nil, err
+		}
+		return types.SignTx(tx, signer, key)
+	case "signature":
+		// 外部签名为 [R || S || V]，V 可以是 0 / 1 或 27 / 28
+		sig, err := ask(p, "signature (65 bytes hex)", "", func(s string) ([]byte, error) {
+			b, err := hexutil.Decode(s)
+			if err != nil || len(b) != crypto.SignatureLength {
+				return nil, fmt.Errorf("expected %d bytes of hex", crypto.SignatureLength)
+			}
+			if b[64] >= 27 {
+				b[64] -= 27
+			}
+			if b[64] > 1 {
+				return nil, fmt.Errorf("invalid recovery id %d", b[64])
+			}
+			return b, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return tx.WithSignature(signer, sig)
+	default:
+		return nil, nil
+	}
+}
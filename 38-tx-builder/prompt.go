@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// prompter 逐项提问并校验输入：交互模式下输入无效时重新提问；
+// 脚本模式（--script）下每行对应一个回答，输入无效直接报错退出
+type prompter struct {
+	in     *bufio.Reader
+	out    io.Writer
+	script bool
+}
+
+// errEOF 输入提前结束
+var errEOF = fmt.Errorf("unexpected end of input")
+
+// ask 提问并用 parse 校验答案；答案为空时使用 def（def 为空表示必填）
+func ask[T any](p *prompter, label, def string, parse func(string) (T, error)) (T, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", label)
+		}
+		line, err := p.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			var zero T
+			return zero, errEOF
+		}
+		answer := strings.TrimSpace(line)
+		if p.script {
+			fmt.Fprintln(p.out, answer) // 回显脚本中的答案，便于对照输出
+		}
+		if answer == "" {
+			answer = def
+		}
+		v, perr := parse(answer)
+		if perr == nil {
+			return v, nil
+		}
+		if p.script {
+			var zero T
+			return zero, fmt.Errorf("%s: %w", label, perr)
+		}
+		fmt.Fprintf(p.out, "  invalid: %v\n", perr)
+	}
+}
+
+// parseUint 十进制或 0x 十六进制的非负整数
+func parseUint(s string) (uint64, error) {
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok || v.Sign() < 0 || !v.IsUint64() {
+		return 0, fmt.Errorf("expected a non-negative integer, got %q", s)
+	}
+	return v.Uint64(), nil
+}
+
+// parseBig 大整数（wei 等），不允许负数和超过 256 位
+func parseBig(s string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok || v.Sign() < 0 || v.BitLen() > 256 {
+		return nil, fmt.Errorf("expected a non-negative 256-bit integer, got %q", s)
+	}
+	return v, nil
+}
+
+// parseUnits 带单位的数量："1.5"（默认单位）、"2 gwei"、"0.1 ether"、"21000 wei"
+func parseUnits(defaultUnit string) func(string) (*big.Int, error) {
+	return func(s string) (*big.Int, error) {
+		num, unit, _ := strings.Cut(strings.TrimSpace(s), " ")
+		unit = strings.ToLower(strings.TrimSpace(unit))
+		if unit == "" {
+			unit = defaultUnit
+		}
+		var decimals int
+		switch unit {
+		case "wei":
+			decimals = 0
+		case "gwei":
+			decimals = 9
+		case "eth", "ether":
+			decimals = 18
+		default:
+			return nil, fmt.Errorf("unknown unit %q (wei | gwei | ether)", unit)
+		}
+		whole, frac, _ := strings.Cut(num, ".")
+		if len(frac) > decimals {
+			return nil, fmt.Errorf("%q has more than %d decimals for unit %s", num, decimals, unit)
+		}
+		v, ok := new(big.Int).SetString(whole+frac+strings.Repeat("0", decimals-len(frac)), 10)
+		if !ok || v.Sign() < 0 || v.BitLen() > 256 {
+			return nil, fmt.Errorf("invalid amount %q", num)
+		}
+		return v, nil
+	}
+}
+
+// parseAddress 校验地址；混合大小写时必须通过 EIP-55 校验和
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %q", s)
+	}
+	addr := common.HexToAddress(s)
+	body := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if body != strings.ToLower(body) && body != strings.ToUpper(body) && addr.Hex() != "0x"+body {
+		return common.Address{}, fmt.Errorf("checksum mismatch, expected %s", addr.Hex())
+	}
+	return addr, nil
+}
+
+// parseOptionalAddress 空值表示合约创建
+func parseOptionalAddress(s string) (*common.Address, error) {
+	if s == "" || s == "-" {
+		return nil, nil
+	}
+	addr, err := parseAddress(s)
+	if err != nil {
+		return nil, err
+	}
+	return &addr, nil
+}
+
+// parseHex 0x 十六进制字节；"-" 或空表示空
+func parseHex(s string) ([]byte, error) {
+	if s == "" || s == "-" || s == "0x" {
+		return nil, nil
+	}
+	return hexutil.Decode(s)
+}
+
+// parseHash 32 字节哈希
+func parseHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("expected 32 bytes of hex, got %q", s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+// parseKey 十六进制私钥
+func parseKey(s string) (*ecdsa.PrivateKey, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+	return key, nil
+}
+
+// parseChoice 从固定选项中选择
+func parseChoice(choices ...string) func(string) (string, error) {
+	return func(s string) (string, error) {
+		s = strings.ToLower(s)
+		for _, c := range choices {
+			if s == c {
+				return s, nil
+			}
+		}
+		return "", fmt.Errorf("choose one of %s", strings.Join(choices, " | "))
+	}
+}
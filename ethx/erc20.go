@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/24-gas-tracker/gasoracle"
+	"github.com/yzucdh1/examples/internal/cli"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// erc20ABI 用到的 ERC-20 方法（与 08-contract-interact 相同）
+var erc20ABI = mustParseABI(`[
+  {"constant": true, "inputs": [{"name": "owner", "type": "address"}], "name": "balanceOf", "outputs": [{"name": "", "type": "uint256"}], "type": "function"},
+  {"constant": true, "inputs": [], "name": "decimals", "outputs": [{"name": "", "type": "uint8"}], "type": "function"},
+  {"constant": true, "inputs": [], "name": "symbol", "outputs": [{"name": "", "type": "string"}], "type": "function"},
+  {"constant": false, "inputs": [{"name": "to", "type": "address"}, {"name": "value", "type": "uint256"}], "name": "transfer", "outputs": [{"name": "", "type": "bool"}], "type": "function"}
+]`)
+
+func mustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// cmdERC20Balance 查询代币余额（08-contract-interact balance 模式）
+func cmdERC20Balance(args []string) error {
+	fs := flag.NewFlagSet("ethx erc20 balance", flag.ExitOnError)
+	node := cli.AddRPCFlags(fs, 20*time.Second)
+	out := output.AddFlags(fs)
+	tokenHex := fs.String("token", "", "ERC-20 contract address (required)")
+	addrHex := fs.String("address", "", "account address (required)")
+	fs.Parse(args)
+
+	if !common.IsHexAddress(*tokenHex) || !common.IsHexAddress(*addrHex) {
+		return errors.New("--token and --address must be valid addresses")
+	}
+	token, account := common.HexToAddress(*tokenHex), common.HexToAddress(*addrHex)
+
+	ctx, cancel := node.Context()
+	defer cancel()
+	client, err := node.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	decimals, err := callERC20[uint8](ctx, client, token, "decimals")
+	if err != nil {
+		return err
+	}
+	balance, err := callERC20[*big.Int](ctx, client, token, "balanceOf", account)
+	if err != nil {
+		return err
+	}
+	// symbol 不是 ERC-20 的必选方法，查询失败时留空
+	symbol, _ := callERC20[string](ctx, client, token, "symbol")
+
+	out.Section("Token Balance")
+	out.Field("Token", token)
+	out.Field("Symbol", symbol)
+	out.Field("Address", account)
+	out.Field("Decimals", decimals)
+	out.Field("Raw", balance)
+	out.Field("Balance", ethutil.FormatUnits(balance, decimals))
+	return out.Flush()
+}
+
+// cmdERC20Transfer 发送代币转账（08-contract-interact transfer 模式）
+func cmdERC20Transfer(args []string) error {
+	fs := flag.NewFlagSet("ethx erc20 transfer", flag.ExitOnError)
+	node := cli.AddRPCFlags(fs, 3*time.Minute)
+	node.AddSignerFlag()
+	out := output.AddFlags(fs)
+	tokenHex := fs.String("token", "", "ERC-20 contract address (required)")
+	toHex := fs.String("to", "", "recipient address (required)")
+	amountStr := fs.String("amount", "", "token amount like 1.5, or raw units like 1500000 (required)")
+	speedFlag := fs.String("speed", "standard", "fee level: slow | standard | fast")
+	wait := fs.Bool("wait", false, "wait for the receipt")
+	confirmations := fs.Uint64("confirmations", 1, "confirmations to wait for with --wait")
+	fs.Parse(args)
+
+	if !common.IsHexAddress(*tokenHex) || !common.IsHexAddress(*toHex) {
+		return errors.New("--token and --to must be valid addresses")
+	}
+	if *amountStr == "" {
+		return errors.New("missing --amount")
+	}
+	speed, err := gasoracle.ParseSpeed(*speedFlag)
+	if err != nil {
+		return err
+	}
+	token, to := common.HexToAddress(*tokenHex), common.HexToAddress(*toHex)
+
+	ctx, cancel := node.Context()
+	defer cancel()
+	client, err := node.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	sgn, err := node.Signer(ctx)
+	if err != nil {
+		return err
+	}
+	from := sgn.Address()
+
+	decimals, err := callERC20[uint8](ctx, client, token, "decimals")
+	if err != nil {
+		return err
+	}
+	amount, err := ethutil.ParseTokenAmount(*amountStr, decimals)
+	if err != nil {
+		return fmt.Errorf("invalid --amount: %w", err)
+	}
+	data, err := erc20ABI.Pack("transfer", to, amount)
+	if err != nil {
+		return fmt.Errorf("failed to pack transfer data: %w", err)
+	}
+	tx, err := sendDynamicTx(ctx, client, sgn, token, new(big.Int), data, speed)
+	if err != nil {
+		return err
+	}
+
+	out.Section("Token Transfer Sent")
+	out.Field("From", from)
+	out.Field("To", to)
+	out.Field("Token", token)
+	out.Field("Amount", ethutil.FormatUnits(amount, decimals))
+	out.Field("Raw", amount)
+	printSent(out, tx)
+	if *wait {
+		if err := waitReceipt(ctx, client, out, tx, *confirmations); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// callERC20 只读调用 ERC-20 方法并解码唯一的返回值
+func callERC20[T any](ctx context.Context, client *ethclient.Client, token common.Address, method string, args ...any) (T, error) {
+	var zero T
+	data, err := erc20ABI.Pack(method, args...)
+	if err != nil {
+		return zero, fmt.Errorf("failed to pack %s: %w", method, err)
+	}
+	output, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return zero, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	values, err := erc20ABI.Unpack(method, output)
+	if err != nil || len(values) != 1 {
+		return zero, fmt.Errorf("failed to unpack %s output (is %s an ERC-20 contract?)", method, token.Hex())
+	}
+	v, ok := values[0].(T)
+	if !ok {
+		return zero, fmt.Errorf("unexpected %s output type %T", method, values[0])
+	}
+	return v, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/yzucdh1/examples/24-gas-tracker/gasoracle"
+	"github.com/yzucdh1/examples/internal/cli"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// cmdGas 按 feeHistory 给出三档费用建议（24-gas-tracker 的单次查询模式）
+func cmdGas(args []string) error {
+	fs := flag.NewFlagSet("ethx gas", flag.ExitOnError)
+	node := cli.AddRPCFlags(fs, 15*time.Second)
+	out := output.AddFlags(fs)
+	blocks := fs.Uint64("blocks", gasoracle.DefaultConfig().Blocks, "number of recent blocks to sample")
+	fs.Parse(args)
+
+	ctx, cancel := node.Context()
+	defer cancel()
+	client, err := node.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	cfg := gasoracle.DefaultConfig()
+	cfg.Blocks = *blocks
+	est, err := gasoracle.Compute(ctx, client, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to estimate fees: %w", err)
+	}
+
+	out.Section("Gas Estimate")
+	out.Field("Block", est.BlockNumber)
+	out.Field("Base Fee", ethutil.FormatGwei(est.BaseFee))
+	out.Field("Next Base", ethutil.FormatGwei(est.NextBaseFee))
+	out.Field("Trend", est.Trend)
+	if est.Legacy {
+		out.Note("chain has no base fee, using gas price")
+	}
+	out.Table("Recommendations", "Speed", "Tip Cap", "Fee Cap")
+	for _, r := range est.Fees {
+		out.Row(r.Speed, ethutil.FormatGwei(r.TipCap), ethutil.FormatGwei(r.FeeCap))
+	}
+	return out.Flush()
+}
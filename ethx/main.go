@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/yzucdh1/examples/internal/cli"
+	_ "github.com/yzucdh1/examples/internal/signer/kms" // --signer kms: / gcp-kms: / vault:
+)
+
+// ethx
+// 把常用示例合并为一个二进制的子命令，统一参数与输出（各示例目录中的独立 main 保持不变）：
+// - 所有访问节点的命令都支持 --profile、--rpc 和 --timeout；节点、超时和私钥按
+//   命令行参数 > 环境变量 > ~/.config/eth-examples.yaml 的顺序取值（格式见 internal/config）
+// - 所有命令支持 --format pretty|json|yaml|csv（--json 为 --format json 的简写），结构化格式输出
+//   {"Section": {"Label": value}}，便于脚本处理（见 internal/output）
+// - 发送交易的命令通过 internal/signer 签名（SENDER_PRIVATE_KEY、keystore、助记词或远程签名服务），
+//   也可以用 --signer 选择云 KMS / Vault（kms:、gcp-kms:、vault:，见 internal/signer/kms）；
+//   费用按 --speed 由 24-gas-tracker/gasoracle 给出
+// - 命令与示例的对应关系：connect（01）、block（02）、tx（03）、balance（04）、erc20（08）、gas（24）
+//
+// 执行示例：
+//    go build -o ethx .
+//    ./ethx connect
+//    ./ethx connect --profile sepolia
+//    ./ethx block get --number 19000000
+//    ./ethx block range --from 100 --to 105 --rate-limit 500ms
+//    ./ethx tx get --hash 0x... --json
+//    ./ethx gas --format csv
+//    ./ethx tx send --to 0x... --amount 0.01 --speed fast
+//    ./ethx tx send --to 0x... --amount 0.01 --signer kms:alias/deployer
+//    ./ethx balance --address 0x... --block 19000000
+//    ./ethx erc20 balance --token 0x... --address 0x...
+//    ./ethx erc20 transfer --token 0x... --to 0x... --amount 1.5
+//    ./ethx gas
+//
+// 注意事项：
+// - 金额一律按十进制精确解析（不经过 float64）：tx send 的 --amount 单位为 ETH；
+//   erc20 transfer 的 --amount 带小数点时按代币数量换算，纯整数视为最小单位（与 08 相同）
+// - 仅在测试网或本地开发链上使用发送类命令，不要使用包含真实资产的私钥
+
+var commands = []*cli.Command{
+	{Name: "connect", Short: "chain id and latest / safe / finalized blocks", Run: cmdConnect},
+	{Name: "block", Short: "query blocks", Sub: []*cli.Command{
+		{Name: "get", Short: "show one block (latest by default)", Run: cmdBlockGet},
+		{Name: "range", Short: "show a range of blocks with rate limiting", Run: cmdBlockRange},
+	}},
+	{Name: "tx", Short: "query and send transactions", Sub: []*cli.Command{
+		{Name: "get", Short: "show a transaction and its receipt", Run: cmdTxGet},
+		{Name: "send", Short: "send ETH (EIP-1559)", Run: cmdTxSend},
+	}},
+	{Name: "balance", Short: "ETH balance of an account", Run: cmdBalance},
+	{Name: "erc20", Short: "ERC-20 token operations", Sub: []*cli.Command{
+		{Name: "balance", Short: "token balance of an account", Run: cmdERC20Balance},
+		{Name: "transfer", Short: "send a token transfer", Run: cmdERC20Transfer},
+	}},
+	{Name: "gas", Short: "fee recommendations from recent blocks", Run: cmdGas},
+}
+
+func main() {
+	if err := cli.Dispatch("ethx", commands, os.Args[1:]); err != nil {
+		if errors.Is(err, cli.ErrUsage) {
+			os.Exit(2)
+		}
+		log.Fatal(err)
+	}
+}
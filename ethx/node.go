@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/yzucdh1/examples/internal/cli"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+)
+
+// cmdConnect 链 ID 与 latest / safe / finalized 区块（01-connect-node）
+func cmdConnect(args []string) error {
+	fs := flag.NewFlagSet("ethx connect", flag.ExitOnError)
+	node := cli.AddRPCFlags(fs, 10*time.Second)
+	out := output.AddFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := node.Context()
+	defer cancel()
+	client, err := node.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %w", err)
+	}
+	latest, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get latest block header: %w", err)
+	}
+
+	out.Section("Ethereum Node Info")
+	out.Field("Chain ID", chainID)
+	out.Field("Latest", latest.Number)
+	out.Field("Hash", latest.Hash())
+	out.Field("Time", time.Unix(int64(latest.Time), 0).Format(time.RFC3339))
+
+	// safe / finalized 需要共识层支持，开发链上可能不存在
+	for _, tag := range []struct {
+		title  string
+		number rpc.BlockNumber
+	}{
+		{"Safe Block", rpc.SafeBlockNumber},
+		{"Finalized Block", rpc.FinalizedBlockNumber},
+	} {
+		h, err := client.HeaderByNumber(ctx, big.NewInt(tag.number.Int64()))
+		if err != nil {
+			out.Note("\n%s not available: %v", tag.title, err)
+			continue
+		}
+		out.Section(tag.title)
+		out.Field("Number", h.Number)
+		out.Field("Hash", h.Hash())
+		out.Field("Confirmed", new(big.Int).Sub(latest.Number, h.Number))
+	}
+	return out.Flush()
+}
+
+// cmdBalance 账户 ETH 余额（04-account-balance）
+func cmdBalance(args []string) error {
+	fs := flag.NewFlagSet("ethx balance", flag.ExitOnError)
+	node := cli.AddRPCFlags(fs, 15*time.Second)
+	out := output.AddFlags(fs)
+	addrHex := fs.String("address", "", "account address (required)")
+	blockNumber := fs.Int64("block", -1, "block number to query (-1 means latest)")
+	fs.Parse(args)
+
+	if !common.IsHexAddress(*addrHex) {
+		return fmt.Errorf("invalid or missing --address %q", *addrHex)
+	}
+	address := common.HexToAddress(*addrHex)
+	var blockNum *big.Int
+	if *blockNumber >= 0 {
+		blockNum = big.NewInt(*blockNumber)
+	}
+
+	ctx, cancel := node.Context()
+	defer cancel()
+	client, err := node.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	balance, err := client.BalanceAt(ctx, address, blockNum)
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	out.Section("Account Balance")
+	out.Field("Address", address)
+	if blockNum == nil {
+		out.Field("Block", "latest")
+	} else {
+		out.Field("Block", blockNum)
+	}
+	out.Field("Balance Wei", balance)
+	out.Field("Balance ETH", ethutil.FormatEth(balance, 6))
+	return out.Flush()
+}
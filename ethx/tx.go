@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/24-gas-tracker/gasoracle"
+	"github.com/yzucdh1/examples/internal/cli"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/output"
+	"github.com/yzucdh1/examples/internal/signer"
+	"github.com/yzucdh1/examples/internal/txbuilder"
+	"github.com/yzucdh1/examples/internal/txwait"
+)
+
+// cmdTxGet 查询交易与回执（03-tx-ops）
+func cmdTxGet(args []string) error {
+	fs := flag.NewFlagSet("ethx tx get", flag.ExitOnError)
+	node := cli.AddRPCFlags(fs, 20*time.Second)
+	out := output.AddFlags(fs)
+	hashHex := fs.String("hash", "", "transaction hash (required)")
+	fs.Parse(args)
+
+	if *hashHex == "" {
+		return errors.New("missing --hash")
+	}
+	hash := common.HexToHash(*hashHex)
+
+	ctx, cancel := node.Context()
+	defer cancel()
+	client, err := node.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	tx, pending, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+	out.Section("Transaction")
+	out.Field("Hash", tx.Hash())
+	out.Field("Type", tx.Type())
+	out.Field("Nonce", tx.Nonce())
+	if to := tx.To(); to != nil {
+		out.Field("To", *to)
+	} else {
+		out.Field("To", "contract creation")
+	}
+	out.Field("Value", tx.Value())
+	out.Field("Gas", tx.Gas())
+	out.Field("Gas Price", tx.GasPrice())
+	out.Field("Data Len", len(tx.Data()))
+	out.Field("Pending", pending)
+
+	if !pending {
+		receipt, err := client.TransactionReceipt(ctx, hash)
+		if err != nil {
+			return fmt.Errorf("failed to get receipt: %w", err)
+		}
+		printReceipt(out, receipt)
+	}
+	return out.Flush()
+}
+
+// cmdTxSend 发送 ETH 转账（03-tx-ops）
+func cmdTxSend(args []string) error {
+	fs := flag.NewFlagSet("ethx tx send", flag.ExitOnError)
+	node := cli.AddRPCFlags(fs, 3*time.Minute)
+	node.AddSignerFlag()
+	out := output.AddFlags(fs)
+	toHex := fs.String("to", "", "recipient address (required)")
+	amount := fs.String("amount", "", "amount in ETH, e.g. 0.01 (required)")
+	speedFlag := fs.String("speed", "standard", "fee level: slow | standard | fast")
+	wait := fs.Bool("wait", false, "wait for the receipt")
+	confirmations := fs.Uint64("confirmations", 1, "confirmations to wait for with --wait")
+	fs.Parse(args)
+
+	if !common.IsHexAddress(*toHex) {
+		return fmt.Errorf("invalid or missing --to %q", *toHex)
+	}
+	value, err := ethutil.ParseUnits(*amount, 18)
+	if err != nil {
+		return fmt.Errorf("invalid --amount: %w", err)
+	}
+	speed, err := gasoracle.ParseSpeed(*speedFlag)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := node.Context()
+	defer cancel()
+	client, err := node.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	sgn, err := node.Signer(ctx)
+	if err != nil {
+		return err
+	}
+	from := sgn.Address()
+
+	to := common.HexToAddress(*toHex)
+	tx, err := sendDynamicTx(ctx, client, sgn, to, value, nil, speed)
+	if err != nil {
+		return err
+	}
+	out.Section("Transaction Sent")
+	out.Field("From", from)
+	out.Field("To", to)
+	out.Field("Value ETH", ethutil.FormatEth(value, 6))
+	printSent(out, tx)
+	if *wait {
+		if err := waitReceipt(ctx, client, out, tx, *confirmations); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// sendDynamicTx 通过 internal/txbuilder 构造、签名并发送 EIP-1559 交易：费用按档位取自 gasoracle，
+// 无 data 的转账 gas 固定为 21000，合约调用为估算值加 20% 余量，发送前检查 ETH 余额
+func sendDynamicTx(ctx context.Context, client *ethclient.Client, sgn signer.Signer, to common.Address, value *big.Int, data []byte, speed gasoracle.Speed) (*types.Transaction, error) {
+	builder := txbuilder.New(client, sgn)
+	builder.Fees = gasoracle.FeeFunc(client, gasoracle.DefaultConfig(), speed)
+	return builder.Send(ctx, txbuilder.Intent{Type: txbuilder.DynamicFee, To: &to, Value: value, Data: data})
+}
+
+// printSent 已发送交易的公共字段
+func printSent(out *output.Printer, tx *types.Transaction) {
+	out.Field("Nonce", tx.Nonce())
+	out.Field("Gas Limit", tx.Gas())
+	out.Field("Tip Cap", ethutil.FormatGwei(tx.GasTipCap()))
+	out.Field("Fee Cap", ethutil.FormatGwei(tx.GasFeeCap()))
+	out.Field("Tx Hash", tx.Hash())
+}
+
+// waitReceipt 等待交易达到 confirmations 个确认并输出回执；回执所在区块被重组掉时继续等待
+func waitReceipt(ctx context.Context, client *ethclient.Client, out *output.Printer, tx *types.Transaction, confirmations uint64) error {
+	out.Note("waiting for receipt...")
+	cfg := txwait.DefaultConfig()
+	cfg.OnUpdate = func(u txwait.Update) {
+		if u.Reorged != nil {
+			out.Note("block %d was reorged out, waiting again...", u.Reorged.BlockNumber.Uint64())
+		}
+	}
+	receipt, err := txwait.Wait(ctx, client, tx.Hash(), confirmations, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to wait for receipt: %w", err)
+	}
+	printReceipt(out, receipt)
+	return nil
+}
+
+// printReceipt 输出回执的主要字段
+func printReceipt(out *output.Printer, r *types.Receipt) {
+	out.Section("Receipt")
+	out.Field("Status", r.Status)
+	out.Field("Block", r.BlockNumber)
+	out.Field("Block Hash", r.BlockHash)
+	out.Field("Tx Index", r.TransactionIndex)
+	out.Field("Gas Used", r.GasUsed)
+	out.Field("Logs", len(r.Logs))
+	if r.ContractAddress != (common.Address{}) {
+		out.Field("Contract", r.ContractAddress)
+	}
+}
@@ -0,0 +1,161 @@
+// Package abis 内置常用标准合约的 ABI（JSON 文件随包嵌入），取代各示例中各自手写、字段略有出入的 ABI 字符串：
+//   - ERC20、ERC721、ERC1155、WETH、Multicall3、ENSRegistry、ENSResolver、ChainlinkAggregator 可以直接用于 Pack / Unpack
+//   - MethodBySelector / EventForLog 按 4 字节选择器或日志主题在所有内置 ABI 中查找方法和事件，用于解码未知合约的调用和日志
+//
+// 同一签名可能出现在多个标准中（ERC-20 和 ERC-721 的 Transfer 主题相同，区别只在 indexed 参数个数），
+// 查找按上面的顺序返回第一个匹配项；EventForLog 会按日志的主题个数区分 ERC-20 和 ERC-721。
+// 合约特有的 ABI（多签、空投分发等）仍然放在各示例中。
+package abis
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+//go:embed *.json
+var files embed.FS
+
+// 内置 ABI
+var (
+	ERC20               = mustLoad("erc20.json")
+	ERC721              = mustLoad("erc721.json")
+	ERC1155             = mustLoad("erc1155.json")
+	WETH                = mustLoad("weth.json")
+	Multicall3          = mustLoad("multicall3.json")
+	ENSRegistry         = mustLoad("ens_registry.json")
+	ENSResolver         = mustLoad("ens_resolver.json")
+	ChainlinkAggregator = mustLoad("chainlink_aggregator.json")
+)
+
+// Multicall3Address Multicall3 在绝大多数 EVM 链上部署在同一地址（确定性部署）
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// Standard 一个内置 ABI 及其名称
+type Standard struct {
+	Name string
+	ABI  abi.ABI
+}
+
+// standards 查找顺序：更常见的标准在前
+var standards = []Standard{
+	{"ERC20", ERC20},
+	{"ERC721", ERC721},
+	{"ERC1155", ERC1155},
+	{"WETH", WETH},
+	{"Multicall3", Multicall3},
+	{"ENSRegistry", ENSRegistry},
+	{"ENSResolver", ENSResolver},
+	{"ChainlinkAggregator", ChainlinkAggregator},
+}
+
+// All 返回所有内置 ABI（按查找顺序）
+func All() []Standard {
+	return append([]Standard(nil), standards...)
+}
+
+// Method 查找到的方法及其所属标准
+type Method struct {
+	Standard string
+	abi.Method
+}
+
+// Event 查找到的事件及其所属标准
+type Event struct {
+	Standard string
+	abi.Event
+}
+
+// MethodBySelector 按调用数据的前 4 字节查找方法，data 不足 4 字节或没有匹配时 ok=false
+func MethodBySelector(data []byte) (m Method, ok bool) {
+	if len(data) < 4 {
+		return m, false
+	}
+	for _, s := range standards {
+		if method, err := s.ABI.MethodById(data[:4]); err == nil {
+			return Method{Standard: s.Name, Method: *method}, true
+		}
+	}
+	return m, false
+}
+
+// EventsByTopic 返回主题哈希（Topics[0]）对应的所有事件，同一签名在不同标准中的定义都会返回
+func EventsByTopic(topic common.Hash) []Event {
+	var out []Event
+	for _, s := range standards {
+		if event, err := s.ABI.EventByID(topic); err == nil {
+			out = append(out, Event{Standard: s.Name, Event: *event})
+		}
+	}
+	return out
+}
+
+// EventForLog 查找与日志匹配的事件：Topics[0] 相同且 indexed 参数个数等于 len(Topics)-1
+func EventForLog(l *types.Log) (e Event, ok bool) {
+	if len(l.Topics) == 0 {
+		return e, false
+	}
+	for _, candidate := range EventsByTopic(l.Topics[0]) {
+		if indexedCount(candidate.Inputs) == len(l.Topics)-1 {
+			return candidate, true
+		}
+	}
+	return e, false
+}
+
+// UnpackLog 解码日志的 indexed 参数（Topics）和普通参数（Data），按参数名返回
+func UnpackLog(l *types.Log) (Event, map[string]any, error) {
+	e, ok := EventForLog(l)
+	if !ok {
+		return e, nil, fmt.Errorf("unknown event %s with %d topics", topicOrEmpty(l), len(l.Topics))
+	}
+	values := make(map[string]any)
+	if len(l.Data) > 0 {
+		if err := e.Inputs.UnpackIntoMap(values, l.Data); err != nil {
+			return e, nil, fmt.Errorf("failed to unpack %s data: %w", e.Name, err)
+		}
+	}
+	var indexed abi.Arguments
+	for _, arg := range e.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, l.Topics[1:]); err != nil {
+		return e, nil, fmt.Errorf("failed to parse %s topics: %w", e.Name, err)
+	}
+	return e, values, nil
+}
+
+func indexedCount(args abi.Arguments) int {
+	n := 0
+	for _, arg := range args {
+		if arg.Indexed {
+			n++
+		}
+	}
+	return n
+}
+
+func topicOrEmpty(l *types.Log) string {
+	if len(l.Topics) == 0 {
+		return "(no topics)"
+	}
+	return l.Topics[0].Hex()
+}
+
+func mustLoad(name string) abi.ABI {
+	data, err := files.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(string(data)))
+	if err != nil {
+		panic(fmt.Sprintf("abis: %s: %v", name, err))
+	}
+	return parsed
+}
@@ -0,0 +1,69 @@
+package abis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestMethodBySelector(t *testing.T) {
+	to := common.HexToAddress("0x000000000000000000000000000000000000beef")
+	data, err := ERC20.Pack("transfer", to, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := MethodBySelector(data)
+	if !ok || m.Standard != "ERC20" || m.Name != "transfer" {
+		t.Fatalf("MethodBySelector(transfer) = %s.%s, %v", m.Standard, m.Name, ok)
+	}
+
+	data, _ = Multicall3.Pack("getEthBalance", to)
+	if m, ok := MethodBySelector(data); !ok || m.Standard != "Multicall3" {
+		t.Errorf("MethodBySelector(getEthBalance) = %s, %v", m.Standard, ok)
+	}
+	if _, ok := MethodBySelector([]byte{0xde, 0xad, 0xbe, 0xef}); ok {
+		t.Error("unknown selector matched")
+	}
+}
+
+func TestEventForLog(t *testing.T) {
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	topic := ERC20.Events["Transfer"].ID
+	if topic != ERC721.Events["Transfer"].ID {
+		t.Fatal("ERC-20 and ERC-721 Transfer topics differ")
+	}
+
+	// ERC-20：value 在 Data 中
+	value := common.BigToHash(big.NewInt(1000))
+	erc20Log := &types.Log{
+		Topics: []common.Hash{topic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:   value.Bytes(),
+	}
+	e, values, err := UnpackLog(erc20Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Standard != "ERC20" || values["from"] != from || values["value"].(*big.Int).Int64() != 1000 {
+		t.Errorf("ERC-20 Transfer = %s %v", e.Standard, values)
+	}
+
+	// ERC-721：tokenId 是第 4 个主题
+	erc721Log := &types.Log{Topics: append(erc20Log.Topics[:3:3], common.BigToHash(big.NewInt(7)))}
+	e, values, err = UnpackLog(erc721Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Standard != "ERC721" || values["tokenId"].(*big.Int).Int64() != 7 {
+		t.Errorf("ERC-721 Transfer = %s %v", e.Standard, values)
+	}
+
+	if len(EventsByTopic(topic)) < 3 { // ERC20、ERC721、WETH
+		t.Errorf("EventsByTopic(Transfer) = %d events", len(EventsByTopic(topic)))
+	}
+	if _, _, err := UnpackLog(&types.Log{Topics: []common.Hash{{1}}}); err == nil {
+		t.Error("unknown event decoded")
+	}
+}
@@ -0,0 +1,54 @@
+// Package cli ethx 与各示例共用的命令行基础设施：
+// - 子命令分发（支持命令组，如 "ethx block range"）
+// - 节点参数 --rpc / --timeout 与连接
+// - 发送方私钥加载
+// - 统一的输出格式：--format pretty | json | yaml | csv，见 internal/output
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrUsage 命令行用法错误，调用方应以退出码 2 结束
+var ErrUsage = errors.New("invalid usage")
+
+// Command 一个子命令。Sub 非空时为命令组，Run 不会被调用
+type Command struct {
+	Name  string
+	Short string // 一行说明，显示在 usage 中
+	Run   func(args []string) error
+	Sub   []*Command
+}
+
+// Dispatch 按 args[0] 查找子命令并执行，命令组递归分发。
+// prog 为当前层级的命令前缀（如 "ethx" / "ethx block"），用于 usage 和子命令的 FlagSet 名称
+func Dispatch(prog string, cmds []*Command, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		Usage(os.Stderr, prog, cmds)
+		return ErrUsage
+	}
+	for _, c := range cmds {
+		if c.Name != args[0] {
+			continue
+		}
+		if len(c.Sub) > 0 {
+			return Dispatch(prog+" "+c.Name, c.Sub, args[1:])
+		}
+		return c.Run(args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	Usage(os.Stderr, prog, cmds)
+	return ErrUsage
+}
+
+// Usage 输出命令列表
+func Usage(w io.Writer, prog string, cmds []*Command) {
+	fmt.Fprintf(w, "usage: %s <command> [flags]\n\ncommands:\n", prog)
+	for _, c := range cmds {
+		fmt.Fprintf(w, "  %-12s%s\n", c.Name, c.Short)
+	}
+	fmt.Fprintln(w, "\nrun a command with -h to see its flags")
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+	"github.com/yzucdh1/examples/internal/signer"
+)
+
+// RPC 共用的节点参数。取值顺序：命令行参数 > 环境变量 > 配置文件（见 internal/config）> 命令自身的默认值
+type RPC struct {
+	fs             *flag.FlagSet
+	profile        string
+	url            string
+	signer         string
+	timeout        time.Duration
+	defaultTimeout time.Duration
+
+	once sync.Once
+	cfg  *config.Config
+}
+
+// AddRPCFlags 注册 --profile、--rpc 和 --timeout，timeout 为该命令的默认超时
+func AddRPCFlags(fs *flag.FlagSet, timeout time.Duration) *RPC {
+	r := &RPC{fs: fs, defaultTimeout: timeout}
+	fs.StringVar(&r.profile, "profile", "", "config profile (default $ETH_PROFILE or the config file's profile)")
+	fs.StringVar(&r.url, "rpc", "", "node RPC URL (default $ETH_RPC_URL or the profile's rpc)")
+	fs.DurationVar(&r.timeout, "timeout", timeout, "overall timeout for the command (0 = none)")
+	return r
+}
+
+// AddSignerFlag 为发送交易的命令注册 --signer（scheme:ref，例如 kms:alias/deployer），
+// 对应的 scheme 需要由调用方导入实现包注册（见 internal/signer/kms）
+func (r *RPC) AddSignerFlag() {
+	r.fs.StringVar(&r.signer, "signer", "", "signer as scheme:ref, e.g. kms:alias/deployer (default $SENDER_SIGNER or the profile's key)")
+}
+
+// Config 合并后的配置，首次调用时加载；配置文件无效时直接退出（与 config.Get 一致）
+func (r *RPC) Config() *config.Config {
+	r.once.Do(func() {
+		cfg, err := config.Load(config.DefaultPath(), r.profile)
+		if err != nil {
+			log.Fatalf("failed to load config: %v", err)
+		}
+		if r.url != "" {
+			cfg.RPCURL = r.url
+		}
+		if r.signer != "" {
+			cfg.Signer = r.signer
+		}
+		// 显式传入的 --timeout 优先，否则使用配置中的超时，最后才是命令默认值
+		timeoutSet := false
+		r.fs.Visit(func(f *flag.Flag) {
+			if f.Name == "timeout" {
+				timeoutSet = true
+			}
+		})
+		if timeoutSet {
+			cfg.Timeout = r.timeout
+		} else {
+			cfg.Timeout = cfg.TimeoutOr(r.defaultTimeout)
+		}
+		r.cfg = cfg
+	})
+	return r.cfg
+}
+
+// Context 返回带超时的上下文
+func (r *RPC) Context() (context.Context, context.CancelFunc) {
+	if timeout := r.Config().Timeout; timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// Dial 连接节点
+func (r *RPC) Dial(ctx context.Context) (*ethclient.Client, error) {
+	url := r.Config().RPCURL
+	if url == "" {
+		return nil, config.ErrNoRPC
+	}
+	client, err := rpcretry.Dial(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ethereum node: %w", err)
+	}
+	return client, nil
+}
+
+// Signer 按配置创建发送方的签名器（--signer、SENDER_PRIVATE_KEY 或 profile 中配置的密钥来源，见 internal/signer）
+func (r *RPC) Signer(ctx context.Context) (signer.Signer, error) {
+	return signer.FromConfig(ctx, r.Config())
+}
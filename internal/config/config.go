@@ -0,0 +1,333 @@
+// Package config 各示例共用的分层配置：命令行参数 > 环境变量 > 配置文件。
+// 命令行参数 --profile、--rpc、--timeout 由 AddFlags 注册（ethx 使用 internal/cli 中的同名参数）。
+//
+// 配置文件默认为 ~/.config/eth-examples.yaml（可用 ETH_EXAMPLES_CONFIG 指定其他路径），不存在时视为空配置：
+//
+//	profile: sepolia              # 默认使用的 profile，可被 ETH_PROFILE / --profile 覆盖
+//	timeout: 30s                  # 所有 profile 的默认超时，覆盖各示例内置的超时
+//	profiles:
+//	  mainnet:
+//	    rpc: https://mainnet.infura.io/v3/${INFURA_KEY}   # 支持 ${VAR} 引用环境变量，避免把密钥写进文件
+//	    ws: wss://mainnet.infura.io/ws/v3/${INFURA_KEY}
+//	  sepolia:
+//	    rpc: https://sepolia.infura.io/v3/${INFURA_KEY}
+//	    timeout: 1m
+//	    key:
+//	      file: ~/.secrets/sepolia.key                   # 文件内容为十六进制私钥
+//	  local:
+//	    rpc: http://127.0.0.1:8545
+//	    key:
+//	      env: ANVIL_PRIVATE_KEY                         # 从另一个环境变量读取私钥
+//	  holesky:
+//	    rpc: https://ethereum-holesky-rpc.publicnode.com
+//	    key:
+//	      keystore: ~/.ethereum/keystore/UTC--...        # keystore 文件，密码默认从 ETH_KEYSTORE_PASSWORD 读取
+//	  dev:
+//	    rpc: http://127.0.0.1:8545
+//	    key:
+//	      mnemonic_env: DEV_MNEMONIC                     # 从环境变量读取助记词
+//	      hd_path: m/44'/60'/0'/0/1                      # 默认 m/44'/60'/0'/0/0
+//	  clef:
+//	    rpc: https://sepolia.infura.io/v3/${INFURA_KEY}
+//	    key:
+//	      remote: http://127.0.0.1:8550                  # 远程签名服务（Clef 的 account_* 接口）
+//	      address: 0x...
+//	  prod:
+//	    rpc: https://mainnet.infura.io/v3/${INFURA_KEY}
+//	    key:
+//	      signer: kms:alias/deployer                     # 云 KMS / Vault，见 internal/signer/kms
+//
+// 密钥来源的解析见 internal/signer。
+// 环境变量 ETH_RPC_URL、ETH_WS_URL、SENDER_PRIVATE_KEY、SENDER_SIGNER、ETH_TIMEOUT 优先于配置文件中的对应项。
+// 未配置时内置一个 local profile（http://127.0.0.1:8545 / ws://127.0.0.1:8546）。
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 环境变量名
+const (
+	EnvConfig  = "ETH_EXAMPLES_CONFIG"
+	EnvProfile = "ETH_PROFILE"
+	EnvRPCURL  = "ETH_RPC_URL"
+	EnvWSURL   = "ETH_WS_URL"
+	EnvKey     = "SENDER_PRIVATE_KEY"
+	EnvSigner  = "SENDER_SIGNER"
+	EnvTimeout = "ETH_TIMEOUT"
+
+	// DefaultPasswordEnv keystore 未指定 password_env 时读取密码的环境变量
+	DefaultPasswordEnv = "ETH_KEYSTORE_PASSWORD"
+)
+
+// File 配置文件结构
+type File struct {
+	Profile  string             `yaml:"profile"`
+	Timeout  time.Duration      `yaml:"timeout"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile 一组命名的节点与密钥配置
+type Profile struct {
+	RPC     string        `yaml:"rpc"`
+	WS      string        `yaml:"ws"`
+	Timeout time.Duration `yaml:"timeout"`
+	Key     KeySource     `yaml:"key"`
+}
+
+// KeySource 签名密钥来源，只能设置其中一种：env / file（十六进制私钥）、keystore、mnemonic_env、remote、signer
+type KeySource struct {
+	Env  string `yaml:"env"`  // 环境变量名
+	File string `yaml:"file"` // 私钥文件路径，支持 ~ 开头
+
+	Keystore    string `yaml:"keystore"`     // keystore JSON 文件路径，支持 ~ 开头
+	PasswordEnv string `yaml:"password_env"` // keystore 密码所在的环境变量，默认 ETH_KEYSTORE_PASSWORD
+
+	MnemonicEnv string `yaml:"mnemonic_env"` // 助记词所在的环境变量名
+	HDPath      string `yaml:"hd_path"`      // 派生路径，默认 m/44'/60'/0'/0/0
+
+	Remote  string `yaml:"remote"`  // 远程签名服务 URL，支持 ${VAR}
+	Address string `yaml:"address"` // 远程签名使用的账户
+
+	Signer string `yaml:"signer"` // scheme:ref 形式的签名器，例如 kms:alias/deployer（见 signer.Open）
+}
+
+// builtinProfiles 配置文件中未定义同名 profile 时使用
+var builtinProfiles = map[string]Profile{
+	"local": {RPC: "http://127.0.0.1:8545", WS: "ws://127.0.0.1:8546"},
+}
+
+// Config 合并各层之后的最终配置
+type Config struct {
+	Path       string // 读取的配置文件，不存在时为空
+	Profile    string // 使用的 profile，未选择时为空
+	RPCURL     string
+	WSURL      string
+	Timeout    time.Duration // 0 表示使用各示例内置的超时
+	PrivateKey string        // 十六进制私钥，未配置时为空
+	Signer     string        // scheme:ref 形式的签名器（SENDER_SIGNER，或未设置 SENDER_PRIVATE_KEY 时 profile 中的 key.signer），优先于 PrivateKey
+	Key        KeySource     // profile 中的 keystore / 助记词 / 远程签名配置；PrivateKey 或 Signer 非空时不使用
+}
+
+// DefaultPath 默认配置文件路径：$ETH_EXAMPLES_CONFIG，否则 $XDG_CONFIG_HOME 或 ~/.config 下的 eth-examples.yaml
+func DefaultPath() string {
+	if p := os.Getenv(EnvConfig); p != "" {
+		return p
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "eth-examples.yaml")
+}
+
+// Load 读取配置文件并按 环境变量 > 配置文件 合并。profile 非空时优先于 ETH_PROFILE 和文件中的默认值
+// （供 --profile 参数使用）；其余命令行参数由调用方在 Load 之后覆盖
+func Load(path, profile string) (*Config, error) {
+	var file File
+	cfg := &Config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		default:
+			if err := yaml.Unmarshal(data, &file); err != nil {
+				return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+			}
+			cfg.Path = path
+		}
+	}
+
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+	if profile == "" {
+		profile = file.Profile
+	}
+	var p Profile
+	if profile != "" {
+		var ok bool
+		if p, ok = file.Profiles[profile]; !ok {
+			if p, ok = builtinProfiles[profile]; !ok {
+				return nil, fmt.Errorf("unknown profile %q", profile)
+			}
+		}
+		cfg.Profile = profile
+	}
+
+	cfg.RPCURL = firstNonEmpty(os.Getenv(EnvRPCURL), os.ExpandEnv(p.RPC))
+	cfg.WSURL = firstNonEmpty(os.Getenv(EnvWSURL), os.ExpandEnv(p.WS))
+
+	cfg.Timeout = p.Timeout
+	if cfg.Timeout == 0 {
+		cfg.Timeout = file.Timeout
+	}
+	if v := os.Getenv(EnvTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvTimeout, err)
+		}
+		cfg.Timeout = d
+	}
+
+	key, err := p.Key.resolve()
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", profile, err)
+	}
+	cfg.Key = key
+	// 环境变量中的 SENDER_SIGNER / SENDER_PRIVATE_KEY 优先于 profile 中的任何密钥来源，包括 key.signer
+	cfg.Signer = os.Getenv(EnvSigner)
+	if v := os.Getenv(EnvKey); v != "" {
+		cfg.PrivateKey = v
+	} else if key.Env != "" || key.File != "" {
+		if cfg.PrivateKey, err = key.read(); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", profile, err)
+		}
+	} else if cfg.Signer == "" {
+		cfg.Signer = key.Signer
+	}
+	return cfg, nil
+}
+
+// resolve 检查只设置了一种来源，并展开路径中的 ~ 和 URL 中的 ${VAR}
+func (k KeySource) resolve() (KeySource, error) {
+	n := 0
+	for _, v := range []string{k.Env, k.File, k.Keystore, k.MnemonicEnv, k.Remote, k.Signer} {
+		if v != "" {
+			n++
+		}
+	}
+	if n > 1 {
+		return k, errors.New("key: set only one of env, file, keystore, mnemonic_env, remote and signer")
+	}
+	if k.Remote != "" && k.Address == "" {
+		return k, errors.New("key: remote requires address")
+	}
+	var err error
+	if k.File, err = expandHome(k.File); err != nil {
+		return k, fmt.Errorf("key file: %w", err)
+	}
+	if k.Keystore, err = expandHome(k.Keystore); err != nil {
+		return k, fmt.Errorf("keystore: %w", err)
+	}
+	if k.Keystore != "" && k.PasswordEnv == "" {
+		k.PasswordEnv = DefaultPasswordEnv
+	}
+	k.Remote = os.ExpandEnv(k.Remote)
+	return k, nil
+}
+
+// read 读取 env / file 来源的十六进制私钥
+func (k KeySource) read() (string, error) {
+	if k.Env != "" {
+		return os.Getenv(k.Env), nil
+	}
+	data, err := os.ReadFile(k.File)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// expandHome 展开开头的 ~/
+func expandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+// TimeoutOr 返回配置的超时，未配置时返回示例自身的默认值 def
+func (c *Config) TimeoutOr(def time.Duration) time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return def
+}
+
+// ErrNoRPC 最终配置中没有可用的节点地址
+var ErrNoRPC = errors.New("no node URL: set ETH_RPC_URL (or ETH_WS_URL), pass --rpc or configure a profile")
+
+// flagValues AddFlags 注册的命令行参数，Get 首次加载时应用
+type flagValues struct {
+	profile string
+	rpc     string
+	timeout time.Duration
+}
+
+var flags flagValues
+
+// AddFlags 为独立示例注册 --profile、--rpc 和 --timeout，须在 flag.Parse 之前、首次调用 Get 之前调用。
+// fs 中已有的同名参数不再注册（例如 16-trace 的 --timeout 有自己的含义）
+func AddFlags(fs *flag.FlagSet) {
+	if fs.Lookup("profile") == nil {
+		fs.StringVar(&flags.profile, "profile", "", "config profile (default $ETH_PROFILE or the config file's profile)")
+	}
+	if fs.Lookup("rpc") == nil {
+		fs.StringVar(&flags.rpc, "rpc", "", "node URL, http(s) or ws(s) (default $ETH_RPC_URL / $ETH_WS_URL or the profile's rpc / ws)")
+	}
+	if fs.Lookup("timeout") == nil {
+		fs.DurationVar(&flags.timeout, "timeout", 0, "overall timeout (default $ETH_TIMEOUT, the profile's timeout or the example's built-in one)")
+	}
+}
+
+// apply 用命令行参数覆盖 cfg。--rpc 同时替换 RPCURL 和 WSURL：ws(s):// 地址两者都用它，
+// http(s):// 地址则清空 WSURL，避免优先读 WSURL 的订阅类示例仍连到环境变量或 profile 中的节点
+func (f flagValues) apply(cfg *Config) {
+	if f.rpc != "" {
+		cfg.RPCURL = f.rpc
+		cfg.WSURL = ""
+		if strings.HasPrefix(f.rpc, "ws://") || strings.HasPrefix(f.rpc, "wss://") {
+			cfg.WSURL = f.rpc
+		}
+	}
+	if f.timeout > 0 {
+		cfg.Timeout = f.timeout
+	}
+}
+
+var loadDefault = sync.OnceValue(func() *Config {
+	cfg, err := Load(DefaultPath(), flags.profile)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	flags.apply(cfg)
+	return cfg
+})
+
+// Get 返回进程级配置（首次调用时从默认路径加载，并应用 AddFlags 注册的参数）。
+// 配置文件存在但无效时直接退出，避免静默回退到环境变量或错误的节点
+func Get() *Config {
+	return loadDefault()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testFile = `
+profile: sepolia
+timeout: 30s
+profiles:
+  sepolia:
+    rpc: https://sepolia.example/v3/${TEST_API_KEY}
+    timeout: 1m
+    key:
+      file: %s
+  mainnet:
+    rpc: https://mainnet.example
+    key:
+      env: TEST_MAINNET_KEY
+`
+
+// writeConfig 写入临时配置文件，并清空会影响结果的环境变量
+func writeConfig(t *testing.T) string {
+	t.Helper()
+	for _, name := range []string{EnvProfile, EnvRPCURL, EnvWSURL, EnvKey, EnvSigner, EnvTimeout} {
+		t.Setenv(name, "")
+	}
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "sepolia.key")
+	if err := os.WriteFile(keyPath, []byte("0xabc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "eth-examples.yaml")
+	content := []byte(fmt.Sprintf(testFile, keyPath))
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFileDefaults(t *testing.T) {
+	path := writeConfig(t)
+	t.Setenv("TEST_API_KEY", "secret")
+
+	cfg, err := Load(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != "sepolia" || cfg.Path != path {
+		t.Errorf("profile = %q, path = %q", cfg.Profile, cfg.Path)
+	}
+	if cfg.RPCURL != "https://sepolia.example/v3/secret" {
+		t.Errorf("RPCURL = %q", cfg.RPCURL)
+	}
+	if cfg.Timeout != time.Minute {
+		t.Errorf("Timeout = %v, want profile timeout 1m", cfg.Timeout)
+	}
+	if cfg.PrivateKey != "0xabc" {
+		t.Errorf("PrivateKey = %q, want key file content", cfg.PrivateKey)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t)
+
+	// ETH_PROFILE 覆盖文件中的默认 profile，profile 参数（--profile）再覆盖 ETH_PROFILE
+	t.Setenv(EnvProfile, "local")
+	t.Setenv("TEST_MAINNET_KEY", "0xdef")
+	cfg, err := Load(path, "mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != "mainnet" || cfg.RPCURL != "https://mainnet.example" || cfg.PrivateKey != "0xdef" {
+		t.Errorf("got profile %q rpc %q key %q", cfg.Profile, cfg.RPCURL, cfg.PrivateKey)
+	}
+	if cfg.Timeout != 30*time.Second {
+		t.Errorf("Timeout = %v, want file default 30s", cfg.Timeout)
+	}
+
+	// 环境变量覆盖 profile 中的值
+	t.Setenv(EnvRPCURL, "http://env.example")
+	t.Setenv(EnvKey, "0x123")
+	t.Setenv(EnvTimeout, "5s")
+	cfg, err = Load(path, "mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RPCURL != "http://env.example" || cfg.PrivateKey != "0x123" || cfg.Timeout != 5*time.Second {
+		t.Errorf("got rpc %q key %q timeout %v", cfg.RPCURL, cfg.PrivateKey, cfg.Timeout)
+	}
+}
+
+func TestLoadBuiltinAndMissing(t *testing.T) {
+	writeConfig(t)
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), "local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Path != "" || cfg.RPCURL != "http://127.0.0.1:8545" {
+		t.Errorf("got path %q rpc %q", cfg.Path, cfg.RPCURL)
+	}
+	if got := cfg.TimeoutOr(7 * time.Second); got != 7*time.Second {
+		t.Errorf("TimeoutOr = %v, want example default", got)
+	}
+
+	if _, err := Load("", "nope"); err == nil {
+		t.Error("Load with unknown profile succeeded, want error")
+	}
+}
+
+func TestLoadKeySources(t *testing.T) {
+	writeConfig(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "eth-examples.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("profiles:\n  dev:\n    key:\n      keystore: ~/keys/dev.json\n")
+	t.Setenv("HOME", dir)
+	cfg, err := Load(path, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Key.Keystore != filepath.Join(dir, "keys/dev.json") || cfg.Key.PasswordEnv != DefaultPasswordEnv {
+		t.Errorf("got keystore %q password env %q", cfg.Key.Keystore, cfg.Key.PasswordEnv)
+	}
+	if cfg.PrivateKey != "" {
+		t.Errorf("PrivateKey = %q, want empty for keystore source", cfg.PrivateKey)
+	}
+
+	write("profiles:\n  dev:\n    key:\n      env: A\n      mnemonic_env: B\n")
+	if _, err := Load(path, "dev"); err == nil {
+		t.Error("Load with two key sources succeeded, want error")
+	}
+
+	write("profiles:\n  dev:\n    key:\n      remote: http://127.0.0.1:8550\n")
+	if _, err := Load(path, "dev"); err == nil {
+		t.Error("Load with remote but no address succeeded, want error")
+	}
+
+	// SENDER_SIGNER 覆盖 profile 中的 key.signer
+	write("profiles:\n  prod:\n    key:\n      signer: kms:alias/deployer\n")
+	if cfg, err = Load(path, "prod"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Signer != "kms:alias/deployer" {
+		t.Errorf("Signer = %q, want profile signer", cfg.Signer)
+	}
+	t.Setenv(EnvSigner, "vault:secret/data/eth/deployer")
+	if cfg, err = Load(path, "prod"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Signer != "vault:secret/data/eth/deployer" {
+		t.Errorf("Signer = %q, want %s", cfg.Signer, EnvSigner)
+	}
+
+	// SENDER_PRIVATE_KEY 同样优先于 profile 中的 key.signer
+	t.Setenv(EnvSigner, "")
+	t.Setenv(EnvKey, "0x123")
+	if cfg, err = Load(path, "prod"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Signer != "" || cfg.PrivateKey != "0x123" {
+		t.Errorf("got signer %q key %q, want %s to win over key.signer", cfg.Signer, cfg.PrivateKey, EnvKey)
+	}
+}
+
+func TestAddFlags(t *testing.T) {
+	defer func() { flags = flagValues{} }()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Duration("timeout", time.Minute, "already defined by the example")
+	AddFlags(fs)
+	if err := fs.Parse([]string{"--profile", "mainnet", "--rpc", "wss://node.example"}); err != nil {
+		t.Fatal(err)
+	}
+	if flags.profile != "mainnet" {
+		t.Errorf("profile = %q, want mainnet", flags.profile)
+	}
+	cfg := &Config{RPCURL: "https://env.example", WSURL: "wss://env.example", Timeout: 10 * time.Second}
+	flags.apply(cfg)
+	if cfg.RPCURL != "wss://node.example" || cfg.WSURL != "wss://node.example" {
+		t.Errorf("got rpc %q ws %q, want both wss://node.example", cfg.RPCURL, cfg.WSURL)
+	}
+	if cfg.Timeout != 10*time.Second {
+		t.Errorf("Timeout = %v, want the example's own --timeout to be left alone", cfg.Timeout)
+	}
+
+	// http(s) 地址清空 WSURL，订阅类示例回退到 RPCURL
+	flags = flagValues{rpc: "https://node.example", timeout: 5 * time.Second}
+	cfg = &Config{RPCURL: "https://env.example", WSURL: "wss://env.example"}
+	flags.apply(cfg)
+	if cfg.RPCURL != "https://node.example" || cfg.WSURL != "" || cfg.Timeout != 5*time.Second {
+		t.Errorf("got rpc %q ws %q timeout %v", cfg.RPCURL, cfg.WSURL, cfg.Timeout)
+	}
+}
@@ -0,0 +1,106 @@
+// Package decimal ETH 与代币数量的精确十进制换算：金额一律以最小单位（wei、代币最小单位）的 *big.Int 表示，
+// 字符串与最小单位之间按 decimals 逐位换算，不经过 float64 / big.Float。
+//
+// float64 只有 53 位尾数，0.1 ETH 会变成 100000000000000005 wei，超过 2^53 wei（约 0.009 ETH）的金额
+// 也无法精确表示；此前 03 的 --amount 就是这样换算的。ethutil 中的 ParseUnits / FormatUnits / FormatEth
+// 现在都委托给本包。
+package decimal
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// 常用单位的小数位数
+const (
+	EtherDecimals = 18
+	GweiDecimals  = 9
+)
+
+// ParseUnits 把十进制字符串按 decimals 精确转换为最小单位，例如 ParseUnits("1.5", 6) = 1500000。
+// 小数位数超过 decimals 时返回错误，而不是静默截断；不接受负数、正号和科学计数法
+func ParseUnits(amount string, decimals uint8) (*big.Int, error) {
+	s := strings.TrimSpace(amount)
+	whole, frac, hasDot := strings.Cut(s, ".")
+	if s == "" || (hasDot && whole == "" && frac == "") || strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	if len(frac) > int(decimals) {
+		return nil, fmt.Errorf("amount %q has more than %d decimals", amount, decimals)
+	}
+	digits := whole + frac + strings.Repeat("0", int(decimals)-len(frac))
+	v, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	return v, nil
+}
+
+// ParseEther 把以 ETH 为单位的十进制字符串转换为 wei
+func ParseEther(amount string) (*big.Int, error) {
+	return ParseUnits(amount, EtherDecimals)
+}
+
+// ParseGwei 把以 gwei 为单位的十进制字符串转换为 wei
+func ParseGwei(amount string) (*big.Int, error) {
+	return ParseUnits(amount, GweiDecimals)
+}
+
+// FormatUnits 把最小单位精确转换为带 decimals 位小数的字符串，例如 FormatUnits(1500000, 6) = "1.500000"
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	neg := amount.Sign() < 0
+	digits := new(big.Int).Abs(amount).String()
+	if decimals > 0 {
+		if len(digits) <= int(decimals) {
+			digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+		}
+		cut := len(digits) - int(decimals)
+		digits = digits[:cut] + "." + digits[cut:]
+	}
+	if neg {
+		return "-" + digits
+	}
+	return digits
+}
+
+// FormatEther 把 wei 精确转换为 ETH 字符串（18 位小数）
+func FormatEther(wei *big.Int) string {
+	return FormatUnits(wei, EtherDecimals)
+}
+
+// FormatFixed 把最小单位转换为保留 places 位小数的字符串（四舍五入，远离零），用于显示，
+// 例如 FormatFixed(1234567890000000000, 18, 6) = "1.234568"
+func FormatFixed(amount *big.Int, decimals uint8, places uint8) string {
+	if places >= decimals {
+		return FormatUnits(new(big.Int).Mul(amount, pow10(places-decimals)), places)
+	}
+	unit := pow10(decimals - places)
+	q, r := new(big.Int).QuoRem(amount, unit, new(big.Int))
+	// |r| × 2 >= unit 时进位
+	if r.Abs(r).Lsh(r, 1).Cmp(unit) >= 0 {
+		if amount.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return FormatUnits(q, places)
+}
+
+// Rescale 把 from 位小数的最小单位换算为 to 位小数，例如 6 位的 USDC 数量换算为 18 位。
+// 缩小精度时不能整除（会丢失尾数）返回错误
+func Rescale(amount *big.Int, from, to uint8) (*big.Int, error) {
+	if to >= from {
+		return new(big.Int).Mul(amount, pow10(to-from)), nil
+	}
+	q, r := new(big.Int).QuoRem(amount, pow10(from-to), new(big.Int))
+	if r.Sign() != 0 {
+		return nil, fmt.Errorf("amount %s cannot be represented with %d decimals", FormatUnits(amount, from), to)
+	}
+	return q, nil
+}
+
+func pow10(n uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,101 @@
+package decimal
+
+import (
+	"math/big"
+	"testing"
+)
+
+func mustInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("bad test integer %q", s)
+	}
+	return v
+}
+
+func TestParseEther(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		// float64 会把 0.1 ether 换算成 100000000000000005 wei
+		{"0.1", "100000000000000000", false},
+		{"0.000000000000000001", "1", false},
+		// 超过 2^53 wei 且有 18 位小数的金额
+		{"12345678.901234567890123456", "12345678901234567890123456", false},
+		{" 1 ", "1000000000000000000", false},
+		{"0.0000000000000000001", "", true},
+		{"1,5", "", true},
+		{"1e18", "", true},
+		{"-0.1", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseEther(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseEther(%q) = %s, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("ParseEther(%q) = %v, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+
+	if got, err := ParseGwei("1.5"); err != nil || got.Int64() != 1_500_000_000 {
+		t.Errorf("ParseGwei(1.5) = %v, %v", got, err)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, s := range []string{"0.000000000000000001", "1.500000000000000000", "123456789.123456789123456789"} {
+		wei, err := ParseEther(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FormatEther(wei); got != s {
+			t.Errorf("FormatEther(ParseEther(%q)) = %q", s, got)
+		}
+	}
+}
+
+func TestFormatFixed(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals uint8
+		places   uint8
+		want     string
+	}{
+		{"1234567890000000000", 18, 6, "1.234568"},
+		{"1234564990000000000", 18, 6, "1.234565"},
+		{"1234564490000000000", 18, 6, "1.234564"},
+		{"999999500000000000", 18, 6, "1.000000"},
+		{"-1234567890000000000", 18, 6, "-1.234568"},
+		{"1", 18, 6, "0.000000"},
+		{"1234567890", 9, 3, "1.235"},
+		{"15", 1, 0, "2"},
+		{"15", 1, 3, "1.500"},
+	}
+	for _, tt := range tests {
+		if got := FormatFixed(mustInt(t, tt.in), tt.decimals, tt.places); got != tt.want {
+			t.Errorf("FormatFixed(%s, %d, %d) = %s, want %s", tt.in, tt.decimals, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestRescale(t *testing.T) {
+	// 1.5 USDC（6 位）换算为 18 位
+	got, err := Rescale(big.NewInt(1_500_000), 6, 18)
+	if err != nil || got.String() != "1500000000000000000" {
+		t.Errorf("Rescale(6 -> 18) = %v, %v", got, err)
+	}
+	got, err = Rescale(mustInt(t, "1500000000000000000"), 18, 6)
+	if err != nil || got.Int64() != 1_500_000 {
+		t.Errorf("Rescale(18 -> 6) = %v, %v", got, err)
+	}
+	if _, err := Rescale(mustInt(t, "1500000000000000001"), 18, 6); err == nil {
+		t.Error("Rescale with lost precision succeeded, want error")
+	}
+}
@@ -0,0 +1,111 @@
+// Package ethutil 各示例共用的小工具，集中维护此前分散在 03 / 04 / 08 等示例中的重复实现：
+// - 私钥加载（去掉 0x 前缀、推导地址）
+// - wei / ETH / gwei 换算与格式化
+// - 代币数量解析 / 格式化（按 decimals 精确换算，不经过 float64，实现见 internal/decimal）
+// - EIP-1559 费用计算（fee cap = base fee × 2 + tip）
+package ethutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/yzucdh1/examples/internal/decimal"
+)
+
+// Trim0x 移除十六进制字符串的 "0x" / "0X" 前缀
+func Trim0x(s string) string {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:]
+	}
+	return s
+}
+
+// LoadPrivateKey 解析十六进制私钥（可带 0x 前缀和首尾空白），同时返回对应地址
+func LoadPrivateKey(hexKey string) (*ecdsa.PrivateKey, common.Address, error) {
+	key, err := crypto.HexToECDSA(Trim0x(strings.TrimSpace(hexKey)))
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("invalid private key: %w", err)
+	}
+	return key, crypto.PubkeyToAddress(key.PublicKey), nil
+}
+
+// PrivateKeyFromEnv 从环境变量（通常是 SENDER_PRIVATE_KEY）加载私钥
+func PrivateKeyFromEnv(name string) (*ecdsa.PrivateKey, common.Address, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil, common.Address{}, fmt.Errorf("%s is not set", name)
+	}
+	return LoadPrivateKey(v)
+}
+
+// WeiToEth 把 wei 转换为 ETH（big.Float，仅用于显示；需要精确值时使用 decimal.FormatEther）
+func WeiToEth(wei *big.Int) *big.Float {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.Ether))
+}
+
+// FormatEth 以 ETH 为单位格式化，保留 prec 位小数（四舍五入）
+func FormatEth(wei *big.Int, prec uint8) string {
+	return decimal.FormatFixed(wei, decimal.EtherDecimals, prec)
+}
+
+// FormatGwei 以 gwei 为单位格式化，保留 3 位小数并带单位，例如 "1.234 gwei"
+func FormatGwei(wei *big.Int) string {
+	return decimal.FormatFixed(wei, decimal.GweiDecimals, 3) + " gwei"
+}
+
+// ParseUnits 把十进制字符串按 decimals 精确转换为最小单位，见 decimal.ParseUnits
+func ParseUnits(amount string, decimals uint8) (*big.Int, error) {
+	return decimal.ParseUnits(amount, decimals)
+}
+
+// ParseTokenAmount 解析命令行中的代币数量（08 / 19 的约定）：
+// 包含小数点（如 "1.5"）时视为代币数量，按 decimals 换算为最小单位；
+// 纯整数（如 "1500000"）视为已经是最小单位
+func ParseTokenAmount(amount string, decimals uint8) (*big.Int, error) {
+	if strings.Contains(amount, ".") {
+		return decimal.ParseUnits(amount, decimals)
+	}
+	return decimal.ParseUnits(amount, 0)
+}
+
+// FormatUnits 把最小单位精确转换为带 decimals 位小数的字符串，见 decimal.FormatUnits
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	return decimal.FormatUnits(amount, decimals)
+}
+
+// FeeBackend DynamicFees 需要的节点能力，*ethclient.Client 和模拟链客户端都满足该接口
+type FeeBackend interface {
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// DynamicFees 简单的 EIP-1559 费用策略：tip 取节点建议值，fee cap = 最新区块 base fee × 2 + tip，
+// base fee 翻倍可以扛住连续 6 个满区块的上涨。不支持 EIP-1559 的链用 gas price 代替 base fee。
+// 需要按速度分档时使用 24-gas-tracker 的 gasoracle
+func DynamicFees(ctx context.Context, backend FeeBackend) (tipCap, feeCap *big.Int, err error) {
+	tipCap, err = backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get gas tip cap: %w", err)
+	}
+	header, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get header: %w", err)
+	}
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		if baseFee, err = backend.SuggestGasPrice(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to get gas price: %w", err)
+		}
+	}
+	feeCap = new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tipCap)
+	return tipCap, feeCap, nil
+}
@@ -0,0 +1,198 @@
+package ethutil
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 测试用私钥（公开的示例私钥，不要在任何真实网络上使用）
+const (
+	testKeyHex  = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testKeyAddr = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+func TestTrim0x(t *testing.T) {
+	tests := map[string]string{
+		"0xabc": "abc",
+		"0Xabc": "abc",
+		"abc":   "abc",
+		"0x":    "",
+		"0":     "0",
+		"":      "",
+	}
+	for in, want := range tests {
+		if got := Trim0x(in); got != want {
+			t.Errorf("Trim0x(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	for _, in := range []string{testKeyHex, "0x" + testKeyHex, " 0x" + testKeyHex + "\n"} {
+		key, addr, err := LoadPrivateKey(in)
+		if err != nil {
+			t.Fatalf("LoadPrivateKey(%q): %v", in, err)
+		}
+		if key == nil || addr.Hex() != testKeyAddr {
+			t.Errorf("LoadPrivateKey(%q) address = %s, want %s", in, addr.Hex(), testKeyAddr)
+		}
+	}
+	if _, _, err := LoadPrivateKey("0x1234"); err == nil {
+		t.Error("LoadPrivateKey(short key) succeeded, want error")
+	}
+}
+
+func TestPrivateKeyFromEnv(t *testing.T) {
+	t.Setenv("ETHUTIL_TEST_KEY", "0x"+testKeyHex)
+	if _, addr, err := PrivateKeyFromEnv("ETHUTIL_TEST_KEY"); err != nil || addr.Hex() != testKeyAddr {
+		t.Fatalf("PrivateKeyFromEnv = %s, %v", addr.Hex(), err)
+	}
+	t.Setenv("ETHUTIL_TEST_KEY", "")
+	if _, _, err := PrivateKeyFromEnv("ETHUTIL_TEST_KEY"); err == nil {
+		t.Fatal("PrivateKeyFromEnv(unset) succeeded, want error")
+	}
+}
+
+func TestFormatEth(t *testing.T) {
+	wei, _ := new(big.Int).SetString("1234567890000000000", 10)
+	if got := FormatEth(wei, 6); got != "1.234568" {
+		t.Errorf("FormatEth = %s, want 1.234568", got)
+	}
+	if got := FormatGwei(big.NewInt(1234567890)); got != "1.235 gwei" {
+		t.Errorf("FormatGwei = %s, want 1.235 gwei", got)
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals uint8
+		want     string
+		wantErr  bool
+	}{
+		{"1.5", 18, "1500000000000000000", false},
+		// float64 会把 0.1 ether 换算成 100000000000000005 wei
+		{"0.1", 18, "100000000000000000", false},
+		{"123456789.123456789123456789", 18, "123456789123456789123456789", false},
+		{"1", 6, "1000000", false},
+		{".5", 6, "500000", false},
+		{"5.", 6, "5000000", false},
+		{"0", 0, "0", false},
+		{"1.0000001", 6, "", true},
+		{"1.5", 0, "", true},
+		{"-1", 18, "", true},
+		{"", 18, "", true},
+		{".", 18, "", true},
+		{"1e18", 18, "", true},
+		{"1.2.3", 18, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseUnits(tt.in, tt.decimals)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseUnits(%q, %d) = %s, want error", tt.in, tt.decimals, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUnits(%q, %d): %v", tt.in, tt.decimals, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseUnits(%q, %d) = %s, want %s", tt.in, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseTokenAmount(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals uint8
+		want     string
+		wantErr  bool
+	}{
+		{"1.5", 18, "1500000000000000000", false},
+		{"1500000", 6, "1500000", false},
+		{"0.000001", 6, "1", false},
+		{"abc", 18, "", true},
+		{"1.2.3", 18, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTokenAmount(tt.in, tt.decimals)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTokenAmount(%q) succeeded, want error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseTokenAmount(%q): %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseTokenAmount(%q, %d) = %s, want %s", tt.in, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	big18, _ := new(big.Int).SetString("123456789123456789123456789", 10)
+	tests := []struct {
+		in       *big.Int
+		decimals uint8
+		want     string
+	}{
+		{big.NewInt(1500000), 6, "1.500000"},
+		{big.NewInt(1), 6, "0.000001"},
+		{big.NewInt(0), 6, "0.000000"},
+		{big.NewInt(42), 0, "42"},
+		{big.NewInt(-1500000), 6, "-1.500000"},
+		{big18, 18, "123456789.123456789123456789"},
+	}
+	for _, tt := range tests {
+		if got := FormatUnits(tt.in, tt.decimals); got != tt.want {
+			t.Errorf("FormatUnits(%s, %d) = %s, want %s", tt.in, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+// fakeFees 固定返回值的 FeeBackend
+type fakeFees struct {
+	tip, gasPrice, baseFee *big.Int
+	err                    error
+}
+
+func (f fakeFees) SuggestGasTipCap(context.Context) (*big.Int, error) { return f.tip, f.err }
+func (f fakeFees) SuggestGasPrice(context.Context) (*big.Int, error)  { return f.gasPrice, nil }
+func (f fakeFees) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: f.baseFee}, nil
+}
+
+func TestDynamicFees(t *testing.T) {
+	ctx := context.Background()
+
+	tip, feeCap, err := DynamicFees(ctx, fakeFees{tip: big.NewInt(2), gasPrice: big.NewInt(100), baseFee: big.NewInt(10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tip.Int64() != 2 || feeCap.Int64() != 22 {
+		t.Errorf("DynamicFees = (%s, %s), want (2, 22)", tip, feeCap)
+	}
+
+	// 没有 base fee 的链退回 gas price
+	_, feeCap, err = DynamicFees(ctx, fakeFees{tip: big.NewInt(2), gasPrice: big.NewInt(100)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feeCap.Int64() != 202 {
+		t.Errorf("DynamicFees without base fee: fee cap = %s, want 202", feeCap)
+	}
+
+	if _, _, err := DynamicFees(ctx, fakeFees{err: errors.New("boom")}); err == nil {
+		t.Error("DynamicFees succeeded, want error")
+	}
+}
@@ -0,0 +1,481 @@
+// Package output 各示例与 ethx 共用的结构化输出，--format 选择格式：
+//   - pretty（默认）：按仓库惯例输出 "=== Title ===" 和 12 字符对齐的 "Label       : value"，表格按列对齐
+//   - json / yaml：把各段收集起来，Flush 时输出一个对象 {"Title": {"Label": value}}，表格为对象数组；
+//     同名的段合并为数组。键保持调用顺序，*big.Int 等大数按原样输出，不会变成浮点数
+//   - csv：键值段输出为 section,field,value 三列，表格输出为表头加数据行，多个块之间空一行
+//
+// 长时间运行的监听命令用 Record 逐条输出事件：json 为一行一个对象（NDJSON），yaml 为以 --- 分隔的文档，
+// csv 为一行数据。Note 只在 pretty 模式下输出，结构化格式的标准输出中只有数据，可以直接交给 jq 等工具处理。
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format 输出格式
+type Format string
+
+const (
+	Pretty Format = "pretty"
+	JSON   Format = "json"
+	YAML   Format = "yaml"
+	CSV    Format = "csv"
+)
+
+// ParseFormat 解析格式名称
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case Pretty, JSON, YAML, CSV:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("unknown format %q (pretty | json | yaml | csv)", s)
+}
+
+// String 实现 flag.Value
+func (f *Format) String() string { return string(*f) }
+
+// Set 实现 flag.Value
+func (f *Format) Set(s string) error {
+	v, err := ParseFormat(s)
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+// jsonFlag --json 是 --format json 的简写
+type jsonFlag struct{ f *Format }
+
+func (j jsonFlag) String() string   { return "false" }
+func (j jsonFlag) IsBoolFlag() bool { return true }
+func (j jsonFlag) Set(s string) error {
+	if s == "true" {
+		*j.f = JSON
+	}
+	return nil
+}
+
+// AddFlags 注册 --format 以及简写 --json，输出到标准输出
+func AddFlags(fs *flag.FlagSet) *Printer {
+	p := New(os.Stdout, Pretty)
+	fs.Var(&p.Format, "format", "output format: pretty | json | yaml | csv")
+	fs.Var(jsonFlag{&p.Format}, "json", "shorthand for --format json")
+	return p
+}
+
+// Printer 按 Format 输出结果，不可并发使用
+type Printer struct {
+	Format Format
+
+	w        io.Writer
+	sections []*section
+	started  bool
+
+	table *tabwriter.Writer // pretty 模式下尚未刷新的表格
+
+	csv       *csv.Writer // Record 的 csv 输出
+	csvHeader []string
+}
+
+type section struct {
+	title   string
+	fields  object
+	columns []string
+	rows    [][]any
+}
+
+// New 创建输出到 w 的 Printer
+func New(w io.Writer, f Format) *Printer {
+	return &Printer{Format: f, w: w}
+}
+
+// Pretty 是否为人类可读的文本格式
+func (p *Printer) Pretty() bool {
+	return p.Format == Pretty || p.Format == ""
+}
+
+// Section 开始新的一段
+func (p *Printer) Section(title string) {
+	p.sections = append(p.sections, &section{title: title})
+	if !p.Pretty() {
+		return
+	}
+	p.flushTable()
+	if p.started {
+		fmt.Fprintln(p.w)
+	}
+	p.started = true
+	fmt.Fprintf(p.w, "=== %s ===\n", title)
+}
+
+// Field 输出一个字段。结构化格式下 value 按 encoding/json 编码（*big.Int 为数字，地址 / 哈希为十六进制字符串）
+func (p *Printer) Field(label string, value any) {
+	if p.Pretty() {
+		p.flushTable()
+		p.started = true
+		fmt.Fprintf(p.w, "%-12s: %v\n", label, value)
+		return
+	}
+	if len(p.sections) == 0 {
+		p.sections = append(p.sections, &section{title: "result"})
+	}
+	s := p.sections[len(p.sections)-1]
+	s.fields = append(s.fields, member{label, value})
+}
+
+// Table 开始一个表格，之后用 Row 逐行添加
+func (p *Printer) Table(title string, columns ...string) {
+	p.Section(title)
+	p.sections[len(p.sections)-1].columns = columns
+	if p.Pretty() {
+		p.table = tabwriter.NewWriter(p.w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(p.table, strings.Join(columns, "\t"))
+	}
+}
+
+// Row 为当前表格添加一行，值的顺序与 Table 的列一致
+func (p *Printer) Row(values ...any) {
+	if len(p.sections) == 0 || p.sections[len(p.sections)-1].columns == nil {
+		panic("output: Row called without Table")
+	}
+	if p.Pretty() {
+		if p.table == nil {
+			p.table = tabwriter.NewWriter(p.w, 0, 8, 2, ' ', 0)
+		}
+		cells := make([]string, len(values))
+		for i, v := range values {
+			cells[i] = fmt.Sprint(v)
+		}
+		fmt.Fprintln(p.table, strings.Join(cells, "\t"))
+		return
+	}
+	s := p.sections[len(p.sections)-1]
+	s.rows = append(s.rows, values)
+}
+
+// Note 输出提示信息，只在 pretty 模式下显示
+func (p *Printer) Note(format string, args ...any) {
+	if p.Pretty() {
+		p.flushTable()
+		p.started = true
+		fmt.Fprintf(p.w, format+"\n", args...)
+	}
+}
+
+// Record 立即输出一条流式记录，kv 为交替的键和值（与 log/slog 相同）。
+// 记录带有 "type": kind 字段；csv 在列变化时重新输出表头。pretty 模式下不输出，由调用方用 Note 输出可读的一行
+func (p *Printer) Record(kind string, kv ...any) error {
+	if p.Pretty() {
+		return nil
+	}
+	if len(kv)%2 != 0 {
+		panic("output: Record needs key/value pairs")
+	}
+	rec := object{{"type", kind}}
+	for i := 0; i < len(kv); i += 2 {
+		rec = append(rec, member{fmt.Sprint(kv[i]), kv[i+1]})
+	}
+	v, err := normalize(rec)
+	if err != nil {
+		return err
+	}
+	switch p.Format {
+	case JSON:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", data)
+		return err
+	case YAML:
+		data, err := yaml.Marshal(toNode(v))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "---\n%s", data)
+		return err
+	}
+	obj := v.(object)
+	if p.csv == nil {
+		p.csv = csv.NewWriter(p.w)
+	}
+	header := make([]string, len(obj))
+	row := make([]string, len(obj))
+	for i, m := range obj {
+		header[i], row[i] = m.Key, cell(m.Value)
+	}
+	if !slices.Equal(header, p.csvHeader) {
+		p.csvHeader = header
+		p.csv.Write(header)
+	}
+	p.csv.Write(row)
+	p.csv.Flush()
+	return p.csv.Error()
+}
+
+// Flush 输出收集到的结果；pretty 模式下只刷新尚未输出的表格
+func (p *Printer) Flush() error {
+	switch p.Format {
+	case JSON, YAML:
+		// 同名的段合并为数组
+		doc := object{}
+		for _, s := range p.sections {
+			i := slices.IndexFunc(doc, func(m member) bool { return m.Key == s.title })
+			switch {
+			case i >= 0:
+				doc[i].Value = append(doc[i].Value.([]any), s.value())
+			case p.repeated(s.title):
+				doc = append(doc, member{s.title, []any{s.value()}})
+			default:
+				doc = append(doc, member{s.title, s.value()})
+			}
+		}
+		v, err := normalize(doc)
+		if err != nil {
+			return err
+		}
+		if p.Format == JSON {
+			data, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(p.w, "%s\n", data)
+			return err
+		}
+		data, err := yaml.Marshal(toNode(v))
+		if err != nil {
+			return err
+		}
+		_, err = p.w.Write(data)
+		return err
+	case CSV:
+		return p.flushCSV()
+	}
+	p.flushTable()
+	return nil
+}
+
+// repeated 同名的段是否出现多次
+func (p *Printer) repeated(title string) bool {
+	n := 0
+	for _, s := range p.sections {
+		if s.title == title {
+			n++
+		}
+	}
+	return n > 1
+}
+
+// value 段在 json / yaml 中的值：键值段为对象，表格为对象数组，两者都有时表格放在 "rows" 中
+func (s *section) value() any {
+	if s.columns == nil {
+		return s.fields
+	}
+	rows := make([]any, len(s.rows))
+	for i, r := range s.rows {
+		obj := make(object, len(s.columns))
+		for j, c := range s.columns {
+			var v any
+			if j < len(r) {
+				v = r[j]
+			}
+			obj[j] = member{c, v}
+		}
+		rows[i] = obj
+	}
+	if len(s.fields) == 0 {
+		return rows
+	}
+	return append(slices.Clone(s.fields), member{"rows", rows})
+}
+
+func (p *Printer) flushCSV() error {
+	w := csv.NewWriter(p.w)
+	first, inFields := true, false
+	block := func(header []string) {
+		if !first {
+			w.Flush()
+			fmt.Fprintln(p.w)
+		}
+		first = false
+		w.Write(header)
+	}
+	for _, s := range p.sections {
+		if len(s.fields) > 0 {
+			if !inFields {
+				block([]string{"section", "field", "value"})
+				inFields = true
+			}
+			for _, m := range s.fields {
+				v, err := normalize(m.Value)
+				if err != nil {
+					return err
+				}
+				w.Write([]string{s.title, m.Key, cell(v)})
+			}
+		}
+		if s.columns != nil {
+			block(s.columns)
+			inFields = false
+			for _, r := range s.rows {
+				row := make([]string, len(r))
+				for i, v := range r {
+					nv, err := normalize(v)
+					if err != nil {
+						return err
+					}
+					row[i] = cell(nv)
+				}
+				w.Write(row)
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (p *Printer) flushTable() {
+	if p.table != nil {
+		p.table.Flush()
+		p.table = nil
+	}
+}
+
+// member / object 保持键顺序的 JSON 对象
+type member struct {
+	Key   string
+	Value any
+}
+
+type object []member
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %q: %w", m.Key, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// normalize 按 encoding/json 的规则把任意值转换为 object / []any / string / json.Number / bool / nil，
+// 使 json、yaml、csv 对同一个值的表示一致（例如地址都是十六进制字符串，大数不经过 float64）
+func normalize(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decode(dec)
+}
+
+func decode(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch d {
+	case '{':
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decode(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key.(string), v})
+		}
+		_, err = dec.Token()
+		return obj, err
+	default:
+		list := []any{}
+		for dec.More() {
+			v, err := decode(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err = dec.Token()
+		return list, err
+	}
+}
+
+// toNode 把 normalize 的结果转换为 yaml 节点，数字按原文输出
+func toNode(v any) *yaml.Node {
+	switch v := v.(type) {
+	case object:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, m := range v {
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: m.Key}, toNode(m.Value))
+		}
+		return n
+	case []any:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, e := range v {
+			n.Content = append(n.Content, toNode(e))
+		}
+		return n
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(string(v), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(v)}
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+// cell normalize 结果在 csv 中的表示：标量直接输出，对象 / 数组为紧凑的 JSON
+func cell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return string(v)
+	case bool:
+		return fmt.Sprint(v)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,149 @@
+package output
+
+import (
+	"bytes"
+	"flag"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// sample 输出两个键值段（同名）和一个表格
+func sample(p *Printer) {
+	wei, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	p.Section("Account")
+	p.Field("Address", common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"))
+	p.Field("Balance", wei)
+	p.Note("not in structured output")
+	p.Section("Account")
+	p.Field("Address", common.Address{})
+	p.Field("Balance", big.NewInt(0))
+	p.Table("Blocks", "Number", "Txs")
+	p.Row(1, 10)
+	p.Row(2, 20)
+}
+
+func render(t *testing.T, f Format) string {
+	t.Helper()
+	var buf bytes.Buffer
+	p := New(&buf, f)
+	sample(p)
+	if err := p.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestFormats(t *testing.T) {
+	tests := map[Format]string{
+		Pretty: `=== Account ===
+Address     : 0x70997970C51812dc3A010C7d01b50e0d17dc79C8
+Balance     : 123456789012345678901234567890
+not in structured output
+
+=== Account ===
+Address     : 0x0000000000000000000000000000000000000000
+Balance     : 0
+
+=== Blocks ===
+Number  Txs
+1       10
+2       20
+`,
+		JSON: `{
+  "Account": [
+    {
+      "Address": "0x70997970c51812dc3a010c7d01b50e0d17dc79c8",
+      "Balance": 123456789012345678901234567890
+    },
+    {
+      "Address": "0x0000000000000000000000000000000000000000",
+      "Balance": 0
+    }
+  ],
+  "Blocks": [
+    {
+      "Number": 1,
+      "Txs": 10
+    },
+    {
+      "Number": 2,
+      "Txs": 20
+    }
+  ]
+}
+`,
+		// 超出 int64 的整数带显式 !!int 标签，避免被解析为浮点数
+		YAML: `Account:
+    - Address: 0x70997970c51812dc3a010c7d01b50e0d17dc79c8
+      Balance: !!int 123456789012345678901234567890
+    - Address: "0x0000000000000000000000000000000000000000"
+      Balance: 0
+Blocks:
+    - Number: 1
+      Txs: 10
+    - Number: 2
+      Txs: 20
+`,
+		CSV: `section,field,value
+Account,Address,0x70997970c51812dc3a010c7d01b50e0d17dc79c8
+Account,Balance,123456789012345678901234567890
+Account,Address,0x0000000000000000000000000000000000000000
+Account,Balance,0
+
+Number,Txs
+1,10
+2,20
+`,
+	}
+	for f, want := range tests {
+		if got := render(t, f); got != want {
+			t.Errorf("%s output:\n%s\nwant:\n%s", f, got, want)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	tests := map[Format]string{
+		Pretty: "",
+		JSON:   "{\"type\":\"block\",\"number\":1,\"hash\":\"0x01\"}\n{\"type\":\"block\",\"number\":2,\"hash\":\"0x02\"}\n{\"type\":\"reorg\",\"depth\":1}\n",
+		YAML:   "---\ntype: block\nnumber: 1\nhash: \"0x01\"\n---\ntype: block\nnumber: 2\nhash: \"0x02\"\n---\ntype: reorg\ndepth: 1\n",
+		CSV:    "type,number,hash\nblock,1,0x01\nblock,2,0x02\ntype,depth\nreorg,1\n",
+	}
+	for f, want := range tests {
+		var buf bytes.Buffer
+		p := New(&buf, f)
+		p.Record("block", "number", 1, "hash", "0x01")
+		p.Record("block", "number", 2, "hash", "0x02")
+		p.Record("reorg", "depth", 1)
+		if got := buf.String(); got != want {
+			t.Errorf("%s records:\n%q\nwant:\n%q", f, got, want)
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	for args, want := range map[string]Format{
+		"":              Pretty,
+		"--format yaml": YAML,
+		"--json":        JSON,
+		"--format=csv":  CSV,
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		p := AddFlags(fs)
+		if err := fs.Parse(strings.Fields(args)); err != nil {
+			t.Fatalf("Parse(%q): %v", args, err)
+		}
+		if p.Format != want {
+			t.Errorf("Parse(%q) format = %s, want %s", args, p.Format, want)
+		}
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&bytes.Buffer{})
+	AddFlags(fs)
+	if err := fs.Parse([]string{"--format", "xml"}); err == nil {
+		t.Error("Parse(--format xml) succeeded, want error")
+	}
+}
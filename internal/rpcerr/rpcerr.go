@@ -0,0 +1,195 @@
+// Package rpcerr 把节点（及不同服务商）返回的 JSON-RPC 错误归类为可用 errors.Is / errors.As 判断的 Go 错误，
+// 取代各示例中直接展示原始错误字符串或各自做字符串匹配的写法：
+//   - 分类依据依次是 HTTP 状态码、JSON-RPC 错误码和错误信息片段（geth / erigon / nethermind / besu 及常见服务商措辞）
+//   - Classify 返回的错误保留原错误：errors.Is(err, ErrNonceTooLow) 判断分类，原有的 rpc.Error 等仍能用 errors.As 取到
+//   - execution reverted 归类为 *RevertError，其中带有 revert 数据和解码出的 Error(string) / Panic(uint256) 原因
+//
+// 使用者：03 / 33 发送交易时给出可操作的提示，10-multi-node-pool 据此决定是否换节点重试以及广播时是否视为成功。
+package rpcerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 错误分类，配合 errors.Is 使用
+var (
+	// ErrRateLimited 服务商限流（HTTP 429、-32005 或对应措辞），稍后或换节点重试可能成功
+	ErrRateLimited = errors.New("rate limited")
+	// ErrNodeSyncing 节点仍在同步或落后于请求的区块（header not found 等），换节点或稍后重试可能成功
+	ErrNodeSyncing = errors.New("node is syncing")
+	// ErrNonceTooLow 该 nonce 已被使用（交易已上链），需要重新获取 nonce
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrReplacementUnderpriced 交易池中已有同 nonce 的交易，新交易的费用没有按要求提高（通常至少 10%）
+	ErrReplacementUnderpriced = errors.New("replacement transaction underpriced")
+	// ErrInsufficientFunds 余额不足以支付 value + gas 费用上限
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrAlreadyKnown 节点交易池中已经有这笔交易，对广播来说等同于成功
+	ErrAlreadyKnown = errors.New("transaction already known")
+	// ErrExecutionReverted 调用或交易执行被 revert，具体信息用 errors.As 取 *RevertError
+	ErrExecutionReverted = errors.New("execution reverted")
+)
+
+// JSON-RPC 错误码
+const (
+	codeExecutionReverted = 3
+	codeLimitExceeded     = -32005
+)
+
+// patterns 错误信息片段（小写）到分类的映射，按顺序匹配；
+// "nonce too low" 要在 "replacement" 之前，部分客户端的替换错误信息里也带有 nonce 字样
+var patterns = []struct {
+	kind      error
+	fragments []string
+}{
+	{ErrRateLimited, []string{"rate limit", "too many requests", "request rate exceeded", "exceeded its compute units", "daily request count exceeded"}},
+	{ErrNodeSyncing, []string{"header not found", "is syncing", "still syncing", "unknown block"}},
+	{ErrNonceTooLow, []string{"nonce too low", "nonce has already been used", "oldnonce"}},
+	{ErrReplacementUnderpriced, []string{"replacement transaction underpriced", "replacement underpriced", "replacementnotallowed"}},
+	{ErrInsufficientFunds, []string{"insufficient funds", "insufficient balance"}},
+	{ErrAlreadyKnown, []string{"already known", "known transaction", "already imported", "transaction already exists", "alreadyknown"}},
+}
+
+// Error 带分类的错误，Error() 与原错误相同
+type Error struct {
+	Kind error // 上面的 Err* 之一
+	Err  error // 原错误
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+// Unwrap 同时暴露分类和原错误
+func (e *Error) Unwrap() []error { return []error{e.Kind, e.Err} }
+
+// RevertError 执行被 revert：Data 为 revert 数据（可能为空），Reason 为解码出的原因（无法解码时为空）
+type RevertError struct {
+	Reason string
+	Data   []byte
+	Err    error // 原错误
+}
+
+func (e *RevertError) Error() string {
+	switch {
+	case e.Reason != "":
+		return "execution reverted: " + e.Reason
+	case len(e.Data) > 0:
+		return "execution reverted with data " + hexutil.Encode(e.Data)
+	}
+	return "execution reverted"
+}
+
+func (e *RevertError) Is(target error) bool { return target == ErrExecutionReverted }
+func (e *RevertError) Unwrap() error        { return e.Err }
+
+// Selector revert 数据的前 4 字节（自定义错误的选择器），数据不足 4 字节时为零值
+func (e *RevertError) Selector() (sel [4]byte) {
+	if len(e.Data) >= 4 {
+		copy(sel[:], e.Data)
+	}
+	return sel
+}
+
+// Classify 识别 err 的分类并返回带分类的错误；无法识别（或 err 为 nil、已经分类过）时原样返回
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var (
+		classified *Error
+		revert     *RevertError
+	)
+	if errors.As(err, &classified) || errors.As(err, &revert) {
+		return err
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return &Error{Kind: ErrRateLimited, Err: err}
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.ErrorCode() {
+		case codeLimitExceeded:
+			return &Error{Kind: ErrRateLimited, Err: err}
+		case codeExecutionReverted:
+			return newRevertError(err)
+		}
+	}
+
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "execution reverted") || strings.Contains(msg, "vm execution error") {
+		return newRevertError(err)
+	}
+	for _, p := range patterns {
+		for _, f := range p.fragments {
+			if strings.Contains(msg, f) {
+				return &Error{Kind: p.kind, Err: err}
+			}
+		}
+	}
+	return err
+}
+
+// Temporary 是否是换节点或稍后重试可能成功的错误（限流、节点同步中）
+func Temporary(err error) bool {
+	err = Classify(err)
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrNodeSyncing)
+}
+
+// newRevertError 从 rpc.DataError 中取出 revert 数据并尝试解码原因
+func newRevertError(err error) *RevertError {
+	e := &RevertError{Err: err}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		switch d := dataErr.ErrorData().(type) {
+		case string:
+			e.Data, _ = hexutil.Decode(d)
+		case []byte:
+			e.Data = d
+		}
+	}
+	if len(e.Data) > 0 {
+		if reason, uerr := abi.UnpackRevert(e.Data); uerr == nil {
+			e.Reason = reason
+		}
+	} else if _, reason, ok := strings.Cut(err.Error(), "execution reverted: "); ok {
+		// 没有 revert 数据时部分节点把原因直接写在错误信息里
+		e.Reason = reason
+	}
+	return e
+}
+
+// Hint 给最终用户的处理建议，无法识别的错误返回空字符串
+func Hint(err error) string {
+	err = Classify(err)
+	var revert *RevertError
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return "the node is rate limiting requests; wait and retry, lower the request rate or use another endpoint"
+	case errors.Is(err, ErrNodeSyncing):
+		return "the node has not caught up to the requested block; retry shortly or use another endpoint"
+	case errors.Is(err, ErrNonceTooLow):
+		return "a transaction with this nonce is already mined; resend so the next nonce is picked up"
+	case errors.Is(err, ErrReplacementUnderpriced):
+		return "a pending transaction uses the same nonce; raise both fee caps by at least 10% to replace it, or wait for it to be mined"
+	case errors.Is(err, ErrInsufficientFunds):
+		return "the sender cannot cover value + gas limit × fee cap; fund the account or lower the value / fees"
+	case errors.Is(err, ErrAlreadyKnown):
+		return "the node already has this transaction; wait for it to be mined"
+	case errors.As(err, &revert):
+		if revert.Reason != "" {
+			return fmt.Sprintf("the contract rejected the call: %s", revert.Reason)
+		}
+		if len(revert.Data) >= 4 {
+			return fmt.Sprintf("the contract rejected the call with custom error %s", hexutil.Encode(revert.Data[:4]))
+		}
+		return "the contract rejected the call without a reason"
+	}
+	return ""
+}
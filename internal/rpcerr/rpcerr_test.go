@@ -0,0 +1,117 @@
+package rpcerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// jsonErr 模拟节点返回的 JSON-RPC 错误（rpc.Error + rpc.DataError）
+type jsonErr struct {
+	code int
+	msg  string
+	data any
+}
+
+func (e *jsonErr) Error() string  { return e.msg }
+func (e *jsonErr) ErrorCode() int { return e.code }
+func (e *jsonErr) ErrorData() any { return e.data }
+
+var (
+	_ rpc.Error     = (*jsonErr)(nil)
+	_ rpc.DataError = (*jsonErr)(nil)
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"http 429", rpc.HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}, ErrRateLimited},
+		{"limit exceeded code", &jsonErr{code: -32005, msg: "limit exceeded"}, ErrRateLimited},
+		{"infura wording", &jsonErr{code: -32000, msg: "project ID request rate exceeded"}, ErrRateLimited},
+		{"header not found", &jsonErr{code: -32000, msg: "header not found"}, ErrNodeSyncing},
+		{"geth nonce too low", &jsonErr{code: -32000, msg: "nonce too low: address 0xabc, tx: 5 state: 7"}, ErrNonceTooLow},
+		{"nethermind nonce", &jsonErr{code: -32010, msg: "OldNonce, Current nonce: 7, nonce of rejected tx: 5"}, ErrNonceTooLow},
+		{"replacement", &jsonErr{code: -32000, msg: "replacement transaction underpriced"}, ErrReplacementUnderpriced},
+		{"insufficient funds", &jsonErr{code: -32000, msg: "insufficient funds for gas * price + value: balance 1, tx cost 2"}, ErrInsufficientFunds},
+		{"already known", &jsonErr{code: -32000, msg: "already known"}, ErrAlreadyKnown},
+		{"wrapped", fmt.Errorf("failed to send transaction: %w", &jsonErr{code: -32000, msg: "nonce too low"}), ErrNonceTooLow},
+		{"revert code", &jsonErr{code: 3, msg: "execution reverted"}, ErrExecutionReverted},
+		{"revert message", errors.New("execution reverted: Ownable: caller is not the owner"), ErrExecutionReverted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.err)
+			if !errors.Is(got, tt.want) {
+				t.Errorf("Classify(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	// 分类后原错误仍可取到
+	orig := &jsonErr{code: -32000, msg: "nonce too low"}
+	var got *jsonErr
+	if !errors.As(Classify(orig), &got) || got != orig {
+		t.Error("Classify lost the original error")
+	}
+
+	plain := errors.New("method not found")
+	if got := Classify(plain); got != plain {
+		t.Errorf("unknown error rewrapped: %v", got)
+	}
+	if Classify(nil) != nil {
+		t.Error("Classify(nil) != nil")
+	}
+}
+
+func TestRevertError(t *testing.T) {
+	// Error(string) "not owner"
+	reasonData := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000009" +
+		"6e6f74206f776e65720000000000000000000000000000000000000000000000"
+	tests := []struct {
+		name       string
+		err        error
+		wantReason string
+		wantSel    string
+	}{
+		{"error string", &jsonErr{code: 3, msg: "execution reverted", data: reasonData}, "not owner", "08c379a0"},
+		{"custom error", &jsonErr{code: 3, msg: "execution reverted", data: "0x82b42900"}, "", "82b42900"},
+		{"reason in message", errors.New("execution reverted: paused"), "paused", "00000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var revert *RevertError
+			if !errors.As(Classify(tt.err), &revert) {
+				t.Fatalf("Classify(%v) is not a *RevertError", tt.err)
+			}
+			if revert.Reason != tt.wantReason {
+				t.Errorf("Reason = %q, want %q", revert.Reason, tt.wantReason)
+			}
+			if sel := revert.Selector(); fmt.Sprintf("%x", sel) != tt.wantSel {
+				t.Errorf("Selector = %x, want %s", sel, tt.wantSel)
+			}
+		})
+	}
+}
+
+func TestTemporaryAndHint(t *testing.T) {
+	if !Temporary(&jsonErr{code: -32005, msg: "limit exceeded"}) {
+		t.Error("rate limit should be temporary")
+	}
+	if Temporary(&jsonErr{code: -32000, msg: "nonce too low"}) {
+		t.Error("nonce too low should not be temporary")
+	}
+	if Hint(&jsonErr{code: -32000, msg: "replacement transaction underpriced"}) == "" {
+		t.Error("missing hint for replacement underpriced")
+	}
+	if Hint(errors.New("boom")) != "" {
+		t.Error("unexpected hint for unknown error")
+	}
+}
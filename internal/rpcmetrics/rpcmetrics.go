@@ -0,0 +1,287 @@
+// Package rpcmetrics 按 JSON-RPC 方法统计请求数、错误数和延迟，并以 Prometheus 文本格式输出，
+// 格式与 10-multi-node-pool 的节点指标一致，同样不引入 client_golang 依赖：
+//   - HTTP(S) 节点：rpcretry.Dial 的传输层经过 Default.Transport，按请求体中的 method 计数（批量请求逐条计入），
+//     按 HTTP 状态和响应中的 error 字段区分成功 / 失败；rpcretry 的每次重试单独计入
+//   - WebSocket / IPC 连接和 10 的连接池没有可以拦截的传输层，由调用方调用 Observe
+//   - 开启方式：设置 METRICS_ADDR 后在 main 中 defer rpcmetrics.ServeFromEnv()()，
+//     已有 HTTP 服务的示例（09）挂载 rpcmetrics.Handler() 即可
+//
+// 解析请求 / 响应体有额外开销，传输层只在 ServeFromEnv / Handler / Enable 之后才记录。
+package rpcmetrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets 延迟直方图的桶上界（秒），与 10-multi-node-pool 相同
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default 各示例共用的记录器，指标名前缀为 ethrpc
+var Default = New("ethrpc")
+
+// methodMetrics 单个方法的累计指标
+type methodMetrics struct {
+	requests uint64
+	errors   uint64
+	buckets  []uint64 // 与 latencyBuckets 一一对应，非累计
+	sum      float64  // 延迟总和（秒）
+}
+
+// Recorder 按方法归档的指标
+type Recorder struct {
+	prefix  string
+	enabled atomic.Bool
+
+	mu      sync.Mutex
+	methods map[string]*methodMetrics
+}
+
+// New 创建记录器，prefix 为指标名前缀
+func New(prefix string) *Recorder {
+	return &Recorder{prefix: prefix, methods: make(map[string]*methodMetrics)}
+}
+
+// Enable 开启传输层记录（Observe 不受影响）
+func (r *Recorder) Enable() { r.enabled.Store(true) }
+
+// Observe 记录一次调用的耗时和结果
+func (r *Recorder) Observe(method string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mm, ok := r.methods[method]
+	if !ok {
+		mm = &methodMetrics{buckets: make([]uint64, len(latencyBuckets))}
+		r.methods[method] = mm
+	}
+	mm.requests++
+	if err != nil {
+		mm.errors++
+	}
+	sec := d.Seconds()
+	mm.sum += sec
+	for i, le := range latencyBuckets {
+		if sec <= le {
+			mm.buckets[i]++
+			break
+		}
+	}
+}
+
+// MethodStats 单个方法的指标快照
+type MethodStats struct {
+	Method   string
+	Requests uint64
+	Errors   uint64
+	AvgMs    float64
+
+	buckets []uint64
+	sum     float64
+}
+
+// Snapshot 返回各方法的指标快照，按方法名排序
+func (r *Recorder) Snapshot() []MethodStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]MethodStats, 0, len(r.methods))
+	for name, mm := range r.methods {
+		s := MethodStats{
+			Method:   name,
+			Requests: mm.requests,
+			Errors:   mm.errors,
+			buckets:  append([]uint64(nil), mm.buckets...),
+			sum:      mm.sum,
+		}
+		if mm.requests > 0 {
+			s.AvgMs = mm.sum / float64(mm.requests) * 1000
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Method < out[j].Method })
+	return out
+}
+
+// WriteTo 以 Prometheus 文本格式写出指标，可以拼接在其他指标之后（见 10 的 MetricsHandler）
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	snap := r.Snapshot()
+
+	var b strings.Builder
+	writeHeader := func(name, typ, help string) {
+		fmt.Fprintf(&b, "# HELP %s_%s %s\n# TYPE %s_%s %s\n", r.prefix, name, help, r.prefix, name, typ)
+	}
+	label := func(s MethodStats) string {
+		return "method=" + strconv.Quote(s.Method)
+	}
+
+	writeHeader("requests_total", "counter", "Total JSON-RPC requests per method.")
+	for _, s := range snap {
+		fmt.Fprintf(&b, "%s_requests_total{%s} %d\n", r.prefix, label(s), s.Requests)
+	}
+
+	writeHeader("errors_total", "counter", "Total JSON-RPC requests per method that failed (transport error, HTTP error status or JSON-RPC error).")
+	for _, s := range snap {
+		fmt.Fprintf(&b, "%s_errors_total{%s} %d\n", r.prefix, label(s), s.Errors)
+	}
+
+	writeHeader("request_duration_seconds", "histogram", "JSON-RPC request latency per method.")
+	for _, s := range snap {
+		var cum uint64
+		for i, le := range latencyBuckets {
+			cum += s.buckets[i]
+			fmt.Fprintf(&b, "%s_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				r.prefix, label(s), strconv.FormatFloat(le, 'g', -1, 64), cum)
+		}
+		fmt.Fprintf(&b, "%s_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", r.prefix, label(s), s.Requests)
+		fmt.Fprintf(&b, "%s_request_duration_seconds_sum{%s} %g\n", r.prefix, label(s), s.sum)
+		fmt.Fprintf(&b, "%s_request_duration_seconds_count{%s} %d\n", r.prefix, label(s), s.Requests)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP 输出 Prometheus 文本格式的指标
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = r.WriteTo(w)
+}
+
+// Handler 开启 Default 的记录并返回输出其指标的 handler，用于挂载到示例已有的 HTTP 服务上
+func Handler() http.Handler {
+	Default.Enable()
+	return Default
+}
+
+// ServeFromEnv 设置了 METRICS_ADDR 时开启 Default 的记录，并在该地址提供 /metrics；
+// 返回关闭服务的函数，未设置时什么也不做。用法：defer rpcmetrics.ServeFromEnv()()
+func ServeFromEnv() (stop func()) {
+	addr := os.Getenv("METRICS_ADDR")
+	if addr == "" {
+		return func() {}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		log.Printf("[INFO] metrics listening on %s/metrics", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("metrics server failed: %v", err)
+		}
+	}()
+	return func() { server.Close() }
+}
+
+// Transport 返回记录每个 JSON-RPC 请求的 http.RoundTripper，base 为 nil 时使用 http.DefaultTransport
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, rec: r}
+}
+
+type transport struct {
+	base http.RoundTripper
+	rec  *Recorder
+}
+
+// rpcMessage 请求和响应中用到的字段
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Error  json.RawMessage `json:"error"`
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.rec.enabled.Load() || req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	calls := decodeMessages(body)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		t.observeAll(calls, time.Since(start), err)
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.observeAll(calls, time.Since(start), fmt.Errorf("%s", resp.Status))
+		return resp, nil
+	}
+
+	// 读完响应体才算请求结束；读入内存后交还给调用方
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	elapsed := time.Since(start)
+	if err != nil {
+		t.observeAll(calls, elapsed, err)
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	failed := make(map[string]bool)
+	for _, m := range decodeMessages(respBody) {
+		if len(m.Error) > 0 && string(m.Error) != "null" {
+			failed[string(m.ID)] = true
+		}
+	}
+	for _, c := range calls {
+		var callErr error
+		// 单个请求的响应 id 与请求相同；只有一个请求时不必依赖 id 匹配
+		if failed[string(c.ID)] || (len(calls) == 1 && len(failed) > 0) {
+			callErr = errRPC
+		}
+		t.rec.Observe(c.Method, elapsed, callErr)
+	}
+	return resp, nil
+}
+
+// errRPC 响应中带有 JSON-RPC error 字段
+var errRPC = errors.New("json-rpc error response")
+
+func (t *transport) observeAll(calls []rpcMessage, d time.Duration, err error) {
+	for _, c := range calls {
+		t.rec.Observe(c.Method, d, err)
+	}
+}
+
+// decodeMessages 解析单个或批量的 JSON-RPC 消息，无法解析时返回 nil
+func decodeMessages(b []byte) []rpcMessage {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil
+	}
+	if b[0] == '[' {
+		var batch []rpcMessage
+		if json.Unmarshal(b, &batch) != nil {
+			return nil
+		}
+		return batch
+	}
+	var m rpcMessage
+	if json.Unmarshal(b, &m) != nil {
+		return nil
+	}
+	return []rpcMessage{m}
+}
@@ -0,0 +1,128 @@
+package rpcmetrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newNode eth_blockNumber 正常返回，其余方法返回 JSON-RPC 错误
+func newNode(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var out []string
+		for _, m := range decodeMessages(body) {
+			if m.Method == "eth_blockNumber" {
+				out = append(out, fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":"0x10"}`, m.ID))
+			} else {
+				out = append(out, fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"method not found"}}`, m.ID))
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+			fmt.Fprint(w, "["+strings.Join(out, ",")+"]")
+		} else {
+			fmt.Fprint(w, out[0])
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dial(t *testing.T, rec *Recorder, url string) *rpc.Client {
+	t.Helper()
+	c, err := rpc.DialOptions(context.Background(), url, rpc.WithHTTPClient(&http.Client{Transport: rec.Transport(nil)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func stats(rec *Recorder) map[string]MethodStats {
+	m := make(map[string]MethodStats)
+	for _, s := range rec.Snapshot() {
+		m[s.Method] = s
+	}
+	return m
+}
+
+func TestTransport(t *testing.T) {
+	srv := newNode(t)
+	rec := New("test")
+	c := dial(t, rec, srv.URL)
+	ctx := context.Background()
+
+	// 未开启时不记录
+	var n string
+	if err := c.CallContext(ctx, &n, "eth_blockNumber"); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(rec.Snapshot()); got != 0 {
+		t.Fatalf("recorded %d methods before Enable", got)
+	}
+
+	rec.Enable()
+	for range 2 {
+		if err := c.CallContext(ctx, &n, "eth_blockNumber"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.CallContext(ctx, &n, "eth_foo"); err == nil {
+		t.Fatal("eth_foo succeeded")
+	}
+	batch := []rpc.BatchElem{
+		{Method: "eth_blockNumber", Result: new(string)},
+		{Method: "eth_bar", Result: new(string)},
+	}
+	if err := c.BatchCallContext(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+
+	got := stats(rec)
+	tests := []struct {
+		method   string
+		requests uint64
+		errors   uint64
+	}{
+		{"eth_blockNumber", 3, 0},
+		{"eth_foo", 1, 1},
+		{"eth_bar", 1, 1},
+	}
+	for _, tt := range tests {
+		s := got[tt.method]
+		if s.Requests != tt.requests || s.Errors != tt.errors {
+			t.Errorf("%s: requests=%d errors=%d, want %d/%d", tt.method, s.Requests, s.Errors, tt.requests, tt.errors)
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	rec := New("test")
+	rec.Observe("eth_call", 30*time.Millisecond, nil)
+	rec.Observe("eth_call", 2*time.Second, fmt.Errorf("boom"))
+
+	var b strings.Builder
+	if _, err := rec.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`test_requests_total{method="eth_call"} 2`,
+		`test_errors_total{method="eth_call"} 1`,
+		`test_request_duration_seconds_bucket{method="eth_call",le="0.05"} 1`,
+		`test_request_duration_seconds_bucket{method="eth_call",le="2.5"} 2`,
+		`test_request_duration_seconds_count{method="eth_call"} 2`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// mocknode 独立运行的模拟节点（internal/rpcmock），用于在没有真实节点时运行各示例：
+//
+//	go run ./rpcmock/cmd/mocknode --addr 127.0.0.1:8545 --block-time 2s
+//	export ETH_RPC_URL=http://127.0.0.1:8545 ETH_WS_URL=ws://127.0.0.1:8545
+//
+// 预置余额账户为 rpcmock.DevAddress（私钥 rpcmock.DevKey，即 Hardhat / Anvil 的 0 号账户，
+// 发送交易的示例可以设置 SENDER_PRIVATE_KEY 为启动日志中打印的私钥），--block-time 0 表示每收到一笔交易立即出块。
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/yzucdh1/examples/internal/rpcmock"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8545", "listen address (HTTP and WebSocket)")
+	blockTime := flag.Duration("block-time", 12*time.Second, "interval between blocks; 0 mines one block per transaction")
+	chainID := flag.Int64("chain-id", 1337, "chain ID")
+	flag.Parse()
+
+	cfg := rpcmock.DefaultConfig()
+	cfg.ChainID = big.NewInt(*chainID)
+	cfg.AutoMine = *blockTime == 0
+	node := rpcmock.New(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if *blockTime > 0 {
+		go node.MineEvery(*blockTime, ctx.Done())
+	}
+
+	server := &http.Server{Addr: *addr, Handler: node}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	log.Printf("[INFO] mock node listening on http://%s and ws://%s (chain ID %d)", *addr, *addr, *chainID)
+	log.Printf("[INFO] funded dev account %s, private key %s", rpcmock.DevAddress.Hex(), hexutil.Encode(rpcmock.DevKey.D.Bytes()))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("mock node failed: %v", err)
+	}
+}
@@ -0,0 +1,629 @@
+package rpcmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ethMethods 内置方法表
+func (n *Node) ethMethods() map[string]Handler {
+	return map[string]Handler{
+		"web3_clientVersion": func([]json.RawMessage) (any, error) { return "rpcmock/v1", nil },
+		"net_version":        func([]json.RawMessage) (any, error) { return n.cfg.ChainID.String(), nil },
+		"eth_chainId":        func([]json.RawMessage) (any, error) { return (*hexutil.Big)(n.cfg.ChainID), nil },
+		"eth_syncing":        func([]json.RawMessage) (any, error) { return false, nil },
+		"eth_blockNumber": func([]json.RawMessage) (any, error) {
+			return hexutil.Uint64(n.Head().Number.Uint64()), nil
+		},
+		"eth_gasPrice": func([]json.RawMessage) (any, error) {
+			return (*hexutil.Big)(new(big.Int).Add(n.cfg.BaseFee, n.cfg.Tip)), nil
+		},
+		"eth_maxPriorityFeePerGas": func([]json.RawMessage) (any, error) { return (*hexutil.Big)(n.cfg.Tip), nil },
+		"eth_feeHistory":           n.feeHistory,
+
+		"eth_getBlockByNumber": func(p []json.RawMessage) (any, error) { return n.getBlock(p, false) },
+		"eth_getBlockByHash":   func(p []json.RawMessage) (any, error) { return n.getBlock(p, true) },
+		"eth_getBlockTransactionCountByNumber": func(p []json.RawMessage) (any, error) {
+			return n.blockTxCount(p, false)
+		},
+		"eth_getBlockTransactionCountByHash": func(p []json.RawMessage) (any, error) {
+			return n.blockTxCount(p, true)
+		},
+		"eth_getBlockReceipts": n.getBlockReceipts,
+
+		"eth_getBalance":          n.getBalance,
+		"eth_getTransactionCount": n.getTransactionCount,
+		"eth_getCode":             n.getCode,
+		"eth_getStorageAt": func([]json.RawMessage) (any, error) {
+			return hexutil.Bytes(common.Hash{}.Bytes()), nil
+		},
+		"eth_call":        func([]json.RawMessage) (any, error) { return hexutil.Bytes{}, nil },
+		"eth_estimateGas": n.estimateGas,
+
+		"eth_sendRawTransaction":    n.sendRawTransaction,
+		"eth_getTransactionByHash":  n.getTransactionByHash,
+		"eth_getTransactionReceipt": n.getTransactionReceipt,
+		"eth_getLogs":               n.getLogs,
+	}
+}
+
+// arg 把 params[i] 解码到 dst，参数缺省或为 null 时不修改 dst
+func arg(params []json.RawMessage, i int, dst any) error {
+	if i >= len(params) || string(params[i]) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params[i], dst); err != nil {
+		return &Error{Code: -32602, Message: fmt.Sprintf("invalid argument %d: %v", i, err)}
+	}
+	return nil
+}
+
+// blockByTagLocked 按区块号或标签查找规范链上的区块，超出链头时返回 nil
+func (n *Node) blockByTagLocked(tag string) (*block, error) {
+	head := uint64(len(n.blocks) - 1)
+	var number uint64
+	switch tag {
+	case "", "latest", "pending":
+		number = head
+	case "earliest":
+		number = 0
+	case "safe":
+		number = head - min(head, safeDepth)
+	case "finalized":
+		number = head - min(head, finalDepth)
+	default:
+		v, err := hexutil.DecodeUint64(tag)
+		if err != nil {
+			return nil, &Error{Code: -32602, Message: fmt.Sprintf("invalid block number %q: %v", tag, err)}
+		}
+		number = v
+	}
+	if number > head {
+		return nil, nil
+	}
+	return n.blocks[number], nil
+}
+
+// blockByHashLocked 按哈希查找规范链上的区块
+func (n *Node) blockByHashLocked(hash common.Hash) *block {
+	for _, b := range n.blocks {
+		if b.header.Hash() == hash {
+			return b
+		}
+	}
+	return nil
+}
+
+// lookupBlockLocked 按 params[0]（哈希或区块号 / 标签）查找区块
+func (n *Node) lookupBlockLocked(p []json.RawMessage, byHash bool) (*block, error) {
+	if byHash {
+		var hash common.Hash
+		if err := arg(p, 0, &hash); err != nil {
+			return nil, err
+		}
+		return n.blockByHashLocked(hash), nil
+	}
+	var tag string
+	if err := arg(p, 0, &tag); err != nil {
+		return nil, err
+	}
+	return n.blockByTagLocked(tag)
+}
+
+func (n *Node) getBlock(p []json.RawMessage, byHash bool) (any, error) {
+	var full bool
+	if err := arg(p, 1, &full); err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	b, err := n.lookupBlockLocked(p, byHash)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return n.blockJSON(b, full), nil
+}
+
+func (n *Node) blockTxCount(p []json.RawMessage, byHash bool) (any, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	b, err := n.lookupBlockLocked(p, byHash)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return hexutil.Uint(len(b.txs)), nil
+}
+
+func (n *Node) getBlockReceipts(p []json.RawMessage) (any, error) {
+	var raw string
+	if err := arg(p, 0, &raw); err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var (
+		b   *block
+		err error
+	)
+	if len(raw) == 66 {
+		b = n.blockByHashLocked(common.HexToHash(raw))
+	} else {
+		b, err = n.blockByTagLocked(raw)
+	}
+	if err != nil || b == nil {
+		return nil, err
+	}
+	out := make([]map[string]any, len(b.txs))
+	for i := range b.txs {
+		out[i] = receiptJSON(b, i)
+	}
+	return out, nil
+}
+
+func (n *Node) getBalance(p []json.RawMessage) (any, error) {
+	var addr common.Address
+	if err := arg(p, 0, &addr); err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(n.Balance(addr)), nil
+}
+
+// getTransactionCount 区块参数为 "pending" 时计入交易池中 nonce 连续的交易
+func (n *Node) getTransactionCount(p []json.RawMessage) (any, error) {
+	var (
+		addr common.Address
+		tag  string
+	)
+	if err := arg(p, 0, &addr); err != nil {
+		return nil, err
+	}
+	if err := arg(p, 1, &tag); err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if tag == "pending" {
+		return hexutil.Uint64(n.pendingNonceLocked(addr)), nil
+	}
+	return hexutil.Uint64(n.nonces[addr]), nil
+}
+
+func (n *Node) getCode(p []json.RawMessage) (any, error) {
+	var addr common.Address
+	if err := arg(p, 0, &addr); err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return hexutil.Bytes(n.codes[addr]), nil
+}
+
+// callArgs eth_call / eth_estimateGas 的调用参数中用到的字段
+type callArgs struct {
+	To    *common.Address `json:"to"`
+	Data  hexutil.Bytes   `json:"data"`
+	Input hexutil.Bytes   `json:"input"`
+}
+
+// estimateGas 普通转账 21000；带数据或创建合约时按 calldata 长度粗略估算
+func (n *Node) estimateGas(p []json.RawMessage) (any, error) {
+	var args callArgs
+	if err := arg(p, 0, &args); err != nil {
+		return nil, err
+	}
+	data := args.Input
+	if len(data) == 0 {
+		data = args.Data
+	}
+	if len(data) == 0 && args.To != nil {
+		return hexutil.Uint64(params.TxGas), nil
+	}
+	return hexutil.Uint64(params.TxGas + 16*uint64(len(data)) + 50_000), nil
+}
+
+// feeHistory 每个区块的 base fee 相同，各百分位的小费都是 Config.Tip
+func (n *Node) feeHistory(p []json.RawMessage) (any, error) {
+	var (
+		count       hexutil.Uint64
+		newest      string
+		percentiles []float64
+	)
+	// 区块数可能是十六进制字符串，也可能是数字
+	if len(p) > 0 && json.Unmarshal(p[0], &count) != nil {
+		var plain uint64
+		if err := arg(p, 0, &plain); err != nil {
+			return nil, err
+		}
+		count = hexutil.Uint64(plain)
+	}
+	if err := arg(p, 1, &newest); err != nil {
+		return nil, err
+	}
+	if err := arg(p, 2, &percentiles); err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	last, err := n.blockByTagLocked(newest)
+	if err != nil {
+		return nil, err
+	}
+	if last == nil {
+		return nil, &Error{Code: -32000, Message: "request beyond head block"}
+	}
+	end := last.header.Number.Uint64()
+	count = min(count, hexutil.Uint64(end+1))
+	oldest := end + 1 - uint64(count)
+
+	result := map[string]any{"oldestBlock": (*hexutil.Big)(new(big.Int).SetUint64(oldest))}
+	var (
+		baseFees = make([]*hexutil.Big, 0, count+1)
+		ratios   = make([]float64, 0, count)
+		rewards  = make([][]*hexutil.Big, 0, count)
+	)
+	for num := oldest; num <= end; num++ {
+		h := n.blocks[num].header
+		baseFees = append(baseFees, (*hexutil.Big)(h.BaseFee))
+		ratios = append(ratios, float64(h.GasUsed)/float64(h.GasLimit))
+		tips := make([]*hexutil.Big, len(percentiles))
+		for i := range tips {
+			tips[i] = (*hexutil.Big)(n.cfg.Tip)
+		}
+		rewards = append(rewards, tips)
+	}
+	baseFees = append(baseFees, (*hexutil.Big)(n.cfg.BaseFee))
+	result["baseFeePerGas"] = baseFees
+	result["gasUsedRatio"] = ratios
+	if len(percentiles) > 0 {
+		result["reward"] = rewards
+	}
+	return result, nil
+}
+
+func (n *Node) sendRawTransaction(p []json.RawMessage) (any, error) {
+	var raw hexutil.Bytes
+	if err := arg(p, 0, &raw); err != nil {
+		return nil, err
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, &Error{Code: -32000, Message: "rlp: " + err.Error()}
+	}
+	if err := n.SubmitTransaction(tx); err != nil {
+		return nil, err
+	}
+	return tx.Hash(), nil
+}
+
+func (n *Node) getTransactionByHash(p []json.RawMessage) (any, error) {
+	var hash common.Hash
+	if err := arg(p, 0, &hash); err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if loc, ok := n.mined[hash]; ok {
+		return txJSON(loc.block.txs[loc.index], loc.block.senders[loc.index], loc.block, loc.index), nil
+	}
+	for _, tx := range n.pending {
+		if tx.Hash() == hash {
+			from, _ := types.Sender(n.signer, tx)
+			return txJSON(tx, from, nil, 0), nil
+		}
+	}
+	return nil, nil
+}
+
+func (n *Node) getTransactionReceipt(p []json.RawMessage) (any, error) {
+	var hash common.Hash
+	if err := arg(p, 0, &hash); err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	loc, ok := n.mined[hash]
+	if !ok {
+		return nil, nil
+	}
+	return receiptJSON(loc.block, loc.index), nil
+}
+
+// filterArgs eth_getLogs / logs 订阅的过滤条件
+type filterArgs struct {
+	FromBlock string            `json:"fromBlock"`
+	ToBlock   string            `json:"toBlock"`
+	BlockHash *common.Hash      `json:"blockHash"`
+	Address   json.RawMessage   `json:"address"`
+	Topics    []json.RawMessage `json:"topics"`
+}
+
+// logFilter 解析后的地址和主题条件，空集合表示不限
+type logFilter struct {
+	addresses []common.Address
+	topics    [][]common.Hash
+}
+
+// parse 地址可以是单个或数组；每个主题位置可以是 null、单个哈希或哈希数组
+func (f filterArgs) parse() (logFilter, error) {
+	var out logFilter
+	if len(f.Address) > 0 && string(f.Address) != "null" {
+		if f.Address[0] == '[' {
+			if err := json.Unmarshal(f.Address, &out.addresses); err != nil {
+				return out, &Error{Code: -32602, Message: "invalid address: " + err.Error()}
+			}
+		} else {
+			var addr common.Address
+			if err := json.Unmarshal(f.Address, &addr); err != nil {
+				return out, &Error{Code: -32602, Message: "invalid address: " + err.Error()}
+			}
+			out.addresses = []common.Address{addr}
+		}
+	}
+	for _, raw := range f.Topics {
+		var set []common.Hash
+		switch {
+		case len(raw) == 0 || string(raw) == "null":
+		case raw[0] == '[':
+			if err := json.Unmarshal(raw, &set); err != nil {
+				return out, &Error{Code: -32602, Message: "invalid topic: " + err.Error()}
+			}
+		default:
+			var h common.Hash
+			if err := json.Unmarshal(raw, &h); err != nil {
+				return out, &Error{Code: -32602, Message: "invalid topic: " + err.Error()}
+			}
+			set = []common.Hash{h}
+		}
+		out.topics = append(out.topics, set)
+	}
+	return out, nil
+}
+
+func (f logFilter) match(l *types.Log) bool {
+	if len(f.addresses) > 0 && !slices.Contains(f.addresses, l.Address) {
+		return false
+	}
+	if len(f.topics) > len(l.Topics) {
+		return false
+	}
+	for i, set := range f.topics {
+		if len(set) > 0 && !slices.Contains(set, l.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *Node) getLogs(p []json.RawMessage) (any, error) {
+	var args filterArgs
+	if err := arg(p, 0, &args); err != nil {
+		return nil, err
+	}
+	filter, err := args.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var blocks []*block
+	if args.BlockHash != nil {
+		if b := n.blockByHashLocked(*args.BlockHash); b != nil {
+			blocks = []*block{b}
+		}
+	} else {
+		from, err := n.blockByTagLocked(args.FromBlock)
+		if err != nil {
+			return nil, err
+		}
+		to, err := n.blockByTagLocked(args.ToBlock)
+		if err != nil {
+			return nil, err
+		}
+		if to == nil {
+			to = n.head()
+		}
+		if from != nil && from.header.Number.Cmp(to.header.Number) <= 0 {
+			blocks = n.blocks[from.header.Number.Uint64() : to.header.Number.Uint64()+1]
+		}
+	}
+	out := []*types.Log{}
+	for _, b := range blocks {
+		for _, l := range b.logs {
+			if filter.match(l) {
+				out = append(out, l)
+			}
+		}
+	}
+	return out, nil
+}
+
+// blockJSON 区块头字段加上交易（哈希或完整交易）等区块体字段
+func (n *Node) blockJSON(b *block, full bool) map[string]any {
+	m := toMap(b.header)
+	var txs []any
+	for i, tx := range b.txs {
+		if full {
+			txs = append(txs, txJSON(tx, b.senders[i], b, i))
+		} else {
+			txs = append(txs, tx.Hash())
+		}
+	}
+	if txs == nil {
+		txs = []any{}
+	}
+	m["transactions"] = txs
+	m["uncles"] = []common.Hash{}
+	m["totalDifficulty"] = "0x0"
+	m["size"] = hexutil.Uint64(uint64(b.header.Size()))
+	return m
+}
+
+// txJSON 交易字段加上 from 和所在区块（b 为 nil 表示待打包）
+func txJSON(tx *types.Transaction, from common.Address, b *block, index int) map[string]any {
+	m := toMap(tx)
+	m["from"] = from
+	m["blockHash"], m["blockNumber"], m["transactionIndex"] = nil, nil, nil
+	if b != nil {
+		m["blockHash"] = b.header.Hash()
+		m["blockNumber"] = (*hexutil.Big)(b.header.Number)
+		m["transactionIndex"] = hexutil.Uint64(index)
+	}
+	return m
+}
+
+// receiptJSON 回执字段加上 from / to
+func receiptJSON(b *block, index int) map[string]any {
+	m := toMap(b.receipts[index])
+	m["from"] = b.senders[index]
+	m["to"] = b.txs[index].To()
+	return m
+}
+
+// toMap 用 v 自己的 JSON 编码转成 map，便于补充字段
+func toMap(v any) map[string]any {
+	var m map[string]any
+	if err := json.Unmarshal(mustJSON(v), &m); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// subscription 一个 WebSocket 订阅
+type subscription struct {
+	id     string
+	kind   string // newHeads / logs / newPendingTransactions
+	conn   *wsConn
+	filter logFilter
+	fullTx bool // newPendingTransactions 推送完整交易
+}
+
+// notification 待推送的订阅消息
+type notification struct {
+	conn   *wsConn
+	sub    string
+	result any
+}
+
+func (n *Node) subscribe(p []json.RawMessage, conn *wsConn) (any, error) {
+	if conn == nil {
+		return nil, &Error{Code: -32601, Message: "notifications not supported"}
+	}
+	var kind string
+	if err := arg(p, 0, &kind); err != nil {
+		return nil, err
+	}
+	sub := &subscription{kind: kind, conn: conn}
+	switch kind {
+	case "newHeads":
+	case "logs":
+		var args filterArgs
+		if err := arg(p, 1, &args); err != nil {
+			return nil, err
+		}
+		filter, err := args.parse()
+		if err != nil {
+			return nil, err
+		}
+		sub.filter = filter
+	case "newPendingTransactions":
+		if err := arg(p, 1, &sub.fullTx); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("no %q subscription in eth namespace", kind)}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.nextSub++
+	sub.id = "0x" + strconv.FormatUint(n.nextSub, 16)
+	n.subs[sub.id] = sub
+	return sub.id, nil
+}
+
+func (n *Node) unsubscribe(p []json.RawMessage, conn *wsConn) (any, error) {
+	var id string
+	if err := arg(p, 0, &id); err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sub, ok := n.subs[id]
+	if !ok || sub.conn != conn {
+		return false, nil
+	}
+	delete(n.subs, id)
+	return true, nil
+}
+
+// dropSubscriptions 连接断开时清理它的订阅
+func (n *Node) dropSubscriptions(conn *wsConn) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for id, sub := range n.subs {
+		if sub.conn == conn {
+			delete(n.subs, id)
+		}
+	}
+}
+
+// blockNotificationsLocked 新区块对应的 newHeads / logs 推送；removed 为 true 时只推送标记为移除的日志
+func (n *Node) blockNotificationsLocked(b *block, removed bool) []notification {
+	var out []notification
+	for _, sub := range n.subs {
+		switch sub.kind {
+		case "newHeads":
+			if !removed {
+				out = append(out, notification{conn: sub.conn, sub: sub.id, result: b.header})
+			}
+		case "logs":
+			for _, l := range b.logs {
+				if sub.filter.match(l) {
+					cp := *l
+					cp.Removed = removed
+					out = append(out, notification{conn: sub.conn, sub: sub.id, result: &cp})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// pendingNotificationsLocked 交易进入交易池时的 newPendingTransactions 推送
+func (n *Node) pendingNotificationsLocked(tx *types.Transaction) []notification {
+	var out []notification
+	for _, sub := range n.subs {
+		if sub.kind != "newPendingTransactions" {
+			continue
+		}
+		var result any = tx.Hash()
+		if sub.fullTx {
+			from, _ := types.Sender(n.signer, tx)
+			result = txJSON(tx, from, nil, 0)
+		}
+		out = append(out, notification{conn: sub.conn, sub: sub.id, result: result})
+	}
+	return out
+}
+
+// deliver 在锁外推送订阅消息，写失败（连接已断开）时忽略
+func (n *Node) deliver(notes []notification) {
+	for _, note := range notes {
+		msg := map[string]any{
+			"jsonrpc": "2.0",
+			"method":  "eth_subscription",
+			"params":  map[string]any{"subscription": note.sub, "result": note.result},
+		}
+		_ = note.conn.write(mustJSON(msg))
+	}
+}
@@ -0,0 +1,514 @@
+// Package rpcmock 是离线开发和测试用的模拟以太坊节点：按方法语义应答 JSON-RPC 请求，
+// 让各示例和测试不依赖真实节点即可运行：
+//   - 同一个 http.Handler 同时服务 HTTP（单个 / 批量请求）和 WebSocket（含 eth_subscribe 的 newHeads、logs、
+//     newPendingTransactions 订阅），测试中用 httptest.NewServer(node) 启动，WSURL 换成 ws:// 地址
+//   - 内存中的链：交易池、按 nonce 打包、余额和 nonce 记账、回执、日志；出块由 Mine / MineEvery 或 Config.AutoMine 驱动
+//   - eth_sendRawTransaction 按 geth 的措辞返回 nonce too low、replacement transaction underpriced、
+//     insufficient funds、already known 等错误，可以配合 internal/rpcerr 测试错误处理
+//   - 场景夹具：Reorg 重组最近的区块、RateLimit / FailNext 让后续请求返回 429 / 5xx、SendTransfer 制造待打包交易；
+//     Handle 可以替换任意方法的应答（例如给 eth_call 返回固定数据）
+//
+// 简化之处：状态只有余额和 nonce（不执行合约，eth_call 默认返回空数据），区块参数对状态查询无效，
+// 交易根 / 回执根不是真实的 MPT 根（只保证空与非空和 ethclient 的校验一致）。
+// 独立运行见 rpcmock/cmd/mocknode。
+package rpcmock
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// DevKey 预置余额的测试私钥（Hardhat / Anvil 的 0 号账户，公开已知，切勿在真实网络上使用）
+var DevKey = mustKey("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+
+// DevAddress DevKey 对应的地址
+var DevAddress = crypto.PubkeyToAddress(DevKey.PublicKey)
+
+// 出块参数
+const (
+	blockGasLimit = 30_000_000
+	blockInterval = 12 // 相邻区块的时间戳间隔（秒）
+	safeDepth     = 16 // "safe" 标签落后最新区块的块数
+	finalDepth    = 32 // "finalized" 标签落后最新区块的块数
+)
+
+// Config 模拟节点参数
+type Config struct {
+	ChainID *big.Int
+	// BaseFee 每个区块的 base fee（固定不变）
+	BaseFee *big.Int
+	// Tip eth_maxPriorityFeePerGas 和 eth_feeHistory 给出的小费
+	Tip *big.Int
+	// AutoMine 为 true 时每收到一笔交易立即出块
+	AutoMine bool
+	// Balances 初始余额
+	Balances map[common.Address]*big.Int
+}
+
+// DefaultConfig 默认参数：链 ID 1337，base fee 1 gwei，小费 1 gwei，DevAddress 预置 1000 ETH，不自动出块
+func DefaultConfig() Config {
+	return Config{
+		ChainID: big.NewInt(1337),
+		BaseFee: big.NewInt(params.GWei),
+		Tip:     big.NewInt(params.GWei),
+		Balances: map[common.Address]*big.Int{
+			DevAddress: new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether)),
+		},
+	}
+}
+
+// block 链上的一个区块
+type block struct {
+	header   *types.Header
+	txs      []*types.Transaction
+	senders  []common.Address
+	receipts []*types.Receipt
+	logs     []*types.Log // 通过 EmitLog 加入的日志（交易本身不产生日志）
+}
+
+// txLocation 已打包交易的位置
+type txLocation struct {
+	block *block
+	index int
+}
+
+// Node 模拟节点，实现 http.Handler
+type Node struct {
+	cfg    Config
+	signer types.Signer
+
+	mu       sync.Mutex
+	blocks   []*block // 规范链，下标即区块号
+	fork     uint64   // 每次重组递增，写入 Extra 使新分叉上的区块哈希不同
+	pending  []*types.Transaction
+	mined    map[common.Hash]txLocation
+	nonces   map[common.Address]uint64
+	balances map[common.Address]*big.Int
+	codes    map[common.Address][]byte
+	queued   []*types.Log // 等待下一个区块的日志
+	builtin  map[string]Handler
+	handlers map[string]Handler // Handle 设置的应答，优先于 builtin
+	failures []failure
+	subs     map[string]*subscription
+	nextSub  uint64
+}
+
+// New 创建模拟节点，链上只有创世区块
+func New(cfg Config) *Node {
+	def := DefaultConfig()
+	if cfg.ChainID == nil {
+		cfg.ChainID = def.ChainID
+	}
+	if cfg.BaseFee == nil {
+		cfg.BaseFee = def.BaseFee
+	}
+	if cfg.Tip == nil {
+		cfg.Tip = def.Tip
+	}
+	n := &Node{
+		cfg:      cfg,
+		signer:   types.LatestSignerForChainID(cfg.ChainID),
+		mined:    make(map[common.Hash]txLocation),
+		nonces:   make(map[common.Address]uint64),
+		balances: make(map[common.Address]*big.Int),
+		codes:    make(map[common.Address][]byte),
+		handlers: make(map[string]Handler),
+		subs:     make(map[string]*subscription),
+	}
+	for addr, v := range cfg.Balances {
+		n.balances[addr] = new(big.Int).Set(v)
+	}
+	genesis := &types.Header{
+		UncleHash:   types.EmptyUncleHash,
+		Root:        crypto.Keccak256Hash([]byte("rpcmock genesis")),
+		TxHash:      types.EmptyTxsHash,
+		ReceiptHash: types.EmptyReceiptsHash,
+		Difficulty:  new(big.Int),
+		Number:      new(big.Int),
+		GasLimit:    blockGasLimit,
+		Time:        uint64(time.Now().Unix()),
+		Extra:       []byte("rpcmock"),
+		BaseFee:     new(big.Int).Set(cfg.BaseFee),
+	}
+	n.blocks = []*block{{header: genesis}}
+	n.builtin = n.ethMethods()
+	return n
+}
+
+// ChainID 链 ID
+func (n *Node) ChainID() *big.Int { return new(big.Int).Set(n.cfg.ChainID) }
+
+// Head 当前最新区块头
+func (n *Node) Head() *types.Header {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return types.CopyHeader(n.head().header)
+}
+
+// SetBalance 设置账户余额
+func (n *Node) SetBalance(addr common.Address, wei *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.balances[addr] = new(big.Int).Set(wei)
+}
+
+// SetCode 设置合约代码（只影响 eth_getCode 的返回，合约不会被执行）
+func (n *Node) SetCode(addr common.Address, code []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.codes[addr] = append([]byte(nil), code...)
+}
+
+// Balance 账户当前余额
+func (n *Node) Balance(addr common.Address) *big.Int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.balance(addr)
+}
+
+// Pending 交易池中尚未打包的交易
+func (n *Node) Pending() []*types.Transaction {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]*types.Transaction(nil), n.pending...)
+}
+
+// Mine 连续出 count 个区块，每个区块打包交易池中 nonce 连续且费用不低于 base fee 的交易，
+// 以及 EmitLog 排队的日志；返回新区块头
+func (n *Node) Mine(count int) []*types.Header {
+	n.mu.Lock()
+	var (
+		heads []*types.Header
+		notes []notification
+	)
+	for range count {
+		b := n.mineLocked()
+		heads = append(heads, types.CopyHeader(b.header))
+		notes = append(notes, n.blockNotificationsLocked(b, false)...)
+	}
+	n.mu.Unlock()
+	n.deliver(notes)
+	return heads
+}
+
+// MineEvery 每隔 interval 出一个区块，直到 stop 被关闭
+func (n *Node) MineEvery(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			n.Mine(1)
+		}
+	}
+}
+
+// EmitLog 把日志排入下一个区块（地址、主题和数据由调用方给出，区块和交易字段由节点填写）
+func (n *Node) EmitLog(addr common.Address, topics []common.Hash, data []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.queued = append(n.queued, &types.Log{Address: addr, Topics: topics, Data: data})
+}
+
+// Reorg 丢弃最近 depth 个区块（最多到创世区块之后），在新的分叉上重新出 depth+1 个区块：
+// 被丢弃区块中的交易回到交易池并在新分叉的第一个区块中重新打包（区块号和哈希都会变化），
+// 日志订阅者先收到 removed=true 的日志，再收到新分叉上的日志
+func (n *Node) Reorg(depth int) []*types.Header {
+	n.mu.Lock()
+	depth = min(depth, len(n.blocks)-1)
+	removed := n.blocks[len(n.blocks)-depth:]
+	n.blocks = n.blocks[:len(n.blocks)-depth]
+
+	var (
+		restored []*types.Transaction
+		requeued []*types.Log
+		notes    []notification
+	)
+	for i := len(removed) - 1; i >= 0; i-- {
+		notes = append(notes, n.blockNotificationsLocked(removed[i], true)...)
+	}
+	for _, b := range removed {
+		for i, tx := range b.txs {
+			n.unapplyLocked(tx, b.senders[i], b.receipts[i])
+			delete(n.mined, tx.Hash())
+		}
+		restored = append(restored, b.txs...)
+		for _, l := range b.logs {
+			requeued = append(requeued, &types.Log{Address: l.Address, Topics: l.Topics, Data: l.Data})
+		}
+	}
+	n.pending = append(restored, n.pending...)
+	n.queued = append(requeued, n.queued...)
+	n.fork++
+
+	var heads []*types.Header
+	for range depth + 1 {
+		b := n.mineLocked()
+		heads = append(heads, types.CopyHeader(b.header))
+		notes = append(notes, n.blockNotificationsLocked(b, false)...)
+	}
+	n.mu.Unlock()
+	n.deliver(notes)
+	return heads
+}
+
+// SendTransfer 用 key 签名一笔转账并放入交易池（nonce 取该账户的下一个可用 nonce，费用按当前 base fee 和小费），
+// 用于制造"待打包交易"场景；AutoMine 时会立即打包
+func (n *Node) SendTransfer(key *ecdsa.PrivateKey, to common.Address, value *big.Int) (*types.Transaction, error) {
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	n.mu.Lock()
+	nonce := n.pendingNonceLocked(from)
+	n.mu.Unlock()
+
+	tip := new(big.Int).Set(n.cfg.Tip)
+	tx, err := types.SignNewTx(key, n.signer, &types.DynamicFeeTx{
+		ChainID:   n.cfg.ChainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(new(big.Int).Mul(n.cfg.BaseFee, big.NewInt(2)), tip),
+		Gas:       params.TxGas,
+		To:        &to,
+		Value:     value,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := n.SubmitTransaction(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// SubmitTransaction 按 eth_sendRawTransaction 的规则把已签名交易放入交易池
+func (n *Node) SubmitTransaction(tx *types.Transaction) error {
+	n.mu.Lock()
+	err := n.addTxLocked(tx)
+	var notes []notification
+	if err == nil {
+		notes = n.pendingNotificationsLocked(tx)
+		if n.cfg.AutoMine {
+			notes = append(notes, n.blockNotificationsLocked(n.mineLocked(), false)...)
+		}
+	}
+	n.mu.Unlock()
+	n.deliver(notes)
+	return err
+}
+
+// addTxLocked 校验交易并放入交易池，错误信息与 geth 一致
+func (n *Node) addTxLocked(tx *types.Transaction) error {
+	if tx.ChainId().Sign() != 0 && tx.ChainId().Cmp(n.cfg.ChainID) != 0 {
+		return &Error{Code: -32000, Message: fmt.Sprintf("invalid chain id: have %s want %s", tx.ChainId(), n.cfg.ChainID)}
+	}
+	from, err := types.Sender(n.signer, tx)
+	if err != nil {
+		return &Error{Code: -32000, Message: "invalid sender: " + err.Error()}
+	}
+	hash := tx.Hash()
+	if _, ok := n.mined[hash]; ok {
+		return &Error{Code: -32000, Message: "already known"}
+	}
+	if state := n.nonces[from]; tx.Nonce() < state {
+		return &Error{Code: -32000, Message: fmt.Sprintf("nonce too low: address %s, tx: %d state: %d", from.Hex(), tx.Nonce(), state)}
+	}
+	if balance := n.balance(from); balance.Cmp(tx.Cost()) < 0 {
+		return &Error{Code: -32000, Message: fmt.Sprintf("insufficient funds for gas * price + value: address %s have %s want %s", from.Hex(), balance, tx.Cost())}
+	}
+	for i, old := range n.pending {
+		if old.Hash() == hash {
+			return &Error{Code: -32000, Message: "already known"}
+		}
+		oldFrom, _ := types.Sender(n.signer, old)
+		if oldFrom != from || old.Nonce() != tx.Nonce() {
+			continue
+		}
+		// 替换交易：小费和费用上限都要比原交易高 10%（geth 默认 --txpool.pricebump）
+		if !bumped(tx.GasTipCap(), old.GasTipCap()) || !bumped(tx.GasFeeCap(), old.GasFeeCap()) {
+			return &Error{Code: -32000, Message: "replacement transaction underpriced"}
+		}
+		n.pending[i] = tx
+		return nil
+	}
+	n.pending = append(n.pending, tx)
+	return nil
+}
+
+// bumped v 是否至少比 old 高 10%
+func bumped(v, old *big.Int) bool {
+	need := new(big.Int).Mul(old, big.NewInt(110))
+	return new(big.Int).Mul(v, big.NewInt(100)).Cmp(need) >= 0
+}
+
+// mineLocked 在当前链头之后出一个区块
+func (n *Node) mineLocked() *block {
+	parent := n.head().header
+	number := new(big.Int).Add(parent.Number, big.NewInt(1))
+	b := &block{}
+
+	// 反复扫描交易池，按 nonce 顺序打包；nonce 不连续或费用上限低于 base fee 的交易留在池中
+	var cumulative uint64
+	for progress := true; progress; {
+		progress = false
+		for i := 0; i < len(n.pending); i++ {
+			tx := n.pending[i]
+			from, _ := types.Sender(n.signer, tx)
+			if tx.Nonce() != n.nonces[from] || tx.GasFeeCap().Cmp(n.cfg.BaseFee) < 0 || cumulative+tx.Gas() > blockGasLimit {
+				continue
+			}
+			gasUsed := params.TxGas
+			if len(tx.Data()) > 0 || tx.To() == nil {
+				gasUsed = tx.Gas()
+			}
+			cumulative += gasUsed
+			receipt := &types.Receipt{
+				Type:              tx.Type(),
+				Status:            types.ReceiptStatusSuccessful,
+				CumulativeGasUsed: cumulative,
+				Logs:              []*types.Log{},
+				TxHash:            tx.Hash(),
+				GasUsed:           gasUsed,
+				EffectiveGasPrice: effectiveGasPrice(tx, n.cfg.BaseFee),
+				BlockNumber:       number,
+				TransactionIndex:  uint(len(b.txs)),
+			}
+			if tx.To() == nil {
+				receipt.ContractAddress = crypto.CreateAddress(from, tx.Nonce())
+			}
+			n.applyLocked(tx, from, receipt)
+			b.txs = append(b.txs, tx)
+			b.senders = append(b.senders, from)
+			b.receipts = append(b.receipts, receipt)
+			n.pending = append(n.pending[:i], n.pending[i+1:]...)
+			i--
+			progress = true
+		}
+	}
+
+	var bloom types.Bloom
+	for _, l := range n.queued {
+		bloom.Add(l.Address.Bytes())
+		for _, t := range l.Topics {
+			bloom.Add(t.Bytes())
+		}
+	}
+	header := &types.Header{
+		ParentHash:  parent.Hash(),
+		UncleHash:   types.EmptyUncleHash,
+		Root:        crypto.Keccak256Hash(number.Bytes(), binary.BigEndian.AppendUint64(nil, n.fork)),
+		TxHash:      types.EmptyTxsHash,
+		ReceiptHash: types.EmptyReceiptsHash,
+		Bloom:       bloom,
+		Difficulty:  new(big.Int),
+		Number:      number,
+		GasLimit:    blockGasLimit,
+		GasUsed:     cumulative,
+		Time:        parent.Time + blockInterval,
+		Extra:       fmt.Appendf(nil, "rpcmock/%d", n.fork),
+		BaseFee:     new(big.Int).Set(n.cfg.BaseFee),
+	}
+	if len(b.txs) > 0 {
+		header.TxHash = listHash(b.txs)
+		header.ReceiptHash = crypto.Keccak256Hash(header.TxHash.Bytes(), []byte("receipts"))
+	}
+	b.header = header
+	hash := header.Hash()
+
+	for i, r := range b.receipts {
+		r.BlockHash = hash
+		n.mined[b.txs[i].Hash()] = txLocation{block: b, index: i}
+	}
+	for i, l := range n.queued {
+		l.BlockNumber = number.Uint64()
+		l.BlockHash = hash
+		l.Index = uint(i)
+		l.TxHash = crypto.Keccak256Hash(hash.Bytes(), binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+	b.logs, n.queued = n.queued, nil
+	n.blocks = append(n.blocks, b)
+	return b
+}
+
+// applyLocked 记账：扣除发送方的 value 和实际手续费，增加接收方余额，nonce 加一
+func (n *Node) applyLocked(tx *types.Transaction, from common.Address, r *types.Receipt) {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(r.GasUsed), r.EffectiveGasPrice)
+	n.balances[from] = new(big.Int).Sub(n.balance(from), new(big.Int).Add(tx.Value(), fee))
+	if to := tx.To(); to != nil {
+		n.balances[*to] = new(big.Int).Add(n.balance(*to), tx.Value())
+	} else {
+		n.balances[r.ContractAddress] = new(big.Int).Add(n.balance(r.ContractAddress), tx.Value())
+	}
+	n.nonces[from]++
+}
+
+// unapplyLocked 撤销 applyLocked（重组时）
+func (n *Node) unapplyLocked(tx *types.Transaction, from common.Address, r *types.Receipt) {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(r.GasUsed), r.EffectiveGasPrice)
+	n.balances[from] = new(big.Int).Add(n.balance(from), new(big.Int).Add(tx.Value(), fee))
+	to := r.ContractAddress
+	if tx.To() != nil {
+		to = *tx.To()
+	}
+	n.balances[to] = new(big.Int).Sub(n.balance(to), tx.Value())
+	n.nonces[from]--
+}
+
+func (n *Node) head() *block { return n.blocks[len(n.blocks)-1] }
+
+func (n *Node) balance(addr common.Address) *big.Int {
+	if v, ok := n.balances[addr]; ok {
+		return new(big.Int).Set(v)
+	}
+	return new(big.Int)
+}
+
+// pendingNonceLocked 已上链的 nonce 加上交易池中从它开始连续的交易数
+func (n *Node) pendingNonceLocked(addr common.Address) uint64 {
+	nonce := n.nonces[addr]
+	for found := true; found; {
+		found = false
+		for _, tx := range n.pending {
+			if from, _ := types.Sender(n.signer, tx); from == addr && tx.Nonce() == nonce {
+				nonce++
+				found = true
+			}
+		}
+	}
+	return nonce
+}
+
+// effectiveGasPrice min(fee cap, base fee + tip)
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	price := new(big.Int).Add(baseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		price.Set(tx.GasFeeCap())
+	}
+	return price
+}
+
+// listHash 交易哈希拼接后的 Keccak256，代替交易根（见包文档）
+func listHash(txs []*types.Transaction) common.Hash {
+	var buf []byte
+	for _, tx := range txs {
+		buf = append(buf, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(buf)
+}
+
+func mustKey(hex string) *ecdsa.PrivateKey {
+	key, err := crypto.HexToECDSA(hex)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
@@ -0,0 +1,234 @@
+package rpcmock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/yzucdh1/examples/internal/rpcerr"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+func start(t *testing.T, cfg Config) (*Node, *httptest.Server) {
+	t.Helper()
+	node := New(cfg)
+	srv := httptest.NewServer(node)
+	t.Cleanup(srv.Close)
+	return node, srv
+}
+
+func dial(t *testing.T, url string) *ethclient.Client {
+	t.Helper()
+	client, err := ethclient.Dial(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func signTransfer(t *testing.T, chainID *big.Int, nonce uint64, tip int64) *types.Transaction {
+	t.Helper()
+	to := common.HexToAddress("0x000000000000000000000000000000000000beef")
+	tx, err := types.SignNewTx(DevKey, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(tip),
+		GasFeeCap: big.NewInt(10 * params.GWei),
+		Gas:       params.TxGas,
+		To:        &to,
+		Value:     big.NewInt(params.Ether),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestTransferLifecycle(t *testing.T) {
+	node, srv := start(t, DefaultConfig())
+	client := dial(t, srv.URL)
+	ctx := context.Background()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil || chainID.Int64() != 1337 {
+		t.Fatalf("ChainID = %v, %v", chainID, err)
+	}
+	tx := signTransfer(t, chainID, 0, params.GWei)
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 出块前交易处于待打包状态
+	if _, pending, err := client.TransactionByHash(ctx, tx.Hash()); err != nil || !pending {
+		t.Fatalf("TransactionByHash: pending=%v err=%v", pending, err)
+	}
+	if _, err := client.TransactionReceipt(ctx, tx.Hash()); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("receipt before mining: %v", err)
+	}
+	if nonce, _ := client.PendingNonceAt(ctx, DevAddress); nonce != 1 {
+		t.Errorf("PendingNonceAt = %d, want 1", nonce)
+	}
+
+	node.Mine(1)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful || receipt.BlockNumber.Uint64() != 1 {
+		t.Errorf("receipt status=%d block=%v", receipt.Status, receipt.BlockNumber)
+	}
+	block, err := client.BlockByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Transactions()) != 1 || block.Transactions()[0].Hash() != tx.Hash() {
+		t.Errorf("block transactions = %v", block.Transactions())
+	}
+	if block.Hash() != receipt.BlockHash {
+		t.Errorf("block hash %s != receipt block hash %s", block.Hash(), receipt.BlockHash)
+	}
+	balance, err := client.BalanceAt(ctx, common.HexToAddress("0x000000000000000000000000000000000000beef"), nil)
+	if err != nil || balance.Cmp(big.NewInt(params.Ether)) != 0 {
+		t.Errorf("recipient balance = %v, %v", balance, err)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	node, srv := start(t, DefaultConfig())
+	client := dial(t, srv.URL)
+	ctx := context.Background()
+	chainID := node.ChainID()
+
+	first := signTransfer(t, chainID, 0, params.GWei)
+	if err := client.SendTransaction(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		tx   *types.Transaction
+		want error
+	}{
+		{"already known", first, rpcerr.ErrAlreadyKnown},
+		{"replacement underpriced", signTransfer(t, chainID, 0, params.GWei+1), rpcerr.ErrReplacementUnderpriced},
+	}
+	for _, tt := range tests {
+		err := rpcerr.Classify(client.SendTransaction(ctx, tt.tx))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+	}
+
+	node.Mine(1)
+	if err := rpcerr.Classify(client.SendTransaction(ctx, signTransfer(t, chainID, 0, 2*params.GWei))); !errors.Is(err, rpcerr.ErrNonceTooLow) {
+		t.Errorf("nonce too low: got %v", err)
+	}
+	node.SetBalance(DevAddress, big.NewInt(1))
+	if err := rpcerr.Classify(client.SendTransaction(ctx, signTransfer(t, chainID, 1, params.GWei))); !errors.Is(err, rpcerr.ErrInsufficientFunds) {
+		t.Errorf("insufficient funds: got %v", err)
+	}
+}
+
+func TestSubscriptionsAndReorg(t *testing.T) {
+	node, srv := start(t, DefaultConfig())
+	client := dial(t, WSURL(srv.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	heads := make(chan *types.Header, 16)
+	headSub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer headSub.Unsubscribe()
+	token := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	logs := make(chan types.Log, 16)
+	logSub, err := client.SubscribeFilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{token}}, logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logSub.Unsubscribe()
+
+	node.EmitLog(token, []common.Hash{{1}}, nil)
+	node.Mine(2)
+	for want := uint64(1); want <= 2; want++ {
+		select {
+		case h := <-heads:
+			if h.Number.Uint64() != want {
+				t.Fatalf("head %d, want %d", h.Number.Uint64(), want)
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for head")
+		}
+	}
+	orig := <-logs
+	if orig.BlockNumber != 1 || orig.Removed {
+		t.Fatalf("log = %+v", orig)
+	}
+
+	// 重组最近 2 个区块：日志先被移除，再出现在新分叉的第一个区块中
+	oldHead := node.Head()
+	newHeads := node.Reorg(2)
+	if len(newHeads) != 3 || newHeads[len(newHeads)-1].Number.Uint64() != 3 {
+		t.Fatalf("Reorg returned %d heads", len(newHeads))
+	}
+	if removed := <-logs; !removed.Removed || removed.BlockHash != orig.BlockHash {
+		t.Errorf("expected removed log, got %+v", removed)
+	}
+	if again := <-logs; again.Removed || again.BlockNumber != 1 || again.BlockHash == orig.BlockHash {
+		t.Errorf("expected re-included log, got %+v", again)
+	}
+	if h, err := client.HeaderByNumber(ctx, big.NewInt(2)); err != nil || h.Hash() == oldHead.Hash() {
+		t.Errorf("block 2 not replaced: %v", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	node, srv := start(t, DefaultConfig())
+	ctx := context.Background()
+
+	plain := dial(t, srv.URL)
+	node.RateLimit(1)
+	if _, err := plain.BlockNumber(ctx); !errors.Is(rpcerr.Classify(err), rpcerr.ErrRateLimited) {
+		t.Errorf("BlockNumber during rate limit: %v", err)
+	}
+
+	policy := rpcretry.DefaultPolicy()
+	policy.MinBackoff = time.Millisecond
+	retries := 0
+	policy.OnRetry = func(int, time.Duration, error) { retries++ }
+	client, err := rpcretry.DialWith(ctx, srv.URL, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	node.RateLimit(2)
+	if _, err := client.BlockNumber(ctx); err != nil {
+		t.Fatalf("BlockNumber with retries: %v", err)
+	}
+	if retries != 2 {
+		t.Errorf("retries = %d, want 2", retries)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	node, srv := start(t, DefaultConfig())
+	client := dial(t, srv.URL)
+	node.Handle("eth_call", func([]json.RawMessage) (any, error) {
+		return nil, &Error{Code: 3, Message: "execution reverted", Data: "0x82b42900"}
+	})
+	_, err := client.CallContract(context.Background(), ethereum.CallMsg{To: &DevAddress}, nil)
+	var revert *rpcerr.RevertError
+	if !errors.As(rpcerr.Classify(err), &revert) || revert.Selector() != [4]byte{0x82, 0xb4, 0x29, 0x00} {
+		t.Errorf("CallContract error = %v", err)
+	}
+}
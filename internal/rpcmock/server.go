@@ -0,0 +1,233 @@
+package rpcmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Handler 单个方法的应答函数，params 为请求中的参数数组；返回 *Error 时按原样输出错误码和数据
+type Handler func(params []json.RawMessage) (any, error)
+
+// Error JSON-RPC 错误
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// failure FailNext 排队的失败
+type failure struct {
+	status int
+}
+
+// Handle 替换 method 的应答（包括内置的 eth_* 方法），h 为 nil 时恢复默认
+func (n *Node) Handle(method string, h Handler) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if h == nil {
+		delete(n.handlers, method)
+		return
+	}
+	n.handlers[method] = h
+}
+
+// FailNext 让接下来的 count 个请求失败：HTTP 请求（整个批量请求算一个）直接返回 status，
+// WebSocket 消息返回 JSON-RPC 错误（429 对应 -32005 limit exceeded，其他状态对应 -32603）
+func (n *Node) FailNext(count, status int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for range count {
+		n.failures = append(n.failures, failure{status: status})
+	}
+}
+
+// RateLimit 让接下来的 count 个请求被限流（HTTP 429 / -32005）
+func (n *Node) RateLimit(count int) { n.FailNext(count, http.StatusTooManyRequests) }
+
+// nextFailure 取出一个排队的失败
+func (n *Node) nextFailure() (failure, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.failures) == 0 {
+		return failure{}, false
+	}
+	f := n.failures[0]
+	n.failures = n.failures[1:]
+	return f, true
+}
+
+func (f failure) rpcError() *Error {
+	if f.status == http.StatusTooManyRequests {
+		return &Error{Code: -32005, Message: "limit exceeded"}
+	}
+	return &Error{Code: -32603, Message: fmt.Sprintf("%d %s", f.status, http.StatusText(f.status))}
+}
+
+// request / response JSON-RPC 消息
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// ServeHTTP 处理 JSON-RPC over HTTP；带 Upgrade 头的请求转为 WebSocket 连接
+func (n *Node) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		n.serveWS(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if f, ok := n.nextFailure(); ok {
+		http.Error(w, http.StatusText(f.status), f.status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(n.handleMessage(body, nil))
+}
+
+// handleMessage 处理单个或批量请求，返回编码后的响应
+func (n *Node) handleMessage(body []byte, conn *wsConn) []byte {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []request
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return mustJSON(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: -32700, Message: err.Error()}})
+		}
+		out := make([]response, len(batch))
+		for i, req := range batch {
+			out[i] = n.call(req, conn)
+		}
+		return mustJSON(out)
+	}
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return mustJSON(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: -32700, Message: err.Error()}})
+	}
+	return mustJSON(n.call(req, conn))
+}
+
+// call 分发单个请求：Handle 设置的应答优先，其次是内置方法
+func (n *Node) call(req request, conn *wsConn) response {
+	resp := response{JSONRPC: "2.0", ID: req.ID}
+	if len(resp.ID) == 0 {
+		resp.ID = json.RawMessage("null")
+	}
+
+	var (
+		result any
+		err    error
+	)
+	n.mu.Lock()
+	h, ok := n.handlers[req.Method]
+	n.mu.Unlock()
+	switch {
+	case ok:
+		result, err = h(req.Params)
+	case req.Method == "eth_subscribe":
+		result, err = n.subscribe(req.Params, conn)
+	case req.Method == "eth_unsubscribe":
+		result, err = n.unsubscribe(req.Params, conn)
+	default:
+		if m, found := n.builtin[req.Method]; found {
+			result, err = m(req.Params)
+		} else {
+			err = &Error{Code: -32601, Message: fmt.Sprintf("the method %s does not exist/is not available", req.Method)}
+		}
+	}
+
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: -32000, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+		return resp
+	}
+	resp.Result = mustJSON(result)
+	return resp
+}
+
+// upgrader 测试用，不校验 Origin
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+// wsConn 一个 WebSocket 连接，写操作加锁（应答和订阅推送可能并发）
+type wsConn struct {
+	mu sync.Mutex
+	c  *websocket.Conn
+}
+
+func (c *wsConn) write(b []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.WriteMessage(websocket.TextMessage, b)
+}
+
+func (n *Node) serveWS(w http.ResponseWriter, r *http.Request) {
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn := &wsConn{c: c}
+	defer func() {
+		n.dropSubscriptions(conn)
+		c.Close()
+	}()
+	for {
+		_, msg, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		var out []byte
+		if f, ok := n.nextFailure(); ok {
+			var req request
+			_ = json.Unmarshal(msg, &req)
+			if len(req.ID) == 0 {
+				req.ID = json.RawMessage("null")
+			}
+			out = mustJSON(response{JSONRPC: "2.0", ID: req.ID, Error: f.rpcError()})
+		} else {
+			out = n.handleMessage(msg, conn)
+		}
+		if err := conn.write(out); err != nil {
+			return
+		}
+	}
+}
+
+// WSURL 把 httptest.Server 的 http:// 地址换成 ws:// 地址
+func WSURL(httpURL string) string {
+	return "ws" + strings.TrimPrefix(httpURL, "http")
+}
+
+func mustJSON(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
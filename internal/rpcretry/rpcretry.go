@@ -0,0 +1,314 @@
+// Package rpcretry 为节点连接加上单次调用超时、有上限的指数退避重试和请求限速，取代此前 02 中手写的重试循环：
+//   - HTTP(S) 节点：Dial 在 HTTP 传输层包装每个 JSON-RPC 请求（包括批量请求和 Client().CallContext），
+//     对调用方透明；429 / 5xx / 连接被重置等临时错误按退避重试，429 带 Retry-After 时至少等待该时长
+//   - WebSocket / IPC 节点：连接是有状态的（订阅依附在连接上），不做透明重试和限速，
+//     需要时用 Do 包装单次调用
+//   - 限速按所有请求共用的最小间隔实现（Policy.RateLimit 次/秒），重试同样计入
+//
+// 10-multi-node-pool 在多个节点之间做故障转移，失败要尽快暴露给连接池，不使用本包。
+package rpcretry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/yzucdh1/examples/internal/rpcmetrics"
+)
+
+// rpcCodeLimitExceeded 部分服务商在 HTTP 200 中返回的限流错误码
+const rpcCodeLimitExceeded = -32005
+
+// Policy 超时、重试与限速参数，零值字段表示不启用对应功能
+type Policy struct {
+	// CallTimeout 单次请求（每次重试分别计算）的超时
+	CallTimeout time.Duration
+	// MaxRetries 首次请求失败后最多重试的次数
+	MaxRetries int
+	// MinBackoff / MaxBackoff 第 n 次重试前等待 MinBackoff×2^n（加随机抖动），不超过 MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// RateLimit 每秒最多发出的请求数
+	RateLimit float64
+	// OnRetry 每次重试前调用，可为 nil
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// DefaultPolicy 各示例使用的默认参数：单次 10 秒超时，最多重试 3 次，退避 500ms 起、最长 5 秒，不限速
+func DefaultPolicy() Policy {
+	return Policy{
+		CallTimeout: 10 * time.Second,
+		MaxRetries:  3,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+	}
+}
+
+// Dial 按默认参数连接节点
+func Dial(ctx context.Context, url string) (*ethclient.Client, error) {
+	return DialWith(ctx, url, DefaultPolicy())
+}
+
+// DialWith 按 p 连接节点；只有 HTTP(S) 连接会应用 p（见包文档）
+func DialWith(ctx context.Context, url string, p Policy) (*ethclient.Client, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return ethclient.DialContext(ctx, url)
+	}
+	// 每次尝试都经过 rpcmetrics 记录（开启 /metrics 时），重试单独计入
+	httpClient := &http.Client{Transport: NewTransport(rpcmetrics.Default.Transport(http.DefaultTransport), p)}
+	c, err := rpc.DialOptions(ctx, url, rpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(c), nil
+}
+
+// Do 按 p 执行一次调用：每次尝试使用带 CallTimeout 的子上下文，Retryable 的错误按退避重试
+func Do[T any](ctx context.Context, p Policy, call func(ctx context.Context) (T, error)) (T, error) {
+	r := newRetrier(p)
+	for attempt := 0; ; attempt++ {
+		var zero T
+		if err := r.limiter.wait(ctx); err != nil {
+			return zero, err
+		}
+		callCtx, cancel := r.callContext(ctx)
+		v, err := call(callCtx)
+		cancel()
+		if err == nil {
+			return v, nil
+		}
+		if attempt >= p.MaxRetries || ctx.Err() != nil || !Retryable(err) {
+			return zero, err
+		}
+		if err := r.sleep(ctx, attempt, 0, err); err != nil {
+			return zero, err
+		}
+	}
+}
+
+// Retryable 判断错误是否是值得在同一节点上重试的临时错误：网络错误、超时、429 / 5xx 和 -32005 限流。
+// 调用方取消、NotFound、参数错误、执行 revert 等重试也不会成功
+func Retryable(err error) bool {
+	var (
+		netErr  net.Error
+		httpErr rpc.HTTPError
+		rpcErr  rpc.Error
+	)
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED),
+		errors.As(err, &netErr):
+		return true
+	case errors.As(err, &httpErr):
+		return retryableStatus(httpErr.StatusCode)
+	case errors.As(err, &rpcErr):
+		return rpcErr.ErrorCode() == rpcCodeLimitExceeded
+	}
+	return false
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// NewTransport 返回按 p 重试和限速的 http.RoundTripper，base 为 nil 时使用 http.DefaultTransport
+func NewTransport(base http.RoundTripper, p Policy) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, retrier: newRetrier(p)}
+}
+
+type transport struct {
+	base http.RoundTripper
+	*retrier
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// 请求体需要在重试时重放，JSON-RPC 请求体很小，直接读入内存
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		callCtx, cancel := t.callContext(ctx)
+		r := req.Clone(callCtx)
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+		resp, err := t.base.RoundTrip(r)
+
+		var retryErr error
+		switch {
+		case err != nil && Retryable(err):
+			retryErr = err
+		case err == nil && retryableStatus(resp.StatusCode):
+			retryErr = fmt.Errorf("%s", resp.Status)
+		}
+		if retryErr == nil || attempt >= t.policy.MaxRetries || ctx.Err() != nil {
+			if resp == nil {
+				cancel()
+				return nil, err
+			}
+			// 单次超时要覆盖读取响应体的过程，调用方关闭响应体时才释放
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		var retryAfter time.Duration
+		if resp != nil {
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		cancel()
+		if err := t.sleep(ctx, attempt, retryAfter, retryErr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retrier HTTP 传输层和 Do 共用的超时、退避和限速逻辑
+type retrier struct {
+	policy  Policy
+	limiter *limiter
+}
+
+func newRetrier(p Policy) *retrier {
+	r := &retrier{policy: p}
+	if p.RateLimit > 0 {
+		r.limiter = &limiter{interval: time.Duration(float64(time.Second) / p.RateLimit)}
+	}
+	return r
+}
+
+func (r *retrier) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.policy.CallTimeout > 0 {
+		return context.WithTimeout(ctx, r.policy.CallTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// sleep 第 attempt 次重试前等待；服务端给出的 Retry-After 更长时以它为准
+func (r *retrier) sleep(ctx context.Context, attempt int, retryAfter time.Duration, err error) error {
+	delay := r.backoff(attempt)
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	if r.policy.OnRetry != nil {
+		r.policy.OnRetry(attempt+1, delay, err)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff MinBackoff×2^attempt，上限 MaxBackoff；取其中 [1/2, 1] 的随机值，避免多个客户端同时重试
+func (r *retrier) backoff(attempt int) time.Duration {
+	d := r.policy.MinBackoff
+	if d <= 0 {
+		return 0
+	}
+	for range attempt {
+		d *= 2
+		if r.policy.MaxBackoff > 0 && d >= r.policy.MaxBackoff {
+			d = r.policy.MaxBackoff
+			break
+		}
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// limiter 所有请求共用的最小发送间隔
+type limiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,181 @@
+package rpcretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fastPolicy 测试用的短退避
+func fastPolicy() Policy {
+	return Policy{CallTimeout: time.Second, MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+// newNode 前 failures 次请求返回 status，之后正常返回 eth_blockNumber 的结果
+func newNode(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x10"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestDialRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int32
+		status    int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{"rate limited then ok", 2, http.StatusTooManyRequests, false, 3},
+		{"bad gateway then ok", 1, http.StatusBadGateway, false, 2},
+		{"retries exhausted", 10, http.StatusServiceUnavailable, true, 4},
+		{"client error not retried", 10, http.StatusUnauthorized, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := newNode(t, tt.failures, tt.status)
+			var retries int
+			p := fastPolicy()
+			p.OnRetry = func(int, time.Duration, error) { retries++ }
+			client, err := DialWith(context.Background(), srv.URL, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			n, err := client.BlockNumber(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && n != 16 {
+				t.Errorf("block number = %d, want 16", n)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if retries != int(tt.wantCalls)-1 {
+				t.Errorf("OnRetry called %d times, want %d", retries, tt.wantCalls-1)
+			}
+		})
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次请求挂起到超时，第二次正常返回
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+	}))
+	defer srv.Close()
+
+	p := fastPolicy()
+	p.CallTimeout = 50 * time.Millisecond
+	client, err := DialWith(context.Background(), srv.URL, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err := client.BlockNumber(context.Background()); err != nil {
+		t.Fatalf("BlockNumber: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	srv, _ := newNode(t, 0, 0)
+	p := fastPolicy()
+	p.RateLimit = 20 // 每 50ms 一个请求
+	client, err := DialWith(context.Background(), srv.URL, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	for range 4 {
+		if _, err := client.BlockNumber(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("4 requests took %v, want >= 150ms at 20 req/s", elapsed)
+	}
+}
+
+func TestDo(t *testing.T) {
+	calls := 0
+	v, err := Do(context.Background(), fastPolicy(), func(ctx context.Context) (int, error) {
+		if calls++; calls < 3 {
+			return 0, syscall.ECONNRESET
+		}
+		return 42, nil
+	})
+	if err != nil || v != 42 || calls != 3 {
+		t.Errorf("got %d, %v after %d calls, want 42 after 3", v, err, calls)
+	}
+
+	calls = 0
+	_, err = Do(context.Background(), fastPolicy(), func(ctx context.Context) (int, error) {
+		calls++
+		return 0, ethereum.NotFound
+	})
+	if !errors.Is(err, ethereum.NotFound) || calls != 1 {
+		t.Errorf("got %v after %d calls, want NotFound without retry", err, calls)
+	}
+}
+
+type codeErr int
+
+func (e codeErr) Error() string  { return fmt.Sprintf("rpc error %d", int(e)) }
+func (e codeErr) ErrorCode() int { return int(e) }
+
+var _ rpc.Error = codeErr(0)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, true},
+		{fmt.Errorf("post: %w", syscall.ECONNRESET), true},
+		{rpc.HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		{rpc.HTTPError{StatusCode: http.StatusForbidden}, false},
+		{codeErr(-32005), true},
+		{codeErr(3), false},
+		{ethereum.NotFound, false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.want {
+			t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
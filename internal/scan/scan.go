@@ -0,0 +1,252 @@
+// Package scan 是 02 / 06 / 08 等示例按区块范围回溯历史数据时共用的进度与续扫框架：
+//   - 按 Step 个区块一段调用处理函数，每隔 --progress-interval 用 [INFO] 日志输出进度条、速度和预计剩余时间
+//   - 每隔 CheckpointEvery 以及退出时把进度写入 --state 指定的状态文件（先写临时文件再 rename），
+//     下次以相同参数运行时从上次处理到的区块继续；扫描完成后删除状态文件
+//   - ctx 被取消（Ctrl-C，见 signal.NotifyContext）时不再开始新的一段，保存进度后返回 ErrInterrupted
+//
+// 状态文件格式各示例相同（见 State），Name 和 Key 用于防止把一个扫描的状态用在参数不同的另一个扫描上。
+// 处理函数需要保证同一段区块可以重复处理：进程在两次检查点之间退出时，最后几段会在续扫时再处理一遍。
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrInterrupted 扫描因 ctx 取消而中止，进度已保存
+var ErrInterrupted = errors.New("scan interrupted")
+
+// ErrStateMismatch 状态文件属于另一个扫描（名称、参数或起始区块不同）
+var ErrStateMismatch = errors.New("state file belongs to a different scan")
+
+// DefaultCheckpointEvery 默认检查点间隔，与 09-project 的索引检查点相同
+const DefaultCheckpointEvery = 10 * time.Second
+
+// State 状态文件内容
+type State struct {
+	Name      string    `json:"name"`  // 扫描名称，如 "02-block-range"
+	Key       string    `json:"key"`   // 扫描参数（合约地址、过滤条件等），不同参数的进度不能混用
+	From      uint64    `json:"from"`  // 起始区块
+	To        uint64    `json:"to"`    // 结束区块（含），续扫时可以变化，例如扫到最新区块
+	Next      uint64    `json:"next"`  // 下一个待处理的区块
+	Items     uint64    `json:"items"` // 累计处理的条目数（区块、日志等，由处理函数返回）
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Options 命令行参数，由 AddFlags 注册
+type Options struct {
+	StateFile       string        // 为空时不保存进度
+	Restart         bool          // 忽略已有的状态文件，从头开始
+	ProgressEvery   time.Duration // 进度日志间隔，0 表示不输出
+	CheckpointEvery time.Duration // 状态文件写入间隔
+}
+
+// AddFlags 注册 --state、--restart 和 --progress-interval，defaultState 为默认状态文件路径
+func AddFlags(fs *flag.FlagSet, defaultState string) *Options {
+	o := &Options{CheckpointEvery: DefaultCheckpointEvery}
+	fs.StringVar(&o.StateFile, "state", defaultState, "file to save scan progress to; rerunning with the same flags resumes from it (empty disables)")
+	fs.BoolVar(&o.Restart, "restart", false, "ignore saved scan progress and start from the first block")
+	fs.DurationVar(&o.ProgressEvery, "progress-interval", 5*time.Second, "interval between progress log lines (0 disables)")
+	return o
+}
+
+// Scan 一次扫描的描述
+type Scan struct {
+	Name     string
+	Key      string
+	From, To uint64 // 区块范围（含两端）
+	Step     uint64 // 每段的区块数，默认 1
+	Unit     string // 条目名称，用于进度日志，默认 "items"
+}
+
+// Func 处理区块 [from, to]，返回处理的条目数；返回错误时该段视为未处理
+type Func func(ctx context.Context, from, to uint64) (items int, err error)
+
+// Result 扫描结果
+type Result struct {
+	Resumed bool   // 是否从状态文件续扫
+	Start   uint64 // 本次运行实际开始的区块
+	Next    uint64 // 下一个待处理的区块（完成时为 To+1）
+	Items   uint64 // 累计条目数（含续扫前的）
+	Elapsed time.Duration
+}
+
+// Run 执行扫描。完成时删除状态文件并返回 nil；ctx 被取消时保存进度并返回 ErrInterrupted；
+// 处理函数出错时同样保存进度（出错的段下次重新处理）并返回该错误
+func (o *Options) Run(ctx context.Context, s Scan, fn Func) (Result, error) {
+	if s.Step == 0 {
+		s.Step = 1
+	}
+	if s.Unit == "" {
+		s.Unit = "items"
+	}
+	if s.From > s.To {
+		return Result{}, fmt.Errorf("invalid range [%d, %d]", s.From, s.To)
+	}
+
+	st := State{Name: s.Name, Key: s.Key, From: s.From, To: s.To, Next: s.From}
+	var res Result
+	if o.StateFile != "" && !o.Restart {
+		saved, ok, err := LoadState(o.StateFile)
+		if err != nil {
+			return res, err
+		}
+		if ok {
+			if saved.Name != s.Name || saved.Key != s.Key || saved.From != s.From {
+				return res, fmt.Errorf("%w: %s was written by %s (key %q, from %d); use --restart or another --state",
+					ErrStateMismatch, o.StateFile, saved.Name, saved.Key, saved.From)
+			}
+			st.Next, st.Items = saved.Next, saved.Items
+			res.Resumed = true
+			log.Printf("[INFO] %s: resuming from block %d (%d %s so far, saved %s)",
+				s.Name, st.Next, st.Items, s.Unit, saved.UpdatedAt.Local().Format(time.DateTime))
+		}
+	}
+	res.Start = st.Next
+
+	p := newProgress(s, st.Next)
+	start := time.Now()
+	lastSave, lastReport := start, start
+	save := func() {
+		if o.StateFile == "" {
+			return
+		}
+		st.UpdatedAt = time.Now()
+		if err := st.Save(o.StateFile); err != nil {
+			log.Printf("[WARN] %s: failed to save progress: %v", s.Name, err)
+		}
+	}
+	finish := func(err error) (Result, error) {
+		res.Next, res.Items, res.Elapsed = st.Next, st.Items, time.Since(start)
+		return res, err
+	}
+
+	for st.Next <= s.To {
+		if ctx.Err() != nil {
+			save()
+			log.Printf("[INFO] %s: interrupted at block %d; %s", s.Name, st.Next, o.resumeHint())
+			return finish(fmt.Errorf("%w at block %d", ErrInterrupted, st.Next))
+		}
+		end := min(st.Next+s.Step-1, s.To)
+		items, err := fn(ctx, st.Next, end)
+		if err != nil {
+			save()
+			if ctx.Err() != nil {
+				log.Printf("[INFO] %s: interrupted at block %d; %s", s.Name, st.Next, o.resumeHint())
+				return finish(fmt.Errorf("%w at block %d", ErrInterrupted, st.Next))
+			}
+			return finish(fmt.Errorf("blocks [%d, %d]: %w", st.Next, end, err))
+		}
+		st.Next = end + 1
+		st.Items += uint64(items)
+
+		now := time.Now()
+		if o.CheckpointEvery > 0 && now.Sub(lastSave) >= o.CheckpointEvery {
+			save()
+			lastSave = now
+		}
+		if o.ProgressEvery > 0 && now.Sub(lastReport) >= o.ProgressEvery {
+			log.Printf("[INFO] %s", p.line(st, now))
+			lastReport = now
+		}
+	}
+
+	if o.StateFile != "" {
+		if err := os.Remove(o.StateFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[WARN] %s: failed to remove finished state file: %v", s.Name, err)
+		}
+	}
+	if o.ProgressEvery > 0 {
+		log.Printf("[INFO] %s", p.line(st, time.Now()))
+	}
+	return finish(nil)
+}
+
+func (o *Options) resumeHint() string {
+	if o.StateFile == "" {
+		return "progress is not saved (no --state)"
+	}
+	return fmt.Sprintf("progress saved to %s, rerun with the same flags to resume", o.StateFile)
+}
+
+// progress 进度日志：进度条、百分比、速度（按本次运行计算）和预计剩余时间
+type progress struct {
+	scan  Scan
+	first uint64 // 本次运行开始的区块
+	start time.Time
+}
+
+func newProgress(s Scan, first uint64) *progress {
+	return &progress{scan: s, first: first, start: time.Now()}
+}
+
+const barWidth = 20
+
+func (p *progress) line(st State, now time.Time) string {
+	total := p.scan.To - p.scan.From + 1
+	done := min(st.Next, p.scan.To+1) - p.scan.From // 续扫时范围可能缩小，已处理的区块数不超过总数
+	frac := float64(done) / float64(total)
+	filled := int(frac * barWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", barWidth-filled)
+
+	line := fmt.Sprintf("%s: [%s] %5.1f%% %d/%d blocks, %d %s", p.scan.Name, bar, frac*100, done, total, st.Items, p.scan.Unit)
+	elapsed := now.Sub(p.start).Seconds()
+	if session := st.Next - p.first; session > 0 && elapsed > 0 {
+		rate := float64(session) / elapsed
+		line += fmt.Sprintf(", %.1f blocks/s", rate)
+		if remaining := total - done; remaining > 0 {
+			eta := time.Duration(float64(remaining) / rate * float64(time.Second))
+			line += ", ETA " + eta.Round(time.Second).String()
+		}
+	}
+	return line
+}
+
+// LoadState 读取状态文件，文件不存在时 ok=false
+func LoadState(path string) (st State, ok bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, false, nil
+	}
+	if err != nil {
+		return st, false, fmt.Errorf("failed to read scan state: %w", err)
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, false, fmt.Errorf("failed to parse scan state %s: %w", path, err)
+	}
+	return st, true, nil
+}
+
+// Save 写入状态文件（先写临时文件再 rename，避免写一半时进程退出导致文件损坏）
+func (st State) Save(path string) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".scan-state-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	opts := &Options{StateFile: path, CheckpointEvery: time.Hour}
+	s := Scan{Name: "test", Key: "0xabc", From: 10, To: 29, Step: 5}
+
+	// 第一次运行处理两段后被取消
+	ctx, cancel := context.WithCancel(context.Background())
+	var seen [][2]uint64
+	res, err := opts.Run(ctx, s, func(_ context.Context, from, to uint64) (int, error) {
+		seen = append(seen, [2]uint64{from, to})
+		if len(seen) == 2 {
+			cancel()
+		}
+		return int(to - from + 1), nil
+	})
+	if !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("Run = %v, want ErrInterrupted", err)
+	}
+	if res.Next != 20 || res.Items != 10 {
+		t.Fatalf("interrupted at next=%d items=%d, want 20/10", res.Next, res.Items)
+	}
+	st, ok, err := LoadState(path)
+	if err != nil || !ok || st.Next != 20 || st.Items != 10 {
+		t.Fatalf("saved state = %+v ok=%v err=%v", st, ok, err)
+	}
+
+	// 第二次运行从第 20 个区块继续，完成后删除状态文件
+	seen = nil
+	res, err = opts.Run(context.Background(), s, func(_ context.Context, from, to uint64) (int, error) {
+		seen = append(seen, [2]uint64{from, to})
+		return int(to - from + 1), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Resumed || res.Start != 20 || res.Items != 20 {
+		t.Errorf("result = %+v", res)
+	}
+	if want := [][2]uint64{{20, 24}, {25, 29}}; len(seen) != 2 || seen[0] != want[0] || seen[1] != want[1] {
+		t.Errorf("segments = %v, want %v", seen, want)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("state file not removed: %v", err)
+	}
+}
+
+func TestStateMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := (State{Name: "test", Key: "0xabc", From: 10, To: 20, Next: 15}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	noop := func(context.Context, uint64, uint64) (int, error) { return 0, nil }
+
+	opts := &Options{StateFile: path}
+	if _, err := opts.Run(context.Background(), Scan{Name: "test", Key: "0xdef", From: 10, To: 20}, noop); !errors.Is(err, ErrStateMismatch) {
+		t.Errorf("Run with another key = %v, want ErrStateMismatch", err)
+	}
+
+	opts.Restart = true
+	res, err := opts.Run(context.Background(), Scan{Name: "test", Key: "0xdef", From: 10, To: 20}, noop)
+	if err != nil || res.Resumed || res.Start != 10 {
+		t.Errorf("Run with --restart = %+v, %v", res, err)
+	}
+}
+
+func TestFuncError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	opts := &Options{StateFile: path}
+	boom := errors.New("boom")
+	_, err := opts.Run(context.Background(), Scan{Name: "test", From: 1, To: 10, Step: 3}, func(_ context.Context, from, _ uint64) (int, error) {
+		if from == 7 {
+			return 0, boom
+		}
+		return 1, nil
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Run = %v, want boom", err)
+	}
+	// 出错的段下次重新处理
+	if st, _, _ := LoadState(path); st.Next != 7 || st.Items != 2 {
+		t.Errorf("saved state = %+v, want next=7 items=2", st)
+	}
+}
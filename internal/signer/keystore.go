@@ -0,0 +1,22 @@
+package signer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+// FromKeystore 用密码解密 keystore JSON 文件（Web3 Secret Storage 格式）。
+// 解密使用 scrypt，标准参数下需要约 1 秒和 256MB 内存
+func FromKeystore(path, password string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	k, err := keystore.DecryptKey(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+	}
+	return NewKey(k.PrivateKey), nil
+}
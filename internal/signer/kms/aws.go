@@ -0,0 +1,47 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/yzucdh1/examples/internal/signer"
+)
+
+// openAWS 使用 AWS KMS 中的非对称密钥（KeySpec ECC_SECG_P256K1，KeyUsage SIGN_VERIFY）。
+// ref 可以是 key id、alias/<name> 或 ARN；区域和凭证来自 AWS SDK 默认链（AWS_REGION、AWS_PROFILE、IAM 角色等）
+func openAWS(ctx context.Context, ref string) (signer.Signer, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("aws kms: failed to load AWS config: %w", err)
+	}
+	client := awskms.NewFromConfig(cfg)
+
+	out, err := client.GetPublicKey(ctx, &awskms.GetPublicKeyInput{KeyId: aws.String(ref)})
+	if err != nil {
+		return nil, fmt.Errorf("aws kms: failed to get public key for %s: %w", ref, err)
+	}
+	if out.KeySpec != kmstypes.KeySpecEccSecgP256k1 {
+		return nil, fmt.Errorf("aws kms: key %s has spec %s, want %s", ref, out.KeySpec, kmstypes.KeySpecEccSecgP256k1)
+	}
+	pub, err := parsePublicKey(out.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("aws kms: %w", err)
+	}
+
+	return newKMSSigner(pub, func(ctx context.Context, digest []byte) ([]byte, error) {
+		res, err := client.Sign(ctx, &awskms.SignInput{
+			KeyId:            aws.String(ref),
+			Message:          digest,
+			MessageType:      kmstypes.MessageTypeDigest, // 已经是 keccak256 哈希，KMS 不再做 SHA-256
+			SigningAlgorithm: kmstypes.SigningAlgorithmSpecEcdsaSha256,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("aws kms: sign: %w", err)
+		}
+		return res.Signature, nil
+	}), nil
+}
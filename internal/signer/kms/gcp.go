@@ -0,0 +1,100 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/yzucdh1/examples/internal/signer"
+	"golang.org/x/oauth2/google"
+)
+
+// gcpKMSEndpoint Cloud KMS REST API 地址（测试时替换）
+var gcpKMSEndpoint = "https://cloudkms.googleapis.com/v1/"
+
+// openGCP 使用 Cloud KMS 中算法为 EC_SIGN_SECP256K1_SHA256 的密钥版本，
+// ref 为完整资源名 projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>/cryptoKeyVersions/<n>。
+// 凭证来自 Application Default Credentials（GOOGLE_APPLICATION_CREDENTIALS、gcloud auth application-default login 或元数据服务）。
+// 直接调用 REST API 而不是 cloud.google.com/go/kms，避免为一个签名接口引入整套 gRPC 依赖
+func openGCP(ctx context.Context, ref string) (signer.Signer, error) {
+	client, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/cloudkms")
+	if err != nil {
+		return nil, fmt.Errorf("gcp kms: failed to load credentials: %w", err)
+	}
+	return newGCPSigner(ctx, client, ref)
+}
+
+func newGCPSigner(ctx context.Context, client *http.Client, ref string) (signer.Signer, error) {
+	var key struct {
+		Pem       string `json:"pem"`
+		Algorithm string `json:"algorithm"`
+	}
+	if err := gcpCall(ctx, client, http.MethodGet, ref+"/publicKey", nil, &key); err != nil {
+		return nil, err
+	}
+	if key.Algorithm != "EC_SIGN_SECP256K1_SHA256" {
+		return nil, fmt.Errorf("gcp kms: key %s has algorithm %s, want EC_SIGN_SECP256K1_SHA256", ref, key.Algorithm)
+	}
+	block, _ := pem.Decode([]byte(key.Pem))
+	if block == nil {
+		return nil, errors.New("gcp kms: invalid PEM public key")
+	}
+	pub, err := parsePublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("gcp kms: %w", err)
+	}
+
+	return newKMSSigner(pub, func(ctx context.Context, digest []byte) ([]byte, error) {
+		// 摘要字段名为 sha256，但 KMS 只检查长度，传入 keccak256 哈希即可
+		req := map[string]any{"digest": map[string]string{"sha256": base64.StdEncoding.EncodeToString(digest)}}
+		var res struct {
+			Signature []byte `json:"signature"` // base64，encoding/json 自动解码
+		}
+		if err := gcpCall(ctx, client, http.MethodPost, ref+":asymmetricSign", req, &res); err != nil {
+			return nil, err
+		}
+		return res.Signature, nil
+	}), nil
+}
+
+// gcpCall 调用 Cloud KMS REST API，in 非 nil 时作为 JSON 请求体
+func gcpCall(ctx context.Context, client *http.Client, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, gcpKMSEndpoint+path, body)
+	if err != nil {
+		return fmt.Errorf("gcp kms: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gcp kms: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("gcp kms: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gcp kms: %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("gcp kms: invalid response: %w", err)
+	}
+	return nil
+}
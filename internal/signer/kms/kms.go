@@ -0,0 +1,138 @@
+// Package kms 把云 KMS 和 HashiCorp Vault 接入 internal/signer，导入本包即注册以下 scheme：
+// - kms:<key-id | alias/... | arn:...>          AWS KMS，密钥规格 ECC_SECG_P256K1，凭证走 AWS SDK 默认链
+// - gcp-kms:projects/.../cryptoKeyVersions/N    GCP Cloud KMS，算法 EC_SIGN_SECP256K1_SHA256，凭证走 ADC
+// - vault:<path>[#field]                        Vault KV（v1 / v2）中保存的十六进制私钥，默认字段 private_key
+//
+// KMS 的私钥不离开 HSM：这里只把交易哈希作为 digest 交给 KMS 签名，再把返回的 DER 编码 (r, s)
+// 转换为以太坊的 65 字节 r || s || v：
+// - KMS 不保证 low-s，s > n/2 时取 n - s（EIP-2 要求，否则节点拒绝交易）
+// - KMS 不返回 recovery id，v 通过尝试 0 / 1 并与公钥比对得到
+//
+// Vault 的 Transit 引擎不支持 secp256k1，因此 vault: 只是从 KV 读取私钥后在进程内存中签名，
+// 避免私钥出现在环境变量、命令行或磁盘文件中；需要私钥完全不出 HSM 时请使用 kms: / gcp-kms:。
+//
+// 用法：
+//
+//	import _ "github.com/yzucdh1/examples/internal/signer/kms"
+//
+//	go run . --signer kms:alias/deployer ...
+//	SENDER_SIGNER=gcp-kms:projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1 go run . ...
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/signer"
+)
+
+func init() {
+	signer.Register("kms", openAWS)
+	signer.Register("gcp-kms", openGCP)
+	signer.Register("vault", openVault)
+}
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// digestSigner 对 32 字节摘要签名，返回 DER 编码的 ECDSA 签名
+type digestSigner func(ctx context.Context, digest []byte) ([]byte, error)
+
+// kmsSigner 公钥在创建时取得并推导地址，签名时只把摘要交给 KMS
+type kmsSigner struct {
+	pub  *ecdsa.PublicKey
+	addr common.Address
+	sign digestSigner
+}
+
+func newKMSSigner(pub *ecdsa.PublicKey, sign digestSigner) *kmsSigner {
+	return &kmsSigner{pub: pub, addr: crypto.PubkeyToAddress(*pub), sign: sign}
+}
+
+func (k *kmsSigner) Address() common.Address { return k.addr }
+
+func (k *kmsSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	s := types.LatestSignerForChainID(chainID)
+	sig, err := k.signHash(ctx, s.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(s, sig)
+}
+
+func (k *kmsSigner) SignMessage(ctx context.Context, msg []byte) ([]byte, error) {
+	sig, err := k.signHash(ctx, accounts.TextHash(msg))
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// signHash 返回 v 为 0 / 1 的 65 字节签名（与 crypto.Sign 相同）
+func (k *kmsSigner) signHash(ctx context.Context, hash []byte) ([]byte, error) {
+	der, err := k.sign(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return derToEthSignature(der, hash, k.pub)
+}
+
+// derToEthSignature 把 DER 编码的 (r, s) 转换为 r || s || v：s 规范化为 low-s，v 由公钥恢复确定
+func derToEthSignature(der, hash []byte, pub *ecdsa.PublicKey) ([]byte, error) {
+	var rs struct{ R, S *big.Int }
+	rest, err := asn1.Unmarshal(der, &rs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DER signature: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("invalid DER signature: trailing data")
+	}
+	if rs.R.Sign() <= 0 || rs.S.Sign() <= 0 || rs.R.Cmp(secp256k1N) >= 0 || rs.S.Cmp(secp256k1N) >= 0 {
+		return nil, errors.New("invalid DER signature: r or s out of range")
+	}
+	if rs.S.Cmp(secp256k1HalfN) > 0 {
+		rs.S.Sub(secp256k1N, rs.S)
+	}
+
+	sig := make([]byte, 65)
+	rs.R.FillBytes(sig[:32])
+	rs.S.FillBytes(sig[32:64])
+	want := crypto.FromECDSAPub(pub)
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		got, err := crypto.Ecrecover(hash, sig)
+		if err == nil && string(got) == string(want) {
+			return sig, nil
+		}
+	}
+	return nil, errors.New("signature does not match the KMS public key (is the key secp256k1?)")
+}
+
+// parsePublicKey 解析 DER 编码的 SubjectPublicKeyInfo。
+// x509.ParsePKIXPublicKey 不支持 secp256k1 曲线，这里直接取出未压缩的公钥点
+func parsePublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &spki); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	pub, err := crypto.UnmarshalPubkey(spki.PublicKey.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("public key is not secp256k1: %w", err)
+	}
+	return pub, nil
+}
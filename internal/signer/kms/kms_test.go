@@ -0,0 +1,219 @@
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// derSign 模拟 KMS：用本地私钥签名并返回 DER 编码的 (r, s)，highS 为 true 时故意返回 n - s
+func derSign(t *testing.T, key *ecdsa.PrivateKey, digest []byte, highS bool) []byte {
+	t.Helper()
+	sig, err := crypto.Sign(digest, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	if highS {
+		s.Sub(secp256k1N, s)
+	}
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// spki 把公钥编码为 DER SubjectPublicKeyInfo（id-ecPublicKey + secp256k1）
+func spki(t *testing.T, pub *ecdsa.PublicKey) []byte {
+	t.Helper()
+	curve, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 10})
+	point := crypto.FromECDSAPub(pub)
+	der, err := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}, Parameters: asn1.RawValue{FullBytes: curve}},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: len(point) * 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestDERToEthSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := crypto.Keccak256([]byte("hello"))
+	for _, highS := range []bool{false, true} {
+		sig, err := derToEthSignature(derSign(t, key, hash, highS), hash, &key.PublicKey)
+		if err != nil {
+			t.Fatalf("highS=%v: %v", highS, err)
+		}
+		if s := new(big.Int).SetBytes(sig[32:64]); s.Cmp(secp256k1HalfN) > 0 {
+			t.Errorf("highS=%v: s is not normalized to low-s", highS)
+		}
+		pub, err := crypto.SigToPub(hash, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if crypto.PubkeyToAddress(*pub) != crypto.PubkeyToAddress(key.PublicKey) {
+			t.Errorf("highS=%v: recovered address does not match key", highS)
+		}
+	}
+
+	other, _ := crypto.GenerateKey()
+	if _, err := derToEthSignature(derSign(t, key, hash, false), hash, &other.PublicKey); err == nil {
+		t.Error("signature from another key accepted, want error")
+	}
+	if _, err := derToEthSignature([]byte{0x30, 0x00}, hash, &key.PublicKey); err == nil {
+		t.Error("empty DER accepted, want error")
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := parsePublicKey(spki(t, &key.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Error("parsed public key does not match")
+	}
+}
+
+// fakeGCP 模拟 Cloud KMS 的 publicKey 和 asymmetricSign 接口，签名总是返回 high-s
+func fakeGCP(t *testing.T, key *ecdsa.PrivateKey, name string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/"+name+"/publicKey":
+			pemKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: spki(t, &key.PublicKey)})
+			json.NewEncoder(w).Encode(map[string]string{"pem": string(pemKey), "algorithm": "EC_SIGN_SECP256K1_SHA256"})
+		case r.Method == http.MethodPost && r.URL.Path == "/"+name+":asymmetricSign":
+			var req struct {
+				Digest struct {
+					SHA256 []byte `json:"sha256"`
+				} `json:"digest"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string][]byte{"signature": derSign(t, key, req.Digest.SHA256, true)})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestGCPSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	name := "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"
+	srv := fakeGCP(t, key, name)
+	defer srv.Close()
+	old := gcpKMSEndpoint
+	gcpKMSEndpoint = srv.URL + "/"
+	defer func() { gcpKMSEndpoint = old }()
+
+	ctx := context.Background()
+	s, err := newGCPSigner(ctx, srv.Client(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Address() != addr {
+		t.Fatalf("address = %s, want %s", s.Address().Hex(), addr.Hex())
+	}
+
+	chainID := big.NewInt(11155111)
+	to := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &to, Value: big.NewInt(1)})
+	signed, err := s.SignTx(ctx, tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(chainID), signed); err != nil || from != addr {
+		t.Errorf("sender = %s (%v), want %s", from.Hex(), err, addr.Hex())
+	}
+
+	msg := []byte("hello")
+	sig, err := s.SignMessage(ctx, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig[64] != 27 && sig[64] != 28 {
+		t.Fatalf("v = %d, want 27 or 28", sig[64])
+	}
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash(msg), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != addr {
+		t.Error("recovered address does not match signer")
+	}
+}
+
+func TestOpenVault(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hexKey := common.Bytes2Hex(crypto.FromECDSA(key))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/eth/deployer": // KV v2
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": map[string]string{"private_key": hexKey}}})
+		case "/v1/kv/eth/deployer": // KV v1
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"key": "0x" + hexKey}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("VAULT_NAMESPACE", "")
+
+	want := crypto.PubkeyToAddress(key.PublicKey)
+	for _, ref := range []string{"secret/data/eth/deployer", "kv/eth/deployer#key"} {
+		s, err := openVault(context.Background(), ref)
+		if err != nil {
+			t.Fatalf("%s: %v", ref, err)
+		}
+		if s.Address() != want {
+			t.Errorf("%s: address = %s, want %s", ref, s.Address().Hex(), want.Hex())
+		}
+	}
+	if _, err := openVault(context.Background(), "secret/data/eth/deployer#missing"); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("missing field: err = %v, want error naming the field", err)
+	}
+}
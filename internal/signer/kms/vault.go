@@ -0,0 +1,65 @@
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/yzucdh1/examples/internal/signer"
+)
+
+// openVault 从 Vault KV 读取私钥，ref 为 API 路径（不含 /v1/），例如 secret/data/eth/deployer#private_key。
+// 地址和令牌来自 Vault CLI 的标准环境变量 VAULT_ADDR、VAULT_TOKEN（以及可选的 VAULT_NAMESPACE）
+func openVault(ctx context.Context, ref string) (signer.Signer, error) {
+	path, field, _ := strings.Cut(ref, "#")
+	if field == "" {
+		field = "private_key"
+	}
+	addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return nil, errors.New("vault: VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault: GET %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	// KV v2 的数据在 data.data 下，KV v1 直接在 data 下
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("vault: invalid response: %w", err)
+	}
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]any); ok {
+		data = inner
+	}
+	hexKey, ok := data[field].(string)
+	if !ok || hexKey == "" {
+		return nil, fmt.Errorf("vault: secret %s has no string field %q", path, field)
+	}
+	return signer.FromHex(hexKey)
+}
@@ -0,0 +1,89 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultHDPath 以太坊账户的默认派生路径（BIP-44，第一个账户）
+const DefaultHDPath = "m/44'/60'/0'/0/0"
+
+// FromMnemonic 按 BIP-39 由助记词和可选口令生成种子，再按 BIP-32 沿 path 派生私钥；path 为空时使用 DefaultHDPath。
+// 注意：这里不校验助记词的单词表和校验和，输错一个单词会得到另一个地址，使用前请核对 Address()
+func FromMnemonic(mnemonic, passphrase, path string) (*Key, error) {
+	words := strings.Fields(mnemonic)
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("invalid mnemonic: got %d words, want 12, 15, 18, 21 or 24", len(words))
+	}
+	if path == "" {
+		path = DefaultHDPath
+	}
+	dpath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path: %w", err)
+	}
+
+	// BIP-39：PBKDF2-HMAC-SHA512，2048 轮，salt 为 "mnemonic" + 口令
+	seed, err := pbkdf2.Key(sha512.New, strings.Join(words, " "), []byte("mnemonic"+passphrase), 2048, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	// BIP-32：主密钥 = HMAC-SHA512("Bitcoin seed", seed)，左 32 字节为私钥，右 32 字节为 chain code
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+
+	n := crypto.S256().Params().N
+	for _, index := range dpath {
+		var data []byte
+		if index >= 0x80000000 {
+			// 强化派生：0x00 || 父私钥
+			data = append([]byte{0}, keyBytes(key)...)
+		} else {
+			// 普通派生：压缩父公钥
+			priv, err := crypto.ToECDSA(keyBytes(key))
+			if err != nil {
+				return nil, err
+			}
+			data = crypto.CompressPubkey(&priv.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+		il := new(big.Int).SetBytes(sum[:32])
+		if il.Cmp(n) >= 0 {
+			return nil, errors.New("invalid derived key, try the next index")
+		}
+		key = il.Add(il, key).Mod(il, n)
+		if key.Sign() == 0 {
+			return nil, errors.New("invalid derived key, try the next index")
+		}
+		chainCode = sum[32:]
+	}
+
+	priv, err := crypto.ToECDSA(keyBytes(key))
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(priv), nil
+}
+
+// keyBytes 把私钥标量编码为 32 字节大端
+func keyBytes(k *big.Int) []byte {
+	return k.FillBytes(make([]byte, 32))
+}
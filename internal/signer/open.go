@@ -0,0 +1,56 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Opener 按 spec 中冒号之后的部分（ref）创建 Signer
+type Opener func(ctx context.Context, ref string) (Signer, error)
+
+var (
+	openersMu sync.RWMutex
+	openers   = make(map[string]Opener)
+)
+
+// Register 注册 scheme 对应的 Opener，通常在实现包的 init 中调用（与 database/sql 的驱动注册相同）。
+// 重复注册同一 scheme 会 panic
+func Register(scheme string, open Opener) {
+	openersMu.Lock()
+	defer openersMu.Unlock()
+	if _, dup := openers[scheme]; dup {
+		panic("signer: Register called twice for scheme " + scheme)
+	}
+	openers[scheme] = open
+}
+
+// Open 按 "scheme:ref" 形式的 spec 创建 Signer，例如 kms:alias/deployer。
+// scheme 需要先由实现包注册，云 KMS / Vault 见 internal/signer/kms
+func Open(ctx context.Context, spec string) (Signer, error) {
+	scheme, ref, ok := strings.Cut(spec, ":")
+	if !ok || ref == "" {
+		return nil, fmt.Errorf("invalid signer %q, want scheme:ref", spec)
+	}
+	openersMu.RLock()
+	open, ok := openers[scheme]
+	openersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown signer scheme %q (registered: %s)", scheme, strings.Join(Schemes(), ", "))
+	}
+	return open(ctx, ref)
+}
+
+// Schemes 已注册的 scheme，按名称排序
+func Schemes() []string {
+	openersMu.RLock()
+	defer openersMu.RUnlock()
+	names := make([]string, 0, len(openers))
+	for name := range openers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,109 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Remote 通过远程签名服务签名，使用 Clef 的 account_signTransaction / account_signData 接口。
+// 每次签名都可能需要在签名服务一侧人工确认，ctx 的超时要留出足够时间
+type Remote struct {
+	client *rpc.Client
+	addr   common.Address
+}
+
+// DialRemote 连接签名服务（http(s)、ws(s) 或 IPC 路径），并确认其管理 addr 这个账户
+func DialRemote(ctx context.Context, url string, addr common.Address) (*Remote, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to remote signer: %w", err)
+	}
+	var list []common.Address
+	if err := client.CallContext(ctx, &list, "account_list"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to list remote signer accounts: %w", err)
+	}
+	for _, a := range list {
+		if a == addr {
+			return &Remote{client: client, addr: addr}, nil
+		}
+	}
+	client.Close()
+	return nil, fmt.Errorf("remote signer does not manage %s", addr.Hex())
+}
+
+func (r *Remote) Address() common.Address { return r.addr }
+
+// Close 关闭与签名服务的连接
+func (r *Remote) Close() { r.client.Close() }
+
+func (r *Remote) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	input := hexutil.Bytes(tx.Data())
+	args := apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(r.addr),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Input:   &input,
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if to := tx.To(); to != nil {
+		m := common.NewMixedcaseAddress(*to)
+		args.To = &m
+	}
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("remote signer: unsupported tx type %d", tx.Type())
+	}
+	if tx.Type() != types.LegacyTxType {
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+
+	var res struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := r.client.CallContext(ctx, &res, "account_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("remote signer: %w", err)
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(res.Raw); err != nil {
+		return nil, fmt.Errorf("remote signer returned invalid transaction: %w", err)
+	}
+	// 签名服务可能改写字段（例如规则引擎调整了 gas），返回前确认签名者和签名内容都没有变化
+	s := types.LatestSignerForChainID(chainID)
+	if from, err := types.Sender(s, signed); err != nil || from != r.addr {
+		return nil, fmt.Errorf("remote signer returned a transaction not signed by %s", r.addr.Hex())
+	}
+	if s.Hash(signed) != s.Hash(tx) {
+		return nil, errors.New("remote signer modified the transaction")
+	}
+	return signed, nil
+}
+
+func (r *Remote) SignMessage(ctx context.Context, msg []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	if err := r.client.CallContext(ctx, &sig, "account_signData", "text/plain", common.NewMixedcaseAddress(r.addr), hexutil.Bytes(msg)); err != nil {
+		return nil, fmt.Errorf("remote signer: %w", err)
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("remote signer returned a %d-byte signature", len(sig))
+	}
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
@@ -0,0 +1,97 @@
+// Package signer 可替换的签名抽象：示例只依赖 Signer 接口，密钥从哪里来由配置决定。
+// 内置四种实现：
+// - 十六进制私钥（SENDER_PRIVATE_KEY 或 profile 中的 env / file）
+// - keystore JSON 文件（geth account new / clef 生成的加密私钥）
+// - BIP-39 助记词 + BIP-32 派生路径（与 MetaMask、anvil、hardhat 的默认账户一致）
+// - 远程签名服务（Clef 的 account_* JSON-RPC 接口），私钥不离开签名进程
+//
+// 接入自己的密钥管理（HSM、KMS、MPC 等）只需实现 Signer 接口，并用 Register 注册一个 scheme，
+// 之后即可通过 --signer scheme:ref、SENDER_SIGNER 或 profile 中的 key.signer 选择。
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yzucdh1/examples/internal/config"
+	"github.com/yzucdh1/examples/internal/ethutil"
+)
+
+// Signer 交易与消息签名
+type Signer interface {
+	// Address 签名账户的地址
+	Address() common.Address
+	// SignTx 按 chainID 对交易签名，返回带签名的新交易
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// SignMessage 按 EIP-191（personal_sign）对消息签名，返回 65 字节 r || s || v，v 为 27 / 28
+	SignMessage(ctx context.Context, msg []byte) ([]byte, error)
+}
+
+// Key 使用内存中私钥的 Signer，十六进制私钥、keystore、助记词最终都得到 Key
+type Key struct {
+	key  *ecdsa.PrivateKey
+	addr common.Address
+}
+
+// NewKey 用已解析的私钥创建 Signer
+func NewKey(key *ecdsa.PrivateKey) *Key {
+	return &Key{key: key, addr: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// FromHex 解析十六进制私钥（可带 0x 前缀）
+func FromHex(hexKey string) (*Key, error) {
+	key, _, err := ethutil.LoadPrivateKey(hexKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(key), nil
+}
+
+func (k *Key) Address() common.Address { return k.addr }
+
+func (k *Key) SignTx(_ context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), k.key)
+}
+
+func (k *Key) SignMessage(_ context.Context, msg []byte) ([]byte, error) {
+	sig, err := crypto.Sign(accounts.TextHash(msg), k.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// FromConfig 按配置选择签名方式：Signer（--signer / SENDER_SIGNER）优先，
+// 其次是 SENDER_PRIVATE_KEY / env / file 的十六进制私钥，再次是 profile 中的 key.signer，
+// 最后是 keystore、mnemonic_env 或 remote。config.Load 已按此顺序填好 Signer 和 PrivateKey
+func FromConfig(ctx context.Context, cfg *config.Config) (Signer, error) {
+	if cfg.Signer != "" {
+		return Open(ctx, cfg.Signer)
+	}
+	if cfg.PrivateKey != "" {
+		return FromHex(cfg.PrivateKey)
+	}
+	k := cfg.Key
+	switch {
+	case k.Keystore != "":
+		return FromKeystore(k.Keystore, os.Getenv(k.PasswordEnv))
+	case k.MnemonicEnv != "":
+		mnemonic := os.Getenv(k.MnemonicEnv)
+		if mnemonic == "" {
+			return nil, fmt.Errorf("%s is not set", k.MnemonicEnv)
+		}
+		return FromMnemonic(mnemonic, "", k.HDPath)
+	case k.Remote != "":
+		return DialRemote(ctx, k.Remote, common.HexToAddress(k.Address))
+	}
+	return nil, errors.New("no signer: set SENDER_PRIVATE_KEY, pass --signer or configure a key for the profile")
+}
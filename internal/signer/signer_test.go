@@ -0,0 +1,172 @@
+package signer
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// anvil / hardhat 的默认助记词
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestFromMnemonic(t *testing.T) {
+	tests := []struct {
+		path string
+		want common.Address
+	}{
+		{"", common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")},
+		{"m/44'/60'/0'/0/1", common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")},
+	}
+	for _, tt := range tests {
+		k, err := FromMnemonic(testMnemonic, "", tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Address() != tt.want {
+			t.Errorf("path %q: address = %s, want %s", tt.path, k.Address().Hex(), tt.want.Hex())
+		}
+	}
+	if _, err := FromMnemonic("test test", "", ""); err == nil {
+		t.Error("FromMnemonic with 2 words succeeded, want error")
+	}
+}
+
+func TestKeySignMessage(t *testing.T) {
+	k, err := FromMnemonic(testMnemonic, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("hello")
+	sig, err := k.SignMessage(context.Background(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig[64] != 27 && sig[64] != 28 {
+		t.Fatalf("v = %d, want 27 or 28", sig[64])
+	}
+	recoverSig := append([]byte(nil), sig...)
+	recoverSig[64] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash(msg), recoverSig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != k.Address() {
+		t.Error("recovered address does not match signer")
+	}
+}
+
+func TestFromKeystore(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.PubkeyToAddress(priv.PublicKey)
+	data, err := keystore.EncryptKey(&keystore.Key{Address: addr, PrivateKey: priv}, "pw", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := FromKeystore(path, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Address() != addr {
+		t.Errorf("address = %s, want %s", k.Address().Hex(), addr.Hex())
+	}
+	if _, err := FromKeystore(path, "wrong"); err == nil {
+		t.Error("FromKeystore with wrong password succeeded, want error")
+	}
+}
+
+// fakeClef 用本地私钥实现 Clef 的 account_* 接口
+type fakeClef struct{ key *Key }
+
+func (c *fakeClef) List() []common.Address { return []common.Address{c.key.Address()} }
+
+func (c *fakeClef) SignTransaction(args apitypes.SendTxArgs) (map[string]hexutil.Bytes, error) {
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := c.key.SignTx(context.Background(), tx, (*big.Int)(args.ChainID))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	return map[string]hexutil.Bytes{"raw": raw}, err
+}
+
+func (c *fakeClef) SignData(contentType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	return c.key.SignMessage(context.Background(), data)
+}
+
+func TestRemote(t *testing.T) {
+	k, err := FromMnemonic(testMnemonic, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", &fakeClef{key: k}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	ctx := context.Background()
+	if _, err := DialRemote(ctx, ts.URL, common.HexToAddress("0x01")); err == nil {
+		t.Error("DialRemote with unmanaged address succeeded, want error")
+	}
+	r, err := DialRemote(ctx, ts.URL, k.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	chainID := big.NewInt(11155111)
+	to := common.HexToAddress("0x02")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     3,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(30e9),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+	signed, err := r.SignTx(ctx, tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := k.SignTx(ctx, tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.Hash() != want.Hash() {
+		t.Errorf("remote tx hash = %s, want %s", signed.Hash().Hex(), want.Hash().Hex())
+	}
+
+	sig, err := r.SignMessage(ctx, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSig, _ := k.SignMessage(ctx, []byte("hello"))
+	if hexutil.Encode(sig) != hexutil.Encode(wantSig) {
+		t.Errorf("remote signature = %x, want %x", sig, wantSig)
+	}
+}
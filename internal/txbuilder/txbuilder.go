@@ -0,0 +1,358 @@
+// Package txbuilder 按"意图"构造并签名交易：调用方只给出 to / value / data、交易类型、费用策略、
+// access list、blobs 或 EIP-7702 授权，nonce、gas、费用由 Builder 从节点补全，
+// 再生成对应的 types.TxData（LegacyTx / AccessListTx / DynamicFeeTx / BlobTx / SetCodeTx）并交给 internal/signer 签名。
+//
+// 取代此前在 03、08、10、ethx 中各自手写的 DynamicFeeTx 构造：
+//   - gas：无 data 的普通转账固定 21000，其余为 eth_estimateGas 的结果加 20% 余量
+//   - 费用：EIP-1559 类型默认 ethutil.DynamicFees（base fee × 2 + tip），可替换为 gasoracle 等策略；
+//     legacy / access list 类型使用节点的 eth_gasPrice
+//   - blob：由 blobs 计算 KZG 承诺、证明和 versioned hash，blob 费用上限默认为当前 blob base fee × 2
+//   - 发送前检查余额是否覆盖 value + gas × fee cap（+ blob gas × blob fee cap）
+package txbuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+	"github.com/yzucdh1/examples/internal/ethutil"
+	"github.com/yzucdh1/examples/internal/rpcerr"
+	"github.com/yzucdh1/examples/internal/signer"
+)
+
+// Type 交易类型。零值 Auto：有 blobs 时为 blob 交易，有授权列表时为 set-code 交易，否则为 EIP-1559 交易
+type Type int
+
+const (
+	Auto Type = iota
+	Legacy
+	AccessList // EIP-2930
+	DynamicFee // EIP-1559
+	Blob       // EIP-4844
+	SetCode    // EIP-7702
+)
+
+// typeNames 命令行中使用的类型名称（与 38-tx-builder 相同）
+var typeNames = map[string]Type{
+	"legacy":     Legacy,
+	"accesslist": AccessList,
+	"dynamic":    DynamicFee,
+	"blob":       Blob,
+	"setcode":    SetCode,
+}
+
+// ParseType 解析类型名称：legacy | accesslist | dynamic | blob | setcode，空字符串为 Auto
+func ParseType(s string) (Type, error) {
+	if s == "" {
+		return Auto, nil
+	}
+	t, ok := typeNames[s]
+	if !ok {
+		return Auto, fmt.Errorf("unknown tx type %q (use legacy, accesslist, dynamic, blob or setcode)", s)
+	}
+	return t, nil
+}
+
+func (t Type) String() string {
+	for name, v := range typeNames {
+		if v == t {
+			return name
+		}
+	}
+	return "auto"
+}
+
+// Backend 构造交易需要的节点能力，*ethclient.Client 和 10-multi-node-pool 的连接池都满足该接口
+type Backend interface {
+	ethutil.FeeBackend
+	ChainID(ctx context.Context) (*big.Int, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// blobFeeBackend 可选能力：查询当前 blob base fee（eth_blobBaseFee），*ethclient.Client 满足
+type blobFeeBackend interface {
+	BlobBaseFee(ctx context.Context) (*big.Int, error)
+}
+
+// FeeFunc EIP-1559 费用策略，返回 tip cap 和 fee cap
+type FeeFunc func(ctx context.Context) (tipCap, feeCap *big.Int, err error)
+
+// Intent 调用方想发送的交易，未设置的字段由 Builder 补全
+type Intent struct {
+	Type  Type
+	To    *common.Address // nil 表示创建合约（blob / set-code 交易不允许）
+	Value *big.Int        // nil 视为 0
+	Data  []byte
+
+	AccessList types.AccessList             // legacy 之外的类型可用
+	Blobs      []kzg4844.Blob               // blob 交易的数据
+	CellProofs bool                         // blob sidecar 使用 Osaka 之后的 cell proofs（BlobSidecarVersion1）
+	AuthList   []types.SetCodeAuthorization // set-code 交易的授权列表
+
+	// 以下字段非零时直接使用，不再向节点查询
+	Nonce      *uint64
+	Gas        uint64
+	GasPrice   *big.Int // legacy / access list
+	Fees       FeeFunc  // 其余类型；为 nil 时使用 Builder 的策略
+	BlobFeeCap *big.Int
+}
+
+// Builder 绑定节点和签名账户
+type Builder struct {
+	backend Backend
+	signer  signer.Signer
+
+	// Fees 默认的 EIP-1559 费用策略，nil 时使用 ethutil.DynamicFees
+	Fees FeeFunc
+	// SkipBalanceCheck 为 true 时不检查余额（例如离线构造或由调用方统一检查）
+	SkipBalanceCheck bool
+}
+
+// New 创建 Builder
+func New(backend Backend, sgn signer.Signer) *Builder {
+	return &Builder{backend: backend, signer: sgn}
+}
+
+// Build 补全 nonce、gas、费用并返回未签名的交易
+func (b *Builder) Build(ctx context.Context, in Intent) (*types.Transaction, error) {
+	tx, _, err := b.build(ctx, in)
+	return tx, err
+}
+
+// build 同时返回链 ID：未签名的 legacy 交易无法从 V 推导链 ID，签名时需要单独传入
+func (b *Builder) build(ctx context.Context, in Intent) (*types.Transaction, *big.Int, error) {
+	typ := in.Type
+	if typ == Auto {
+		switch {
+		case len(in.Blobs) > 0:
+			typ = Blob
+		case len(in.AuthList) > 0:
+			typ = SetCode
+		default:
+			typ = DynamicFee
+		}
+	}
+	if err := in.check(typ); err != nil {
+		return nil, nil, err
+	}
+	from := b.signer.Address()
+	value := in.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	chainID, err := b.backend.ChainID(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	var nonce uint64
+	if in.Nonce != nil {
+		nonce = *in.Nonce
+	} else if nonce, err = b.backend.PendingNonceAt(ctx, from); err != nil {
+		return nil, nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	// 费用
+	var gasPrice, tipCap, feeCap, blobFeeCap *big.Int
+	if typ == Legacy || typ == AccessList {
+		gasPrice = in.GasPrice
+		if gasPrice == nil {
+			if gasPrice, err = b.backend.SuggestGasPrice(ctx); err != nil {
+				return nil, nil, fmt.Errorf("failed to get gas price: %w", err)
+			}
+		}
+	} else {
+		fees := in.Fees
+		if fees == nil {
+			fees = b.Fees
+		}
+		if fees == nil {
+			fees = func(ctx context.Context) (*big.Int, *big.Int, error) { return ethutil.DynamicFees(ctx, b.backend) }
+		}
+		if tipCap, feeCap, err = fees(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to estimate fees: %w", err)
+		}
+	}
+
+	// blob sidecar：承诺、证明和 versioned hash
+	var sidecar *types.BlobTxSidecar
+	if typ == Blob {
+		if sidecar, err = newSidecar(in.Blobs, in.CellProofs); err != nil {
+			return nil, nil, err
+		}
+		if blobFeeCap = in.BlobFeeCap; blobFeeCap == nil {
+			bb, ok := b.backend.(blobFeeBackend)
+			if !ok {
+				return nil, nil, errors.New("backend cannot report the blob base fee, set BlobFeeCap")
+			}
+			base, err := bb.BlobBaseFee(ctx)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get blob base fee: %w", err)
+			}
+			blobFeeCap = new(big.Int).Mul(base, big.NewInt(2))
+		}
+	}
+
+	// gas
+	gas := in.Gas
+	if gas == 0 {
+		if in.To != nil && len(in.Data) == 0 && len(in.AccessList) == 0 && typ != Blob && typ != SetCode {
+			gas = 21000
+		} else {
+			msg := ethereum.CallMsg{
+				From:              from,
+				To:                in.To,
+				Value:             value,
+				Data:              in.Data,
+				AccessList:        in.AccessList,
+				AuthorizationList: in.AuthList,
+			}
+			if sidecar != nil {
+				msg.BlobHashes = sidecar.BlobHashes()
+				msg.BlobGasFeeCap = blobFeeCap
+			}
+			if gas, err = b.backend.EstimateGas(ctx, msg); err != nil {
+				return nil, nil, fmt.Errorf("failed to estimate gas: %w", rpcerr.Classify(err))
+			}
+			// 增加 20% 的缓冲，避免 Gas 不足
+			gas = gas * 120 / 100
+		}
+	}
+
+	var data types.TxData
+	switch typ {
+	case Legacy:
+		data = &types.LegacyTx{Nonce: nonce, GasPrice: gasPrice, Gas: gas, To: in.To, Value: value, Data: in.Data}
+	case AccessList:
+		data = &types.AccessListTx{ChainID: chainID, Nonce: nonce, GasPrice: gasPrice, Gas: gas, To: in.To, Value: value, Data: in.Data, AccessList: in.AccessList}
+	case DynamicFee:
+		data = &types.DynamicFeeTx{ChainID: chainID, Nonce: nonce, GasTipCap: tipCap, GasFeeCap: feeCap, Gas: gas, To: in.To, Value: value, Data: in.Data, AccessList: in.AccessList}
+	case Blob:
+		data = &types.BlobTx{
+			ChainID:    uint256.MustFromBig(chainID),
+			Nonce:      nonce,
+			GasTipCap:  uint256.MustFromBig(tipCap),
+			GasFeeCap:  uint256.MustFromBig(feeCap),
+			Gas:        gas,
+			To:         *in.To,
+			Value:      uint256.MustFromBig(value),
+			Data:       in.Data,
+			AccessList: in.AccessList,
+			BlobFeeCap: uint256.MustFromBig(blobFeeCap),
+			BlobHashes: sidecar.BlobHashes(),
+			Sidecar:    sidecar,
+		}
+	case SetCode:
+		data = &types.SetCodeTx{
+			ChainID:    uint256.MustFromBig(chainID),
+			Nonce:      nonce,
+			GasTipCap:  uint256.MustFromBig(tipCap),
+			GasFeeCap:  uint256.MustFromBig(feeCap),
+			Gas:        gas,
+			To:         *in.To,
+			Value:      uint256.MustFromBig(value),
+			Data:       in.Data,
+			AccessList: in.AccessList,
+			AuthList:   in.AuthList,
+		}
+	}
+	tx := types.NewTx(data)
+
+	if !b.SkipBalanceCheck {
+		balance, err := b.backend.BalanceAt(ctx, from, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get balance: %w", err)
+		}
+		// Cost = value + gas × fee cap（legacy 为 gas price）+ blob gas × blob fee cap
+		if cost := tx.Cost(); balance.Cmp(cost) < 0 {
+			return nil, nil, fmt.Errorf("insufficient balance: have %s wei, need %s wei", balance, cost)
+		}
+	}
+	return tx, chainID, nil
+}
+
+// check 检查意图与交易类型是否匹配
+func (in *Intent) check(typ Type) error {
+	switch {
+	case typ < Legacy || typ > SetCode:
+		return fmt.Errorf("unknown tx type %d", typ)
+	case typ == Legacy && len(in.AccessList) > 0:
+		return errors.New("legacy transactions cannot carry an access list")
+	case typ == Blob && len(in.Blobs) == 0:
+		return errors.New("blob transactions need at least one blob")
+	case typ != Blob && len(in.Blobs) > 0:
+		return fmt.Errorf("%s transactions cannot carry blobs", typ)
+	case typ == SetCode && len(in.AuthList) == 0:
+		return errors.New("set-code transactions need at least one authorization")
+	case typ != SetCode && len(in.AuthList) > 0:
+		return fmt.Errorf("%s transactions cannot carry authorizations", typ)
+	case (typ == Blob || typ == SetCode) && in.To == nil:
+		return fmt.Errorf("%s transactions cannot create contracts", typ)
+	}
+	return nil
+}
+
+// newSidecar 为每个 blob 计算 KZG 承诺和证明
+func newSidecar(blobs []kzg4844.Blob, cellProofs bool) (*types.BlobTxSidecar, error) {
+	version := types.BlobSidecarVersion0
+	if cellProofs {
+		version = types.BlobSidecarVersion1
+	}
+	commitments := make([]kzg4844.Commitment, len(blobs))
+	var proofs []kzg4844.Proof
+	for i := range blobs {
+		c, err := kzg4844.BlobToCommitment(&blobs[i])
+		if err != nil {
+			return nil, fmt.Errorf("blob %d: failed to compute commitment: %w", i, err)
+		}
+		commitments[i] = c
+		if cellProofs {
+			ps, err := kzg4844.ComputeCellProofs(&blobs[i])
+			if err != nil {
+				return nil, fmt.Errorf("blob %d: failed to compute cell proofs: %w", i, err)
+			}
+			proofs = append(proofs, ps...)
+			continue
+		}
+		p, err := kzg4844.ComputeBlobProof(&blobs[i], c)
+		if err != nil {
+			return nil, fmt.Errorf("blob %d: failed to compute proof: %w", i, err)
+		}
+		proofs = append(proofs, p)
+	}
+	return types.NewBlobTxSidecar(version, blobs, commitments, proofs), nil
+}
+
+// Sign 构造并签名交易
+func (b *Builder) Sign(ctx context.Context, in Intent) (*types.Transaction, error) {
+	tx, chainID, err := b.build(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := b.signer.SignTx(ctx, tx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return signed, nil
+}
+
+// Send 构造、签名并发送交易，返回已发送的交易
+func (b *Builder) Send(ctx context.Context, in Intent) (*types.Transaction, error) {
+	signed, err := b.Sign(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.backend.SendTransaction(ctx, signed); err != nil {
+		return nil, fmt.Errorf("failed to send transaction: %w", rpcerr.Classify(err))
+	}
+	return signed, nil
+}
@@ -0,0 +1,179 @@
+package txbuilder
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+	"github.com/yzucdh1/examples/internal/signer"
+)
+
+// fakeBackend 固定返回值的节点，记录最后一次估算和发送的交易
+type fakeBackend struct {
+	balance  *big.Int
+	estimate uint64
+	lastCall *ethereum.CallMsg
+	sent     *types.Transaction
+}
+
+func (f *fakeBackend) ChainID(context.Context) (*big.Int, error) { return big.NewInt(1337), nil }
+func (f *fakeBackend) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return 7, nil
+}
+func (f *fakeBackend) EstimateGas(_ context.Context, msg ethereum.CallMsg) (uint64, error) {
+	f.lastCall = &msg
+	return f.estimate, nil
+}
+func (f *fakeBackend) BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error) {
+	return f.balance, nil
+}
+func (f *fakeBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	f.sent = tx
+	return nil
+}
+func (f *fakeBackend) SuggestGasTipCap(context.Context) (*big.Int, error) { return big.NewInt(2), nil }
+func (f *fakeBackend) SuggestGasPrice(context.Context) (*big.Int, error)  { return big.NewInt(30), nil }
+func (f *fakeBackend) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: big.NewInt(10)}, nil
+}
+func (f *fakeBackend) BlobBaseFee(context.Context) (*big.Int, error) { return big.NewInt(3), nil }
+
+func newTestBuilder(t *testing.T) (*Builder, *fakeBackend, *signer.Key) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &fakeBackend{balance: big.NewInt(1e18), estimate: 50000}
+	sgn := signer.NewKey(key)
+	return New(backend, sgn), backend, sgn
+}
+
+func TestSendTypes(t *testing.T) {
+	b, backend, sgn := newTestBuilder(t)
+	to := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	authKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := types.SignSetCode(authKey, types.SetCodeAuthorization{ChainID: *uint256.NewInt(1337), Address: to})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		in       Intent
+		wantType uint8
+		wantGas  uint64
+	}{
+		{"transfer", Intent{To: &to, Value: big.NewInt(1)}, types.DynamicFeeTxType, 21000},
+		{"legacy call", Intent{Type: Legacy, To: &to, Data: []byte{1}}, types.LegacyTxType, 60000},
+		{"access list", Intent{Type: AccessList, To: &to, AccessList: types.AccessList{{Address: to}}}, types.AccessListTxType, 60000},
+		{"create", Intent{Data: []byte{0x60, 0x00}}, types.DynamicFeeTxType, 60000},
+		{"blob", Intent{To: &to, Blobs: []kzg4844.Blob{{}}}, types.BlobTxType, 60000},
+		{"setcode", Intent{To: &to, AuthList: []types.SetCodeAuthorization{auth}}, types.SetCodeTxType, 60000},
+	}
+	var blobTx *types.Transaction
+	for _, tt := range tests {
+		tx, err := b.Send(context.Background(), tt.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if tx.Type() != tt.wantType || tx.Gas() != tt.wantGas || tx.Nonce() != 7 {
+			t.Errorf("%s: type %d gas %d nonce %d, want type %d gas %d nonce 7", tt.name, tx.Type(), tx.Gas(), tx.Nonce(), tt.wantType, tt.wantGas)
+		}
+		if backend.sent != tx {
+			t.Errorf("%s: transaction was not sent", tt.name)
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1337)), tx)
+		if err != nil || from != sgn.Address() {
+			t.Errorf("%s: sender = %s (%v), want %s", tt.name, from.Hex(), err, sgn.Address().Hex())
+		}
+		if tx.Type() == types.BlobTxType {
+			blobTx = tx
+		}
+		switch tx.Type() {
+		case types.LegacyTxType, types.AccessListTxType:
+			if tx.GasPrice().Int64() != 30 {
+				t.Errorf("%s: gas price = %s, want eth_gasPrice 30", tt.name, tx.GasPrice())
+			}
+		default:
+			// ethutil.DynamicFees：fee cap = base fee × 2 + tip
+			if tx.GasTipCap().Int64() != 2 || tx.GasFeeCap().Int64() != 22 {
+				t.Errorf("%s: tip %s fee cap %s, want 2 / 22", tt.name, tx.GasTipCap(), tx.GasFeeCap())
+			}
+		}
+	}
+
+	backend.sent = nil
+	tx, err := b.Sign(context.Background(), Intent{To: &to, Blobs: []kzg4844.Blob{{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.BlobGasFeeCap().Int64() != 6 || len(tx.BlobHashes()) != 1 || tx.BlobTxSidecar() == nil {
+		t.Errorf("blob fee cap %s, %d hashes, sidecar %v", tx.BlobGasFeeCap(), len(tx.BlobHashes()), tx.BlobTxSidecar() != nil)
+	}
+	if tx.BlobHashes()[0] != blobTx.BlobHashes()[0] || backend.sent != nil {
+		t.Error("Sign should build the same blob hash without sending")
+	}
+}
+
+func TestBuildOverridesAndChecks(t *testing.T) {
+	b, backend, _ := newTestBuilder(t)
+	to := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	nonce := uint64(42)
+	tx, err := b.Build(context.Background(), Intent{
+		To:    &to,
+		Data:  []byte{1},
+		Nonce: &nonce,
+		Gas:   100000,
+		Fees: func(context.Context) (*big.Int, *big.Int, error) {
+			return big.NewInt(5), big.NewInt(50), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce() != 42 || tx.Gas() != 100000 || tx.GasFeeCap().Int64() != 50 {
+		t.Errorf("nonce %d gas %d fee cap %s, want overrides", tx.Nonce(), tx.Gas(), tx.GasFeeCap())
+	}
+	if backend.lastCall != nil {
+		t.Error("EstimateGas called although Gas was set")
+	}
+
+	bad := []struct {
+		in   Intent
+		want string
+	}{
+		{Intent{Type: Legacy, To: &to, AccessList: types.AccessList{{Address: to}}}, "access list"},
+		{Intent{Type: Blob, To: &to}, "at least one blob"},
+		{Intent{Type: SetCode}, "authorization"},
+		{Intent{Blobs: []kzg4844.Blob{{}}}, "cannot create contracts"},
+		{Intent{Type: DynamicFee, To: &to, Value: big.NewInt(2e18)}, "insufficient balance"},
+	}
+	for _, tt := range bad {
+		if _, err := b.Build(context.Background(), tt.in); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Build(%+v) err = %v, want %q", tt.in.Type, err, tt.want)
+		}
+	}
+}
+
+func TestParseType(t *testing.T) {
+	for name, want := range typeNames {
+		got, err := ParseType(name)
+		if err != nil || got != want || got.String() != name {
+			t.Errorf("ParseType(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := ParseType("eip1559"); err == nil {
+		t.Error("ParseType(eip1559) succeeded, want error")
+	}
+}
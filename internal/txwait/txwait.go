@@ -0,0 +1,208 @@
+// Package txwait 等待交易上链并达到指定确认数，取代此前 08 中手写的回执轮询和各处的 bind.WaitMined：
+//   - 轮询与订阅结合：节点支持 eth_subscribe（ws / ipc）时每个新区块检查一次，轮询作为兜底；
+//     HTTP 节点只轮询
+//   - 感知重组：每次检查都确认回执所在区块仍是该高度的规范区块，被重组掉时丢弃回执，
+//     继续等待交易重新打包（可能进入另一个区块，也可能回到交易池）
+//   - 超时：ctx 截止或 Config.Timeout 到期时返回 *TimeoutError，其中带有最后看到的回执和确认数，
+//     调用方可以区分"还没上链"和"已上链但确认数不足"
+//   - 节点错误：NotFound 和临时错误（rpcretry.Retryable：网络错误、429 / 5xx 等）只跳过本轮；其他错误（鉴权失败、方法不存在等）
+//     重试也不会成功，直接返回
+package txwait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yzucdh1/examples/internal/rpcretry"
+)
+
+// ErrTimeout 等待超时，可用 errors.Is 判断；具体信息见 *TimeoutError
+var ErrTimeout = errors.New("timed out waiting for transaction")
+
+// Backend 等待需要的节点能力，*ethclient.Client 和 10-multi-node-pool 的连接池都满足该接口
+type Backend interface {
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// headSubscriber 可选能力：订阅新区块（ws / ipc 连接的 *ethclient.Client 可用）
+type headSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// Update 等待过程中的状态变化
+type Update struct {
+	Receipt       *types.Receipt // 当前看到的回执，未上链或被重组掉时为 nil
+	Confirmations uint64         // 回执所在区块到最新区块的区块数（含两端），未上链时为 0
+	Head          uint64         // 最新区块高度
+	Reorged       *types.Receipt // 非 nil 表示这一回执所在的区块刚被重组掉
+}
+
+// Config 等待参数
+type Config struct {
+	// PollInterval 轮询间隔；订阅可用时仍按该间隔兜底检查
+	PollInterval time.Duration
+	// Timeout 最长等待时间，0 表示只受 ctx 限制
+	Timeout time.Duration
+	// OnUpdate 确认数变化或发生重组时调用，可为 nil
+	OnUpdate func(Update)
+}
+
+// DefaultConfig 默认参数：每 3 秒轮询一次，不设超时
+func DefaultConfig() Config {
+	return Config{PollInterval: 3 * time.Second}
+}
+
+// TimeoutError 超时时最后的状态
+type TimeoutError struct {
+	Hash          common.Hash
+	Receipt       *types.Receipt // 最后看到的回执，未上链时为 nil
+	Confirmations uint64
+	Want          uint64
+	Err           error // ctx 的错误
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Receipt == nil {
+		return fmt.Sprintf("timed out waiting for transaction %s: not mined", e.Hash.Hex())
+	}
+	return fmt.Sprintf("timed out waiting for transaction %s: mined in block %d with %d/%d confirmations",
+		e.Hash.Hex(), e.Receipt.BlockNumber.Uint64(), e.Confirmations, e.Want)
+}
+
+func (e *TimeoutError) Is(target error) bool { return target == ErrTimeout }
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// WaitMined 按默认参数等待交易 hash 达到 confirmations 个确认（0 视为 1，即上链即可），返回回执
+func WaitMined(ctx context.Context, b Backend, hash common.Hash, confirmations uint64) (*types.Receipt, error) {
+	return Wait(ctx, b, hash, confirmations, DefaultConfig())
+}
+
+// Wait 按 cfg 等待交易 hash 达到 confirmations 个确认
+func Wait(ctx context.Context, b Backend, hash common.Hash, confirmations uint64, cfg Config) (*types.Receipt, error) {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = DefaultConfig().PollInterval
+	}
+
+	// 订阅失败（例如 HTTP 节点）时 heads / subErr 保持 nil，select 中对应分支永远不会就绪
+	var (
+		heads  chan *types.Header
+		subErr <-chan error
+	)
+	if s, ok := b.(headSubscriber); ok {
+		ch := make(chan *types.Header, 16)
+		if sub, err := s.SubscribeNewHead(ctx, ch); err == nil {
+			defer sub.Unsubscribe()
+			heads, subErr = ch, sub.Err()
+		}
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	w := &waiter{b: b, hash: hash, want: confirmations, onUpdate: cfg.OnUpdate}
+	var head *types.Header
+	for {
+		done, err := w.check(ctx, head)
+		if done {
+			return w.receipt, nil
+		}
+		if err != nil && ctx.Err() == nil {
+			return nil, fmt.Errorf("failed to wait for transaction %s: %w", hash.Hex(), err)
+		}
+		head = nil
+		select {
+		case <-ctx.Done():
+			return nil, &TimeoutError{Hash: hash, Receipt: w.receipt, Confirmations: w.confirmations, Want: confirmations, Err: ctx.Err()}
+		case <-ticker.C:
+		case head = <-heads:
+		case <-subErr:
+			// 订阅断开后退回纯轮询
+			heads, subErr = nil, nil
+		}
+	}
+}
+
+// waiter 一次等待的状态
+type waiter struct {
+	b        Backend
+	hash     common.Hash
+	want     uint64
+	onUpdate func(Update)
+
+	receipt       *types.Receipt
+	confirmations uint64
+}
+
+// check 检查一次，返回是否已达到确认数。head 为订阅收到的新区块，nil 时向节点查询最新区块。
+// 节点的临时错误（包括负载均衡到落后节点时的 NotFound）只跳过本轮，由下一轮重试；其他错误返回给调用方
+func (w *waiter) check(ctx context.Context, head *types.Header) (bool, error) {
+	if head == nil {
+		h, err := w.b.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return false, permanent(err)
+		}
+		head = h
+	}
+
+	// 回执所在区块是否仍是规范链上的区块
+	if w.receipt != nil {
+		block, err := w.b.HeaderByNumber(ctx, w.receipt.BlockNumber)
+		switch {
+		case err == nil && block.Hash() != w.receipt.BlockHash:
+			reorged := w.receipt
+			w.receipt, w.confirmations = nil, 0
+			w.notify(Update{Head: head.Number.Uint64(), Reorged: reorged})
+		case err != nil && !errors.Is(err, ethereum.NotFound):
+			return false, permanent(err)
+		}
+	}
+
+	if w.receipt == nil {
+		r, err := w.b.TransactionReceipt(ctx, w.hash)
+		if err != nil {
+			// NotFound：还在交易池中（或被重组后尚未重新打包）
+			return false, permanent(err)
+		}
+		w.receipt = r
+	}
+
+	var conf uint64
+	if n, at := head.Number.Uint64(), w.receipt.BlockNumber.Uint64(); n >= at {
+		conf = n - at + 1
+	}
+	if conf != w.confirmations {
+		w.confirmations = conf
+		w.notify(Update{Receipt: w.receipt, Confirmations: conf, Head: head.Number.Uint64()})
+	}
+	return conf >= w.want, nil
+}
+
+// permanent 过滤掉可以在下一轮重试的错误：NotFound 和 rpcretry.Retryable 认定的临时错误，其余错误原样返回
+func permanent(err error) error {
+	if errors.Is(err, ethereum.NotFound) || rpcretry.Retryable(err) {
+		return nil
+	}
+	return err
+}
+
+func (w *waiter) notify(u Update) {
+	if w.onUpdate != nil {
+		w.onUpdate(u)
+	}
+}
@@ -0,0 +1,182 @@
+package txwait
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeChain 可控的链：canonical 为各高度的规范区块头，receipt 为节点当前返回的回执
+type fakeChain struct {
+	mu        sync.Mutex
+	canonical []*types.Header
+	receipt   *types.Receipt
+	err       error // 非 nil 时 TransactionReceipt 返回该错误
+}
+
+func header(number uint64, extra byte) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(number), Extra: []byte{extra}}
+}
+
+func (c *fakeChain) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if number == nil {
+		return c.canonical[len(c.canonical)-1], nil
+	}
+	if n := number.Uint64(); n < uint64(len(c.canonical)) {
+		return c.canonical[n], nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *fakeChain) TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.receipt == nil {
+		return nil, ethereum.NotFound
+	}
+	return c.receipt, nil
+}
+
+// mine 追加一个区块；includeTx 为 true 时把交易打包进这个区块
+func (c *fakeChain) mine(includeTx bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h := header(uint64(len(c.canonical)), 0)
+	c.canonical = append(c.canonical, h)
+	if includeTx {
+		c.receipt = &types.Receipt{BlockNumber: h.Number, BlockHash: h.Hash(), Status: types.ReceiptStatusSuccessful}
+	}
+}
+
+// reorg 替换 from 及之后的区块，交易回到交易池
+func (c *fakeChain) reorg(from uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for n := from; n < uint64(len(c.canonical)); n++ {
+		c.canonical[n] = header(n, 1)
+	}
+	c.receipt = nil
+}
+
+func newFakeChain() *fakeChain {
+	return &fakeChain{canonical: []*types.Header{header(0, 0)}}
+}
+
+func TestWaitConfirmationsAndReorg(t *testing.T) {
+	chain := newFakeChain()
+	var (
+		mu      sync.Mutex
+		updates []Update
+	)
+	cfg := Config{PollInterval: time.Millisecond, OnUpdate: func(u Update) {
+		mu.Lock()
+		updates = append(updates, u)
+		mu.Unlock()
+	}}
+
+	done := make(chan struct{})
+	var (
+		receipt *types.Receipt
+		err     error
+	)
+	go func() {
+		receipt, err = Wait(context.Background(), chain, common.Hash{1}, 3, cfg)
+		close(done)
+	}()
+
+	// 交易在区块 1 上链，区块 1 随后被重组掉，最终在区块 3 上链并等到 3 个确认
+	step := func(f func()) {
+		f()
+		time.Sleep(20 * time.Millisecond)
+	}
+	step(func() { chain.mine(true) })
+	step(func() { chain.mine(false) })
+	step(func() { chain.reorg(1) })
+	step(func() { chain.mine(true) })
+	step(func() { chain.mine(false) })
+	step(func() { chain.mine(false) })
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait did not return")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.BlockNumber.Uint64() != 3 {
+		t.Errorf("receipt block = %d, want 3", receipt.BlockNumber.Uint64())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	reorged := false
+	for _, u := range updates {
+		if u.Reorged != nil {
+			reorged = true
+			if u.Reorged.BlockNumber.Uint64() != 1 {
+				t.Errorf("reorged receipt block = %d, want 1", u.Reorged.BlockNumber.Uint64())
+			}
+		}
+	}
+	if !reorged {
+		t.Error("no reorg update reported")
+	}
+	if last := updates[len(updates)-1]; last.Confirmations != 3 {
+		t.Errorf("last update confirmations = %d, want 3", last.Confirmations)
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	chain := newFakeChain()
+	_, err := Wait(context.Background(), chain, common.Hash{1}, 1, Config{PollInterval: time.Millisecond, Timeout: 20 * time.Millisecond})
+	var te *TimeoutError
+	if !errors.Is(err, ErrTimeout) || !errors.As(err, &te) || te.Receipt != nil {
+		t.Fatalf("err = %v, want not-mined timeout", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want it to wrap context.DeadlineExceeded", err)
+	}
+
+	chain.mine(true)
+	_, err = Wait(context.Background(), chain, common.Hash{1}, 5, Config{PollInterval: time.Millisecond, Timeout: 20 * time.Millisecond})
+	if !errors.As(err, &te) || te.Receipt == nil || te.Confirmations != 1 || te.Want != 5 {
+		t.Fatalf("err = %v, want mined timeout with 1/5 confirmations", err)
+	}
+}
+
+func TestWaitNodeErrors(t *testing.T) {
+	cfg := Config{PollInterval: time.Millisecond, Timeout: 50 * time.Millisecond}
+
+	// 限流是临时错误，继续等待直到超时
+	chain := newFakeChain()
+	chain.err = rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}
+	if _, err := Wait(context.Background(), chain, common.Hash{1}, 1, cfg); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want timeout for rate limited node", err)
+	}
+
+	// 鉴权失败重试也不会成功，立即返回
+	denied := rpc.HTTPError{StatusCode: 401, Status: "401 Unauthorized"}
+	chain.err = denied
+	start := time.Now()
+	_, err := Wait(context.Background(), chain, common.Hash{1}, 1, Config{PollInterval: time.Millisecond})
+	if errors.Is(err, ErrTimeout) || !errors.As(err, new(rpc.HTTPError)) {
+		t.Fatalf("err = %v, want %v", err, denied)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("permanent error returned after %v, want immediately", time.Since(start))
+	}
+}